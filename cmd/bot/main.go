@@ -1,43 +1,203 @@
 package main
 
 import (
-	"log"
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
 
 	"telegramBotTrade/internal/config"
+	"telegramBotTrade/internal/finance"
+	"telegramBotTrade/internal/logging"
+	"telegramBotTrade/internal/openai"
 	"telegramBotTrade/internal/server"
 	"telegramBotTrade/internal/storage"
 	"telegramBotTrade/internal/telegram"
+	"telegramBotTrade/internal/version"
 )
 
+func fatal(msg string, err error) {
+	slog.Error(msg, "err", err)
+	os.Exit(1)
+}
+
+// preflight verifies each credential and the database before any traffic is
+// accepted, so a bad key fails the deploy instead of the first command.
+func preflight(ctx context.Context, cfg config.Config, db storage.DB) {
+	ctx, cancel := context.WithTimeout(ctx, 20*time.Second)
+	defer cancel()
+
+	if err := storage.NewStore(db).CheckWrite(ctx); err != nil {
+		fatal("preflight: database is not writable, check DB_PATH and volume permissions", err)
+	}
+	slog.Info("preflight: database ok", "path", cfg.DBPath)
+
+	for _, bc := range cfg.Bots() {
+		user, err := telegram.CheckToken(bc.Token)
+		if err != nil {
+			fatal(fmt.Sprintf("preflight: Telegram rejected %s (getMe failed); check the token with @BotFather", tokenKey(bc)), err)
+		}
+		slog.Info("preflight: telegram ok", "bot", user, "name", bc.Name)
+	}
+
+	if !cfg.PreflightOpenAI {
+		slog.Info("preflight: openai check skipped", "reason", "PREFLIGHT_OPENAI=false")
+		return
+	}
+	if err := openai.CheckKey(ctx, cfg.OpenAIKey); err != nil {
+		fatal("preflight: OpenAI rejected OPENAI_API_KEY (models list failed)", err)
+	}
+	slog.Info("preflight: openai ok")
+}
+
+// tokenKey names the setting holding a bot's token, for error messages.
+func tokenKey(bc config.BotConfig) string {
+	if bc.Name == "" {
+		return "TELEGRAM_BOT_TOKEN"
+	}
+	return "BOT_" + strings.ToUpper(bc.Name) + "_TOKEN"
+}
+
 func main() {
-	cfg := config.Load()
+	cfg, err := config.Load()
+	if err != nil {
+		fatal("config: invalid", err)
+	}
+	logging.Setup(os.Stderr, cfg.LogFormat, cfg.LogLevel)
+	for _, bc := range cfg.Bots() {
+		logging.RegisterSecret(bc.Token)
+	}
+	logging.RegisterSecret(cfg.OpenAIKey)
+	about := func() version.Info {
+		info := version.Get()
+		info.OpenAIModel = openai.Model
+		info.DBPath = cfg.DBPath
+		return info
+	}
+	slog.Info("starting", about().LogAttrs()...)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	finance.SetYahooPacing(time.Duration(cfg.YahooMinGapMS)*time.Millisecond, time.Duration(cfg.YahooRetryJitterMS)*time.Millisecond)
+	finance.SetRenderPool(cfg.RenderWorkers, cfg.RenderQueueSize, time.Duration(cfg.RenderTimeoutSec)*time.Second)
+	if cfg.DemoMode {
+		finance.SetSeriesSource(finance.DemoSource{})
+		slog.Warn("finance: DEMO_MODE on, serving synthetic prices instead of Yahoo")
+	}
 
 	// Ensure parent directory for the DB exists
 	_ = os.MkdirAll(filepath.Dir(cfg.DBPath), 0o755)
 	db, err := storage.OpenSQLite("file:" + cfg.DBPath + "?_fk=1")
 	if err != nil {
-		log.Fatal(err)
+		fatal("db: open failed", err)
 	}
 	defer db.Close()
-	log.Printf("db: opened sqlite at %s", cfg.DBPath)
+	slog.Info("db: opened sqlite", "path", cfg.DBPath)
 	if err := storage.InitSchema(db); err != nil {
-		log.Fatal(err)
+		fatal("db: schema init failed", err)
 	}
-	log.Println("db: schema ensured (messages table)")
+	slog.Info("db: schema ensured")
+	preflight(ctx, cfg, db)
 
-	tg, err := telegram.NewBot(cfg.TelegramToken, cfg.WebhookPublicURL, db, cfg.OpenAIKey)
-	if err != nil {
-		log.Fatal(err)
+	// every bot shares the database and the finance pools; the first one is
+	// the primary bot that runs background jobs and serves /api/notify
+	checks := map[string]server.Checker{
+		"db": storage.NewStore(db).Ping,
+	}
+	webhooks := map[string]http.HandlerFunc{}
+	var bots []*telegram.Bot
+	for _, bc := range cfg.Bots() {
+		tg, err := telegram.NewBot(telegram.Options{
+			Name:       bc.Name,
+			Token:      bc.Token,
+			WebhookURL: bc.WebhookTarget(),
+			OpenAIKey:  cfg.OpenAIKey,
+			Workers:    cfg.HandlerWorkers,
+			QueueSize:  cfg.HandlerQueueSize,
+
+			PerChatOrder: cfg.PerChatOrder,
+			AdminChatID:  cfg.AdminChatID,
+			About:        about,
+
+			TargetExpiryDays: cfg.TargetExpiryDays,
+			WeeklyReport:     cfg.WeeklyReport,
+			AIDailyLimit:     cfg.AIDailyLimit,
+		}, db)
+		if err != nil {
+			fatal("telegram: init failed for "+tokenKey(bc), err)
+		}
+		if tg.Mode() == "webhook" {
+			slog.Info("telegram: bot initialized", "name", bc.Name, "mode", "webhook", "path", bc.WebhookPath())
+		} else {
+			slog.Info("telegram: bot initialized", "name", bc.Name, "mode", "polling", "reason", "no webhook URL set")
+		}
+		go func() {
+			if err := tg.Run(ctx); err != nil {
+				slog.Error("telegram: update loop error", "name", bc.Name, "err", err)
+			}
+		}()
+		check := "telegram"
+		if bc.Name != "" {
+			check += "_" + bc.Name
+		}
+		checks[check] = tg.CheckTelegram
+		webhooks[bc.WebhookPath()] = tg.WebhookHandler
+		bots = append(bots, tg)
 	}
-	log.Printf("telegram: bot initialized, webhook target %s", cfg.WebhookPublicURL)
 
-	mux := server.NewHTTPMux(tg.WebhookHandler) // registers /telegram/webhook
-	addr := ":" + cfg.Port
-	log.Println("http: listening on", addr)
-	if err := server.ListenAndServe(addr, mux); err != nil {
-		log.Println("server error:", err)
+	// the HTTP server still runs in polling mode so /healthz stays available
+	api := server.APIOptions{
+		Token:           cfg.APIToken,
+		Notify:          bots[0].Notify,
+		NotifyPerMinute: cfg.APINotifyPerMin,
+		ChartPerMinute:  cfg.APIChartPerMin,
+	}
+	mux := server.NewHTTPMux(webhooks, checks, about, api) // registers each /telegram/webhook path, /healthz, /readyz and /api/*
+	srvOpts := server.Options{
+		Addr:        ":" + cfg.Port,
+		TLSCertFile: cfg.TLSCertFile,
+		TLSKeyFile:  cfg.TLSKeyFile,
+		TrustProxy:  cfg.TrustProxy,
+	}
+	srv := server.NewServer(srvOpts, mux)
+	slog.Info("http: listening", "addr", srv.Addr, "tls", srvOpts.TLSEnabled())
+	errCh := make(chan error, 1)
+	go func() { errCh <- server.Serve(srv, srvOpts) }()
+	select {
+	case err := <-errCh:
+		slog.Error("http: server error", "err", err)
+		db.Close()
 		os.Exit(1)
+	case <-ctx.Done():
+		slog.Info("shutdown: signal received, draining")
+	}
+
+	// Stop accepting webhook requests first, then let running handlers finish
+	// before the deferred db.Close runs.
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		slog.Error("shutdown: http server", "err", err)
+	}
+	// bots drain concurrently so the timeout doesn't add up per bot
+	drained := make(chan bool, len(bots))
+	for _, tg := range bots {
+		go func() { drained <- tg.Shutdown(cfg.DeleteWebhookOnShutdown, 30*time.Second) }()
+	}
+	ok := true
+	for range bots {
+		ok = <-drained && ok
+	}
+	if ok {
+		slog.Info("shutdown: all handlers finished")
+	} else {
+		slog.Warn("shutdown: timed out waiting for handlers")
 	}
 }
@@ -0,0 +1,118 @@
+package chartkit
+
+import (
+	"slices"
+	"time"
+
+	"github.com/vicanso/go-charts/v2"
+)
+
+const (
+	// denseLabels is the label count past which chartkit thins the x axis and
+	// draws it itself. go-charts measures every label and picks its own
+	// stride, which is slow on a year of hourly bars and lands ticks anywhere
+	// in a session.
+	denseLabels = 500
+	// axisTick is go-charts' tick length; labels sit two ticks below the axis.
+	axisTick = 5
+	// labelGap is the least space between two drawn labels.
+	labelGap = 10
+)
+
+// thinLabels picks about 2×split evenly spaced indices of n labels, always
+// including the first and the last. When times spans several days, each pick
+// moves to the nearest session open, the first point of a day, so the ticks
+// mark where sessions begin. It returns nil when n is not above denseLabels
+// or split is 0, leaving the axis to go-charts.
+func thinLabels(n, split int, times []time.Time) []int {
+	if n <= denseLabels || split <= 0 {
+		return nil
+	}
+	var opens []int
+	if len(times) == n {
+		for i, t := range times {
+			if i == 0 || t.YearDay() != times[i-1].YearDay() || t.Year() != times[i-1].Year() {
+				opens = append(opens, i)
+			}
+		}
+		if len(opens) < 2 {
+			opens = nil
+		}
+	}
+	want := 2 * split
+	keep := make([]int, 0, want+1)
+	for k := range want {
+		i := k * (n - 1) / (want - 1)
+		if opens != nil && i < n-1 {
+			i = nearest(opens, i)
+		}
+		keep = append(keep, i)
+	}
+	keep = append(keep, n-1)
+	slices.Sort(keep)
+	return slices.Compact(keep)
+}
+
+// nearest returns the value of sorted closest to i.
+func nearest(sorted []int, i int) int {
+	j, _ := slices.BinarySearch(sorted, i)
+	switch {
+	case j == len(sorted):
+		return sorted[j-1]
+	case j > 0 && i-sorted[j-1] <= sorted[j]-i:
+		return sorted[j-1]
+	}
+	return sorted[j]
+}
+
+// blankLabels keeps the labels at the keep indices and empties the others.
+func blankLabels(labels []string, keep []int) []string {
+	out := make([]string, len(labels))
+	for _, i := range keep {
+		out[i] = labels[i]
+	}
+	return out
+}
+
+// drawXAxis draws the x axis line under the plot with a tick and a label at
+// each kept index, styled like go-charts' own. Labels are centered on their
+// tick but kept from running past the right end. A label that would run into
+// the one before it is skipped, except the last, which replaces it.
+func (a *plotArea) drawXAxis(labels []string, keep []int, theme charts.ColorPalette) {
+	p := a.axis
+	p.SetDrawingStyle(charts.Style{StrokeColor: theme.GetAxisStrokeColor(), StrokeWidth: 1})
+	p.OverrideTextStyle(charts.Style{Font: theme.GetFont(), FontSize: theme.GetFontSize(), FontColor: theme.GetTextColor()})
+	p.LineStroke([]charts.Point{{X: 0, Y: 0}, {X: p.Width(), Y: 0}})
+
+	type label struct {
+		text              string
+		tick, left, right int
+	}
+	var shown []label
+	height := 0
+	for _, i := range keep {
+		if i >= len(a.xs) || labels[i] == "" {
+			continue
+		}
+		box := p.MeasureText(labels[i])
+		l := label{text: labels[i], tick: a.xs[i]}
+		l.left = min(l.tick-box.Width()/2, p.Width()-box.Width())
+		l.right = l.left + box.Width()
+		overlaps := func() bool {
+			return len(shown) > 0 && l.left < shown[len(shown)-1].right+labelGap
+		}
+		if i == keep[len(keep)-1] {
+			for overlaps() {
+				shown = shown[:len(shown)-1]
+			}
+		} else if overlaps() {
+			continue
+		}
+		shown = append(shown, l)
+		height = max(height, box.Height())
+	}
+	for _, l := range shown {
+		p.LineStroke([]charts.Point{{X: l.tick, Y: axisTick}, {X: l.tick, Y: 0}})
+		p.Text(l.text, l.left, 2*axisTick+height)
+	}
+}
@@ -0,0 +1,75 @@
+package chartkit
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+// sessions returns the times of days sessions of perDay bars a minute apart,
+// starting 2024-06-03 09:30 UTC.
+func sessions(days, perDay int) []time.Time {
+	start := time.Date(2024, 6, 3, 9, 30, 0, 0, time.UTC)
+	var out []time.Time
+	for d := range days {
+		for i := range perDay {
+			out = append(out, start.AddDate(0, 0, d).Add(time.Duration(i)*time.Minute))
+		}
+	}
+	return out
+}
+
+func TestThinLabels(t *testing.T) {
+	tests := []struct {
+		name     string
+		n, split int
+		times    []time.Time
+		want     []int
+	}{
+		{"sparse axis left to go-charts", denseLabels, 5, nil, nil},
+		{"no split", 1000, 0, nil, nil},
+		{"evenly spaced", 1000, 5, nil, []int{0, 111, 222, 333, 444, 555, 666, 777, 888, 999}},
+		{"just past the threshold", denseLabels + 1, 2, nil, []int{0, 166, 333, 500}},
+		{"snapped to session opens", 600, 3, sessions(6, 100), []int{0, 100, 200, 400, 500, 599}},
+		{"one session is not snapped", 600, 3, sessions(1, 600), []int{0, 119, 239, 359, 479, 599}},
+		{"times of another length are ignored", 600, 3, sessions(6, 10), []int{0, 119, 239, 359, 479, 599}},
+	}
+	for _, tc := range tests {
+		got := thinLabels(tc.n, tc.split, tc.times)
+		if !slices.Equal(got, tc.want) {
+			t.Errorf("%s: thinLabels(%d, %d) = %v, want %v", tc.name, tc.n, tc.split, got, tc.want)
+		}
+	}
+}
+
+func TestThinLabelsCount(t *testing.T) {
+	for _, n := range []int{501, 777, 2000, 8760} {
+		for _, split := range []int{1, 2, 6, 12} {
+			for _, times := range [][]time.Time{nil, sessions(n/78+1, 78)[:n]} {
+				got := thinLabels(n, split, times)
+				if len(got) < 2 || len(got) > 2*split+1 {
+					t.Errorf("n=%d split=%d: %d labels, want 2 to %d", n, split, len(got), 2*split+1)
+				}
+				if got[0] != 0 || got[len(got)-1] != n-1 || !slices.IsSorted(got) {
+					t.Errorf("n=%d split=%d: %v does not run sorted from the first to the last label", n, split, got)
+				}
+			}
+		}
+	}
+}
+
+func TestNearest(t *testing.T) {
+	opens := []int{0, 100, 200}
+	for i, want := range map[int]int{0: 0, 49: 0, 50: 0, 51: 100, 100: 100, 160: 200, 999: 200} {
+		if got := nearest(opens, i); got != want {
+			t.Errorf("nearest(%v, %d) = %d, want %d", opens, i, got, want)
+		}
+	}
+}
+
+func TestBlankLabels(t *testing.T) {
+	got := blankLabels([]string{"a", "b", "c", "d"}, []int{0, 3})
+	if want := []string{"a", "", "", "d"}; !slices.Equal(got, want) {
+		t.Errorf("blankLabels = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,48 @@
+package chartkit
+
+import (
+	"slices"
+
+	"github.com/vicanso/go-charts/v2"
+)
+
+// Bar is a horizontal bar chart, one labeled bar per value with the value
+// printed at its end. The first value is drawn at the top.
+type Bar struct {
+	Style
+	Title         string
+	Labels        []string
+	Values        []float64
+	Width, Height int // 0 is 600x400
+}
+
+// Render draws the chart and encodes it.
+func (c Bar) Render() ([]byte, error) {
+	theme := c.Theme
+	if theme == "" {
+		theme = ThemeLight
+	}
+	// go-charts stacks categories bottom-up
+	labels, values := slices.Clone(c.Labels), slices.Clone(c.Values)
+	slices.Reverse(labels)
+	slices.Reverse(values)
+	series := charts.NewSeriesListDataFromValues([][]float64{values}, charts.ChartTypeHorizontalBar)
+	series[0].Label.Show = true
+	opts := []charts.OptionFunc{
+		charts.TitleTextOptionFunc(c.Title),
+		charts.YAxisDataOptionFunc(labels),
+		charts.ThemeOptionFunc(theme),
+		charts.TypeOptionFunc(c.outputType()),
+	}
+	if c.Width > 0 {
+		opts = append(opts, charts.WidthOptionFunc(c.Width))
+	}
+	if c.Height > 0 {
+		opts = append(opts, charts.HeightOptionFunc(c.Height))
+	}
+	p, err := charts.Render(charts.ChartOption{SeriesList: series}, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return p.Bytes()
+}
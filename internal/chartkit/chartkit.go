@@ -0,0 +1,49 @@
+// Package chartkit draws the bot's charts. Chart builders describe a chart
+// (line panels with filled bands and annotations, pies, bars, stacked
+// panels) and chartkit renders it with go-charts, drawing what go-charts
+// lacks on top of its layout. Nothing outside this package touches the
+// go-charts Painter.
+package chartkit
+
+import (
+	"github.com/vicanso/go-charts/v2"
+)
+
+// Color is an RGBA color.
+type Color = charts.Color
+
+// Theme names accepted in Style.Theme.
+const (
+	ThemeLight   = charts.ThemeLight
+	ThemeDark    = charts.ThemeDark
+	ThemeGrafana = charts.ThemeGrafana
+	ThemeAnt     = charts.ThemeAnt
+)
+
+// Themes lists every theme name, light first.
+var Themes = []string{ThemeLight, ThemeDark, ThemeGrafana, ThemeAnt}
+
+// Style is the presentation every chart shares.
+type Style struct {
+	Theme string // one of Themes; empty is light
+	SVG   bool   // encode as SVG instead of PNG
+}
+
+// outputType returns the go-charts output type for the image format.
+func (s Style) outputType() string {
+	if s.SVG {
+		return charts.ChartOutputSVG
+	}
+	return charts.ChartOutputPNG
+}
+
+// theme returns the go-charts palette of the style's theme.
+func (s Style) theme() charts.ColorPalette {
+	if s.Theme == "" {
+		return charts.NewTheme(ThemeLight)
+	}
+	return charts.NewTheme(s.Theme)
+}
+
+// NullValue marks a missing point in a series; the line breaks around it.
+func NullValue() float64 { return charts.GetNullValue() }
@@ -0,0 +1,123 @@
+package chartkit
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/vicanso/go-charts/v2"
+)
+
+const (
+	heatHeight    = 160 // default image height
+	heatMinCell   = 96  // narrowest cell before the image grows wider
+	heatPadding   = 20
+	heatTitleArea = 50 // title band above the cells
+	heatGap       = 4  // between cells
+	heatLabelSize = 13
+	heatValueSize = 12
+)
+
+// Ends and middle of the heat color scale.
+var (
+	heatRed     = Color{R: 0xb7, G: 0x1c, B: 0x1c, A: 0xff}
+	heatNeutral = Color{R: 0xe6, G: 0xe6, B: 0xe6, A: 0xff}
+	heatGreen   = Color{R: 0x1b, G: 0x5e, B: 0x20, A: 0xff}
+)
+
+// Heat is a single row of cells, one per label, each filled by its value on
+// a red-to-green scale and printed with the label and the value in percent.
+type Heat struct {
+	Style
+	Title  string
+	Labels []string
+	Values []float64 // percent
+	// Scale is the value, either side of zero, drawn in the deepest color;
+	// 0 uses the largest absolute value.
+	Scale         float64
+	Width, Height int // 0 is 600x160, wider when the cells would be narrower than heatMinCell
+}
+
+// HeatColor maps v onto the scale from deep red at -scale through a light
+// gray at 0 to deep green at +scale; values past scale get the end colors.
+func HeatColor(v, scale float64) Color {
+	if scale <= 0 || math.IsNaN(v) {
+		return heatNeutral
+	}
+	t := math.Max(-1, math.Min(1, v/scale))
+	if t < 0 {
+		return mixColor(heatNeutral, heatRed, -t)
+	}
+	return mixColor(heatNeutral, heatGreen, t)
+}
+
+// mixColor is the color t of the way from a to b.
+func mixColor(a, b Color, t float64) Color {
+	mix := func(x, y uint8) uint8 { return uint8(math.Round(float64(x) + (float64(y)-float64(x))*t)) }
+	return Color{R: mix(a.R, b.R), G: mix(a.G, b.G), B: mix(a.B, b.B), A: mix(a.A, b.A)}
+}
+
+// heatTextColor is white on the deep half of the scale, dark otherwise.
+func heatTextColor(v, scale float64) Color {
+	if scale > 0 && math.Abs(v) > scale/2 {
+		return Color{R: 0xff, G: 0xff, B: 0xff, A: 0xff}
+	}
+	return Color{R: 0x21, G: 0x21, B: 0x21, A: 0xff}
+}
+
+// Render draws the strip and encodes it.
+func (c Heat) Render() ([]byte, error) {
+	n := len(c.Values)
+	if n == 0 || len(c.Labels) != n {
+		return nil, errors.New("heat: need one label per value")
+	}
+	scale := c.Scale
+	if scale <= 0 {
+		for _, v := range c.Values {
+			scale = math.Max(scale, math.Abs(v))
+		}
+	}
+	width, height := c.Width, c.Height
+	if width <= 0 {
+		width = max(defaultWidth, n*heatMinCell+2*heatPadding)
+	}
+	if height <= 0 {
+		height = heatHeight
+	}
+	p, err := charts.NewPainter(charts.PainterOptions{Type: c.outputType(), Width: width, Height: height})
+	if err != nil {
+		return nil, err
+	}
+	theme := c.theme()
+	p.SetBackground(width, height, theme.GetBackgroundColor())
+	if c.Title != "" {
+		title := p.Child(charts.PainterPaddingOption(charts.Box{Top: heatPadding, Left: heatPadding, Right: heatPadding}))
+		if _, err := charts.NewTitlePainter(title, charts.TitleOption{Text: c.Title, Theme: theme}).Render(); err != nil {
+			return nil, err
+		}
+	}
+
+	top, bottom := heatTitleArea, height-heatPadding
+	if c.Title == "" {
+		top = heatPadding
+	}
+	cell := float64(width-2*heatPadding) / float64(n)
+	for i, v := range c.Values {
+		left := heatPadding + int(math.Round(float64(i)*cell))
+		right := heatPadding + int(math.Round(float64(i+1)*cell)) - heatGap
+		color := HeatColor(v, scale)
+		p.SetDrawingStyle(charts.Style{FillColor: color, StrokeColor: color})
+		p.Rect(charts.Box{Left: left, Top: top, Right: right, Bottom: bottom})
+
+		text := heatTextColor(v, scale)
+		mid := (top + bottom) / 2
+		p.OverrideTextStyle(charts.Style{FontSize: heatLabelSize, FontColor: text})
+		box := p.MeasureText(c.Labels[i])
+		p.Text(c.Labels[i], left+(right-left-box.Width())/2, mid-4)
+		value := fmt.Sprintf("%+.1f%%", v)
+		p.OverrideTextStyle(charts.Style{FontSize: heatValueSize, FontColor: text})
+		box = p.MeasureText(value)
+		p.Text(value, left+(right-left-box.Width())/2, mid+4+box.Height())
+	}
+	return p.Bytes()
+}
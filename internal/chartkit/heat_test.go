@@ -0,0 +1,68 @@
+package chartkit
+
+import (
+	"math"
+	"testing"
+)
+
+func TestHeatColor(t *testing.T) {
+	tests := []struct {
+		name     string
+		v, scale float64
+		want     Color
+	}{
+		{"zero", 0, 10, heatNeutral},
+		{"full gain", 10, 10, heatGreen},
+		{"full loss", -10, 10, heatRed},
+		{"past the gain end", 25, 10, heatGreen},
+		{"past the loss end", -25, 10, heatRed},
+		{"half gain", 5, 10, Color{R: 0x81, G: 0xa2, B: 0x83, A: 0xff}},
+		{"half loss", -5, 10, Color{R: 0xcf, G: 0x81, B: 0x81, A: 0xff}},
+		{"no scale", 5, 0, heatNeutral},
+		{"NaN", math.NaN(), 10, heatNeutral},
+	}
+	for _, tc := range tests {
+		if got := HeatColor(tc.v, tc.scale); got != tc.want {
+			t.Errorf("%s: HeatColor(%v, %v) = %+v, want %+v", tc.name, tc.v, tc.scale, got, tc.want)
+		}
+	}
+}
+
+func TestHeatColorMonotonic(t *testing.T) {
+	// each step away from zero moves the color further from the neutral gray
+	dist := func(c Color) int {
+		d := func(a, b uint8) int { return int(a) - int(b) }
+		r, g, b := d(c.R, heatNeutral.R), d(c.G, heatNeutral.G), d(c.B, heatNeutral.B)
+		return r*r + g*g + b*b
+	}
+	for _, sign := range []float64{1, -1} {
+		prev := 0
+		for v := 0.5; v <= 10; v += 0.5 {
+			d := dist(HeatColor(sign*v, 10))
+			if d <= prev {
+				t.Fatalf("HeatColor(%v, 10) is no further from neutral than the step before", sign*v)
+			}
+			prev = d
+		}
+	}
+}
+
+func TestHeatTextColor(t *testing.T) {
+	white, dark := Color{R: 0xff, G: 0xff, B: 0xff, A: 0xff}, Color{R: 0x21, G: 0x21, B: 0x21, A: 0xff}
+	for v, want := range map[float64]Color{0: dark, 5: dark, -5: dark, 5.1: white, -8: white, 20: white} {
+		if got := heatTextColor(v, 10); got != want {
+			t.Errorf("heatTextColor(%v, 10) = %+v, want %+v", v, got, want)
+		}
+	}
+	if got := heatTextColor(5, 0); got != dark {
+		t.Errorf("heatTextColor without a scale = %+v, want dark", got)
+	}
+}
+
+func TestHeatRenderNeedsLabels(t *testing.T) {
+	for _, h := range []Heat{{}, {Labels: []string{"A"}, Values: []float64{1, 2}}} {
+		if _, err := h.Render(); err == nil {
+			t.Errorf("%d labels for %d values rendered", len(h.Labels), len(h.Values))
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package chartkit
+
+import (
+	"errors"
+
+	"github.com/vicanso/go-charts/v2"
+)
+
+// Histogram is a vertical bar chart of counts per bin, optionally with a
+// curve of expected counts, such as a fitted distribution, drawn over the
+// bars.
+type Histogram struct {
+	Style
+	Title, Subtitle string
+	Labels          []string  // one per bin, under its bar
+	Counts          []float64 // one per bin
+	Curve           []float64 // nil draws no curve
+	Names           [2]string // legend labels of the bars and the curve, when there is a curve
+	Split           int       // how many labels to show; 0 lets go-charts choose
+	Width, Height   int       // 0 is 600x400
+}
+
+// Render draws the chart and encodes it.
+func (c Histogram) Render() ([]byte, error) {
+	if len(c.Counts) == 0 {
+		return nil, errors.New("no bins to draw")
+	}
+	theme := c.Theme
+	if theme == "" {
+		theme = ThemeLight
+	}
+	series := charts.NewSeriesListDataFromValues([][]float64{c.Counts}, charts.ChartTypeBar)
+	if c.Curve != nil {
+		curve := charts.NewSeriesListDataFromValues([][]float64{c.Curve}, charts.ChartTypeLine)[0]
+		series[0].Name, curve.Name = c.Names[0], c.Names[1]
+		series = append(series, curve)
+	}
+	opt := charts.ChartOption{
+		SeriesList: series,
+		XAxis:      charts.XAxisOption{Data: c.Labels, SplitNumber: c.Split},
+		Title:      charts.TitleOption{Text: c.Title, Subtext: c.Subtitle},
+		Theme:      theme,
+		Type:       c.outputType(),
+		Padding:    charts.Box{Top: 20, Right: 20, Bottom: 20, Left: 20},
+		Width:      c.Width,
+		Height:     c.Height,
+	}
+	if c.Curve != nil {
+		opt.Legend = charts.LegendOption{Data: c.Names[:], Left: charts.PositionRight}
+	}
+	p, err := charts.Render(opt)
+	if err != nil {
+		return nil, err
+	}
+	return p.Bytes()
+}
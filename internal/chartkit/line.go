@@ -0,0 +1,228 @@
+package chartkit
+
+import (
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/vicanso/go-charts/v2"
+)
+
+const (
+	defaultWidth  = 600
+	defaultHeight = 400
+	// legendRowHeight and legendPerRow lay out a legend below the plot.
+	legendRowHeight = 25
+	legendPerRow    = 3
+)
+
+// Series is one line of a Line chart.
+type Series struct {
+	Name   string
+	Values []float64 // NullValue leaves a gap
+	Axis   int       // 0 is the left y axis, 1 the right one
+	Dash   []float64 // stroke dash pattern; nil draws a solid line
+}
+
+// XAxis holds the category labels under the plot, one per point. Past
+// denseLabels labels, about 2×Split of them are kept and the rest left blank;
+// Times, the instant of each point, moves those to session opens.
+type XAxis struct {
+	Labels []string
+	Split  int  // how many labels to show; 0 lets go-charts choose
+	Gap    bool // inset the first and last points by half a category
+	Times  []time.Time
+}
+
+// YAxis bounds one value axis; nil bounds are derived from the data.
+type YAxis struct {
+	Min, Max *float64
+	Divide   int // intervals between labels; 0 is go-charts' default
+}
+
+// Legend labels the series in order.
+type Legend struct {
+	Labels   []string
+	Centered bool // centered above the plot instead of right-aligned
+	// Below moves the legend under the plot, wrapping every legendPerRow
+	// labels; the image grows by the rows it needs.
+	Below bool
+}
+
+// Line is a line chart panel.
+type Line struct {
+	Style
+	Title, Subtitle string
+	Series          []Series
+	X               XAxis
+	Y               []YAxis // the left axis, then the right one if a series uses it
+	Legend          Legend
+	Width, Height   int // of the plot; 0 is 600x400
+	// Colors replaces the theme's series colors; a zero color is drawn in the
+	// theme's text color.
+	Colors []Color
+	Bands  []Band    // drawn under the lines
+	Marks  []Mark    // drawn over them
+	Refs   []RefLine // drawn over them
+}
+
+// Render draws the chart and encodes it.
+func (l Line) Render() ([]byte, error) {
+	p, err := l.draw()
+	if err != nil {
+		return nil, err
+	}
+	return p.Bytes()
+}
+
+func (l Line) draw() (*charts.Painter, error) {
+	if len(l.Series) == 0 {
+		return nil, errors.New("no series to draw")
+	}
+	width, height := l.Width, l.Height
+	if width <= 0 {
+		width = defaultWidth
+	}
+	if height <= 0 {
+		height = defaultHeight
+	}
+	theme := l.theme()
+	if l.Colors != nil {
+		colors := slices.Clone(l.Colors)
+		for i := range colors {
+			if colors[i].IsZero() {
+				colors[i] = theme.GetTextColor()
+			}
+		}
+		theme.SetSeriesColor(colors)
+	}
+	total := height
+	if l.Legend.Below {
+		rows := (len(l.Legend.Labels) + legendPerRow - 1) / legendPerRow
+		total += rows*legendRowHeight + 10
+	}
+	p, err := charts.NewPainter(charts.PainterOptions{Type: l.outputType(), Width: width, Height: total})
+	if err != nil {
+		return nil, err
+	}
+
+	opt := charts.LineChartOption{
+		Theme:        theme,
+		Padding:      charts.Box{Top: 20, Right: 20, Bottom: 20, Left: 20},
+		SeriesList:   l.seriesList(),
+		XAxis:        charts.XAxisOption{Data: l.X.Labels, SplitNumber: l.X.Split},
+		YAxisOptions: yAxisOptions(l.Y),
+		Title:        charts.TitleOption{Text: l.Title, Subtext: l.Subtitle},
+	}
+	if !l.X.Gap {
+		opt.XAxis.BoundaryGap = charts.FalseFlag()
+	}
+	plot := p
+	if l.Legend.Below {
+		p.SetBackground(p.Width(), p.Height(), theme.GetBackgroundColor())
+		plot = p.Child(charts.PainterBoxOption(charts.Box{Right: width, Bottom: height}))
+		opt.Padding.Bottom = 10
+	} else if len(l.Legend.Labels) > 0 {
+		opt.Legend = charts.LegendOption{Data: l.Legend.Labels, Left: charts.PositionRight}
+		if l.Legend.Centered {
+			opt.Legend.Left = ""
+		}
+	}
+
+	keep := thinLabels(len(l.X.Labels), l.X.Split, l.X.Times)
+	if keep != nil {
+		opt.XAxis.Data = blankLabels(l.X.Labels, keep)
+		opt.XAxis.Show = charts.FalseFlag()
+	}
+	var area *plotArea
+	if len(l.Bands) > 0 || len(l.Marks) > 0 || len(l.Refs) > 0 || keep != nil {
+		y := l.boundedY()
+		opt.YAxisOptions = yAxisOptions(y)
+		if area, err = layout(plot, opt, l.X.Gap); err != nil {
+			return nil, err
+		}
+	}
+	if len(l.Bands) > 0 {
+		if !l.Legend.Below {
+			p.SetBackground(p.Width(), p.Height(), theme.GetBackgroundColor())
+		}
+		for _, b := range l.Bands {
+			color := b.Color
+			if color.IsZero() {
+				color = theme.GetSeriesColor(0).WithAlpha(0x33)
+			}
+			area.fillBand(b, color)
+		}
+		// the background is painted already; go-charts would paint over the bands
+		opt.Theme = underlaid{theme}
+	}
+	if _, err := charts.NewLineChart(plot, opt).Render(); err != nil {
+		return nil, err
+	}
+	if l.Legend.Below {
+		legend := p.Child(charts.PainterBoxOption(charts.Box{Top: height, Left: 20, Right: width - 20, Bottom: p.Height()}))
+		if _, err := charts.NewLegendPainter(legend, charts.LegendOption{Theme: theme, Data: l.Legend.Labels, Left: "0"}).Render(); err != nil {
+			return nil, err
+		}
+	}
+	if keep != nil {
+		area.drawXAxis(l.X.Labels, keep, theme)
+	}
+	for _, r := range l.Refs {
+		area.drawRef(r, theme.GetTextColor())
+	}
+	for _, m := range l.Marks {
+		if m.Series >= 0 && m.Series < len(l.Series) {
+			area.drawMark(m, l.Series[m.Series], theme.GetSeriesColor(m.Series), theme.GetTextColor())
+		}
+	}
+	return p, nil
+}
+
+// seriesList converts the series to go-charts line series.
+func (l Line) seriesList() charts.SeriesList {
+	values := make([][]float64, len(l.Series))
+	for i, s := range l.Series {
+		values[i] = s.Values
+	}
+	list := charts.NewSeriesListDataFromValues(values, charts.ChartTypeLine)
+	for i, s := range l.Series {
+		list[i].Name = s.Name
+		list[i].AxisIndex = s.Axis
+		list[i].Style.StrokeDashArray = s.Dash
+	}
+	return list
+}
+
+// yAxisOptions converts the y axes to go-charts options; the second axis is
+// drawn on the right.
+func yAxisOptions(axes []YAxis) []charts.YAxisOption {
+	if axes == nil {
+		return nil
+	}
+	out := make([]charts.YAxisOption, len(axes))
+	for i, y := range axes {
+		out[i] = charts.YAxisOption{Min: y.Min, Max: y.Max, DivideCount: y.Divide}
+		if i > 0 {
+			out[i].Position = charts.PositionRight
+		}
+	}
+	return out
+}
+
+// underlaid is a theme with a transparent background, for drawing a chart
+// over a canvas that already holds its background and bands.
+type underlaid struct {
+	charts.ColorPalette
+}
+
+func (underlaid) GetBackgroundColor() Color { return Color{} }
+
+// Lines makes one unnamed series per value slice, all on the left axis.
+func Lines(values ...[]float64) []Series {
+	out := make([]Series, len(values))
+	for i, v := range values {
+		out[i] = Series{Values: v}
+	}
+	return out
+}
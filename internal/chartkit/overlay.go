@@ -0,0 +1,298 @@
+package chartkit
+
+import (
+	"math"
+	"slices"
+
+	"github.com/dustin/go-humanize"
+	"github.com/vicanso/go-charts/v2"
+)
+
+const (
+	// xAxisHeight and defaultDivide are go-charts' own layout constants.
+	xAxisHeight   = 30
+	defaultDivide = 6
+	markRadius    = 4
+	markFontSize  = 10
+)
+
+// refDash is the stroke pattern of a RefLine.
+var refDash = []float64{6, 4}
+
+// Band fills the area between two bounds, such as a Bollinger envelope or a
+// Monte Carlo fan, under the lines. Points where either bound is NullValue
+// split the band.
+type Band struct {
+	Lower, Upper []float64
+	Axis         int
+	Color        Color // zero is the first series' color, faded
+}
+
+// Mark annotates one point of a series with a dot and a short label.
+type Mark struct {
+	Series int // index into Line.Series
+	Index  int // point within the series
+	Text   string
+}
+
+// RefLine is a dashed horizontal line across the plot at a fixed value, such
+// as a previous close, with its text at the left end.
+type RefLine struct {
+	Value float64
+	Axis  int
+	Text  string
+}
+
+// boundedY returns the y axes with explicit bounds covering every series,
+// band and reference line, so overlays can be placed without guessing go-charts' rounded ranges.
+// Bounds that already cover the data are kept.
+func (l Line) boundedY() []YAxis {
+	n := len(l.Y)
+	for _, s := range l.Series {
+		n = max(n, s.Axis+1)
+	}
+	out := make([]YAxis, n)
+	copy(out, l.Y)
+	for i := range out {
+		lo, hi := math.Inf(1), math.Inf(-1)
+		extend := func(vs []float64) {
+			for _, v := range vs {
+				if v != NullValue() {
+					lo, hi = math.Min(lo, v), math.Max(hi, v)
+				}
+			}
+		}
+		for _, s := range l.Series {
+			if s.Axis == i {
+				extend(s.Values)
+			}
+		}
+		for _, b := range l.Bands {
+			if b.Axis == i {
+				extend(b.Lower)
+				extend(b.Upper)
+			}
+		}
+		for _, r := range l.Refs {
+			if r.Axis == i {
+				extend([]float64{r.Value})
+			}
+		}
+		if lo > hi {
+			continue
+		}
+		pad := (hi - lo) * 0.05
+		if out[i].Min == nil || *out[i].Min > lo {
+			v := lo - pad
+			out[i].Min = &v
+		}
+		if out[i].Max == nil || *out[i].Max < hi {
+			v := hi + pad
+			out[i].Max = &v
+		}
+	}
+	return out
+}
+
+// plotArea is where go-charts draws a line chart's series, with the x
+// position of every point and the bounds of every y axis. axis is the strip
+// under it that holds the x axis.
+type plotArea struct {
+	p      *charts.Painter
+	axis   *charts.Painter
+	xs     []int
+	bounds map[int][2]float64
+}
+
+// layout works out the plot area of opt drawn on plot the way go-charts lays
+// out the title, legend and axes, measuring them on a scratch canvas. Every
+// axis needs explicit bounds covering its series, see boundedY.
+func layout(plot *charts.Painter, opt charts.LineChartOption, gap bool) (*plotArea, error) {
+	scratch, err := charts.NewPainter(charts.PainterOptions{Type: charts.ChartOutputPNG, Width: plot.Width(), Height: plot.Height()})
+	if err != nil {
+		return nil, err
+	}
+	canvas, probe := plot, scratch
+	pad := func(b charts.Box) {
+		canvas = canvas.Child(charts.PainterPaddingOption(b))
+		probe = probe.Child(charts.PainterPaddingOption(b))
+	}
+	if !opt.Padding.IsZero() {
+		pad(opt.Padding)
+	}
+	legendHeight := 0
+	if len(opt.Legend.Data) > 0 {
+		lo := opt.Legend
+		lo.Theme = opt.Theme
+		b, err := charts.NewLegendPainter(probe, lo).Render()
+		if err != nil {
+			return nil, err
+		}
+		legendHeight = b.Height()
+	}
+	if opt.Title.Text != "" {
+		to := opt.Title
+		to.Theme = opt.Theme
+		b, err := charts.NewTitlePainter(probe, to).Render()
+		if err != nil {
+			return nil, err
+		}
+		pad(charts.Box{Top: max(legendHeight, b.Height()) + 20})
+	}
+
+	area := &plotArea{bounds: map[int][2]float64{}}
+	var axes []int
+	for _, s := range opt.SeriesList {
+		if !slices.Contains(axes, s.AxisIndex) {
+			axes = append(axes, s.AxisIndex)
+		}
+	}
+	slices.Sort(axes)
+	slices.Reverse(axes)
+	left, right := 0, 0
+	for _, i := range axes {
+		yo := opt.YAxisOptions[i]
+		lo, hi := *yo.Min, *yo.Max
+		area.bounds[i] = [2]float64{lo, hi}
+		divide := yo.DivideCount
+		if divide <= 0 {
+			divide = defaultDivide
+		}
+		step := (hi - lo) / float64(divide)
+		yo.Data = make([]string, divide+1)
+		for k := 0; k <= divide; k++ {
+			yo.Data[divide-k] = axisLabel(lo + float64(k)*step)
+		}
+		yo.Theme = opt.Theme
+		child := probe.Child(charts.PainterPaddingOption(charts.Box{Left: left, Right: right}))
+		if i == 0 {
+			b, err := charts.NewLeftYAxis(child, yo).Render()
+			if err != nil {
+				return nil, err
+			}
+			left += b.Width()
+		} else {
+			b, err := charts.NewRightYAxis(child, yo).Render()
+			if err != nil {
+				return nil, err
+			}
+			right += b.Width()
+		}
+	}
+	area.p = canvas.Child(charts.PainterPaddingOption(charts.Box{Bottom: xAxisHeight, Left: left, Right: right}))
+	area.axis = canvas.Child(charts.PainterPaddingOption(charts.Box{Top: canvas.Height() - xAxisHeight, Left: left, Right: right}))
+
+	n := len(opt.XAxis.Data)
+	if !gap {
+		n--
+	}
+	if n < 1 {
+		return area, nil
+	}
+	divs := make([]int, n+1)
+	unit := float64(area.p.Width()) / float64(n)
+	for i := range divs {
+		divs[i] = int(float64(i) * unit)
+	}
+	divs[n] = area.p.Width()
+	area.xs = divs
+	if gap {
+		area.xs = make([]int, n)
+		for i := range area.xs {
+			area.xs[i] = (divs[i] + divs[i+1]) >> 1
+		}
+	}
+	return area, nil
+}
+
+// axisLabel formats a y axis value the way go-charts does.
+func axisLabel(v float64) string {
+	for _, u := range []struct {
+		size   float64
+		suffix string
+	}{{1e12, "T"}, {1e9, "G"}, {1e6, "M"}, {1e3, "k"}} {
+		if v >= u.size {
+			return humanize.CommafWithDigits(v/u.size, 2) + u.suffix
+		}
+	}
+	return humanize.CommafWithDigits(v, 2)
+}
+
+// y is the vertical position of v on the given axis.
+func (a *plotArea) y(axis int, v float64) int {
+	b := a.bounds[axis]
+	h := a.p.Height()
+	if b[1] <= b[0] {
+		return h
+	}
+	return h - int((v-b[0])/(b[1]-b[0])*float64(h))
+}
+
+// fillBand fills b in color, one polygon per run of points with both bounds.
+func (a *plotArea) fillBand(b Band, color Color) {
+	var upper, lower []charts.Point
+	flush := func() {
+		if len(upper) > 1 {
+			slices.Reverse(lower)
+			points := append(append(upper, lower...), upper[0])
+			a.p.SetDrawingStyle(charts.Style{FillColor: color})
+			a.p.FillArea(points)
+		}
+		upper, lower = nil, nil
+	}
+	n := min(len(b.Lower), len(b.Upper), len(a.xs))
+	for i := 0; i < n; i++ {
+		lo, hi := b.Lower[i], b.Upper[i]
+		if lo == NullValue() || hi == NullValue() {
+			flush()
+			continue
+		}
+		upper = append(upper, charts.Point{X: a.xs[i], Y: a.y(b.Axis, hi)})
+		lower = append(lower, charts.Point{X: a.xs[i], Y: a.y(b.Axis, lo)})
+	}
+	flush()
+}
+
+// drawMark draws m on series s: a dot in the series color and the text above
+// it, or below when the dot is near the top.
+func (a *plotArea) drawMark(m Mark, s Series, color, textColor Color) {
+	if m.Index < 0 || m.Index >= len(s.Values) || m.Index >= len(a.xs) || s.Values[m.Index] == NullValue() {
+		return
+	}
+	x, y := a.xs[m.Index], a.y(s.Axis, s.Values[m.Index])
+	a.p.SetDrawingStyle(charts.Style{FillColor: color, StrokeColor: color, StrokeWidth: 1})
+	a.p.Circle(markRadius, x, y)
+	a.p.FillStroke()
+	if m.Text == "" {
+		return
+	}
+	a.p.OverrideTextStyle(charts.Style{FontSize: markFontSize, FontColor: textColor})
+	box := a.p.MeasureText(m.Text)
+	tx := min(max(x-box.Width()/2, 0), a.p.Width()-box.Width())
+	ty := y - markRadius - 4
+	if ty-box.Height() < 0 {
+		ty = y + markRadius + 4 + box.Height()
+	}
+	a.p.Text(m.Text, tx, ty)
+}
+
+// drawRef draws r across the plot in color, its text just above the line, or
+// below when the line is near the top.
+func (a *plotArea) drawRef(r RefLine, color Color) {
+	if _, ok := a.bounds[r.Axis]; !ok {
+		return
+	}
+	y := a.y(r.Axis, r.Value)
+	a.p.SetDrawingStyle(charts.Style{StrokeColor: color, StrokeWidth: 1, StrokeDashArray: refDash})
+	a.p.LineStroke([]charts.Point{{X: 0, Y: y}, {X: a.p.Width(), Y: y}})
+	if r.Text == "" {
+		return
+	}
+	a.p.OverrideTextStyle(charts.Style{FontSize: markFontSize, FontColor: color})
+	box := a.p.MeasureText(r.Text)
+	ty := y - 4
+	if ty-box.Height() < 0 {
+		ty = y + 4 + box.Height()
+	}
+	a.p.Text(r.Text, 4, ty)
+}
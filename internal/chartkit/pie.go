@@ -0,0 +1,39 @@
+package chartkit
+
+import (
+	"github.com/vicanso/go-charts/v2"
+)
+
+// Pie is a pie chart with a centered legend above it.
+type Pie struct {
+	Style
+	Title         string
+	Values        []float64
+	Legend        []string
+	Width, Height int // 0 is 600x400
+}
+
+// Render draws the chart and encodes it.
+func (c Pie) Render() ([]byte, error) {
+	theme := c.Theme
+	if theme == "" {
+		theme = ThemeLight
+	}
+	opts := []charts.OptionFunc{
+		charts.TitleTextOptionFunc(c.Title),
+		charts.LegendOptionFunc(charts.LegendOption{Data: c.Legend, Top: charts.PositionTop}),
+		charts.ThemeOptionFunc(theme),
+		charts.TypeOptionFunc(c.outputType()),
+	}
+	if c.Width > 0 {
+		opts = append(opts, charts.WidthOptionFunc(c.Width))
+	}
+	if c.Height > 0 {
+		opts = append(opts, charts.HeightOptionFunc(c.Height))
+	}
+	p, err := charts.PieRender(c.Values, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return p.Bytes()
+}
@@ -0,0 +1,45 @@
+package chartkit
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/draw"
+	"image/png"
+)
+
+// Stack lays rendered PNG panels out top to bottom in one image, such as a
+// price chart over its indicator. Panels narrower than the widest one are
+// left-aligned on a white background.
+func Stack(panels ...[]byte) ([]byte, error) {
+	if len(panels) == 0 {
+		return nil, errors.New("no panels to stack")
+	}
+	imgs := make([]image.Image, len(panels))
+	width, height := 0, 0
+	for i, p := range panels {
+		img, err := png.Decode(bytes.NewReader(p))
+		if err != nil {
+			return nil, err
+		}
+		imgs[i] = img
+		b := img.Bounds()
+		if b.Dx() > width {
+			width = b.Dx()
+		}
+		height += b.Dy()
+	}
+	canvas := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(canvas, canvas.Bounds(), image.White, image.Point{}, draw.Src)
+	y := 0
+	for _, img := range imgs {
+		b := img.Bounds()
+		draw.Draw(canvas, image.Rect(0, y, b.Dx(), y+b.Dy()), img, b.Min, draw.Over)
+		y += b.Dy()
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, canvas); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
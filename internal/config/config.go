@@ -1,40 +1,303 @@
 package config
 
 import (
-	"log"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
 	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
 )
 
 type Config struct {
 	TelegramToken    string
 	WebhookPublicURL string
-	OpenAIKey        string
-	Port             string
-	DBPath           string
+	// WebhookPathSuffix is appended to /telegram/webhook and to WebhookPublicURL
+	// (empty = the bare path)
+	WebhookPathSuffix string
+	ExtraBots         []BotConfig // more bots served by this process, from EXTRA_BOTS
+	OpenAIKey         string
+	Port              string
+	DBPath            string
+	// DeleteWebhookOnShutdown removes the webhook on SIGTERM so Telegram stops retrying during deploys
+	DeleteWebhookOnShutdown bool
+	LogFormat               string // json (default) or text
+	LogLevel                string // debug, info (default), warn, error
+	HandlerWorkers          int    // concurrent message handlers
+	HandlerQueueSize        int    // pending updates before new ones are dropped
+	PerChatOrder            bool   // serialize handling per chat (default on)
+	TLSCertFile             string // optional; with TLSKeyFile enables HTTPS
+	TLSKeyFile              string
+	TrustProxy              bool   // honor X-Forwarded-For when behind a reverse proxy
+	APIToken                string // comma-separated bearer tokens for the /api endpoints (empty = disabled)
+	APINotifyPerMin         int    // /api/notify requests per token per minute
+	APIChartPerMin          int    // /api/chart and /api/portfolio requests per token per minute
+	PreflightOpenAI         bool   // verify OPENAI_API_KEY at startup (default on)
+	AdminChatID             int64  // chat allowed to run admin commands such as /version (0 = none)
+	TargetExpiryDays        int    // days before a /target expires
+	WeeklyReport            bool   // Monday usage report to ADMIN_CHAT_ID (default off)
+	YahooMinGapMS           int    // minimum gap between Yahoo requests
+	YahooRetryJitterMS      int    // max random delay added to Yahoo retry backoffs
+	RenderWorkers           int    // concurrent chart renders
+	RenderQueueSize         int    // chart renders waiting before new ones are refused
+	RenderTimeoutSec        int    // per-chart render timeout, queue wait included
+	AIDailyLimit            int    // LLM requests per chat per day
+	DemoMode                bool   // synthetic prices instead of Yahoo (default off)
+}
+
+// BotConfig is one bot served by this process.
+type BotConfig struct {
+	Name       string // empty for the primary bot
+	Token      string
+	WebhookURL string // public base URL; empty selects long polling
+	PathSuffix string // appended to the webhook path and URL
+}
+
+// WebhookPath is where the server receives this bot's updates.
+func (b BotConfig) WebhookPath() string {
+	if b.PathSuffix == "" {
+		return "/telegram/webhook"
+	}
+	return "/telegram/webhook/" + b.PathSuffix
+}
+
+// WebhookTarget is the URL registered with Telegram, or "" for long polling.
+func (b BotConfig) WebhookTarget() string {
+	if b.WebhookURL == "" || b.PathSuffix == "" {
+		return b.WebhookURL
+	}
+	return strings.TrimRight(b.WebhookURL, "/") + "/" + b.PathSuffix
+}
+
+// Bots returns the primary bot followed by the extra ones.
+func (c Config) Bots() []BotConfig {
+	primary := BotConfig{Token: c.TelegramToken, WebhookURL: c.WebhookPublicURL, PathSuffix: c.WebhookPathSuffix}
+	return append([]BotConfig{primary}, c.ExtraBots...)
+}
+
+// tokenHash derives a stable, unguessable webhook path suffix from a token.
+func tokenHash(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:8])
+}
+
+var (
+	botNamePattern    = regexp.MustCompile(`^[a-z0-9_]+$`)
+	pathSuffixPattern = regexp.MustCompile(`^[A-Za-z0-9_-]*$`)
+)
+
+// extraBots reads the bots named in EXTRA_BOTS ("staging,canary"). Bot NAME
+// takes BOT_NAME_TOKEN (required), BOT_NAME_WEBHOOK_URL (default
+// WEBHOOK_PUBLIC_URL) and BOT_NAME_WEBHOOK_PATH_SUFFIX (default a hash of its
+// token, so every bot gets its own path).
+func (s *source) extraBots(webhookURL string) []BotConfig {
+	var out []BotConfig
+	for _, name := range strings.Split(s.get("EXTRA_BOTS"), ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		if !botNamePattern.MatchString(name) {
+			s.errs = append(s.errs, fmt.Errorf("EXTRA_BOTS: invalid bot name %q, use lowercase letters, digits and _", name))
+			continue
+		}
+		prefix := "BOT_" + strings.ToUpper(name) + "_"
+		bot := BotConfig{
+			Name:       name,
+			Token:      s.required(prefix + "TOKEN"),
+			WebhookURL: s.getDefault(prefix+"WEBHOOK_URL", webhookURL),
+			PathSuffix: s.get(prefix + "WEBHOOK_PATH_SUFFIX"),
+		}
+		if bot.PathSuffix == "" && bot.Token != "" {
+			bot.PathSuffix = tokenHash(bot.Token)
+		}
+		out = append(out, bot)
+	}
+	return out
+}
+
+// checkBots rejects duplicate names, tokens and webhook paths, and path
+// suffixes that aren't URL-safe.
+func (s *source) checkBots(bots []BotConfig) {
+	names, tokens, paths := map[string]bool{}, map[string]bool{}, map[string]bool{}
+	for _, b := range bots {
+		label := b.Name
+		if label == "" {
+			label = "primary bot"
+		}
+		if !pathSuffixPattern.MatchString(b.PathSuffix) {
+			s.errs = append(s.errs, fmt.Errorf("%s: webhook path suffix %q may only contain letters, digits, _ and -", label, b.PathSuffix))
+		}
+		switch {
+		case names[b.Name]:
+			s.errs = append(s.errs, fmt.Errorf("EXTRA_BOTS: %s is listed twice", label))
+		case b.Token != "" && tokens[b.Token]:
+			s.errs = append(s.errs, fmt.Errorf("%s: token is already used by another bot", label))
+		case paths[b.WebhookPath()]:
+			s.errs = append(s.errs, fmt.Errorf("%s: webhook path %s is already used by another bot", label, b.WebhookPath()))
+		}
+		names[b.Name], tokens[b.Token], paths[b.WebhookPath()] = true, true, true
+	}
+}
+
+// source resolves settings from the environment, *_FILE secrets and an
+// optional config file, in that order of precedence.
+type source struct {
+	file    map[string]string // keys upper-cased to match env names
+	missing []string
+	errs    []error
+}
+
+// loadFile parses a YAML or JSON config file into upper-cased keys.
+// JSON is valid YAML, so one decoder handles both.
+func loadFile(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	raw := map[string]any{}
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	out := make(map[string]string, len(raw))
+	for k, v := range raw {
+		if v == nil {
+			continue
+		}
+		out[strings.ToUpper(k)] = fmt.Sprint(v)
+	}
+	return out, nil
+}
+
+// get returns k from the environment, then from the file named by k_FILE,
+// then from the config file.
+func (s *source) get(k string) string {
+	if v := os.Getenv(k); v != "" {
+		return v
+	}
+	if path := os.Getenv(k + "_FILE"); path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			s.errs = append(s.errs, fmt.Errorf("%s_FILE: %w", k, err))
+			return ""
+		}
+		return strings.TrimSpace(string(data))
+	}
+	return s.file[k]
 }
 
-func mustEnv(k string) string {
-	v := os.Getenv(k)
+// getDefault returns k, or def when unset.
+func (s *source) getDefault(k, def string) string {
+	if v := s.get(k); v != "" {
+		return v
+	}
+	return def
+}
+
+// required returns k and records it as missing when unset.
+func (s *source) required(k string) string {
+	v := s.get(k)
 	if v == "" {
-		log.Fatalf("missing env %s", k)
+		s.missing = append(s.missing, k)
 	}
 	return v
 }
 
-func Load() Config {
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "9095"
+// int reads a positive integer from k, returning def when unset or invalid.
+func (s *source) int(k string, def int) int {
+	v := s.get(k)
+	if v == "" {
+		return def
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n <= 0 {
+		slog.Warn("config: ignoring invalid integer", "key", k, "value", v)
+		return def
 	}
-	dbPath := os.Getenv("DB_PATH")
-	if dbPath == "" {
-		dbPath = "/app/data/chat.db"
+	return n
+}
+
+// int64 reads a chat ID from k; group IDs are negative. Returns 0 when unset or invalid.
+func (s *source) int64(k string) int64 {
+	v := s.get(k)
+	if v == "" {
+		return 0
+	}
+	n, err := strconv.ParseInt(strings.TrimSpace(v), 10, 64)
+	if err != nil {
+		s.errs = append(s.errs, fmt.Errorf("%s: invalid chat ID %q", k, v))
+		return 0
+	}
+	return n
+}
+
+// bool reads a boolean flag from k, returning def when unset.
+func (s *source) bool(k string, def bool) bool {
+	switch strings.ToLower(strings.TrimSpace(s.get(k))) {
+	case "1", "true", "yes", "on":
+		return true
+	case "0", "false", "no", "off":
+		return false
+	default:
+		return def
+	}
+}
+
+// Load reads the configuration. CONFIG_FILE may name a YAML or JSON file whose
+// keys match the env var names (case-insensitive); env vars override it, and
+// any KEY_FILE variant reads KEY from a mounted secret file. All missing
+// required values are reported together.
+func Load() (Config, error) {
+	s := &source{}
+	if path := os.Getenv("CONFIG_FILE"); path != "" {
+		f, err := loadFile(path)
+		if err != nil {
+			return Config{}, fmt.Errorf("config file: %w", err)
+		}
+		s.file = f
+	}
+	cfg := Config{
+		TelegramToken:    s.required("TELEGRAM_BOT_TOKEN"),
+		WebhookPublicURL: s.get("WEBHOOK_PUBLIC_URL"), // empty selects long-polling mode
+		OpenAIKey:        s.required("OPENAI_API_KEY"),
+		Port:             s.getDefault("PORT", "9095"),
+		DBPath:           s.getDefault("DB_PATH", "/app/data/chat.db"),
+
+		WebhookPathSuffix: s.get("WEBHOOK_PATH_SUFFIX"),
+
+		DeleteWebhookOnShutdown: s.bool("DELETE_WEBHOOK_ON_SHUTDOWN", false),
+		LogFormat:               s.get("LOG_FORMAT"),
+		LogLevel:                s.get("LOG_LEVEL"),
+		HandlerWorkers:          s.int("HANDLER_WORKERS", 16),
+		HandlerQueueSize:        s.int("HANDLER_QUEUE_SIZE", 256),
+		PerChatOrder:            s.bool("PER_CHAT_ORDER", true),
+		TLSCertFile:             s.get("TLS_CERT_FILE"),
+		TLSKeyFile:              s.get("TLS_KEY_FILE"),
+		TrustProxy:              s.bool("TRUST_PROXY", false),
+		APIToken:                s.get("API_TOKEN"),
+		APINotifyPerMin:         s.int("API_NOTIFY_PER_MIN", 20),
+		APIChartPerMin:          s.int("API_CHART_PER_MIN", 30),
+		PreflightOpenAI:         s.bool("PREFLIGHT_OPENAI", true),
+		AdminChatID:             s.int64("ADMIN_CHAT_ID"),
+		TargetExpiryDays:        s.int("TARGET_EXPIRY_DAYS", 30),
+		WeeklyReport:            s.bool("WEEKLY_REPORT", false),
+		YahooMinGapMS:           s.int("YAHOO_MIN_GAP_MS", 150),
+		YahooRetryJitterMS:      s.int("YAHOO_RETRY_JITTER_MS", 250),
+		RenderWorkers:           s.int("RENDER_WORKERS", 2),
+		RenderQueueSize:         s.int("RENDER_QUEUE_SIZE", 16),
+		RenderTimeoutSec:        s.int("RENDER_TIMEOUT_SEC", 30),
+		AIDailyLimit:            s.int("AI_DAILY_LIMIT", 50),
+		DemoMode:                s.bool("DEMO_MODE", false),
 	}
-	return Config{
-		TelegramToken:    mustEnv("TELEGRAM_BOT_TOKEN"),
-		WebhookPublicURL: mustEnv("WEBHOOK_PUBLIC_URL"),
-		OpenAIKey:        mustEnv("OPENAI_API_KEY"),
-		Port:             port,
-		DBPath:           dbPath,
+	cfg.ExtraBots = s.extraBots(cfg.WebhookPublicURL)
+	s.checkBots(cfg.Bots())
+	if len(s.missing) > 0 {
+		s.errs = append(s.errs, fmt.Errorf("missing required values: %s", strings.Join(s.missing, ", ")))
 	}
+	return cfg, errors.Join(s.errs...)
 }
@@ -0,0 +1,147 @@
+package finance
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"telegramBotTrade/internal/chartkit"
+)
+
+// Intraday chart anchors accepted in RenderOptions.Anchor. An anchored chart
+// shows only the latest session, in exchange time.
+const (
+	AnchorOpen      = "open"      // the regular session from the 09:30 open
+	AnchorPre       = "pre"       // the whole day from 04:00, premarket included
+	AnchorPrevClose = "prevclose" // the regular session against the previous close
+)
+
+// Anchors lists the accepted RenderOptions.Anchor values.
+var Anchors = []string{AnchorOpen, AnchorPre, AnchorPrevClose}
+
+// anchorRange is the Yahoo range fetched for an anchored chart: enough to
+// reach the previous session across a long weekend.
+const anchorRange = "5d"
+
+const (
+	preMarketOpen = 4 * 3600       // 04:00, seconds into the exchange day
+	regularOpen   = 9*3600 + 30*60 // 09:30
+	regularClose  = 16 * 3600      // 16:00
+	daySeconds    = int64(24 * 3600)
+)
+
+// AnchorLabel names an anchor in chart titles and captions, e.g. SINCE OPEN.
+func AnchorLabel(anchor string) string {
+	switch anchor {
+	case AnchorOpen:
+		return "SINCE OPEN"
+	case AnchorPre:
+		return "SINCE PREMARKET"
+	case AnchorPrevClose:
+		return "VS PREV CLOSE"
+	}
+	return strings.ToUpper(anchor)
+}
+
+// regularSession is b's regular session on the exchange day day (days since
+// the epoch in exchange time): the session Yahoo reported when it falls on
+// that day, otherwise 09:30 to 16:00 checked against the US calendar. Zero on
+// a holiday.
+func regularSession(b bars, day int64) tradingPeriod {
+	off := int64(b.gmtOffset)
+	if p := b.session; p.end > p.start && (p.start+off)/daySeconds == day {
+		return p
+	}
+	start := day*daySeconds - off
+	return tradingPeriod{start: start + regularOpen, end: start + regularClose}.withUSCalendar()
+}
+
+// between returns the bars of b starting in [from, to).
+func (b bars) between(from, to int64) bars {
+	lo, _ := slices.BinarySearch(b.ts, from)
+	hi, _ := slices.BinarySearch(b.ts, to)
+	cut := func(col []float64) []float64 {
+		if col == nil {
+			return nil
+		}
+		return col[lo:hi]
+	}
+	return bars{ts: b.ts[lo:hi], open: cut(b.open), high: cut(b.high), low: cut(b.low), close: cut(b.close),
+		volume: cut(b.volume), gmtOffset: b.gmtOffset, session: b.session}
+}
+
+// anchorBars keeps the bars of the latest session with at least two bars for
+// anchor. For AnchorPrevClose it also returns the last close of the regular
+// session before it; 0 otherwise.
+func anchorBars(b bars, anchor string) (bars, float64, error) {
+	if !slices.Contains(Anchors, anchor) {
+		return bars{}, 0, invalidInput("unknown anchor %s (%s)", anchor, strings.Join(Anchors, ", "))
+	}
+	if len(b.ts) == 0 {
+		return bars{}, 0, ErrNoData
+	}
+	off := int64(b.gmtOffset)
+	first := (b.ts[0] + off) / daySeconds
+	for day := (b.ts[len(b.ts)-1] + off) / daySeconds; day >= first; day-- {
+		var out bars
+		reg := regularSession(b, day)
+		if anchor == AnchorPre {
+			start := day*daySeconds - off
+			out = b.between(start+preMarketOpen, start+daySeconds)
+		} else if reg.end > reg.start {
+			out = b.between(reg.start, reg.end)
+		}
+		if len(out.ts) < 2 {
+			continue
+		}
+		if anchor != AnchorPrevClose {
+			return out, 0, nil
+		}
+		prev, ok := previousClose(b, day-1, first)
+		if !ok {
+			return bars{}, 0, noData("no previous close in the data")
+		}
+		return out, prev, nil
+	}
+	if anchor == AnchorPre {
+		return bars{}, 0, noData("no session since premarket in the data")
+	}
+	return bars{}, 0, noData("no regular session in the data")
+}
+
+// previousClose is the last close of the latest regular session on or before
+// day and not before first.
+func previousClose(b bars, day, first int64) (float64, bool) {
+	for ; day >= first; day-- {
+		reg := regularSession(b, day)
+		if reg.end <= reg.start {
+			continue
+		}
+		if s := b.between(reg.start, reg.end); len(s.close) > 0 {
+			return s.close[len(s.close)-1], true
+		}
+	}
+	return 0, false
+}
+
+// prevCloseRef is the dashed line an AnchorPrevClose chart draws at prev.
+func prevCloseRef(prev float64) chartkit.RefLine {
+	return chartkit.RefLine{Value: prev, Text: fmt.Sprintf("Prev close %.2f", prev)}
+}
+
+// errAnchorInterval is returned for an anchored chart of daily bars.
+var errAnchorInterval = invalidInput("an anchored chart needs an intraday interval")
+
+// anchorMeta measures the change of an AnchorPrevClose chart from prev, the
+// previous close, instead of the first bar, and says so in the note.
+func anchorMeta(meta *ChartMeta, prev float64) {
+	if prev <= 0 {
+		return
+	}
+	meta.Change = (meta.LastPrice/prev - 1) * 100
+	note := fmt.Sprintf("Change against the previous close %.2f", prev)
+	if meta.Note != "" {
+		note = meta.Note + "\n" + note
+	}
+	meta.Note = note
+}
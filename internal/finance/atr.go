@@ -0,0 +1,100 @@
+package finance
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"telegramBotTrade/internal/chartkit"
+)
+
+const (
+	// DefaultATRPeriod is the usual 14-bar ATR lookback.
+	DefaultATRPeriod = 14
+	// atrStopMultiple is k in the close ± k·ATR stop lines.
+	atrStopMultiple = 3.0
+)
+
+// ATRSummary is the latest ATR reading shown in the /atr caption.
+type ATRSummary struct {
+	Period    int
+	Price     float64
+	ATR       float64
+	Pct       float64 // ATR as a percentage of Price
+	LongStop  float64 // Price - k·ATR
+	ShortStop float64 // Price + k·ATR
+	Multiple  float64
+}
+
+// MakeATRChart renders daily closes over window with stop lines at close ± k·ATR,
+// where ATR is Wilder's average true range over period days.
+func MakeATRChart(ctx context.Context, symbol string, period int, window string, opts RenderOptions) ([]byte, *ATRSummary, error) {
+	if period < 2 {
+		return nil, nil, errors.New("ATR period must be at least 2")
+	}
+	_, rng, _ := normalizeIntervalWindow("1d", window)
+	b, err := fetchBars(ctx, symbol, "1d", rng)
+	if err != nil {
+		return nil, nil, err
+	}
+	if b.high == nil || b.low == nil {
+		return nil, nil, errors.New("no high/low data for this symbol")
+	}
+	// Bars with missing highs or lows come back as zero.
+	var ts []int64
+	var hi, lo, cl []float64
+	for i := range b.ts {
+		if b.high[i] <= 0 || b.low[i] <= 0 {
+			continue
+		}
+		ts = append(ts, b.ts[i])
+		hi = append(hi, b.high[i])
+		lo = append(lo, b.low[i])
+		cl = append(cl, b.close[i])
+	}
+	if len(cl) < period+2 {
+		return nil, nil, classify(ErrNoData, fmt.Errorf("not enough data: ATR(%d) needs at least %d daily bars, got %d", period, period+2, len(cl)))
+	}
+	a := atr(hi, lo, cl, period)
+	s := period - 1
+	ts, cl, a = ts[s:], cl[s:], a[s:]
+
+	k := atrStopMultiple
+	long := make([]float64, len(cl))
+	short := make([]float64, len(cl))
+	for i := range cl {
+		long[i] = cl[i] - k*a[i]
+		short[i] = cl[i] + k*a[i]
+	}
+	n := len(cl) - 1
+	sum := &ATRSummary{
+		Period:    period,
+		Price:     cl[n],
+		ATR:       a[n],
+		Pct:       a[n] / cl[n] * 100,
+		LongStop:  long[n],
+		ShortStop: short[n],
+		Multiple:  k,
+	}
+
+	loc := opts.location()
+	x := make([]string, len(ts))
+	for i := range ts {
+		x[i] = time.Unix(ts[i], 0).In(loc).Format("2006-01-02")
+	}
+	yMin, yMax := paddedRange(append(append([]float64{}, long...), short...))
+	img, err := renderChart(ctx, chartkit.Line{
+		Style:  opts.style(),
+		Title:  fmt.Sprintf("%s • ATR(%d) %.2f (%.2f%%) • %s", strings.ToUpper(symbol), period, sum.ATR, sum.Pct, strings.ToUpper(rng)),
+		Series: chartkit.Lines(cl, long, short),
+		Legend: chartkit.Legend{Labels: []string{"Close", fmt.Sprintf("-%gATR", k), fmt.Sprintf("+%gATR", k)}},
+		X:      chartkit.XAxis{Labels: x, Split: 10},
+		Y:      []chartkit.YAxis{{Min: &yMin, Max: &yMax, Divide: 5}},
+	}.Render)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to render chart: %w", err)
+	}
+	return img, sum, nil
+}
@@ -0,0 +1,55 @@
+package finance
+
+import (
+	"context"
+	"time"
+)
+
+// Bar is one fetched bar in the exchange's time zone. Open, High, Low and
+// Volume are zero when the series has no such column (see BarSeries).
+type Bar struct {
+	Time                   time.Time
+	Open, High, Low, Close float64
+	Volume                 float64
+}
+
+// BarSeries is a symbol's bars for one interval and range.
+type BarSeries struct {
+	Interval string // after normalization, e.g. "1d"
+	Range    string // Yahoo range actually fetched, e.g. "3mo"
+	Bars     []Bar
+	OHLC     bool // open/high/low present (false on the spark fallback)
+	Volume   bool
+}
+
+// FetchBars fetches symbol's bars, applying the same interval/window
+// normalization and clamping as the custom chart commands.
+func FetchBars(ctx context.Context, symbol, interval, window string) (*BarSeries, error) {
+	interval, rng, _ := normalizeIntervalWindow(interval, window)
+	b, err := fetchBars(ctx, symbol, interval, rng)
+	if err != nil {
+		return nil, err
+	}
+	if len(b.ts) == 0 {
+		return nil, noData("no data points")
+	}
+	loc := time.FixedZone("", b.gmtOffset)
+	s := &BarSeries{
+		Interval: interval,
+		Range:    rng,
+		Bars:     make([]Bar, len(b.ts)),
+		OHLC:     b.open != nil && b.high != nil && b.low != nil,
+		Volume:   b.volume != nil,
+	}
+	for i := range b.ts {
+		bar := Bar{Time: time.Unix(b.ts[i], 0).In(loc), Close: b.close[i]}
+		if s.OHLC {
+			bar.Open, bar.High, bar.Low = b.open[i], b.high[i], b.low[i]
+		}
+		if s.Volume {
+			bar.Volume = b.volume[i]
+		}
+		s.Bars[i] = bar
+	}
+	return s, nil
+}
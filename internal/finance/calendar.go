@@ -0,0 +1,146 @@
+package finance
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// EconomicEvent is one scheduled macro release.
+type EconomicEvent struct {
+	Time     time.Time `json:"time"`
+	Title    string    `json:"title"`
+	Country  string    `json:"country"`
+	Impact   string    `json:"impact"`
+	Forecast string    `json:"forecast,omitempty"`
+	Previous string    `json:"previous,omitempty"`
+}
+
+// CalendarProvider returns this week's economic releases.
+type CalendarProvider interface {
+	Name() string
+	Events(ctx context.Context) ([]EconomicEvent, error)
+}
+
+// CalendarCache persists a provider's raw result for a day so the source is
+// fetched at most once per day across restarts.
+type CalendarCache interface {
+	LoadCalendar(source, day string) ([]byte, bool, error)
+	SaveCalendar(source, day string, payload []byte) error
+}
+
+// faireconomyCalendarURL is the free weekly JSON feed behind ForexFactory's calendar.
+const faireconomyCalendarURL = "https://nfs.faireconomy.media/ff_calendar_thisweek.json"
+
+// FaireconomyCalendar reads the public ForexFactory weekly JSON feed.
+type FaireconomyCalendar struct {
+	URL string // defaults to the public feed
+}
+
+func (FaireconomyCalendar) Name() string { return "faireconomy" }
+
+// Events fetches every release in the current week, all countries and impacts.
+func (c FaireconomyCalendar) Events(ctx context.Context) ([]EconomicEvent, error) {
+	url := c.URL
+	if url == "" {
+		url = faireconomyCalendarURL
+	}
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.4 Safari/605.1.15")
+	req.Header.Set("Accept", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 4<<20))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("calendar feed returned %d", resp.StatusCode)
+	}
+	var raw []struct {
+		Title    string `json:"title"`
+		Country  string `json:"country"`
+		Date     string `json:"date"`
+		Impact   string `json:"impact"`
+		Forecast string `json:"forecast"`
+		Previous string `json:"previous"`
+	}
+	if err := json.Unmarshal(body, &raw); err != nil {
+		return nil, fmt.Errorf("decode calendar feed: %w", err)
+	}
+	out := make([]EconomicEvent, 0, len(raw))
+	for _, r := range raw {
+		t, err := time.Parse(time.RFC3339, r.Date)
+		if err != nil {
+			continue
+		}
+		out = append(out, EconomicEvent{
+			Time:     t,
+			Title:    r.Title,
+			Country:  r.Country,
+			Impact:   r.Impact,
+			Forecast: r.Forecast,
+			Previous: r.Previous,
+		})
+	}
+	return out, nil
+}
+
+type cachedCalendar struct {
+	p     CalendarProvider
+	cache CalendarCache
+}
+
+// CachedCalendar wraps p so each UTC day's result is stored in cache and reused.
+// Cache read or write failures fall through to the provider.
+func CachedCalendar(p CalendarProvider, cache CalendarCache) CalendarProvider {
+	return cachedCalendar{p: p, cache: cache}
+}
+
+func (c cachedCalendar) Name() string { return c.p.Name() }
+
+func (c cachedCalendar) Events(ctx context.Context) ([]EconomicEvent, error) {
+	day := time.Now().UTC().Format("2006-01-02")
+	if payload, ok, err := c.cache.LoadCalendar(c.p.Name(), day); err == nil && ok {
+		var events []EconomicEvent
+		if err := json.Unmarshal(payload, &events); err == nil {
+			return events, nil
+		}
+	}
+	events, err := c.p.Events(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if payload, err := json.Marshal(events); err == nil {
+		_ = c.cache.SaveCalendar(c.p.Name(), day, payload)
+	}
+	return events, nil
+}
+
+// HighImpactUS keeps the high-impact USD releases (CPI, FOMC, NFP, GDP, ...)
+// between from and to, sorted by time.
+func HighImpactUS(events []EconomicEvent, from, to time.Time) []EconomicEvent {
+	var out []EconomicEvent
+	for _, e := range events {
+		if !strings.EqualFold(e.Country, "USD") || !strings.EqualFold(e.Impact, "High") {
+			continue
+		}
+		if e.Time.Before(from) || !e.Time.Before(to) {
+			continue
+		}
+		out = append(out, e)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Time.Before(out[j].Time) })
+	return out
+}
@@ -1,30 +1,85 @@
 package finance
 
 import (
+	"context"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
 var (
 	chartCache   = map[string]chartCacheEntry{}
 	chartCacheMu sync.Mutex
+	chartLookups cacheCounter
 )
 
-func cacheGet(key string) ([]byte, bool) {
+// CacheStats counts the lookups of an in-memory cache since the process
+// started.
+type CacheStats struct {
+	Hits, Misses int64
+}
+
+// HitRate is the share of lookups served from the cache, in percent; 0
+// before the first lookup.
+func (c CacheStats) HitRate() float64 {
+	if n := c.Hits + c.Misses; n > 0 {
+		return float64(c.Hits) / float64(n) * 100
+	}
+	return 0
+}
+
+type cacheCounter struct {
+	hits, misses atomic.Int64
+}
+
+func (c *cacheCounter) record(hit bool) {
+	if hit {
+		c.hits.Add(1)
+	} else {
+		c.misses.Add(1)
+	}
+}
+
+func (c *cacheCounter) stats() CacheStats {
+	return CacheStats{Hits: c.hits.Load(), Misses: c.misses.Load()}
+}
+
+// ChartCacheStats reports the lookups of the rendered chart cache.
+func ChartCacheStats() CacheStats { return chartLookups.stats() }
+
+// cacheGet returns a cached chart and replays its data time and missing bars
+// into ctx's Freshness.
+func cacheGet(ctx context.Context, key string) (ChartResult, bool) {
+	if seriesSourceFrom(ctx) != nil {
+		return ChartResult{}, false
+	}
 	chartCacheMu.Lock()
 	defer chartCacheMu.Unlock()
 	if entry, ok := chartCache[key]; ok {
 		if time.Now().Before(entry.createdAt.Add(chartCacheTTL)) {
 			img := make([]byte, len(entry.image))
 			copy(img, entry.image)
-			return img, true
+			noteLastBar(ctx, entry.asOf)
+			noteMissingBars(ctx, entry.gap.symbol, entry.gap.share)
+			chartLookups.record(true)
+			return ChartResult{Image: img, Meta: entry.meta}, true
 		}
 	}
-	return nil, false
+	chartLookups.record(false)
+	return ChartResult{}, false
 }
 
-func cacheSet(key string, img []byte) {
+// cacheSet stores res with the data time and missing bars recorded in ctx's
+// Freshness, if any.
+func cacheSet(ctx context.Context, key string, res ChartResult) {
+	if seriesSourceFrom(ctx) != nil {
+		return
+	}
+	f := freshnessFrom(ctx)
+	asOf := f.AsOf()
+	var gap missingNote
+	gap.symbol, gap.share = f.MissingBars()
 	chartCacheMu.Lock()
-	chartCache[key] = chartCacheEntry{createdAt: time.Now(), image: img}
+	chartCache[key] = chartCacheEntry{createdAt: time.Now(), image: res.Image, asOf: asOf, gap: gap, meta: res.Meta}
 	chartCacheMu.Unlock()
 }
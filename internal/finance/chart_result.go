@@ -0,0 +1,61 @@
+package finance
+
+import "time"
+
+// ChartMeta describes what a rendered chart shows, so handlers can build
+// captions and follow-ups without recomputing it. Single-value fields
+// (LastPrice, Change) describe the first symbol, or the portfolio on
+// portfolio charts.
+type ChartMeta struct {
+	Symbols   []string           // symbols drawn, in legend order
+	Interval  string             // bar interval shown, after normalization and resampling, e.g. "5m", "1wk"
+	Window    string             // Yahoo range fetched, e.g. "5d", "6mo"
+	LastPrice float64            // last close (portfolio value on portfolio charts)
+	Change    float64            // change over the window in percent
+	Changes   map[string]float64 // per-symbol window change on multi-symbol charts
+	Points    int                // points drawn per series
+	AsOf      time.Time          // time of the last bar drawn
+	Note      string             // why a requested overlay was left out, if it was
+	Skipped   []SkippedSymbol
+
+	// Portfolio is set on portfolio charts.
+	Portfolio *PortfolioStats
+	// Contributions is set by detail portfolio charts too large to draw every asset.
+	Contributions []AssetContribution
+}
+
+// ChartResult is a rendered chart with its metadata.
+type ChartResult struct {
+	Image []byte
+	Meta  ChartMeta
+}
+
+// seriesMeta fills the single-series fields of a chart's metadata from the
+// closes and timestamps drawn.
+func seriesMeta(symbol, interval, window string, ts []int64, cl []float64) ChartMeta {
+	m := ChartMeta{Symbols: []string{symbol}, Interval: interval, Window: window, Points: len(cl)}
+	if n := len(cl); n > 0 {
+		m.LastPrice = cl[n-1]
+		m.Change = windowReturn(cl)
+	}
+	if n := len(ts); n > 0 {
+		m.AsOf = time.Unix(ts[n-1], 0)
+	}
+	return m
+}
+
+// multiMeta fills the metadata of a multi-symbol chart from the names and
+// window returns of its lines, in legend order.
+func multiMeta(names []string, rets []float64, interval, window string, points int, last int64, skipped []SkippedSymbol) ChartMeta {
+	m := ChartMeta{Symbols: names, Interval: interval, Window: window, Points: points, Skipped: skipped, Changes: make(map[string]float64, len(names))}
+	for i, s := range names {
+		m.Changes[s] = rets[i]
+	}
+	if len(rets) > 0 {
+		m.Change = rets[0]
+	}
+	if last != 0 {
+		m.AsOf = time.Unix(last, 0)
+	}
+	return m
+}
@@ -1,20 +1,26 @@
 package finance
 
 import (
-	"errors"
-	"fmt"
+	"context"
 	"sort"
 	"strings"
 	"time"
 
-	"github.com/vicanso/go-charts/v2"
+	"telegramBotTrade/internal/chartkit"
 )
 
-// Make5mChart generates a 5-minute chart for the given symbol and time window (1d,1w,1m)
-func Make5mChart(symbol string, window ...string) ([]byte, error) {
+// miniRanges maps the windows of the 5m mini charts to Yahoo ranges.
+var miniRanges = map[string]string{"1d": "1d", "1w": "5d", "1m": "1mo"}
+
+// MiniChartWindows lists the windows of the 5m mini charts, shortest first.
+var MiniChartWindows = []string{"1d", "1w", "1m"}
+
+// Make5mChart generates a 5-minute chart for the given symbol and time window (1d,1w,1m).
+// Meta.Note explains an overlay that was requested but left out.
+func Make5mChart(ctx context.Context, symbol string, window string, opts RenderOptions) (ChartResult, error) {
 	w := "1d"
-	if len(window) > 0 && window[0] != "" {
-		switch strings.ToLower(strings.TrimSpace(window[0])) {
+	if window != "" {
+		switch strings.ToLower(strings.TrimSpace(window)) {
 		case "1d", "day", "1day":
 			w = "1d"
 		case "1w", "1wk", "week", "1week":
@@ -23,28 +29,57 @@ func Make5mChart(symbol string, window ...string) ([]byte, error) {
 			w = "1m"
 		}
 	}
-	rangeParam := map[string]string{"1d": "1d", "1w": "5d", "1m": "1mo"}[w]
+	rangeParam := miniRanges[w]
+	span := strings.ToUpper(w)
+	if opts.Anchor != "" {
+		w, rangeParam, span = "1d", anchorRange, AnchorLabel(opts.Anchor)
+	}
 
 	// cache
-	cacheKey := strings.ToUpper(symbol) + "|" + w
-	if img, ok := cacheGet(cacheKey); ok {
-		return img, nil
+	cacheKey := strings.ToUpper(symbol) + "|" + w + opts.cacheSuffix()
+	if res, ok := cacheGet(ctx, cacheKey); ok {
+		return res, nil
 	}
 
-	ts, cl, err := fetch5mSeries(symbol, rangeParam)
+	b, err := fetchBars(ctx, symbol, "5m", rangeParam)
 	if err != nil {
-		return nil, err
+		return ChartResult{}, err
 	}
+	var prev float64
+	if opts.Anchor != "" {
+		if b, prev, err = anchorBars(b, opts.Anchor); err != nil {
+			return ChartResult{}, err
+		}
+	}
+	ts, cl := b.ts, b.close
 	if len(ts) == 0 || len(cl) == 0 {
-		return nil, errors.New("no data")
+		return ChartResult{}, ErrNoData
+	}
+	chart := chartkit.Line{
+		Style:  opts.style(),
+		Title:  strings.ToUpper(symbol) + " • 5m • " + span,
+		Series: []chartkit.Series{{Values: cl}},
+	}
+	var note string
+	if opts.VWAP {
+		var vwap []float64
+		if vwap, note = vwapOverlay(b); vwap != nil {
+			chart.Series = append(chart.Series, chartkit.Series{Values: vwap})
+			chart.Legend.Labels = []string{strings.ToUpper(symbol), "VWAP"}
+		}
+	}
+	if prev > 0 {
+		chart.Refs = []chartkit.RefLine{prevCloseRef(prev)}
 	}
 
 	// build labels and y-range
-	et := getEasternTime()
+	et := opts.location()
 	xAll := make([]string, len(ts))
+	times := make([]time.Time, len(ts))
 	var yMin, yMax float64
 	for i, t := range ts {
 		tt := time.Unix(t, 0).UTC().In(et)
+		times[i] = tt
 		if w == "1d" {
 			xAll[i] = tt.Format("15:04")
 		} else {
@@ -63,7 +98,7 @@ func Make5mChart(symbol string, window ...string) ([]byte, error) {
 		}
 	}
 	if len(cl) < 2 {
-		return nil, errors.New("not enough data points")
+		return ChartResult{}, noData("not enough data points")
 	}
 	pad := (yMax - yMin) * 0.05
 	if pad < yMax*0.002 {
@@ -74,33 +109,30 @@ func Make5mChart(symbol string, window ...string) ([]byte, error) {
 		yMin = 0
 	}
 	yMax += pad
-	split := map[string]int{"1d": 8, "1w": 7, "1m": 10}[w]
-
-	painter, err := charts.LineRender([][]float64{cl},
-		charts.TitleTextOptionFunc(strings.ToUpper(symbol)+" • 5m • "+strings.ToUpper(w)),
-		charts.XAxisOptionFunc(charts.XAxisOption{Data: xAll, BoundaryGap: charts.FalseFlag(), SplitNumber: split}),
-		charts.YAxisOptionFunc(charts.YAxisOption{Min: &yMin, Max: &yMax, DivideCount: 5}),
-		charts.ThemeOptionFunc(charts.ThemeLight),
-	)
-	if err != nil {
-		return nil, err
-	}
-	img, err := painter.Bytes()
+	chart.X = chartkit.XAxis{Labels: xAll, Split: map[string]int{"1d": 8, "1w": 7, "1m": 10}[w], Times: times}
+	chart.Y = []chartkit.YAxis{{Min: &yMin, Max: &yMax, Divide: 5}}
+	img, err := renderChart(ctx, chart.Render)
 	if err != nil {
-		return nil, err
+		return ChartResult{}, err
 	}
-	cacheSet(cacheKey, img)
-	return img, nil
+	meta := seriesMeta(strings.ToUpper(symbol), "5m", rangeParam, ts, cl)
+	meta.Note = note
+	anchorMeta(&meta, prev)
+	res := ChartResult{Image: img, Meta: meta}
+	cacheSet(ctx, cacheKey, res)
+	return res, nil
 }
 
 // MakeMulti5mChart renders multiple symbols in one chart with legends and two y-axes if needed.
-func MakeMulti5mChart(symbols []string, window ...string) ([]byte, error) {
+// Symbols that fail to fetch are left out and listed in Meta.Skipped, as long as
+// two remain.
+func MakeMulti5mChart(ctx context.Context, symbols []string, window string, opts RenderOptions) (ChartResult, error) {
 	if len(symbols) == 0 {
-		return nil, errors.New("no symbols provided")
+		return ChartResult{}, invalidInput("no symbols provided")
 	}
 	w := "1d"
-	if len(window) > 0 && window[0] != "" {
-		switch strings.ToLower(strings.TrimSpace(window[0])) {
+	if window != "" {
+		switch strings.ToLower(strings.TrimSpace(window)) {
 		case "1d", "day", "1day":
 			w = "1d"
 		case "1w", "1wk", "week", "1week":
@@ -109,28 +141,13 @@ func MakeMulti5mChart(symbols []string, window ...string) ([]byte, error) {
 			w = "1m"
 		}
 	}
-	rangeParam := map[string]string{"1d": "1d", "1w": "5d", "1m": "1mo"}[w]
+	rangeParam := miniRanges[w]
 
-	type sd struct {
-		sym string
-		ts  []int64
-		cl  []float64
-	}
-	arr := make([]sd, 0, len(symbols))
-	for _, s := range symbols {
-		s = strings.TrimSpace(s)
-		if s == "" {
-			continue
-		}
-		ts, cl, err := fetch5mSeries(s, rangeParam)
-		if err != nil {
-			return nil, fmt.Errorf("%s: %w", s, err)
-		}
-		arr = append(arr, sd{sym: strings.ToUpper(s), ts: ts, cl: cl})
-		time.Sleep(120 * time.Millisecond)
-	}
-	if len(arr) == 0 {
-		return nil, errors.New("no series fetched")
+	arr, skipped, err := fetchSymbols(symbols, 2, func(symbol string) ([]int64, []float64, error) {
+		return fetch5mSeries(ctx, symbol, rangeParam)
+	})
+	if err != nil {
+		return ChartResult{Meta: ChartMeta{Skipped: skipped}}, err
 	}
 
 	// intersect timestamps across all series
@@ -147,15 +164,17 @@ func MakeMulti5mChart(symbols []string, window ...string) ([]byte, error) {
 		}
 	}
 	if len(common) < 2 {
-		return nil, errors.New("not enough overlapping time points")
+		return ChartResult{}, noData("not enough overlapping time points")
 	}
 	sort.Slice(common, func(i, j int) bool { return common[i] < common[j] })
 
 	// labels
-	et := getEasternTime()
+	et := opts.location()
 	xLabels := make([]string, len(common))
+	xTimes := make([]time.Time, len(common))
 	for i, t := range common {
 		tt := time.Unix(t, 0).UTC().In(et)
+		xTimes[i] = tt
 		if w == "1d" {
 			xLabels[i] = tt.Format("15:04")
 		} else {
@@ -166,6 +185,7 @@ func MakeMulti5mChart(symbols []string, window ...string) ([]byte, error) {
 	// build aligned values
 	values := make([][]float64, 0, len(arr))
 	names := make([]string, 0, len(arr))
+	rets := make([]float64, 0, len(arr))
 	normalized := len(arr) > 2
 	var leftMin, leftMax, rightMin, rightMax *float64
 	var commonMin, commonMax *float64
@@ -183,6 +203,7 @@ func MakeMulti5mChart(symbols []string, window ...string) ([]byte, error) {
 			}
 		}
 		cl := aligned
+		rets = append(rets, windowReturn(aligned))
 		if normalized {
 			base := 0.0
 			for _, v := range cl {
@@ -250,17 +271,20 @@ func MakeMulti5mChart(symbols []string, window ...string) ([]byte, error) {
 	}
 
 	split := map[string]int{"1d": 8, "1w": 7, "1m": 10}[w]
-	seriesList := charts.NewSeriesListDataFromValues(values, charts.ChartTypeLine)
-	for i := range seriesList {
-		seriesList[i].Name = names[i]
-		if normalized {
-			seriesList[i].AxisIndex = 0
-		} else {
-			seriesList[i].AxisIndex = i % 2
+	series := make([]chartkit.Series, len(values))
+	for i := range series {
+		series[i] = chartkit.Series{Name: names[i], Values: values[i]}
+		if !normalized {
+			series[i].Axis = i % 2
 		}
 	}
-	var painter *charts.Painter
-	var err error
+	chart := symbolLines{
+		title:   "Multi • 5m • " + strings.ToUpper(w),
+		syms:    names,
+		returns: rets,
+		series:  series,
+		xAxis:   chartkit.XAxis{Labels: xLabels, Split: split, Times: xTimes},
+	}
 	if normalized {
 		var yMin, yMax *float64
 		if commonMin != nil && commonMax != nil {
@@ -270,27 +294,14 @@ func MakeMulti5mChart(symbols []string, window ...string) ([]byte, error) {
 			yMin = &vmin
 			yMax = &vmax
 		}
-		painter, err = charts.Render(charts.ChartOption{SeriesList: seriesList},
-			charts.TitleTextOptionFunc("Multi • 5m • "+strings.ToUpper(w), strings.Join(names, ", ")+" • normalized %"),
-			charts.XAxisOptionFunc(charts.XAxisOption{Data: xLabels, BoundaryGap: charts.FalseFlag(), SplitNumber: split}),
-			charts.YAxisOptionFunc(charts.YAxisOption{Min: yMin, Max: yMax, DivideCount: 5}),
-			charts.LegendOptionFunc(charts.LegendOption{Data: names}),
-			charts.ThemeOptionFunc(charts.ThemeLight),
-		)
+		chart.subtitle = "normalized %"
+		chart.yAxes = []chartkit.YAxis{{Min: yMin, Max: yMax, Divide: 5}}
 	} else {
-		painter, err = charts.Render(charts.ChartOption{SeriesList: seriesList},
-			charts.TitleTextOptionFunc("Multi • 5m • "+strings.ToUpper(w), strings.Join(names, ", ")),
-			charts.XAxisOptionFunc(charts.XAxisOption{Data: xLabels, BoundaryGap: charts.FalseFlag(), SplitNumber: split}),
-			charts.YAxisOptionFunc(
-				charts.YAxisOption{Min: leftMin, Max: leftMax, DivideCount: 5},
-				charts.YAxisOption{Min: rightMin, Max: rightMax, DivideCount: 5, Position: charts.PositionRight},
-			),
-			charts.LegendOptionFunc(charts.LegendOption{Data: names}),
-			charts.ThemeOptionFunc(charts.ThemeLight),
-		)
-	}
-	if err != nil {
-		return nil, err
+		chart.yAxes = []chartkit.YAxis{
+			{Min: leftMin, Max: leftMax, Divide: 5},
+			{Min: rightMin, Max: rightMax, Divide: 5},
+		}
 	}
-	return painter.Bytes()
+	img, err := renderChart(ctx, chart.line(opts).Render)
+	return ChartResult{Image: img, Meta: multiMeta(names, rets, "5m", rangeParam, len(common), common[len(common)-1], skipped)}, err
 }
@@ -1,17 +1,46 @@
 package finance
 
 import (
-	"errors"
+	"context"
 	"fmt"
+	"slices"
 	"sort"
 	"strings"
 	"time"
 
-	"github.com/vicanso/go-charts/v2"
+	"telegramBotTrade/internal/chartkit"
 )
 
-// normalizeIntervalWindow clamps and maps to Yahoo-supported ranges given interval constraints.
-func normalizeIntervalWindow(intervalIn, windowIn string) (interval string, rangeParam string) {
+// windowRanks lists the Yahoo ranges from shortest to longest; a window's
+// rank is its index plus one. windowLabels names each rank the way windows
+// are typed, for notices.
+var (
+	windowRanks  = []string{"1d", "5d", "1mo", "3mo", "6mo", "1y", "2y", "5y", "10y", "30y"}
+	windowLabels = []string{"1d", "5d", "1m", "3m", "6m", "1y", "2y", "5y", "10y", "30y"}
+)
+
+// ChartWindows lists the windows the custom charts accept, shortest first.
+func ChartWindows() []string {
+	return slices.Clone(windowLabels)
+}
+
+// IntervalLimit is the longest lookback the data source serves for an interval.
+type IntervalLimit struct {
+	Interval string
+	Max      string // e.g. "30d"
+	rank     int
+}
+
+// IntervalLimits is the clamp table of normalizeIntervalWindow, shortest
+// interval first.
+var IntervalLimits = []IntervalLimit{
+	{"1m", "30d", 3}, {"5m", "90d", 4}, {"15m", "180d", 5}, {"1h", "2y", 7}, {"1d", "30y", 10},
+}
+
+// normalizeIntervalWindow clamps and maps to Yahoo-supported ranges given
+// interval constraints. clamped reports that the window was shortened to the
+// interval's limit; see ClampNotice.
+func normalizeIntervalWindow(intervalIn, windowIn string) (interval string, rangeParam string, clamped bool) {
 	allowed := map[string]string{"1m": "1m", "5m": "5m", "15m": "15m", "1h": "1h", "1d": "1d"}
 	interval = strings.ToLower(strings.TrimSpace(intervalIn))
 	if _, ok := allowed[interval]; !ok {
@@ -58,51 +87,101 @@ func normalizeIntervalWindow(intervalIn, windowIn string) (interval string, rang
 			return 3
 		}
 	}
-	maxRank := map[string]int{"1m": 3, "5m": 4, "15m": 5, "1h": 7, "1d": 10}[interval]
+	maxRank := intervalLimit(interval).rank
 	r := rank(w)
 	if r > maxRank {
-		r = maxRank
-	}
-	switch r {
-	case 1:
-		rangeParam = "1d"
-	case 2:
-		rangeParam = "5d"
-	case 3:
-		rangeParam = "1mo"
-	case 4:
-		rangeParam = "3mo"
-	case 5:
-		rangeParam = "6mo"
-	case 6:
-		rangeParam = "1y"
-	case 7:
-		rangeParam = "2y"
-	case 8:
-		rangeParam = "5y"
-	case 9:
-		rangeParam = "10y"
-	default:
-		rangeParam = "30y"
-	}
-	return interval, rangeParam
+		r, clamped = maxRank, true
+	}
+	return interval, windowRanks[r-1], clamped
+}
+
+func intervalLimit(interval string) IntervalLimit {
+	for _, l := range IntervalLimits {
+		if l.Interval == interval {
+			return l
+		}
+	}
+	return IntervalLimit{}
+}
+
+// ClampNotice is the caption line for a window too long for the interval,
+// e.g. "1m data limited to 30d by the data source; showing 1M", or "" when
+// the window is served as asked.
+func ClampNotice(interval, window string) string {
+	itv, rng, clamped := normalizeIntervalWindow(interval, window)
+	if !clamped {
+		return ""
+	}
+	l := intervalLimit(itv)
+	return fmt.Sprintf("%s data limited to %s by the data source; showing %s", itv, l.Max, strings.ToUpper(windowLabels[slices.Index(windowRanks, rng)]))
 }
 
 // MakeChart builds a single-symbol chart with custom interval and window.
-func MakeChart(symbol string, interval string, window string) ([]byte, error) {
-	itv, rng := normalizeIntervalWindow(interval, window)
-	ts, cl, err := fetchSeries(symbol, itv, rng)
+// Daily charts that are not resampled mark ex-dividend dates and splits
+// unless opts.NoEvents is set.
+// Meta.Note explains an overlay that was requested but left out.
+func MakeChart(ctx context.Context, symbol string, interval string, window string, opts RenderOptions) (ChartResult, error) {
+	itv, rng, _ := normalizeIntervalWindow(interval, window)
+	span := strings.ToUpper(rng)
+	if opts.Anchor != "" {
+		if itv == "1d" {
+			return ChartResult{}, errAnchorInterval
+		}
+		rng, span = anchorRange, AnchorLabel(opts.Anchor)
+	}
+	b, err := fetchBars(ctx, symbol, itv, rng)
 	if err != nil {
-		return nil, err
+		return ChartResult{}, err
 	}
+	var prev float64
+	if opts.Anchor != "" {
+		if b, prev, err = anchorBars(b, opts.Anchor); err != nil {
+			return ChartResult{}, err
+		}
+	}
+	ts, cl := b.ts, b.close
 	if len(ts) == 0 || len(cl) == 0 {
-		return nil, errors.New("no data")
+		return ChartResult{}, ErrNoData
+	}
+	et := opts.location()
+	shown := itv
+	if itv == "1d" {
+		if p := resamplePeriod(len(ts)); p != "" {
+			ts, cl = resampleCloses(ts, cl, p, et)
+			shown = p
+		}
+	}
+	chart := chartkit.Line{
+		Style:  opts.style(),
+		Title:  strings.ToUpper(symbol) + " • " + strings.ToUpper(shown) + " • " + span,
+		Series: []chartkit.Series{{Values: cl}},
+	}
+	if prev > 0 {
+		chart.Refs = []chartkit.RefLine{prevCloseRef(prev)}
+	}
+	if shown == "1d" && !opts.NoEvents {
+		if chart.Marks = eventMarks(b); chart.Marks != nil {
+			chart.Subtitle = eventsLegend
+		}
+	}
+	var note string
+	if opts.VWAP {
+		if itv == "1d" {
+			note = "VWAP omitted: it needs an intraday interval"
+		} else {
+			var vwap []float64
+			if vwap, note = vwapOverlay(b); vwap != nil {
+				chart.Series = append(chart.Series, chartkit.Series{Values: vwap})
+				chart.Legend.Labels = []string{strings.ToUpper(symbol), "VWAP"}
+			}
+		}
 	}
-	et := getEasternTime()
 	x := make([]string, len(ts))
+	times := make([]time.Time, len(ts))
 	var yMin, yMax float64
 	for i := range ts {
 		tt := time.Unix(ts[i], 0).UTC().In(et)
+		times[i] = tt
 		switch itv {
 		case "1d":
 			x[i] = tt.Format("2006-01-02")
@@ -124,7 +203,7 @@ func MakeChart(symbol string, interval string, window string) ([]byte, error) {
 		}
 	}
 	if len(cl) < 2 {
-		return nil, errors.New("not enough data points")
+		return ChartResult{}, noData("not enough data points")
 	}
 	pad := (yMax - yMin) * 0.05
 	if pad < yMax*0.002 {
@@ -142,44 +221,47 @@ func MakeChart(symbol string, interval string, window string) ([]byte, error) {
 	case "1mo", "3mo", "6mo":
 		split = 10
 	}
-	painter, err := charts.LineRender([][]float64{cl},
-		charts.TitleTextOptionFunc(strings.ToUpper(symbol)+" • "+strings.ToUpper(itv)+" • "+strings.ToUpper(rng)),
-		charts.XAxisOptionFunc(charts.XAxisOption{Data: x, BoundaryGap: charts.FalseFlag(), SplitNumber: split}),
-		charts.YAxisOptionFunc(charts.YAxisOption{Min: &yMin, Max: &yMax, DivideCount: 5}),
-		charts.ThemeOptionFunc(charts.ThemeLight),
-	)
+	chart.X = chartkit.XAxis{Labels: x, Split: split, Times: times}
+	chart.Y = []chartkit.YAxis{{Min: &yMin, Max: &yMax, Divide: 5}}
+	img, err := renderChart(ctx, chart.Render)
 	if err != nil {
-		return nil, err
+		return ChartResult{}, err
 	}
-	return painter.Bytes()
+	meta := seriesMeta(strings.ToUpper(symbol), shown, rng, ts, cl)
+	meta.Note = note
+	anchorMeta(&meta, prev)
+	return ChartResult{Image: img, Meta: meta}, nil
 }
 
 // MakeMultiChart builds a multi-symbol chart that normalizes when >2 symbols.
-func MakeMultiChart(symbols []string, interval string, window string) ([]byte, error) {
+// Symbols that fail to fetch are left out and listed in Meta.Skipped, as long as
+// two remain.
+func MakeMultiChart(ctx context.Context, symbols []string, interval string, window string, opts RenderOptions) (ChartResult, error) {
 	if len(symbols) == 0 {
-		return nil, errors.New("no symbols provided")
-	}
-	itv, rng := normalizeIntervalWindow(interval, window)
-	type sd struct {
-		sym string
-		ts  []int64
-		cl  []float64
-	}
-	arr := make([]sd, 0, len(symbols))
-	for _, s := range symbols {
-		su := strings.TrimSpace(s)
-		if su == "" {
-			continue
+		return ChartResult{}, invalidInput("no symbols provided")
+	}
+	itv, rng, _ := normalizeIntervalWindow(interval, window)
+	arr, skipped, err := fetchSymbols(symbols, 2, func(symbol string) ([]int64, []float64, error) {
+		return fetchSeries(ctx, symbol, itv, rng)
+	})
+	if err != nil {
+		return ChartResult{Meta: ChartMeta{Skipped: skipped}}, err
+	}
+	et := opts.location()
+	shown := itv
+	if itv == "1d" {
+		longest := 0
+		for _, x := range arr {
+			if len(x.ts) > longest {
+				longest = len(x.ts)
+			}
 		}
-		ts, cl, err := fetchSeries(su, itv, rng)
-		if err != nil {
-			return nil, fmt.Errorf("%s: %w", su, err)
+		if p := resamplePeriod(longest); p != "" {
+			for i := range arr {
+				arr[i].ts, arr[i].cl = resampleCloses(arr[i].ts, arr[i].cl, p, et)
+			}
+			shown = p
 		}
-		arr = append(arr, sd{sym: strings.ToUpper(su), ts: ts, cl: cl})
-		time.Sleep(120 * time.Millisecond)
-	}
-	if len(arr) == 0 {
-		return nil, errors.New("no series fetched")
 	}
 	ref := arr[0]
 	for _, x := range arr[1:] {
@@ -194,13 +276,14 @@ func MakeMultiChart(symbols []string, interval string, window string) ([]byte, e
 		}
 	}
 	if minLen < 2 {
-		return nil, errors.New("not enough data points")
+		return ChartResult{}, noData("not enough data points")
 	}
 	sort.Slice(ref.ts, func(i, j int) bool { return ref.ts[i] < ref.ts[j] })
 	xLabels := make([]string, minLen)
-	et := getEasternTime()
+	xTimes := make([]time.Time, minLen)
 	for i, ts := range ref.ts[len(ref.ts)-minLen:] {
 		tt := time.Unix(ts, 0).UTC().In(et)
+		xTimes[i] = tt
 		switch itv {
 		case "1d":
 			xLabels[i] = tt.Format("2006-01-02")
@@ -213,11 +296,13 @@ func MakeMultiChart(symbols []string, interval string, window string) ([]byte, e
 	normalized := len(arr) > 2
 	values := make([][]float64, 0, len(arr))
 	names := make([]string, 0, len(arr))
+	rets := make([]float64, 0, len(arr))
 	var leftMin, leftMax, rightMin, rightMax *float64
 	var commonMin, commonMax *float64
 	for i, x := range arr {
 		clOrig := x.cl[len(x.cl)-minLen:]
 		cl := clOrig
+		rets = append(rets, windowReturn(clOrig))
 		if normalized {
 			base := 0.0
 			for _, v := range clOrig {
@@ -291,17 +376,20 @@ func MakeMultiChart(symbols []string, interval string, window string) ([]byte, e
 	case "1mo", "3mo", "6mo":
 		split = 10
 	}
-	seriesList := charts.NewSeriesListDataFromValues(values, charts.ChartTypeLine)
-	for i := range seriesList {
-		seriesList[i].Name = names[i]
-		if normalized {
-			seriesList[i].AxisIndex = 0
-		} else {
-			seriesList[i].AxisIndex = i % 2
+	series := make([]chartkit.Series, len(values))
+	for i := range series {
+		series[i] = chartkit.Series{Name: names[i], Values: values[i]}
+		if !normalized {
+			series[i].Axis = i % 2
 		}
 	}
-	var painter *charts.Painter
-	var err error
+	chart := symbolLines{
+		title:   "Multi • " + strings.ToUpper(shown) + " • " + strings.ToUpper(rng),
+		syms:    names,
+		returns: rets,
+		series:  series,
+		xAxis:   chartkit.XAxis{Labels: xLabels, Split: split, Times: xTimes},
+	}
 	if normalized {
 		var yMin, yMax *float64
 		if commonMin != nil && commonMax != nil {
@@ -311,42 +399,47 @@ func MakeMultiChart(symbols []string, interval string, window string) ([]byte, e
 			yMin = &vmin
 			yMax = &vmax
 		}
-		painter, err = charts.Render(charts.ChartOption{SeriesList: seriesList}, charts.TitleTextOptionFunc("Multi • "+strings.ToUpper(itv)+" • "+strings.ToUpper(rng), strings.Join(names, ", ")+" • normalized %"), charts.XAxisOptionFunc(charts.XAxisOption{Data: xLabels, BoundaryGap: charts.FalseFlag(), SplitNumber: split}), charts.YAxisOptionFunc(charts.YAxisOption{Min: yMin, Max: yMax, DivideCount: 5}), charts.LegendOptionFunc(charts.LegendOption{Data: names}), charts.ThemeOptionFunc(charts.ThemeLight))
+		chart.subtitle = "normalized %"
+		chart.yAxes = []chartkit.YAxis{{Min: yMin, Max: yMax, Divide: 5}}
 	} else {
-		painter, err = charts.Render(charts.ChartOption{SeriesList: seriesList}, charts.TitleTextOptionFunc("Multi • "+strings.ToUpper(itv)+" • "+strings.ToUpper(rng), strings.Join(names, ", ")), charts.XAxisOptionFunc(charts.XAxisOption{Data: xLabels, BoundaryGap: charts.FalseFlag(), SplitNumber: split}), charts.YAxisOptionFunc(charts.YAxisOption{Min: leftMin, Max: leftMax, DivideCount: 5}, charts.YAxisOption{Min: rightMin, Max: rightMax, DivideCount: 5, Position: charts.PositionRight}), charts.LegendOptionFunc(charts.LegendOption{Data: names}), charts.ThemeOptionFunc(charts.ThemeLight))
-	}
-	if err != nil {
-		return nil, err
+		chart.yAxes = []chartkit.YAxis{
+			{Min: leftMin, Max: leftMax, Divide: 5},
+			{Min: rightMin, Max: rightMax, Divide: 5},
+		}
 	}
-	return painter.Bytes()
+	img, err := renderChart(ctx, chart.line(opts).Render)
+	return ChartResult{Image: img, Meta: multiMeta(names, rets, shown, rng, minLen, ref.ts[len(ref.ts)-1], skipped)}, err
 }
 
 // MakeIndexedChart renders multiple symbols indexed to base 100 at the first point.
-func MakeIndexedChart(symbols []string, interval string, window string, base100 bool) ([]byte, error) {
+// Symbols that fail to fetch are left out and listed in Meta.Skipped, as long as
+// one remains.
+func MakeIndexedChart(ctx context.Context, symbols []string, interval string, window string, base100 bool, opts RenderOptions) (ChartResult, error) {
 	if len(symbols) == 0 {
-		return nil, errors.New("no symbols provided")
-	}
-	itv, rng := normalizeIntervalWindow(interval, window)
-	type sd struct {
-		sym string
-		ts  []int64
-		cl  []float64
-	}
-	arr := make([]sd, 0, len(symbols))
-	for _, s := range symbols {
-		su := strings.TrimSpace(s)
-		if su == "" {
-			continue
+		return ChartResult{}, invalidInput("no symbols provided")
+	}
+	itv, rng, _ := normalizeIntervalWindow(interval, window)
+	arr, skipped, err := fetchSymbols(symbols, 1, func(symbol string) ([]int64, []float64, error) {
+		return fetchSeries(ctx, symbol, itv, rng)
+	})
+	if err != nil {
+		return ChartResult{Meta: ChartMeta{Skipped: skipped}}, err
+	}
+	et := opts.location()
+	shown := itv
+	if itv == "1d" {
+		longest := 0
+		for _, x := range arr {
+			if len(x.ts) > longest {
+				longest = len(x.ts)
+			}
 		}
-		ts, cl, err := fetchSeries(su, itv, rng)
-		if err != nil {
-			return nil, fmt.Errorf("%s: %w", su, err)
+		if p := resamplePeriod(longest); p != "" {
+			for i := range arr {
+				arr[i].ts, arr[i].cl = resampleCloses(arr[i].ts, arr[i].cl, p, et)
+			}
+			shown = p
 		}
-		arr = append(arr, sd{sym: strings.ToUpper(su), ts: ts, cl: cl})
-		time.Sleep(120 * time.Millisecond)
-	}
-	if len(arr) == 0 {
-		return nil, errors.New("no series fetched")
 	}
 	// choose reference timeline longest ts
 	ref := arr[0]
@@ -362,13 +455,14 @@ func MakeIndexedChart(symbols []string, interval string, window string, base100
 		}
 	}
 	if minLen < 2 {
-		return nil, errors.New("not enough data points")
+		return ChartResult{}, noData("not enough data points")
 	}
 	// labels
-	et := getEasternTime()
 	xLabels := make([]string, minLen)
+	xTimes := make([]time.Time, minLen)
 	for i, ts := range ref.ts[len(ref.ts)-minLen:] {
 		tt := time.Unix(ts, 0).UTC().In(et)
+		xTimes[i] = tt
 		switch itv {
 		case "1d":
 			xLabels[i] = tt.Format("2006-01-02")
@@ -381,9 +475,11 @@ func MakeIndexedChart(symbols []string, interval string, window string, base100
 	// index values
 	values := make([][]float64, 0, len(arr))
 	names := make([]string, 0, len(arr))
+	rets := make([]float64, 0, len(arr))
 	var gmin, gmax *float64
 	for _, x := range arr {
 		cl := x.cl[len(x.cl)-minLen:]
+		rets = append(rets, windowReturn(cl))
 		base := 0.0
 		for _, v := range cl {
 			if v != 0 {
@@ -433,21 +529,25 @@ func MakeIndexedChart(symbols []string, interval string, window string, base100
 	case "1mo", "3mo", "6mo":
 		split = 10
 	}
-	seriesList := charts.NewSeriesListDataFromValues(values, charts.ChartTypeLine)
-	for i := range seriesList {
-		seriesList[i].Name = names[i]
-		seriesList[i].AxisIndex = 0
+	series := make([]chartkit.Series, len(values))
+	for i := range series {
+		series[i] = chartkit.Series{Name: names[i], Values: values[i]}
 	}
-	title := "Indexed • " + strings.ToUpper(itv) + " • " + strings.ToUpper(rng)
-	subtitle := strings.Join(names, ", ") + " • base "
+	subtitle := "base "
 	if base100 {
 		subtitle += "100"
 	} else {
 		subtitle += "1.0"
 	}
-	painter, err := charts.Render(charts.ChartOption{SeriesList: seriesList}, charts.TitleTextOptionFunc(title, subtitle), charts.XAxisOptionFunc(charts.XAxisOption{Data: xLabels, BoundaryGap: charts.FalseFlag(), SplitNumber: split}), charts.YAxisOptionFunc(charts.YAxisOption{Min: yMin, Max: yMax, DivideCount: 5}), charts.LegendOptionFunc(charts.LegendOption{Data: names}), charts.ThemeOptionFunc(charts.ThemeLight))
-	if err != nil {
-		return nil, err
-	}
-	return painter.Bytes()
+	chart := symbolLines{
+		title:    "Indexed • " + strings.ToUpper(shown) + " • " + strings.ToUpper(rng),
+		subtitle: subtitle,
+		syms:     names,
+		returns:  rets,
+		series:   series,
+		xAxis:    chartkit.XAxis{Labels: xLabels, Split: split, Times: xTimes},
+		yAxes:    []chartkit.YAxis{{Min: yMin, Max: yMax, Divide: 5}},
+	}
+	img, err := renderChart(ctx, chart.line(opts).Render)
+	return ChartResult{Image: img, Meta: multiMeta(names, rets, shown, rng, minLen, ref.ts[len(ref.ts)-1], skipped)}, err
 }
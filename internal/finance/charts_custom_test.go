@@ -0,0 +1,83 @@
+package finance
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestNormalizeIntervalWindow(t *testing.T) {
+	tests := []struct {
+		interval, window string
+		itv, rng         string
+		clamped          bool
+	}{
+		{"5m", "1m", "5m", "1mo", false},
+		{"1m", "30d", "1m", "1mo", false},
+		{"1m", "3m", "1m", "1mo", true},
+		{"1m", "1y", "1m", "1mo", true},
+		{"5m", "90d", "5m", "3mo", false},
+		{"5m", "6m", "5m", "3mo", true},
+		{"15m", "6m", "15m", "6mo", false},
+		{"15m", "1y", "15m", "6mo", true},
+		{"1h", "2y", "1h", "2y", false},
+		{"1h", "5y", "1h", "2y", true},
+		{"1d", "30y", "1d", "30y", false},
+		{" 1H ", " 1Y ", "1h", "1y", false},
+		{"2m", "1d", "5m", "1d", false}, // unknown intervals fall back to 5m
+		{"1m", "", "1m", "1mo", false},  // the default window always fits
+		{"1d", "", "1d", "1y", false},
+	}
+	for _, tc := range tests {
+		itv, rng, clamped := normalizeIntervalWindow(tc.interval, tc.window)
+		if itv != tc.itv || rng != tc.rng || clamped != tc.clamped {
+			t.Errorf("normalizeIntervalWindow(%q, %q) = %q, %q, %v; want %q, %q, %v",
+				tc.interval, tc.window, itv, rng, clamped, tc.itv, tc.rng, tc.clamped)
+		}
+	}
+}
+
+func TestClampNotice(t *testing.T) {
+	tests := []struct {
+		interval, window, want string
+	}{
+		{"1m", "1y", "1m data limited to 30d by the data source; showing 1M"},
+		{"5m", "6m", "5m data limited to 90d by the data source; showing 3M"},
+		{"15m", "30y", "15m data limited to 180d by the data source; showing 6M"},
+		{"1h", "5y", "1h data limited to 2y by the data source; showing 2Y"},
+		{"1m", "5d", ""},
+		{"1d", "30y", ""},
+	}
+	for _, tc := range tests {
+		if got := ClampNotice(tc.interval, tc.window); got != tc.want {
+			t.Errorf("ClampNotice(%q, %q) = %q, want %q", tc.interval, tc.window, got, tc.want)
+		}
+	}
+
+	// the notice appears exactly when the window served differs from the one asked
+	for _, l := range IntervalLimits {
+		for i, w := range windowLabels {
+			_, rng, _ := normalizeIntervalWindow(l.Interval, w)
+			changed := rng != windowRanks[i]
+			if got := ClampNotice(l.Interval, w) != ""; got != changed {
+				t.Errorf("%s %s: served %s, notice shown = %v", l.Interval, w, rng, got)
+			}
+		}
+	}
+}
+
+func TestIntervalLimitsMatchRanks(t *testing.T) {
+	// Max is how /help limits words the rank the clamp uses
+	days := map[string]string{"30d": "1mo", "90d": "3mo", "180d": "6mo"}
+	for _, l := range IntervalLimits {
+		want := l.Max
+		if r, ok := days[l.Max]; ok {
+			want = r
+		}
+		if got := windowRanks[l.rank-1]; got != want {
+			t.Errorf("%s: limit %s but clamps to %s", l.Interval, l.Max, got)
+		}
+	}
+	if !slices.IsSortedFunc(IntervalLimits, func(a, b IntervalLimit) int { return a.rank - b.rank }) {
+		t.Error("IntervalLimits is not shortest interval first")
+	}
+}
@@ -84,3 +84,32 @@ func filterIQR(ts []int64, cl []float64, k float64, minPoints int) ([]int64, []f
 	}
 	return outTs, outCl
 }
+
+// filtered applies filterNonNegative and filterIQR to the closes and drops the
+// same bars from every other column. The filters run on bar indices in place of
+// timestamps so the surviving indices can be mapped back. Columns whose length
+// doesn't match the timestamps are dropped.
+func (b bars) filtered() bars {
+	idx := make([]int64, len(b.ts))
+	for i := range idx {
+		idx[i] = int64(i)
+	}
+	idx, cl := filterNonNegative(idx, b.close)
+	idx, cl = filterIQR(idx, cl, 1.5, 20)
+	pick := func(col []float64) []float64 {
+		if len(col) != len(b.ts) {
+			return nil
+		}
+		out := make([]float64, len(idx))
+		for j, i := range idx {
+			out[j] = col[i]
+		}
+		return out
+	}
+	out := bars{ts: make([]int64, len(idx)), close: cl, gmtOffset: b.gmtOffset, session: b.session, events: b.events}
+	for j, i := range idx {
+		out.ts[j] = b.ts[i]
+	}
+	out.open, out.high, out.low, out.volume = pick(b.open), pick(b.high), pick(b.low), pick(b.volume)
+	return out
+}
@@ -0,0 +1,83 @@
+package finance
+
+import "time"
+
+// MissingBarsWarn is the share of a session's expected intraday bars that may
+// be absent from the source before a chart is flagged as incomplete.
+const MissingBarsWarn = 0.2
+
+const (
+	// coverageGrace is left off the end of a session in progress, so bars the
+	// source has not published yet are not counted as missing.
+	coverageGrace = 10 * time.Minute
+	// coverageMinBars is the fewest expected bars worth judging; early in a
+	// session a single late bar would read as a large gap.
+	coverageMinBars = 6
+)
+
+// intradaySteps maps the intraday Yahoo intervals to their bar length.
+var intradaySteps = map[string]time.Duration{
+	"1m": time.Minute, "2m": 2 * time.Minute, "5m": 5 * time.Minute, "15m": 15 * time.Minute,
+	"30m": 30 * time.Minute, "60m": time.Hour, "1h": time.Hour, "90m": 90 * time.Minute,
+}
+
+// tradingPeriod is an exchange's regular session in Unix seconds, as Yahoo
+// reports it with a chart. Yahoo shortens it on half-days, so the expected
+// bar count follows the exchange's actual hours. Zero when unknown, as on the
+// spark fallback and injected sources.
+type tradingPeriod struct {
+	start, end int64
+}
+
+// withUSCalendar checks a period opening at 09:30 Eastern, a US equity
+// session, against the market calendar, in case the source reports regular
+// hours on a holiday or half-day: a holiday has no session and a half-day
+// ends at the early close.
+func (p tradingPeriod) withUSCalendar() tradingPeriod {
+	start := time.Unix(p.start, 0).In(DefaultLocation())
+	if p.end <= p.start || start.Hour() != 9 || start.Minute() != 30 {
+		return p
+	}
+	_, close, ok := USSession(start)
+	if !ok {
+		return tradingPeriod{}
+	}
+	return tradingPeriod{start: p.start, end: min(p.end, close.Unix())}
+}
+
+// expectedBars is how many bars of length step should have started in p by
+// now: all of them once the session is over, otherwise those that started
+// before now less coverageGrace. 0 before the session opens.
+func expectedBars(p tradingPeriod, step time.Duration, now time.Time) int {
+	secs := int64(step / time.Second)
+	end := min(p.end, now.Add(-coverageGrace).Unix())
+	if secs <= 0 || end <= p.start {
+		return 0
+	}
+	return int((end - p.start + secs - 1) / secs)
+}
+
+// missingBars returns the share of p's expected bars absent from ts, or 0 when
+// the interval is not intraday, the session is unknown or too young to judge.
+// Bars outside the regular session, such as pre- and post-market, are not
+// counted.
+func missingBars(ts []int64, p tradingPeriod, interval string, now time.Time) float64 {
+	step, ok := intradaySteps[interval]
+	if !ok || p.end <= p.start {
+		return 0
+	}
+	want := expectedBars(p, step, now)
+	if want < coverageMinBars {
+		return 0
+	}
+	got := 0
+	for _, t := range ts {
+		if t >= p.start && t < p.end {
+			got++
+		}
+	}
+	if got >= want {
+		return 0
+	}
+	return float64(want-got) / float64(want)
+}
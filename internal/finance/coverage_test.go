@@ -0,0 +1,116 @@
+package finance
+
+import (
+	"testing"
+	"time"
+)
+
+// usSession is the 09:30 to 16:00 Eastern session Yahoo reports for a US
+// listing on the date, half-days and holidays included.
+func usSession(y int, m time.Month, d int) tradingPeriod {
+	return tradingPeriod{
+		start: time.Date(y, m, d, 9, 30, 0, 0, DefaultLocation()).Unix(),
+		end:   time.Date(y, m, d, 16, 0, 0, 0, DefaultLocation()).Unix(),
+	}
+}
+
+// fiveMinuteBars are the 5m bar starts of p, plus pre- and post-market bars
+// an hour either side.
+func fiveMinuteBars(p tradingPeriod, prePost bool) []int64 {
+	var ts []int64
+	from, to := p.start, p.end
+	if prePost {
+		from, to = from-3600, to+3600
+	}
+	for t := from; t < to; t += 300 {
+		ts = append(ts, t)
+	}
+	return ts
+}
+
+func TestWithUSCalendar(t *testing.T) {
+	at := func(y int, m time.Month, d, h, min int) int64 {
+		return time.Date(y, m, d, h, min, 0, 0, DefaultLocation()).Unix()
+	}
+	tests := []struct {
+		name string
+		in   tradingPeriod
+		want tradingPeriod
+	}{
+		{"regular day", usSession(2024, time.July, 2), usSession(2024, time.July, 2)},
+		{"day before Independence Day", usSession(2024, time.July, 3), tradingPeriod{at(2024, time.July, 3, 9, 30), at(2024, time.July, 3, 13, 0)}},
+		{"day after Thanksgiving", usSession(2024, time.November, 29), tradingPeriod{at(2024, time.November, 29, 9, 30), at(2024, time.November, 29, 13, 0)}},
+		{"already shortened", tradingPeriod{at(2024, time.December, 24, 9, 30), at(2024, time.December, 24, 13, 0)}, tradingPeriod{at(2024, time.December, 24, 9, 30), at(2024, time.December, 24, 13, 0)}},
+		{"Christmas", usSession(2024, time.December, 25), tradingPeriod{}},
+		{"Good Friday", usSession(2024, time.March, 29), tradingPeriod{}},
+		// a session not opening at 09:30 Eastern isn't a US equity's
+		{"other exchange", tradingPeriod{at(2024, time.December, 25, 3, 0), at(2024, time.December, 25, 11, 30)}, tradingPeriod{at(2024, time.December, 25, 3, 0), at(2024, time.December, 25, 11, 30)}},
+		{"unknown", tradingPeriod{}, tradingPeriod{}},
+	}
+	for _, tc := range tests {
+		if got := tc.in.withUSCalendar(); got != tc.want {
+			t.Errorf("%s: withUSCalendar = %+v, want %+v", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestExpectedBars(t *testing.T) {
+	day := usSession(2024, time.July, 2)
+	half := usSession(2024, time.July, 3).withUSCalendar()
+	et := func(h, m int) time.Time { return time.Date(2024, time.July, 2, h, m, 0, 0, DefaultLocation()) }
+	after := et(20, 0)
+	tests := []struct {
+		name string
+		p    tradingPeriod
+		step time.Duration
+		now  time.Time
+		want int
+	}{
+		{"full day, 5m", day, 5 * time.Minute, after, 78},
+		{"full day, 1h", day, time.Hour, after, 7}, // the 15:30 bar is half an hour
+		{"half-day, 5m", half, 5 * time.Minute, after.AddDate(0, 0, 1), 42},
+		{"holiday", usSession(2024, time.July, 4).withUSCalendar(), 5 * time.Minute, after.AddDate(0, 0, 2), 0},
+		{"in progress", day, 5 * time.Minute, et(10, 40), 12}, // bars up to 10:30, the grace before now
+		{"just opened", day, 5 * time.Minute, et(9, 45), 1},
+		{"before the open", day, 5 * time.Minute, et(8, 0), 0},
+		{"no step", day, 0, after, 0},
+	}
+	for _, tc := range tests {
+		if got := expectedBars(tc.p, tc.step, tc.now); got != tc.want {
+			t.Errorf("%s: expectedBars = %d, want %d", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestMissingBars(t *testing.T) {
+	day := usSession(2024, time.July, 2)
+	reported := usSession(2024, time.July, 3) // Yahoo's regular hours on a half-day
+	half := reported.withUSCalendar()
+	holiday := usSession(2024, time.July, 4).withUSCalendar()
+	evening := time.Date(2024, time.July, 5, 20, 0, 0, 0, DefaultLocation())
+	full := fiveMinuteBars(day, true)
+	tests := []struct {
+		name     string
+		ts       []int64
+		p        tradingPeriod
+		interval string
+		now      time.Time
+		want     float64
+	}{
+		{"complete day with pre and post", full, day, "5m", evening, 0},
+		{"half of the day", fiveMinuteBars(day, false)[:39], day, "5m", evening, 0.5},
+		{"pre and post only", append(append([]int64{}, full[:12]...), full[len(full)-12:]...), day, "5m", evening, 1},
+		{"complete half-day", fiveMinuteBars(half, false), half, "5m", evening, 0},
+		// without the calendar the early close reads as 36 of 78 bars missing
+		{"half-day at regular hours", fiveMinuteBars(half, false), reported, "5m", evening, 36.0 / 78},
+		{"holiday", nil, holiday, "5m", evening, 0},
+		{"daily bars", nil, day, "1d", evening, 0},
+		{"unknown session", nil, tradingPeriod{}, "5m", evening, 0},
+		{"too young to judge", nil, day, "5m", time.Date(2024, time.July, 2, 10, 0, 0, 0, DefaultLocation()), 0},
+	}
+	for _, tc := range tests {
+		if got := missingBars(tc.ts, tc.p, tc.interval, tc.now); !closeTo(got, tc.want, 1e-9) {
+			t.Errorf("%s: missingBars = %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
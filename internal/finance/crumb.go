@@ -0,0 +1,162 @@
+package finance
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// yahooCookieURL sets the session cookie (A3) the crumb is tied to; it
+	// answers 404, which is expected.
+	yahooCookieURL = "https://fc.yahoo.com"
+	yahooCrumbURL  = "https://query1.finance.yahoo.com/v1/test/getcrumb"
+	// yahooQuoteSummaryURL is formatted with the symbol.
+	yahooQuoteSummaryURL = "https://query2.finance.yahoo.com/v10/finance/quoteSummary/%s"
+	// crumbTTL is how long a crumb is reused before a fresh handshake.
+	crumbTTL = 6 * time.Hour
+)
+
+// errInvalidCrumb marks a response that rejected the session, so the caller
+// refreshes the crumb and retries once.
+var errInvalidCrumb = errors.New("yahoo rejected the crumb")
+
+// crumbManager holds the cookie jar and crumb Yahoo's quoteSummary endpoints
+// require. The handshake runs lazily on first use and again after crumbTTL or
+// when a request is rejected.
+type crumbManager struct {
+	cookieURL, crumbURL string
+	ttl                 time.Duration
+
+	mu      sync.Mutex
+	client  *http.Client
+	crumb   string
+	fetched time.Time
+}
+
+var yahooCrumbs = newCrumbManager(yahooCookieURL, yahooCrumbURL, crumbTTL)
+
+func newCrumbManager(cookieURL, crumbURL string, ttl time.Duration) *crumbManager {
+	return &crumbManager{cookieURL: cookieURL, crumbURL: crumbURL, ttl: ttl}
+}
+
+// session returns an HTTP client carrying the session cookie and its crumb.
+func (c *crumbManager) session(ctx context.Context) (*http.Client, string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.crumb != "" && time.Since(c.fetched) < c.ttl {
+		return c.client, c.crumb, nil
+	}
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		return nil, "", err
+	}
+	client := &http.Client{Jar: jar, Timeout: 15 * time.Second}
+	resp, err := yahooGet(ctx, client, c.cookieURL)
+	if err != nil {
+		return nil, "", fmt.Errorf("yahoo cookie: %w", err)
+	}
+	// any status is fine here; only the Set-Cookie matters
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+	resp, err = yahooGet(ctx, client, c.crumbURL)
+	if err != nil {
+		return nil, "", fmt.Errorf("yahoo crumb: %w", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	if err != nil {
+		return nil, "", fmt.Errorf("yahoo crumb: %w", err)
+	}
+	crumb := strings.TrimSpace(string(body))
+	if resp.StatusCode != http.StatusOK || crumb == "" || strings.ContainsAny(crumb, "<{ ") {
+		return nil, "", fmt.Errorf("yahoo crumb: unexpected response (%d)", resp.StatusCode)
+	}
+	c.client, c.crumb, c.fetched = client, crumb, time.Now()
+	return c.client, c.crumb, nil
+}
+
+// invalidate drops the crumb so the next session call performs a new handshake.
+func (c *crumbManager) invalidate(crumb string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	// another caller may already have refreshed it
+	if c.crumb == crumb {
+		c.crumb = ""
+	}
+}
+
+// get fetches rawURL with the session cookie and crumb attached and decodes
+// the JSON response into out, refreshing the crumb and retrying once when
+// Yahoo rejects it.
+func (c *crumbManager) get(ctx context.Context, rawURL string, out any) error {
+	if DemoMode() {
+		return ErrDemoData
+	}
+	for attempt := 0; ; attempt++ {
+		err := c.getOnce(ctx, rawURL, out)
+		if !errors.Is(err, errInvalidCrumb) || attempt == 1 {
+			return err
+		}
+	}
+}
+
+func (c *crumbManager) getOnce(ctx context.Context, rawURL string, out any) error {
+	client, crumb, err := c.session(ctx)
+	if err != nil {
+		return err
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return err
+	}
+	q := u.Query()
+	q.Set("crumb", crumb)
+	u.RawQuery = q.Encode()
+	resp, err := yahooGet(ctx, client, u.String())
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 4<<20))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode == http.StatusUnauthorized || strings.Contains(string(body), "Invalid Crumb") || strings.Contains(string(body), "Invalid Cookie") {
+		c.invalidate(crumb)
+		return errInvalidCrumb
+	}
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return classify(ErrRateLimited, errors.New("yahoo rate limit (429)"))
+	}
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNotFound {
+		return fmt.Errorf("yahoo returned %d", resp.StatusCode)
+	}
+	return json.Unmarshal(body, out)
+}
+
+func yahooGet(ctx context.Context, client *http.Client, rawURL string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json, text/plain, */*")
+	req.Header.Set("Accept-Language", "en-US,en;q=0.9")
+	return yahooDo(client, req)
+}
+
+// fetchQuoteSummary reads the given quoteSummary modules for symbol into out,
+// which should mirror {"quoteSummary":{"result":[...],"error":...}}.
+func fetchQuoteSummary(ctx context.Context, symbol string, modules []string, out any) error {
+	u := fmt.Sprintf(yahooQuoteSummaryURL, url.PathEscape(strings.ToUpper(symbol))) +
+		"?modules=" + url.QueryEscape(strings.Join(modules, ","))
+	return yahooCrumbs.get(ctx, u, out)
+}
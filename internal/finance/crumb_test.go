@@ -0,0 +1,142 @@
+package finance
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeYahoo simulates the cookie and crumb handshake: /cookie sets a session
+// cookie, /crumb hands out the crumb of that session, and /data answers only
+// the current session's crumb with 401 otherwise.
+type fakeYahoo struct {
+	mu         sync.Mutex
+	sessions   int // handshakes started
+	valid      string
+	alwaysDeny bool
+}
+
+func (f *fakeYahoo) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/cookie", func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		f.sessions++
+		n := f.sessions
+		f.mu.Unlock()
+		http.SetCookie(w, &http.Cookie{Name: "A3", Value: fmt.Sprint("session-", n), Path: "/"})
+		http.NotFound(w, r) // like fc.yahoo.com
+	})
+	mux.HandleFunc("/crumb", func(w http.ResponseWriter, r *http.Request) {
+		c, err := r.Cookie("A3")
+		if err != nil {
+			http.Error(w, "no cookie", http.StatusUnauthorized)
+			return
+		}
+		crumb := "crumb-of-" + c.Value
+		f.mu.Lock()
+		f.valid = crumb
+		f.mu.Unlock()
+		fmt.Fprint(w, crumb)
+	})
+	mux.HandleFunc("/data", func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		ok := !f.alwaysDeny && r.URL.Query().Get("crumb") == f.valid
+		f.mu.Unlock()
+		if _, err := r.Cookie("A3"); err != nil || !ok {
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprint(w, `{"finance":{"error":{"code":"Unauthorized","description":"Invalid Crumb"}}}`)
+			return
+		}
+		fmt.Fprint(w, `{"value":42}`)
+	})
+	return mux
+}
+
+// expire makes the server reject every crumb handed out so far.
+func (f *fakeYahoo) expire() {
+	f.mu.Lock()
+	f.valid = "expired"
+	f.mu.Unlock()
+}
+
+func (f *fakeYahoo) handshakes() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.sessions
+}
+
+func newFakeYahoo(t *testing.T, ttl time.Duration) (*fakeYahoo, *crumbManager, string) {
+	t.Helper()
+	f := &fakeYahoo{}
+	srv := httptest.NewServer(f.handler())
+	t.Cleanup(srv.Close)
+	return f, newCrumbManager(srv.URL+"/cookie", srv.URL+"/crumb", ttl), srv.URL + "/data"
+}
+
+func getValue(t *testing.T, c *crumbManager, url string) error {
+	t.Helper()
+	var out struct{ Value int }
+	err := c.get(context.Background(), url, &out)
+	if err == nil && out.Value != 42 {
+		t.Fatalf("decoded %d, want 42", out.Value)
+	}
+	return err
+}
+
+func TestCrumbFetchAndReuse(t *testing.T) {
+	f, c, url := newFakeYahoo(t, time.Hour)
+	for i := range 3 {
+		if err := getValue(t, c, url); err != nil {
+			t.Fatalf("get %d: %v", i, err)
+		}
+	}
+	if n := f.handshakes(); n != 1 {
+		t.Errorf("handshakes = %d, want 1 reused session", n)
+	}
+}
+
+func TestCrumbExpiry(t *testing.T) {
+	f, c, url := newFakeYahoo(t, time.Hour)
+	if err := getValue(t, c, url); err != nil {
+		t.Fatal(err)
+	}
+	c.mu.Lock()
+	c.fetched = time.Now().Add(-2 * time.Hour)
+	c.mu.Unlock()
+	if err := getValue(t, c, url); err != nil {
+		t.Fatal(err)
+	}
+	if n := f.handshakes(); n != 2 {
+		t.Errorf("handshakes = %d, want 2 after the crumb expired", n)
+	}
+}
+
+func TestCrumbRefreshOn401(t *testing.T) {
+	f, c, url := newFakeYahoo(t, time.Hour)
+	if err := getValue(t, c, url); err != nil {
+		t.Fatal(err)
+	}
+	f.expire()
+	if err := getValue(t, c, url); err != nil {
+		t.Fatalf("get after the server dropped the crumb: %v", err)
+	}
+	if n := f.handshakes(); n != 2 {
+		t.Errorf("handshakes = %d, want 2 after a 401", n)
+	}
+}
+
+func TestCrumbRejectedTwice(t *testing.T) {
+	f, c, url := newFakeYahoo(t, time.Hour)
+	f.alwaysDeny = true
+	if err := getValue(t, c, url); !errors.Is(err, errInvalidCrumb) {
+		t.Fatalf("err = %v, want errInvalidCrumb", err)
+	}
+	if n := f.handshakes(); n != 2 {
+		t.Errorf("handshakes = %d, want 2 (one retry)", n)
+	}
+}
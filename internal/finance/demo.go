@@ -0,0 +1,132 @@
+package finance
+
+import (
+	"context"
+	"errors"
+	"hash/fnv"
+	"math"
+	"math/rand/v2"
+	"slices"
+	"strings"
+	"time"
+)
+
+// ErrDemoData is returned by lookups DemoSource cannot fake, such as
+// fundamentals and option chains, while DEMO_MODE is on.
+var ErrDemoData = errors.New("not available with demo data")
+
+// demoSteps are the bar intervals DemoSource generates.
+var demoSteps = map[string]time.Duration{
+	"1m": time.Minute, "2m": 2 * time.Minute, "5m": 5 * time.Minute, "15m": 15 * time.Minute,
+	"30m": 30 * time.Minute, "60m": time.Hour, "1h": time.Hour, "90m": 90 * time.Minute, "1d": 24 * time.Hour,
+}
+
+// demoSessions maps the short Yahoo ranges to a number of trading sessions;
+// longer ranges are calendar months, see demoMonths.
+var demoSessions = map[string]int{"1d": 1, "5d": 5}
+
+var demoMonths = map[string]int{"1mo": 1, "3mo": 3, "6mo": 6, "1y": 12, "2y": 24, "5y": 60, "10y": 120, "30y": 360, "max": 360}
+
+// DemoSource generates plausible prices without the network: a random walk
+// seeded by the symbol, so $TSLA always has the same price level and
+// volatility. Bars fall in the regular NYSE sessions, see USSession, and end
+// at the latest one before now. The walk runs backwards from a fixed
+// last price per symbol, so every interval of a symbol agrees on the quote.
+type DemoSource struct {
+	Now func() time.Time // nil means time.Now
+}
+
+// Series implements SeriesSource.
+func (d DemoSource) Series(_ context.Context, symbol, interval, rangeParam string) (Series, error) {
+	step, ok := demoSteps[interval]
+	if !ok {
+		return Series{}, errors.New("demo: unsupported interval " + interval)
+	}
+	now := time.Now()
+	if d.Now != nil {
+		now = d.Now()
+	}
+	times, err := demoTimes(now, step, rangeParam)
+	if err != nil {
+		return Series{}, err
+	}
+	n := len(times)
+
+	h := fnv.New64a()
+	h.Write([]byte(strings.ToUpper(symbol)))
+	seed := h.Sum64()
+	params := rand.New(rand.NewPCG(seed, 0))
+	last := 10 * math.Exp(params.Float64()*3.5) // 10 to ~330
+	vol := 0.15 + params.Float64()*0.45         // annualized
+	drift := 0.02 + params.Float64()*0.12
+	baseVolume := 2e5 * math.Exp(params.Float64()*4)
+
+	// dt is one bar as a fraction of a 252-session year of 6.5h sessions
+	dt := 1.0 / 252
+	if step < 24*time.Hour {
+		dt = step.Hours() / 6.5 / 252
+	}
+	sd := vol * math.Sqrt(dt)
+	walk := rand.New(rand.NewPCG(seed, uint64(step)))
+
+	_, offset := now.In(DefaultLocation()).Zone()
+	out := Series{
+		Timestamps: make([]int64, n),
+		Open:       make([]float64, n),
+		High:       make([]float64, n),
+		Low:        make([]float64, n),
+		Close:      make([]float64, n),
+		Volume:     make([]float64, n),
+		GMTOffset:  offset,
+	}
+	c := last
+	for i := n - 1; i >= 0; i-- {
+		r := (drift-vol*vol/2)*dt + sd*walk.NormFloat64()
+		o := c / math.Exp(r)
+		out.Timestamps[i] = times[i].Unix()
+		out.Close[i] = c
+		out.Open[i] = o
+		out.High[i] = math.Max(o, c) * (1 + math.Abs(walk.NormFloat64())*sd/2)
+		out.Low[i] = math.Min(o, c) * (1 - math.Abs(walk.NormFloat64())*sd/2)
+		out.Volume[i] = math.Round(baseVolume * dt * 252 * (0.5 + walk.Float64()))
+		c = o
+	}
+	return out, nil
+}
+
+// demoTimes lists the bar start times of rangeParam ending at the last
+// session bar that started before now, oldest first.
+func demoTimes(now time.Time, step time.Duration, rangeParam string) ([]time.Time, error) {
+	sessions, months := demoSessions[rangeParam], demoMonths[rangeParam]
+	from := now.AddDate(0, -months, 0)
+	if rangeParam == "ytd" {
+		from = time.Date(now.In(DefaultLocation()).Year(), 1, 1, 0, 0, 0, 0, DefaultLocation())
+	} else if sessions == 0 && months == 0 {
+		return nil, errors.New("demo: unsupported range " + rangeParam)
+	}
+
+	var out []time.Time
+	day := now.In(DefaultLocation())
+	for seen := 0; ; day = day.AddDate(0, 0, -1) {
+		open, close, ok := USSession(day)
+		if !ok || !open.Before(now) {
+			continue
+		}
+		if sessions > 0 && seen == sessions || sessions == 0 && open.Before(from) {
+			break
+		}
+		seen++
+		if step >= 24*time.Hour {
+			out = append(out, open)
+			continue
+		}
+		// bars are collected newest first and reversed at the end
+		for t := open.Add((close.Sub(open) - 1) / step * step); !t.Before(open); t = t.Add(-step) {
+			if t.Before(now) {
+				out = append(out, t)
+			}
+		}
+	}
+	slices.Reverse(out)
+	return out, nil
+}
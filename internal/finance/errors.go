@@ -0,0 +1,60 @@
+package finance
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// Failures of the fetch and chart layer, matched with errors.Is. The errors
+// returned carry the detail, such as the host and status, for the logs.
+var (
+	// ErrRateLimited means the data source answered 429; waiting helps.
+	ErrRateLimited = errors.New("rate limited by the data source")
+	// ErrSymbolNotFound means the data source doesn't know the symbol.
+	ErrSymbolNotFound = errors.New("symbol not found")
+	// ErrNoData means the symbol exists but has too few bars for the request.
+	ErrNoData = errors.New("no data")
+	// ErrTimeout means the request's deadline passed before the data arrived.
+	ErrTimeout = errors.New("data source timed out")
+)
+
+// InvalidInputError is a request rejected before anything is fetched, such as
+// a malformed window or weight: the caller's to fix. Match it with errors.As.
+type InvalidInputError struct {
+	Err error
+}
+
+func (e *InvalidInputError) Error() string { return e.Err.Error() }
+func (e *InvalidInputError) Unwrap() error { return e.Err }
+
+// invalidInput is an *InvalidInputError formatted as by fmt.Errorf.
+func invalidInput(format string, args ...any) error {
+	return &InvalidInputError{Err: fmt.Errorf(format, args...)}
+}
+
+// classified is a detailed error that also matches the sentinel kind.
+type classified struct {
+	kind, err error
+}
+
+func (e *classified) Error() string   { return e.err.Error() }
+func (e *classified) Unwrap() []error { return []error{e.kind, e.err} }
+
+// classify marks err as a kind failure, keeping its text.
+func classify(kind, err error) error {
+	return &classified{kind: kind, err: err}
+}
+
+// noData is an ErrNoData with msg as its text.
+func noData(msg string) error {
+	return classify(ErrNoData, errors.New(msg))
+}
+
+// withTimeout marks err as ErrTimeout when it comes from an expired deadline.
+func withTimeout(err error) error {
+	if errors.Is(err, context.DeadlineExceeded) && !errors.Is(err, ErrTimeout) {
+		return classify(ErrTimeout, err)
+	}
+	return err
+}
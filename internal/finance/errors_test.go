@@ -0,0 +1,128 @@
+package finance
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// errorKinds are the sentinels a fetch error can match.
+var errorKinds = []error{ErrRateLimited, ErrSymbolNotFound, ErrNoData, ErrTimeout}
+
+// checkKind reports a mismatch when err isn't want, or matches another kind;
+// want nil expects an unclassified error.
+func checkKind(t *testing.T, err, want error) {
+	t.Helper()
+	if err == nil {
+		t.Fatal("err = nil")
+	}
+	for _, kind := range errorKinds {
+		if got := errors.Is(err, kind); got != (kind == want) {
+			t.Errorf("errors.Is(%q, %v) = %v", err, kind, got)
+		}
+	}
+}
+
+func TestYahooResponseError(t *testing.T) {
+	notFound := `{"chart":{"result":null,"error":{"code":"Not Found","description":"No data found, symbol may be delisted"}}}`
+	tests := []struct {
+		name   string
+		status int
+		body   string
+		want   error // nil: an unclassified error
+		ok     bool  // no error at all
+	}{
+		{name: "429", status: http.StatusTooManyRequests, body: "Too Many Requests", want: ErrRateLimited},
+		{name: "edge body with 200", status: http.StatusOK, body: "Edge: Too Many Requests", want: ErrRateLimited},
+		{name: "404", status: http.StatusNotFound, body: notFound, want: ErrSymbolNotFound},
+		{name: "500", status: http.StatusInternalServerError, body: "oops"},
+		{name: "401", status: http.StatusUnauthorized, body: `{"finance":{"error":{"code":"Unauthorized"}}}`},
+		{name: "html with 200", status: http.StatusOK, body: "<!doctype html><p>consent</p>"},
+		{name: "other edge body", status: http.StatusOK, body: "Edge: Something"},
+		{name: "json", status: http.StatusOK, body: `{"chart":{"result":[]}}`, ok: true},
+		{name: "empty body", status: http.StatusOK, body: "", ok: true}, // left to the JSON decoder
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := yahooResponseError("chart", "query1.finance.yahoo.com", tc.status, []byte(tc.body))
+			if tc.ok {
+				if err != nil {
+					t.Fatalf("err = %v, want nil", err)
+				}
+				return
+			}
+			checkKind(t, err, tc.want)
+			if !strings.Contains(err.Error(), "query1.finance.yahoo.com chart") {
+				t.Errorf("err %q doesn't name the host and endpoint", err)
+			}
+		})
+	}
+}
+
+func TestYahooResponseErrorPreview(t *testing.T) {
+	err := yahooResponseError("spark", "h", http.StatusBadGateway, []byte(strings.Repeat("x", 500)))
+	if n := strings.Count(err.Error(), "x"); n != 120 {
+		t.Errorf("body preview has %d bytes, want 120", n)
+	}
+}
+
+func TestChartBarsEmpty(t *testing.T) {
+	for _, body := range []string{
+		`{"chart":{"result":[],"error":null}}`,
+		`{"chart":{"result":null,"error":{"code":"Not Found"}}}`,
+		`{"chart":{"result":[{"timestamp":[1,2],"indicators":{"quote":[]}}]}}`,
+	} {
+		var yc yahooChartResp
+		if err := json.Unmarshal([]byte(body), &yc); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := chartBars(&yc); !errors.Is(err, ErrNoData) {
+			t.Errorf("chartBars(%s) = %v, want ErrNoData", body, err)
+		}
+	}
+	var yc yahooChartResp
+	body := `{"chart":{"result":[{"meta":{"gmtoffset":-14400},"timestamp":[1,2],"indicators":{"quote":[{"close":[10,11]}]}}]}}`
+	if err := json.Unmarshal([]byte(body), &yc); err != nil {
+		t.Fatal(err)
+	}
+	b, err := chartBars(&yc)
+	if err != nil || len(b.close) != 2 || b.gmtOffset != -14400 {
+		t.Errorf("chartBars = %+v, %v; want 2 closes at -14400", b, err)
+	}
+}
+
+func TestWithTimeout(t *testing.T) {
+	expired, cancel := context.WithTimeout(context.Background(), 0)
+	defer cancel()
+	<-expired.Done()
+	sleepErr := sleepCtx(expired, time.Hour)
+
+	tests := []struct {
+		name string
+		err  error
+		want error
+	}{
+		{"deadline", context.DeadlineExceeded, ErrTimeout},
+		{"wrapped deadline", fmt.Errorf("get: %w", context.DeadlineExceeded), ErrTimeout},
+		{"backoff cut short", sleepErr, ErrTimeout},
+		{"rate limited stays", classify(ErrRateLimited, errors.New("429")), ErrRateLimited},
+		{"cancelled is not a timeout", context.Canceled, nil},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := withTimeout(tc.err)
+			checkKind(t, got, tc.want)
+			if !errors.Is(got, tc.err) {
+				t.Errorf("withTimeout lost the cause %v", tc.err)
+			}
+		})
+	}
+	if err := withTimeout(withTimeout(context.DeadlineExceeded)); err.Error() != context.DeadlineExceeded.Error() {
+		t.Errorf("classified twice: %q", err)
+	}
+}
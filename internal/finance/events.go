@@ -0,0 +1,94 @@
+package finance
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+
+	"telegramBotTrade/internal/chartkit"
+)
+
+// Marker texts of the corporate events drawn on daily charts.
+const (
+	eventDividend = "D"
+	eventSplit    = "S"
+)
+
+// eventsLegend is the subtitle of a chart with event markers.
+const eventsLegend = "D ex-dividend • S split"
+
+// corpEvent is an ex-dividend date or a stock split from the chart endpoint's
+// events, at its Unix time.
+type corpEvent struct {
+	at   int64
+	text string // eventDividend or eventSplit
+}
+
+// yahooEvents mirrors the events object of a v8 chart result, requested with
+// events=div,splits and keyed by the event's Unix time as a string.
+type yahooEvents struct {
+	Dividends map[string]struct {
+		Date int64 `json:"date"`
+	} `json:"dividends"`
+	Splits map[string]struct {
+		Date int64 `json:"date"`
+	} `json:"splits"`
+}
+
+// list returns the dividends and splits oldest first; a split and a dividend
+// on the same date keep that order.
+func (e yahooEvents) list() []corpEvent {
+	var out []corpEvent
+	for _, d := range e.Dividends {
+		out = append(out, corpEvent{at: d.Date, text: eventDividend})
+	}
+	for _, s := range e.Splits {
+		out = append(out, corpEvent{at: s.Date, text: eventSplit})
+	}
+	slices.SortFunc(out, func(a, b corpEvent) int {
+		return cmp.Or(cmp.Compare(a.at, b.at), -cmp.Compare(a.text, b.text))
+	})
+	return out
+}
+
+// eventIndex maps an event at Unix time at to the daily bar of ts (sorted,
+// one bar per exchange day) it belongs on, or -1 when it falls outside the
+// days ts spans. An event on a day without a bar, such as a weekend or a
+// holiday, goes to the bar of the nearest day, the later one on a tie, since
+// an ex-date off the calendar takes effect at the next session.
+func eventIndex(ts []int64, at int64, gmtOffset int) int {
+	if len(ts) == 0 {
+		return -1
+	}
+	day := func(t int64) int64 { return (t + int64(gmtOffset)) / daySeconds }
+	d := day(at)
+	if d < day(ts[0]) || d > day(ts[len(ts)-1]) {
+		return -1
+	}
+	i, _ := slices.BinarySearchFunc(ts, d, func(t, d int64) int { return cmp.Compare(day(t), d) })
+	if i > 0 && d-day(ts[i-1]) < day(ts[i])-d {
+		return i - 1
+	}
+	return i
+}
+
+// eventMarks marks the events of b on series 0 of a daily chart of b's bars.
+// Events outside the bars' days are left out, and an event sharing a bar
+// with an earlier one of the other kind is added to its text, e.g. "S D".
+func eventMarks(b bars) []chartkit.Mark {
+	var marks []chartkit.Mark
+	for _, e := range b.events {
+		i := eventIndex(b.ts, e.at, b.gmtOffset)
+		switch {
+		case i < 0:
+			continue
+		case len(marks) > 0 && marks[len(marks)-1].Index == i:
+			if m := &marks[len(marks)-1]; !slices.Contains(strings.Fields(m.Text), e.text) {
+				m.Text += " " + e.text
+			}
+		default:
+			marks = append(marks, chartkit.Mark{Index: i, Text: e.text})
+		}
+	}
+	return marks
+}
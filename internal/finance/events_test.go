@@ -0,0 +1,117 @@
+package finance
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+	"time"
+
+	"telegramBotTrade/internal/chartkit"
+)
+
+// newYork is the gmtoffset Yahoo reports for US listings in summer.
+const newYork = -4 * 3600
+
+// at is the Unix time of a UTC date and time in June 2024.
+func at(day, hour, minute int) int64 {
+	return time.Date(2024, time.June, day, hour, minute, 0, 0, time.UTC).Unix()
+}
+
+// juneBars are daily bars stamped at the 9:30 New York open, from Thursday
+// the 13th to Friday the 21st, with the weekend and Juneteenth (Wednesday
+// the 19th) off.
+var juneBars = []int64{at(13, 13, 30), at(14, 13, 30), at(17, 13, 30), at(18, 13, 30), at(20, 13, 30), at(21, 13, 30)}
+
+func TestEventIndex(t *testing.T) {
+	tests := []struct {
+		name      string
+		ts        []int64
+		at        int64
+		gmtOffset int
+		want      int
+	}{
+		{"on a bar", juneBars, at(17, 13, 30), newYork, 2},
+		{"later the same day", juneBars, at(17, 20, 0), newYork, 2},
+		{"local midnight", juneBars, at(18, 4, 0), newYork, 3},
+		// 22:00 on Monday in New York is already Tuesday in UTC
+		{"evening before, local", juneBars, at(18, 2, 0), newYork, 2},
+		{"evening before, UTC", juneBars, at(18, 2, 0), 0, 3},
+		{"saturday to friday", juneBars, at(15, 13, 30), newYork, 1},
+		{"sunday to monday", juneBars, at(16, 13, 30), newYork, 2},
+		{"holiday tie to the next session", juneBars, at(19, 13, 30), newYork, 4},
+		{"first bar", juneBars, at(13, 4, 0), newYork, 0},
+		{"last bar", juneBars, at(21, 23, 0), newYork, 5},
+		{"before the first bar", juneBars, at(12, 13, 30), newYork, -1},
+		{"after the last bar", juneBars, at(22, 13, 30), newYork, -1},
+		{"single bar", juneBars[:1], at(13, 13, 30), newYork, 0},
+		{"no bars", nil, at(13, 13, 30), newYork, -1},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := eventIndex(tc.ts, tc.at, tc.gmtOffset); got != tc.want {
+				t.Errorf("eventIndex = %d, want %d", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestEventIndexLongWeekend(t *testing.T) {
+	// Friday the 31st of May, then Tuesday the 4th with the Monday off
+	ts := []int64{
+		time.Date(2024, time.May, 31, 13, 30, 0, 0, time.UTC).Unix(),
+		time.Date(2024, time.June, 4, 13, 30, 0, 0, time.UTC).Unix(),
+	}
+	for day, want := range map[int]int{1: 0, 2: 1, 3: 1} { // Saturday, Sunday (a tie), Monday
+		if got := eventIndex(ts, at(day, 13, 30), newYork); got != want {
+			t.Errorf("June %d: eventIndex = %d, want %d", day, got, want)
+		}
+	}
+}
+
+func TestYahooEventsList(t *testing.T) {
+	raw := `{
+		"dividends": {
+			"1718631000": {"amount": 0.25, "date": 1718631000},
+			"1718112600": {"amount": 0.25, "date": 1718112600}
+		},
+		"splits": {
+			"1718631000": {"date": 1718631000, "numerator": 10, "denominator": 1, "splitRatio": "10:1"}
+		}
+	}`
+	var e yahooEvents
+	if err := json.Unmarshal([]byte(raw), &e); err != nil {
+		t.Fatal(err)
+	}
+	want := []corpEvent{
+		{at: 1718112600, text: eventDividend},
+		{at: 1718631000, text: eventSplit},
+		{at: 1718631000, text: eventDividend},
+	}
+	if got := e.list(); !slices.Equal(got, want) {
+		t.Errorf("list = %v, want %v", got, want)
+	}
+	if got := (yahooEvents{}).list(); len(got) != 0 {
+		t.Errorf("list of no events = %v", got)
+	}
+}
+
+func TestEventMarks(t *testing.T) {
+	b := bars{ts: juneBars, gmtOffset: newYork, events: []corpEvent{
+		{at: at(10, 13, 30), text: eventDividend}, // before the bars
+		{at: at(14, 13, 30), text: eventSplit},
+		{at: at(15, 13, 30), text: eventDividend}, // Saturday, onto Friday's split
+		{at: at(15, 15, 0), text: eventDividend},  // a second one there adds nothing
+		{at: at(19, 13, 30), text: eventDividend}, // Juneteenth, onto Thursday
+		{at: at(24, 13, 30), text: eventSplit},    // after the bars
+	}}
+	want := []chartkit.Mark{
+		{Index: 1, Text: "S D"},
+		{Index: 4, Text: "D"},
+	}
+	if got := eventMarks(b); !slices.Equal(got, want) {
+		t.Errorf("eventMarks = %v, want %v", got, want)
+	}
+	if got := eventMarks(bars{ts: juneBars, gmtOffset: newYork}); got != nil {
+		t.Errorf("eventMarks without events = %v, want none", got)
+	}
+}
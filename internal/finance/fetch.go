@@ -1,148 +1,51 @@
 package finance
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"strings"
 	"time"
+
+	"telegramBotTrade/internal/logging"
 )
 
 // fetch5mSeries fetches 5m timestamps and close prices for a single symbol and window range.
-func fetch5mSeries(symbol string, rangeParam string) ([]int64, []float64, error) {
-	hosts := []string{"query1.finance.yahoo.com", "query2.finance.yahoo.com"}
-	backoffs := []time.Duration{200 * time.Millisecond, 500 * time.Millisecond, 1 * time.Second}
-	var yc yahooChartResp
-	var lastErr error
-	for attempt := 0; attempt < len(backoffs)+1; attempt++ {
-		for _, host := range hosts {
-			url := fmt.Sprintf("https://%s/v8/finance/chart/%s?range=%s&interval=5m&includePrePost=true&events=div,splits", host, symbol, rangeParam)
-			req, _ := http.NewRequest("GET", url, nil)
-			req.Header.Set("User-Agent", "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.4 Safari/605.1.15")
-			req.Header.Set("Accept", "application/json, text/javascript, */*; q=0.01")
-			req.Header.Set("Accept-Language", "en-US,en;q=0.9")
-			req.Header.Set("Referer", fmt.Sprintf("https://finance.yahoo.com/quote/%s/chart", strings.ToUpper(symbol)))
-			resp, err := http.DefaultClient.Do(req)
-			if err != nil {
-				lastErr = err
-				continue
-			}
-			body, readErr := io.ReadAll(resp.Body)
-			resp.Body.Close()
-			if readErr != nil {
-				lastErr = fmt.Errorf("failed to read yahoo response: %w", readErr)
-				continue
-			}
-			if resp.StatusCode == http.StatusTooManyRequests || strings.HasPrefix(string(body), "Edge: Too Many Requests") {
-				lastErr = fmt.Errorf("yahoo %s returned 429: Edge: Too Many Requests", host)
-				continue
-			}
-			if resp.StatusCode != http.StatusOK {
-				preview := string(body)
-				if len(preview) > 120 {
-					preview = preview[:120]
-				}
-				lastErr = fmt.Errorf("yahoo %s returned %d: %s", host, resp.StatusCode, preview)
-				continue
-			}
-			if strings.HasPrefix(string(body), "<") || strings.HasPrefix(string(body), "Edge:") {
-				preview := string(body)
-				if len(preview) > 120 {
-					preview = preview[:120]
-				}
-				lastErr = fmt.Errorf("yahoo returned non-json body: %s", preview)
-				continue
-			}
-			if err := json.Unmarshal(body, &yc); err != nil {
-				preview := string(body)
-				if len(preview) > 120 {
-					preview = preview[:120]
-				}
-				lastErr = fmt.Errorf("failed to parse yahoo json: %v; body: %s", err, preview)
-				continue
-			}
-			lastErr = nil
-			break
-		}
-		if lastErr == nil {
-			break
-		}
-		if attempt < len(backoffs) {
-			time.Sleep(backoffs[attempt])
-		}
+func fetch5mSeries(ctx context.Context, symbol string, rangeParam string) ([]int64, []float64, error) {
+	return fetchSeries(ctx, symbol, "5m", rangeParam)
+}
+
+// fetchSeries fetches timestamps and close prices for a single symbol using the given interval and range.
+func fetchSeries(ctx context.Context, symbol string, interval string, rangeParam string) ([]int64, []float64, error) {
+	b, err := fetchBars(ctx, symbol, interval, rangeParam)
+	if err != nil {
+		return nil, nil, err
 	}
-	if lastErr != nil {
-		// Spark fallback
-		var sp yahooSparkResp
-		for attempt := 0; attempt < len(backoffs)+1 && lastErr != nil; attempt++ {
-			for _, host := range hosts {
-				url := fmt.Sprintf("https://%s/v7/finance/spark?symbols=%s&range=%s&interval=5m", host, strings.ToUpper(symbol), rangeParam)
-				req, _ := http.NewRequest("GET", url, nil)
-				req.Header.Set("User-Agent", "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.4 Safari/605.1.15")
-				req.Header.Set("Accept", "application/json, text/javascript, */*; q=0.01")
-				req.Header.Set("Accept-Language", "en-US,en;q=0.9")
-				req.Header.Set("Referer", fmt.Sprintf("https://finance.yahoo.com/quote/%s/chart", strings.ToUpper(symbol)))
-				resp, err := http.DefaultClient.Do(req)
-				if err != nil {
-					lastErr = err
-					continue
-				}
-				body, readErr := io.ReadAll(resp.Body)
-				resp.Body.Close()
-				if readErr != nil {
-					lastErr = fmt.Errorf("failed to read yahoo spark response: %w", readErr)
-					continue
-				}
-				if resp.StatusCode == http.StatusTooManyRequests || strings.HasPrefix(string(body), "Edge: Too Many Requests") {
-					lastErr = fmt.Errorf("yahoo %s returned 429 on spark", host)
-					continue
-				}
-				if resp.StatusCode != http.StatusOK {
-					preview := string(body)
-					if len(preview) > 120 {
-						preview = preview[:120]
-					}
-					lastErr = fmt.Errorf("yahoo %s spark returned %d: %s", host, resp.StatusCode, preview)
-					continue
-				}
-				if strings.HasPrefix(string(body), "<") {
-					lastErr = errors.New("yahoo spark returned non-json body")
-					continue
-				}
-				if err := json.Unmarshal(body, &sp); err != nil {
-					lastErr = fmt.Errorf("failed to parse yahoo spark json: %v", err)
-					continue
-				}
-				if len(sp.Spark.Result) > 0 && len(sp.Spark.Result[0].Response) > 0 {
-					ts := sp.Spark.Result[0].Response[0].Timestamp
-					cl := sp.Spark.Result[0].Response[0].Close
-					ts, cl = filterNonNegative(ts, cl)
-					ts, cl = filterIQR(ts, cl, 1.5, 20)
-					return ts, cl, nil
-				}
-			}
-			if attempt < len(backoffs) {
-				time.Sleep(backoffs[attempt])
-			}
+	return b.ts, b.close, nil
+}
+
+// fetchBars fetches a symbol's bars from the v8 chart endpoint, falling back to
+// spark (closes only, no volume) when it keeps failing. Bars are cleaned with
+// filterNonNegative and filterIQR. Intraday bars are checked against the
+// exchange's regular session and a large shortfall is logged and noted in
+// ctx's Freshness.
+func fetchBars(ctx context.Context, symbol string, interval string, rangeParam string) (b bars, err error) {
+	defer func() {
+		if err != nil || len(b.ts) == 0 {
+			return
 		}
-		if lastErr != nil {
-			return nil, nil, lastErr
+		noteLastBar(ctx, time.Unix(b.ts[len(b.ts)-1], 0))
+		if missing := missingBars(b.ts, b.session, interval, time.Now()); missing >= MissingBarsWarn {
+			logging.FromContext(ctx).Warn("yahoo: bars missing from session", "symbol", symbol, "interval", interval, "range", rangeParam, "missing_pct", int(missing*100+0.5))
+			noteMissingBars(ctx, symbol, missing)
 		}
+	}()
+	if src := seriesSourceFrom(ctx); src != nil {
+		return sourceBars(ctx, src, symbol, interval, rangeParam)
 	}
-	if len(yc.Chart.Result) == 0 || len(yc.Chart.Result[0].Indicators.Quote) == 0 {
-		return nil, nil, errors.New("no data")
-	}
-	ts := yc.Chart.Result[0].Timestamp
-	cl := yc.Chart.Result[0].Indicators.Quote[0].Close
-	ts, cl = filterNonNegative(ts, cl)
-	ts, cl = filterIQR(ts, cl, 1.5, 20)
-	return ts, cl, nil
-}
-
-// fetchSeries fetches timestamps and close prices for a single symbol using the given interval and range.
-func fetchSeries(symbol string, interval string, rangeParam string) ([]int64, []float64, error) {
 	hosts := []string{"query1.finance.yahoo.com", "query2.finance.yahoo.com"}
 	backoffs := []time.Duration{200 * time.Millisecond, 500 * time.Millisecond, 1 * time.Second}
 	var yc yahooChartResp
@@ -150,12 +53,11 @@ func fetchSeries(symbol string, interval string, rangeParam string) ([]int64, []
 	for attempt := 0; attempt < len(backoffs)+1; attempt++ {
 		for _, host := range hosts {
 			url := fmt.Sprintf("https://%s/v8/finance/chart/%s?range=%s&interval=%s&includePrePost=true&events=div,splits", host, symbol, rangeParam, interval)
-			req, _ := http.NewRequest("GET", url, nil)
-			req.Header.Set("User-Agent", "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.4 Safari/605.1.15")
+			req, _ := http.NewRequestWithContext(ctx, "GET", url, nil)
 			req.Header.Set("Accept", "application/json, text/javascript, */*; q=0.01")
 			req.Header.Set("Accept-Language", "en-US,en;q=0.9")
 			req.Header.Set("Referer", fmt.Sprintf("https://finance.yahoo.com/quote/%s/chart", strings.ToUpper(symbol)))
-			resp, err := http.DefaultClient.Do(req)
+			resp, err := yahooDo(http.DefaultClient, req)
 			if err != nil {
 				lastErr = err
 				continue
@@ -166,32 +68,11 @@ func fetchSeries(symbol string, interval string, rangeParam string) ([]int64, []
 				lastErr = fmt.Errorf("failed to read yahoo response: %w", readErr)
 				continue
 			}
-			if resp.StatusCode == http.StatusTooManyRequests || strings.HasPrefix(string(body), "Edge: Too Many Requests") {
-				lastErr = fmt.Errorf("yahoo %s returned 429: Edge: Too Many Requests", host)
-				continue
-			}
-			if resp.StatusCode != http.StatusOK {
-				preview := string(body)
-				if len(preview) > 120 {
-					preview = preview[:120]
-				}
-				lastErr = fmt.Errorf("yahoo %s returned %d: %s", host, resp.StatusCode, preview)
-				continue
-			}
-			if strings.HasPrefix(string(body), "<") || strings.HasPrefix(string(body), "Edge:") {
-				preview := string(body)
-				if len(preview) > 120 {
-					preview = preview[:120]
-				}
-				lastErr = fmt.Errorf("yahoo returned non-json body: %s", preview)
+			if lastErr = yahooResponseError("chart", host, resp.StatusCode, body); lastErr != nil {
 				continue
 			}
 			if err := json.Unmarshal(body, &yc); err != nil {
-				preview := string(body)
-				if len(preview) > 120 {
-					preview = preview[:120]
-				}
-				lastErr = fmt.Errorf("failed to parse yahoo json: %v; body: %s", err, preview)
+				lastErr = fmt.Errorf("failed to parse yahoo json: %v; body: %s", err, bodyPreview(body))
 				continue
 			}
 			lastErr = nil
@@ -200,22 +81,25 @@ func fetchSeries(symbol string, interval string, rangeParam string) ([]int64, []
 		if lastErr == nil {
 			break
 		}
+		logging.FromContext(ctx).Warn("yahoo: chart fetch attempt failed", "symbol", symbol, "attempt", attempt+1, "err", lastErr)
 		if attempt < len(backoffs) {
-			time.Sleep(backoffs[attempt])
+			if err := sleepCtx(ctx, withJitter(backoffs[attempt])); err != nil {
+				return bars{}, withTimeout(err)
+			}
 		}
 	}
 	if lastErr != nil {
+		logging.FromContext(ctx).Info("yahoo: falling back to spark endpoint", "symbol", symbol)
 		// Spark fallback
 		var sp yahooSparkResp
 		for attempt := 0; attempt < len(backoffs)+1 && lastErr != nil; attempt++ {
 			for _, host := range hosts {
 				url := fmt.Sprintf("https://%s/v7/finance/spark?symbols=%s&range=%s&interval=%s", host, strings.ToUpper(symbol), rangeParam, interval)
-				req, _ := http.NewRequest("GET", url, nil)
-				req.Header.Set("User-Agent", "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.4 Safari/605.1.15")
+				req, _ := http.NewRequestWithContext(ctx, "GET", url, nil)
 				req.Header.Set("Accept", "application/json, text/javascript, */*; q=0.01")
 				req.Header.Set("Accept-Language", "en-US,en;q=0.9")
 				req.Header.Set("Referer", fmt.Sprintf("https://finance.yahoo.com/quote/%s/chart", strings.ToUpper(symbol)))
-				resp, err := http.DefaultClient.Do(req)
+				resp, err := yahooDo(http.DefaultClient, req)
 				if err != nil {
 					lastErr = err
 					continue
@@ -226,20 +110,7 @@ func fetchSeries(symbol string, interval string, rangeParam string) ([]int64, []
 					lastErr = fmt.Errorf("failed to read yahoo spark response: %w", readErr)
 					continue
 				}
-				if resp.StatusCode == http.StatusTooManyRequests || strings.HasPrefix(string(body), "Edge: Too Many Requests") {
-					lastErr = fmt.Errorf("yahoo %s returned 429 on spark", host)
-					continue
-				}
-				if resp.StatusCode != http.StatusOK {
-					preview := string(body)
-					if len(preview) > 120 {
-						preview = preview[:120]
-					}
-					lastErr = fmt.Errorf("yahoo %s spark returned %d: %s", host, resp.StatusCode, preview)
-					continue
-				}
-				if strings.HasPrefix(string(body), "<") {
-					lastErr = errors.New("yahoo spark returned non-json body")
+				if lastErr = yahooResponseError("spark", host, resp.StatusCode, body); lastErr != nil {
 					continue
 				}
 				if err := json.Unmarshal(body, &sp); err != nil {
@@ -247,27 +118,59 @@ func fetchSeries(symbol string, interval string, rangeParam string) ([]int64, []
 					continue
 				}
 				if len(sp.Spark.Result) > 0 && len(sp.Spark.Result[0].Response) > 0 {
-					ts := sp.Spark.Result[0].Response[0].Timestamp
-					cl := sp.Spark.Result[0].Response[0].Close
-					ts, cl = filterNonNegative(ts, cl)
-					ts, cl = filterIQR(ts, cl, 1.5, 20)
-					return ts, cl, nil
+					r := sp.Spark.Result[0].Response[0]
+					return bars{ts: r.Timestamp, close: r.Close}.filtered(), nil
 				}
 			}
 			if attempt < len(backoffs) {
-				time.Sleep(backoffs[attempt])
+				if err := sleepCtx(ctx, withJitter(backoffs[attempt])); err != nil {
+					return bars{}, withTimeout(err)
+				}
 			}
 		}
 		if lastErr != nil {
-			return nil, nil, lastErr
+			logging.FromContext(ctx).Error("yahoo: fetch failed", "symbol", symbol, "err", lastErr)
+			return bars{}, withTimeout(lastErr)
 		}
 	}
+	return chartBars(&yc)
+}
+
+// chartBars is the cleaned bars of a v8 chart response, or ErrNoData when it
+// has no quotes.
+func chartBars(yc *yahooChartResp) (bars, error) {
 	if len(yc.Chart.Result) == 0 || len(yc.Chart.Result[0].Indicators.Quote) == 0 {
-		return nil, nil, errors.New("no data")
+		return bars{}, ErrNoData
+	}
+	res := yc.Chart.Result[0]
+	q := res.Indicators.Quote[0]
+	return bars{ts: res.Timestamp, open: q.Open, high: q.High, low: q.Low, close: q.Close, volume: q.Volume, gmtOffset: res.Meta.GmtOffset, events: res.Events.list(),
+		session: tradingPeriod{start: res.Meta.CurrentTradingPeriod.Regular.Start, end: res.Meta.CurrentTradingPeriod.Regular.End}.withUSCalendar()}.filtered(), nil
+}
+
+// yahooResponseError classifies a Yahoo answer before its JSON is parsed: a
+// 429, or the "Edge: Too Many Requests" body Yahoo also sends with a 200, is
+// ErrRateLimited, a 404 ErrSymbolNotFound, and any other status or a body
+// that isn't JSON a plain error. It is nil for a body worth parsing.
+// endpoint names the endpoint in the messages, e.g. "chart" or "spark".
+func yahooResponseError(endpoint, host string, status int, body []byte) error {
+	switch {
+	case status == http.StatusTooManyRequests || bytes.HasPrefix(body, []byte("Edge: Too Many Requests")):
+		return classify(ErrRateLimited, fmt.Errorf("yahoo %s %s returned 429: Too Many Requests", host, endpoint))
+	case status == http.StatusNotFound:
+		return classify(ErrSymbolNotFound, fmt.Errorf("yahoo %s %s returned 404: %s", host, endpoint, bodyPreview(body)))
+	case status != http.StatusOK:
+		return fmt.Errorf("yahoo %s %s returned %d: %s", host, endpoint, status, bodyPreview(body))
+	case bytes.HasPrefix(body, []byte("<")) || bytes.HasPrefix(body, []byte("Edge:")):
+		return fmt.Errorf("yahoo %s %s returned a non-json body: %s", host, endpoint, bodyPreview(body))
+	}
+	return nil
+}
+
+// bodyPreview is the start of a response body for error messages.
+func bodyPreview(body []byte) string {
+	if len(body) > 120 {
+		body = body[:120]
 	}
-	ts := yc.Chart.Result[0].Timestamp
-	cl := yc.Chart.Result[0].Indicators.Quote[0].Close
-	ts, cl = filterNonNegative(ts, cl)
-	ts, cl = filterIQR(ts, cl, 1.5, 20)
-	return ts, cl, nil
+	return string(body)
 }
@@ -0,0 +1,104 @@
+package finance
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// StaleAfter is how old the last bar may get during US market hours before a
+// chart is flagged as stale.
+const StaleAfter = 20 * time.Minute
+
+type freshnessKey struct{}
+
+// Freshness collects the time of the last bar of every series fetched while
+// building one chart, and the series most short of its session's bars.
+// Charts served from the cache report what was recorded when they were
+// rendered.
+type Freshness struct {
+	mu   sync.Mutex
+	asOf time.Time
+	gap  missingNote
+}
+
+// missingNote is a series' share of expected session bars the source left out.
+type missingNote struct {
+	symbol string
+	share  float64
+}
+
+// WithFreshness returns a context whose fetches are recorded in the returned Freshness.
+func WithFreshness(ctx context.Context) (context.Context, *Freshness) {
+	f := &Freshness{}
+	return context.WithValue(ctx, freshnessKey{}, f), f
+}
+
+func freshnessFrom(ctx context.Context) *Freshness {
+	f, _ := ctx.Value(freshnessKey{}).(*Freshness)
+	return f
+}
+
+// noteLastBar records t, keeping the oldest: a multi-symbol chart is only as
+// fresh as its stalest series.
+func noteLastBar(ctx context.Context, t time.Time) {
+	f := freshnessFrom(ctx)
+	if f == nil || t.IsZero() {
+		return
+	}
+	f.mu.Lock()
+	if f.asOf.IsZero() || t.Before(f.asOf) {
+		f.asOf = t
+	}
+	f.mu.Unlock()
+}
+
+// noteMissingBars records that share of symbol's session bars are missing,
+// keeping the largest share across the chart's series.
+func noteMissingBars(ctx context.Context, symbol string, share float64) {
+	f := freshnessFrom(ctx)
+	if f == nil || share <= 0 {
+		return
+	}
+	f.mu.Lock()
+	if share > f.gap.share {
+		f.gap = missingNote{symbol: symbol, share: share}
+	}
+	f.mu.Unlock()
+}
+
+// MissingBars returns the series with the largest share of its session's
+// bars missing from the source, at least MissingBarsWarn, or a zero share
+// when every series was complete enough.
+func (f *Freshness) MissingBars() (symbol string, share float64) {
+	if f == nil {
+		return "", 0
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.gap.symbol, f.gap.share
+}
+
+// AsOf is the time of the oldest last bar, or zero when nothing was fetched.
+func (f *Freshness) AsOf() time.Time {
+	if f == nil {
+		return time.Time{}
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.asOf
+}
+
+// Stale reports whether the data is older than StaleAfter while the US market
+// is open. Outside regular hours the last bar is expected to be old.
+func (f *Freshness) Stale(now time.Time) bool {
+	asOf := f.AsOf()
+	return !asOf.IsZero() && usMarketOpen(now) && now.Sub(asOf) > StaleAfter
+}
+
+// usMarketOpen reports whether t falls in an NYSE regular session, see
+// USSession.
+func usMarketOpen(t time.Time) bool {
+	open, close, ok := USSession(t)
+	return ok && !t.Before(open) && t.Before(close)
+}
@@ -0,0 +1,213 @@
+package finance
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"telegramBotTrade/internal/storage"
+)
+
+// The chart builders are rendered from fixed synthetic series and compared
+// with the goldens in testdata/golden.json, so axis, label and layout
+// regressions show up before a user posts a screenshot.
+//
+//	go test ./internal/finance -run Golden                       # check
+//	go test ./internal/finance -run Golden -update               # accept the current renders
+//	go test ./internal/finance -run Golden -golden-out /tmp/charts # also write the PNGs to look at
+//
+// UPDATE_GOLDEN=1 works like -update.
+var (
+	updateGolden = flag.Bool("update", false, "rewrite testdata/golden.json from the current renders")
+	goldenOut    = flag.String("golden-out", "", "directory to write the rendered golden PNGs to")
+)
+
+const goldenPath = "testdata/golden.json"
+
+// golden is the stored fingerprint of one render.
+type golden struct {
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+	Pixels string `json:"pixels"` // sha256 of the decoded RGBA pixels
+}
+
+var goldenCases = []struct {
+	name   string
+	render func(ctx context.Context, opts RenderOptions) (ChartResult, error)
+}{
+	{"5m_1d", func(ctx context.Context, o RenderOptions) (ChartResult, error) {
+		return Make5mChart(ctx, "AAA", "1d", o)
+	}},
+	{"5m_1w_vwap", func(ctx context.Context, o RenderOptions) (ChartResult, error) {
+		o.VWAP = true
+		return Make5mChart(ctx, "AAA", "1w", o)
+	}},
+	{"5m_prevclose", func(ctx context.Context, o RenderOptions) (ChartResult, error) {
+		o.Anchor = AnchorPrevClose
+		return Make5mChart(ctx, "AAA", "", o)
+	}},
+	{"custom_15m_open", func(ctx context.Context, o RenderOptions) (ChartResult, error) {
+		o.Anchor = AnchorOpen
+		return MakeChart(ctx, "BBB", "15m", "", o)
+	}},
+	{"custom_1d_6m", func(ctx context.Context, o RenderOptions) (ChartResult, error) {
+		return MakeChart(ctx, "BBB", "1d", "6m", o)
+	}},
+	{"custom_1d_10y_resampled", func(ctx context.Context, o RenderOptions) (ChartResult, error) {
+		return MakeChart(ctx, "BBB", "1d", "10y", o)
+	}},
+	{"multi_two_axes", func(ctx context.Context, o RenderOptions) (ChartResult, error) {
+		return MakeMultiChart(ctx, []string{"AAA", "BBB"}, "1h", "1m", o)
+	}},
+	{"multi_normalized", func(ctx context.Context, o RenderOptions) (ChartResult, error) {
+		return MakeMultiChart(ctx, []string{"AAA", "BBB", "CCC"}, "1d", "1y", o)
+	}},
+	{"multi_5m", func(ctx context.Context, o RenderOptions) (ChartResult, error) {
+		return MakeMulti5mChart(ctx, []string{"AAA", "BBB"}, "1d", o)
+	}},
+	{"indexed", func(ctx context.Context, o RenderOptions) (ChartResult, error) {
+		return MakeIndexedChart(ctx, []string{"AAA", "BBB", "CCC"}, "1d", "6m", true, o)
+	}},
+	{"ew_portfolio", func(ctx context.Context, o RenderOptions) (ChartResult, error) {
+		return MakePortfolioChart(ctx, []string{"AAA", "BBB"}, "1y", o)
+	}},
+	{"weighted_portfolio", func(ctx context.Context, o RenderOptions) (ChartResult, error) {
+		return MakeWeightedPortfolioChart(ctx, []string{"AAA", "BBB"}, []float64{0.6, -0.2}, "1y", o)
+	}},
+	{"weighted_portfolio_detail", func(ctx context.Context, o RenderOptions) (ChartResult, error) {
+		return MakeWeightedPortfolioDetailChart(ctx, []string{"AAA", "BBB", "CCC"}, []float64{0.5, 0.3, 0.2}, "1y", o)
+	}},
+	{"atr", func(ctx context.Context, o RenderOptions) (ChartResult, error) {
+		img, _, err := MakeATRChart(ctx, "AAA", 14, "6m", o)
+		return ChartResult{Image: img}, err
+	}},
+	{"macd", func(ctx context.Context, o RenderOptions) (ChartResult, error) {
+		img, _, err := MakeMACDChart(ctx, "BBB", "1d", "1y", o)
+		return ChartResult{Image: img}, err
+	}},
+	{"vix", func(ctx context.Context, o RenderOptions) (ChartResult, error) {
+		img, _, err := MakeVIXChart(ctx, "6m", o)
+		return ChartResult{Image: img}, err
+	}},
+	{"yield", func(ctx context.Context, o RenderOptions) (ChartResult, error) {
+		img, _, err := MakeYieldChart(ctx, "1y", o)
+		return ChartResult{Image: img}, err
+	}},
+	{"curve", func(ctx context.Context, o RenderOptions) (ChartResult, error) {
+		img, _, err := MakeCurveChart(ctx, o)
+		return ChartResult{Image: img}, err
+	}},
+	{"heat", func(ctx context.Context, o RenderOptions) (ChartResult, error) {
+		return MakeHeatChart(ctx, []string{"AAA", "BBB", "CCC", "DDD", "EEE", "FFF"}, "1m", o)
+	}},
+	{"weighted_portfolio_hist", func(ctx context.Context, o RenderOptions) (ChartResult, error) {
+		img, err := MakeWeightedPortfolioHistogram(ctx, []string{"AAA", "BBB"}, []float64{0.6, -0.2}, "1y", HistogramOptions{Normal: true}, o)
+		return ChartResult{Image: img}, err
+	}},
+	{"montecarlo", func(ctx context.Context, o RenderOptions) (ChartResult, error) {
+		p := MonteCarloParams{HorizonDays: 252, Sims: 500, Seed: 1}
+		img, _, err := MakeMonteCarloChart(ctx, []string{"AAA", "BBB"}, []float64{0.6, 0.4}, "1y", p, o)
+		return ChartResult{Image: img}, err
+	}},
+	{"usage_pie", func(ctx context.Context, o RenderOptions) (ChartResult, error) {
+		stats := map[string]*storage.UsageStats{"charts": {Count: 42}, "portfolio": {Count: 17}, "ai": {Count: 9}}
+		img, err := NewUsageAnalytics().MakeUsageChart(ctx, stats, 7)
+		return ChartResult{Image: img}, err
+	}},
+}
+
+// fingerprint decodes a PNG and checks it is drawable before hashing its pixels.
+func fingerprint(img []byte) (golden, error) {
+	m, err := png.Decode(bytes.NewReader(img))
+	if err != nil {
+		return golden{}, fmt.Errorf("not a PNG: %w", err)
+	}
+	b := m.Bounds()
+	if b.Dx() < 100 || b.Dy() < 100 {
+		return golden{}, fmt.Errorf("image is only %dx%d", b.Dx(), b.Dy())
+	}
+	rgba := image.NewRGBA(b)
+	first, uniform := m.At(b.Min.X, b.Min.Y), true
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			c := m.At(x, y)
+			rgba.Set(x, y, c)
+			if uniform && c != first {
+				uniform = false
+			}
+		}
+	}
+	if uniform {
+		return golden{}, fmt.Errorf("image is blank")
+	}
+	sum := sha256.Sum256(rgba.Pix)
+	return golden{Width: b.Dx(), Height: b.Dy(), Pixels: hex.EncodeToString(sum[:])}, nil
+}
+
+func TestChartGolden(t *testing.T) {
+	update := *updateGolden || os.Getenv("UPDATE_GOLDEN") != ""
+	want := map[string]golden{}
+	if data, err := os.ReadFile(goldenPath); err == nil {
+		if err := json.Unmarshal(data, &want); err != nil {
+			t.Fatalf("bad golden file %s: %v", goldenPath, err)
+		}
+	} else if !update {
+		t.Fatalf("no golden file, run with -update first: %v", err)
+	}
+
+	ctx := WithSeriesSource(context.Background(), SyntheticSource{})
+	opts := RenderOptions{Theme: "light", Location: time.UTC}
+	got := map[string]golden{}
+	for _, c := range goldenCases {
+		t.Run(c.name, func(t *testing.T) {
+			res, err := c.render(ctx, opts)
+			if err != nil {
+				t.Fatalf("render: %v", err)
+			}
+			fp, err := fingerprint(res.Image)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got[c.name] = fp
+			if *goldenOut != "" {
+				if err := os.WriteFile(filepath.Join(*goldenOut, c.name+".png"), res.Image, 0o644); err != nil {
+					t.Errorf("write png: %v", err)
+				}
+			}
+			if update {
+				return
+			}
+			if w, ok := want[c.name]; !ok {
+				t.Errorf("no golden, run with -update")
+			} else if w != fp {
+				t.Errorf("render changed: got %+v, want %+v; run with -update if intended", fp, w)
+			}
+		})
+	}
+	if update && !t.Failed() {
+		// charts filtered out with -run keep their stored golden
+		out := map[string]golden{}
+		for _, c := range goldenCases {
+			if fp, ok := got[c.name]; ok {
+				out[c.name] = fp
+			} else if fp, ok := want[c.name]; ok {
+				out[c.name] = fp
+			}
+		}
+		data, _ := json.MarshalIndent(out, "", "  ")
+		if err := os.WriteFile(goldenPath, append(data, '\n'), 0o644); err != nil {
+			t.Fatalf("write golden: %v", err)
+		}
+		t.Logf("goldens updated: %d of %d charts in %s", len(got), len(out), goldenPath)
+	}
+}
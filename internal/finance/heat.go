@@ -0,0 +1,70 @@
+package finance
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"telegramBotTrade/internal/chartkit"
+)
+
+// heatScales is the change, either side of zero, a heat strip draws in its
+// deepest color for each Yahoo range, so one day's strip is comparable with
+// the next instead of always saturating at its biggest mover.
+var heatScales = map[string]float64{
+	"1d": 3, "5d": 6, "1mo": 10, "3mo": 15, "6mo": 25,
+	"1y": 40, "2y": 60, "5y": 100, "10y": 200, "30y": 400,
+}
+
+// MakeHeatChart renders each symbol's change over window as one strip of
+// colored cells, from daily closes. The 1d window (the default) is the
+// change from the previous close. Symbols that fail to fetch are left out
+// and returned as skipped, as long as one remains.
+func MakeHeatChart(ctx context.Context, symbols []string, window string, opts RenderOptions) (ChartResult, error) {
+	w := strings.ToLower(strings.TrimSpace(window))
+	if w == "" {
+		w = "1d"
+	}
+	rng := "1d"
+	if w != "1d" {
+		_, rng, _ = normalizeIntervalWindow("1d", w)
+	}
+	fetchRange := rng
+	if rng == "1d" {
+		// a 1d range holds a single daily bar
+		fetchRange = "5d"
+	}
+	arr, skipped, err := fetchSymbols(symbols, 1, func(symbol string) ([]int64, []float64, error) {
+		ts, cl, err := fetchSeries(ctx, symbol, "1d", fetchRange)
+		if err == nil && len(cl) < 2 {
+			err = noData("not enough data points")
+		}
+		return ts, cl, err
+	})
+	if err != nil {
+		return ChartResult{Meta: ChartMeta{Skipped: skipped}}, err
+	}
+
+	names := make([]string, len(arr))
+	rets := make([]float64, len(arr))
+	var last int64
+	for i, x := range arr {
+		cl := x.cl
+		if rng == "1d" {
+			cl = cl[len(cl)-2:]
+		}
+		names[i], rets[i] = x.sym, windowReturn(cl)
+		last = max(last, x.ts[len(x.ts)-1])
+	}
+	img, err := renderChart(ctx, chartkit.Heat{
+		Style:  opts.style(),
+		Title:  "Change • " + strings.ToUpper(w),
+		Labels: names,
+		Values: rets,
+		Scale:  heatScales[rng],
+	}.Render)
+	if err != nil {
+		return ChartResult{}, fmt.Errorf("failed to render chart: %w", err)
+	}
+	return ChartResult{Image: img, Meta: multiMeta(names, rets, "1d", rng, len(arr), last, skipped)}, nil
+}
@@ -0,0 +1,19 @@
+package finance
+
+import "testing"
+
+func TestHeatScales(t *testing.T) {
+	// every window a strip can show has a scale, wider for longer windows
+	prev := 0.0
+	for _, rng := range windowRanks {
+		s, ok := heatScales[rng]
+		if !ok {
+			t.Errorf("no heat scale for %s", rng)
+			continue
+		}
+		if s <= prev {
+			t.Errorf("heat scale %v for %s is not wider than the shorter window's %v", s, rng, prev)
+		}
+		prev = s
+	}
+}
@@ -0,0 +1,180 @@
+package finance
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+
+	"telegramBotTrade/internal/chartkit"
+)
+
+// HistogramFixedWidth is the bin width, in percent, of /port hist=fixed, and
+// the automatic width when the returns are too few or too flat to estimate one.
+const HistogramFixedWidth = 0.25
+
+// histogramMaxBins caps the bars drawn; wider ranges get wider bins.
+const histogramMaxBins = 60
+
+// HistogramOptions choose how a returns histogram is binned and drawn.
+type HistogramOptions struct {
+	BinWidth float64 // in percent; 0 picks the width by Freedman–Diaconis
+	Normal   bool    // overlay the normal distribution with the same mean and volatility
+}
+
+// histogramBins is a returns histogram: Counts[i] returns fell in
+// [Start+i*Width, Start+(i+1)*Width).
+type histogramBins struct {
+	Start, Width float64
+	Counts       []int
+}
+
+// freedmanDiaconisWidth is the Freedman–Diaconis bin width of xs, 2·IQR/∛n,
+// rounded to a 1, 2, 2.5 or 5 step so the bin edges read cleanly. Samples too
+// small or too flat for an interquartile range get HistogramFixedWidth.
+func freedmanDiaconisWidth(xs []float64) float64 {
+	if len(xs) < 4 {
+		return HistogramFixedWidth
+	}
+	sorted := slices.Clone(xs)
+	slices.Sort(sorted)
+	iqr := percentileSorted(sorted, 75) - percentileSorted(sorted, 25)
+	w := 2 * iqr / math.Cbrt(float64(len(xs)))
+	if !(w > 0) {
+		return HistogramFixedWidth
+	}
+	return niceWidth(w)
+}
+
+// niceWidth rounds w to the nearest 1, 2, 2.5 or 5 times a power of ten,
+// nearest by ratio.
+func niceWidth(w float64) float64 {
+	pow := math.Pow(10, math.Floor(math.Log10(w)))
+	best := pow
+	for _, m := range []float64{2, 2.5, 5, 10} {
+		if math.Abs(math.Log(m*pow/w)) < math.Abs(math.Log(best/w)) {
+			best = m * pow
+		}
+	}
+	return best
+}
+
+// binReturns counts xs into bins of width aligned on zero, so one edge sits
+// at 0% and up and down days never share a bar. A width that would need more
+// than histogramMaxBins bins is widened to fit.
+func binReturns(xs []float64, width float64) histogramBins {
+	if len(xs) == 0 || !(width > 0) {
+		return histogramBins{Width: width}
+	}
+	lo, hi := slices.Min(xs), slices.Max(xs)
+	for math.Floor(hi/width)-math.Floor(lo/width)+1 > histogramMaxBins {
+		width = niceWidth(width * 2)
+	}
+	b := histogramBins{Start: math.Floor(lo/width) * width, Width: width}
+	b.Counts = make([]int, int(math.Floor(hi/width)-math.Floor(lo/width))+1)
+	for _, x := range xs {
+		i := int(math.Floor((x - b.Start) / width))
+		// a return exactly on the top edge lands past the last bin in floating point
+		b.Counts[min(max(i, 0), len(b.Counts)-1)]++
+	}
+	return b
+}
+
+// normalCounts returns how many of n draws from a normal distribution with
+// mean and sd would fall in each bin, for overlaying on the histogram.
+func (b histogramBins) normalCounts(n int, mean, sd float64) []float64 {
+	out := make([]float64, len(b.Counts))
+	if sd <= 0 {
+		return out
+	}
+	cdf := func(x float64) float64 { return 0.5 * math.Erfc(-(x-mean)/(sd*math.Sqrt2)) }
+	for i := range out {
+		lo := b.Start + float64(i)*b.Width
+		out[i] = float64(n) * (cdf(lo+b.Width) - cdf(lo))
+	}
+	return out
+}
+
+// excessKurtosis is the sample kurtosis of xs minus 3, the normal
+// distribution's: above zero the tails are fatter than normal.
+func excessKurtosis(xs []float64, mean, sd float64) float64 {
+	if len(xs) < 4 || sd <= 0 {
+		return 0
+	}
+	m4 := 0.0
+	for _, x := range xs {
+		d := (x - mean) / sd
+		m4 += d * d * d * d
+	}
+	return m4/float64(len(xs)) - 3
+}
+
+// MakeWeightedPortfolioHistogram runs the same backtest as
+// MakeWeightedPortfolioChart and draws the distribution of its daily returns.
+func MakeWeightedPortfolioHistogram(ctx context.Context, symbols []string, weights []float64, window string, hist HistogramOptions, opts RenderOptions) ([]byte, error) {
+	weightStrs := make([]string, len(weights))
+	for i, w := range weights {
+		weightStrs[i] = fmt.Sprintf("%.3f", w)
+	}
+	cacheKey := fmt.Sprintf("wporthist-%s-%s-%s-%g-%t", strings.Join(symbols, ","), strings.Join(weightStrs, ","), window, hist.BinWidth, hist.Normal) + opts.cacheSuffix()
+	if res, found := cacheGet(ctx, cacheKey); found {
+		return res.Image, nil
+	}
+
+	run, err := runWeightedPortfolio(ctx, symbols, weights, window)
+	if err != nil {
+		return nil, err
+	}
+	rets := make([]float64, len(run.portfolio.Returns))
+	for i, r := range run.portfolio.Returns {
+		rets[i] = r * 100
+	}
+	if len(rets) < 2 {
+		return nil, fmt.Errorf("need at least 2 daily returns for a histogram, got %d", len(rets))
+	}
+
+	width := hist.BinWidth
+	if width <= 0 {
+		width = freedmanDiaconisWidth(rets)
+	}
+	bins := binReturns(rets, width)
+	mean, sd := meanStd(rets)
+
+	labels := make([]string, len(bins.Counts))
+	counts := make([]float64, len(bins.Counts))
+	decimals := max(0, int(math.Ceil(-math.Log10(bins.Width))))
+	for i, c := range bins.Counts {
+		labels[i] = fmt.Sprintf("%.*f", decimals, bins.Start+(float64(i)+0.5)*bins.Width)
+		counts[i] = float64(c)
+	}
+	chart := chartkit.Histogram{
+		Style:  opts.style(),
+		Title:  "Weighted Portfolio Daily Returns (%)",
+		Labels: labels,
+		Counts: counts,
+		Split:  splitLabels(len(labels)),
+		Width:  800,
+	}
+	chart.Subtitle = fmt.Sprintf("%d days | Bin: %g%% | Mean: %.2f%% | SD: %.2f%% | Excess kurtosis: %.1f",
+		len(rets), bins.Width, mean, sd, excessKurtosis(rets, mean, sd))
+	if hist.Normal {
+		chart.Curve = bins.normalCounts(len(rets), mean, sd)
+		chart.Names = [2]string{"Days", "Normal"}
+	}
+	buf, err := renderChart(ctx, chart.Render)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render histogram: %w", err)
+	}
+	cacheSet(ctx, cacheKey, ChartResult{Image: buf})
+	return buf, nil
+}
+
+// splitLabels is how many bin labels fit under a histogram: all of a few
+// bins, about ten of many.
+func splitLabels(bins int) int {
+	if bins <= 12 {
+		return 0
+	}
+	return 10
+}
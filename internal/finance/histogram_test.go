@@ -0,0 +1,182 @@
+package finance
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestNiceWidth(t *testing.T) {
+	tests := []struct{ w, want float64 }{
+		{1, 1},
+		{0.3, 0.25},
+		{0.7, 0.5},
+		{0.75, 1}, // 1 is nearer than 0.5 by ratio
+		{3, 2.5},
+		{7.5, 10},
+		{0.04, 0.05},
+		{1.75, 2},
+	}
+	for _, tc := range tests {
+		if got := niceWidth(tc.w); !closeTo(got, tc.want, 1e-12) {
+			t.Errorf("niceWidth(%v) = %v, want %v", tc.w, got, tc.want)
+		}
+	}
+}
+
+func TestFreedmanDiaconisWidth(t *testing.T) {
+	tests := []struct {
+		name string
+		xs   []float64
+		want float64
+	}{
+		// IQR 6.25-2.75 = 3.5 over 8 samples: 2*3.5/2 = 3.5, shown as 2.5
+		{"one to eight", []float64{8, 1, 7, 2, 6, 3, 5, 4}, 2.5},
+		// IQR 1.625+0.125 = 1.75, rounded to 2
+		{"half steps", []float64{2.5, -1, 0, 1, -0.5, 0.5, 1.5, 2}, 2},
+		{"too few", []float64{-1, 0, 1}, HistogramFixedWidth},
+		{"flat", []float64{0.1, 0.1, 0.1, 0.1, 0.1}, HistogramFixedWidth},
+		// one outlier but no spread between the quartiles
+		{"flat with outlier", []float64{0, 0, 0, 0, 5}, HistogramFixedWidth},
+		{"empty", nil, HistogramFixedWidth},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			in := slices.Clone(tc.xs)
+			if got := freedmanDiaconisWidth(tc.xs); !closeTo(got, tc.want, 1e-12) {
+				t.Errorf("width = %v, want %v", got, tc.want)
+			}
+			if !slices.Equal(in, tc.xs) {
+				t.Errorf("input reordered to %v", tc.xs)
+			}
+		})
+	}
+}
+
+func TestBinReturns(t *testing.T) {
+	tests := []struct {
+		name       string
+		xs         []float64
+		width      float64
+		wantStart  float64
+		wantWidth  float64
+		wantCounts []int
+	}{
+		{
+			// an edge at 0 puts 0 with the up days, and 0.25 opens the next bin
+			name:       "fixed width",
+			xs:         []float64{-0.3, -0.1, 0, 0.1, 0.24, 0.25, 0.6},
+			width:      0.25,
+			wantStart:  -0.5,
+			wantWidth:  0.25,
+			wantCounts: []int{1, 1, 3, 1, 1},
+		},
+		{
+			name:       "up and down days apart",
+			xs:         []float64{-0.0001, 0.0001},
+			width:      1,
+			wantStart:  -1,
+			wantWidth:  1,
+			wantCounts: []int{1, 1},
+		},
+		{
+			name:       "single value",
+			xs:         []float64{1.3},
+			width:      0.5,
+			wantStart:  1,
+			wantWidth:  0.5,
+			wantCounts: []int{1},
+		},
+		{
+			name:       "all down",
+			xs:         []float64{-2, -1, -0.5},
+			width:      1,
+			wantStart:  -2,
+			wantWidth:  1,
+			wantCounts: []int{1, 2},
+		},
+		{
+			// 81 bins of 0.25 would be too many; 0.5 gives 41
+			name:       "widened to fit",
+			xs:         []float64{-10, 0, 10},
+			width:      0.25,
+			wantStart:  -10,
+			wantWidth:  0.5,
+			wantCounts: append(append(append([]int{1}, make([]int, 19)...), 1), append(make([]int, 19), 1)...),
+		},
+		{name: "empty", xs: nil, width: 0.25, wantWidth: 0.25},
+		{name: "zero width", xs: []float64{1, 2}, width: 0, wantWidth: 0},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			b := binReturns(tc.xs, tc.width)
+			if !closeTo(b.Start, tc.wantStart, 1e-12) || !closeTo(b.Width, tc.wantWidth, 1e-12) {
+				t.Errorf("start %v width %v, want %v and %v", b.Start, b.Width, tc.wantStart, tc.wantWidth)
+			}
+			if !slices.Equal(b.Counts, tc.wantCounts) {
+				t.Errorf("counts = %v, want %v", b.Counts, tc.wantCounts)
+			}
+			if len(b.Counts) > histogramMaxBins {
+				t.Errorf("%d bins, more than %d", len(b.Counts), histogramMaxBins)
+			}
+		})
+	}
+}
+
+func TestBinReturnsCountsEveryReturn(t *testing.T) {
+	// tenths are inexact in binary, so some land a hair off their edge
+	var xs []float64
+	for i := -30; i <= 30; i++ {
+		xs = append(xs, float64(i)/10)
+	}
+	for _, width := range []float64{0.1, 0.2, 0.25, 0.3} {
+		b := binReturns(xs, width)
+		total := 0
+		for _, c := range b.Counts {
+			total += c
+		}
+		if total != len(xs) {
+			t.Errorf("width %v: counted %d of %d returns", width, total, len(xs))
+		}
+	}
+}
+
+func TestNormalCounts(t *testing.T) {
+	b := histogramBins{Start: -5, Width: 1, Counts: make([]int, 10)}
+	got := b.normalCounts(100, 0, 1)
+	total := 0.0
+	for i, v := range got {
+		total += v
+		if !closeTo(v, got[len(got)-1-i], 1e-9) {
+			t.Errorf("bins %d and %d differ: %v and %v", i, len(got)-1-i, v, got[len(got)-1-i])
+		}
+	}
+	// 34.13% of a normal lies within one sd on each side of the mean
+	if !closeTo(got[5], 34.13, 0.01) || !closeTo(total, 100, 0.001) {
+		t.Errorf("bin [0,1) = %v, total %v; want 34.13 of 100", got[5], total)
+	}
+	for _, v := range b.normalCounts(100, 0, 0) {
+		if v != 0 {
+			t.Fatalf("sd 0 gave %v, want no curve", v)
+		}
+	}
+}
+
+func TestExcessKurtosis(t *testing.T) {
+	tests := []struct {
+		name     string
+		xs       []float64
+		mean, sd float64
+		want     float64
+	}{
+		// a two-point distribution has the thinnest possible tails
+		{"two point", []float64{-1, 1, -1, 1}, 0, 1, -2},
+		{"one outlier", []float64{0, 0, 0, 0, 0, 0, 0, 2}, 0, 1, 16.0/8 - 3},
+		{"too few", []float64{-1, 1, 3}, 1, 2, 0},
+		{"flat", []float64{1, 1, 1, 1}, 1, 0, 0},
+	}
+	for _, tc := range tests {
+		if got := excessKurtosis(tc.xs, tc.mean, tc.sd); !closeTo(got, tc.want, 1e-12) {
+			t.Errorf("%s: excessKurtosis = %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
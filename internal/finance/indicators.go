@@ -0,0 +1,108 @@
+package finance
+
+import "math"
+
+// ema returns the exponential moving average of values with the given period,
+// seeded with the simple average of the first period values. Entries before
+// the seed (index < period-1) are zero.
+func ema(values []float64, period int) []float64 {
+	out := make([]float64, len(values))
+	if period <= 0 || len(values) < period {
+		return out
+	}
+	var sum float64
+	for _, v := range values[:period] {
+		sum += v
+	}
+	out[period-1] = sum / float64(period)
+	k := 2 / float64(period+1)
+	for i := period; i < len(values); i++ {
+		out[i] = values[i]*k + out[i-1]*(1-k)
+	}
+	return out
+}
+
+// MACD holds the MACD line, its signal line and the histogram between them.
+// Start is the first index where all three are defined.
+type MACD struct {
+	Line, Signal, Hist []float64
+	Start              int
+}
+
+// computeMACD computes MACD(fast, slow, signal) over closes.
+func computeMACD(closes []float64, fast, slow, signal int) MACD {
+	fastEMA := ema(closes, fast)
+	slowEMA := ema(closes, slow)
+	m := MACD{
+		Line:   make([]float64, len(closes)),
+		Signal: make([]float64, len(closes)),
+		Hist:   make([]float64, len(closes)),
+		Start:  slow - 1 + signal - 1,
+	}
+	if len(closes) < slow {
+		m.Start = len(closes)
+		return m
+	}
+	for i := slow - 1; i < len(closes); i++ {
+		m.Line[i] = fastEMA[i] - slowEMA[i]
+	}
+	sig := ema(m.Line[slow-1:], signal)
+	copy(m.Signal[slow-1:], sig)
+	if m.Start > len(closes) {
+		m.Start = len(closes)
+	}
+	for i := m.Start; i < len(closes); i++ {
+		m.Hist[i] = m.Line[i] - m.Signal[i]
+	}
+	return m
+}
+
+// crossovers counts where a crosses above b (bullish) and below b (bearish)
+// between consecutive points from index start on.
+func crossovers(a, b []float64, start int) (bullish, bearish int) {
+	for i := start + 1; i < len(a) && i < len(b); i++ {
+		prev, cur := a[i-1]-b[i-1], a[i]-b[i]
+		switch {
+		case prev <= 0 && cur > 0:
+			bullish++
+		case prev >= 0 && cur < 0:
+			bearish++
+		}
+	}
+	return bullish, bearish
+}
+
+// trueRange returns each bar's true range: the largest of high-low and the
+// gaps from the previous close to the high and the low, so overnight gaps
+// count. The first bar has no previous close and uses high-low.
+func trueRange(high, low, close []float64) []float64 {
+	out := make([]float64, len(close))
+	for i := range close {
+		tr := high[i] - low[i]
+		if i > 0 {
+			tr = math.Max(tr, math.Abs(high[i]-close[i-1]))
+			tr = math.Max(tr, math.Abs(low[i]-close[i-1]))
+		}
+		out[i] = tr
+	}
+	return out
+}
+
+// atr returns Wilder's average true range over period bars. It is seeded with
+// the mean of the first period true ranges; entries before that are zero.
+func atr(high, low, close []float64, period int) []float64 {
+	tr := trueRange(high, low, close)
+	out := make([]float64, len(tr))
+	if period <= 0 || len(tr) < period {
+		return out
+	}
+	var sum float64
+	for _, v := range tr[:period] {
+		sum += v
+	}
+	out[period-1] = sum / float64(period)
+	for i := period; i < len(tr); i++ {
+		out[i] = (out[i-1]*float64(period-1) + tr[i]) / float64(period)
+	}
+	return out
+}
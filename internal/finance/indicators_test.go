@@ -0,0 +1,172 @@
+package finance
+
+import (
+	"math"
+	"testing"
+)
+
+// closeTo reports whether got and want agree to tol.
+func closeTo(got, want, tol float64) bool {
+	return math.Abs(got-want) <= tol
+}
+
+// The 10-day EMA example of StockCharts' "Moving Averages" ChartSchool
+// article, rounded there to cents.
+var (
+	emaReferenceCloses = []float64{
+		22.27, 22.19, 22.08, 22.17, 22.18, 22.13, 22.23, 22.43, 22.24, 22.29,
+		22.15, 22.39, 22.38, 22.61, 23.36, 24.05, 23.75, 23.83, 23.95, 23.63,
+		23.82, 23.87, 23.65, 23.19, 23.10, 23.33, 22.68, 23.10, 22.40, 22.17,
+	}
+	emaReference10 = []float64{
+		22.22, 22.21, 22.24, 22.27, 22.33, 22.52, 22.80, 22.97, 23.13, 23.28,
+		23.34, 23.43, 23.51, 23.53, 23.47, 23.40, 23.39, 23.26, 23.23, 23.08, 22.92,
+	}
+)
+
+func TestEMAReference(t *testing.T) {
+	got := ema(emaReferenceCloses, 10)
+	for i := range 9 {
+		if got[i] != 0 {
+			t.Errorf("ema[%d] = %v before the seed, want 0", i, got[i])
+		}
+	}
+	for i, want := range emaReference10 {
+		if g := got[9+i]; !closeTo(g, want, 0.005) {
+			t.Errorf("ema[%d] = %.4f, want %.2f", 9+i, g, want)
+		}
+	}
+}
+
+func TestEMAShortInput(t *testing.T) {
+	for _, tc := range []struct {
+		values []float64
+		period int
+	}{
+		{[]float64{1, 2}, 3},
+		{[]float64{1, 2, 3}, 0},
+		{nil, 3},
+	} {
+		for i, v := range ema(tc.values, tc.period) {
+			if v != 0 {
+				t.Errorf("ema(%v, %d)[%d] = %v, want 0", tc.values, tc.period, i, v)
+			}
+		}
+	}
+}
+
+func TestMACD(t *testing.T) {
+	linear := make([]float64, 60)
+	for i := range linear {
+		linear[i] = float64(i + 1)
+	}
+	flat := make([]float64, 60)
+	for i := range flat {
+		flat[i] = 50
+	}
+	tests := []struct {
+		name         string
+		closes       []float64
+		line, signal float64 // from Start on
+		wantStart    int
+	}{
+		// an EMA of a straight line trails it by (period-1)/2, so MACD(12,26)
+		// is 12.5-5.5 = 7 exactly and the signal and histogram follow
+		{name: "linear", closes: linear, line: 7, signal: 7, wantStart: 33},
+		{name: "flat", closes: flat, line: 0, signal: 0, wantStart: 33},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			m := computeMACD(tc.closes, 12, 26, 9)
+			if m.Start != tc.wantStart {
+				t.Fatalf("Start = %d, want %d", m.Start, tc.wantStart)
+			}
+			for i := m.Start; i < len(tc.closes); i++ {
+				if !closeTo(m.Line[i], tc.line, 1e-9) || !closeTo(m.Signal[i], tc.signal, 1e-9) || !closeTo(m.Hist[i], 0, 1e-9) {
+					t.Fatalf("at %d: line %v signal %v hist %v, want %v %v 0", i, m.Line[i], m.Signal[i], m.Hist[i], tc.line, tc.signal)
+				}
+			}
+		})
+	}
+}
+
+func TestMACDShortInput(t *testing.T) {
+	closes := make([]float64, 20)
+	if m := computeMACD(closes, 12, 26, 9); m.Start != len(closes) {
+		t.Errorf("Start = %d for %d closes, want %d", m.Start, len(closes), len(closes))
+	}
+	closes = make([]float64, 30)
+	if m := computeMACD(closes, 12, 26, 9); m.Start != len(closes) {
+		t.Errorf("Start = %d for %d closes, want %d", m.Start, len(closes), len(closes))
+	}
+}
+
+func TestCrossovers(t *testing.T) {
+	tests := []struct {
+		name             string
+		a, b             []float64
+		start            int
+		bullish, bearish int
+	}{
+		{"none", []float64{1, 2, 3}, []float64{0, 0, 0}, 0, 0, 0},
+		{"up", []float64{-1, 1}, []float64{0, 0}, 0, 1, 0},
+		{"down", []float64{1, -1}, []float64{0, 0}, 0, 0, 1},
+		{"touch then cross counts once", []float64{-1, 0, 1}, []float64{0, 0, 0}, 0, 1, 0},
+		{"both", []float64{-1, 1, -1, 1}, []float64{0, 0, 0, 0}, 0, 2, 1},
+		{"before start ignored", []float64{-1, 1, 2}, []float64{0, 0, 0}, 1, 0, 0},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			bull, bear := crossovers(tc.a, tc.b, tc.start)
+			if bull != tc.bullish || bear != tc.bearish {
+				t.Errorf("crossovers = %d bullish, %d bearish; want %d, %d", bull, bear, tc.bullish, tc.bearish)
+			}
+		})
+	}
+}
+
+// atrFixture is five bars with a gap up, a gap down and an inside bar.
+var atrFixture = struct {
+	high, low, close []float64
+	tr               []float64
+}{
+	high:  []float64{10, 12, 10, 10.2, 10.6},
+	low:   []float64{9, 11.5, 9.6, 9.7, 10.1},
+	close: []float64{9.5, 11.8, 9.8, 10, 10.5},
+	// first bar high-low; gap up reaches back to the 9.5 close; gap down to
+	// the 11.8 close; then plain high-low ranges and a small gap
+	tr: []float64{1, 2.5, 2.2, 0.5, 0.6},
+}
+
+func TestTrueRange(t *testing.T) {
+	f := atrFixture
+	got := trueRange(f.high, f.low, f.close)
+	for i, want := range f.tr {
+		if !closeTo(got[i], want, 1e-9) {
+			t.Errorf("trueRange[%d] = %v, want %v", i, got[i], want)
+		}
+	}
+}
+
+func TestATR(t *testing.T) {
+	f := atrFixture
+	tests := []struct {
+		period int
+		want   []float64
+	}{
+		// seeded with the mean of the first three ranges, then Wilder smoothing:
+		// (1.9*2+0.5)/3 and (1.4333*2+0.6)/3
+		{3, []float64{0, 0, 1.9, 4.3 / 3, (4.3/3*2 + 0.6) / 3}},
+		{5, []float64{0, 0, 0, 0, 6.8 / 5}},
+		{6, []float64{0, 0, 0, 0, 0}},
+		{0, []float64{0, 0, 0, 0, 0}},
+	}
+	for _, tc := range tests {
+		got := atr(f.high, f.low, f.close, tc.period)
+		for i, want := range tc.want {
+			if !closeTo(got[i], want, 1e-9) {
+				t.Errorf("atr(period %d)[%d] = %v, want %v", tc.period, i, got[i], want)
+			}
+		}
+	}
+}
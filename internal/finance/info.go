@@ -0,0 +1,160 @@
+package finance
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Fundamentals is the /info snapshot of a symbol. Pointer fields are nil when
+// Yahoo doesn't report them, which is common for ETFs, indices and crypto.
+type Fundamentals struct {
+	Symbol    string
+	Name      string
+	QuoteType string // EQUITY, ETF, CRYPTOCURRENCY, INDEX, ...
+	Currency  string
+	Sector    string
+	Industry  string
+
+	MarketCap     *float64
+	TrailingPE    *float64
+	ForwardPE     *float64
+	DividendYield *float64 // fraction, e.g. 0.0051
+	Beta          *float64
+	Low52         *float64
+	High52        *float64
+}
+
+const infoCacheTTL = 4 * time.Hour
+
+var (
+	infoCache   = map[string]*Fundamentals{}
+	infoCacheAt = map[string]time.Time{}
+	infoCacheMu sync.Mutex
+)
+
+// yahooValue is Yahoo's {"raw": 1.23, "fmt": "1.23"} wrapper; missing values are {}.
+type yahooValue struct {
+	Raw *float64 `json:"raw"`
+}
+
+type quoteSummaryResp struct {
+	QuoteSummary struct {
+		Result []struct {
+			Price *struct {
+				LongName  string `json:"longName"`
+				ShortName string `json:"shortName"`
+				QuoteType string `json:"quoteType"`
+				Currency  string `json:"currency"`
+			} `json:"price"`
+			SummaryDetail *struct {
+				MarketCap     yahooValue `json:"marketCap"`
+				TrailingPE    yahooValue `json:"trailingPE"`
+				ForwardPE     yahooValue `json:"forwardPE"`
+				DividendYield yahooValue `json:"dividendYield"`
+				Yield         yahooValue `json:"yield"` // funds report yield instead
+				Beta          yahooValue `json:"beta"`
+				Low52         yahooValue `json:"fiftyTwoWeekLow"`
+				High52        yahooValue `json:"fiftyTwoWeekHigh"`
+			} `json:"summaryDetail"`
+			KeyStats *struct {
+				ForwardPE yahooValue `json:"forwardPE"`
+				Beta      yahooValue `json:"beta"`
+				Beta3Y    yahooValue `json:"beta3Year"`
+			} `json:"defaultKeyStatistics"`
+			Profile *struct {
+				Sector   string `json:"sector"`
+				Industry string `json:"industry"`
+			} `json:"assetProfile"`
+		} `json:"result"`
+		Error *struct {
+			Code        string `json:"code"`
+			Description string `json:"description"`
+		} `json:"error"`
+	} `json:"quoteSummary"`
+}
+
+// FetchFundamentals returns the fundamentals snapshot of symbol, cached per
+// symbol for a few hours.
+func FetchFundamentals(ctx context.Context, symbol string) (*Fundamentals, error) {
+	symbol = strings.ToUpper(strings.TrimSpace(symbol))
+	infoCacheMu.Lock()
+	if f, ok := infoCache[symbol]; ok && time.Since(infoCacheAt[symbol]) < infoCacheTTL {
+		infoCacheMu.Unlock()
+		return f, nil
+	}
+	infoCacheMu.Unlock()
+
+	var resp quoteSummaryResp
+	if err := fetchQuoteSummary(ctx, symbol, []string{"price", "summaryDetail", "defaultKeyStatistics", "assetProfile"}, &resp); err != nil {
+		return nil, err
+	}
+	f, err := fundamentalsFrom(symbol, &resp)
+	if err != nil {
+		return nil, err
+	}
+	infoCacheMu.Lock()
+	infoCache[symbol] = f
+	infoCacheAt[symbol] = time.Now()
+	infoCacheMu.Unlock()
+	return f, nil
+}
+
+// fundamentalsFrom reads symbol's snapshot out of a quoteSummary response,
+// preferring summaryDetail and filling gaps from defaultKeyStatistics.
+func fundamentalsFrom(symbol string, resp *quoteSummaryResp) (*Fundamentals, error) {
+	if e := resp.QuoteSummary.Error; e != nil {
+		err := fmt.Errorf("yahoo error %s", e.Code)
+		if e.Description != "" {
+			err = errors.New(e.Description)
+		}
+		if e.Code == "Not Found" {
+			return nil, classify(ErrSymbolNotFound, err)
+		}
+		return nil, err
+	}
+	if len(resp.QuoteSummary.Result) == 0 {
+		return nil, noData("no data for this symbol")
+	}
+	r := resp.QuoteSummary.Result[0]
+	f := &Fundamentals{Symbol: symbol}
+	if p := r.Price; p != nil {
+		f.Name, f.QuoteType, f.Currency = p.LongName, p.QuoteType, p.Currency
+		if f.Name == "" {
+			f.Name = p.ShortName
+		}
+	}
+	if p := r.Profile; p != nil {
+		f.Sector, f.Industry = p.Sector, p.Industry
+	}
+	if d := r.SummaryDetail; d != nil {
+		f.MarketCap = d.MarketCap.Raw
+		f.TrailingPE = d.TrailingPE.Raw
+		f.ForwardPE = d.ForwardPE.Raw
+		f.DividendYield = firstValue(d.DividendYield, d.Yield)
+		f.Beta = d.Beta.Raw
+		f.Low52, f.High52 = d.Low52.Raw, d.High52.Raw
+	}
+	if k := r.KeyStats; k != nil {
+		if f.ForwardPE == nil {
+			f.ForwardPE = k.ForwardPE.Raw
+		}
+		if f.Beta == nil {
+			f.Beta = firstValue(k.Beta, k.Beta3Y)
+		}
+	}
+	return f, nil
+}
+
+// firstValue returns the first reported value, treating zero as missing.
+func firstValue(vs ...yahooValue) *float64 {
+	for _, v := range vs {
+		if v.Raw != nil && *v.Raw != 0 {
+			return v.Raw
+		}
+	}
+	return nil
+}
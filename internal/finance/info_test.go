@@ -0,0 +1,89 @@
+package finance
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestFundamentalsFrom(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want Fundamentals
+	}{
+		{
+			name: "equity",
+			body: `{"quoteSummary":{"result":[{
+				"price":{"longName":"Apple Inc.","shortName":"Apple","quoteType":"EQUITY","currency":"USD"},
+				"summaryDetail":{"marketCap":{"raw":2.95e12,"fmt":"2.95T"},"trailingPE":{"raw":31.5},"forwardPE":{},
+					"dividendYield":{"raw":0.0051},"beta":{"raw":1.24},"fiftyTwoWeekLow":{"raw":164.08},"fiftyTwoWeekHigh":{"raw":237.23}},
+				"defaultKeyStatistics":{"forwardPE":{"raw":28.1}},
+				"assetProfile":{"sector":"Technology","industry":"Consumer Electronics"}}],"error":null}}`,
+			want: Fundamentals{Symbol: "AAPL", Name: "Apple Inc.", QuoteType: "EQUITY", Currency: "USD",
+				Sector: "Technology", Industry: "Consumer Electronics",
+				MarketCap: ptr(2.95e12), TrailingPE: ptr(31.5), ForwardPE: ptr(28.1), DividendYield: ptr(0.0051),
+				Beta: ptr(1.24), Low52: ptr(164.08), High52: ptr(237.23)},
+		},
+		{
+			// funds report yield rather than dividendYield, and beta3Year
+			name: "etf",
+			body: `{"quoteSummary":{"result":[{
+				"price":{"shortName":"SPDR S&P 500","quoteType":"ETF","currency":"USD"},
+				"summaryDetail":{"dividendYield":{},"yield":{"raw":0.012},"beta":{},"fiftyTwoWeekLow":{"raw":400},"fiftyTwoWeekHigh":{"raw":560}},
+				"defaultKeyStatistics":{"beta3Year":{"raw":1.0}}}],"error":null}}`,
+			want: Fundamentals{Symbol: "AAPL", Name: "SPDR S&P 500", QuoteType: "ETF", Currency: "USD",
+				DividendYield: ptr(0.012), Beta: ptr(1.0), Low52: ptr(400), High52: ptr(560)},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var resp quoteSummaryResp
+			if err := json.Unmarshal([]byte(tc.body), &resp); err != nil {
+				t.Fatal(err)
+			}
+			f, err := fundamentalsFrom("AAPL", &resp)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if f.Symbol != tc.want.Symbol || f.Name != tc.want.Name || f.QuoteType != tc.want.QuoteType ||
+				f.Currency != tc.want.Currency || f.Sector != tc.want.Sector || f.Industry != tc.want.Industry {
+				t.Errorf("got %+v, want %+v", f, tc.want)
+			}
+			for _, v := range []struct {
+				name      string
+				got, want *float64
+			}{
+				{"MarketCap", f.MarketCap, tc.want.MarketCap},
+				{"TrailingPE", f.TrailingPE, tc.want.TrailingPE},
+				{"ForwardPE", f.ForwardPE, tc.want.ForwardPE},
+				{"DividendYield", f.DividendYield, tc.want.DividendYield},
+				{"Beta", f.Beta, tc.want.Beta},
+				{"Low52", f.Low52, tc.want.Low52},
+				{"High52", f.High52, tc.want.High52},
+			} {
+				switch {
+				case v.want == nil && v.got != nil:
+					t.Errorf("%s = %v, want missing", v.name, *v.got)
+				case v.want != nil && (v.got == nil || *v.got != *v.want):
+					t.Errorf("%s = %v, want %v", v.name, v.got, *v.want)
+				}
+			}
+		})
+	}
+}
+
+func TestFundamentalsFromErrors(t *testing.T) {
+	var notFound quoteSummaryResp
+	json.Unmarshal([]byte(`{"quoteSummary":{"result":null,"error":{"code":"Not Found","description":"Quote not found for symbol: XXXX"}}}`), &notFound)
+	if _, err := fundamentalsFrom("XXXX", &notFound); !errors.Is(err, ErrSymbolNotFound) {
+		t.Errorf("err = %v, want ErrSymbolNotFound", err)
+	}
+	var empty quoteSummaryResp
+	json.Unmarshal([]byte(`{"quoteSummary":{"result":[],"error":null}}`), &empty)
+	if _, err := fundamentalsFrom("XXXX", &empty); !errors.Is(err, ErrNoData) {
+		t.Errorf("err = %v, want ErrNoData", err)
+	}
+}
+
+func ptr(v float64) *float64 { return &v }
@@ -0,0 +1,114 @@
+package finance
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"telegramBotTrade/internal/chartkit"
+)
+
+// MACD(12,26,9) periods.
+const (
+	macdFast   = 12
+	macdSlow   = 26
+	macdSignal = 9
+)
+
+// MACDSummary describes the latest MACD reading and crossovers in the chart.
+type MACDSummary struct {
+	Line, Signal, Hist float64
+	Bullish, Bearish   int // signal-line crosses within the plotted range
+}
+
+// MakeMACDChart renders price on top and MACD(12,26,9) below. Bars before the
+// indicator warms up are dropped, so both panels share the same dates.
+func MakeMACDChart(ctx context.Context, symbol string, interval string, window string, opts RenderOptions) ([]byte, *MACDSummary, error) {
+	itv, rng, _ := normalizeIntervalWindow(interval, window)
+	ts, cl, err := fetchSeries(ctx, symbol, itv, rng)
+	if err != nil {
+		return nil, nil, err
+	}
+	m := computeMACD(cl, macdFast, macdSlow, macdSignal)
+	if len(cl)-m.Start < 2 {
+		return nil, nil, classify(ErrNoData, fmt.Errorf("not enough data: MACD needs more than %d bars, got %d", m.Start+1, len(cl)))
+	}
+	s := m.Start
+	ts, cl = ts[s:], cl[s:]
+	line, signal, hist := m.Line[s:], m.Signal[s:], m.Hist[s:]
+	n := len(cl) - 1
+	sum := &MACDSummary{Line: line[n], Signal: signal[n], Hist: hist[n]}
+	sum.Bullish, sum.Bearish = crossovers(line, signal, 0)
+
+	loc := opts.location()
+	x := make([]string, len(ts))
+	for i := range ts {
+		x[i] = barLabel(time.Unix(ts[i], 0).In(loc), itv)
+	}
+	title := strings.ToUpper(symbol) + " • " + strings.ToUpper(itv) + " • " + strings.ToUpper(rng)
+	pMin, pMax := paddedRange(cl)
+	topImg, err := renderChart(ctx, chartkit.Line{
+		Style:  chartkit.Style{Theme: opts.theme()},
+		Title:  title,
+		Series: chartkit.Lines(cl),
+		X:      chartkit.XAxis{Labels: x, Split: 10},
+		Y:      []chartkit.YAxis{{Min: &pMin, Max: &pMax, Divide: 5}},
+	}.Render)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to render chart: %w", err)
+	}
+
+	// go-charts bars grow from the axis floor, so the histogram is drawn as a
+	// line; the symmetric axis puts a grid line at zero.
+	all := append(append(append([]float64{}, line...), signal...), hist...)
+	mMin, mMax := symmetricRange(all)
+	bottomImg, err := renderChart(ctx, chartkit.Line{
+		Style:  chartkit.Style{Theme: opts.theme()},
+		Title:  fmt.Sprintf("MACD(%d,%d,%d)", macdFast, macdSlow, macdSignal),
+		Series: chartkit.Lines(line, signal, hist),
+		Legend: chartkit.Legend{Labels: []string{"MACD", "Signal", "Histogram"}},
+		X:      chartkit.XAxis{Labels: x, Split: 10},
+		Y:      []chartkit.YAxis{{Min: &mMin, Max: &mMax, Divide: 4}},
+	}.Render)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to render chart: %w", err)
+	}
+	img, err := chartkit.Stack(topImg, bottomImg)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to stack panels: %w", err)
+	}
+	return img, sum, nil
+}
+
+// barLabel formats an x-axis label for a bar of the given interval.
+func barLabel(t time.Time, interval string) string {
+	switch interval {
+	case "1d":
+		return t.Format("2006-01-02")
+	case "1h":
+		return t.Format("Jan 02 15:00")
+	default:
+		return t.Format("Jan 02 15:04")
+	}
+}
+
+// symmetricRange returns bounds centred on zero that cover xs with 10% headroom.
+func symmetricRange(xs []float64) (float64, float64) {
+	if len(xs) == 0 {
+		return -1, 1
+	}
+	var m float64
+	for _, x := range xs {
+		if x > m {
+			m = x
+		} else if -x > m {
+			m = -x
+		}
+	}
+	if m == 0 {
+		return -1, 1
+	}
+	m *= 1.1
+	return -m, m
+}
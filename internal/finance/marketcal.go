@@ -0,0 +1,130 @@
+package finance
+
+import "time"
+
+// usEarlyClose is when NYSE closes on a half-day, Eastern.
+const usEarlyClose = 13 * time.Hour
+
+// usSpecialClosures are one-off NYSE closures no rule predicts.
+var usSpecialClosures = map[string]string{
+	"2018-12-05": "National Day of Mourning",
+	"2025-01-09": "National Day of Mourning",
+}
+
+// USHoliday returns the name of the NYSE holiday on t's Eastern date, or ""
+// when the exchange is open or it is a weekend.
+func USHoliday(t time.Time) string {
+	y, m, d := t.In(DefaultLocation()).Date()
+	date := time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+	if name, ok := usSpecialClosures[date.Format(time.DateOnly)]; ok {
+		return name
+	}
+	for _, h := range usHolidays(y) {
+		if h.date.Equal(date) {
+			return h.name
+		}
+	}
+	return ""
+}
+
+// USEarlyClose reports whether NYSE closes at 13:00 Eastern on t's date: the
+// day before Independence Day, the day after Thanksgiving and Christmas Eve.
+func USEarlyClose(t time.Time) bool {
+	y, m, d := t.In(DefaultLocation()).Date()
+	date := time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+	if wd := date.Weekday(); wd == time.Saturday || wd == time.Sunday || USHoliday(t) != "" {
+		return false
+	}
+	switch {
+	case m == time.July && d == 3:
+		return true
+	case m == time.December && d == 24:
+		return true
+	case m == time.November:
+		return date.Equal(nthWeekday(y, time.November, time.Thursday, 4).AddDate(0, 0, 1))
+	}
+	return false
+}
+
+// USSession returns the NYSE regular session on t's Eastern date, 09:30 to
+// 16:00 or 13:00 on half-days; ok is false on weekends and holidays.
+func USSession(t time.Time) (open, close time.Time, ok bool) {
+	et := t.In(DefaultLocation())
+	if wd := et.Weekday(); wd == time.Saturday || wd == time.Sunday || USHoliday(t) != "" {
+		return time.Time{}, time.Time{}, false
+	}
+	y, m, d := et.Date()
+	open = time.Date(y, m, d, 9, 30, 0, 0, DefaultLocation())
+	close = time.Date(y, m, d, 16, 0, 0, 0, DefaultLocation())
+	if USEarlyClose(t) {
+		close = time.Date(y, m, d, 0, 0, 0, 0, DefaultLocation()).Add(usEarlyClose)
+	}
+	return open, close, true
+}
+
+type usHoliday struct {
+	date time.Time // midnight UTC of the observed date
+	name string
+}
+
+// usHolidays lists the NYSE holidays of year on their observed dates: a
+// fixed-date holiday on a Saturday is taken on the Friday before, one on a
+// Sunday on the Monday after.
+func usHolidays(year int) []usHoliday {
+	fixed := func(m time.Month, d int) time.Time {
+		date := time.Date(year, m, d, 0, 0, 0, 0, time.UTC)
+		switch date.Weekday() {
+		case time.Saturday:
+			return date.AddDate(0, 0, -1)
+		case time.Sunday:
+			return date.AddDate(0, 0, 1)
+		}
+		return date
+	}
+	out := []usHoliday{
+		{nthWeekday(year, time.January, time.Monday, 3), "Martin Luther King Jr. Day"},
+		{nthWeekday(year, time.February, time.Monday, 3), "Washington's Birthday"},
+		{easter(year).AddDate(0, 0, -2), "Good Friday"},
+		{nthWeekday(year, time.May, time.Monday, -1), "Memorial Day"},
+		{fixed(time.July, 4), "Independence Day"},
+		{nthWeekday(year, time.September, time.Monday, 1), "Labor Day"},
+		{nthWeekday(year, time.November, time.Thursday, 4), "Thanksgiving Day"},
+		{fixed(time.December, 25), "Christmas Day"},
+	}
+	// NYSE does not close on a Friday December 31 for the coming New Year
+	if newYear := time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC); newYear.Weekday() != time.Saturday {
+		out = append(out, usHoliday{fixed(time.January, 1), "New Year's Day"})
+	}
+	if year >= 2022 {
+		out = append(out, usHoliday{fixed(time.June, 19), "Juneteenth"})
+	}
+	return out
+}
+
+// nthWeekday returns the n-th wd of month in year, counting from the end of
+// the month when n is negative.
+func nthWeekday(year int, month time.Month, wd time.Weekday, n int) time.Time {
+	if n < 0 {
+		last := time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC)
+		return last.AddDate(0, 0, -((int(last.Weekday())-int(wd)+7)%7 + 7*(-n-1)))
+	}
+	first := time.Date(year, month, 1, 0, 0, 0, 0, time.UTC)
+	return first.AddDate(0, 0, (int(wd)-int(first.Weekday())+7)%7+7*(n-1))
+}
+
+// easter returns Easter Sunday of year in the Gregorian calendar (the
+// anonymous Gregorian algorithm).
+func easter(year int) time.Time {
+	a := year % 19
+	b, c := year/100, year%100
+	d, e := b/4, b%4
+	f := (b + 8) / 25
+	g := (b - f + 1) / 3
+	h := (19*a + b - d - g + 15) % 30
+	i, k := c/4, c%4
+	l := (32 + 2*e + 2*i - h - k) % 7
+	m := (a + 11*h + 22*l) / 451
+	month := (h + l - 7*m + 114) / 31
+	day := (h+l-7*m+114)%31 + 1
+	return time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,117 @@
+package finance
+
+import (
+	"testing"
+	"time"
+)
+
+// eastern is noon Eastern of a date, unless a test needs another hour.
+func eastern(y int, m time.Month, d int) time.Time {
+	return time.Date(y, m, d, 12, 0, 0, 0, DefaultLocation())
+}
+
+func TestUSHoliday(t *testing.T) {
+	tests := []struct {
+		date time.Time
+		want string
+	}{
+		{eastern(2024, time.January, 1), "New Year's Day"},
+		{eastern(2023, time.January, 2), "New Year's Day"}, // Sunday the 1st observed Monday
+		{eastern(2021, time.December, 31), ""},             // Saturday the 1st is not observed
+		{eastern(2024, time.January, 15), "Martin Luther King Jr. Day"},
+		{eastern(2024, time.February, 19), "Washington's Birthday"},
+		{eastern(2019, time.April, 19), "Good Friday"},
+		{eastern(2024, time.March, 29), "Good Friday"},
+		{eastern(2025, time.April, 18), "Good Friday"},
+		{eastern(2024, time.May, 27), "Memorial Day"},
+		{eastern(2021, time.June, 18), ""}, // before Juneteenth became an NYSE holiday
+		{eastern(2022, time.June, 20), "Juneteenth"},
+		{eastern(2024, time.June, 19), "Juneteenth"},
+		{eastern(2027, time.June, 18), "Juneteenth"}, // Saturday the 19th observed Friday
+		{eastern(2020, time.July, 3), "Independence Day"},
+		{eastern(2020, time.July, 4), ""}, // the Saturday itself is just a weekend
+		{eastern(2021, time.July, 5), "Independence Day"},
+		{eastern(2024, time.July, 4), "Independence Day"},
+		{eastern(2024, time.September, 2), "Labor Day"},
+		{eastern(2024, time.November, 28), "Thanksgiving Day"},
+		{eastern(2021, time.December, 24), "Christmas Day"},
+		{eastern(2022, time.December, 26), "Christmas Day"},
+		{eastern(2025, time.January, 9), "National Day of Mourning"},
+		{eastern(2024, time.July, 5), ""},
+		{eastern(2024, time.November, 29), ""},
+		// 22:00 on July 3 in New York is already July 4 in UTC
+		{time.Date(2024, time.July, 4, 2, 0, 0, 0, time.UTC), ""},
+		{time.Date(2024, time.July, 5, 2, 0, 0, 0, time.UTC), "Independence Day"},
+	}
+	for _, tc := range tests {
+		if got := USHoliday(tc.date); got != tc.want {
+			t.Errorf("USHoliday(%s) = %q, want %q", tc.date.In(DefaultLocation()).Format("Mon 2006-01-02 15:04"), got, tc.want)
+		}
+	}
+}
+
+func TestUSEarlyClose(t *testing.T) {
+	tests := []struct {
+		date time.Time
+		want bool
+	}{
+		{eastern(2019, time.July, 3), true},
+		{eastern(2023, time.July, 3), true},
+		{eastern(2024, time.July, 3), true},
+		{eastern(2020, time.July, 2), false}, // the 3rd is the observed holiday, not a half-day
+		{eastern(2020, time.July, 3), false},
+		{eastern(2023, time.November, 24), true},
+		{eastern(2024, time.November, 29), true},
+		{eastern(2024, time.November, 22), false},
+		{eastern(2024, time.December, 24), true},
+		{eastern(2021, time.December, 24), false}, // observed Christmas
+		{eastern(2022, time.December, 24), false}, // Saturday
+		{eastern(2024, time.December, 31), false},
+		{eastern(2024, time.July, 5), false},
+	}
+	for _, tc := range tests {
+		if got := USEarlyClose(tc.date); got != tc.want {
+			t.Errorf("USEarlyClose(%s) = %v, want %v", tc.date.Format("Mon 2006-01-02"), got, tc.want)
+		}
+	}
+}
+
+func TestUSSession(t *testing.T) {
+	tests := []struct {
+		date      time.Time
+		wantOK    bool
+		wantClose int // Eastern hour
+	}{
+		{eastern(2024, time.July, 2), true, 16},
+		{eastern(2024, time.July, 3), true, 13},
+		{eastern(2024, time.July, 4), false, 0},
+		{eastern(2024, time.November, 29), true, 13},
+		{eastern(2024, time.December, 24), true, 13},
+		{eastern(2024, time.December, 25), false, 0},
+		{eastern(2024, time.July, 6), false, 0}, // Saturday
+	}
+	for _, tc := range tests {
+		open, close, ok := USSession(tc.date)
+		if ok != tc.wantOK {
+			t.Errorf("USSession(%s) ok = %v, want %v", tc.date.Format("2006-01-02"), ok, tc.wantOK)
+			continue
+		}
+		if !ok {
+			continue
+		}
+		if o := open.In(DefaultLocation()); o.Hour() != 9 || o.Minute() != 30 {
+			t.Errorf("USSession(%s) opens at %s, want 09:30", tc.date.Format("2006-01-02"), o.Format("15:04"))
+		}
+		if c := close.In(DefaultLocation()); c.Hour() != tc.wantClose || c.Minute() != 0 {
+			t.Errorf("USSession(%s) closes at %s, want %02d:00", tc.date.Format("2006-01-02"), c.Format("15:04"), tc.wantClose)
+		}
+	}
+}
+
+func TestEaster(t *testing.T) {
+	for year, want := range map[int]string{2019: "2019-04-21", 2024: "2024-03-31", 2025: "2025-04-20", 2038: "2038-04-25"} {
+		if got := easter(year).Format(time.DateOnly); got != want {
+			t.Errorf("easter(%d) = %s, want %s", year, got, want)
+		}
+	}
+}
@@ -0,0 +1,238 @@
+package finance
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+
+	"telegramBotTrade/internal/chartkit"
+)
+
+const (
+	// MaxMonteCarloSims caps simulation count to bound CPU and memory per request.
+	MaxMonteCarloSims     = 10000
+	defaultMonteCarloSims = 2000
+	tradingDaysPerYear    = 252
+)
+
+// monteCarloPercentiles are the fan chart bands, lowest first.
+var monteCarloPercentiles = []float64{5, 25, 50, 75, 95}
+
+// MonteCarloParams configures a projection. Seed makes runs reproducible.
+type MonteCarloParams struct {
+	HorizonDays int // trading days to project
+	Sims        int
+	Seed        int64
+}
+
+// MonteCarloResult holds percentile bands of portfolio value at each checkpoint.
+type MonteCarloResult struct {
+	StepDays     int         // trading days between checkpoints
+	Bands        [][]float64 // one series per monteCarloPercentiles entry; index 0 is the start
+	MedianFinal  float64
+	ProbLoss     float64 // share of paths ending below the start value, 0..1
+	MeanDaily    float64 // mean daily log return used
+	VolDaily     float64 // daily log return volatility used
+	InitialValue float64
+	HorizonDays  int
+	Sims         int
+}
+
+// ParseHorizon converts "20y", "6m", "12w" or "30d" into trading days.
+func ParseHorizon(s string) (int, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if len(s) < 2 {
+		return 0, fmt.Errorf("invalid horizon %q", s)
+	}
+	n, err := strconv.Atoi(s[:len(s)-1])
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("invalid horizon %q", s)
+	}
+	var days int
+	switch s[len(s)-1] {
+	case 'd':
+		days = n
+	case 'w':
+		days = n * 5
+	case 'm':
+		days = n * 21
+	case 'y':
+		days = n * tradingDaysPerYear
+	default:
+		return 0, fmt.Errorf("invalid horizon %q (use d, w, m or y)", s)
+	}
+	if days > 50*tradingDaysPerYear {
+		return 0, fmt.Errorf("horizon %q exceeds 50y", s)
+	}
+	return days, nil
+}
+
+// simulateMonteCarlo projects initial forward with i.i.d. normal daily log
+// returns of the given mean and volatility.
+func simulateMonteCarlo(initial, mu, sigma float64, p MonteCarloParams) *MonteCarloResult {
+	sims := p.Sims
+	if sims <= 0 {
+		sims = defaultMonteCarloSims
+	}
+	if sims > MaxMonteCarloSims {
+		sims = MaxMonteCarloSims
+	}
+	// keep about 120 checkpoints whatever the horizon
+	step := max(1, p.HorizonDays/120)
+	points := p.HorizonDays/step + 1
+	rng := rand.New(rand.NewSource(p.Seed))
+
+	paths := make([][]float64, points)
+	for i := range paths {
+		paths[i] = make([]float64, sims)
+	}
+	losses := 0
+	for s := 0; s < sims; s++ {
+		logV := math.Log(initial)
+		paths[0][s] = initial
+		for pt := 1; pt < points; pt++ {
+			for d := 0; d < step; d++ {
+				logV += mu + sigma*rng.NormFloat64()
+			}
+			paths[pt][s] = math.Exp(logV)
+		}
+		if paths[points-1][s] < initial {
+			losses++
+		}
+	}
+
+	res := &MonteCarloResult{
+		StepDays:     step,
+		Bands:        make([][]float64, len(monteCarloPercentiles)),
+		MeanDaily:    mu,
+		VolDaily:     sigma,
+		InitialValue: initial,
+		HorizonDays:  (points - 1) * step,
+		Sims:         sims,
+		ProbLoss:     float64(losses) / float64(sims),
+	}
+	for i := range res.Bands {
+		res.Bands[i] = make([]float64, points)
+	}
+	for pt := 0; pt < points; pt++ {
+		sort.Float64s(paths[pt])
+		for i, pc := range monteCarloPercentiles {
+			res.Bands[i][pt] = percentileSorted(paths[pt], pc)
+		}
+	}
+	res.MedianFinal = res.Bands[2][points-1]
+	return res
+}
+
+// percentileSorted returns the pc-th percentile of sorted values using linear interpolation.
+func percentileSorted(sorted []float64, pc float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	pos := pc / 100 * float64(len(sorted)-1)
+	lo := int(math.Floor(pos))
+	hi := int(math.Ceil(pos))
+	if lo == hi {
+		return sorted[lo]
+	}
+	return sorted[lo] + (sorted[hi]-sorted[lo])*(pos-float64(lo))
+}
+
+// MakeMonteCarloChart backtests the weighted portfolio over window to estimate
+// daily return mean and volatility, projects it forward and renders a fan chart.
+func MakeMonteCarloChart(ctx context.Context, symbols []string, weights []float64, window string, p MonteCarloParams, opts RenderOptions) ([]byte, *MonteCarloResult, error) {
+	assets, err := fetchPortfolioAssets(ctx, symbols, window)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to fetch assets: %w", err)
+	}
+	timestamps, alignedPrices, err := alignTimestamps(assets)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to align timestamps: %w", err)
+	}
+	config, err := createPortfolioConfig(symbols, weights, 100.0)
+	if err != nil {
+		return nil, nil, err
+	}
+	portfolio, err := calculateWeightedPortfolio(timestamps, alignedPrices, config)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to calculate portfolio: %w", err)
+	}
+	if len(portfolio.Values) < 20 {
+		return nil, nil, fmt.Errorf("need at least 20 daily observations to estimate returns, got %d", len(portfolio.Values))
+	}
+
+	var logRets []float64
+	for i := 1; i < len(portfolio.Values); i++ {
+		if portfolio.Values[i-1] <= 0 || portfolio.Values[i] <= 0 {
+			return nil, nil, fmt.Errorf("portfolio value went non-positive; can't model log returns")
+		}
+		logRets = append(logRets, math.Log(portfolio.Values[i]/portfolio.Values[i-1]))
+	}
+	mu, sigma := meanStd(logRets)
+	res := simulateMonteCarlo(100.0, mu, sigma, p)
+
+	xLabels := make([]string, len(res.Bands[0]))
+	for i := range xLabels {
+		d := i * res.StepDays
+		if res.HorizonDays >= 2*tradingDaysPerYear {
+			xLabels[i] = fmt.Sprintf("Y%.1f", float64(d)/tradingDaysPerYear)
+		} else {
+			xLabels[i] = fmt.Sprintf("M%.0f", float64(d)/21)
+		}
+	}
+	yMin := res.Bands[0][0]
+	yMax := res.Bands[0][0]
+	for _, band := range res.Bands {
+		for _, v := range band {
+			yMin = math.Min(yMin, v)
+			yMax = math.Max(yMax, v)
+		}
+	}
+	padding := (yMax - yMin) * 0.05
+	yMin, yMax = math.Max(0, yMin-padding), yMax+padding
+
+	parts := make([]string, len(symbols))
+	for i, s := range symbols {
+		parts[i] = fmt.Sprintf("%s %.0f%%", s, weights[i]*100)
+	}
+	title := fmt.Sprintf("Monte Carlo: %s (%d sims)\nMedian: %.1f | P(loss): %.1f%% | start 100",
+		strings.Join(parts, ", "), res.Sims, res.MedianFinal, res.ProbLoss*100)
+	legend := []string{"P5", "P25", "Median", "P75", "P95"}
+
+	buf, err := renderChart(ctx, chartkit.Line{
+		Style:  opts.style(),
+		Title:  title,
+		Series: chartkit.Lines(res.Bands...),
+		Legend: chartkit.Legend{Labels: legend},
+		X:      chartkit.XAxis{Labels: xLabels, Split: 6},
+		Y:      []chartkit.YAxis{{Min: &yMin, Max: &yMax, Divide: 5}},
+	}.Render)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to render chart: %w", err)
+	}
+	return buf, res, nil
+}
+
+// meanStd returns the mean and sample standard deviation of xs.
+func meanStd(xs []float64) (float64, float64) {
+	if len(xs) == 0 {
+		return 0, 0
+	}
+	mean := 0.0
+	for _, x := range xs {
+		mean += x
+	}
+	mean /= float64(len(xs))
+	if len(xs) < 2 {
+		return mean, 0
+	}
+	v := 0.0
+	for _, x := range xs {
+		v += (x - mean) * (x - mean)
+	}
+	return mean, math.Sqrt(v / float64(len(xs)-1))
+}
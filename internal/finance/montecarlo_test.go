@@ -0,0 +1,136 @@
+package finance
+
+import (
+	"math"
+	"slices"
+	"testing"
+)
+
+// normalQuantiles are the standard normal quantiles of monteCarloPercentiles.
+var normalQuantiles = []float64{-1.644854, -0.674490, 0, 0.674490, 1.644854}
+
+func TestSimulateMonteCarloBands(t *testing.T) {
+	const initial, mu, sigma = 100.0, 0.0004, 0.01
+	p := MonteCarloParams{HorizonDays: 252, Sims: 10000, Seed: 42}
+	res := simulateMonteCarlo(initial, mu, sigma, p)
+
+	if res.StepDays != 2 || res.HorizonDays != 252 || len(res.Bands[0]) != 127 || res.Sims != 10000 {
+		t.Fatalf("step %d, horizon %d, %d points, %d sims; want 2, 252, 127, 10000",
+			res.StepDays, res.HorizonDays, len(res.Bands[0]), res.Sims)
+	}
+	for pt := range res.Bands[0] {
+		for i := range res.Bands {
+			if pt == 0 && res.Bands[i][0] != initial {
+				t.Fatalf("band %d starts at %v, want %v", i, res.Bands[i][0], initial)
+			}
+			if i > 0 && res.Bands[i][pt] < res.Bands[i-1][pt] {
+				t.Fatalf("bands cross at point %d: %v below %v", pt, res.Bands[i][pt], res.Bands[i-1][pt])
+			}
+		}
+	}
+	// the final log value is normal with mean mu*T and sd sigma*sqrt(T)
+	T := float64(res.HorizonDays)
+	for i, z := range normalQuantiles {
+		want := initial * math.Exp(mu*T+z*sigma*math.Sqrt(T))
+		got := res.Bands[i][len(res.Bands[i])-1]
+		if math.Abs(math.Log(got/want)) > 0.01 {
+			t.Errorf("p%v final = %.2f, want about %.2f", monteCarloPercentiles[i], got, want)
+		}
+	}
+	if res.MedianFinal != res.Bands[2][len(res.Bands[2])-1] {
+		t.Errorf("MedianFinal %v is not the final median band", res.MedianFinal)
+	}
+	// P(log return < 0) = Phi(-mu*T / (sigma*sqrt(T)))
+	wantLoss := 0.5 * math.Erfc(mu*T/(sigma*math.Sqrt(T))/math.Sqrt2)
+	if math.Abs(res.ProbLoss-wantLoss) > 0.015 {
+		t.Errorf("ProbLoss = %.3f, want about %.3f", res.ProbLoss, wantLoss)
+	}
+}
+
+func TestSimulateMonteCarloSeed(t *testing.T) {
+	p := MonteCarloParams{HorizonDays: 60, Sims: 500, Seed: 7}
+	a := simulateMonteCarlo(100, 0, 0.02, p)
+	b := simulateMonteCarlo(100, 0, 0.02, p)
+	for i := range a.Bands {
+		if !slices.Equal(a.Bands[i], b.Bands[i]) {
+			t.Fatalf("band %d differs between runs with the same seed", i)
+		}
+	}
+	if a.ProbLoss != b.ProbLoss {
+		t.Errorf("ProbLoss %v and %v with the same seed", a.ProbLoss, b.ProbLoss)
+	}
+	p.Seed = 8
+	if c := simulateMonteCarlo(100, 0, 0.02, p); slices.Equal(c.Bands[2], a.Bands[2]) {
+		t.Error("another seed gave the same median band")
+	}
+}
+
+func TestSimulateMonteCarloNoVolatility(t *testing.T) {
+	res := simulateMonteCarlo(100, 0.001, 0, MonteCarloParams{HorizonDays: 10, Sims: 50, Seed: 1})
+	for pt := range res.Bands[0] {
+		want := 100 * math.Exp(0.001*float64(pt*res.StepDays))
+		for i := range res.Bands {
+			if !closeTo(res.Bands[i][pt], want, 1e-9) {
+				t.Fatalf("band %d at point %d = %v, want %v", i, pt, res.Bands[i][pt], want)
+			}
+		}
+	}
+	if res.ProbLoss != 0 {
+		t.Errorf("ProbLoss = %v for a steady gain", res.ProbLoss)
+	}
+}
+
+func TestSimulateMonteCarloLimits(t *testing.T) {
+	tests := []struct {
+		p                   MonteCarloParams
+		sims, step, horizon int
+	}{
+		{MonteCarloParams{HorizonDays: 10}, defaultMonteCarloSims, 1, 10},
+		{MonteCarloParams{HorizonDays: 10, Sims: MaxMonteCarloSims + 1}, MaxMonteCarloSims, 1, 10},
+		{MonteCarloParams{HorizonDays: 251, Sims: 10}, 10, 2, 250}, // the last partial step is dropped
+		{MonteCarloParams{HorizonDays: 20 * tradingDaysPerYear, Sims: 10}, 10, 42, 5040},
+	}
+	for _, tc := range tests {
+		res := simulateMonteCarlo(100, 0, 0.01, tc.p)
+		if res.Sims != tc.sims || res.StepDays != tc.step || res.HorizonDays != tc.horizon {
+			t.Errorf("%+v: %d sims, step %d, horizon %d; want %d, %d, %d", tc.p, res.Sims, res.StepDays, res.HorizonDays, tc.sims, tc.step, tc.horizon)
+		}
+	}
+}
+
+func TestPercentileSorted(t *testing.T) {
+	xs := []float64{1, 2, 3, 4}
+	for pc, want := range map[float64]float64{0: 1, 25: 1.75, 50: 2.5, 100: 4} {
+		if got := percentileSorted(xs, pc); !closeTo(got, want, 1e-12) {
+			t.Errorf("percentileSorted(%v, %v) = %v, want %v", xs, pc, got, want)
+		}
+	}
+	if got := percentileSorted(nil, 50); got != 0 {
+		t.Errorf("percentileSorted(nil) = %v, want 0", got)
+	}
+}
+
+func TestParseHorizon(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    int
+		wantErr bool
+	}{
+		{"30d", 30, false},
+		{"12w", 60, false},
+		{"6m", 126, false},
+		{" 20Y ", 5040, false},
+		{"50y", 12600, false},
+		{"51y", 0, true},
+		{"0y", 0, true},
+		{"y", 0, true},
+		{"10q", 0, true},
+		{"", 0, true},
+	}
+	for _, tc := range tests {
+		got, err := ParseHorizon(tc.in)
+		if (err != nil) != tc.wantErr || got != tc.want {
+			t.Errorf("ParseHorizon(%q) = %d, %v; want %d, error %v", tc.in, got, err, tc.want, tc.wantErr)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package finance
+
+import (
+	"errors"
+	"strings"
+)
+
+// SkippedSymbol is a symbol left out of a multi-symbol chart because it
+// couldn't be fetched.
+type SkippedSymbol struct {
+	Symbol string
+	Err    error
+}
+
+// Reason is a short, user-facing explanation of why the symbol was skipped.
+func (s SkippedSymbol) Reason() string {
+	switch {
+	case errors.Is(s.Err, ErrNoData):
+		return "no data"
+	case errors.Is(s.Err, ErrSymbolNotFound):
+		return "unknown symbol"
+	case errors.Is(s.Err, ErrRateLimited):
+		return "rate limited"
+	case errors.Is(s.Err, ErrTimeout):
+		return "timed out"
+	}
+	return "fetch failed"
+}
+
+// SymbolsError is the failure of a multi-symbol chart that fetched too few
+// of its symbols; Skipped says why each one failed.
+type SymbolsError struct {
+	Skipped []SkippedSymbol
+}
+
+func (e *SymbolsError) Error() string {
+	parts := make([]string, len(e.Skipped))
+	for i, s := range e.Skipped {
+		parts[i] = s.Symbol + ": " + s.Err.Error()
+	}
+	return strings.Join(parts, "\n")
+}
+
+func (e *SymbolsError) Unwrap() []error {
+	errs := make([]error, len(e.Skipped))
+	for i, s := range e.Skipped {
+		errs[i] = s.Err
+	}
+	return errs
+}
+
+// symbolSeries is one fetched symbol of a multi-symbol chart.
+type symbolSeries struct {
+	sym string
+	ts  []int64
+	cl  []float64
+}
+
+// fetchSymbols fetches every symbol, collecting failures instead of stopping
+// at the first one so a single typo doesn't sink a whole comparison. It fails
+// only when fewer than minOK symbols could be fetched.
+func fetchSymbols(symbols []string, minOK int, fetch func(symbol string) ([]int64, []float64, error)) ([]symbolSeries, []SkippedSymbol, error) {
+	arr := make([]symbolSeries, 0, len(symbols))
+	var skipped []SkippedSymbol
+	for _, s := range symbols {
+		su := strings.TrimSpace(s)
+		if su == "" {
+			continue
+		}
+		ts, cl, err := fetch(su)
+		if err != nil {
+			skipped = append(skipped, SkippedSymbol{Symbol: strings.ToUpper(su), Err: err})
+			continue
+		}
+		arr = append(arr, symbolSeries{sym: strings.ToUpper(su), ts: ts, cl: cl})
+	}
+	if len(arr) >= minOK {
+		return arr, skipped, nil
+	}
+	if len(skipped) == 0 {
+		return nil, nil, errors.New("no series fetched")
+	}
+	return nil, skipped, &SymbolsError{Skipped: skipped}
+}
@@ -0,0 +1,172 @@
+package finance
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+)
+
+// fakeFetch succeeds for every symbol except those mapped to an error.
+func fakeFetch(fail map[string]error) func(string) ([]int64, []float64, error) {
+	return func(symbol string) ([]int64, []float64, error) {
+		if err, ok := fail[symbol]; ok {
+			return nil, nil, err
+		}
+		return []int64{1, 2, 3}, []float64{10, 11, 12}, nil
+	}
+}
+
+func TestFetchSymbols(t *testing.T) {
+	tests := []struct {
+		name        string
+		symbols     []string
+		minOK       int
+		fail        map[string]error
+		wantSyms    []string
+		wantSkipped []string
+		wantErr     bool
+	}{
+		{name: "all succeed", symbols: []string{"spy", "QQQ", "IWM"}, minOK: 2, wantSyms: []string{"SPY", "QQQ", "IWM"}},
+		{
+			name: "partial", symbols: []string{"SPY", "XYZ", "QQQ"}, minOK: 2,
+			fail:     map[string]error{"XYZ": ErrSymbolNotFound},
+			wantSyms: []string{"SPY", "QQQ"}, wantSkipped: []string{"XYZ"},
+		},
+		{
+			name: "too few left", symbols: []string{"SPY", "XYZ", "ABC"}, minOK: 2,
+			fail:        map[string]error{"XYZ": ErrSymbolNotFound, "ABC": ErrNoData},
+			wantSkipped: []string{"XYZ", "ABC"}, wantErr: true,
+		},
+		{
+			name: "one is enough for indexed", symbols: []string{"SPY", "XYZ"}, minOK: 1,
+			fail:     map[string]error{"XYZ": ErrNoData},
+			wantSyms: []string{"SPY"}, wantSkipped: []string{"XYZ"},
+		},
+		{
+			name: "all fail", symbols: []string{"XYZ", "ABC"}, minOK: 1,
+			fail:        map[string]error{"XYZ": ErrRateLimited, "ABC": ErrTimeout},
+			wantSkipped: []string{"XYZ", "ABC"}, wantErr: true,
+		},
+		{name: "blank symbols ignored", symbols: []string{" ", ""}, minOK: 1, wantErr: true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			arr, skipped, err := fetchSymbols(tc.symbols, tc.minOK, fakeFetch(tc.fail))
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("err = %v, want error %v", err, tc.wantErr)
+			}
+			var syms, skippedSyms []string
+			for _, s := range arr {
+				syms = append(syms, s.sym)
+			}
+			for _, s := range skipped {
+				skippedSyms = append(skippedSyms, s.Symbol)
+			}
+			if !slices.Equal(syms, tc.wantSyms) || !slices.Equal(skippedSyms, tc.wantSkipped) {
+				t.Errorf("fetched %v skipped %v, want %v and %v", syms, skippedSyms, tc.wantSyms, tc.wantSkipped)
+			}
+			var se *SymbolsError
+			if tc.wantErr && len(tc.wantSkipped) > 0 {
+				if !errors.As(err, &se) || len(se.Skipped) != len(tc.wantSkipped) {
+					t.Errorf("err = %v, want a SymbolsError listing %v", err, tc.wantSkipped)
+				}
+				for _, s := range se.Skipped {
+					if !errors.Is(err, tc.fail[s.Symbol]) {
+						t.Errorf("err does not wrap %v of %s", tc.fail[s.Symbol], s.Symbol)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestSkippedReason(t *testing.T) {
+	tests := []struct {
+		err  error
+		want string
+	}{
+		{ErrNoData, "no data"},
+		{fmt.Errorf("yahoo returned 404: %w", ErrSymbolNotFound), "unknown symbol"},
+		{classify(ErrRateLimited, errors.New("429")), "rate limited"},
+		{ErrTimeout, "timed out"},
+		{errors.New("boom"), "fetch failed"},
+	}
+	for _, tc := range tests {
+		if got := (SkippedSymbol{Symbol: "X", Err: tc.err}).Reason(); got != tc.want {
+			t.Errorf("Reason(%v) = %q, want %q", tc.err, got, tc.want)
+		}
+	}
+}
+
+// failingSource is SyntheticSource except for the symbols in fail.
+type failingSource struct {
+	SyntheticSource
+	fail map[string]error
+}
+
+func (s failingSource) Series(ctx context.Context, symbol, interval, rangeParam string) (Series, error) {
+	if err, ok := s.fail[symbol]; ok {
+		return Series{}, err
+	}
+	return s.SyntheticSource.Series(ctx, symbol, interval, rangeParam)
+}
+
+// TestMultiChartSkipsFailedSymbols runs the chart builders end to end on a
+// source where some symbols fail.
+func TestMultiChartSkipsFailedSymbols(t *testing.T) {
+	src := failingSource{fail: map[string]error{"XYZ": ErrSymbolNotFound, "ABC": ErrNoData}}
+	ctx := WithSeriesSource(context.Background(), src)
+	tests := []struct {
+		name        string
+		build       func([]string) (ChartResult, error)
+		symbols     []string
+		wantSkipped []string
+		wantErr     bool
+	}{
+		{"multi all succeed", multiBuilder(ctx), []string{"SPY", "QQQ"}, nil, false},
+		{"multi partial", multiBuilder(ctx), []string{"SPY", "XYZ", "QQQ"}, []string{"XYZ"}, false},
+		{"multi one left", multiBuilder(ctx), []string{"SPY", "XYZ"}, nil, true},
+		{"multi all fail", multiBuilder(ctx), []string{"XYZ", "ABC"}, nil, true},
+		{"indexed one left", indexedBuilder(ctx), []string{"SPY", "XYZ", "ABC"}, []string{"XYZ", "ABC"}, false},
+		{"indexed all fail", indexedBuilder(ctx), []string{"XYZ", "ABC"}, nil, true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			res, err := tc.build(tc.symbols)
+			if tc.wantErr {
+				var se *SymbolsError
+				if !errors.As(err, &se) {
+					t.Fatalf("err = %v, want a SymbolsError", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(res.Image) == 0 {
+				t.Error("no image rendered")
+			}
+			var skipped []string
+			for _, s := range res.Meta.Skipped {
+				skipped = append(skipped, s.Symbol)
+			}
+			if !slices.Equal(skipped, tc.wantSkipped) {
+				t.Errorf("skipped %v, want %v", skipped, tc.wantSkipped)
+			}
+		})
+	}
+}
+
+func multiBuilder(ctx context.Context) func([]string) (ChartResult, error) {
+	return func(syms []string) (ChartResult, error) {
+		return MakeMultiChart(ctx, syms, "1d", "3m", RenderOptions{})
+	}
+}
+
+func indexedBuilder(ctx context.Context) func([]string) (ChartResult, error) {
+	return func(syms []string) (ChartResult, error) {
+		return MakeIndexedChart(ctx, syms, "1d", "3m", true, RenderOptions{})
+	}
+}
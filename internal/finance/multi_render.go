@@ -0,0 +1,99 @@
+package finance
+
+import (
+	"fmt"
+	"hash/fnv"
+	"strings"
+
+	"telegramBotTrade/internal/chartkit"
+)
+
+// symbolPalette is the fixed set of line colors symbols hash into, readable
+// on both light and dark themes.
+var symbolPalette = []chartkit.Color{
+	{R: 0x4e, G: 0x79, B: 0xa7, A: 0xff}, // blue
+	{R: 0xf2, G: 0x8e, B: 0x2b, A: 0xff}, // orange
+	{R: 0xe1, G: 0x57, B: 0x59, A: 0xff}, // red
+	{R: 0x76, G: 0xb7, B: 0xb2, A: 0xff}, // teal
+	{R: 0x59, G: 0xa1, B: 0x4f, A: 0xff}, // green
+	{R: 0xed, G: 0xc9, B: 0x48, A: 0xff}, // yellow
+	{R: 0xb0, G: 0x7a, B: 0xa1, A: 0xff}, // purple
+	{R: 0xff, G: 0x9d, B: 0xa7, A: 0xff}, // pink
+	{R: 0x9c, G: 0x75, B: 0x5f, A: 0xff}, // brown
+	{R: 0x17, G: 0xbe, B: 0xcf, A: 0xff}, // cyan
+	{R: 0xbc, G: 0xbd, B: 0x22, A: 0xff}, // olive
+	{R: 0x7f, G: 0x3c, B: 0x8d, A: 0xff}, // plum
+}
+
+const (
+	multiChartWidth  = 600
+	multiChartHeight = 400 // plot area; the legend rows are added below
+)
+
+// symbolColors returns a color per symbol, hashed from the symbol so SPY is
+// the same color on every chart. A symbol whose slot is already taken on this
+// chart moves to the next free one.
+func symbolColors(syms []string) []chartkit.Color {
+	out := make([]chartkit.Color, len(syms))
+	used := make(map[int]bool, len(syms))
+	for i, s := range syms {
+		h := fnv.New32a()
+		h.Write([]byte(strings.ToUpper(s)))
+		idx := int(h.Sum32() % uint32(len(symbolPalette)))
+		for used[idx] && len(used) < len(symbolPalette) {
+			idx = (idx + 1) % len(symbolPalette)
+		}
+		used[idx] = true
+		out[i] = symbolPalette[idx]
+	}
+	return out
+}
+
+// windowReturn is the percent change from the first non-zero close to the last.
+func windowReturn(cl []float64) float64 {
+	for _, v := range cl {
+		if v != 0 {
+			return (cl[len(cl)-1]/v - 1) * 100
+		}
+	}
+	return 0
+}
+
+// symbolLines is a multi-symbol line chart: one series per symbol, in the
+// order of syms, each labelled in the legend with its window return.
+type symbolLines struct {
+	title, subtitle string
+	syms            []string
+	returns         []float64
+	series          []chartkit.Series
+	xAxis           chartkit.XAxis
+	yAxes           []chartkit.YAxis
+	// colors overrides the per-symbol palette when set; a zero color is
+	// replaced by the theme's text color.
+	colors []chartkit.Color
+}
+
+// line lays the chart out with stable per-symbol colors and the legend below
+// the plot, wrapping onto as many rows as the symbols need.
+func (c symbolLines) line(opts RenderOptions) chartkit.Line {
+	colors := c.colors
+	if colors == nil {
+		colors = symbolColors(c.syms)
+	}
+	labels := make([]string, len(c.syms))
+	for i, s := range c.syms {
+		labels[i] = fmt.Sprintf("%s %+.1f%%", s, c.returns[i])
+	}
+	return chartkit.Line{
+		Style:    opts.style(),
+		Title:    c.title,
+		Subtitle: c.subtitle,
+		Series:   c.series,
+		X:        c.xAxis,
+		Y:        c.yAxes,
+		Legend:   chartkit.Legend{Labels: labels, Below: true},
+		Width:    multiChartWidth,
+		Height:   multiChartHeight,
+		Colors:   colors,
+	}
+}
@@ -0,0 +1,140 @@
+package finance
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// yahooOptionsURL is formatted with the symbol; without a date it returns the
+// nearest expiry's chain.
+const yahooOptionsURL = "https://query2.finance.yahoo.com/v7/finance/options/%s"
+
+// ErrNoOptions is returned for symbols without a listed option chain.
+var ErrNoOptions = errors.New("no listed options")
+
+// ImpliedMove is the move priced in by the at-the-money straddle of the
+// nearest expiry.
+type ImpliedMove struct {
+	Symbol   string
+	Price    float64 // underlying
+	Expiry   time.Time
+	Strike   float64
+	Call     float64 // call premium used (mid, or last when there is no market)
+	Put      float64
+	Move     float64 // straddle cost in dollars
+	MovePct  float64 // Move as a percentage of Price
+	DaysLeft int
+}
+
+type optionContract struct {
+	Strike    float64 `json:"strike"`
+	LastPrice float64 `json:"lastPrice"`
+	Bid       float64 `json:"bid"`
+	Ask       float64 `json:"ask"`
+}
+
+type optionChainResp struct {
+	OptionChain struct {
+		Result []struct {
+			ExpirationDates []int64 `json:"expirationDates"`
+			Quote           struct {
+				RegularMarketPrice float64 `json:"regularMarketPrice"`
+			} `json:"quote"`
+			Options []struct {
+				ExpirationDate int64            `json:"expirationDate"`
+				Calls          []optionContract `json:"calls"`
+				Puts           []optionContract `json:"puts"`
+			} `json:"options"`
+		} `json:"result"`
+		Error *struct {
+			Description string `json:"description"`
+		} `json:"error"`
+	} `json:"optionChain"`
+}
+
+// FetchImpliedMove prices the nearest-expiry ATM straddle of symbol.
+func FetchImpliedMove(ctx context.Context, symbol string) (*ImpliedMove, error) {
+	symbol = strings.ToUpper(strings.TrimSpace(symbol))
+	var resp optionChainResp
+	if err := yahooCrumbs.get(ctx, fmt.Sprintf(yahooOptionsURL, url.PathEscape(symbol)), &resp); err != nil {
+		return nil, err
+	}
+	return impliedMoveFrom(symbol, &resp, time.Now())
+}
+
+// impliedMoveFrom prices the straddle of the nearest expiry in a v7 options
+// response, counting days left from now.
+func impliedMoveFrom(symbol string, resp *optionChainResp, now time.Time) (*ImpliedMove, error) {
+	if e := resp.OptionChain.Error; e != nil && e.Description != "" {
+		return nil, errors.New(e.Description)
+	}
+	if len(resp.OptionChain.Result) == 0 {
+		return nil, ErrNoOptions
+	}
+	r := resp.OptionChain.Result[0]
+	if len(r.ExpirationDates) == 0 || len(r.Options) == 0 {
+		return nil, ErrNoOptions
+	}
+	chain := r.Options[0]
+	m, err := atmStraddle(r.Quote.RegularMarketPrice, chain.Calls, chain.Puts)
+	if err != nil {
+		return nil, err
+	}
+	m.Symbol = symbol
+	m.Expiry = time.Unix(chain.ExpirationDate, 0).UTC()
+	m.DaysLeft = int(math.Ceil(m.Expiry.Sub(now).Hours() / 24))
+	if m.DaysLeft < 0 {
+		m.DaysLeft = 0
+	}
+	return m, nil
+}
+
+// atmStraddle picks the strike closest to price that has both a call and a
+// put with a usable premium, and prices the straddle there.
+func atmStraddle(price float64, calls, puts []optionContract) (*ImpliedMove, error) {
+	if price <= 0 {
+		return nil, errors.New("no underlying price")
+	}
+	putAt := make(map[float64]float64, len(puts))
+	for _, p := range puts {
+		if v := optionPremium(p); v > 0 {
+			putAt[p.Strike] = v
+		}
+	}
+	var best *ImpliedMove
+	for _, c := range calls {
+		call := optionPremium(c)
+		put, ok := putAt[c.Strike]
+		if call <= 0 || !ok {
+			continue
+		}
+		if best == nil || math.Abs(c.Strike-price) < math.Abs(best.Strike-price) {
+			best = &ImpliedMove{Price: price, Strike: c.Strike, Call: call, Put: put}
+		}
+	}
+	if best == nil {
+		return nil, errors.New("no at-the-money call/put pair with prices")
+	}
+	best.Move = roundTo(best.Call+best.Put, 2)
+	best.MovePct = roundTo(best.Move/price*100, 1)
+	return best, nil
+}
+
+// optionPremium is the bid/ask midpoint, or the last trade when the market is
+// one-sided or closed.
+func optionPremium(c optionContract) float64 {
+	if c.Bid > 0 && c.Ask >= c.Bid {
+		return (c.Bid + c.Ask) / 2
+	}
+	return c.LastPrice
+}
+
+func roundTo(v float64, decimals int) float64 {
+	p := math.Pow(10, float64(decimals))
+	return math.Round(v*p) / p
+}
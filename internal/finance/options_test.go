@@ -0,0 +1,135 @@
+package finance
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+// optionChainFixture is a trimmed v7 options response for a $101.30 stock
+// expiring Friday 2024-07-19 00:00 UTC.
+const optionChainFixture = `{"optionChain":{"result":[{
+	"expirationDates":[1721347200,1721952000],
+	"quote":{"regularMarketPrice":101.3},
+	"options":[{"expirationDate":1721347200,
+		"calls":[
+			{"strike":95,"lastPrice":6.9,"bid":6.7,"ask":7.0},
+			{"strike":100,"lastPrice":3.1,"bid":2.95,"ask":3.05},
+			{"strike":102.5,"lastPrice":1.8,"bid":0,"ask":0},
+			{"strike":105,"lastPrice":0.9,"bid":0.85,"ask":0.95}],
+		"puts":[
+			{"strike":95,"lastPrice":0.4,"bid":0.35,"ask":0.45},
+			{"strike":100,"lastPrice":1.7,"bid":1.62,"ask":1.68},
+			{"strike":102.5,"lastPrice":0,"bid":0,"ask":0},
+			{"strike":105,"lastPrice":4.1,"bid":3.9,"ask":4.2}]}]}],"error":null}}`
+
+func TestImpliedMoveFrom(t *testing.T) {
+	var resp optionChainResp
+	if err := json.Unmarshal([]byte(optionChainFixture), &resp); err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2024, 7, 16, 14, 0, 0, 0, time.UTC)
+	m, err := impliedMoveFrom("XYZ", &resp, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 102.5 is nearest to 101.30 but its put never traded, so 100 is used:
+	// call mid 3.00 + put mid 1.65 = 4.65, 4.59% of the price
+	want := ImpliedMove{Symbol: "XYZ", Price: 101.3, Strike: 100, Call: 3, Put: 1.65, Move: 4.65, MovePct: 4.6,
+		Expiry: time.Date(2024, 7, 19, 0, 0, 0, 0, time.UTC), DaysLeft: 3}
+	if !closeTo(m.Call, want.Call, 1e-9) || !closeTo(m.Put, want.Put, 1e-9) {
+		t.Errorf("premiums %v/%v, want %v/%v", m.Call, m.Put, want.Call, want.Put)
+	}
+	m.Call, m.Put = want.Call, want.Put
+	if *m != want {
+		t.Errorf("got %+v\nwant %+v", *m, want)
+	}
+}
+
+func TestImpliedMoveFromNoOptions(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+	}{
+		{"no result", `{"optionChain":{"result":[],"error":null}}`},
+		{"no expiries", `{"optionChain":{"result":[{"expirationDates":[],"quote":{"regularMarketPrice":10},"options":[]}],"error":null}}`},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var resp optionChainResp
+			if err := json.Unmarshal([]byte(tc.body), &resp); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := impliedMoveFrom("BRK-A", &resp, time.Now()); !errors.Is(err, ErrNoOptions) {
+				t.Errorf("err = %v, want ErrNoOptions", err)
+			}
+		})
+	}
+}
+
+func TestATMStraddle(t *testing.T) {
+	c := func(strike, last, bid, ask float64) optionContract {
+		return optionContract{Strike: strike, LastPrice: last, Bid: bid, Ask: ask}
+	}
+	tests := []struct {
+		name       string
+		price      float64
+		calls      []optionContract
+		puts       []optionContract
+		wantStrike float64
+		wantMove   float64
+		wantPct    float64
+		wantErr    bool
+	}{
+		{
+			name:  "nearest strike",
+			price: 49, calls: []optionContract{c(45, 0, 4.1, 4.3), c(50, 0, 1.2, 1.4)},
+			puts:       []optionContract{c(45, 0, 0.2, 0.3), c(50, 0, 2.1, 2.3)},
+			wantStrike: 50, wantMove: 3.5, wantPct: 7.1,
+		},
+		{
+			name:  "last price when the market is one-sided",
+			price: 20, calls: []optionContract{c(20, 0.8, 0, 0.9)},
+			puts:       []optionContract{c(20, 0.7, 0, 0)},
+			wantStrike: 20, wantMove: 1.5, wantPct: 7.5,
+		},
+		{
+			name:  "crossed market falls back to last",
+			price: 20, calls: []optionContract{c(20, 0.8, 1.0, 0.9)},
+			puts:       []optionContract{c(20, 0.7, 0.6, 0.8)},
+			wantStrike: 20, wantMove: 1.5, wantPct: 7.5,
+		},
+		{
+			// 0.333+0.333 rounds to cents before the percentage is taken
+			name:  "rounding",
+			price: 30, calls: []optionContract{c(30, 0.333, 0, 0)},
+			puts:       []optionContract{c(30, 0.333, 0, 0)},
+			wantStrike: 30, wantMove: 0.67, wantPct: 2.2,
+		},
+		{
+			name:  "no matching put",
+			price: 20, calls: []optionContract{c(20, 1, 0, 0)},
+			puts:    []optionContract{c(25, 1, 0, 0)},
+			wantErr: true,
+		},
+		{name: "no price", price: 0, calls: []optionContract{c(20, 1, 0, 0)}, puts: []optionContract{c(20, 1, 0, 0)}, wantErr: true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			m, err := atmStraddle(tc.price, tc.calls, tc.puts)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("got %+v, want an error", *m)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if m.Strike != tc.wantStrike || m.Move != tc.wantMove || m.MovePct != tc.wantPct {
+				t.Errorf("strike %v move %v (%v%%), want %v %v (%v%%)", m.Strike, m.Move, m.MovePct, tc.wantStrike, tc.wantMove, tc.wantPct)
+			}
+		})
+	}
+}
@@ -0,0 +1,164 @@
+package finance
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"telegramBotTrade/internal/chartkit"
+)
+
+// PaperStartingCash is the virtual cash a chat's paper book starts with.
+const PaperStartingCash = 100000.0
+
+// PaperFill is one simulated trade; Qty is negative for sells.
+type PaperFill struct {
+	Symbol string
+	Qty    float64
+	Price  float64
+	Time   time.Time
+}
+
+// PaperPosition is the open quantity and average cost of one symbol, plus the
+// P&L already realized by selling it.
+type PaperPosition struct {
+	Symbol   string
+	Qty      float64
+	AvgCost  float64
+	Realized float64
+}
+
+// PaperBook replays fills with the average-cost method. Positions are sorted by
+// symbol and include fully closed ones so their realized P&L is reported.
+func PaperBook(fills []PaperFill) (positions []PaperPosition, cash float64) {
+	cash = PaperStartingCash
+	bySym := map[string]*PaperPosition{}
+	for _, f := range fills {
+		p := bySym[f.Symbol]
+		if p == nil {
+			p = &PaperPosition{Symbol: f.Symbol}
+			bySym[f.Symbol] = p
+		}
+		cash -= f.Qty * f.Price
+		if f.Qty > 0 {
+			p.AvgCost = (p.AvgCost*p.Qty + f.Qty*f.Price) / (p.Qty + f.Qty)
+			p.Qty += f.Qty
+			continue
+		}
+		sold := math.Min(-f.Qty, p.Qty)
+		p.Realized += sold * (f.Price - p.AvgCost)
+		p.Qty -= sold
+		if p.Qty < 1e-9 {
+			p.Qty, p.AvgCost = 0, 0
+		}
+	}
+	for _, p := range bySym {
+		positions = append(positions, *p)
+	}
+	sort.Slice(positions, func(i, j int) bool { return positions[i].Symbol < positions[j].Symbol })
+	return positions, cash
+}
+
+// paperEquity replays fills against aligned daily closes and returns the book's
+// equity (cash plus holdings at the close) for each day.
+func paperEquity(fills []PaperFill, symbols []string, timestamps []time.Time, closes [][]float64) *PortfolioData {
+	idx := map[string]int{}
+	for i, s := range symbols {
+		idx[s] = i
+	}
+	qty := make([]float64, len(symbols))
+	cash := PaperStartingCash
+	next := 0
+	out := &PortfolioData{}
+	for d, ts := range timestamps {
+		// daily bars are stamped at the open; include every fill up to the end of that day
+		end := ts.Add(24 * time.Hour)
+		for next < len(fills) && fills[next].Time.Before(end) {
+			f := fills[next]
+			qty[idx[f.Symbol]] += f.Qty
+			cash -= f.Qty * f.Price
+			next++
+		}
+		equity := cash
+		for i := range symbols {
+			equity += qty[i] * closes[i][d]
+		}
+		out.Timestamps = append(out.Timestamps, ts)
+		out.Values = append(out.Values, equity)
+		if d > 0 && out.Values[d-1] != 0 {
+			out.Returns = append(out.Returns, equity/out.Values[d-1]-1)
+		}
+	}
+	return out
+}
+
+// MakePaperEquityChart renders the paper book's daily equity since its first fill.
+func MakePaperEquityChart(ctx context.Context, fills []PaperFill, opts RenderOptions) ([]byte, *PortfolioStats, error) {
+	if len(fills) == 0 {
+		return nil, nil, fmt.Errorf("no trades yet")
+	}
+	sort.SliceStable(fills, func(i, j int) bool { return fills[i].Time.Before(fills[j].Time) })
+	seen := map[string]bool{}
+	var symbols []string
+	for _, f := range fills {
+		if !seen[f.Symbol] {
+			seen[f.Symbol] = true
+			symbols = append(symbols, f.Symbol)
+		}
+	}
+	days := int(time.Since(fills[0].Time).Hours()/24) + 1
+	if days < 5 {
+		days = 5
+	}
+	assets, err := fetchPortfolioAssets(ctx, symbols, fmt.Sprintf("%dd", days))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to fetch assets: %w", err)
+	}
+	timestamps, closes, err := alignTimestamps(assets)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to align timestamps: %w", err)
+	}
+	// drop days before the first fill
+	first := fills[0].Time.Truncate(24 * time.Hour)
+	start := sort.Search(len(timestamps), func(i int) bool { return !timestamps[i].Before(first) })
+	timestamps = timestamps[start:]
+	for i := range closes {
+		closes[i] = closes[i][start:]
+	}
+	equity := paperEquity(fills, symbols, timestamps, closes)
+	stats, err := calculatePortfolioStats(equity)
+	if err != nil {
+		return nil, nil, fmt.Errorf("not enough trading days since the first trade: %w", err)
+	}
+
+	loc := opts.location()
+	xLabels := make([]string, len(equity.Timestamps))
+	minVal, maxVal := equity.Values[0], equity.Values[0]
+	for i, ts := range equity.Timestamps {
+		xLabels[i] = ts.In(loc).Format("Jan 02")
+		minVal = math.Min(minVal, equity.Values[i])
+		maxVal = math.Max(maxVal, equity.Values[i])
+	}
+	padding := (maxVal - minVal) * 0.05
+	if padding == 0 {
+		padding = maxVal * 0.05
+	}
+	yMin, yMax := minVal-padding, maxVal+padding
+	title := fmt.Sprintf("Paper book (%s)\nReturn: %.2f%% | MaxDD: %.2f%%",
+		strings.Join(symbols, ", "), stats.TotalReturn, stats.MaxDrawdown)
+
+	buf, err := renderChart(ctx, chartkit.Line{
+		Style:  opts.style(),
+		Title:  title,
+		Series: chartkit.Lines(equity.Values),
+		X:      chartkit.XAxis{Labels: xLabels, Split: max(3, min(6, len(xLabels)/3))},
+		Y:      []chartkit.YAxis{{Min: &yMin, Max: &yMax, Divide: 5}},
+	}.Render)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to render chart: %w", err)
+	}
+	return buf, stats, nil
+}
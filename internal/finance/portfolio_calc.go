@@ -6,7 +6,9 @@ import (
 	"time"
 )
 
-// calculateWeightedPortfolio creates a weighted portfolio with optional cash and calculates PnL
+// calculateWeightedPortfolio creates a weighted portfolio with optional cash and calculates PnL.
+// Shorts and margin can take the value to zero or below: the portfolio is then
+// wiped out, its value is set to 0 on that day and the series stops there.
 func calculateWeightedPortfolio(timestamps []time.Time, assetPrices [][]float64, config *PortfolioConfig) (*PortfolioData, error) {
 	if config == nil {
 		return nil, fmt.Errorf("portfolio config is nil")
@@ -95,6 +97,11 @@ func calculateWeightedPortfolio(timestamps []time.Time, assetPrices [][]float64,
 		if math.IsNaN(portfolioValue) || math.IsInf(portfolioValue, 0) {
 			return nil, fmt.Errorf("invalid portfolio value on day %d: %f", day, portfolioValue)
 		}
+		wipedOut := portfolioValue <= 0
+		if wipedOut {
+			// the equity is gone; a negative value would be debt, not a portfolio
+			portfolioValue = 0
+		}
 
 		portfolioValues[day] = portfolioValue
 
@@ -108,6 +115,15 @@ func calculateWeightedPortfolio(timestamps []time.Time, assetPrices [][]float64,
 		} else {
 			portfolioReturns[day-1] = 0.0
 		}
+
+		if wipedOut {
+			return &PortfolioData{
+				Timestamps: timestamps[:day+1],
+				Values:     portfolioValues[:day+1],
+				Returns:    portfolioReturns[:day],
+				WipedOut:   day,
+			}, nil
+		}
 	}
 
 	return &PortfolioData{
@@ -184,6 +200,9 @@ func calculatePortfolioStats(portfolio *PortfolioData) (*PortfolioStats, error)
 	if yearsInPeriod > 0 && finalValue > 0 && initialValue > 0 {
 		// Geometric annualization: (1 + total_return)^(1/years) - 1
 		annualReturn = math.Pow(finalValue/initialValue, 1.0/yearsInPeriod) - 1.0
+	} else if finalValue <= 0 {
+		// wiped out: nothing compounds back from zero
+		annualReturn = -1.0
 	}
 
 	// Alternative: Arithmetic annualization of daily returns
@@ -212,8 +231,26 @@ func calculatePortfolioStats(portfolio *PortfolioData) (*PortfolioStats, error)
 		// }
 	}
 
+	// Sortino ratio: downside deviation counts only the losing days, against
+	// a zero target, over all observations
+	downside := 0.0
+	for _, ret := range portfolio.Returns {
+		if ret < 0 {
+			downside += ret * ret
+		}
+	}
+	downsideDeviation := math.Sqrt(downside/n) * math.Sqrt(tradingDaysPerYear)
+	var sortinoRatio float64
+	if downsideDeviation > 0 {
+		sortinoRatio = annualReturn / downsideDeviation
+	}
+
 	// Maximum drawdown
 	maxDrawdown := calculateMaxDrawdown(portfolio.Values)
+	var calmarRatio float64
+	if maxDrawdown > 0 {
+		calmarRatio = annualReturn / maxDrawdown
+	}
 
 	// Final validation of calculated statistics
 	stats := &PortfolioStats{
@@ -223,10 +260,37 @@ func calculatePortfolioStats(portfolio *PortfolioData) (*PortfolioStats, error)
 		AnnualReturn: annualReturn * 100,     // Convert to percentage
 		Volatility:   annualVolatility * 100, // Convert to percentage
 		SharpeRatio:  sharpeRatio,
+		SortinoRatio: sortinoRatio,
+		CalmarRatio:  calmarRatio,
 		MaxDrawdown:  maxDrawdown * 100, // Convert to percentage
 		NumDays:      numDays,
 	}
 
+	// Best and worst day; Returns[i] is the move into Values[i+1]
+	best, worst := 0, 0
+	for i, ret := range portfolio.Returns {
+		if ret > portfolio.Returns[best] {
+			best = i
+		}
+		if ret < portfolio.Returns[worst] {
+			worst = i
+		}
+	}
+	stats.BestDay = portfolio.Returns[best] * 100
+	stats.WorstDay = portfolio.Returns[worst] * 100
+	if len(portfolio.Timestamps) == numDays {
+		stats.BestDayAt = portfolio.Timestamps[best+1]
+		stats.WorstDayAt = portfolio.Timestamps[worst+1]
+		if maxDrawdown > 0 {
+			peak, trough := maxDrawdownSpan(portfolio.Values)
+			stats.MaxDDPeak = portfolio.Timestamps[peak]
+			stats.MaxDDTrough = portfolio.Timestamps[trough]
+		}
+		if portfolio.WipedOut > 0 {
+			stats.WipedOutAt = portfolio.Timestamps[portfolio.WipedOut]
+		}
+	}
+
 	// Validate final statistics for any anomalies
 	if math.IsNaN(stats.TotalReturn) || math.IsInf(stats.TotalReturn, 0) {
 		return nil, fmt.Errorf("invalid total return: %f", stats.TotalReturn)
@@ -243,6 +307,12 @@ func calculatePortfolioStats(portfolio *PortfolioData) (*PortfolioStats, error)
 	if math.IsNaN(stats.MaxDrawdown) || math.IsInf(stats.MaxDrawdown, 0) {
 		return nil, fmt.Errorf("invalid max drawdown: %f", stats.MaxDrawdown)
 	}
+	if math.IsNaN(stats.SortinoRatio) || math.IsInf(stats.SortinoRatio, 0) {
+		return nil, fmt.Errorf("invalid Sortino ratio: %f", stats.SortinoRatio)
+	}
+	if math.IsNaN(stats.CalmarRatio) || math.IsInf(stats.CalmarRatio, 0) {
+		return nil, fmt.Errorf("invalid Calmar ratio: %f", stats.CalmarRatio)
+	}
 
 	return stats, nil
 }
@@ -288,3 +358,44 @@ func calculateMaxDrawdown(values []float64) float64 {
 
 	return maxDrawdown
 }
+
+// maxDrawdownSpan returns the indices of the peak and trough of the largest
+// decline in values, as measured by calculateMaxDrawdown.
+func maxDrawdownSpan(values []float64) (peak, trough int) {
+	maxDrawdown := 0.0
+	high := 0
+	for i, value := range values {
+		if value > values[high] {
+			high = i
+		}
+		if values[high] > 0 && value >= 0 {
+			if drawdown := (values[high] - value) / values[high]; drawdown > maxDrawdown {
+				maxDrawdown = drawdown
+				peak, trough = high, i
+			}
+		}
+	}
+	return peak, trough
+}
+
+// PortfolioValue is the value, from a start of 100, of a weighted portfolio
+// bought at base prices and marked at current ones: the /port backtest over
+// those two points. Cash is whatever the weights leave, as in /port.
+func PortfolioValue(symbols []string, weights, base, current []float64) (float64, error) {
+	if len(base) != len(symbols) || len(current) != len(symbols) {
+		return 0, fmt.Errorf("need base and current prices for all %d symbols", len(symbols))
+	}
+	config, err := createPortfolioConfig(symbols, weights, 100)
+	if err != nil {
+		return 0, err
+	}
+	prices := make([][]float64, len(symbols))
+	for i := range symbols {
+		prices[i] = []float64{base[i], current[i]}
+	}
+	data, err := calculateWeightedPortfolio(make([]time.Time, 2), prices, config)
+	if err != nil {
+		return 0, err
+	}
+	return data.Values[1], nil
+}
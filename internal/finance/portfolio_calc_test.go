@@ -0,0 +1,112 @@
+package finance
+
+import (
+	"testing"
+	"time"
+)
+
+// dailyStamps is n consecutive days from day0.
+func dailyStamps(n int) []time.Time {
+	out := make([]time.Time, n)
+	for i := range out {
+		out[i] = day0.AddDate(0, 0, i)
+	}
+	return out
+}
+
+func TestWeightedPortfolioWipeout(t *testing.T) {
+	tests := []struct {
+		name       string
+		weights    []float64
+		prices     [][]float64
+		wantValues []float64
+		wantWiped  int
+	}{
+		{
+			// short 100% of XYZ at 10: worth 200 cash - 10 shares; it rallies
+			// to 20 and the equity reaches zero
+			name:       "short run over",
+			weights:    []float64{-1},
+			prices:     [][]float64{{10, 12, 15, 20, 25, 30}},
+			wantValues: []float64{100, 80, 50, 0},
+			wantWiped:  3,
+		},
+		{
+			// a gap through zero is clamped rather than plotted as debt
+			name:       "short gapped through",
+			weights:    []float64{-1},
+			prices:     [][]float64{{10, 12, 35, 8}},
+			wantValues: []float64{100, 80, 0},
+			wantWiped:  2,
+		},
+		{
+			// 150% long funded by a 50% short; the long collapses
+			name:       "margin long",
+			weights:    []float64{1, 0.5, -0.5},
+			prices:     [][]float64{{100, 90, 40, 30}, {50, 50, 50, 50}, {20, 20, 21, 22}},
+			wantValues: []float64{100, 90, 37.5, 25},
+			wantWiped:  0,
+		},
+		{
+			name:       "long only",
+			weights:    []float64{0.5, 0.5},
+			prices:     [][]float64{{10, 11, 12}, {20, 18, 22}},
+			wantValues: []float64{100, 100, 115},
+			wantWiped:  0,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			config := &PortfolioConfig{InitialValue: 100}
+			for i, w := range tc.weights {
+				config.Assets = append(config.Assets, WeightedAsset{Symbol: string(rune('A' + i)), Weight: w})
+			}
+			ts := dailyStamps(len(tc.prices[0]))
+			p, err := calculateWeightedPortfolio(ts, tc.prices, config)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(p.Values) != len(tc.wantValues) {
+				t.Fatalf("values = %v, want %v", p.Values, tc.wantValues)
+			}
+			for i := range p.Values {
+				if !closeTo(p.Values[i], tc.wantValues[i], 1e-9) {
+					t.Fatalf("values = %v, want %v", p.Values, tc.wantValues)
+				}
+			}
+			if p.WipedOut != tc.wantWiped {
+				t.Errorf("WipedOut = %d, want %d", p.WipedOut, tc.wantWiped)
+			}
+			if len(p.Timestamps) != len(p.Values) || len(p.Returns) != len(p.Values)-1 {
+				t.Errorf("%d timestamps and %d returns for %d values", len(p.Timestamps), len(p.Returns), len(p.Values))
+			}
+			if tc.wantWiped > 0 && p.Returns[len(p.Returns)-1] != -1 {
+				t.Errorf("last return = %v, want -100%%", p.Returns[len(p.Returns)-1])
+			}
+		})
+	}
+}
+
+func TestPortfolioStatsWipedOutAt(t *testing.T) {
+	config := &PortfolioConfig{InitialValue: 100, Assets: []WeightedAsset{{Symbol: "XYZ", Weight: -1}}}
+	ts := dailyStamps(6)
+	p, err := calculateWeightedPortfolio(ts, [][]float64{{10, 12, 15, 20, 25, 30}}, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	st, err := calculatePortfolioStats(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !st.WipedOutAt.Equal(ts[3]) {
+		t.Errorf("WipedOutAt = %v, want %v", st.WipedOutAt, ts[3])
+	}
+	if st.FinalValue != 0 || st.TotalReturn != -100 {
+		t.Errorf("final value %v, total return %v%%; want 0 and -100%%", st.FinalValue, st.TotalReturn)
+	}
+
+	long, _ := calculateWeightedPortfolio(dailyStamps(3), [][]float64{{10, 11, 12}}, &PortfolioConfig{InitialValue: 100, Assets: []WeightedAsset{{Symbol: "A", Weight: 1}}})
+	if st, err := calculatePortfolioStats(long); err != nil || !st.WipedOutAt.IsZero() {
+		t.Errorf("long-only WipedOutAt = %v, %v; want zero", st.WipedOutAt, err)
+	}
+}
@@ -1,64 +1,69 @@
 package finance
 
 import (
+	"context"
 	"fmt"
+	"math"
 	"strings"
+	"time"
 
-	"github.com/vicanso/go-charts/v2"
+	"telegramBotTrade/internal/chartkit"
 )
 
-// MakePortfolioChart generates a chart showing portfolio performance with statistics
-func MakePortfolioChart(symbols []string, window string) ([]byte, error) {
+// MakePortfolioChart generates a chart showing portfolio performance with
+// statistics, which Meta.Portfolio carries too.
+func MakePortfolioChart(ctx context.Context, symbols []string, window string, opts RenderOptions) (ChartResult, error) {
 	if len(symbols) == 0 {
-		return nil, fmt.Errorf("no symbols provided")
+		return ChartResult{}, invalidInput("no symbols provided")
 	}
 
 	// Create cache key
-	cacheKey := fmt.Sprintf("portfolio-%s-%s", strings.Join(symbols, ","), window)
-	if img, found := cacheGet(cacheKey); found {
-		return img, nil
+	cacheKey := fmt.Sprintf("portfolio-%s-%s", strings.Join(symbols, ","), window) + opts.cacheSuffix()
+	if res, found := cacheGet(ctx, cacheKey); found {
+		return res, nil
 	}
 
 	// Fetch asset data
-	assets, err := fetchPortfolioAssets(symbols, window)
+	assets, err := fetchPortfolioAssets(ctx, symbols, window)
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch assets: %w", err)
+		return ChartResult{}, fmt.Errorf("failed to fetch assets: %w", err)
 	}
 
 	// Align timestamps across all assets
 	timestamps, alignedPrices, err := alignTimestamps(assets)
 	if err != nil {
-		return nil, fmt.Errorf("failed to align timestamps: %w", err)
+		return ChartResult{}, fmt.Errorf("failed to align timestamps: %w", err)
 	}
 
 	// Calculate equal weighted portfolio
 	portfolio, err := calculateEqualWeightedPortfolio(timestamps, alignedPrices, 100.0)
 	if err != nil {
-		return nil, fmt.Errorf("failed to calculate portfolio: %w", err)
+		return ChartResult{}, fmt.Errorf("failed to calculate portfolio: %w", err)
 	}
 
 	// Calculate statistics
 	stats, err := calculatePortfolioStats(portfolio)
 	if err != nil {
-		return nil, fmt.Errorf("failed to calculate stats: %w", err)
+		return ChartResult{}, fmt.Errorf("failed to calculate stats: %w", err)
 	}
+	stats.noteHistory(assets, window)
 
-	// Convert timestamps to Eastern Time for display
-	easternLoc := getEasternTime()
+	// Convert timestamps to the display time zone
+	loc := opts.location()
 	var xLabels []string
 	var values []float64
 
 	for i, ts := range portfolio.Timestamps {
-		easternTime := ts.In(easternLoc)
+		localTime := ts.In(loc)
 
 		// Format labels based on data range
 		var label string
 		if len(portfolio.Timestamps) <= 10 {
-			label = easternTime.Format("Jan 02")
+			label = localTime.Format("Jan 02")
 		} else if len(portfolio.Timestamps) <= 60 {
-			label = easternTime.Format("Jan 02")
+			label = localTime.Format("Jan 02")
 		} else {
-			label = easternTime.Format("Jan '06")
+			label = localTime.Format("Jan '06")
 		}
 
 		xLabels = append(xLabels, label)
@@ -100,55 +105,76 @@ func MakePortfolioChart(symbols []string, window string) ([]byte, error) {
 	// Combine title and subtitle
 	fullTitle := title + "\n" + subtitle
 
-	p, err := charts.LineRender(
-		[][]float64{values},
-		charts.TitleTextOptionFunc(fullTitle),
-		charts.XAxisOptionFunc(charts.XAxisOption{
-			Data:        xLabels,
-			SplitNumber: splitNum,
-			BoundaryGap: charts.FalseFlag(),
-		}),
-		charts.YAxisOptionFunc(charts.YAxisOption{
-			Min:         &yMin,
-			Max:         &yMax,
-			DivideCount: 5,
-		}),
-		charts.ThemeOptionFunc(charts.ThemeLight),
-	)
-
+	buf, err := renderChart(ctx, chartkit.Line{
+		Style:  opts.style(),
+		Title:  fullTitle,
+		Series: chartkit.Lines(values),
+		X:      chartkit.XAxis{Labels: xLabels, Split: splitNum},
+		Y:      []chartkit.YAxis{{Min: &yMin, Max: &yMax, Divide: 5}},
+	}.Render)
 	if err != nil {
-		return nil, fmt.Errorf("failed to render chart: %w", err)
+		return ChartResult{}, fmt.Errorf("failed to render chart: %w", err)
 	}
 
-	buf, err := p.Bytes()
+	res := ChartResult{Image: buf, Meta: portfolioMeta(symbols, window, portfolio, stats)}
+	cacheSet(ctx, cacheKey, res)
+
+	return res, nil
+}
+
+// PortfolioDetailMax is the most constituents a detail chart draws behind the
+// portfolio line; larger portfolios get the plain chart and contributions.
+const PortfolioDetailMax = 6
+
+// AssetContribution is one constituent's part in a weighted portfolio's return.
+type AssetContribution struct {
+	Symbol       string
+	Weight       float64
+	Return       float64 // the asset's own return over the window, in percent
+	Contribution float64 // percentage points of the portfolio return
+}
+
+// MakeWeightedPortfolioChart generates a chart showing weighted portfolio
+// performance with statistics, which Meta.Portfolio carries too.
+func MakeWeightedPortfolioChart(ctx context.Context, symbols []string, weights []float64, window string, opts RenderOptions) (ChartResult, error) {
+	return makeWeightedPortfolioChart(ctx, symbols, weights, window, false, opts)
+}
+
+// MakeWeightedPortfolioDetailChart also plots each constituent, indexed to the
+// portfolio's starting value, as a faint line behind the portfolio. Beyond
+// PortfolioDetailMax constituents it draws the plain chart and sets each
+// constituent's contribution in the metadata instead.
+func MakeWeightedPortfolioDetailChart(ctx context.Context, symbols []string, weights []float64, window string, opts RenderOptions) (ChartResult, error) {
+	return makeWeightedPortfolioChart(ctx, symbols, weights, window, true, opts)
+}
+
+// WeightedPortfolioStats runs the same backtest as /port but skips the
+// chart, returning only the statistics.
+func WeightedPortfolioStats(ctx context.Context, symbols []string, weights []float64, window string) (*PortfolioStats, error) {
+	run, err := runWeightedPortfolio(ctx, symbols, weights, window)
 	if err != nil {
-		return nil, fmt.Errorf("failed to generate chart bytes: %w", err)
+		return nil, err
 	}
+	return run.stats, nil
+}
 
-	// Cache the result
-	cacheSet(cacheKey, buf)
-
-	return buf, nil
+// weightedRun is a weighted backtest before rendering.
+type weightedRun struct {
+	config        *PortfolioConfig
+	alignedPrices [][]float64
+	portfolio     *PortfolioData
+	stats         *PortfolioStats
 }
 
-// MakeWeightedPortfolioChart generates a chart showing weighted portfolio performance with statistics
-func MakeWeightedPortfolioChart(symbols []string, weights []float64, window string) ([]byte, error) {
+// runWeightedPortfolio fetches and aligns the assets, then computes the
+// portfolio and its statistics.
+func runWeightedPortfolio(ctx context.Context, symbols []string, weights []float64, window string) (*weightedRun, error) {
 	if len(symbols) == 0 {
-		return nil, fmt.Errorf("no symbols provided")
+		return nil, invalidInput("no symbols provided")
 	}
 
 	if len(symbols) != len(weights) {
-		return nil, fmt.Errorf("symbols and weights length mismatch")
-	}
-
-	// Create cache key
-	weightStrs := make([]string, len(weights))
-	for i, w := range weights {
-		weightStrs[i] = fmt.Sprintf("%.3f", w)
-	}
-	cacheKey := fmt.Sprintf("wport-%s-%s-%s", strings.Join(symbols, ","), strings.Join(weightStrs, ","), window)
-	if img, found := cacheGet(cacheKey); found {
-		return img, nil
+		return nil, invalidInput("symbols and weights length mismatch")
 	}
 
 	// Create portfolio config
@@ -158,7 +184,7 @@ func MakeWeightedPortfolioChart(symbols []string, weights []float64, window stri
 	}
 
 	// Fetch asset data
-	assets, err := fetchPortfolioAssets(symbols, window)
+	assets, err := fetchPortfolioAssets(ctx, symbols, window)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch assets: %w", err)
 	}
@@ -174,29 +200,67 @@ func MakeWeightedPortfolioChart(symbols []string, weights []float64, window stri
 	if err != nil {
 		return nil, fmt.Errorf("failed to calculate portfolio: %w", err)
 	}
+	// a wiped-out portfolio stops early; its constituents stop with it
+	for i := range alignedPrices {
+		alignedPrices[i] = alignedPrices[i][:len(portfolio.Values)]
+	}
 
 	// Calculate statistics
 	stats, err := calculatePortfolioStats(portfolio)
 	if err != nil {
 		return nil, fmt.Errorf("failed to calculate stats: %w", err)
 	}
+	stats.noteHistory(assets, window)
+	return &weightedRun{config: config, alignedPrices: alignedPrices, portfolio: portfolio, stats: stats}, nil
+}
+
+func makeWeightedPortfolioChart(ctx context.Context, symbols []string, weights []float64, window string, detail bool, opts RenderOptions) (ChartResult, error) {
+	if len(symbols) == 0 {
+		return ChartResult{}, invalidInput("no symbols provided")
+	}
+
+	if len(symbols) != len(weights) {
+		return ChartResult{}, invalidInput("symbols and weights length mismatch")
+	}
+	legs := detail && len(symbols) <= PortfolioDetailMax
+	table := detail && !legs
 
-	// Convert timestamps to Eastern Time for display
-	easternLoc := getEasternTime()
+	// Create cache key
+	weightStrs := make([]string, len(weights))
+	for i, w := range weights {
+		weightStrs[i] = fmt.Sprintf("%.3f", w)
+	}
+	cacheKey := fmt.Sprintf("wport-%s-%s-%s", strings.Join(symbols, ","), strings.Join(weightStrs, ","), window) + opts.cacheSuffix()
+	if legs {
+		cacheKey += "-detail"
+	}
+	// the contributions aren't cached, so the table variant always computes
+	if res, found := cacheGet(ctx, cacheKey); found && !table {
+		return res, nil
+	}
+
+	run, err := runWeightedPortfolio(ctx, symbols, weights, window)
+	if err != nil {
+		return ChartResult{}, err
+	}
+	config, alignedPrices, portfolio, stats := run.config, run.alignedPrices, run.portfolio, run.stats
+
+	// Convert timestamps to the display time zone
+	loc := opts.location()
 	var xLabels []string
 	var values []float64
 
 	for i, ts := range portfolio.Timestamps {
-		easternTime := ts.In(easternLoc)
+		localTime := ts.In(loc)
 
 		// Format labels based on data range
 		var label string
 		if len(portfolio.Timestamps) <= 10 {
-			label = easternTime.Format("Jan 02")
+			label = localTime.Format("Jan 02")
 		} else if len(portfolio.Timestamps) <= 60 {
-			label = easternTime.Format("Jan 02")
+			label = localTime.Format("Jan 02")
 		} else {
-			label = easternTime.Format("Jan '06")
+			label = localTime.Format("Jan '06")
 		}
 
 		xLabels = append(xLabels, label)
@@ -253,33 +317,150 @@ func MakeWeightedPortfolioChart(symbols []string, weights []float64, window stri
 	// Combine title and subtitle
 	fullTitle := title + "\n" + subtitle
 
-	p, err := charts.LineRender(
-		[][]float64{values},
-		charts.TitleTextOptionFunc(fullTitle),
-		charts.XAxisOptionFunc(charts.XAxisOption{
-			Data:        xLabels,
-			SplitNumber: splitNum,
-			BoundaryGap: charts.FalseFlag(),
-		}),
-		charts.YAxisOptionFunc(charts.YAxisOption{
-			Min:         &yMin,
-			Max:         &yMax,
-			DivideCount: 5,
-		}),
-		charts.ThemeOptionFunc(charts.ThemeLight),
-	)
+	var contributions []AssetContribution
+	if table {
+		contributions = assetContributions(config, alignedPrices)
+	}
 
+	chart := chartkit.Line{
+		Style:  opts.style(),
+		Title:  fullTitle,
+		Series: chartkit.Lines(values),
+		X:      chartkit.XAxis{Labels: xLabels, Split: splitNum},
+		Y:      []chartkit.YAxis{{Min: &yMin, Max: &yMax, Divide: 5}},
+	}
+	if legs {
+		chart = portfolioLegs(title, subtitle, config, alignedPrices, values, stats.TotalReturn, xLabels, splitNum).line(opts)
+	}
+	riskCues(&chart, config, portfolio, len(chart.Series)-1, loc)
+	buf, err := renderChart(ctx, chart.Render)
 	if err != nil {
-		return nil, fmt.Errorf("failed to render chart: %w", err)
+		return ChartResult{}, fmt.Errorf("failed to render chart: %w", err)
 	}
 
-	buf, err := p.Bytes()
-	if err != nil {
-		return nil, fmt.Errorf("failed to generate chart bytes: %w", err)
+	res := ChartResult{Image: buf, Meta: portfolioMeta(symbols, window, portfolio, stats)}
+	res.Meta.Contributions = contributions
+	cacheSet(ctx, cacheKey, res)
+
+	return res, nil
+}
+
+// underwaterColor shades where a short or leveraged portfolio is below its
+// initial value.
+var underwaterColor = chartkit.Color{R: 0xe5, G: 0x39, B: 0x35, A: 0x33}
+
+// riskCues marks up the chart of a portfolio with shorts or margin, which can
+// lose more than it put in: the stretch below the initial value is shaded
+// against a dashed line at it. A wiped-out portfolio gets a mark where it hit
+// zero, whatever its positions. series is the portfolio's line in chart.
+func riskCues(chart *chartkit.Line, config *PortfolioConfig, p *PortfolioData, series int, loc *time.Location) {
+	if p.WipedOut > 0 {
+		chart.Marks = append(chart.Marks, chartkit.Mark{Series: series, Index: p.WipedOut,
+			Text: "Wiped out " + p.Timestamps[p.WipedOut].In(loc).Format("Jan 02, 2006")})
 	}
+	leveraged := config.CashWeight < 0
+	for _, a := range config.Assets {
+		leveraged = leveraged || a.Weight < 0
+	}
+	if !leveraged {
+		return
+	}
+	start := config.InitialValue
+	chart.Refs = append(chart.Refs, chartkit.RefLine{Value: start, Text: fmt.Sprintf("Start %.0f", start)})
+	n := len(p.Values)
+	lower, upper := make([]float64, n), make([]float64, n)
+	below := func(i int) bool { return i >= 0 && i < n && p.Values[i] < start }
+	for i, v := range p.Values {
+		// points next to an underwater stretch close its polygon at the start line
+		if below(i) || below(i-1) || below(i+1) {
+			lower[i], upper[i] = math.Min(v, start), start
+		} else {
+			lower[i], upper[i] = chartkit.NullValue(), chartkit.NullValue()
+		}
+	}
+	chart.Bands = append(chart.Bands, chartkit.Band{Lower: lower, Upper: upper, Color: underwaterColor})
+}
 
-	// Cache the result
-	cacheSet(cacheKey, buf)
+// portfolioMeta describes a portfolio chart: the values are the portfolio's,
+// starting from its initial value.
+func portfolioMeta(symbols []string, window string, p *PortfolioData, stats *PortfolioStats) ChartMeta {
+	m := ChartMeta{Symbols: symbols, Interval: "1d", Window: window, Points: len(p.Values), Portfolio: stats, Change: stats.TotalReturn}
+	if n := len(p.Values); n > 0 {
+		m.LastPrice = p.Values[n-1]
+		m.AsOf = p.Timestamps[n-1]
+	}
+	return m
+}
 
-	return buf, nil
+// assetContributions splits a buy-and-hold portfolio's return by constituent:
+// each contributes its weight times its own return over the window.
+func assetContributions(config *PortfolioConfig, alignedPrices [][]float64) []AssetContribution {
+	out := make([]AssetContribution, len(config.Assets))
+	for i, a := range config.Assets {
+		prices := alignedPrices[i]
+		ret := (prices[len(prices)-1]/prices[0] - 1) * 100
+		out[i] = AssetContribution{Symbol: a.Symbol, Weight: a.Weight, Return: ret, Contribution: a.Weight * ret}
+	}
+	return out
+}
+
+// portfolioLegs lays out a detail chart: every constituent indexed to the
+// portfolio's initial value as a faint dashed line, with the portfolio drawn
+// last, on top, in the theme's text color.
+func portfolioLegs(title, subtitle string, config *PortfolioConfig, alignedPrices [][]float64, values []float64, totalReturn float64, xLabels []string, splitNum int) symbolLines {
+	n := len(config.Assets)
+	names := make([]string, 0, n+1)
+	syms := make([]string, 0, n)
+	rets := make([]float64, 0, n+1)
+	series := make([][]float64, 0, n+1)
+	lo, hi := values[0], values[0]
+	for i, a := range config.Assets {
+		prices := alignedPrices[i]
+		line := make([]float64, len(prices))
+		for k, p := range prices {
+			line[k] = p / prices[0] * config.InitialValue
+			lo, hi = math.Min(lo, line[k]), math.Max(hi, line[k])
+		}
+		name := a.Symbol
+		if a.Weight < 0 {
+			name += " (short)"
+		}
+		syms = append(syms, a.Symbol)
+		names = append(names, name)
+		rets = append(rets, (prices[len(prices)-1]/prices[0]-1)*100)
+		series = append(series, line)
+	}
+	for _, v := range values {
+		lo, hi = math.Min(lo, v), math.Max(hi, v)
+	}
+	names = append(names, "Portfolio")
+	rets = append(rets, totalReturn)
+	series = append(series, values)
+
+	pad := (hi - lo) * 0.05
+	if pad == 0 {
+		pad = hi * 0.05
+	}
+	yMin, yMax := lo-pad, hi+pad
+	lines := make([]chartkit.Series, len(series))
+	for i := range lines {
+		lines[i] = chartkit.Series{Name: names[i], Values: series[i]}
+		if i < n {
+			lines[i].Dash = []float64{4, 3}
+		}
+	}
+	colors := symbolColors(syms)
+	for i := range colors {
+		colors[i] = colors[i].WithAlpha(0x90)
+	}
+	return symbolLines{
+		title:    title,
+		subtitle: subtitle,
+		syms:     names,
+		returns:  rets,
+		series:   lines,
+		colors:   append(colors, chartkit.Color{}),
+		xAxis:    chartkit.XAxis{Labels: xLabels, Split: splitNum},
+		yAxes:    []chartkit.YAxis{{Min: &yMin, Max: &yMax, Divide: 5}},
+	}
 }
@@ -1,11 +1,26 @@
 package finance
 
 import (
+	"cmp"
+	"context"
 	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
 )
 
+// rePortfolioWindow is the window grammar of the portfolio commands: a count
+// of days, weeks, months or years, or ytd.
+var rePortfolioWindow = regexp.MustCompile(`(?i)^(?:\d+[dwmy]|ytd)$`)
+
+// IsPortfolioWindow reports whether s is a window such as 30d, 6m or ytd
+// rather than a symbol or weight.
+func IsPortfolioWindow(s string) bool {
+	return rePortfolioWindow.MatchString(s)
+}
+
 // parsePortfolioWindow parses window string and returns Yahoo range parameter and target days
 func parsePortfolioWindow(window string) (string, int, error) {
 	if window == "" {
@@ -13,100 +28,131 @@ func parsePortfolioWindow(window string) (string, int, error) {
 	}
 
 	window = strings.ToLower(window)
+	if !IsPortfolioWindow(window) {
+		return "", 0, invalidInput("invalid window format: %s (use format like 30d, 12w, 6m, 2y or ytd)", window)
+	}
+	if window == "ytd" {
+		return "ytd", 0, nil // Yahoo's range already starts on January 1
+	}
+	num, err := strconv.Atoi(window[:len(window)-1])
+	if err != nil || num < 1 {
+		return "", 0, invalidInput("invalid window %s: the count must be at least 1", window)
+	}
 
 	// Map user input to Yahoo Finance range parameters and target days for filtering
-	switch {
-	case strings.HasSuffix(window, "d"):
-		days := strings.TrimSuffix(window, "d")
-		var dayNum int
-		if _, err := fmt.Sscanf(days, "%d", &dayNum); err != nil {
-			return "1mo", 30, nil // Default if parsing fails
-		}
-
+	switch window[len(window)-1] {
+	case 'd':
 		// For specific day requests, determine appropriate Yahoo range
-		if dayNum <= 5 {
-			return "5d", dayNum, nil
-		} else if dayNum <= 30 {
-			return "1mo", dayNum, nil
-		} else if dayNum <= 90 {
-			return "3mo", dayNum, nil
-		} else {
-			return "1y", dayNum, nil
+		if num <= 5 {
+			return "5d", num, nil
+		} else if num <= 30 {
+			return "1mo", num, nil
+		} else if num <= 90 {
+			return "3mo", num, nil
 		}
+		return longRange(num), num, nil
 
-	case strings.HasSuffix(window, "w"):
-		weeks := strings.TrimSuffix(window, "w")
-		var weekNum int
-		if _, err := fmt.Sscanf(weeks, "%d", &weekNum); err != nil {
-			return "1mo", 21, nil // Default 3 weeks if parsing fails
-		}
-
-		targetDays := weekNum * 7 // Convert weeks to days
+	case 'w':
+		targetDays := num * 7 // Convert weeks to days
 
 		// For week requests, determine appropriate Yahoo range
-		if weekNum <= 1 {
+		if num <= 1 {
 			return "5d", targetDays, nil
-		} else if weekNum <= 4 {
+		} else if num <= 4 {
 			return "1mo", targetDays, nil // Use 1mo but filter to requested weeks
-		} else if weekNum <= 12 {
+		} else if num <= 12 {
 			return "3mo", targetDays, nil
-		} else if weekNum <= 26 {
+		} else if num <= 26 {
 			return "6mo", targetDays, nil
-		} else {
-			return "1y", targetDays, nil
 		}
+		return longRange(targetDays), targetDays, nil
 
-	case strings.HasSuffix(window, "m"):
-		months := strings.TrimSuffix(window, "m")
-		var monthNum int
-		if _, err := fmt.Sscanf(months, "%d", &monthNum); err != nil {
-			return "1y", 365, nil // Default if parsing fails
-		}
-
-		targetDays := monthNum * 30 // Approximate days
+	case 'm':
+		targetDays := num * 30 // Approximate days
 
-		if monthNum <= 1 {
+		if num <= 1 {
 			return "1mo", targetDays, nil
-		} else if monthNum <= 3 {
+		} else if num <= 3 {
 			return "3mo", targetDays, nil
-		} else if monthNum <= 6 {
+		} else if num <= 6 {
 			return "6mo", targetDays, nil
-		} else if monthNum <= 12 {
-			return "1y", targetDays, nil
-		} else if monthNum <= 24 {
-			return "2y", targetDays, nil
-		} else {
-			return "5y", targetDays, nil
-		}
-
-	case strings.HasSuffix(window, "y"):
-		years := strings.TrimSuffix(window, "y")
-		var yearNum int
-		if _, err := fmt.Sscanf(years, "%d", &yearNum); err != nil {
-			return "1y", 365, nil // Default if parsing fails
 		}
+		return longRange(targetDays), targetDays, nil
 
-		targetDays := yearNum * 365 // Approximate days
+	default: // 'y'
+		targetDays := num * 365 // Approximate days
 
-		if yearNum <= 1 {
+		if num <= 1 {
 			return "1y", targetDays, nil
-		} else if yearNum <= 2 {
+		} else if num <= 2 {
 			return "2y", targetDays, nil
-		} else if yearNum <= 5 {
+		} else if num <= 5 {
 			return "5y", targetDays, nil
-		} else if yearNum <= 10 {
+		} else if num <= 10 {
 			return "10y", targetDays, nil
 		} else {
 			return "max", targetDays, nil
 		}
+	}
+}
+
+// longRange is the shortest Yahoo range of a year or more covering days.
+func longRange(days int) string {
+	switch {
+	case days <= 366:
+		return "1y"
+	case days <= 731:
+		return "2y"
+	case days <= 1827:
+		return "5y"
+	case days <= 3653:
+		return "10y"
+	}
+	return "max"
+}
 
-	default:
-		return "", 0, fmt.Errorf("invalid window format: %s (use format like 1d, 1w, 1m, 1y)", window)
+// historyGrace is how much later than the window start an asset's first bar
+// may be before the window counts as truncated; weekends, holidays and
+// Yahoo's range rounding stay well below it.
+const historyGrace = 7 * 24 * time.Hour
+
+// portfolioWindowStart is when window begins, counted back from the latest
+// bar the way filterToTargetDays does.
+func portfolioWindowStart(window string, latest time.Time) time.Time {
+	if strings.EqualFold(window, "ytd") {
+		return time.Date(latest.Year(), 1, 1, 0, 0, 0, 0, latest.Location())
+	}
+	_, targetDays, err := parsePortfolioWindow(window)
+	if err != nil {
+		return time.Time{}
+	}
+	return latest.Add(-time.Duration(targetDays) * 24 * time.Hour)
+}
+
+// noteHistory records where each asset's data starts and, when the latest
+// start is materially after the window start, the truncation alignTimestamps
+// applied and the asset that caused it.
+func (s *PortfolioStats) noteHistory(assets []AssetData, window string) {
+	var latest int64
+	s.Starts = s.Starts[:0]
+	for _, a := range assets {
+		if len(a.Timestamps) == 0 {
+			continue
+		}
+		s.Starts = append(s.Starts, AssetStart{Symbol: a.Symbol, Start: time.Unix(a.Timestamps[0], 0)})
+		latest = max(latest, a.Timestamps[len(a.Timestamps)-1])
+	}
+	windowStart := portfolioWindowStart(window, time.Unix(latest, 0))
+	for _, st := range s.Starts {
+		if windowStart.IsZero() || st.Start.Sub(windowStart) <= historyGrace || !st.Start.After(s.TruncatedTo) {
+			continue
+		}
+		s.TruncatedTo, s.LimitedBy = st.Start, st.Symbol
 	}
 }
 
 // fetchPortfolioAssets fetches daily price data for multiple assets and filters to target timeframe
-func fetchPortfolioAssets(symbols []string, window string) ([]AssetData, error) {
+func fetchPortfolioAssets(ctx context.Context, symbols []string, window string) ([]AssetData, error) {
 	rangeParam, targetDays, err := parsePortfolioWindow(window)
 	if err != nil {
 		return nil, err
@@ -116,13 +162,13 @@ func fetchPortfolioAssets(symbols []string, window string) ([]AssetData, error)
 
 	for _, symbol := range symbols {
 		// Use daily interval for portfolio analysis
-		ts, prices, err := fetchSeries(symbol, "1d", rangeParam)
+		ts, prices, err := fetchSeries(ctx, symbol, "1d", rangeParam)
 		if err != nil {
 			return nil, fmt.Errorf("failed to fetch %s: %w", symbol, err)
 		}
 
 		if len(ts) == 0 || len(prices) == 0 {
-			return nil, fmt.Errorf("no data available for %s", symbol)
+			return nil, classify(ErrNoData, fmt.Errorf("no data available for %s", symbol))
 		}
 
 		// Filter to target timeframe if needed
@@ -200,75 +246,69 @@ func alignTimestamps(assets []AssetData) ([]time.Time, [][]float64, error) {
 
 	// Use base asset's timestamps as our unified timeline
 	// This prevents excessive data points when mixing daily stocks with minute-level crypto
-	unifiedTimestamps := make([]int64, len(baseAsset.Timestamps))
-	copy(unifiedTimestamps, baseAsset.Timestamps)
-
-	// Sort timestamps
-	for i := 0; i < len(unifiedTimestamps)-1; i++ {
-		for j := i + 1; j < len(unifiedTimestamps); j++ {
-			if unifiedTimestamps[i] > unifiedTimestamps[j] {
-				unifiedTimestamps[i], unifiedTimestamps[j] = unifiedTimestamps[j], unifiedTimestamps[i]
-			}
+	unifiedTimestamps := slices.Clone(baseAsset.Timestamps)
+	slices.Sort(unifiedTimestamps)
+
+	// Start where every asset has data, so none is back-filled with a price
+	// from after the date; noteHistory reports the truncation
+	var commonStart int64
+	for _, asset := range assets {
+		if len(asset.Timestamps) > 0 && asset.Timestamps[0] > commonStart {
+			commonStart = asset.Timestamps[0]
 		}
 	}
+	for len(unifiedTimestamps) > 0 && unifiedTimestamps[0] < commonStart {
+		unifiedTimestamps = unifiedTimestamps[1:]
+	}
+	if len(unifiedTimestamps) == 0 {
+		return nil, nil, fmt.Errorf("the assets' price histories don't overlap")
+	}
 
 	// Convert to time.Time slice
-	var alignedTimes []time.Time
-	for _, ts := range unifiedTimestamps {
-		alignedTimes = append(alignedTimes, time.Unix(ts, 0))
+	alignedTimes := make([]time.Time, len(unifiedTimestamps))
+	for i, ts := range unifiedTimestamps {
+		alignedTimes[i] = time.Unix(ts, 0)
 	}
 
-	// Forward-fill each asset to match the unified timeline
-	var alignedPrices [][]float64
-
-	for assetIdx, asset := range assets {
-		// Create timestamp to price map for this asset
-		priceMap := make(map[int64]float64)
-		for i, ts := range asset.Timestamps {
-			if i < len(asset.Prices) && asset.Prices[i] > 0 {
-				priceMap[ts] = asset.Prices[i]
-			}
-		}
-
-		// Forward-fill prices for the unified timestamp series
-		var assetPrices []float64
+	// Forward-fill each asset to match the unified timeline: a timestamp the
+	// asset has a price for takes it, any other repeats the last price used.
+	// The asset's priced bars are sorted once, so matching them against the
+	// sorted timeline is a single pass.
+	alignedPrices := make([][]float64, len(assets))
+	for i, asset := range assets {
+		series := pricedInOrder(asset)
+		prices := make([]float64, len(unifiedTimestamps))
 		var lastKnownPrice float64
 		hasFirstPrice := false
-
-		for _, ts := range unifiedTimestamps {
-			if price, exists := priceMap[ts]; exists {
+		next := 0 // series.Timestamps[:next] are before ts
+		for k, ts := range unifiedTimestamps {
+			for next < len(series.Timestamps) && series.Timestamps[next] < ts {
+				next++
+			}
+			switch {
+			case next < len(series.Timestamps) && series.Timestamps[next] == ts:
 				// Exact timestamp match - use actual price
-				lastKnownPrice = price
+				lastKnownPrice = series.Prices[next]
 				hasFirstPrice = true
-				assetPrices = append(assetPrices, price)
-			} else if hasFirstPrice {
-				// No exact match - forward fill with last known price
-				assetPrices = append(assetPrices, lastKnownPrice)
-			} else {
+			case !hasFirstPrice:
 				// No price data available yet - find the closest price before or at this timestamp
-				closestPrice := findClosestPrice(asset, ts)
-				if closestPrice > 0 {
-					lastKnownPrice = closestPrice
-					hasFirstPrice = true
-					assetPrices = append(assetPrices, closestPrice)
-				} else {
-					return nil, nil, fmt.Errorf("no valid price data found for asset %s (%s) at or before timestamp %d", asset.Symbol, assets[assetIdx].Symbol, ts)
+				lastKnownPrice = findClosestPrice(asset, ts)
+				if lastKnownPrice <= 0 {
+					return nil, nil, fmt.Errorf("no valid price data found for asset %s at or before timestamp %d", asset.Symbol, ts)
 				}
+				hasFirstPrice = true
 			}
+			prices[k] = lastKnownPrice
 		}
-
-		if len(assetPrices) != len(unifiedTimestamps) {
-			return nil, nil, fmt.Errorf("price alignment failed for asset %s: got %d prices, expected %d", asset.Symbol, len(assetPrices), len(unifiedTimestamps))
-		}
-
-		alignedPrices = append(alignedPrices, assetPrices)
+		alignedPrices[i] = prices
 	}
 
 	return alignedTimes, alignedPrices, nil
 }
 
-// findClosestPrice finds the closest price for an asset at or before the given timestamp
-// This is used for forward-filling when an exact timestamp match is not found
+// findClosestPrice finds the closest price for an asset at or before the given timestamp,
+// or failing that the first one after it. It seeds the forward-fill of an asset whose
+// bars don't line up with the unified timeline, so it runs at most once per asset.
 func findClosestPrice(asset AssetData, targetTimestamp int64) float64 {
 	var bestPrice float64
 	var bestTimestamp int64 = -1
@@ -295,12 +335,26 @@ func findClosestPrice(asset AssetData, targetTimestamp int64) float64 {
 	return bestPrice
 }
 
-// findNextPrice finds the next available price for an asset at or after the given timestamp
-func findNextPrice(asset AssetData, fromTimestamp int64) float64 {
-	for i, ts := range asset.Timestamps {
-		if ts >= fromTimestamp && i < len(asset.Prices) && asset.Prices[i] > 0 {
-			return asset.Prices[i]
+// pricedInOrder returns asset's bars with a positive price, sorted by time.
+// Of several bars with the same timestamp the last one wins, as it would in a
+// timestamp-keyed map.
+func pricedInOrder(asset AssetData) AssetData {
+	n := min(len(asset.Timestamps), len(asset.Prices))
+	idx := make([]int, 0, n)
+	for i := 0; i < n; i++ {
+		if asset.Prices[i] > 0 {
+			idx = append(idx, i)
+		}
+	}
+	slices.SortStableFunc(idx, func(a, b int) int { return cmp.Compare(asset.Timestamps[a], asset.Timestamps[b]) })
+	out := AssetData{Symbol: asset.Symbol, Timestamps: make([]int64, 0, len(idx)), Prices: make([]float64, 0, len(idx))}
+	for _, i := range idx {
+		if k := len(out.Timestamps); k > 0 && out.Timestamps[k-1] == asset.Timestamps[i] {
+			out.Prices[k-1] = asset.Prices[i]
+			continue
 		}
+		out.Timestamps = append(out.Timestamps, asset.Timestamps[i])
+		out.Prices = append(out.Prices, asset.Prices[i])
 	}
-	return 0 // No valid price found
+	return out
 }
@@ -0,0 +1,281 @@
+package finance
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"slices"
+	"testing"
+	"time"
+)
+
+// alignTimestampsReference is alignTimestamps as it was before the
+// bubble sort and per-timestamp scans were replaced; the rewrite must agree
+// with it on every input.
+func alignTimestampsReference(assets []AssetData) ([]time.Time, [][]float64, error) {
+	if len(assets) == 0 {
+		return nil, nil, fmt.Errorf("no assets provided")
+	}
+	var baseAsset AssetData
+	minDataPoints := int(^uint(0) >> 1)
+	for _, asset := range assets {
+		if len(asset.Timestamps) < minDataPoints {
+			minDataPoints = len(asset.Timestamps)
+			baseAsset = asset
+		}
+	}
+	if len(baseAsset.Timestamps) == 0 {
+		return nil, nil, fmt.Errorf("no timestamps found in base asset")
+	}
+	unifiedTimestamps := make([]int64, len(baseAsset.Timestamps))
+	copy(unifiedTimestamps, baseAsset.Timestamps)
+	for i := 0; i < len(unifiedTimestamps)-1; i++ {
+		for j := i + 1; j < len(unifiedTimestamps); j++ {
+			if unifiedTimestamps[i] > unifiedTimestamps[j] {
+				unifiedTimestamps[i], unifiedTimestamps[j] = unifiedTimestamps[j], unifiedTimestamps[i]
+			}
+		}
+	}
+	var commonStart int64
+	for _, asset := range assets {
+		if len(asset.Timestamps) > 0 && asset.Timestamps[0] > commonStart {
+			commonStart = asset.Timestamps[0]
+		}
+	}
+	for len(unifiedTimestamps) > 0 && unifiedTimestamps[0] < commonStart {
+		unifiedTimestamps = unifiedTimestamps[1:]
+	}
+	if len(unifiedTimestamps) == 0 {
+		return nil, nil, fmt.Errorf("the assets' price histories don't overlap")
+	}
+	var alignedTimes []time.Time
+	for _, ts := range unifiedTimestamps {
+		alignedTimes = append(alignedTimes, time.Unix(ts, 0))
+	}
+	var alignedPrices [][]float64
+	for _, asset := range assets {
+		priceMap := make(map[int64]float64)
+		for i, ts := range asset.Timestamps {
+			if i < len(asset.Prices) && asset.Prices[i] > 0 {
+				priceMap[ts] = asset.Prices[i]
+			}
+		}
+		var assetPrices []float64
+		var lastKnownPrice float64
+		hasFirstPrice := false
+		for _, ts := range unifiedTimestamps {
+			if price, exists := priceMap[ts]; exists {
+				lastKnownPrice = price
+				hasFirstPrice = true
+				assetPrices = append(assetPrices, price)
+			} else if hasFirstPrice {
+				assetPrices = append(assetPrices, lastKnownPrice)
+			} else {
+				closestPrice := closestPriceReference(asset, ts)
+				if closestPrice <= 0 {
+					return nil, nil, fmt.Errorf("no valid price data found for asset %s at or before timestamp %d", asset.Symbol, ts)
+				}
+				lastKnownPrice = closestPrice
+				hasFirstPrice = true
+				assetPrices = append(assetPrices, closestPrice)
+			}
+		}
+		alignedPrices = append(alignedPrices, assetPrices)
+	}
+	return alignedTimes, alignedPrices, nil
+}
+
+// closestPriceReference is the original findClosestPrice.
+func closestPriceReference(asset AssetData, targetTimestamp int64) float64 {
+	var bestPrice float64
+	var bestTimestamp int64 = -1
+	for i, ts := range asset.Timestamps {
+		if ts <= targetTimestamp && i < len(asset.Prices) && asset.Prices[i] > 0 && ts > bestTimestamp {
+			bestTimestamp = ts
+			bestPrice = asset.Prices[i]
+		}
+	}
+	if bestTimestamp == -1 {
+		for i, ts := range asset.Timestamps {
+			if ts > targetTimestamp && i < len(asset.Prices) && asset.Prices[i] > 0 {
+				return asset.Prices[i]
+			}
+		}
+	}
+	return bestPrice
+}
+
+const (
+	day       = int64(24 * 3600)
+	jan2_2024 = int64(1704153600) // Tuesday 00:00 UTC
+)
+
+// stockDays returns n weekday bars at 14:30 UTC from 2024-01-02, priced from start.
+func stockDays(sym string, n int, start float64) AssetData {
+	a := AssetData{Symbol: sym}
+	for ts := jan2_2024; len(a.Timestamps) < n; ts += day {
+		if wd := time.Unix(ts, 0).UTC().Weekday(); wd == time.Saturday || wd == time.Sunday {
+			continue
+		}
+		a.Timestamps = append(a.Timestamps, ts+14*3600+1800)
+		a.Prices = append(a.Prices, start+float64(len(a.Prices)))
+	}
+	return a
+}
+
+// cryptoDays returns n daily bars at 00:00 UTC from 2024-01-02, weekends included.
+func cryptoDays(sym string, n int, start float64) AssetData {
+	a := AssetData{Symbol: sym}
+	for i := range n {
+		a.Timestamps = append(a.Timestamps, jan2_2024+int64(i)*day)
+		a.Prices = append(a.Prices, start+10*float64(i))
+	}
+	return a
+}
+
+func TestAlignTimestampsMatchesReference(t *testing.T) {
+	spy := stockDays("SPY", 20, 100)
+	tests := []struct {
+		name   string
+		assets []AssetData
+	}{
+		{"shared calendar", []AssetData{spy, stockDays("QQQ", 20, 300)}},
+		// crypto trades at 00:00 UTC every day and stocks at 14:30 on
+		// weekdays, so no timestamp matches after the first seed
+		{"mixed calendars", []AssetData{spy, cryptoDays("BTC-USD", 30, 40000)}},
+		{"mixed calendars, crypto on the stock clock", func() []AssetData {
+			btc := cryptoDays("BTC-USD", 30, 40000)
+			for i := range btc.Timestamps {
+				if i%3 == 0 {
+					btc.Timestamps[i] += 14*3600 + 1800
+				}
+			}
+			return []AssetData{spy, btc}
+		}()},
+		{"gaps and bad prices", func() []AssetData {
+			qqq := stockDays("QQQ", 20, 300)
+			qqq.Prices[3], qqq.Prices[4], qqq.Prices[9] = 0, -1, 0
+			qqq.Timestamps = slices.Delete(qqq.Timestamps, 12, 15)
+			qqq.Prices = slices.Delete(qqq.Prices, 12, 15)
+			return []AssetData{spy, qqq}
+		}()},
+		{"first bar unpriced", func() []AssetData {
+			qqq := stockDays("QQQ", 20, 300)
+			qqq.Prices[0] = 0
+			return []AssetData{spy, qqq}
+		}()},
+		{"unsorted with duplicates", func() []AssetData {
+			qqq := stockDays("QQQ", 22, 300)
+			qqq.Timestamps[5], qqq.Timestamps[6] = qqq.Timestamps[6], qqq.Timestamps[5]
+			qqq.Timestamps[10] = qqq.Timestamps[11] // later bar wins
+			return []AssetData{spy, qqq}
+		}()},
+		{"unsorted base", func() []AssetData {
+			base := stockDays("SPY", 10, 100)
+			slices.Reverse(base.Timestamps)
+			return []AssetData{base, stockDays("QQQ", 20, 300)}
+		}()},
+		{"late starter", func() []AssetData {
+			late := stockDays("NEW", 25, 10)
+			late.Timestamps, late.Prices = late.Timestamps[8:], late.Prices[8:]
+			return []AssetData{stockDays("SPY", 25, 100), late}
+		}()},
+		{"no positive prices", []AssetData{spy, {Symbol: "ZERO", Timestamps: spy.Timestamps, Prices: make([]float64, len(spy.Prices))}}},
+		{"no overlap", []AssetData{{Symbol: "OLD", Timestamps: []int64{1, 2}, Prices: []float64{1, 2}}, {Symbol: "NEW", Timestamps: []int64{5, 6, 7}, Prices: []float64{1, 2, 3}}}},
+		{"empty base", []AssetData{spy, {Symbol: "NONE"}}},
+		{"no assets", nil},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			compareAlignment(t, tc.assets)
+		})
+	}
+}
+
+// TestAlignTimestampsRandomized compares the two on random calendars, gaps,
+// unpriced bars, duplicates and shuffles.
+func TestAlignTimestampsRandomized(t *testing.T) {
+	r := rand.New(rand.NewPCG(1, 2))
+	for n := range 300 {
+		assets := make([]AssetData, 1+r.IntN(4))
+		for i := range assets {
+			a := AssetData{Symbol: fmt.Sprint("A", i)}
+			ts := int64(r.IntN(10))
+			for range r.IntN(40) {
+				ts += int64(r.IntN(3)) // 0 repeats a timestamp
+				a.Timestamps = append(a.Timestamps, ts)
+				p := float64(1 + r.IntN(100))
+				if r.IntN(8) == 0 {
+					p = 0
+				}
+				a.Prices = append(a.Prices, p)
+			}
+			if r.IntN(4) == 0 {
+				r.Shuffle(len(a.Timestamps), func(x, y int) {
+					a.Timestamps[x], a.Timestamps[y] = a.Timestamps[y], a.Timestamps[x]
+				})
+			}
+			assets[i] = a
+		}
+		t.Run(fmt.Sprint(n), func(t *testing.T) { compareAlignment(t, assets) })
+	}
+}
+
+func compareAlignment(t *testing.T, assets []AssetData) {
+	t.Helper()
+	wantTimes, wantPrices, wantErr := alignTimestampsReference(assets)
+	gotTimes, gotPrices, gotErr := alignTimestamps(assets)
+	if (gotErr != nil) != (wantErr != nil) {
+		t.Fatalf("err = %v, reference err = %v", gotErr, wantErr)
+	}
+	if wantErr != nil {
+		return
+	}
+	if !slices.EqualFunc(gotTimes, wantTimes, time.Time.Equal) {
+		t.Fatalf("times = %v\nreference %v", gotTimes, wantTimes)
+	}
+	if len(gotPrices) != len(wantPrices) {
+		t.Fatalf("%d price series, reference %d", len(gotPrices), len(wantPrices))
+	}
+	for i := range wantPrices {
+		if !slices.Equal(gotPrices[i], wantPrices[i]) {
+			t.Errorf("%s prices = %v\nreference %v", assets[i].Symbol, gotPrices[i], wantPrices[i])
+		}
+	}
+}
+
+// benchAssets is a 5-asset 10y hourly backtest, ~12k bars each, with one
+// asset on a shifted clock so the forward-fill has work to do.
+func benchAssets() []AssetData {
+	assets := make([]AssetData, 5)
+	for i := range assets {
+		a := AssetData{Symbol: fmt.Sprint("A", i)}
+		for k := range 12000 {
+			ts := jan2_2024 + int64(k)*3600
+			if i == 4 {
+				ts += 1800
+			}
+			a.Timestamps = append(a.Timestamps, ts)
+			a.Prices = append(a.Prices, 100+float64(k%50))
+		}
+		assets[i] = a
+	}
+	return assets
+}
+
+func BenchmarkAlignTimestamps(b *testing.B) {
+	assets := benchAssets()
+	for b.Loop() {
+		if _, _, err := alignTimestamps(assets); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkAlignTimestampsReference(b *testing.B) {
+	assets := benchAssets()
+	for b.Loop() {
+		if _, _, err := alignTimestampsReference(assets); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
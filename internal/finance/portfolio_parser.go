@@ -1,13 +1,17 @@
 package finance
 
 import (
-	"fmt"
 	"strconv"
 	"strings"
 )
 
 // ParseWeightedPortfolio parses a weighted portfolio command string
-// Format: /port SPY 0.5 AAPL 0.25 1y
+// Format: /port SPY 0.5 AAPL 0.25 [1y]
+// Weights may also be percentages (SPY 50% AAPL 25%), the last one may be
+// "rest" for whatever the others leave (SPY 0.6 TLT rest), and "SPY QQQ GLD
+// eq" weights the symbols equally. Decimals and percentages can't be mixed.
+// The window is optional (default 1y) and validated here, so a bad one is
+// reported before anything is fetched; every error is an *InvalidInputError.
 // Returns: symbols, weights, window, error
 func ParseWeightedPortfolio(input string) ([]string, []float64, string, error) {
 	// Remove command prefix and clean input
@@ -17,48 +21,43 @@ func ParseWeightedPortfolio(input string) ([]string, []float64, string, error) {
 	}
 
 	parts := strings.Fields(input)
-	if len(parts) < 3 {
-		return nil, nil, "", fmt.Errorf("insufficient arguments: need at least symbol weight window")
+	if len(parts) < 2 {
+		return nil, nil, "", invalidInput("insufficient arguments: need at least a symbol and a weight")
 	}
 
-	// Last part should be the window
-	window := parts[len(parts)-1]
-	parts = parts[:len(parts)-1] // Remove window from parts
+	// The last part is the window only when it looks like one; a weight
+	// never does, so "SPY 0.5 AAPL 0.5" keeps both pairs
+	window := "1y"
+	if last := parts[len(parts)-1]; IsPortfolioWindow(last) {
+		window = strings.ToLower(last)
+		parts = parts[:len(parts)-1]
+	} else if strings.ContainsAny(last[:1], "0123456789.") && strings.ContainsAny(last[len(last)-1:], "dwmyDWMY") {
+		// e.g. 1.5y: meant as a window, not a weight
+		return nil, nil, "", invalidInput("invalid window %s (use format like 30d, 12w, 6m, 2y or ytd)", last)
+	}
+	if _, _, err := parsePortfolioWindow(window); err != nil {
+		return nil, nil, "", err
+	}
+	for _, p := range parts {
+		if IsPortfolioWindow(p) {
+			return nil, nil, "", invalidInput("the window %s must come last, e.g. SPY 0.6 TLT 0.4 %s", p, strings.ToLower(p))
+		}
+	}
 
-	// Remaining parts should be pairs of symbol weight
-	if len(parts)%2 != 0 {
-		return nil, nil, "", fmt.Errorf("invalid format: each symbol must have a weight")
+	if len(parts) == 0 {
+		return nil, nil, "", invalidInput("insufficient arguments: need at least a symbol and a weight")
 	}
 
 	var symbols []string
 	var weights []float64
-	totalWeight := 0.0
-
-	for i := 0; i < len(parts); i += 2 {
-		symbol := strings.ToUpper(strings.TrimSpace(parts[i]))
-		weightStr := strings.TrimSpace(parts[i+1])
-
-		if symbol == "" {
-			return nil, nil, "", fmt.Errorf("empty symbol at position %d", i/2+1)
-		}
-
-		weight, err := strconv.ParseFloat(weightStr, 64)
-		if err != nil {
-			return nil, nil, "", fmt.Errorf("invalid weight '%s' for symbol %s: %w", weightStr, symbol, err)
-		}
-
-		// Allow negative weights for short positions
-		if weight > 1 {
-			return nil, nil, "", fmt.Errorf("long weight %f for symbol %s exceeds 1.0", weight, symbol)
-		}
-
-		if weight < -1 {
-			return nil, nil, "", fmt.Errorf("short weight %f for symbol %s exceeds -1.0 (max 100%% short)", weight, symbol)
-		}
-
-		symbols = append(symbols, symbol)
-		weights = append(weights, weight)
-		totalWeight += weight
+	var err error
+	if strings.EqualFold(parts[len(parts)-1], "eq") {
+		symbols, weights, err = parseEqualWeights(parts[:len(parts)-1])
+	} else {
+		symbols, weights, err = parseWeightPairs(parts)
+	}
+	if err != nil {
+		return nil, nil, "", err
 	}
 
 	// For short selling portfolios, we need to validate differently
@@ -79,14 +78,14 @@ func ParseWeightedPortfolio(input string) ([]string, []float64, string, error) {
 	// Total gross exposure should be reasonable (e.g., max 3x leverage)
 	totalGrossExposure := totalLong + totalShort
 	if totalGrossExposure > 3.0 {
-		return nil, nil, "", fmt.Errorf("total gross exposure %.3f exceeds 3.0 (300%% leverage limit)", totalGrossExposure)
+		return nil, nil, "", invalidInput("total gross exposure %.3f exceeds 3.0 (300%% leverage limit)", totalGrossExposure)
 	}
 
 	// Check for duplicate symbols
 	seen := make(map[string]bool)
 	for _, symbol := range symbols {
 		if seen[symbol] {
-			return nil, nil, "", fmt.Errorf("duplicate symbol: %s", symbol)
+			return nil, nil, "", invalidInput("duplicate symbol: %s", symbol)
 		}
 		seen[symbol] = true
 	}
@@ -94,10 +93,120 @@ func ParseWeightedPortfolio(input string) ([]string, []float64, string, error) {
 	return symbols, weights, window, nil
 }
 
+// parseEqualWeights gives each of symbols the same weight, for the "eq" form.
+func parseEqualWeights(parts []string) ([]string, []float64, error) {
+	if len(parts) == 0 {
+		return nil, nil, invalidInput("eq needs at least one symbol, e.g. SPY QQQ GLD eq")
+	}
+	symbols := make([]string, len(parts))
+	weights := make([]float64, len(parts))
+	for i, p := range parts {
+		if _, _, err := parseWeight(p); err == nil || strings.EqualFold(p, "rest") || strings.EqualFold(p, "eq") {
+			return nil, nil, invalidInput("eq weights every symbol equally, so it can't be combined with weights like %s", p)
+		}
+		symbols[i] = strings.ToUpper(p)
+		weights[i] = 1 / float64(len(parts))
+	}
+	return symbols, weights, nil
+}
+
+// parseWeightPairs parses SYMBOL WEIGHT pairs. The weights must share one
+// notation, decimals or percentages, and the last may be "rest".
+func parseWeightPairs(parts []string) ([]string, []float64, error) {
+	if len(parts)%2 != 0 {
+		return nil, nil, invalidInput("invalid format: each symbol must have a weight")
+	}
+
+	var symbols []string
+	var weights []float64
+	totalWeight := 0.0
+	notation := "" // "decimal" or "percentage", set by the first weight
+	restAt := -1
+
+	for i := 0; i < len(parts); i += 2 {
+		symbol := strings.ToUpper(strings.TrimSpace(parts[i]))
+		weightStr := strings.TrimSpace(parts[i+1])
+
+		if symbol == "" {
+			return nil, nil, invalidInput("empty symbol at position %d", i/2+1)
+		}
+		if strings.EqualFold(symbol, "eq") {
+			return nil, nil, invalidInput("eq weights every symbol equally, so it can't be combined with weights; use e.g. SPY QQQ GLD eq")
+		}
+
+		if strings.EqualFold(weightStr, "rest") {
+			if i+2 != len(parts) {
+				return nil, nil, invalidInput("rest can only be the weight of the last symbol, e.g. SPY 0.6 TLT rest")
+			}
+			restAt = len(symbols)
+			symbols = append(symbols, symbol)
+			weights = append(weights, 0)
+			continue
+		}
+
+		weight, pct, err := parseWeight(weightStr)
+		if err != nil {
+			return nil, nil, invalidInput("invalid weight '%s' for symbol %s: %w", weightStr, symbol, err)
+		}
+		kind := "decimal"
+		if pct {
+			kind = "percentage"
+		}
+		if notation != "" && kind != notation {
+			return nil, nil, invalidInput("mixed weight notations: write every weight as a decimal (0.6) or every weight as a percentage (60%%), not both")
+		}
+		notation = kind
+
+		if err := checkWeight(symbol, weight); err != nil {
+			return nil, nil, err
+		}
+
+		symbols = append(symbols, symbol)
+		weights = append(weights, weight)
+		totalWeight += weight
+	}
+
+	if restAt >= 0 {
+		rest := 1 - totalWeight
+		if rest <= 1e-9 {
+			return nil, nil, invalidInput("nothing left for rest: the other weights already add up to %.1f%%", totalWeight*100)
+		}
+		if err := checkWeight(symbols[restAt], rest); err != nil {
+			return nil, nil, err
+		}
+		weights[restAt] = rest
+	}
+	return symbols, weights, nil
+}
+
+// parseWeight reads a decimal weight (0.6, -0.2) or a percentage (60%, -20%)
+// as a fraction, reporting which notation was used.
+func parseWeight(s string) (weight float64, pct bool, err error) {
+	if trimmed, ok := strings.CutSuffix(s, "%"); ok {
+		w, err := strconv.ParseFloat(trimmed, 64)
+		return w / 100, true, err
+	}
+	w, err := strconv.ParseFloat(s, 64)
+	return w, false, err
+}
+
+// checkWeight enforces the per-position limits: at most 100% long or short.
+func checkWeight(symbol string, weight float64) error {
+	// Allow negative weights for short positions
+	if weight > 1 {
+		return invalidInput("long weight %f for symbol %s exceeds 1.0", weight, symbol)
+	}
+
+	if weight < -1 {
+		return invalidInput("short weight %f for symbol %s exceeds -1.0 (max 100%% short)", weight, symbol)
+	}
+	return nil
+}
+
 // createPortfolioConfig creates a PortfolioConfig from symbols and weights
 func createPortfolioConfig(symbols []string, weights []float64, initialValue float64) (*PortfolioConfig, error) {
 	if len(symbols) != len(weights) {
-		return nil, fmt.Errorf("symbols and weights length mismatch: %d vs %d", len(symbols), len(weights))
+		return nil, invalidInput("symbols and weights length mismatch: %d vs %d", len(symbols), len(weights))
 	}
 
 	var assets []WeightedAsset
@@ -0,0 +1,96 @@
+package finance
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+type portfolioParseCase struct {
+	name    string
+	input   string
+	syms    []string
+	weights []float64
+	window  string
+	wantErr string // substring of the error
+}
+
+func runPortfolioParseCases(t *testing.T, tests []portfolioParseCase) {
+	t.Helper()
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			syms, weights, window, err := ParseWeightedPortfolio(tc.input)
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Fatalf("ParseWeightedPortfolio(%q) = %v %v %q, %v; want error containing %q", tc.input, syms, weights, window, err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseWeightedPortfolio(%q): %v", tc.input, err)
+			}
+			if !slices.Equal(syms, tc.syms) || window != tc.window || len(weights) != len(tc.weights) {
+				t.Fatalf("ParseWeightedPortfolio(%q) = %v %v %q, want %v %v %q", tc.input, syms, weights, window, tc.syms, tc.weights, tc.window)
+			}
+			for i := range weights {
+				if !closeTo(weights[i], tc.weights[i], 1e-9) {
+					t.Errorf("weight of %s = %v, want %v", syms[i], weights[i], tc.weights[i])
+				}
+			}
+		})
+	}
+}
+
+func TestParseWeightedPortfolioWindow(t *testing.T) {
+	runPortfolioParseCases(t, []portfolioParseCase{
+		{name: "missing window", input: "/port SPY 0.5 AAPL 0.5", syms: []string{"SPY", "AAPL"}, weights: []float64{0.5, 0.5}, window: "1y"},
+		{name: "window", input: "/port spy 0.6 tlt 0.4 2y", syms: []string{"SPY", "TLT"}, weights: []float64{0.6, 0.4}, window: "2y"},
+		{name: "uppercase window", input: "SPY 0.6 TLT 0.4 YTD", syms: []string{"SPY", "TLT"}, weights: []float64{0.6, 0.4}, window: "ytd"},
+		{name: "days window", input: "SPY 1 30d", syms: []string{"SPY"}, weights: []float64{1}, window: "30d"},
+		// a weight is never taken for the window
+		{name: "whole-number weight", input: "SPY 1", syms: []string{"SPY"}, weights: []float64{1}, window: "1y"},
+		{name: "short weight last", input: "SPY 0.8 QQQ -0.3", syms: []string{"SPY", "QQQ"}, weights: []float64{0.8, -0.3}, window: "1y"},
+		{name: "misplaced window", input: "SPY 0.6 2y TLT 0.4", wantErr: "must come last"},
+		{name: "fractional window", input: "SPY 0.5 AAPL 1.5y", wantErr: "invalid window 1.5y"},
+		{name: "zero window", input: "SPY 0.5 AAPL 0.5 0m", wantErr: "at least 1"},
+		{name: "window without pairs", input: "SPY 2y", wantErr: "each symbol must have a weight"},
+	})
+}
+
+func TestParseWeightedPortfolioMalformed(t *testing.T) {
+	runPortfolioParseCases(t, []portfolioParseCase{
+		{name: "empty", input: "/port", wantErr: "insufficient arguments"},
+		{name: "symbol only", input: "SPY", wantErr: "insufficient arguments"},
+		{name: "window only", input: "2y", wantErr: "insufficient arguments"},
+		{name: "odd pairs", input: "SPY 0.5 AAPL", wantErr: "each symbol must have a weight"},
+		{name: "word weight", input: "SPY half AAPL 0.5", wantErr: "invalid weight 'half' for symbol SPY"},
+		{name: "duplicate", input: "SPY 0.5 spy 0.5", wantErr: "duplicate symbol: SPY"},
+		{name: "long over 100%", input: "SPY 1.5", wantErr: "exceeds 1.0"},
+		{name: "short over 100%", input: "SPY 1 QQQ -1.2", wantErr: "exceeds -1.0"},
+		{name: "leverage", input: "SPY 1 QQQ 1 IWM -1 TLT 0.5", wantErr: "exceeds 3.0"},
+	})
+}
+
+func TestParseWeightedPortfolioNotations(t *testing.T) {
+	runPortfolioParseCases(t, []portfolioParseCase{
+		{name: "percentages", input: "/port SPY 50% QQQ 30% GLD 20% 2y", syms: []string{"SPY", "QQQ", "GLD"}, weights: []float64{0.5, 0.3, 0.2}, window: "2y"},
+		{name: "short percentage", input: "SPY 80% TLT -20%", syms: []string{"SPY", "TLT"}, weights: []float64{0.8, -0.2}, window: "1y"},
+		{name: "decimals still work", input: "SPY 0.6 TLT 0.4", syms: []string{"SPY", "TLT"}, weights: []float64{0.6, 0.4}, window: "1y"},
+		{name: "eq", input: "/port SPY QQQ GLD eq 1y", syms: []string{"SPY", "QQQ", "GLD"}, weights: []float64{1.0 / 3, 1.0 / 3, 1.0 / 3}, window: "1y"},
+		{name: "eq without window", input: "spy tlt EQ", syms: []string{"SPY", "TLT"}, weights: []float64{0.5, 0.5}, window: "1y"},
+		{name: "rest", input: "SPY 0.6 TLT rest", syms: []string{"SPY", "TLT"}, weights: []float64{0.6, 0.4}, window: "1y"},
+		{name: "rest after percentages", input: "SPY 50% QQQ 30% GLD REST 6m", syms: []string{"SPY", "QQQ", "GLD"}, weights: []float64{0.5, 0.3, 0.2}, window: "6m"},
+		{name: "rest covers a short", input: "SPY 0.8 QQQ -0.3 TLT rest", syms: []string{"SPY", "QQQ", "TLT"}, weights: []float64{0.8, -0.3, 0.5}, window: "1y"},
+		{name: "mixed notations", input: "SPY 0.5 QQQ 50%", wantErr: "mixed weight notations"},
+		{name: "mixed notations reversed", input: "SPY 50% QQQ 0.5", wantErr: "mixed weight notations"},
+		{name: "eq with weights", input: "SPY 0.5 QQQ eq", wantErr: "can't be combined"},
+		{name: "eq as a symbol", input: "eq 0.5 SPY 0.5", wantErr: "can't be combined"},
+		{name: "eq alone", input: "eq 1y", wantErr: "eq needs at least one symbol"},
+		{name: "eq without symbols", input: "eq eq", wantErr: "can't be combined"},
+		{name: "rest not last", input: "SPY rest TLT 0.4", wantErr: "rest can only be the weight of the last symbol"},
+		{name: "nothing left for rest", input: "SPY 60% QQQ 40% GLD rest", wantErr: "nothing left for rest"},
+		{name: "rest over 100%", input: "SPY -0.5 TLT rest", wantErr: "exceeds 1.0"},
+		{name: "percentage over 100%", input: "SPY 150%", wantErr: "exceeds 1.0"},
+		{name: "bare percent", input: "SPY %", wantErr: "invalid weight '%'"},
+	})
+}
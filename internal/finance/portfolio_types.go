@@ -7,6 +7,9 @@ type PortfolioData struct {
 	Timestamps []time.Time
 	Values     []float64 // Portfolio values starting from 100
 	Returns    []float64 // Daily returns
+	// WipedOut is the index of the day the value fell to zero, where the
+	// series ends; 0 when it never did.
+	WipedOut int
 }
 
 // PortfolioStats represents calculated portfolio statistics
@@ -17,8 +20,35 @@ type PortfolioStats struct {
 	AnnualReturn float64 // Annualized return
 	Volatility   float64 // Annualized volatility
 	SharpeRatio  float64 // Risk-free rate assumed to be 0
+	SortinoRatio float64 // Like Sharpe, over the downside deviation only
+	CalmarRatio  float64 // Annual return over maximum drawdown
 	MaxDrawdown  float64 // Maximum drawdown as percentage
 	NumDays      int     // Number of trading days
+
+	// Dates need the portfolio's timestamps and are zero without them.
+	MaxDDPeak   time.Time // last high before the maximum drawdown
+	MaxDDTrough time.Time // bottom of the maximum drawdown
+	BestDay     float64   // best daily return as percentage
+	BestDayAt   time.Time
+	WorstDay    float64 // worst daily return as percentage
+	WorstDayAt  time.Time
+
+	// Starts is each asset's first bar in the window. TruncatedTo is set when
+	// LimitedBy's data begins materially later than the window, so the
+	// backtest only covers the time since.
+	Starts      []AssetStart
+	TruncatedTo time.Time
+	LimitedBy   string
+
+	// WipedOutAt is the day shorts or margin took the value to zero and the
+	// backtest stopped; zero when it never did.
+	WipedOutAt time.Time
+}
+
+// AssetStart is the first bar of an asset's data within a portfolio window.
+type AssetStart struct {
+	Symbol string
+	Start  time.Time
 }
 
 // AssetData represents price data for a single asset
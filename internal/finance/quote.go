@@ -0,0 +1,209 @@
+package finance
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"telegramBotTrade/internal/logging"
+)
+
+// Quote is the latest price of a symbol and its change from the previous close.
+type Quote struct {
+	Symbol    string
+	Price     float64
+	PrevClose float64
+	ChangePct float64
+	Time      time.Time // timestamp of the latest bar
+}
+
+const (
+	quoteCacheTTL    = 30 * time.Second
+	quoteConcurrency = 4
+	// yahooQuoteURL is Yahoo's batch quote endpoint; it needs the crumb.
+	yahooQuoteURL = "https://query1.finance.yahoo.com/v7/finance/quote"
+	// batchQuoteSize is the most symbols asked of yahooQuoteURL at once.
+	batchQuoteSize = 50
+)
+
+var (
+	quoteCache   = map[string]Quote{}
+	quoteCacheAt = map[string]time.Time{}
+	quoteCacheMu sync.Mutex
+	quoteLookups cacheCounter
+)
+
+// QuoteCacheStats reports the lookups of the quote cache.
+func QuoteCacheStats() CacheStats { return quoteLookups.stats() }
+
+// FetchQuote returns the latest daily bar of symbol against the previous close.
+// Results are cached briefly so pollers and briefs don't refetch the same symbol.
+func FetchQuote(ctx context.Context, symbol string) (Quote, error) {
+	symbol = strings.ToUpper(strings.TrimSpace(symbol))
+	if q, ok := cachedQuote(symbol); ok {
+		return q, nil
+	}
+
+	ts, cl, err := fetchSeries(ctx, symbol, "1d", "5d")
+	if err != nil {
+		return Quote{}, err
+	}
+	if len(cl) < 2 {
+		return Quote{}, noData("not enough data for a quote")
+	}
+	n := len(cl)
+	q := Quote{
+		Symbol:    symbol,
+		Price:     cl[n-1],
+		PrevClose: cl[n-2],
+		Time:      time.Unix(ts[n-1], 0),
+	}
+	if q.PrevClose != 0 {
+		q.ChangePct = (q.Price/q.PrevClose - 1) * 100
+	}
+	cacheQuote(q)
+	return q, nil
+}
+
+// cachedQuote returns symbol's quote if it was fetched within quoteCacheTTL.
+func cachedQuote(symbol string) (Quote, bool) {
+	quoteCacheMu.Lock()
+	defer quoteCacheMu.Unlock()
+	q, ok := quoteCache[symbol]
+	ok = ok && time.Since(quoteCacheAt[symbol]) < quoteCacheTTL
+	quoteLookups.record(ok)
+	return q, ok
+}
+
+func cacheQuote(q Quote) {
+	quoteCacheMu.Lock()
+	quoteCache[q.Symbol] = q
+	quoteCacheAt[q.Symbol] = time.Now()
+	quoteCacheMu.Unlock()
+}
+
+// FetchQuotes fetches several symbols with bounded concurrency. Symbols that
+// fail are reported in errs instead of failing the whole batch.
+func FetchQuotes(ctx context.Context, symbols []string) (quotes map[string]Quote, errs map[string]error) {
+	quotes = map[string]Quote{}
+	errs = map[string]error{}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, quoteConcurrency)
+	for _, sym := range symbols {
+		wg.Add(1)
+		go func(sym string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			q, err := FetchQuote(ctx, sym)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				errs[sym] = err
+				return
+			}
+			quotes[sym] = q
+		}(sym)
+	}
+	wg.Wait()
+	return quotes, errs
+}
+
+type batchQuoteResp struct {
+	QuoteResponse struct {
+		Result []struct {
+			Symbol                     string  `json:"symbol"`
+			RegularMarketPrice         float64 `json:"regularMarketPrice"`
+			RegularMarketPreviousClose float64 `json:"regularMarketPreviousClose"`
+			RegularMarketChangePercent float64 `json:"regularMarketChangePercent"`
+			RegularMarketTime          int64   `json:"regularMarketTime"`
+		} `json:"result"`
+		Error *struct {
+			Code        string `json:"code"`
+			Description string `json:"description"`
+		} `json:"error"`
+	} `json:"quoteResponse"`
+}
+
+// FetchBatchQuotes is FetchQuotes for many symbols at once: symbols not in
+// the quote cache are asked of Yahoo's quote endpoint batchQuoteSize at a
+// time, one request per batch instead of one chart fetch per symbol. Symbols
+// a batch doesn't return, batches that fail and injected sources such as
+// DEMO_MODE go through FetchQuotes. Results share FetchQuote's cache.
+func FetchBatchQuotes(ctx context.Context, symbols []string) (quotes map[string]Quote, errs map[string]error) {
+	if seriesSourceFrom(ctx) != nil {
+		return FetchQuotes(ctx, symbols)
+	}
+	quotes = map[string]Quote{}
+	var missing []string
+	for _, sym := range symbols {
+		if q, ok := cachedQuote(strings.ToUpper(strings.TrimSpace(sym))); ok {
+			quotes[sym] = q
+		} else if !slices.Contains(missing, sym) {
+			missing = append(missing, sym)
+		}
+	}
+	var fallback []string
+	for start := 0; start < len(missing); start += batchQuoteSize {
+		batch := missing[start:min(start+batchQuoteSize, len(missing))]
+		got, err := fetchQuoteBatch(ctx, batch)
+		if err != nil {
+			logging.FromContext(ctx).Warn("yahoo: batch quote failed", "symbols", len(batch), "err", err)
+		}
+		for _, sym := range batch {
+			if q, ok := got[strings.ToUpper(strings.TrimSpace(sym))]; ok {
+				quotes[sym] = q
+			} else {
+				fallback = append(fallback, sym)
+			}
+		}
+	}
+	errs = map[string]error{}
+	if len(fallback) > 0 {
+		var more map[string]Quote
+		more, errs = FetchQuotes(ctx, fallback)
+		maps.Copy(quotes, more)
+	}
+	return quotes, errs
+}
+
+// fetchQuoteBatch asks Yahoo's quote endpoint for symbols and caches every
+// usable quote, keyed by upper-case symbol.
+func fetchQuoteBatch(ctx context.Context, symbols []string) (map[string]Quote, error) {
+	upper := make([]string, len(symbols))
+	for i, s := range symbols {
+		upper[i] = strings.ToUpper(strings.TrimSpace(s))
+	}
+	var resp batchQuoteResp
+	if err := yahooCrumbs.get(ctx, yahooQuoteURL+"?symbols="+url.QueryEscape(strings.Join(upper, ",")), &resp); err != nil {
+		return nil, err
+	}
+	if e := resp.QuoteResponse.Error; e != nil {
+		return nil, fmt.Errorf("yahoo quote: %s", e.Description)
+	}
+	out := make(map[string]Quote, len(resp.QuoteResponse.Result))
+	for _, r := range resp.QuoteResponse.Result {
+		if r.RegularMarketPrice <= 0 || r.RegularMarketTime == 0 {
+			continue
+		}
+		q := Quote{
+			Symbol:    strings.ToUpper(r.Symbol),
+			Price:     r.RegularMarketPrice,
+			PrevClose: r.RegularMarketPreviousClose,
+			ChangePct: r.RegularMarketChangePercent,
+			Time:      time.Unix(r.RegularMarketTime, 0),
+		}
+		if q.PrevClose != 0 {
+			q.ChangePct = (q.Price/q.PrevClose - 1) * 100
+		}
+		cacheQuote(q)
+		out[q.Symbol] = q
+	}
+	return out, nil
+}
@@ -0,0 +1,65 @@
+package finance
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// yahooLimiter keeps a minimum gap between Yahoo requests across every caller
+// (charts, quotes, background pollers) so bursts don't trigger 429s. The gap is
+// tunable with SetYahooPacing.
+var yahooLimiter = newRateLimiter(float64(time.Second)/float64(defaultYahooMinGap), 1)
+
+// rateLimiter is a token bucket refilled at perSecond up to burst tokens.
+type rateLimiter struct {
+	mu       sync.Mutex
+	interval time.Duration
+	burst    int
+	tokens   float64
+	last     time.Time
+}
+
+func newRateLimiter(perSecond float64, burst int) *rateLimiter {
+	return &rateLimiter{
+		interval: time.Duration(float64(time.Second) / perSecond),
+		burst:    burst,
+		tokens:   float64(burst),
+		last:     time.Now(),
+	}
+}
+
+// setInterval changes the refill interval, i.e. the minimum gap when burst is 1.
+func (l *rateLimiter) setInterval(d time.Duration) {
+	l.mu.Lock()
+	l.interval = d
+	l.mu.Unlock()
+}
+
+// wait blocks until a token is available or ctx is done.
+func (l *rateLimiter) wait(ctx context.Context) error {
+	for {
+		l.mu.Lock()
+		now := time.Now()
+		l.tokens += float64(now.Sub(l.last)) / float64(l.interval)
+		if l.tokens > float64(l.burst) {
+			l.tokens = float64(l.burst)
+		}
+		l.last = now
+		if l.tokens >= 1 {
+			l.tokens--
+			l.mu.Unlock()
+			return nil
+		}
+		delay := time.Duration((1 - l.tokens) * float64(l.interval))
+		l.mu.Unlock()
+
+		t := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return ctx.Err()
+		case <-t.C:
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package finance
+
+import (
+	"strings"
+	"time"
+
+	"telegramBotTrade/internal/chartkit"
+)
+
+// RenderOptions carries presentation settings shared by every chart builder.
+type RenderOptions struct {
+	Theme    string         // light (default), dark, grafana or ant
+	Location *time.Location // x-axis label time zone (default America/New_York)
+	VWAP     bool           // overlay session VWAP on intraday single-symbol charts
+	Format   string         // png (default) or svg; stacked charts (MACD, VIX) are always PNG
+	Anchor   string         // intraday single-symbol charts: "" or one of Anchors
+	NoEvents bool           // leave dividend and split markers off daily single-symbol charts
+}
+
+// Chart output formats accepted in RenderOptions.Format.
+const (
+	FormatPNG = "png"
+	FormatSVG = "svg"
+)
+
+// Themes lists the accepted RenderOptions.Theme values.
+var Themes = chartkit.Themes
+
+// theme returns the chart theme name, defaulting to light.
+func (o RenderOptions) theme() string {
+	t := strings.ToLower(strings.TrimSpace(o.Theme))
+	for _, name := range Themes {
+		if t == name {
+			return name
+		}
+	}
+	return chartkit.ThemeLight
+}
+
+// location returns the label time zone, defaulting to Eastern Time.
+func (o RenderOptions) location() *time.Location {
+	if o.Location != nil {
+		return o.Location
+	}
+	return getEasternTime()
+}
+
+// SVG reports whether the chart is rendered as SVG instead of PNG.
+func (o RenderOptions) SVG() bool {
+	return strings.EqualFold(strings.TrimSpace(o.Format), FormatSVG)
+}
+
+// style returns the chartkit theme and image format.
+func (o RenderOptions) style() chartkit.Style {
+	return chartkit.Style{Theme: o.theme(), SVG: o.SVG()}
+}
+
+// DefaultLocation is the time zone used when RenderOptions.Location is nil.
+func DefaultLocation() *time.Location { return getEasternTime() }
+
+// cacheSuffix keeps cached images for different render options apart.
+func (o RenderOptions) cacheSuffix() string {
+	suffix := "|" + o.theme() + "|" + o.location().String()
+	if o.VWAP {
+		suffix += "|vwap"
+	}
+	if o.SVG() {
+		suffix += "|svg"
+	}
+	if o.Anchor != "" {
+		suffix += "|" + o.Anchor
+	}
+	if o.NoEvents {
+		suffix += "|noevents"
+	}
+	return suffix
+}
@@ -0,0 +1,158 @@
+package finance
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// ErrRenderBusy is returned when the render queue is full. Handlers tell the
+// user to try again shortly instead of showing the error.
+var ErrRenderBusy = errors.New("chart renderer busy")
+
+const (
+	defaultRenderWorkers = 2
+	defaultRenderQueue   = 16
+	defaultRenderTimeout = 30 * time.Second
+	renderStatsEvery     = 10 * time.Minute
+)
+
+// renderer is the process-wide render queue every chart image goes through.
+var renderer = newRenderQueue(defaultRenderWorkers, defaultRenderQueue, defaultRenderTimeout)
+
+// SetRenderPool replaces the render queue with one of workers goroutines, up
+// to queue waiting jobs and a per-job timeout (queue wait included). Call it at
+// startup, before charts are requested; values <= 0 keep the defaults.
+func SetRenderPool(workers, queue int, timeout time.Duration) {
+	old := renderer
+	renderer = newRenderQueue(workers, queue, timeout)
+	close(old.jobs)
+}
+
+type renderResult struct {
+	img []byte
+	err error
+}
+
+type renderJob struct {
+	ctx  context.Context
+	draw func() ([]byte, error)
+	done chan renderResult
+}
+
+// renderQueue renders charts on a fixed number of goroutines fed by a bounded
+// queue, so a burst of chart commands can't spike CPU and memory or starve the
+// webhook handler.
+type renderQueue struct {
+	jobs    chan renderJob
+	workers int
+	timeout time.Duration
+
+	mu       sync.Mutex
+	since    time.Time
+	rendered int
+	rejected int
+	timedOut int
+	maxDepth int
+}
+
+func newRenderQueue(workers, queue int, timeout time.Duration) *renderQueue {
+	if workers <= 0 {
+		workers = defaultRenderWorkers
+	}
+	if queue <= 0 {
+		queue = defaultRenderQueue
+	}
+	if timeout <= 0 {
+		timeout = defaultRenderTimeout
+	}
+	q := &renderQueue{jobs: make(chan renderJob, queue), workers: workers, timeout: timeout, since: time.Now()}
+	for i := 0; i < workers; i++ {
+		go q.work()
+	}
+	return q
+}
+
+func (q *renderQueue) work() {
+	for j := range q.jobs {
+		if j.ctx.Err() != nil {
+			// the caller gave up while the job was queued
+			j.done <- renderResult{err: j.ctx.Err()}
+			continue
+		}
+		j.done <- runRender(j.draw)
+	}
+}
+
+// runRender runs one job, turning a go-charts panic into an error: the worker runs
+// outside the handler's panic recovery.
+func runRender(fn func() ([]byte, error)) (res renderResult) {
+	defer func() {
+		if r := recover(); r != nil {
+			res = renderResult{err: fmt.Errorf("chart render panicked: %v", r)}
+		}
+	}()
+	img, err := fn()
+	return renderResult{img: img, err: err}
+}
+
+// render draws and encodes one chart on the pool. It fails fast with
+// ErrRenderBusy when the queue is full.
+func (q *renderQueue) render(ctx context.Context, draw func() ([]byte, error)) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, q.timeout)
+	defer cancel()
+	j := renderJob{ctx: ctx, draw: draw, done: make(chan renderResult, 1)}
+	select {
+	case q.jobs <- j:
+		q.record(len(q.jobs), false, false)
+	default:
+		q.record(len(q.jobs), true, false)
+		return nil, ErrRenderBusy
+	}
+	select {
+	case res := <-j.done:
+		return res.img, res.err
+	case <-ctx.Done():
+		q.record(0, false, true)
+		return nil, fmt.Errorf("chart render: %w", ctx.Err())
+	}
+}
+
+// record updates the counters and logs a summary every renderStatsEvery, to
+// size RENDER_WORKERS and RENDER_QUEUE_SIZE.
+func (q *renderQueue) record(depth int, rejected, timedOut bool) {
+	q.mu.Lock()
+	switch {
+	case rejected:
+		q.rejected++
+	case timedOut:
+		q.timedOut++
+	default:
+		q.rendered++
+	}
+	if depth > q.maxDepth {
+		q.maxDepth = depth
+	}
+	if time.Since(q.since) < renderStatsEvery {
+		q.mu.Unlock()
+		return
+	}
+	rendered, rej, tout, maxDepth, since := q.rendered, q.rejected, q.timedOut, q.maxDepth, q.since
+	q.rendered, q.rejected, q.timedOut, q.maxDepth, q.since = 0, 0, 0, 0, time.Now()
+	q.mu.Unlock()
+	slog.Info("render: queue summary",
+		"window", time.Since(since).Round(time.Second).String(),
+		"workers", q.workers,
+		"queued", rendered,
+		"rejected", rej,
+		"timed_out", tout,
+		"max_depth", maxDepth)
+}
+
+// renderChart draws a chart and encodes it (PNG or SVG) on the render pool.
+func renderChart(ctx context.Context, draw func() ([]byte, error)) ([]byte, error) {
+	return renderer.render(ctx, draw)
+}
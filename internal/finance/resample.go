@@ -0,0 +1,50 @@
+package finance
+
+import (
+	"time"
+)
+
+// chartPointBudget is the most points a line chart renders; longer daily
+// series are resampled to weekly or monthly closes.
+const chartPointBudget = 1500
+
+// resampleCloses keeps the last close of each period ("1wk" = ISO week,
+// "1mo" = calendar month) with periods taken in loc. The timestamp of each
+// kept point is that of its last bar, so labels show a real trading day.
+func resampleCloses(ts []int64, cl []float64, period string, loc *time.Location) ([]int64, []float64) {
+	n := len(ts)
+	if len(cl) < n {
+		n = len(cl)
+	}
+	outTs := make([]int64, 0, n)
+	outCl := make([]float64, 0, n)
+	key := func(t int64) int {
+		tt := time.Unix(t, 0).In(loc)
+		if period == "1mo" {
+			return tt.Year()*100 + int(tt.Month())
+		}
+		y, w := tt.ISOWeek()
+		return y*100 + w
+	}
+	for i := 0; i < n; i++ {
+		if i+1 < n && key(ts[i]) == key(ts[i+1]) {
+			continue
+		}
+		outTs = append(outTs, ts[i])
+		outCl = append(outCl, cl[i])
+	}
+	return outTs, outCl
+}
+
+// resamplePeriod returns the coarsest period needed to fit n daily points in
+// the budget ("" when they already fit).
+func resamplePeriod(n int) string {
+	switch {
+	case n <= chartPointBudget:
+		return ""
+	case n/5 <= chartPointBudget:
+		return "1wk"
+	default:
+		return "1mo"
+	}
+}
@@ -0,0 +1,109 @@
+package finance
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+// dailyBar is the Unix time of a daily bar stamped at the 09:30 New York open.
+func dailyBar(y int, m time.Month, d int) int64 {
+	return time.Date(y, m, d, 9, 30, 0, 0, DefaultLocation()).Unix()
+}
+
+func TestResampleCloses(t *testing.T) {
+	lateJan31 := time.Date(2024, time.January, 31, 23, 30, 0, 0, DefaultLocation()).Unix() // February in UTC
+	tests := []struct {
+		name   string
+		ts     []int64
+		period string
+		loc    *time.Location
+		want   []int64
+	}{
+		{
+			name: "months across the new year",
+			ts: []int64{dailyBar(2023, time.December, 28), dailyBar(2023, time.December, 29),
+				dailyBar(2024, time.January, 2), dailyBar(2024, time.January, 3)},
+			period: "1mo", loc: DefaultLocation(),
+			want: []int64{dailyBar(2023, time.December, 29), dailyBar(2024, time.January, 3)},
+		},
+		{
+			// Monday December 30 2024 already belongs to ISO week 2025-W01
+			name: "ISO week spanning the new year",
+			ts: []int64{dailyBar(2024, time.December, 26), dailyBar(2024, time.December, 27), dailyBar(2024, time.December, 30),
+				dailyBar(2024, time.December, 31), dailyBar(2025, time.January, 2), dailyBar(2025, time.January, 3)},
+			period: "1wk", loc: DefaultLocation(),
+			want: []int64{dailyBar(2024, time.December, 27), dailyBar(2025, time.January, 3)},
+		},
+		{
+			name: "months split the same ISO week",
+			ts: []int64{dailyBar(2024, time.December, 26), dailyBar(2024, time.December, 27), dailyBar(2024, time.December, 30),
+				dailyBar(2024, time.December, 31), dailyBar(2025, time.January, 2), dailyBar(2025, time.January, 3)},
+			period: "1mo", loc: DefaultLocation(),
+			want: []int64{dailyBar(2024, time.December, 31), dailyBar(2025, time.January, 3)},
+		},
+		{
+			name:   "week 53",
+			ts:     []int64{dailyBar(2020, time.December, 24), dailyBar(2020, time.December, 30), dailyBar(2020, time.December, 31), dailyBar(2021, time.January, 4)},
+			period: "1wk", loc: DefaultLocation(),
+			want: []int64{dailyBar(2020, time.December, 24), dailyBar(2020, time.December, 31), dailyBar(2021, time.January, 4)},
+		},
+		{
+			name:   "month end in the chart's zone",
+			ts:     []int64{dailyBar(2024, time.January, 30), lateJan31, dailyBar(2024, time.February, 1)},
+			period: "1mo", loc: DefaultLocation(),
+			want: []int64{lateJan31, dailyBar(2024, time.February, 1)},
+		},
+		{
+			name:   "month end in UTC",
+			ts:     []int64{dailyBar(2024, time.January, 30), lateJan31, dailyBar(2024, time.February, 1)},
+			period: "1mo", loc: time.UTC,
+			want: []int64{dailyBar(2024, time.January, 30), dailyBar(2024, time.February, 1)},
+		},
+		{name: "empty", period: "1wk", loc: DefaultLocation()},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			cl := make([]float64, len(tc.ts))
+			for i := range cl {
+				cl[i] = float64(i + 1)
+			}
+			gotTs, gotCl := resampleCloses(tc.ts, cl, tc.period, tc.loc)
+			if !slices.Equal(gotTs, tc.want) {
+				t.Fatalf("timestamps = %v, want %v", gotTs, tc.want)
+			}
+			// each kept close is the one of its bar
+			for i, ts := range gotTs {
+				if want := cl[slices.Index(tc.ts, ts)]; gotCl[i] != want {
+					t.Errorf("close %d = %v, want %v", i, gotCl[i], want)
+				}
+			}
+		})
+	}
+}
+
+func TestResampleClosesShortCloses(t *testing.T) {
+	ts := []int64{dailyBar(2024, time.January, 30), dailyBar(2024, time.January, 31), dailyBar(2024, time.February, 1)}
+	gotTs, gotCl := resampleCloses(ts, []float64{1, 2}, "1mo", DefaultLocation())
+	if !slices.Equal(gotTs, ts[1:2]) || !slices.Equal(gotCl, []float64{2}) {
+		t.Errorf("got %v %v, want the January close only", gotTs, gotCl)
+	}
+}
+
+func TestResamplePeriod(t *testing.T) {
+	tests := []struct {
+		n    int
+		want string
+	}{
+		{0, ""},
+		{chartPointBudget, ""},
+		{chartPointBudget + 1, "1wk"},
+		{chartPointBudget*5 + 4, "1wk"},
+		{chartPointBudget*5 + 5, "1mo"},
+	}
+	for _, tc := range tests {
+		if got := resamplePeriod(tc.n); got != tc.want {
+			t.Errorf("resamplePeriod(%d) = %q, want %q", tc.n, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package finance
+
+import (
+	"context"
+	"time"
+)
+
+// Call is a long or short position suggested at a point in time.
+type Call struct {
+	Symbol string
+	Short  bool
+	At     time.Time
+}
+
+// CallScore is a call's return since it was made, in percent and
+// sign-adjusted so a falling price counts for a short. OK is false when the
+// symbol's prices could not be fetched.
+type CallScore struct {
+	Call
+	Return float64
+	OK     bool
+}
+
+// ScoreCalls measures each call from the last daily close at or before it was
+// made to the latest close. Each symbol is fetched once, over the shortest
+// Yahoo range reaching its oldest call.
+func ScoreCalls(ctx context.Context, calls []Call) []CallScore {
+	oldest := map[string]time.Time{}
+	for _, c := range calls {
+		if t, ok := oldest[c.Symbol]; !ok || c.At.Before(t) {
+			oldest[c.Symbol] = c.At
+		}
+	}
+	type series struct {
+		ts     []int64
+		closes []float64
+	}
+	fetched := map[string]series{}
+	for sym, since := range oldest {
+		ts, closes, err := fetchSeries(ctx, sym, "1d", sinceRange(since, time.Now()))
+		if err == nil {
+			fetched[sym] = series{ts, closes}
+		}
+	}
+	out := make([]CallScore, len(calls))
+	for i, c := range calls {
+		out[i].Call = c
+		if s, ok := fetched[c.Symbol]; ok {
+			out[i].Return, out[i].OK = callReturn(s.ts, s.closes, c)
+		}
+	}
+	return out
+}
+
+// callReturn is c's sign-adjusted return over daily closes ts/closes. The
+// base is the last close at or before c.At, or the first one when the series
+// starts later.
+func callReturn(ts []int64, closes []float64, c Call) (float64, bool) {
+	if len(ts) == 0 || len(ts) != len(closes) {
+		return 0, false
+	}
+	base := closes[0]
+	for i, t := range ts {
+		if t > c.At.Unix() {
+			break
+		}
+		base = closes[i]
+	}
+	if base <= 0 {
+		return 0, false
+	}
+	ret := (closes[len(closes)-1]/base - 1) * 100
+	if c.Short {
+		ret = -ret
+	}
+	return ret, true
+}
+
+// sinceRange is the shortest Yahoo range holding a daily close before since,
+// with a few days' margin for weekends and holidays.
+func sinceRange(since, now time.Time) string {
+	days := int(now.Sub(since).Hours()/24) + 5
+	switch {
+	case days <= 28:
+		return "1mo"
+	case days <= 88:
+		return "3mo"
+	case days <= 180:
+		return "6mo"
+	}
+	return longRange(days)
+}
+
+// CallStats summarizes scored calls: how many could be scored, how many made
+// money and their average return in percent.
+func CallStats(scores []CallScore) (scored, hits int, avg float64) {
+	for _, s := range scores {
+		if !s.OK {
+			continue
+		}
+		scored++
+		if s.Return > 0 {
+			hits++
+		}
+		avg += s.Return
+	}
+	if scored > 0 {
+		avg /= float64(scored)
+	}
+	return scored, hits, avg
+}
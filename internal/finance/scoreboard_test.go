@@ -0,0 +1,144 @@
+package finance
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+// day0 is the first bar of the scoreboard fixtures, a Monday 21:00 UTC close.
+var day0 = time.Date(2024, 3, 4, 21, 0, 0, 0, time.UTC)
+
+// dailyCloses returns bars one day apart from day0.
+func dailyCloses(closes ...float64) Series {
+	s := Series{Close: closes}
+	for i := range closes {
+		s.Timestamps = append(s.Timestamps, day0.AddDate(0, 0, i).Unix())
+	}
+	return s
+}
+
+// fixtureSource serves fixed series and records the ranges requested.
+type fixtureSource struct {
+	series map[string]Series
+	mu     sync.Mutex
+	ranges map[string][]string
+}
+
+func (f *fixtureSource) Series(_ context.Context, symbol, interval, rangeParam string) (Series, error) {
+	f.mu.Lock()
+	f.ranges[symbol] = append(f.ranges[symbol], rangeParam)
+	f.mu.Unlock()
+	s, ok := f.series[symbol]
+	if !ok || interval != "1d" {
+		return Series{}, ErrSymbolNotFound
+	}
+	return s, nil
+}
+
+func TestCallReturn(t *testing.T) {
+	s := dailyCloses(100, 110, 120, 90, 150)
+	tests := []struct {
+		name   string
+		call   Call
+		want   float64
+		wantOK bool
+	}{
+		{"long on a close", Call{At: day0.AddDate(0, 0, 1)}, 150.0/110*100 - 100, true},
+		{"long between closes uses the earlier", Call{At: day0.AddDate(0, 0, 2).Add(-time.Hour)}, 150.0/110*100 - 100, true},
+		{"short", Call{Short: true, At: day0.AddDate(0, 0, 3)}, -(150.0/90*100 - 100), true},
+		{"before the series", Call{At: day0.AddDate(0, 0, -10)}, 50, true},
+		{"on the last close", Call{At: day0.AddDate(0, 0, 4)}, 0, true},
+		{"after the last close", Call{Short: true, At: day0.AddDate(0, 0, 9)}, 0, true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, ok := callReturn(s.Timestamps, s.Close, tc.call)
+			if ok != tc.wantOK || !closeTo(got, tc.want, 1e-9) {
+				t.Errorf("callReturn = %v, %v; want %v, %v", got, ok, tc.want, tc.wantOK)
+			}
+		})
+	}
+	if _, ok := callReturn(nil, nil, Call{At: day0}); ok {
+		t.Error("empty series scored")
+	}
+	if _, ok := callReturn([]int64{1, 2}, []float64{1}, Call{At: day0}); ok {
+		t.Error("mismatched series scored")
+	}
+	if _, ok := callReturn([]int64{1, 2}, []float64{0, 5}, Call{At: time.Unix(1, 0)}); ok {
+		t.Error("zero base scored")
+	}
+}
+
+func TestSinceRange(t *testing.T) {
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		daysAgo int
+		want    string
+	}{
+		{1, "1mo"},
+		{23, "1mo"},
+		{30, "3mo"},
+		{83, "3mo"},
+		{90, "6mo"},
+		{175, "6mo"},
+		{200, "1y"},
+		{400, "2y"},
+	}
+	for _, tc := range tests {
+		if got := sinceRange(now.AddDate(0, 0, -tc.daysAgo), now); got != tc.want {
+			t.Errorf("sinceRange(%d days ago) = %q, want %q", tc.daysAgo, got, tc.want)
+		}
+	}
+}
+
+// TestScoreCalls scores synthetic recommendation rows end to end: several
+// calls on one symbol, a short, and a symbol without data.
+func TestScoreCalls(t *testing.T) {
+	src := &fixtureSource{
+		series: map[string]Series{
+			"AAA": dailyCloses(100, 110, 120, 90, 150),
+			"BBB": dailyCloses(50, 45, 40, 42, 40),
+		},
+		ranges: map[string][]string{},
+	}
+	ctx := WithSeriesSource(context.Background(), src)
+	calls := []Call{
+		{Symbol: "AAA", At: day0.AddDate(0, 0, 1)},
+		{Symbol: "AAA", Short: true, At: day0.AddDate(0, 0, 3)},
+		{Symbol: "BBB", Short: true, At: day0},
+		{Symbol: "XXX", At: day0},
+	}
+	scores := ScoreCalls(ctx, calls)
+	want := []struct {
+		ret float64
+		ok  bool
+	}{
+		{150.0/110*100 - 100, true},
+		{-(150.0/90*100 - 100), true},
+		{20, true},
+		{0, false},
+	}
+	for i, w := range want {
+		if scores[i].Call != calls[i] || scores[i].OK != w.ok || !closeTo(scores[i].Return, w.ret, 1e-9) {
+			t.Errorf("score %d = %+v, want return %v ok %v", i, scores[i], w.ret, w.ok)
+		}
+	}
+	if n := len(src.ranges["AAA"]); n != 1 {
+		t.Errorf("AAA fetched %d times, want once for both calls", n)
+	}
+
+	scored, hits, avg := CallStats(scores)
+	wantAvg := (150.0/110*100 - 100 - (150.0/90*100 - 100) + 20) / 3
+	if scored != 3 || hits != 2 || !closeTo(avg, wantAvg, 1e-9) {
+		t.Errorf("CallStats = %d scored, %d hits, %.3f avg; want 3, 2, %.3f", scored, hits, avg, wantAvg)
+	}
+}
+
+func TestCallStatsNoneScored(t *testing.T) {
+	scored, hits, avg := CallStats([]CallScore{{Call: Call{Symbol: "XXX"}}})
+	if scored != 0 || hits != 0 || avg != 0 {
+		t.Errorf("CallStats = %d, %d, %v; want zeros", scored, hits, avg)
+	}
+}
@@ -0,0 +1,63 @@
+package finance
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// yahooSearchURL is formatted with the query; news results are not requested.
+const yahooSearchURL = "https://query2.finance.yahoo.com/v1/finance/search?q=%s&quotesCount=5&newsCount=0"
+
+// symbolCacheTTL is how long a symbol lookup is remembered. Listings rarely
+// change, so one lookup a day per token is plenty.
+const symbolCacheTTL = 24 * time.Hour
+
+var (
+	symbolCache   = map[string]bool{}
+	symbolCacheAt = map[string]time.Time{}
+	symbolCacheMu sync.Mutex
+)
+
+type searchResp struct {
+	Quotes []struct {
+		Symbol    string `json:"symbol"`
+		QuoteType string `json:"quoteType"`
+	} `json:"quotes"`
+}
+
+// IsListedSymbol reports whether Yahoo's symbol search returns symbol itself
+// (not just a company whose name contains it), e.g. true for "TSLA" and false
+// for "CEO". Results are cached for a day. Under DEMO_MODE every symbol is
+// listed, since the demo source prices anything.
+func IsListedSymbol(ctx context.Context, symbol string) (bool, error) {
+	if DemoMode() {
+		return true, nil
+	}
+	symbol = strings.ToUpper(strings.TrimSpace(symbol))
+	symbolCacheMu.Lock()
+	if ok, hit := symbolCache[symbol]; hit && time.Since(symbolCacheAt[symbol]) < symbolCacheTTL {
+		symbolCacheMu.Unlock()
+		return ok, nil
+	}
+	symbolCacheMu.Unlock()
+
+	var resp searchResp
+	if err := yahooCrumbs.get(ctx, strings.Replace(yahooSearchURL, "%s", url.QueryEscape(symbol), 1), &resp); err != nil {
+		return false, err
+	}
+	listed := false
+	for _, q := range resp.Quotes {
+		if strings.EqualFold(q.Symbol, symbol) && q.QuoteType != "" {
+			listed = true
+			break
+		}
+	}
+	symbolCacheMu.Lock()
+	symbolCache[symbol] = listed
+	symbolCacheAt[symbol] = time.Now()
+	symbolCacheMu.Unlock()
+	return listed, nil
+}
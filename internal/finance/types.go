@@ -9,13 +9,24 @@ type yahooChartResp struct {
 	Chart struct {
 		Result []struct {
 			Meta struct {
-				GmtOffset int    `json:"gmtoffset"`
-				Timezone  string `json:"timezone"`
+				GmtOffset            int    `json:"gmtoffset"`
+				Timezone             string `json:"timezone"`
+				CurrentTradingPeriod struct {
+					Regular struct {
+						Start int64 `json:"start"`
+						End   int64 `json:"end"`
+					} `json:"regular"`
+				} `json:"currentTradingPeriod"`
 			} `json:"meta"`
-			Timestamp  []int64 `json:"timestamp"`
+			Timestamp  []int64     `json:"timestamp"`
+			Events     yahooEvents `json:"events"`
 			Indicators struct {
 				Quote []struct {
-					Close []float64 `json:"close"`
+					Open   []float64 `json:"open"`
+					High   []float64 `json:"high"`
+					Low    []float64 `json:"low"`
+					Close  []float64 `json:"close"`
+					Volume []float64 `json:"volume"`
 				} `json:"quote"`
 			} `json:"indicators"`
 		} `json:"result"`
@@ -23,6 +34,17 @@ type yahooChartResp struct {
 	} `json:"chart"`
 }
 
+// bars is one fetched series with its columns kept index-aligned.
+type bars struct {
+	ts              []int64
+	open, high, low []float64 // nil when the source has no OHLC (spark fallback)
+	close           []float64
+	volume          []float64 // nil when the source has no volume (spark fallback)
+	gmtOffset       int       // exchange offset from UTC in seconds
+	session         tradingPeriod
+	events          []corpEvent // dividends and splits, oldest first; nil without them
+}
+
 // yahooSparkResp mirrors Yahoo v7 spark fallback (trimmed)
 type yahooSparkResp struct {
 	Spark struct {
@@ -41,6 +63,9 @@ type yahooSparkResp struct {
 type chartCacheEntry struct {
 	createdAt time.Time
 	image     []byte
+	asOf      time.Time // last bar time of the data rendered
+	gap       missingNote
+	meta      ChartMeta
 }
 
 const chartCacheTTL = 60 * time.Second
@@ -1,13 +1,16 @@
 package finance
 
 import (
+	"context"
 	"fmt"
+	"math"
 	"sort"
+	"strconv"
+	"strings"
 	"time"
 
+	"telegramBotTrade/internal/chartkit"
 	"telegramBotTrade/internal/storage"
-
-	"github.com/vicanso/go-charts/v2"
 )
 
 // UsageAnalytics handles usage metrics visualization
@@ -18,7 +21,7 @@ func NewUsageAnalytics() *UsageAnalytics {
 }
 
 // MakeUsageChart creates a usage statistics chart
-func (ua *UsageAnalytics) MakeUsageChart(stats map[string]*storage.UsageStats, days int) ([]byte, error) {
+func (ua *UsageAnalytics) MakeUsageChart(ctx context.Context, stats map[string]*storage.UsageStats, days int) ([]byte, error) {
 	if len(stats) == 0 {
 		return nil, fmt.Errorf("no usage data available")
 	}
@@ -49,31 +52,46 @@ func (ua *UsageAnalytics) MakeUsageChart(stats map[string]*storage.UsageStats, d
 		pieLabels = append(pieLabels, fmt.Sprintf("%s (%.1f%%)", category, percentage))
 	}
 
-	p, err := charts.PieRender(
-		values,
-		charts.TitleTextOptionFunc(fmt.Sprintf("Command Usage Distribution (%d days)", days)),
-		charts.LegendOptionFunc(charts.LegendOption{
-			Data: pieLabels,
-			Top:  charts.PositionTop,
-		}),
-
-		charts.ThemeOptionFunc(charts.ThemeLight),
-		charts.WidthOptionFunc(800),
-		charts.HeightOptionFunc(600),
-	)
+	buf, err := renderChart(ctx, chartkit.Pie{
+		Title:  fmt.Sprintf("Command Usage Distribution (%d days)", days),
+		Values: values,
+		Legend: pieLabels,
+		Width:  800,
+		Height: 600,
+	}.Render)
 	if err != nil {
 		return nil, err
 	}
+	return buf, nil
+}
 
-	buf, err := p.Bytes()
-	if err != nil {
-		return nil, err
+// MakeUsageTimeSeriesChart creates a time series chart for usage analytics. When
+// prev holds the preceding period (bucketed by intervalHours), its total is
+// overlaid as a dashed line shifted forward by one period.
+func (ua *UsageAnalytics) MakeUsageTimeSeriesChart(ctx context.Context, series, prev map[string][]storage.TimeSeriesPoint, days, intervalHours int) ([]byte, error) {
+	// Sort categories for consistent ordering
+	var sortedCategories []string
+	for category := range series {
+		sortedCategories = append(sortedCategories, category)
 	}
-	return buf, nil
+	sort.Strings(sortedCategories)
+	return timeSeriesChart(ctx, fmt.Sprintf("Command Usage Over Time (%d days)", days), series, sortedCategories, prev, days, intervalHours)
+}
+
+// MakeActivityChart charts the chat's messages per bucket of intervalHours,
+// one line per series in the order of names.
+func (ua *UsageAnalytics) MakeActivityChart(ctx context.Context, series map[string][]storage.TimeSeriesPoint, names []string, days, intervalHours int) ([]byte, error) {
+	per := "day"
+	if intervalHours < 24 {
+		per = "hour"
+	}
+	return timeSeriesChart(ctx, fmt.Sprintf("Chat Activity, messages per %s (%d days)", per, days), series, names, nil, days, intervalHours)
 }
 
-// MakeUsageTimeSeriesChart creates a time series chart for usage analytics
-func (ua *UsageAnalytics) MakeUsageTimeSeriesChart(series map[string][]storage.TimeSeriesPoint, days int) ([]byte, error) {
+// timeSeriesChart draws series, bucketed by intervalHours, as one line per
+// name in names order, with prev's total dashed as MakeUsageTimeSeriesChart
+// describes.
+func timeSeriesChart(ctx context.Context, title string, series map[string][]storage.TimeSeriesPoint, names []string, prev map[string][]storage.TimeSeriesPoint, days, intervalHours int) ([]byte, error) {
 	if len(series) == 0 {
 		return nil, fmt.Errorf("no time series data available")
 	}
@@ -112,14 +130,7 @@ func (ua *UsageAnalytics) MakeUsageTimeSeriesChart(series map[string][]storage.T
 	var allSeries [][]float64
 	var seriesNames []string
 
-	// Sort categories for consistent ordering
-	var sortedCategories []string
-	for category := range series {
-		sortedCategories = append(sortedCategories, category)
-	}
-	sort.Strings(sortedCategories)
-
-	for _, category := range sortedCategories {
+	for _, category := range names {
 		points := series[category]
 
 		// Create a map for quick lookup
@@ -141,36 +152,46 @@ func (ua *UsageAnalytics) MakeUsageTimeSeriesChart(series map[string][]storage.T
 		allSeries = append(allSeries, data)
 		seriesNames = append(seriesNames, category)
 	}
-
-	// Create line chart
-	p, err := charts.LineRender(
-		allSeries,
-		charts.XAxisOptionFunc(charts.XAxisOption{
-			Data: xAxisData,
-		}),
-		charts.TitleTextOptionFunc(fmt.Sprintf("Command Usage Over Time (%d days)", days)),
-		charts.LegendOptionFunc(charts.LegendOption{
-			Data: seriesNames,
-			Top:  charts.PositionTop,
-		}),
-		charts.YAxisOptionFunc(charts.YAxisOption{}),
-		charts.ThemeOptionFunc(charts.ThemeLight),
-		charts.WidthOptionFunc(1000),
-		charts.HeightOptionFunc(600),
-	)
-	if err != nil {
-		return nil, err
+	seriesList := chartkit.Lines(allSeries...)
+
+	if len(prev) > 0 {
+		// Shift by whole buckets so previous-period buckets land on current ones
+		bucket := int64(intervalHours) * 3600
+		shift := int64(days) * 86400 / bucket * bucket
+		prevTotals := make(map[int64]int)
+		for _, points := range prev {
+			for _, point := range points {
+				prevTotals[point.Timestamp+shift] += point.Count
+			}
+		}
+		data := make([]float64, len(allTimestamps))
+		for i, ts := range allTimestamps {
+			data[i] = float64(prevTotals[ts])
+		}
+		seriesList = append(seriesList, chartkit.Series{Values: data, Dash: []float64{6, 4}})
+		seriesNames = append(seriesNames, "previous period (total)")
 	}
 
-	buf, err := p.Bytes()
+	// Create line chart
+	buf, err := renderChart(ctx, chartkit.Line{
+		Title:  title,
+		Series: seriesList,
+		X:      chartkit.XAxis{Labels: xAxisData, Gap: true},
+		Y:      []chartkit.YAxis{{}},
+		Legend: chartkit.Legend{Labels: seriesNames, Centered: true},
+		Width:  1000,
+		Height: 600,
+	}.Render)
 	if err != nil {
 		return nil, err
 	}
 	return buf, nil
 }
 
-// FormatUsageStatsText creates a formatted text summary of usage statistics
-func (ua *UsageAnalytics) FormatUsageStatsText(stats map[string]*storage.UsageStats, days int) string {
+// FormatUsageStatsText creates a formatted text summary of usage statistics.
+// A non-nil prev holds the preceding period of the same length; each category
+// then shows its change and a trend line compares the totals.
+func (ua *UsageAnalytics) FormatUsageStatsText(stats, prev map[string]*storage.UsageStats, days int) string {
 	if len(stats) == 0 {
 		return "No usage data available for the specified period."
 	}
@@ -183,16 +204,32 @@ func (ua *UsageAnalytics) FormatUsageStatsText(stats map[string]*storage.UsageSt
 		totalCommands += stats[category].Count
 	}
 	sort.Strings(sortedCategories)
+	prevTotal := 0
+	for _, stat := range prev {
+		prevTotal += stat.Count
+	}
 
 	text := fmt.Sprintf("📊 **Usage Analytics** (%d days)\n\n", days)
-	text += fmt.Sprintf("**Total Commands**: %d\n\n", totalCommands)
+	text += fmt.Sprintf("**Total Commands**: %d\n", totalCommands)
+	if prev != nil {
+		text += usageTrend(totalCommands, prevTotal, days) + "\n"
+	}
+	text += "\n"
 
 	for _, category := range sortedCategories {
 		stat := stats[category]
 		percentage := float64(stat.Count) / float64(totalCommands) * 100
 
-		text += fmt.Sprintf("**%s** (%d commands, %.1f%%)\n",
-			formatCategoryName(category), stat.Count, percentage)
+		delta := ""
+		if prev != nil {
+			before := 0
+			if p := prev[category]; p != nil {
+				before = p.Count
+			}
+			delta = ", " + usageDelta(stat.Count, before)
+		}
+		text += fmt.Sprintf("**%s** (%d commands, %.1f%%%s)\n",
+			formatCategoryName(category), stat.Count, percentage, delta)
 
 		// Sort commands within category
 		type cmdCount struct {
@@ -220,6 +257,109 @@ func (ua *UsageAnalytics) FormatUsageStatsText(stats map[string]*storage.UsageSt
 	return text
 }
 
+// FormatTopSymbols is the /usage section listing the most-charted tickers,
+// at most five; "" when no chart named one.
+func (ua *UsageAnalytics) FormatTopSymbols(top []storage.SymbolCount) string {
+	if len(top) == 0 {
+		return ""
+	}
+	text := "**Top Symbols**\n"
+	for i, s := range top {
+		if i >= 5 {
+			break
+		}
+		text += fmt.Sprintf("  %d. %s: %d\n", i+1, s.Symbol, s.Count)
+	}
+	return text + "\n"
+}
+
+// MakeTopSymbolsChart draws the most-charted tickers as horizontal bars, the
+// most frequent on top.
+func (ua *UsageAnalytics) MakeTopSymbolsChart(ctx context.Context, top []storage.SymbolCount, days int) ([]byte, error) {
+	if len(top) == 0 {
+		return nil, fmt.Errorf("no charted symbols")
+	}
+	labels := make([]string, len(top))
+	values := make([]float64, len(top))
+	for i, s := range top {
+		labels[i], values[i] = s.Symbol, float64(s.Count)
+	}
+	return renderChart(ctx, chartkit.Bar{
+		Title:  fmt.Sprintf("Most Charted Symbols (%d days)", days),
+		Labels: labels,
+		Values: values,
+		Width:  800,
+		Height: 150 + 35*len(top),
+	}.Render)
+}
+
+// FormatLatency is the /usage section with each category's median and p95
+// command duration in seconds; "" when no duration was recorded.
+func (ua *UsageAnalytics) FormatLatency(stats []storage.LatencyStats) string {
+	if len(stats) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString("**Latency** (seconds)\n```\n")
+	fmt.Fprintf(&b, "%-12s %5s %7s %7s\n", "category", "n", "median", "p95")
+	for _, s := range stats {
+		fmt.Fprintf(&b, "%-12s %5d %7.1f %7.1f\n", s.Category, s.Count, s.Median.Seconds(), s.P95.Seconds())
+	}
+	b.WriteString("```\n")
+	return b.String()
+}
+
+// MakeLatencyChart draws the daily median command duration, so a slow data
+// source or AI provider shows up as a step.
+func (ua *UsageAnalytics) MakeLatencyChart(ctx context.Context, points []storage.LatencyPoint, days int) ([]byte, error) {
+	if len(points) < 2 {
+		return nil, fmt.Errorf("not enough days with latency data")
+	}
+	labels := make([]string, len(points))
+	values := make([]float64, len(points))
+	for i, p := range points {
+		labels[i], values[i] = p.Day.Format("01/02"), p.Median.Seconds()
+	}
+	return renderChart(ctx, chartkit.Line{
+		Title:  fmt.Sprintf("Daily Median Latency, seconds (%d days)", days),
+		Series: chartkit.Lines(values),
+		X:      chartkit.XAxis{Labels: labels, Gap: true},
+		Y:      []chartkit.YAxis{{}},
+		Width:  1000,
+		Height: 500,
+	}.Render)
+}
+
+// usageDelta formats the change from before to now, e.g. "+31%". A category
+// with no usage before is "new".
+func usageDelta(now, before int) string {
+	if before == 0 {
+		if now == 0 {
+			return "±0%"
+		}
+		return "new"
+	}
+	pct := (float64(now)/float64(before) - 1) * 100
+	if math.Abs(pct) < 0.5 {
+		return "±0%"
+	}
+	return fmt.Sprintf("%+.0f%%", pct)
+}
+
+// usageTrend summarizes the total against the previous period of days.
+func usageTrend(now, before, days int) string {
+	if before == 0 {
+		return fmt.Sprintf("Trend: no usage in the previous %d days to compare", days)
+	}
+	arrow := "➡️ flat"
+	if now > before {
+		arrow = "📈 up"
+	} else if now < before {
+		arrow = "📉 down"
+	}
+	return fmt.Sprintf("Trend: %s %s vs %d in the previous %d days", arrow, usageDelta(now, before), before, days)
+}
+
 // formatCategoryName converts category names to user-friendly format
 func formatCategoryName(category string) string {
 	switch category {
@@ -235,3 +375,59 @@ func formatCategoryName(category string) string {
 		return category
 	}
 }
+
+// FormatWeeklyReport renders the cross-chat admin report for cur, with changes
+// against prev. names maps chat IDs to display names where known.
+func (ua *UsageAnalytics) FormatWeeklyReport(cur, prev storage.UsageReport, names map[int64]string) string {
+	var b strings.Builder
+	from := time.Unix(cur.Since, 0).UTC().Format("Jan 2")
+	to := time.Unix(cur.Until-1, 0).UTC().Format("Jan 2")
+	fmt.Fprintf(&b, "📊 Weekly usage report (%s – %s UTC)\n\n", from, to)
+	fmt.Fprintf(&b, "Total commands: %d (%s)\n", cur.Total, usageDelta(cur.Total, prev.Total))
+	fmt.Fprintf(&b, "Active chats: %d (%s)\n", cur.ActiveChats, usageDelta(cur.ActiveChats, prev.ActiveChats))
+	if len(cur.Bots) > 1 {
+		bots := make([]string, 0, len(cur.Bots))
+		for bot := range cur.Bots {
+			bots = append(bots, bot)
+		}
+		sort.Strings(bots)
+		parts := make([]string, len(bots))
+		for i, bot := range bots {
+			name := bot
+			if name == "" {
+				name = "primary"
+			}
+			parts[i] = fmt.Sprintf("%s %d", name, cur.Bots[bot])
+		}
+		fmt.Fprintf(&b, "By bot: %s\n", strings.Join(parts, ", "))
+	}
+
+	if len(cur.Categories) > 0 {
+		categories := make([]string, 0, len(cur.Categories))
+		for c := range cur.Categories {
+			categories = append(categories, c)
+		}
+		sort.Slice(categories, func(i, j int) bool {
+			if cur.Categories[categories[i]] != cur.Categories[categories[j]] {
+				return cur.Categories[categories[i]] > cur.Categories[categories[j]]
+			}
+			return categories[i] < categories[j]
+		})
+		b.WriteString("\nTop categories:\n")
+		for _, c := range categories {
+			fmt.Fprintf(&b, "  • %s: %d (%s)\n", formatCategoryName(c), cur.Categories[c], usageDelta(cur.Categories[c], prev.Categories[c]))
+		}
+	}
+	if len(cur.TopChats) > 0 {
+		b.WriteString("\nTop chats:\n")
+		for i, c := range cur.TopChats {
+			name := names[c.ChatID]
+			if name == "" {
+				name = strconv.FormatInt(c.ChatID, 10)
+			}
+			fmt.Fprintf(&b, "  %d. %s: %d\n", i+1, name, c.Count)
+		}
+	}
+	b.WriteString("\nError rate: not tracked yet\nOpenAI spend: not tracked yet\n")
+	return b.String()
+}
@@ -0,0 +1,150 @@
+package finance
+
+import (
+	"strings"
+	"testing"
+
+	"telegramBotTrade/internal/storage"
+)
+
+func TestUsageDelta(t *testing.T) {
+	tests := []struct {
+		now, before int
+		want        string
+	}{
+		{0, 0, "±0%"},
+		{5, 0, "new"},
+		{0, 5, "-100%"},
+		{131, 100, "+31%"},
+		{50, 100, "-50%"},
+		{200, 201, "±0%"}, // -0.5% rounds to no change
+		{100, 100, "±0%"},
+	}
+	for _, tc := range tests {
+		if got := usageDelta(tc.now, tc.before); got != tc.want {
+			t.Errorf("usageDelta(%d, %d) = %q, want %q", tc.now, tc.before, got, tc.want)
+		}
+	}
+}
+
+func TestUsageTrend(t *testing.T) {
+	tests := []struct {
+		now, before int
+		want        string
+	}{
+		{10, 0, "Trend: no usage in the previous 7 days to compare"},
+		{12, 10, "Trend: 📈 up +20% vs 10 in the previous 7 days"},
+		{5, 10, "Trend: 📉 down -50% vs 10 in the previous 7 days"},
+		{10, 10, "Trend: ➡️ flat ±0% vs 10 in the previous 7 days"},
+	}
+	for _, tc := range tests {
+		if got := usageTrend(tc.now, tc.before, 7); got != tc.want {
+			t.Errorf("usageTrend(%d, %d) = %q, want %q", tc.now, tc.before, got, tc.want)
+		}
+	}
+}
+
+func TestFormatUsageStatsTextComparison(t *testing.T) {
+	ua := NewUsageAnalytics()
+	stats := map[string]*storage.UsageStats{
+		"charts":     {Category: "charts", Count: 30, Commands: map[string]int{"/chart": 30}},
+		"summarizer": {Category: "summarizer", Count: 10, Commands: map[string]int{"/summary": 10}},
+	}
+	tests := []struct {
+		name string
+		prev map[string]*storage.UsageStats
+		want []string
+		not  []string
+	}{
+		{
+			name: "no comparison",
+			prev: nil,
+			want: []string{"**Total Commands**: 40\n", "(30 commands, 75.0%)", "(10 commands, 25.0%)"},
+			not:  []string{"Trend:", "new"},
+		},
+		{
+			name: "first week",
+			prev: map[string]*storage.UsageStats{},
+			want: []string{"Trend: no usage in the previous 7 days to compare", "(30 commands, 75.0%, new)", "(10 commands, 25.0%, new)"},
+		},
+		{
+			name: "week over week",
+			prev: map[string]*storage.UsageStats{
+				"charts":    {Category: "charts", Count: 20},
+				"portfolio": {Category: "portfolio", Count: 5},
+			},
+			want: []string{"Trend: 📈 up +60% vs 25 in the previous 7 days", "(30 commands, 75.0%, +50%)", "(10 commands, 25.0%, new)"},
+			not:  []string{"Portfolio"},
+		},
+	}
+	for _, tc := range tests {
+		got := ua.FormatUsageStatsText(stats, tc.prev, 7)
+		for _, w := range tc.want {
+			if !strings.Contains(got, w) {
+				t.Errorf("%s: missing %q in\n%s", tc.name, w, got)
+			}
+		}
+		for _, n := range tc.not {
+			if strings.Contains(got, n) {
+				t.Errorf("%s: unexpected %q in\n%s", tc.name, n, got)
+			}
+		}
+	}
+}
+
+func TestFormatWeeklyReport(t *testing.T) {
+	const monday = 1717372800 // 2024-06-03 00:00 UTC
+	cur := storage.UsageReport{
+		Since:       monday,
+		Until:       monday + 7*86400,
+		Total:       60,
+		ActiveChats: 3,
+		Categories:  map[string]int{"charts": 40, "summarizer": 10, "portfolio": 10},
+		Bots:        map[string]int{"": 50, "beta": 10},
+		TopChats:    []storage.ChatCount{{ChatID: -100, Count: 45}, {ChatID: 42, Count: 15}},
+	}
+	prev := storage.UsageReport{
+		Total:       40,
+		ActiveChats: 3,
+		Categories:  map[string]int{"charts": 40},
+	}
+	got := NewUsageAnalytics().FormatWeeklyReport(cur, prev, map[int64]string{-100: "Traders"})
+	want := `📊 Weekly usage report (Jun 3 – Jun 9 UTC)
+
+Total commands: 60 (+50%)
+Active chats: 3 (±0%)
+By bot: primary 50, beta 10
+
+Top categories:
+  • 📈 Stock Charts: 40 (±0%)
+  • 💼 Portfolio Analysis: 10 (new)
+  • 📝 Chat Summaries: 10 (new)
+
+Top chats:
+  1. Traders: 45
+  2. 42: 15
+
+Error rate: not tracked yet
+OpenAI spend: not tracked yet
+`
+	if got != want {
+		t.Errorf("FormatWeeklyReport =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestFormatWeeklyReportFirstWeek(t *testing.T) {
+	cur := storage.UsageReport{Since: 0, Until: 7 * 86400, Total: 5, ActiveChats: 1,
+		Categories: map[string]int{"charts": 5}, Bots: map[string]int{"": 5}}
+	got := NewUsageAnalytics().FormatWeeklyReport(cur, storage.UsageReport{}, nil)
+	for _, w := range []string{"Total commands: 5 (new)", "Active chats: 1 (new)", "📈 Stock Charts: 5 (new)"} {
+		if !strings.Contains(got, w) {
+			t.Errorf("missing %q in\n%s", w, got)
+		}
+	}
+	// a single bot isn't broken down, and no chats means no chat list
+	for _, n := range []string{"By bot", "Top chats"} {
+		if strings.Contains(got, n) {
+			t.Errorf("unexpected %q in\n%s", n, got)
+		}
+	}
+}
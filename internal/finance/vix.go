@@ -0,0 +1,153 @@
+package finance
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"telegramBotTrade/internal/chartkit"
+)
+
+// VIXSummary holds the latest volatility index levels shown with /vix.
+type VIXSummary struct {
+	Level      float64 // latest ^VIX close
+	Percentile float64 // share of the past year's closes at or below Level, 0-100
+	VIX9D      float64
+	VIX3M      float64
+	Inverted   bool // VIX above VIX3M: near-term fear priced over the 3-month
+}
+
+// MakeVIXChart renders ^VIX over window on top and ^VIX9D/^VIX/^VIX3M on
+// shared dates underneath, so term-structure inversions show as crossings.
+func MakeVIXChart(ctx context.Context, window string, opts RenderOptions) ([]byte, *VIXSummary, error) {
+	if strings.TrimSpace(window) == "" {
+		window = "6m"
+	}
+	_, rng, _ := normalizeIntervalWindow("1d", window)
+	loc := opts.location()
+
+	ts, vix, err := fetchSeries(ctx, "^VIX", "1d", rng)
+	if err != nil {
+		return nil, nil, fmt.Errorf("^VIX: %w", err)
+	}
+	if len(vix) < 2 {
+		return nil, nil, errors.New("not enough ^VIX data points")
+	}
+	yearCl := vix
+	if rng != "1y" {
+		if _, yearCl, err = fetchSeries(ctx, "^VIX", "1d", "1y"); err != nil {
+			return nil, nil, fmt.Errorf("^VIX 1y: %w", err)
+		}
+	}
+	sum := &VIXSummary{Level: vix[len(vix)-1]}
+	sum.Percentile = percentileRank(yearCl, sum.Level)
+
+	x := make([]string, len(ts))
+	for i := range ts {
+		x[i] = time.Unix(ts[i], 0).In(loc).Format("2006-01-02")
+	}
+	yMin, yMax := paddedRange(vix)
+	topImg, err := renderChart(ctx, chartkit.Line{
+		Style:  chartkit.Style{Theme: opts.theme()},
+		Title:  fmt.Sprintf("^VIX • %s • %.2f (%.0fth pct of 1Y)", strings.ToUpper(rng), sum.Level, sum.Percentile),
+		Series: chartkit.Lines(vix),
+		X:      chartkit.XAxis{Labels: x, Split: 10},
+		Y:      []chartkit.YAxis{{Min: &yMin, Max: &yMax, Divide: 5}},
+	}.Render)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to render chart: %w", err)
+	}
+
+	// Term structure: keep only dates all three indices traded.
+	byDay := func(symbol string) (map[string]float64, error) {
+		ts, cl, err := fetchSeries(ctx, symbol, "1d", rng)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", symbol, err)
+		}
+		out := make(map[string]float64, len(ts))
+		for i := range ts {
+			out[time.Unix(ts[i], 0).In(loc).Format("2006-01-02")] = cl[i]
+		}
+		return out, nil
+	}
+	short, err := byDay("^VIX9D")
+	if err != nil {
+		return nil, nil, err
+	}
+	long, err := byDay("^VIX3M")
+	if err != nil {
+		return nil, nil, err
+	}
+	var days []string
+	series := make([][]float64, 3)
+	for i, d := range x {
+		s, ok1 := short[d]
+		l, ok2 := long[d]
+		if !ok1 || !ok2 {
+			continue
+		}
+		days = append(days, d)
+		series[0] = append(series[0], s)
+		series[1] = append(series[1], vix[i])
+		series[2] = append(series[2], l)
+	}
+	if len(days) < 2 {
+		return nil, nil, errors.New("not enough overlapping term-structure data")
+	}
+	n := len(days) - 1
+	sum.VIX9D, sum.VIX3M = series[0][n], series[2][n]
+	sum.Inverted = series[1][n] > sum.VIX3M
+
+	tMin, tMax := paddedRange(append(append(append([]float64{}, series[0]...), series[1]...), series[2]...))
+	state := "contango"
+	if sum.Inverted {
+		state = "INVERTED"
+	}
+	bottomImg, err := renderChart(ctx, chartkit.Line{
+		Style:  chartkit.Style{Theme: opts.theme()},
+		Title:  fmt.Sprintf("Term structure • VIX/VIX3M %.2f (%s)", series[1][n]/sum.VIX3M, state),
+		Series: chartkit.Lines(series...),
+		Legend: chartkit.Legend{Labels: []string{"VIX9D", "VIX", "VIX3M"}},
+		X:      chartkit.XAxis{Labels: days, Split: 10},
+		Y:      []chartkit.YAxis{{Min: &tMin, Max: &tMax, Divide: 5}},
+	}.Render)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to render chart: %w", err)
+	}
+	img, err := chartkit.Stack(topImg, bottomImg)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to stack panels: %w", err)
+	}
+	return img, sum, nil
+}
+
+// percentileRank returns the percentage of xs at or below v.
+func percentileRank(xs []float64, v float64) float64 {
+	if len(xs) == 0 {
+		return 0
+	}
+	n := 0
+	for _, x := range xs {
+		if x <= v {
+			n++
+		}
+	}
+	return float64(n) / float64(len(xs)) * 100
+}
+
+// paddedRange returns the min and max of xs widened by 5% for axis bounds.
+func paddedRange(xs []float64) (float64, float64) {
+	lo, hi := xs[0], xs[0]
+	for _, x := range xs {
+		lo = math.Min(lo, x)
+		hi = math.Max(hi, x)
+	}
+	pad := (hi - lo) * 0.05
+	if pad == 0 {
+		pad = hi * 0.05
+	}
+	return math.Max(0, lo-pad), hi + pad
+}
@@ -0,0 +1,55 @@
+package finance
+
+import (
+	"fmt"
+
+	"telegramBotTrade/internal/chartkit"
+)
+
+// sessionVWAP returns the running volume-weighted average of the closes,
+// restarting at each exchange-local day. Bars before a session's first traded
+// volume are null. Only closes are fetched, so the close stands in for the
+// usual (high+low+close)/3 typical price.
+func sessionVWAP(b bars) (vwap []float64, sessions, empty int) {
+	vwap = make([]float64, len(b.ts))
+	var pv, vol float64
+	day := int64(-1)
+	sessionVol := map[int64]float64{}
+	for i, t := range b.ts {
+		d := (t + int64(b.gmtOffset)) / 86400
+		if d != day {
+			day, pv, vol = d, 0, 0
+			sessionVol[d] = 0
+		}
+		pv += b.close[i] * b.volume[i]
+		vol += b.volume[i]
+		sessionVol[d] += b.volume[i]
+		if vol > 0 {
+			vwap[i] = pv / vol
+		} else {
+			vwap[i] = chartkit.NullValue()
+		}
+	}
+	for _, v := range sessionVol {
+		if v == 0 {
+			empty++
+		}
+	}
+	return vwap, len(sessionVol), empty
+}
+
+// vwapOverlay returns the VWAP series to draw next to b's closes, or nil with
+// a caption note when there is no volume to weight by.
+func vwapOverlay(b bars) ([]float64, string) {
+	if b.volume == nil {
+		return nil, "VWAP omitted: no volume data from the fallback source"
+	}
+	vwap, sessions, empty := sessionVWAP(b)
+	switch {
+	case empty == sessions:
+		return nil, "VWAP omitted: no volume recorded for this symbol"
+	case empty > 0:
+		return vwap, fmt.Sprintf("VWAP omitted for %d of %d sessions without volume", empty, sessions)
+	}
+	return vwap, ""
+}
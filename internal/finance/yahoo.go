@@ -0,0 +1,133 @@
+package finance
+
+import (
+	"context"
+	"log/slog"
+	"math/rand/v2"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	defaultYahooMinGap      = 150 * time.Millisecond
+	defaultYahooRetryJitter = 250 * time.Millisecond
+	// yahooStatsEvery is how often the request/429 summary is logged.
+	yahooStatsEvery = 10 * time.Minute
+)
+
+// yahooUserAgents are current desktop browser strings; one is picked per
+// request so traffic from one IP doesn't all carry the same fingerprint.
+var yahooUserAgents = []string{
+	"Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.4 Safari/605.1.15",
+	"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.0.0 Safari/537.36",
+	"Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.0.0 Safari/537.36",
+	"Mozilla/5.0 (Windows NT 10.0; Win64; x64; rv:125.0) Gecko/20100101 Firefox/125.0",
+	"Mozilla/5.0 (X11; Linux x86_64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.0.0 Safari/537.36",
+	"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.0.0 Safari/537.36 Edg/124.0.0.0",
+}
+
+var (
+	yahooUANext      atomic.Uint32
+	yahooRetryJitter atomic.Int64 // nanoseconds
+)
+
+func init() { yahooRetryJitter.Store(int64(defaultYahooRetryJitter)) }
+
+// SetYahooPacing sets the minimum gap between any two Yahoo requests and the
+// maximum random jitter added to retry backoffs. Non-positive values keep the
+// defaults.
+func SetYahooPacing(minGap, retryJitter time.Duration) {
+	if minGap > 0 {
+		yahooLimiter.setInterval(minGap)
+	}
+	if retryJitter > 0 {
+		yahooRetryJitter.Store(int64(retryJitter))
+	}
+}
+
+// yahooUserAgent rotates through yahooUserAgents.
+func yahooUserAgent() string {
+	return yahooUserAgents[int(yahooUANext.Add(1))%len(yahooUserAgents)]
+}
+
+// withJitter adds up to the configured retry jitter to a backoff so callers
+// that failed together don't retry in lockstep.
+func withJitter(d time.Duration) time.Duration {
+	if j := yahooRetryJitter.Load(); j > 0 {
+		d += time.Duration(rand.Int64N(j))
+	}
+	return d
+}
+
+// sleepCtx waits d, returning early with ctx's error when it is done first.
+func sleepCtx(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+// yahooDo waits for the global Yahoo pacing, sends req with a rotated
+// User-Agent and records the outcome for the periodic 429 summary. Every
+// Yahoo request, retries and the crumb handshake included, goes through here.
+func yahooDo(client *http.Client, req *http.Request) (*http.Response, error) {
+	if err := yahooLimiter.wait(req.Context()); err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", yahooUserAgent())
+	resp, err := client.Do(req)
+	yahooStats.record(err == nil && resp.StatusCode == http.StatusTooManyRequests)
+	return resp, err
+}
+
+// yahooStats counts Yahoo requests and 429s and logs the rate every
+// yahooStatsEvery, so pacing changes can be judged from the logs.
+var yahooStats = &requestStats{since: time.Now()}
+
+type requestStats struct {
+	mu          sync.Mutex
+	since       time.Time
+	requests    int
+	rateLimited int
+}
+
+// YahooStats are the Yahoo requests of the current stats window, which
+// restarts every yahooStatsEvery.
+type YahooStats struct {
+	Since       time.Time
+	Requests    int
+	RateLimited int // answered with 429
+}
+
+// CurrentYahooStats reports the Yahoo requests since the window started.
+func CurrentYahooStats() YahooStats {
+	yahooStats.mu.Lock()
+	defer yahooStats.mu.Unlock()
+	return YahooStats{Since: yahooStats.since, Requests: yahooStats.requests, RateLimited: yahooStats.rateLimited}
+}
+
+func (s *requestStats) record(rateLimited bool) {
+	s.mu.Lock()
+	s.requests++
+	if rateLimited {
+		s.rateLimited++
+	}
+	if time.Since(s.since) < yahooStatsEvery {
+		s.mu.Unlock()
+		return
+	}
+	n, limited, since := s.requests, s.rateLimited, s.since
+	s.requests, s.rateLimited, s.since = 0, 0, time.Now()
+	s.mu.Unlock()
+	slog.Info("yahoo: request summary",
+		"window", time.Since(since).Round(time.Second).String(),
+		"requests", n,
+		"rate_limited", limited,
+		"rate_limited_pct", float64(limited)/float64(n)*100)
+}
@@ -0,0 +1,70 @@
+package finance
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestYahooDoPacing(t *testing.T) {
+	const gap = 40 * time.Millisecond
+	SetYahooPacing(gap, 0)
+	t.Cleanup(func() { SetYahooPacing(defaultYahooMinGap, 0) })
+
+	var agents []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		agents = append(agents, r.UserAgent())
+	}))
+	t.Cleanup(srv.Close)
+
+	yahooLimiter.wait(context.Background()) // start from an empty bucket
+	start := time.Now()
+	for range 4 {
+		req, _ := http.NewRequest("GET", srv.URL, nil)
+		resp, err := yahooDo(srv.Client(), req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+	}
+	if elapsed := time.Since(start); elapsed < 4*gap-5*time.Millisecond {
+		t.Errorf("4 requests took %v, want at least %v apart", elapsed, gap)
+	}
+	if agents[0] == agents[1] {
+		t.Errorf("User-Agent not rotated: %q twice", agents[0])
+	}
+}
+
+func TestYahooDoCancelledWhileWaiting(t *testing.T) {
+	yahooLimiter.wait(context.Background()) // take the token at the default pace
+	SetYahooPacing(time.Hour, 0)
+	t.Cleanup(func() { SetYahooPacing(defaultYahooMinGap, 0) })
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, "GET", "http://127.0.0.1:0", nil)
+	start := time.Now()
+	if _, err := yahooDo(http.DefaultClient, req); err != context.DeadlineExceeded {
+		t.Fatalf("err = %v, want context.DeadlineExceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("yahooDo returned after %v, want soon after the deadline", elapsed)
+	}
+}
+
+func TestSleepCtx(t *testing.T) {
+	if err := sleepCtx(context.Background(), time.Millisecond); err != nil {
+		t.Errorf("sleepCtx = %v, want nil", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	start := time.Now()
+	if err := sleepCtx(ctx, time.Hour); err != context.Canceled {
+		t.Errorf("sleepCtx = %v, want context.Canceled", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("cancelled sleepCtx took %v", elapsed)
+	}
+}
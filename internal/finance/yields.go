@@ -0,0 +1,185 @@
+package finance
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"telegramBotTrade/internal/chartkit"
+)
+
+// Treasury is a CBOE US Treasury yield index as Yahoo lists it.
+type Treasury struct {
+	Symbol string
+	Label  string // maturity, e.g. 10Y
+}
+
+// Treasuries are the yields /yield and /curve show, shortest maturity first.
+var Treasuries = []Treasury{
+	{Symbol: "^IRX", Label: "13W"},
+	{Symbol: "^FVX", Label: "5Y"},
+	{Symbol: "^TNX", Label: "10Y"},
+	{Symbol: "^TYX", Label: "30Y"},
+}
+
+// yieldScaleCutoff is the quote above which a yield must be in percent×10,
+// as Yahoo has quoted ^TNX, ^FVX and ^TYX (42.5 for 4.25%). No Treasury
+// yield has come near 20%.
+const yieldScaleCutoff = 20
+
+// YieldSummary holds the latest yields in percent, in Treasuries order.
+type YieldSummary struct {
+	Yields []float64
+	// Inversions lists each pair whose shorter maturity yields more than the
+	// longer one, e.g. "13W > 10Y"; empty for a normal curve.
+	Inversions []string
+}
+
+// normalizeYields converts a yield series to percent. The latest quote sets
+// the scale, so a series quoted ×10 is scaled whole even where its yields
+// were below 2% and the quotes below the cutoff; quotes still above the
+// cutoff after that are ×10 whatever the latest says, covering a history
+// whose quoting changed partway.
+func normalizeYields(xs []float64) []float64 {
+	out := make([]float64, len(xs))
+	scale := 1.0
+	if len(xs) > 0 && xs[len(xs)-1] > yieldScaleCutoff {
+		scale = 0.1
+	}
+	for i, v := range xs {
+		v *= scale
+		if v > yieldScaleCutoff {
+			v /= 10
+		}
+		out[i] = v
+	}
+	return out
+}
+
+// yieldSummary reports the latest yields and the inverted pairs among them.
+func yieldSummary(latest []float64) *YieldSummary {
+	sum := &YieldSummary{Yields: latest}
+	for i := range latest {
+		for j := i + 1; j < len(latest); j++ {
+			if latest[i] > latest[j] {
+				sum.Inversions = append(sum.Inversions, Treasuries[i].Label+" > "+Treasuries[j].Label)
+			}
+		}
+	}
+	return sum
+}
+
+// MakeYieldChart renders the Treasuries' daily yields over window on shared
+// dates, in percent.
+func MakeYieldChart(ctx context.Context, window string, opts RenderOptions) ([]byte, *YieldSummary, error) {
+	if strings.TrimSpace(window) == "" {
+		window = "1y"
+	}
+	_, rng, _ := normalizeIntervalWindow("1d", window)
+	loc := opts.location()
+
+	// keep only dates every maturity traded
+	byDay := make([]map[string]float64, len(Treasuries))
+	var days []string
+	for k, t := range Treasuries {
+		ts, cl, err := fetchSeries(ctx, t.Symbol, "1d", rng)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s: %w", t.Symbol, err)
+		}
+		cl = normalizeYields(cl)
+		byDay[k] = make(map[string]float64, len(ts))
+		for i := range ts {
+			d := time.Unix(ts[i], 0).In(loc).Format("2006-01-02")
+			byDay[k][d] = cl[i]
+			if k == 0 {
+				days = append(days, d)
+			}
+		}
+	}
+	series := make([][]float64, len(Treasuries))
+	var x []string
+	for _, d := range days {
+		row := make([]float64, len(Treasuries))
+		ok := true
+		for k := range Treasuries {
+			if row[k], ok = byDay[k][d]; !ok {
+				break
+			}
+		}
+		if !ok {
+			continue
+		}
+		x = append(x, d)
+		for k := range series {
+			series[k] = append(series[k], row[k])
+		}
+	}
+	if len(x) < 2 {
+		return nil, nil, noData("not enough overlapping yield data")
+	}
+	n := len(x) - 1
+	latest := make([]float64, len(series))
+	labels := make([]string, len(series))
+	var all []float64
+	for k, s := range series {
+		latest[k] = s[n]
+		labels[k] = Treasuries[k].Label
+		all = append(all, s...)
+	}
+	sum := yieldSummary(latest)
+
+	yMin, yMax := paddedRange(all)
+	img, err := renderChart(ctx, chartkit.Line{
+		Style:  opts.style(),
+		Title:  "US Treasury yields (%) • " + strings.ToUpper(rng),
+		Series: chartkit.Lines(series...),
+		Legend: chartkit.Legend{Labels: labels},
+		X:      chartkit.XAxis{Labels: x, Split: 10},
+		Y:      []chartkit.YAxis{{Min: &yMin, Max: &yMax, Divide: 5}},
+	}.Render)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to render chart: %w", err)
+	}
+	return img, sum, nil
+}
+
+// MakeCurveChart renders the latest Treasury yields across maturities, each
+// point labeled with its yield. Maturities are evenly spaced, not to scale.
+func MakeCurveChart(ctx context.Context, opts RenderOptions) ([]byte, *YieldSummary, error) {
+	latest := make([]float64, len(Treasuries))
+	labels := make([]string, len(Treasuries))
+	marks := make([]chartkit.Mark, len(Treasuries))
+	for k, t := range Treasuries {
+		_, cl, err := fetchSeries(ctx, t.Symbol, "1d", "5d")
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s: %w", t.Symbol, err)
+		}
+		if len(cl) == 0 {
+			return nil, nil, noData("no " + t.Symbol + " data")
+		}
+		cl = normalizeYields(cl)
+		latest[k] = cl[len(cl)-1]
+		labels[k] = t.Label
+		marks[k] = chartkit.Mark{Index: k, Text: fmt.Sprintf("%.2f%%", latest[k])}
+	}
+	sum := yieldSummary(latest)
+
+	title := "US Treasury curve (%)"
+	if len(sum.Inversions) > 0 {
+		title += " • INVERTED"
+	}
+	yMin, yMax := paddedRange(latest)
+	img, err := renderChart(ctx, chartkit.Line{
+		Style:  opts.style(),
+		Title:  title,
+		Series: chartkit.Lines(latest),
+		X:      chartkit.XAxis{Labels: labels, Gap: true},
+		Y:      []chartkit.YAxis{{Min: &yMin, Max: &yMax, Divide: 5}},
+		Marks:  marks,
+	}.Render)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to render chart: %w", err)
+	}
+	return img, sum, nil
+}
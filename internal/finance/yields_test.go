@@ -0,0 +1,82 @@
+package finance
+
+import (
+	"context"
+	"slices"
+	"testing"
+)
+
+func TestNormalizeYields(t *testing.T) {
+	tests := []struct {
+		name string
+		in   []float64
+		want []float64
+	}{
+		{"percent", []float64{4.1, 4.25, 4.3}, []float64{4.1, 4.25, 4.3}},
+		{"percent×10", []float64{41, 42.5, 43}, []float64{4.1, 4.25, 4.3}},
+		// the latest quote scales the whole series, low yields included
+		{"percent×10 below the cutoff", []float64{1.5, 9, 25}, []float64{0.15, 0.9, 2.5}},
+		// quoting switched to percent: the old ×10 quotes are still scaled
+		{"switched to percent", []float64{42, 43, 4.4, 4.5}, []float64{4.2, 4.3, 4.4, 4.5}},
+		{"near zero", []float64{0.02, 0.05}, []float64{0.02, 0.05}},
+		{"empty", nil, []float64{}},
+	}
+	for _, tc := range tests {
+		got := normalizeYields(tc.in)
+		if len(got) != len(tc.want) {
+			t.Errorf("%s: normalizeYields(%v) = %v, want %v", tc.name, tc.in, got, tc.want)
+			continue
+		}
+		for i := range got {
+			if !closeTo(got[i], tc.want[i], 1e-9) {
+				t.Errorf("%s: normalizeYields(%v) = %v, want %v", tc.name, tc.in, got, tc.want)
+				break
+			}
+		}
+	}
+}
+
+func TestYieldSummary(t *testing.T) {
+	tests := []struct {
+		latest []float64
+		want   []string
+	}{
+		{[]float64{4.0, 4.2, 4.4, 4.6}, nil},
+		{[]float64{5.2, 4.2, 4.3, 4.5}, []string{"13W > 5Y", "13W > 10Y", "13W > 30Y"}},
+		{[]float64{4.0, 4.5, 4.3, 4.6}, []string{"5Y > 10Y"}},
+		{[]float64{4.0, 4.0, 4.0, 4.0}, nil}, // a flat curve isn't inverted
+	}
+	for _, tc := range tests {
+		if got := yieldSummary(tc.latest).Inversions; !slices.Equal(got, tc.want) {
+			t.Errorf("yieldSummary(%v).Inversions = %q, want %q", tc.latest, got, tc.want)
+		}
+	}
+}
+
+func TestMakeCurveChartScales(t *testing.T) {
+	src := &fixtureSource{
+		series: map[string]Series{
+			"^IRX": dailyCloses(5.2, 5.2, 5.2),
+			"^FVX": dailyCloses(42, 42, 42), // quoted ×10
+			"^TNX": dailyCloses(43, 43, 43),
+			"^TYX": dailyCloses(4.5, 4.5, 4.5),
+		},
+		ranges: map[string][]string{},
+	}
+	img, sum, err := MakeCurveChart(WithSeriesSource(context.Background(), src), RenderOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(img) == 0 {
+		t.Error("empty chart")
+	}
+	want := []float64{5.2, 4.2, 4.3, 4.5}
+	for i := range want {
+		if !closeTo(sum.Yields[i], want[i], 1e-9) {
+			t.Fatalf("Yields = %v, want %v", sum.Yields, want)
+		}
+	}
+	if len(sum.Inversions) != 3 {
+		t.Errorf("Inversions = %q, want the 13W above every other maturity", sum.Inversions)
+	}
+}
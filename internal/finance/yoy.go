@@ -0,0 +1,105 @@
+package finance
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"telegramBotTrade/internal/chartkit"
+)
+
+// MaxYoYYears caps how many past years /yoy overlays.
+const MaxYoYYears = 9
+
+// MakeYoYChart overlays the current year-to-date path of symbol with the
+// previous years' paths, each indexed to 100 at its first trading day and
+// aligned by trading-day number. The current year is listed first.
+func MakeYoYChart(ctx context.Context, symbol string, years int, opts RenderOptions) ([]byte, error) {
+	if years < 1 || years > MaxYoYYears {
+		return nil, fmt.Errorf("years must be between 1 and %d", MaxYoYYears)
+	}
+	rng := "10y"
+	switch {
+	case years == 1:
+		rng = "2y"
+	case years <= 4:
+		rng = "5y"
+	}
+	b, err := fetchBars(ctx, symbol, "1d", rng)
+	if err != nil {
+		return nil, err
+	}
+
+	// Split by calendar year in exchange-local time.
+	byYear := map[int][]float64{}
+	firstMonth := map[int]time.Month{}
+	for i, t := range b.ts {
+		local := time.Unix(t+int64(b.gmtOffset), 0).UTC()
+		y := local.Year()
+		if _, ok := byYear[y]; !ok {
+			firstMonth[y] = local.Month()
+		}
+		byYear[y] = append(byYear[y], b.close[i])
+	}
+	current := time.Now().In(opts.location()).Year()
+	if len(byYear[current]) < 2 {
+		return nil, noData("not enough data for the current year yet")
+	}
+
+	var series [][]float64
+	var labels []string
+	longest := 0
+	for y := current; y >= current-years; y-- {
+		cl := byYear[y]
+		// The oldest fetched year usually starts mid-year; skip partial years.
+		if len(cl) < 2 || cl[0] <= 0 || (y != current && firstMonth[y] != time.January) {
+			continue
+		}
+		indexed := make([]float64, len(cl))
+		for i, v := range cl {
+			indexed[i] = v / cl[0] * 100
+		}
+		series = append(series, indexed)
+		labels = append(labels, strconv.Itoa(y))
+		longest = max(longest, len(indexed))
+	}
+	if len(series) < 2 {
+		return nil, errors.New("not enough history to compare years")
+	}
+
+	yMin, yMax := math.Inf(1), math.Inf(-1)
+	for i, s := range series {
+		for _, v := range s {
+			yMin = math.Min(yMin, v)
+			yMax = math.Max(yMax, v)
+		}
+		// Shorter years (including the current one) end early.
+		for len(s) < longest {
+			s = append(s, chartkit.NullValue())
+		}
+		series[i] = s
+	}
+	pad := (yMax - yMin) * 0.05
+	yMin, yMax = yMin-pad, yMax+pad
+
+	x := make([]string, longest)
+	for i := range x {
+		x[i] = strconv.Itoa(i + 1)
+	}
+	img, err := renderChart(ctx, chartkit.Line{
+		Style:  opts.style(),
+		Title:  fmt.Sprintf("%s • %d YTD vs previous years (Jan start = 100)", strings.ToUpper(symbol), current),
+		Series: chartkit.Lines(series...),
+		Legend: chartkit.Legend{Labels: labels},
+		X:      chartkit.XAxis{Labels: x, Split: 12},
+		Y:      []chartkit.YAxis{{Min: &yMin, Max: &yMax, Divide: 5}},
+	}.Render)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render chart: %w", err)
+	}
+	return img, nil
+}
@@ -0,0 +1,79 @@
+package logging
+
+import (
+	"context"
+	"log/slog"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// LoggedError is the latest error logged by one subsystem.
+type LoggedError struct {
+	Subsystem string // the message's "subsystem:" prefix, else its first word
+	Message   string
+	Err       string // the "err" attribute, redacted; "" when absent
+	Time      time.Time
+}
+
+var (
+	lastErrorsMu sync.Mutex
+	lastErrors   = map[string]LoggedError{}
+)
+
+// LastErrors returns the latest error-level log of each subsystem since the
+// process started, by subsystem name.
+func LastErrors() []LoggedError {
+	lastErrorsMu.Lock()
+	out := make([]LoggedError, 0, len(lastErrors))
+	for _, e := range lastErrors {
+		out = append(out, e)
+	}
+	lastErrorsMu.Unlock()
+	sort.Slice(out, func(i, j int) bool { return out[i].Subsystem < out[j].Subsystem })
+	return out
+}
+
+// subsystem names the part of the bot a log message came from: "openai" for
+// "openai: merge summary failed", "summary" for "summary failed".
+func subsystem(msg string) string {
+	if prefix, _, ok := strings.Cut(msg, ":"); ok && prefix != "" && !strings.ContainsAny(prefix, " \t") {
+		return prefix
+	}
+	if f := strings.Fields(msg); len(f) > 0 {
+		return f[0]
+	}
+	return "unknown"
+}
+
+// lastErrorHandler remembers the latest error-level record per subsystem
+// before passing every record on.
+type lastErrorHandler struct {
+	slog.Handler
+}
+
+func (h lastErrorHandler) Handle(ctx context.Context, r slog.Record) error {
+	if r.Level >= slog.LevelError {
+		e := LoggedError{Subsystem: subsystem(r.Message), Message: r.Message, Time: r.Time}
+		r.Attrs(func(a slog.Attr) bool {
+			if a.Key != "err" {
+				return true
+			}
+			e.Err = Redact(a.Value.String())
+			return false
+		})
+		lastErrorsMu.Lock()
+		lastErrors[e.Subsystem] = e
+		lastErrorsMu.Unlock()
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h lastErrorHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return lastErrorHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h lastErrorHandler) WithGroup(name string) slog.Handler {
+	return lastErrorHandler{h.Handler.WithGroup(name)}
+}
@@ -0,0 +1,110 @@
+package logging
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"io"
+	"log/slog"
+	"strings"
+	"sync"
+)
+
+type ctxKey struct{}
+
+var (
+	secretsMu sync.RWMutex
+	secrets   []string
+)
+
+// Setup installs the default slog logger. format is "json" (default) or "text";
+// level is one of debug, info, warn, error (default info). The logger keeps
+// the latest error of each subsystem for LastErrors.
+func Setup(w io.Writer, format, level string) {
+	opts := &slog.HandlerOptions{Level: parseLevel(level), ReplaceAttr: redactAttr}
+	var h slog.Handler
+	if strings.EqualFold(format, "text") {
+		h = slog.NewTextHandler(w, opts)
+	} else {
+		h = slog.NewJSONHandler(w, opts)
+	}
+	slog.SetDefault(slog.New(lastErrorHandler{h}))
+}
+
+func parseLevel(level string) slog.Level {
+	switch strings.ToLower(strings.TrimSpace(level)) {
+	case "debug":
+		return slog.LevelDebug
+	case "warn", "warning":
+		return slog.LevelWarn
+	case "error":
+		return slog.LevelError
+	default:
+		return slog.LevelInfo
+	}
+}
+
+// RegisterSecret makes the logger mask every occurrence of s in string values.
+// Tokens end up in library error messages (the Bot API URL embeds the token),
+// so masking values is more reliable than trusting call sites.
+func RegisterSecret(s string) {
+	if len(s) < 8 {
+		return
+	}
+	secretsMu.Lock()
+	secrets = append(secrets, s)
+	secretsMu.Unlock()
+}
+
+// Redact masks registered secrets in s.
+func Redact(s string) string {
+	secretsMu.RLock()
+	defer secretsMu.RUnlock()
+	for _, sec := range secrets {
+		s = strings.ReplaceAll(s, sec, "[REDACTED]")
+	}
+	return s
+}
+
+func redactAttr(_ []string, a slog.Attr) slog.Attr {
+	switch a.Value.Kind() {
+	case slog.KindString:
+		return slog.String(a.Key, Redact(a.Value.String()))
+	case slog.KindAny:
+		if err, ok := a.Value.Any().(error); ok {
+			return slog.String(a.Key, Redact(err.Error()))
+		}
+	}
+	return a
+}
+
+// NewRequestID returns a short random identifier for one update.
+func NewRequestID() string {
+	var b [6]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "unknown"
+	}
+	return hex.EncodeToString(b[:])
+}
+
+// WithRequestID stores id in ctx so every log line for one update shares it.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, ctxKey{}, id)
+}
+
+// RequestID returns the correlation ID stored in ctx, if any.
+func RequestID(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	id, _ := ctx.Value(ctxKey{}).(string)
+	return id
+}
+
+// FromContext returns the default logger annotated with the request ID in ctx.
+func FromContext(ctx context.Context) *slog.Logger {
+	if id := RequestID(ctx); id != "" {
+		return slog.Default().With("request_id", id)
+	}
+	return slog.Default()
+}
@@ -0,0 +1,104 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"regexp"
+	"strings"
+
+	oa "github.com/openai/openai-go"
+	"github.com/openai/openai-go/shared"
+
+	"telegramBotTrade/internal/logging"
+)
+
+// ActionItem is one task ExtractActionItems found in a chat.
+type ActionItem struct {
+	Owner string `json:"owner"` // "" when nobody took it on
+	Task  string `json:"task"`
+	Due   string `json:"due"` // as said in the chat, "" when no deadline was given
+}
+
+// reActionLine matches a bullet of the summary's "Owner → Task → When" form,
+// the shape the model falls back to when it ignores the JSON format.
+var reActionLine = regexp.MustCompile(`^\s*(?:[-*•]|\d+[.)])?\s*([^→]+?)\s*→\s*([^→]+?)\s*(?:→\s*(.+?))?\s*$`)
+
+// ExtractActionItems asks the small model, in JSON mode, for the action items
+// of messages only: who committed to doing what, and by when.
+func (s *Summarizer) ExtractActionItems(ctx context.Context, messages []ChatMessage) ([]ActionItem, error) {
+	msgs := sanitizeMessages(messages)
+	if len(msgs) == 0 {
+		return nil, nil
+	}
+	resp, err := s.cli.Chat.Completions.New(ctx, oa.ChatCompletionNewParams{
+		Model: SmallModel,
+		Messages: []oa.ChatCompletionMessageParamUnion{
+			oa.SystemMessage("You extract action items from a group chat. Lines read \"Name: message\"; an indented \"↳ replying to Name: …\" line quotes the message being answered. " +
+				"An action item is a task someone committed to or was asked to do and accepted. Leave out questions, opinions and finished work. " +
+				`Reply with JSON only: {"action_items": [{"owner": "Name", "task": "short imperative task", "due": "deadline as said, or empty"}]}. ` +
+				`Use an empty owner when nobody took the task on, and {"action_items": []} when there are none.`),
+			oa.UserMessage("Chat excerpt:\n" + strings.Join(msgs, "\n")),
+		},
+		ResponseFormat: oa.ChatCompletionNewParamsResponseFormatUnion{OfJSONObject: &shared.ResponseFormatJSONObjectParam{}},
+		MaxTokens:      oa.Int(800),
+	})
+	if err != nil {
+		logging.FromContext(ctx).Error("openai: action items failed", "err", err)
+		return nil, err
+	}
+	if len(resp.Choices) == 0 {
+		return nil, errors.New("no response from OpenAI")
+	}
+	items, err := parseActionItems(resp.Choices[0].Message.Content)
+	if err != nil {
+		logging.FromContext(ctx).Warn("openai: unreadable action items", "err", err, "content", resp.Choices[0].Message.Content)
+		return nil, err
+	}
+	logging.FromContext(ctx).Info("openai: action items complete", "messages", len(msgs), "items", len(items))
+	return items, nil
+}
+
+// parseActionItems reads the model's reply: the requested object, a bare
+// array of items, either wrapped in prose or a code fence, or failing those
+// "Owner → Task → When" lines. Items without a task are dropped.
+func parseActionItems(content string) ([]ActionItem, error) {
+	var items []ActionItem
+	var wrapped struct {
+		ActionItems []ActionItem `json:"action_items"`
+	}
+	i := strings.IndexAny(content, "{[")
+	switch {
+	case i >= 0 && content[i] == '{' && unmarshalWithin(content[i:], '}', &wrapped) == nil:
+		items = wrapped.ActionItems
+	case i >= 0 && content[i] == '[' && unmarshalWithin(content[i:], ']', &items) == nil:
+	default:
+		for _, line := range strings.Split(content, "\n") {
+			if g := reActionLine.FindStringSubmatch(line); g != nil {
+				items = append(items, ActionItem{Owner: g[1], Task: g[2], Due: g[3]})
+			}
+		}
+		if len(items) == 0 {
+			return nil, errors.New("no action items in the reply")
+		}
+	}
+	out := items[:0]
+	for _, it := range items {
+		it.Owner, it.Task, it.Due = strings.TrimSpace(it.Owner), strings.TrimSpace(it.Task), strings.TrimSpace(it.Due)
+		if it.Task == "" {
+			continue
+		}
+		out = append(out, it)
+	}
+	return out, nil
+}
+
+// unmarshalWithin decodes s up to its last close into v, dropping whatever
+// follows the JSON, such as a closing code fence.
+func unmarshalWithin(s string, close byte, v any) error {
+	j := strings.LastIndexByte(s, close)
+	if j < 0 {
+		return errors.New("no JSON found")
+	}
+	return json.Unmarshal([]byte(s[:j+1]), v)
+}
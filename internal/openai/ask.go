@@ -0,0 +1,44 @@
+package openai
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	oa "github.com/openai/openai-go"
+
+	"telegramBotTrade/internal/logging"
+)
+
+// NotInHistory is the reply Answer asks the model for when the history
+// doesn't contain the answer.
+const NotInHistory = "I couldn't find that in the chat history."
+
+// Answer replies to question using only messages, which should be in
+// chronological order and carry Time labels so the model can cite when
+// something was said.
+func (s *Summarizer) Answer(ctx context.Context, question string, messages []ChatMessage) (string, error) {
+	msgs := sanitizeMessages(messages)
+	if len(msgs) == 0 {
+		return NotInHistory, nil
+	}
+	resp, err := s.cli.Chat.Completions.New(ctx, oa.ChatCompletionNewParams{
+		Model: Model,
+		Messages: []oa.ChatCompletionMessageParamUnion{
+			oa.SystemMessage("You answer questions about a group chat using only the excerpt provided. Lines read \"[time] Name: message\"; an indented \"↳ replying to Name: …\" line quotes the message being answered. " +
+				"Answer in one to three plain-text sentences and cite the approximate time and who said it, e.g. \"Bob suggested NVDA (Tue ~14:05)\". " +
+				"Use no outside knowledge and don't guess. If the excerpt doesn't contain the answer, reply exactly: " + NotInHistory),
+			oa.UserMessage("Chat excerpt:\n" + strings.Join(msgs, "\n") + "\n\nQuestion: " + question),
+		},
+		MaxTokens: oa.Int(300),
+	})
+	if err != nil {
+		logging.FromContext(ctx).Error("openai: answer failed", "err", err)
+		return "", err
+	}
+	if len(resp.Choices) == 0 {
+		return "", errors.New("no response from OpenAI")
+	}
+	logging.FromContext(ctx).Info("openai: answer complete", "messages", len(msgs), "completion_tokens", resp.Usage.CompletionTokens)
+	return strings.TrimSpace(resp.Choices[0].Message.Content), nil
+}
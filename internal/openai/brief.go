@@ -0,0 +1,32 @@
+package openai
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	oa "github.com/openai/openai-go"
+
+	"telegramBotTrade/internal/logging"
+)
+
+// MoverComment writes a two-sentence comment on the standout mover of a
+// morning brief. facts lists the quotes the brief shows, one per line.
+func (s *Summarizer) MoverComment(ctx context.Context, mover string, facts string) (string, error) {
+	resp, err := s.cli.Chat.Completions.New(ctx, oa.ChatCompletionNewParams{
+		Model: Model,
+		Messages: []oa.ChatCompletionMessageParamUnion{
+			oa.SystemMessage("You are a concise market commentator. Reply with exactly two plain-text sentences, no advice, no links, no markdown."),
+			oa.UserMessage("Standout mover: " + mover + "\nQuotes (symbol, price, % change vs previous close):\n" + facts),
+		},
+		MaxTokens: oa.Int(120),
+	})
+	if err != nil {
+		logging.FromContext(ctx).Error("openai: mover comment failed", "err", err)
+		return "", err
+	}
+	if len(resp.Choices) == 0 {
+		return "", errors.New("no response from OpenAI")
+	}
+	return strings.TrimSpace(resp.Choices[0].Message.Content), nil
+}
@@ -0,0 +1,95 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	oa "github.com/openai/openai-go"
+
+	"telegramBotTrade/internal/logging"
+)
+
+// ChartIntent is a chart request extracted from free text such as "show me
+// apple for the last six months".
+type ChartIntent struct {
+	Symbols   []string `json:"symbols"`    // Yahoo tickers, e.g. AAPL, ^GSPC, BTC-USD
+	Interval  string   `json:"interval"`   // 1m|5m|15m|1h|1d, "" when not implied
+	Window    string   `json:"window"`     // 1d|5d|1m|3m|6m|1y|2y|5y|10y|30y, "" when not implied
+	ChartType string   `json:"chart_type"` // price, compare, indexed or macd
+	// Confident is false when the model had to guess a ticker or the timeframe.
+	Confident bool `json:"confident"`
+}
+
+const chartIntentTool = "show_chart"
+
+var chartIntentParams = oa.FunctionParameters{
+	"type": "object",
+	"properties": map[string]any{
+		"symbols": map[string]any{
+			"type":        "array",
+			"items":       map[string]any{"type": "string"},
+			"description": "Yahoo Finance tickers in upper case, e.g. AAPL, SPY, ^GSPC, BTC-USD, GC=F. Empty when no instrument is named.",
+		},
+		"interval": map[string]any{
+			"type":        "string",
+			"enum":        []string{"", "1m", "5m", "15m", "1h", "1d"},
+			"description": "Bar interval, empty unless the text implies one.",
+		},
+		"window": map[string]any{
+			"type":        "string",
+			"enum":        []string{"", "1d", "5d", "1m", "3m", "6m", "1y", "2y", "5y", "10y", "30y"},
+			"description": "Lookback, m = months. Round to the nearest value; empty unless the text implies one.",
+		},
+		"chart_type": map[string]any{
+			"type":        "string",
+			"enum":        []string{"price", "compare", "indexed", "macd"},
+			"description": "price for one symbol, compare to overlay several, indexed for relative performance from a common start, macd when MACD is asked for.",
+		},
+		"confident": map[string]any{
+			"type":        "boolean",
+			"description": "False if any ticker or the timeframe was a guess.",
+		},
+	},
+	"required": []string{"symbols", "interval", "window", "chart_type", "confident"},
+}
+
+// ExtractChartIntent asks the model to turn a free-text chart request into a
+// ChartIntent through a forced function call. Validating the result against
+// what the chart commands accept is left to the caller.
+func (s *Summarizer) ExtractChartIntent(ctx context.Context, text string) (ChartIntent, error) {
+	resp, err := s.cli.Chat.Completions.New(ctx, oa.ChatCompletionNewParams{
+		Model: Model,
+		Messages: []oa.ChatCompletionMessageParamUnion{
+			oa.SystemMessage("You turn chat messages asking for a market chart into a " + chartIntentTool + " call. Map company and asset names to their Yahoo Finance tickers. Never invent an instrument the message doesn't mention."),
+			oa.UserMessage(text),
+		},
+		Tools: []oa.ChatCompletionToolParam{{
+			Function: oa.FunctionDefinitionParam{
+				Name:        chartIntentTool,
+				Description: oa.String("Show a price chart of one or more instruments."),
+				Parameters:  chartIntentParams,
+			},
+		}},
+		ToolChoice: oa.ChatCompletionToolChoiceOptionParamOfChatCompletionNamedToolChoice(
+			oa.ChatCompletionNamedToolChoiceFunctionParam{Name: chartIntentTool}),
+		MaxTokens: oa.Int(150),
+	})
+	if err != nil {
+		logging.FromContext(ctx).Error("openai: chart intent failed", "err", err)
+		return ChartIntent{}, err
+	}
+	if len(resp.Choices) == 0 || len(resp.Choices[0].Message.ToolCalls) == 0 {
+		return ChartIntent{}, errors.New("no chart request recognized")
+	}
+	var intent ChartIntent
+	if err := json.Unmarshal([]byte(resp.Choices[0].Message.ToolCalls[0].Function.Arguments), &intent); err != nil {
+		return ChartIntent{}, fmt.Errorf("chart intent: %w", err)
+	}
+	for i, sym := range intent.Symbols {
+		intent.Symbols[i] = strings.ToUpper(strings.TrimSpace(sym))
+	}
+	return intent, nil
+}
@@ -0,0 +1,94 @@
+package openai
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// emojiOnlyMaxRunes is the longest emoji-only message Condense drops; longer
+// runs of emoji are kept as someone making a point.
+const emojiOnlyMaxRunes = 8
+
+// Condense prepares a transcript for Summarize by collapsing runs of
+// consecutive messages with the same text, ignoring case, spacing and
+// trailing punctuation, into the first one with a count ("+1 (×14)"), and
+// by dropping short emoji-only messages. A run by several people is
+// attributed to the first with "and N others". Replies only join a run
+// answering the same message. condensed is how many messages were dropped
+// or folded into another.
+func Condense(messages []ChatMessage) (out []ChatMessage, condensed int) {
+	out = make([]ChatMessage, 0, len(messages))
+	var (
+		key     string // condenseKey of the last message in out
+		repeats int    // messages folded into it
+		authors map[string]bool
+	)
+	flush := func() {
+		if repeats == 0 {
+			return
+		}
+		last := &out[len(out)-1]
+		last.Text = fmt.Sprintf("%s (×%d)", last.Text, repeats+1)
+		if others := len(authors) - 1; others > 0 {
+			last.Author = fmt.Sprintf("%s and %d other%s", last.Author, others, plural(others))
+		}
+	}
+	for _, m := range messages {
+		if emojiOnly(m.Text) {
+			condensed++
+			continue
+		}
+		k := condenseKey(m.Text)
+		if k != "" && len(out) > 0 && k == key && m.ReplyText == out[len(out)-1].ReplyText {
+			repeats++
+			authors[m.Author] = true
+			condensed++
+			continue
+		}
+		flush()
+		out = append(out, m)
+		key, repeats, authors = k, 0, map[string]bool{m.Author: true}
+	}
+	flush()
+	return out, condensed
+}
+
+// condenseKey is the text Condense compares consecutive messages by.
+func condenseKey(text string) string {
+	s := strings.ToLower(strings.Join(strings.Fields(sanitizeText(text)), " "))
+	if t := strings.TrimRightFunc(s, unicode.IsPunct); t != "" {
+		return t
+	}
+	return s
+}
+
+// emojiOnly reports whether text is at most emojiOnlyMaxRunes of emoji and
+// spacing, the text a sticker or reaction message leaves behind.
+func emojiOnly(text string) bool {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return false
+	}
+	n := 0
+	for _, r := range text {
+		switch {
+		case unicode.IsSpace(r):
+			continue
+		case r == '\u200d' || unicode.Is(unicode.Mn, r) || unicode.Is(unicode.Me, r):
+			// joiners, variation selectors and keycaps are parts of an emoji
+		case unicode.Is(unicode.So, r) || unicode.Is(unicode.Sk, r):
+			n++
+		default:
+			return false
+		}
+	}
+	return n > 0 && n <= emojiOnlyMaxRunes
+}
+
+func plural(n int) string {
+	if n == 1 {
+		return ""
+	}
+	return "s"
+}
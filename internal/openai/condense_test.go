@@ -0,0 +1,192 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	oa "github.com/openai/openai-go"
+	"github.com/openai/openai-go/option"
+)
+
+func TestCondense(t *testing.T) {
+	tests := []struct {
+		name          string
+		in            []ChatMessage
+		want          []ChatMessage
+		wantCondensed int
+	}{
+		{
+			name: "repeats fold into the first",
+			in: []ChatMessage{
+				{Author: "ann", Text: "+1"},
+				{Author: "bob", Text: "+1"},
+				{Author: "cat", Text: " +1 "},
+				{Author: "ann", Text: "+1"},
+			},
+			want:          []ChatMessage{{Author: "ann and 2 others", Text: "+1 (×4)"}},
+			wantCondensed: 3,
+		},
+		{
+			name: "case, spacing and trailing punctuation are ignored",
+			in: []ChatMessage{
+				{Author: "ann", Text: "To the moon"},
+				{Author: "bob", Text: "to  the MOON!!!"},
+				{Author: "ann", Text: "to the moon?"},
+			},
+			want:          []ChatMessage{{Author: "ann and 1 other", Text: "To the moon (×3)"}},
+			wantCondensed: 2,
+		},
+		{
+			name: "only consecutive messages fold",
+			in: []ChatMessage{
+				{Author: "ann", Text: "buy"},
+				{Author: "bob", Text: "sell"},
+				{Author: "cat", Text: "buy"},
+			},
+			want: []ChatMessage{
+				{Author: "ann", Text: "buy"},
+				{Author: "bob", Text: "sell"},
+				{Author: "cat", Text: "buy"},
+			},
+		},
+		{
+			name: "replies fold only when answering the same message",
+			in: []ChatMessage{
+				{Author: "ann", Text: "yes", ReplyAuthor: "bob", ReplyText: "long NVDA?"},
+				{Author: "cat", Text: "yes", ReplyAuthor: "bob", ReplyText: "long NVDA?"},
+				{Author: "dan", Text: "yes", ReplyAuthor: "bob", ReplyText: "short TSLA?"},
+			},
+			want: []ChatMessage{
+				{Author: "ann and 1 other", Text: "yes (×2)", ReplyAuthor: "bob", ReplyText: "long NVDA?"},
+				{Author: "dan", Text: "yes", ReplyAuthor: "bob", ReplyText: "short TSLA?"},
+			},
+			wantCondensed: 1,
+		},
+		{
+			name: "emoji-only messages are dropped",
+			in: []ChatMessage{
+				{Author: "ann", Text: "earnings beat"},
+				{Author: "bob", Text: "🚀🚀🚀"},
+				{Author: "cat", Text: "👍🏽"},
+				{Author: "dan", Text: "❤️"},
+				{Author: "eve", Text: "great 🚀"},
+			},
+			want: []ChatMessage{
+				{Author: "ann", Text: "earnings beat"},
+				{Author: "eve", Text: "great 🚀"},
+			},
+			wantCondensed: 3,
+		},
+		{
+			name: "a repeat by one author keeps the name",
+			in: []ChatMessage{
+				{Author: "ann", Text: "hello?"},
+				{Author: "ann", Text: "hello?"},
+			},
+			want:          []ChatMessage{{Author: "ann", Text: "hello? (×2)"}},
+			wantCondensed: 1,
+		},
+	}
+	for _, tc := range tests {
+		got, condensed := Condense(tc.in)
+		if condensed != tc.wantCondensed || len(got) != len(tc.want) {
+			t.Errorf("%s: Condense = %+v, %d; want %+v, %d", tc.name, got, condensed, tc.want, tc.wantCondensed)
+			continue
+		}
+		for i := range got {
+			if got[i] != tc.want[i] {
+				t.Errorf("%s: message %d = %+v, want %+v", tc.name, i, got[i], tc.want[i])
+			}
+		}
+	}
+}
+
+func TestEmojiOnly(t *testing.T) {
+	tests := map[string]bool{
+		"🚀":         true,
+		"🔥 🔥 🔥":     true,
+		"👨‍👩‍👧":     true, // joined into one emoji
+		"1️⃣":       false,
+		"🚀🚀🚀🚀🚀🚀🚀🚀":  true,
+		"🚀🚀🚀🚀🚀🚀🚀🚀🚀": false, // a long run makes a point
+		"lol 😂":     false,
+		"":          false,
+		"   ":       false,
+		"+1":        false,
+	}
+	for text, want := range tests {
+		if got := emojiOnly(text); got != want {
+			t.Errorf("emojiOnly(%q) = %v, want %v", text, got, want)
+		}
+	}
+}
+
+// TestSummarizeCondensed checks what a condensed transcript sends to the
+// model, against a stub of the chat completions endpoint.
+func TestSummarizeCondensed(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		prompts []string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Messages []struct {
+				Role    string `json:"role"`
+				Content string `json:"content"`
+			} `json:"messages"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		mu.Lock()
+		for _, m := range req.Messages {
+			if m.Role == "user" {
+				prompts = append(prompts, m.Content)
+			}
+		}
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id":"1","object":"chat.completion","created":0,"model":"gpt-4",
+			"choices":[{"index":0,"finish_reason":"stop","message":{"role":"assistant","content":"summary"}}]}`))
+	}))
+	defer srv.Close()
+	s := &Summarizer{cli: oa.NewClient(option.WithAPIKey("test"), option.WithBaseURL(srv.URL), option.WithMaxRetries(0))}
+
+	var transcript []ChatMessage
+	transcript = append(transcript, ChatMessage{Author: "ann", Text: "Is NVDA reporting tonight?"})
+	for _, a := range []string{"bob", "cat", "bob", "dan"} {
+		transcript = append(transcript, ChatMessage{Author: a, Text: "🚀🚀"})
+	}
+	for _, a := range []string{"bob", "cat", "dan"} {
+		transcript = append(transcript, ChatMessage{Author: a, Text: "yes, after the close", ReplyAuthor: "ann", ReplyText: "Is NVDA reporting tonight?"})
+	}
+	condensedMsgs, condensed := Condense(transcript)
+	if condensed != 6 {
+		t.Errorf("condensed %d messages, want 6", condensed)
+	}
+	out, err := s.Summarize(context.Background(), condensedMsgs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out != "summary" {
+		t.Errorf("Summarize = %q, want the stub's summary", out)
+	}
+	// one chunk and the merge
+	if len(prompts) != 2 {
+		t.Fatalf("%d requests, want 2", len(prompts))
+	}
+	want := "ann: Is NVDA reporting tonight?\n" +
+		"bob and 2 others: yes, after the close (×3)\n  ↳ replying to ann: Is NVDA reporting tonight?"
+	if !strings.HasSuffix(prompts[0], want) {
+		t.Errorf("chunk prompt =\n%s\nwant it to end with\n%s", prompts[0], want)
+	}
+	if strings.Contains(prompts[0], "🚀") {
+		t.Error("the chunk prompt still has the emoji-only messages")
+	}
+}
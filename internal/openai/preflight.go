@@ -0,0 +1,15 @@
+package openai
+
+import (
+	"context"
+
+	oa "github.com/openai/openai-go"
+	"github.com/openai/openai-go/option"
+)
+
+// CheckKey lists models to confirm the API key is accepted. It costs no tokens.
+func CheckKey(ctx context.Context, apiKey string) error {
+	client := oa.NewClient(option.WithAPIKey(apiKey), option.WithMaxRetries(0))
+	_, err := client.Models.List(ctx)
+	return err
+}
@@ -3,11 +3,58 @@ package openai
 import (
 	"context"
 	"fmt"
+	"regexp"
+	"strings"
 
 	oa "github.com/openai/openai-go"
 	"github.com/openai/openai-go/option"
+
+	"telegramBotTrade/internal/logging"
+)
+
+// Pick is one position a recommendation takes.
+type Pick struct {
+	Symbol string
+	Short  bool
+}
+
+// Recommendation is the model's answer, with the closing POSITIONS line
+// moved out of Text into Picks. Picks is empty when the line is missing.
+type Recommendation struct {
+	Text  string
+	Picks []Pick
+}
+
+// maxPicks caps the positions recorded from one recommendation.
+const maxPicks = 10
+
+var (
+	rePositionsLine = regexp.MustCompile(`(?im)^[\s*_]*positions[\s*_]*:[\s*_]*(.*)$`)
+	rePosition      = regexp.MustCompile(`(?i)\b(long|short)\s+\$?([A-Za-z0-9.^=-]{1,15})\b`)
 )
 
+// parsePositions splits the POSITIONS line off text and reads its entries,
+// e.g. "POSITIONS: LONG XLF, SHORT TLT". Repeated symbols keep their first
+// direction.
+func parsePositions(text string) Recommendation {
+	loc := rePositionsLine.FindStringSubmatchIndex(text)
+	if loc == nil {
+		return Recommendation{Text: text}
+	}
+	line := text[loc[2]:loc[3]]
+	rec := Recommendation{Text: strings.TrimSpace(text[:loc[0]] + text[loc[1]:])}
+	seen := map[string]bool{}
+	for _, g := range rePosition.FindAllStringSubmatch(line, -1) {
+		sym := strings.ToUpper(g[2])
+		if seen[sym] || len(rec.Picks) == maxPicks {
+			continue
+		}
+		seen[sym] = true
+		rec.Picks = append(rec.Picks, Pick{Symbol: sym, Short: strings.EqualFold(g[1], "short")})
+	}
+	return rec
+}
+
 type Recommender struct {
 	cli oa.Client
 }
@@ -17,7 +64,9 @@ func NewRecommender(apiKey string) *Recommender {
 	return &Recommender{cli: client}
 }
 
-func (r *Recommender) GetTradingRecommendation(ctx context.Context, userInput string) (string, error) {
+// GetTradingRecommendation asks for a structured recommendation on the
+// user's thesis. Its positions come back in Picks so they can be scored later.
+func (r *Recommender) GetTradingRecommendation(ctx context.Context, userInput string) (Recommendation, error) {
 	systemPrompt := `You are a professional financial analyst providing structured trading recommendations. You will receive a user's investment thesis or market view and provide a comprehensive analysis.
 
 Your response must follow this exact structure:
@@ -41,12 +90,13 @@ Guidelines:
 - Consider both direct and indirect ways to play the thesis
 - Include risk management perspective
 - Use clear, concise explanations
-- Format with bullet points where appropriate`
+- Format with bullet points where appropriate
+- End with one final line listing every position from Ticker Recommendations as LONG or SHORT and its ticker, exactly like: POSITIONS: LONG XLF, SHORT TLT`
 
 	userPrompt := fmt.Sprintf("User wants to bet on: %s\n\nProvide trading recommendations following the structured format.", userInput)
 
 	resp, err := r.cli.Chat.Completions.New(ctx, oa.ChatCompletionNewParams{
-		Model: "gpt-4",
+		Model: Model,
 		Messages: []oa.ChatCompletionMessageParamUnion{
 			oa.SystemMessage(systemPrompt),
 			oa.UserMessage(userPrompt),
@@ -54,12 +104,14 @@ Guidelines:
 		MaxTokens: oa.Int(1500), // Limit response length for telegram
 	})
 	if err != nil {
-		return "", fmt.Errorf("OpenAI API error: %w", err)
+		logging.FromContext(ctx).Error("openai: recommendation failed", "err", err)
+		return Recommendation{}, fmt.Errorf("OpenAI API error: %w", err)
 	}
 
 	if len(resp.Choices) == 0 {
-		return "", fmt.Errorf("no response from OpenAI")
+		return Recommendation{}, fmt.Errorf("no response from OpenAI")
 	}
 
-	return resp.Choices[0].Message.Content, nil
+	logging.FromContext(ctx).Info("openai: recommendation complete", "completion_tokens", resp.Usage.CompletionTokens)
+	return parsePositions(resp.Choices[0].Message.Content), nil
 }
@@ -2,13 +2,24 @@ package openai
 
 import (
 	"context"
+	"fmt"
 	"regexp"
 	"strings"
+	"sync"
 
 	oa "github.com/openai/openai-go"
 	"github.com/openai/openai-go/option"
+
+	"telegramBotTrade/internal/logging"
 )
 
+// Model is the chat completion model used for summaries and recommendations.
+const Model = "gpt-4"
+
+// SmallModel is the cheaper model used for short, high-volume tasks such as
+// translation.
+const SmallModel = "gpt-4o-mini"
+
 type Summarizer struct {
 	cli oa.Client
 }
@@ -18,46 +29,110 @@ func NewSummarizer(apiKey string) *Summarizer {
 	return &Summarizer{cli: client}
 }
 
-func (s *Summarizer) Summarize(ctx context.Context, messages []string) (string, error) {
+// ChatMessage is one chat message handed to Summarize. ReplyAuthor and
+// ReplyText describe the message it answers, when that is known.
+type ChatMessage struct {
+	Author      string
+	Text        string
+	ReplyAuthor string
+	ReplyText   string
+	Time        string // optional label such as "Tue 14:05", shown before the author
+}
+
+// replySnippetLen caps how much of a replied-to message is quoted.
+const replySnippetLen = 120
+
+// Summarize sends the transcript in chunks of summaryChunk messages to keep
+// tokens reasonable, each repeating the last summaryOverlap messages of the
+// one before, and runs up to summaryConcurrency of them at once.
+const (
+	summaryChunk       = 60
+	summaryOverlap     = 10
+	summaryConcurrency = 3
+)
+
+// chunkBounds returns the [start, end) message ranges Summarize sends for n
+// messages.
+func chunkBounds(n int) [][2]int {
+	var out [][2]int
+	for start := 0; ; start += summaryChunk - summaryOverlap {
+		end := min(start+summaryChunk, n)
+		out = append(out, [2]int{start, end})
+		if end == n {
+			return out
+		}
+	}
+}
+
+// Summarize summarizes the transcript chunk by chunk and merges the partial
+// summaries into one. The overlap lets a chunk see the question an answer at
+// its start refers to; the merge unifies what the overlap repeats.
+func (s *Summarizer) Summarize(ctx context.Context, messages []ChatMessage) (string, error) {
 	// sanitize messages: strip URLs, markdown images, and non-textual blobs
 	msgs := sanitizeMessages(messages)
 	if len(msgs) == 0 {
 		return "No text messages to summarize.", nil
 	}
-	// chunk to keep tokens reasonable
-	const chunk = 60
-	var partials []string
-	for i := 0; i < len(msgs); i += chunk {
-		end := i + chunk
-		if end > len(msgs) {
-			end = len(messages)
-		}
-		part := strings.Join(msgs[i:end], "\n")
-
-		resp, err := s.cli.Chat.Completions.New(ctx, oa.ChatCompletionNewParams{
-			Model: "gpt-4",
-			Messages: []oa.ChatCompletionMessageParamUnion{
-				oa.SystemMessage("You are a concise text-only chat summarizer. Ignore images, videos, stickers, audio, locations, code attachments, and links. Do not include or describe media. Use bullets. Capture decisions, questions, and action items (who/what/when)."),
-				oa.UserMessage("Summarize this group chat excerpt concisely (text only):\n" + part),
-			},
-		})
-		if err != nil {
-			return "", err
-		}
-		partials = append(partials, resp.Choices[0].Message.Content)
+	logger := logging.FromContext(ctx)
+	bounds := chunkBounds(len(msgs))
+	partials := make([]string, len(bounds))
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		firstErr error
+	)
+	sem := make(chan struct{}, summaryConcurrency)
+	for i, b := range bounds {
+		wg.Add(1)
+		go func(i, start, end int) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			logger.Debug("openai: summarizing chunk", "start", start, "end", end, "total", len(msgs))
+			prompt := "Summarize this group chat excerpt concisely (text only):\n"
+			if i > 0 {
+				prompt = fmt.Sprintf("Summarize this group chat excerpt concisely (text only). Its first %d lines repeat the end of the previous excerpt for context: use them to connect answers to earlier questions, but don't summarize them on their own.\n", min(summaryOverlap, end-start))
+			}
+			resp, err := s.cli.Chat.Completions.New(ctx, oa.ChatCompletionNewParams{
+				Model: Model,
+				Messages: []oa.ChatCompletionMessageParamUnion{
+					oa.SystemMessage("You are a concise text-only chat summarizer. Ignore images, videos, stickers, audio, locations, code attachments, and links. Do not include or describe media. Use bullets. Capture decisions, questions, and action items (who/what/when). Lines read \"Name: message\"; an indented \"↳ replying to Name: …\" line quotes the message being answered, so attribute answers and action items to the right thread."),
+					oa.UserMessage(prompt + strings.Join(msgs[start:end], "\n")),
+				},
+			})
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				if firstErr == nil {
+					firstErr = err
+					cancel()
+				}
+				return
+			}
+			partials[i] = fmt.Sprintf("Part %d of %d:\n%s", i+1, len(bounds), resp.Choices[0].Message.Content)
+		}(i, b[0], b[1])
+	}
+	wg.Wait()
+	if firstErr != nil {
+		logger.Error("openai: chunk summary failed", "err", firstErr)
+		return "", firstErr
 	}
 
 	merged := strings.Join(partials, "\n\n")
 	final, err := s.cli.Chat.Completions.New(ctx, oa.ChatCompletionNewParams{
-		Model: "gpt-4",
+		Model: Model,
 		Messages: []oa.ChatCompletionMessageParamUnion{
-			oa.SystemMessage("Create a single compact text-only summary with sections: Key Points, Decisions, Open Questions, Action Items (Owner → Task → When). Do not include links or media descriptions."),
+			oa.SystemMessage("Create a single compact text-only summary with sections: Key Points, Decisions, Open Questions, Action Items (Owner → Task → When). The input is partial summaries of consecutive, slightly overlapping parts of one chat, in order. Unify bullets that describe the same point into one. When a question in one part is answered in a later part, report it once with its answer under Key Points or Decisions and leave it out of Open Questions. Do not include links or media descriptions."),
 			oa.UserMessage(merged),
 		},
 	})
 	if err != nil {
+		logger.Error("openai: merge summary failed", "err", err)
 		return "", err
 	}
+	logger.Info("openai: summary complete", "messages", len(msgs), "chunks", len(partials))
 	return strings.TrimSpace(final.Choices[0].Message.Content), nil
 }
 
@@ -66,13 +141,15 @@ var (
 	reURL         = regexp.MustCompile(`https?://\S+`)
 )
 
-// sanitizeMessages removes media references and large non-textual content
-func sanitizeMessages(messages []string) []string {
+// sanitizeMessages removes media references and large non-textual content and
+// renders each message as an "Author: text" line. A reply is followed by an
+// indented "↳ replying to Author: …" line quoting its parent, so the model can
+// tell which question an answer addresses. The pair stays one entry so
+// chunking never separates them.
+func sanitizeMessages(messages []ChatMessage) []string {
 	out := make([]string, 0, len(messages))
 	for _, m := range messages {
-		text := reMarkdownImg.ReplaceAllString(m, "")
-		text = reURL.ReplaceAllString(text, "")
-		text = strings.TrimSpace(text)
+		text := sanitizeText(m.Text)
 		if text == "" {
 			continue
 		}
@@ -80,7 +157,26 @@ func sanitizeMessages(messages []string) []string {
 		if len(text) > 2000 {
 			text = text[:2000]
 		}
-		out = append(out, text)
+		line := text
+		if m.Author != "" {
+			line = m.Author + ": " + text
+		}
+		if m.Time != "" {
+			line = "[" + m.Time + "] " + line
+		}
+		if parent := sanitizeText(m.ReplyText); parent != "" {
+			if r := []rune(parent); len(r) > replySnippetLen {
+				parent = string(r[:replySnippetLen]) + "…"
+			}
+			line += "\n  ↳ replying to " + m.ReplyAuthor + ": " + strings.ReplaceAll(parent, "\n", " ")
+		}
+		out = append(out, line)
 	}
 	return out
 }
+
+func sanitizeText(s string) string {
+	s = reMarkdownImg.ReplaceAllString(s, "")
+	s = reURL.ReplaceAllString(s, "")
+	return strings.TrimSpace(s)
+}
@@ -0,0 +1,32 @@
+package openai
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	oa "github.com/openai/openai-go"
+
+	"telegramBotTrade/internal/logging"
+)
+
+// Translate translates text into lang (a language name such as "English" or
+// "Chinese") with the small model. The caller caps the input length.
+func (s *Summarizer) Translate(ctx context.Context, text, lang string) (string, error) {
+	resp, err := s.cli.Chat.Completions.New(ctx, oa.ChatCompletionNewParams{
+		Model: SmallModel,
+		Messages: []oa.ChatCompletionMessageParamUnion{
+			oa.SystemMessage("Translate the user's message into " + lang + ". Reply with the translation only, keeping names, tickers, numbers and emoji unchanged. If it is already in " + lang + ", return it unchanged."),
+			oa.UserMessage(text),
+		},
+		MaxTokens: oa.Int(1000),
+	})
+	if err != nil {
+		logging.FromContext(ctx).Error("openai: translation failed", "err", err)
+		return "", err
+	}
+	if len(resp.Choices) == 0 {
+		return "", errors.New("no response from OpenAI")
+	}
+	return strings.TrimSpace(resp.Choices[0].Message.Content), nil
+}
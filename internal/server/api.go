@@ -0,0 +1,186 @@
+package server
+
+import (
+	"cmp"
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"telegramBotTrade/internal/finance"
+	"telegramBotTrade/internal/logging"
+)
+
+type apiError struct {
+	Error string `json:"error"`
+}
+
+// APIOptions configures the /api endpoints. They are disabled when Token is empty.
+type APIOptions struct {
+	Token           string   // one or more comma-separated bearer tokens
+	Notify          Notifier // delivers /api/notify messages; nil disables the endpoint
+	NotifyPerMinute int      // /api/notify requests allowed per token per minute
+	ChartPerMinute  int      // /api/chart and /api/portfolio requests allowed per token per minute, together
+}
+
+const defaultChartPerMinute = 30
+
+// tokens returns the configured bearer tokens.
+func (o APIOptions) tokens() []string {
+	var out []string
+	for _, t := range strings.Split(o.Token, ",") {
+		if t = strings.TrimSpace(t); t != "" {
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
+// registerAPI adds the authenticated endpoints:
+//
+//	GET  /api/chart?symbol=SPY&interval=1h&window=1y
+//	GET  /api/portfolio?spec=SPY:0.6,TLT:0.4&window=2y
+//	POST /api/notify
+//
+// All require "Authorization: Bearer <token>" and are rate limited per token;
+// the two chart endpoints share one limit. Charts go through the same Yahoo
+// pacing and render pool as the Telegram commands, so API traffic can't
+// starve the bot.
+func registerAPI(mux *http.ServeMux, opts APIOptions) {
+	tokens := opts.tokens()
+	charts := newTokenLimiter(cmp.Or(max(opts.ChartPerMinute, 0), defaultChartPerMinute), time.Minute)
+	mux.Handle("/api/chart", requireToken(tokens, http.MethodGet, limitPerToken(charts, http.HandlerFunc(chartHandler))))
+	mux.Handle("/api/portfolio", requireToken(tokens, http.MethodGet, limitPerToken(charts, http.HandlerFunc(portfolioHandler))))
+	if opts.Notify != nil {
+		notify := newTokenLimiter(cmp.Or(max(opts.NotifyPerMinute, 0), defaultNotifyPerMinute), time.Minute)
+		mux.Handle("/api/notify", requireToken(tokens, http.MethodPost, limitPerToken(notify, notifyHandler(opts.Notify))))
+	}
+}
+
+type apiTokenKey struct{}
+
+// requireToken rejects requests without one of the bearer tokens and any
+// method but method. The matched token is stored in the request context for
+// per-token rate limits.
+func requireToken(tokens []string, method string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, ok := matchToken(tokens, r.Header.Get("Authorization"))
+		if !ok {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			writeAPIError(w, http.StatusUnauthorized, "missing or invalid bearer token")
+			return
+		}
+		if r.Method != method {
+			w.Header().Set("Allow", method)
+			writeAPIError(w, http.StatusMethodNotAllowed, "only "+method+" is supported")
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), apiTokenKey{}, token)))
+	})
+}
+
+// matchToken compares the Authorization header against every token in
+// constant time.
+func matchToken(tokens []string, header string) (string, bool) {
+	got, ok := strings.CutPrefix(header, "Bearer ")
+	if !ok {
+		return "", false
+	}
+	match := ""
+	for _, t := range tokens {
+		if subtle.ConstantTimeCompare([]byte(got), []byte(t)) == 1 {
+			match = t
+		}
+	}
+	return match, match != ""
+}
+
+func chartHandler(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	symbol := strings.ToUpper(strings.TrimSpace(q.Get("symbol")))
+	if symbol == "" {
+		writeAPIError(w, http.StatusBadRequest, "symbol is required, e.g. ?symbol=SPY&interval=1h&window=1y")
+		return
+	}
+	interval := q.Get("interval")
+	if interval == "" {
+		interval = "5m"
+	}
+	res, err := finance.MakeChart(r.Context(), symbol, interval, q.Get("window"), finance.RenderOptions{})
+	if err != nil {
+		logging.FromContext(r.Context()).Error("api: chart failed", "symbol", symbol, "err", err)
+		writeChartError(w, err)
+		return
+	}
+	writePNG(w, res.Image)
+}
+
+func portfolioHandler(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	spec := strings.TrimSpace(q.Get("spec"))
+	if spec == "" {
+		writeAPIError(w, http.StatusBadRequest, "spec is required, e.g. ?spec=SPY:0.6,TLT:0.4&window=2y")
+		return
+	}
+	window := q.Get("window")
+	if window == "" {
+		window = "1y"
+	}
+	// SPY:0.6,TLT:0.4 is the /port argument list with different separators
+	input := strings.NewReplacer(":", " ", ",", " ").Replace(spec) + " " + window
+	symbols, weights, window, err := finance.ParseWeightedPortfolio(input)
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, "invalid spec: "+err.Error())
+		return
+	}
+	res, err := finance.MakeWeightedPortfolioChart(r.Context(), symbols, weights, window, finance.RenderOptions{})
+	if err != nil {
+		logging.FromContext(r.Context()).Error("api: portfolio failed", "symbols", symbols, "err", err)
+		writeChartError(w, err)
+		return
+	}
+	writePNG(w, res.Image)
+}
+
+// writeChartError maps a chart failure onto a status code: bad arguments,
+// unknown symbols and symbols without data are the caller's problem, a full
+// render queue, a rate-limited data source or an expired deadline the
+// server's, anything else is reported as a failed upstream fetch.
+func writeChartError(w http.ResponseWriter, err error) {
+	var invalid *finance.InvalidInputError
+	switch {
+	case errors.As(err, &invalid):
+		writeAPIError(w, http.StatusBadRequest, invalid.Error())
+	case errors.Is(err, finance.ErrSymbolNotFound):
+		writeAPIError(w, http.StatusNotFound, "unknown symbol")
+	case errors.Is(err, finance.ErrNoData):
+		writeAPIError(w, http.StatusUnprocessableEntity, "no data for this symbol and window")
+	case errors.Is(err, finance.ErrRenderBusy):
+		w.Header().Set("Retry-After", "5")
+		writeAPIError(w, http.StatusServiceUnavailable, "busy rendering other charts, try again shortly")
+	case errors.Is(err, finance.ErrRateLimited):
+		w.Header().Set("Retry-After", "60")
+		writeAPIError(w, http.StatusServiceUnavailable, "data source rate limited, try again in a minute")
+	case errors.Is(err, finance.ErrTimeout), errors.Is(err, context.DeadlineExceeded):
+		writeAPIError(w, http.StatusGatewayTimeout, "chart timed out")
+	default:
+		writeAPIError(w, http.StatusBadGateway, err.Error())
+	}
+}
+
+func writePNG(w http.ResponseWriter, img []byte) {
+	w.Header().Set("Content-Type", "image/png")
+	w.Header().Set("Cache-Control", "private, max-age=60")
+	_, _ = w.Write(img)
+}
+
+func writeAPIError(w http.ResponseWriter, status int, msg string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	_ = enc.Encode(apiError{Error: msg})
+}
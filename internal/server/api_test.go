@@ -0,0 +1,77 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"telegramBotTrade/internal/finance"
+)
+
+func TestWriteChartError(t *testing.T) {
+	_, _, _, parseErr := finance.ParseWeightedPortfolio("SPY 0.5 1.5y")
+	if parseErr == nil {
+		t.Fatal("ParseWeightedPortfolio accepted a bad window")
+	}
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"invalid input", parseErr, http.StatusBadRequest},
+		{"wrapped invalid input", fmt.Errorf("chart: %w", &finance.InvalidInputError{Err: errors.New("bad")}), http.StatusBadRequest},
+		{"unknown symbol", fmt.Errorf("fetch XYZ: %w", finance.ErrSymbolNotFound), http.StatusNotFound},
+		{"no data", finance.ErrNoData, http.StatusUnprocessableEntity},
+		{"render busy", finance.ErrRenderBusy, http.StatusServiceUnavailable},
+		{"rate limited", finance.ErrRateLimited, http.StatusServiceUnavailable},
+		{"timeout", finance.ErrTimeout, http.StatusGatewayTimeout},
+		{"deadline", context.DeadlineExceeded, http.StatusGatewayTimeout},
+		// a data problem that happens to start with "invalid" is not the caller's
+		{"invalid price", errors.New("invalid price for asset 0 (SPY) on day 3: NaN"), http.StatusBadGateway},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			writeChartError(rec, tc.err)
+			if rec.Code != tc.want {
+				t.Errorf("status = %d, want %d", rec.Code, tc.want)
+			}
+		})
+	}
+}
+
+func TestChartRateLimit(t *testing.T) {
+	mux := http.NewServeMux()
+	registerAPI(mux, APIOptions{Token: "a,b", ChartPerMinute: 2})
+	get := func(path, token string) int {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		if rec.Code == http.StatusTooManyRequests && rec.Header().Get("Retry-After") == "" {
+			t.Errorf("429 without Retry-After")
+		}
+		return rec.Code
+	}
+	// requests without arguments are answered 400 without fetching anything,
+	// but still count against the limit
+	steps := []struct {
+		path, token string
+		want        int
+	}{
+		{"/api/chart", "a", http.StatusBadRequest},
+		{"/api/portfolio", "a", http.StatusBadRequest},
+		{"/api/chart", "a", http.StatusTooManyRequests},
+		{"/api/portfolio", "a", http.StatusTooManyRequests}, // the endpoints share the limit
+		{"/api/chart", "b", http.StatusBadRequest},          // tokens don't
+		{"/api/chart", "wrong", http.StatusUnauthorized},
+	}
+	for i, s := range steps {
+		if got := get(s.path, s.token); got != s.want {
+			t.Errorf("step %d: GET %s as %s = %d, want %d", i, s.path, s.token, got, s.want)
+		}
+	}
+}
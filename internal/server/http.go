@@ -1,16 +1,133 @@
 package server
 
 import (
+	"context"
+	"encoding/json"
+	"net"
 	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"telegramBotTrade/internal/version"
 )
 
-func NewHTTPMux(webhook http.HandlerFunc) *http.ServeMux {
+// Checker reports whether a dependency is usable; it should be cheap.
+type Checker func(ctx context.Context) error
+
+type checkResult struct {
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+type readyResponse struct {
+	Status string                 `json:"status"`
+	Checks map[string]checkResult `json:"checks"`
+}
+
+// NewHTTPMux registers each bot's webhook under its path (they only accept
+// POSTs of up to 64KB), the /healthz liveness probe and the /readyz readiness
+// probe that runs every checker. /healthz?verbose=1 also returns the build
+// info from about. The /api endpoints are only registered when api.Token is set.
+func NewHTTPMux(webhooks map[string]http.HandlerFunc, checks map[string]Checker, about func() version.Info, api APIOptions) *http.ServeMux {
 	mux := http.NewServeMux()
-	mux.HandleFunc("/telegram/webhook", webhook)
-	mux.HandleFunc("/healthz", func(w http.ResponseWriter, _ *http.Request) { w.WriteHeader(200) })
+	for path, webhook := range webhooks {
+		mux.Handle(path, webhookOnly(webhook))
+	}
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		v := r.URL.Query().Get("verbose")
+		if (v != "1" && v != "true") || about == nil {
+			w.WriteHeader(200)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(about())
+	})
+	mux.HandleFunc("/readyz", readyHandler(checks))
+	if api.Token != "" {
+		registerAPI(mux, api)
+	}
 	return mux
 }
 
-func ListenAndServe(addr string, mux *http.ServeMux) error {
-	return http.ListenAndServe(addr, mux)
+func readyHandler(checks map[string]Checker) http.HandlerFunc {
+	names := make([]string, 0, len(checks))
+	for name := range checks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+		defer cancel()
+		resp := readyResponse{Status: "ok", Checks: make(map[string]checkResult, len(checks))}
+		for _, name := range names {
+			if err := checks[name](ctx); err != nil {
+				resp.Status = "fail"
+				resp.Checks[name] = checkResult{Status: "fail", Error: err.Error()}
+				continue
+			}
+			resp.Checks[name] = checkResult{Status: "ok"}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if resp.Status != "ok" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		} else {
+			w.WriteHeader(http.StatusOK)
+		}
+		_ = json.NewEncoder(w).Encode(resp)
+	}
+}
+
+// Options configures the HTTP server.
+type Options struct {
+	Addr        string
+	TLSCertFile string // serve HTTPS when both cert and key are set
+	TLSKeyFile  string
+	TrustProxy  bool // take the client address from X-Forwarded-For
+}
+
+// TLSEnabled reports whether both certificate files are configured.
+func (o Options) TLSEnabled() bool { return o.TLSCertFile != "" && o.TLSKeyFile != "" }
+
+// NewServer builds the HTTP server with explicit timeouts, a deadline on every
+// request and an access log; callers stop it with Shutdown to drain in-flight
+// requests.
+func NewServer(opts Options, mux *http.ServeMux) *http.Server {
+	return &http.Server{
+		Addr:              opts.Addr,
+		Handler:           logRequests(withDeadline(mux, requestTimeout), opts.TrustProxy),
+		ReadHeaderTimeout: 10 * time.Second,
+		ReadTimeout:       30 * time.Second,
+		WriteTimeout:      60 * time.Second,
+		IdleTimeout:       120 * time.Second,
+	}
+}
+
+// Serve runs srv over HTTPS when TLS files are configured, plain HTTP otherwise.
+func Serve(srv *http.Server, opts Options) error {
+	if opts.TLSEnabled() {
+		return srv.ListenAndServeTLS(opts.TLSCertFile, opts.TLSKeyFile)
+	}
+	return srv.ListenAndServe()
+}
+
+// clientIP returns the caller's address. Behind a trusted reverse proxy the
+// left-most X-Forwarded-For entry is the original client.
+func clientIP(r *http.Request, trustProxy bool) string {
+	if trustProxy {
+		if xff := r.Header.Get("X-Forwarded-For"); xff != "" {
+			first, _, _ := strings.Cut(xff, ",")
+			if ip := strings.TrimSpace(first); ip != "" {
+				return ip
+			}
+		}
+		if ip := r.Header.Get("X-Real-IP"); ip != "" {
+			return ip
+		}
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
 }
@@ -0,0 +1,83 @@
+package server
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+const (
+	// maxWebhookBody caps an update; real ones are a few kilobytes.
+	maxWebhookBody = 64 << 10
+	// requestTimeout bounds every handler, short of the server's WriteTimeout
+	// so a slow chart still gets its error response written.
+	requestTimeout = 50 * time.Second
+)
+
+// webhookOnly accepts POSTs with a body of at most maxWebhookBody bytes;
+// reading past the limit fails and closes the connection.
+func webhookOnly(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		r.Body = http.MaxBytesReader(w, r.Body, maxWebhookBody)
+		next.ServeHTTP(w, r)
+	})
+}
+
+// withDeadline cancels each request's context after timeout.
+func withDeadline(next http.Handler, timeout time.Duration) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeout(r.Context(), timeout)
+		defer cancel()
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// statusRecorder remembers the status code a handler wrote.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (s *statusRecorder) WriteHeader(code int) {
+	if s.status == 0 {
+		s.status = code
+	}
+	s.ResponseWriter.WriteHeader(code)
+}
+
+func (s *statusRecorder) Write(b []byte) (int, error) {
+	if s.status == 0 {
+		s.status = http.StatusOK
+	}
+	return s.ResponseWriter.Write(b)
+}
+
+// logRequests writes one access log line per request with the client address,
+// status and duration. Successful health probes are logged at debug level so
+// they don't drown out real traffic.
+func logRequests(next http.Handler, trustProxy bool) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w}
+		next.ServeHTTP(rec, r)
+		if rec.status == 0 {
+			rec.status = http.StatusOK
+		}
+		level := slog.LevelInfo
+		if (r.URL.Path == "/healthz" || r.URL.Path == "/readyz") && rec.status < 400 {
+			level = slog.LevelDebug
+		}
+		slog.Log(r.Context(), level, "http: request",
+			"method", r.Method,
+			"path", r.URL.Path,
+			"status", rec.status,
+			"duration", time.Since(start).Round(time.Millisecond),
+			"remote", clientIP(r, trustProxy))
+	})
+}
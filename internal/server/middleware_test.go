@@ -0,0 +1,152 @@
+package server
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// readAll is a webhook like the bot's: it reads the whole update and answers
+// 413 when the body limit cuts it off.
+func readAll(w http.ResponseWriter, r *http.Request) {
+	_, err := io.ReadAll(r.Body)
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		http.Error(w, "update too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+	if err != nil {
+		http.Error(w, "bad update", http.StatusBadRequest)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+func newTestServer(t *testing.T, webhook http.HandlerFunc) *httptest.Server {
+	t.Helper()
+	mux := NewHTTPMux(map[string]http.HandlerFunc{"/telegram/webhook": webhook}, nil, nil, APIOptions{})
+	srv := httptest.NewServer(NewServer(Options{}, mux).Handler)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestWebhookGuard(t *testing.T) {
+	srv := newTestServer(t, readAll)
+	tests := []struct {
+		name   string
+		method string
+		body   string
+		want   int
+	}{
+		{"update", http.MethodPost, `{"update_id":1}`, http.StatusOK},
+		{"at the limit", http.MethodPost, strings.Repeat("x", maxWebhookBody), http.StatusOK},
+		{"oversized", http.MethodPost, strings.Repeat("x", maxWebhookBody+1), http.StatusRequestEntityTooLarge},
+		{"megabyte", http.MethodPost, strings.Repeat("x", 1<<20), http.StatusRequestEntityTooLarge},
+		{"get", http.MethodGet, "", http.StatusMethodNotAllowed},
+		{"put", http.MethodPut, `{"update_id":1}`, http.StatusMethodNotAllowed},
+		{"head", http.MethodHead, "", http.StatusMethodNotAllowed},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			req, _ := http.NewRequest(tc.method, srv.URL+"/telegram/webhook", strings.NewReader(tc.body))
+			resp, err := srv.Client().Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != tc.want {
+				t.Fatalf("status = %d, want %d", resp.StatusCode, tc.want)
+			}
+			if tc.want == http.StatusMethodNotAllowed && resp.Header.Get("Allow") != http.MethodPost {
+				t.Errorf("Allow = %q, want POST", resp.Header.Get("Allow"))
+			}
+		})
+	}
+}
+
+func TestWebhookNotCalledForWrongMethod(t *testing.T) {
+	called := false
+	srv := newTestServer(t, func(w http.ResponseWriter, r *http.Request) { called = true })
+	resp, err := srv.Client().Get(srv.URL + "/telegram/webhook")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if called {
+		t.Error("webhook ran for a GET")
+	}
+}
+
+func TestRequestDeadline(t *testing.T) {
+	var deadline time.Time
+	srv := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		deadline, _ = r.Context().Deadline()
+	})
+	start := time.Now()
+	resp, err := srv.Client().Post(srv.URL+"/telegram/webhook", "application/json", strings.NewReader("{}"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if deadline.IsZero() || deadline.Sub(start) > requestTimeout+time.Second {
+		t.Errorf("deadline %v after start, want about %v", deadline.Sub(start), requestTimeout)
+	}
+}
+
+func TestAccessLog(t *testing.T) {
+	var buf bytes.Buffer
+	prev := slog.Default()
+	slog.SetDefault(slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelInfo})))
+	t.Cleanup(func() { slog.SetDefault(prev) })
+
+	srv := newTestServer(t, readAll)
+	for _, path := range []string{"/telegram/webhook", "/healthz"} {
+		resp, err := srv.Client().Get(srv.URL + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+	}
+	log := buf.String()
+	for _, want := range []string{"method=GET", "path=/telegram/webhook", "status=405", "duration=", "remote=127.0.0.1"} {
+		if !strings.Contains(log, want) {
+			t.Errorf("access log missing %q:\n%s", want, log)
+		}
+	}
+	if strings.Contains(log, "path=/healthz") {
+		t.Errorf("healthy probe logged at info:\n%s", log)
+	}
+}
+
+func TestClientIP(t *testing.T) {
+	tests := []struct {
+		name       string
+		xff, real  string
+		trustProxy bool
+		want       string
+	}{
+		{name: "remote address", want: "192.0.2.1"},
+		{name: "forwarded header ignored", xff: "203.0.113.7", want: "192.0.2.1"},
+		{name: "trusted proxy", xff: "203.0.113.7, 10.0.0.1", trustProxy: true, want: "203.0.113.7"},
+		{name: "real ip", real: "203.0.113.9", trustProxy: true, want: "203.0.113.9"},
+		{name: "no headers behind proxy", trustProxy: true, want: "192.0.2.1"},
+	}
+	for _, tc := range tests {
+		r := httptest.NewRequest(http.MethodGet, "/", nil) // RemoteAddr 192.0.2.1:1234
+		if tc.xff != "" {
+			r.Header.Set("X-Forwarded-For", tc.xff)
+		}
+		if tc.real != "" {
+			r.Header.Set("X-Real-IP", tc.real)
+		}
+		if got := clientIP(r, tc.trustProxy); got != tc.want {
+			t.Errorf("%s: clientIP = %q, want %q", tc.name, got, tc.want)
+		}
+	}
+}
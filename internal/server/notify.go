@@ -0,0 +1,143 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"telegramBotTrade/internal/logging"
+)
+
+// ErrUnknownChat is returned by a Notifier for a chat the bot has never seen.
+var ErrUnknownChat = errors.New("unknown chat")
+
+// Notification is the /api/notify request body.
+type Notification struct {
+	ChatID    int64  `json:"chat_id"`
+	Text      string `json:"text"`
+	ParseMode string `json:"parse_mode,omitempty"` // "", HTML, Markdown or MarkdownV2
+	Symbol    string `json:"symbol,omitempty"`     // attach a chart of this symbol
+}
+
+// NotifyResult reports what a Notifier delivered.
+type NotifyResult struct {
+	MessageID  int    `json:"message_id"`
+	ChartError string `json:"chart_error,omitempty"` // the text was sent but the chart wasn't
+}
+
+// Notifier delivers a notification into its chat.
+type Notifier func(ctx context.Context, n Notification) (NotifyResult, error)
+
+type notifyResponse struct {
+	Status string `json:"status"`
+	NotifyResult
+}
+
+const (
+	maxNotifyBody          = 16 << 10
+	maxNotifyText          = 4096 // Telegram's message limit
+	defaultNotifyPerMinute = 20
+)
+
+// notifyHandler validates a notification and hands it to notify.
+func notifyHandler(notify Notifier) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var n Notification
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxNotifyBody)).Decode(&n); err != nil {
+			writeAPIError(w, http.StatusBadRequest, "invalid JSON body: "+err.Error())
+			return
+		}
+		n.Text = strings.TrimSpace(n.Text)
+		n.Symbol = strings.ToUpper(strings.TrimSpace(n.Symbol))
+		switch {
+		case n.ChatID == 0:
+			writeAPIError(w, http.StatusBadRequest, "chat_id is required")
+			return
+		case n.Text == "":
+			writeAPIError(w, http.StatusBadRequest, "text is required")
+			return
+		case len([]rune(n.Text)) > maxNotifyText:
+			writeAPIError(w, http.StatusBadRequest, "text exceeds 4096 characters")
+			return
+		}
+		switch n.ParseMode {
+		case "", "HTML", "Markdown", "MarkdownV2":
+		default:
+			writeAPIError(w, http.StatusBadRequest, "parse_mode must be HTML, Markdown or MarkdownV2")
+			return
+		}
+
+		res, err := notify(r.Context(), n)
+		if errors.Is(err, ErrUnknownChat) {
+			writeAPIError(w, http.StatusNotFound, "chat_id is not a chat the bot knows")
+			return
+		}
+		if err != nil {
+			logging.FromContext(r.Context()).Warn("api: notify failed", "chat_id", n.ChatID, "err", err)
+			writeAPIError(w, http.StatusBadGateway, "delivery failed: "+err.Error())
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusAccepted)
+		_ = json.NewEncoder(w).Encode(notifyResponse{Status: "delivered", NotifyResult: res})
+	}
+}
+
+// tokenLimiter allows limit requests per key in each window, refilling
+// continuously so a caller is never locked out for a whole window.
+type tokenLimiter struct {
+	mu      sync.Mutex
+	limit   float64
+	window  time.Duration
+	buckets map[string]*tokenBucket
+}
+
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// limitPerToken answers 429 once the bearer token requireToken matched has
+// used up its requests in limiter.
+func limitPerToken(limiter *tokenLimiter, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, _ := r.Context().Value(apiTokenKey{}).(string)
+		if ok, retry := limiter.allow(token); !ok {
+			w.Header().Set("Retry-After", strconv.Itoa(int(retry.Seconds())+1))
+			writeAPIError(w, http.StatusTooManyRequests, "rate limit exceeded")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func newTokenLimiter(limit int, window time.Duration) *tokenLimiter {
+	return &tokenLimiter{limit: float64(limit), window: window, buckets: map[string]*tokenBucket{}}
+}
+
+// allow takes a token for key, or reports how long until one is available.
+func (l *tokenLimiter) allow(key string) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := time.Now()
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &tokenBucket{tokens: l.limit, last: now}
+		l.buckets[key] = b
+	}
+	b.tokens += l.limit * float64(now.Sub(b.last)) / float64(l.window)
+	if b.tokens > l.limit {
+		b.tokens = l.limit
+	}
+	b.last = now
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0
+	}
+	return false, time.Duration((1 - b.tokens) / l.limit * float64(l.window))
+}
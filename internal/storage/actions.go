@@ -0,0 +1,94 @@
+package storage
+
+import "database/sql"
+
+// ActionItem is a task /actions found in a chat.
+type ActionItem struct {
+	ID        int64
+	ChatID    int64
+	Num       int // per-chat number used by /actions done N
+	Owner     string
+	Task      string
+	Due       string // as written in the chat, e.g. "Friday"; "" when none was given
+	CreatedAt int64
+	DoneAt    int64 // 0 while open
+}
+
+func initActionItemsSchema(db DB) error {
+	_, err := db.Exec(`CREATE TABLE IF NOT EXISTS action_items(
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		chat_id INTEGER NOT NULL,
+		num INTEGER NOT NULL,
+		owner TEXT NOT NULL DEFAULT '',
+		task TEXT NOT NULL,
+		due TEXT NOT NULL DEFAULT '',
+		created_at INTEGER NOT NULL,
+		done_at INTEGER NOT NULL DEFAULT 0,
+		UNIQUE(chat_id, num)
+	)`)
+	return err
+}
+
+const actionItemColumns = `id, chat_id, num, owner, task, due, created_at, done_at`
+
+func scanActionItems(rows *sql.Rows) ([]ActionItem, error) {
+	var out []ActionItem
+	for rows.Next() {
+		var a ActionItem
+		if err := rows.Scan(&a.ID, &a.ChatID, &a.Num, &a.Owner, &a.Task, &a.Due, &a.CreatedAt, &a.DoneAt); err != nil {
+			return nil, err
+		}
+		out = append(out, a)
+	}
+	return out, rows.Err()
+}
+
+// SaveActionItems stores items for chatID, numbering them on from the chat's
+// highest number, and returns them with their IDs and numbers set.
+func (s *Store) SaveActionItems(chatID int64, items []ActionItem, ts int64) ([]ActionItem, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	var last int
+	if err := tx.QueryRow(`SELECT COALESCE(MAX(num), 0) FROM action_items WHERE chat_id=?`, chatID).Scan(&last); err != nil {
+		return nil, err
+	}
+	out := make([]ActionItem, len(items))
+	for i, a := range items {
+		a.ChatID, a.Num, a.CreatedAt, a.DoneAt = chatID, last+i+1, ts, 0
+		res, err := tx.Exec(`INSERT INTO action_items(chat_id,num,owner,task,due,created_at) VALUES(?,?,?,?,?,?)`,
+			a.ChatID, a.Num, a.Owner, a.Task, a.Due, a.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		if a.ID, err = res.LastInsertId(); err != nil {
+			return nil, err
+		}
+		out[i] = a
+	}
+	return out, tx.Commit()
+}
+
+// FetchOpenActionItems returns the chat's action items not marked done, in
+// number order.
+func (s *Store) FetchOpenActionItems(chatID int64) ([]ActionItem, error) {
+	rows, err := s.db.Query(`SELECT `+actionItemColumns+` FROM action_items WHERE chat_id=? AND done_at=0 ORDER BY num`, chatID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanActionItems(rows)
+}
+
+// MarkActionItemDone marks the chat's item num done at ts. It reports false
+// when the chat has no such open item.
+func (s *Store) MarkActionItemDone(chatID int64, num int, ts int64) (bool, error) {
+	res, err := s.db.Exec(`UPDATE action_items SET done_at=? WHERE chat_id=? AND num=? AND done_at=0`, ts, chatID, num)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
@@ -0,0 +1,115 @@
+package storage
+
+import (
+	"sort"
+	"strconv"
+)
+
+// Series names of FetchMessageActivity.
+const (
+	ActivityAll    = "messages" // every message, when not split by user
+	ActivityOthers = "others"   // users outside the most active ones
+)
+
+// FetchMessageActivity counts the chat's stored messages with since <= ts <
+// until in buckets of intervalHours, bucketed like FetchUsageTimeSeries.
+// Every bucket from since's to until's is present, empty ones as zero, so a
+// chart's x-axis has no gaps. With topUsers > 0 the counts are split into a
+// series per most active user, named by display name, plus ActivityOthers
+// when anyone else wrote; otherwise there is one ActivityAll series. names
+// lists the series most active first, ActivityOthers last.
+func (s *Store) FetchMessageActivity(chatID, since, until int64, intervalHours, topUsers int) (series map[string][]TimeSeriesPoint, names []string, err error) {
+	if intervalHours <= 0 {
+		intervalHours = 1
+	}
+	rows, err := s.db.Query(`SELECT user_id, MAX(user_name), (ts / (? * 3600)) * (? * 3600) AS time_bucket, COUNT(*)
+		FROM messages WHERE chat_id=? AND ts>=? AND ts<?
+		GROUP BY user_id, time_bucket`,
+		intervalHours, intervalHours, chatID, since, until)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+	type userCounts struct {
+		name     string
+		total    int
+		byBucket map[int64]int
+	}
+	users := map[int64]*userCounts{}
+	for rows.Next() {
+		var userID, bucket int64
+		var name string
+		var n int
+		if err := rows.Scan(&userID, &name, &bucket, &n); err != nil {
+			return nil, nil, err
+		}
+		u := users[userID]
+		if u == nil {
+			u = &userCounts{byBucket: map[int64]int{}}
+			users[userID] = u
+		}
+		if name != "" {
+			u.name = name
+		}
+		u.total += n
+		u.byBucket[bucket] += n
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	ids := make([]int64, 0, len(users))
+	for id := range users {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		if users[ids[i]].total != users[ids[j]].total {
+			return users[ids[i]].total > users[ids[j]].total
+		}
+		return ids[i] < ids[j]
+	})
+	// series name of each user
+	seriesOf := map[int64]string{}
+	seen := map[string]bool{}
+	for i, id := range ids {
+		switch {
+		case topUsers <= 0:
+			seriesOf[id] = ActivityAll
+		case i < topUsers:
+			seriesOf[id] = users[id].name
+			if seriesOf[id] == "" {
+				seriesOf[id] = "user " + strconv.FormatInt(id, 10)
+			}
+		default:
+			seriesOf[id] = ActivityOthers
+		}
+		if name := seriesOf[id]; !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		names = []string{ActivityAll}
+	}
+	counts := map[string]map[int64]int{}
+	for id, u := range users {
+		name := seriesOf[id]
+		if counts[name] == nil {
+			counts[name] = map[int64]int{}
+		}
+		for b, n := range u.byBucket {
+			counts[name][b] += n
+		}
+	}
+
+	step := int64(intervalHours) * 3600
+	series = make(map[string][]TimeSeriesPoint, len(names))
+	for _, name := range names {
+		points := []TimeSeriesPoint{}
+		for b := since / step * step; b < until; b += step {
+			points = append(points, TimeSeriesPoint{Timestamp: b, Count: counts[name][b]})
+		}
+		series[name] = points
+	}
+	return series, names, nil
+}
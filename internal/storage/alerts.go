@@ -0,0 +1,35 @@
+package storage
+
+import "time"
+
+// alert_log remembers which background alerts were already sent so pollers
+// notify at most once per key and period (e.g. once per symbol per day).
+func initAlertLogSchema(db DB) error {
+	_, err := db.Exec(`CREATE TABLE IF NOT EXISTS alert_log(
+		chat_id INTEGER NOT NULL,
+		kind TEXT NOT NULL,
+		key TEXT NOT NULL,
+		period TEXT NOT NULL,
+		ts INTEGER NOT NULL,
+		PRIMARY KEY(chat_id, kind, key, period)
+	)`)
+	return err
+}
+
+// MarkAlerted records an alert and reports whether it is the first one for
+// (chatID, kind, key, period); callers send the alert only when it is.
+func (s *Store) MarkAlerted(chatID int64, kind, key, period string) (bool, error) {
+	res, err := s.db.Exec(`INSERT OR IGNORE INTO alert_log(chat_id,kind,key,period,ts) VALUES(?,?,?,?,?)`,
+		chatID, kind, key, period, time.Now().Unix())
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+// PruneAlertLog deletes alert records older than before.
+func (s *Store) PruneAlertLog(before int64) error {
+	_, err := s.db.Exec(`DELETE FROM alert_log WHERE ts < ?`, before)
+	return err
+}
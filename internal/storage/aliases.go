@@ -0,0 +1,72 @@
+package storage
+
+import "time"
+
+// Alias is a per-chat shorthand that expands into a full bot command.
+type Alias struct {
+	Name      string // without the slash, e.g. "g"
+	Expansion string // e.g. "/stockx GLD 1h 6m"
+}
+
+func initAliasesSchema(db DB) error {
+	_, err := db.Exec(`CREATE TABLE IF NOT EXISTS aliases(
+		chat_id INTEGER NOT NULL,
+		name TEXT NOT NULL,
+		expansion TEXT NOT NULL,
+		created_at INTEGER NOT NULL,
+		PRIMARY KEY(chat_id, name)
+	)`)
+	return err
+}
+
+// SetAlias creates or replaces the chat's alias name.
+func (s *Store) SetAlias(chatID int64, name, expansion string) error {
+	_, err := s.db.Exec(`INSERT INTO aliases(chat_id,name,expansion,created_at) VALUES(?,?,?,?)
+		ON CONFLICT(chat_id,name) DO UPDATE SET expansion=excluded.expansion`,
+		chatID, name, expansion, time.Now().Unix())
+	return err
+}
+
+// DeleteAlias removes the chat's alias name and reports whether it existed.
+func (s *Store) DeleteAlias(chatID int64, name string) (bool, error) {
+	res, err := s.db.Exec(`DELETE FROM aliases WHERE chat_id=? AND name=?`, chatID, name)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+// FetchAlias returns the expansion of the chat's alias name, or "" when there is none.
+func (s *Store) FetchAlias(chatID int64, name string) (string, error) {
+	rows, err := s.db.Query(`SELECT expansion FROM aliases WHERE chat_id=? AND name=?`, chatID, name)
+	if err != nil {
+		return "", err
+	}
+	defer rows.Close()
+	var expansion string
+	if rows.Next() {
+		if err := rows.Scan(&expansion); err != nil {
+			return "", err
+		}
+	}
+	return expansion, rows.Err()
+}
+
+// FetchAliases returns the chat's aliases sorted by name.
+func (s *Store) FetchAliases(chatID int64) ([]Alias, error) {
+	rows, err := s.db.Query(`SELECT name, expansion FROM aliases WHERE chat_id=? ORDER BY name`, chatID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []Alias
+	for rows.Next() {
+		var a Alias
+		if err := rows.Scan(&a.Name, &a.Expansion); err != nil {
+			return nil, err
+		}
+		out = append(out, a)
+	}
+	return out, rows.Err()
+}
@@ -0,0 +1,43 @@
+package storage
+
+import "time"
+
+// calendar_cache keeps one day's economic calendar payload per source so the
+// upstream feed is fetched at most once a day. It is not chat-scoped.
+func initCalendarSchema(db DB) error {
+	_, err := db.Exec(`CREATE TABLE IF NOT EXISTS calendar_cache(
+		source TEXT NOT NULL,
+		day TEXT NOT NULL,
+		payload BLOB NOT NULL,
+		ts INTEGER NOT NULL,
+		PRIMARY KEY(source, day)
+	)`)
+	return err
+}
+
+// LoadCalendar returns the cached payload for source on day, if any.
+func (s *Store) LoadCalendar(source, day string) ([]byte, bool, error) {
+	rows, err := s.db.Query(`SELECT payload FROM calendar_cache WHERE source=? AND day=?`, source, day)
+	if err != nil {
+		return nil, false, err
+	}
+	defer rows.Close()
+	if !rows.Next() {
+		return nil, false, rows.Err()
+	}
+	var payload []byte
+	if err := rows.Scan(&payload); err != nil {
+		return nil, false, err
+	}
+	return payload, true, nil
+}
+
+// SaveCalendar stores the payload for source on day and drops older days.
+func (s *Store) SaveCalendar(source, day string, payload []byte) error {
+	if _, err := s.db.Exec(`INSERT OR REPLACE INTO calendar_cache(source,day,payload,ts) VALUES(?,?,?,?)`,
+		source, day, payload, time.Now().Unix()); err != nil {
+		return err
+	}
+	_, err := s.db.Exec(`DELETE FROM calendar_cache WHERE source=? AND day<>?`, source, day)
+	return err
+}
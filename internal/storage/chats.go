@@ -0,0 +1,82 @@
+package storage
+
+// initChatsSchema creates the per-bot state of each chat: whether sends still
+// reach it and the chart command /again replays. A chat is only listed once a
+// send to it failed for good or it ran a chart; missing rows are active.
+func initChatsSchema(db DB) error {
+	if _, err := db.Exec(`CREATE TABLE IF NOT EXISTS chats(
+		bot_id TEXT NOT NULL DEFAULT '',
+		chat_id INTEGER NOT NULL,
+		is_active INTEGER NOT NULL DEFAULT 1,
+		reason TEXT NOT NULL DEFAULT '',
+		changed_at INTEGER NOT NULL,
+		PRIMARY KEY(bot_id, chat_id)
+	)`); err != nil {
+		return err
+	}
+	for _, col := range []string{"last_command", "last_theme"} {
+		if err := addColumn(db, "chats", col, "TEXT NOT NULL DEFAULT ''"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// LastCommand is the chart or portfolio command a chat last ran, as /again
+// replays it.
+type LastCommand struct {
+	Text string
+	// Theme is the theme an /again override chose ("" = the chat's theme)
+	Theme string
+}
+
+// SetChatActive records whether the bot can still reach chatID, with the
+// Telegram error that made it unreachable as reason.
+func (s *Store) SetChatActive(chatID int64, active bool, reason string, at int64) error {
+	_, err := s.db.Exec(`INSERT INTO chats(bot_id,chat_id,is_active,reason,changed_at) VALUES(?,?,?,?,?)
+		ON CONFLICT(bot_id, chat_id) DO UPDATE SET is_active=excluded.is_active, reason=excluded.reason, changed_at=excluded.changed_at`,
+		s.bot, chatID, active, reason, at)
+	return err
+}
+
+// SaveLastCommand records c as the chat's last chart command.
+func (s *Store) SaveLastCommand(chatID int64, c LastCommand, at int64) error {
+	_, err := s.db.Exec(`INSERT INTO chats(bot_id,chat_id,changed_at,last_command,last_theme) VALUES(?,?,?,?,?)
+		ON CONFLICT(bot_id, chat_id) DO UPDATE SET last_command=excluded.last_command, last_theme=excluded.last_theme`,
+		s.bot, chatID, at, c.Text, c.Theme)
+	return err
+}
+
+// FetchLastCommand returns the chat's last chart command, empty when it ran none.
+func (s *Store) FetchLastCommand(chatID int64) (LastCommand, error) {
+	rows, err := s.db.Query(`SELECT last_command, last_theme FROM chats WHERE bot_id=? AND chat_id=?`, s.bot, chatID)
+	if err != nil {
+		return LastCommand{}, err
+	}
+	defer rows.Close()
+	var c LastCommand
+	if rows.Next() {
+		if err := rows.Scan(&c.Text, &c.Theme); err != nil {
+			return LastCommand{}, err
+		}
+	}
+	return c, rows.Err()
+}
+
+// FetchInactiveChats returns the chats the bot has been blocked or removed from.
+func (s *Store) FetchInactiveChats() ([]int64, error) {
+	rows, err := s.db.Query(`SELECT chat_id FROM chats WHERE bot_id=? AND is_active=0`, s.bot)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		out = append(out, id)
+	}
+	return out, rows.Err()
+}
@@ -0,0 +1,66 @@
+package storage
+
+// Feedback is a /feedback message left for the maintainer.
+type Feedback struct {
+	ID     int64
+	ChatID int64
+	UserID int64
+	Text   string
+	TS     int64
+	Done   bool
+}
+
+func initFeedbackSchema(db DB) error {
+	_, err := db.Exec(`CREATE TABLE IF NOT EXISTS feedback(
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		chat_id INTEGER NOT NULL,
+		user_id INTEGER NOT NULL,
+		text TEXT NOT NULL,
+		ts INTEGER NOT NULL,
+		done INTEGER NOT NULL DEFAULT 0
+	)`)
+	return err
+}
+
+// SaveFeedback stores a feedback message and returns its ID.
+func (s *Store) SaveFeedback(chatID, userID int64, text string, ts int64) (int64, error) {
+	res, err := s.db.Exec(`INSERT INTO feedback(chat_id,user_id,text,ts) VALUES(?,?,?,?)`,
+		chatID, userID, text, ts)
+	if err != nil {
+		return 0, err
+	}
+	return res.LastInsertId()
+}
+
+// FetchFeedback returns up to limit of the newest feedback entries, open ones only
+// unless includeDone is set.
+func (s *Store) FetchFeedback(limit int, includeDone bool) ([]Feedback, error) {
+	q := `SELECT id, chat_id, user_id, text, ts, done FROM feedback`
+	if !includeDone {
+		q += ` WHERE done=0`
+	}
+	rows, err := s.db.Query(q+` ORDER BY id DESC LIMIT ?`, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []Feedback
+	for rows.Next() {
+		var f Feedback
+		if err := rows.Scan(&f.ID, &f.ChatID, &f.UserID, &f.Text, &f.TS, &f.Done); err != nil {
+			return nil, err
+		}
+		out = append(out, f)
+	}
+	return out, rows.Err()
+}
+
+// ResolveFeedback marks feedback id as done. It reports false when no such entry exists.
+func (s *Store) ResolveFeedback(id int64) (bool, error) {
+	res, err := s.db.Exec(`UPDATE feedback SET done=1 WHERE id=?`, id)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
@@ -0,0 +1,67 @@
+package storage
+
+import (
+	"slices"
+	"testing"
+)
+
+// feedbackIDs lists the IDs of fs in order.
+func feedbackIDs(fs []Feedback) []int64 {
+	ids := make([]int64, len(fs))
+	for i, f := range fs {
+		ids[i] = f.ID
+	}
+	return ids
+}
+
+func TestFeedback(t *testing.T) {
+	s := newTestStore(t)
+	var ids []int64
+	for i, text := range []string{"charts are slow", "add /heat colors", "typo in /help"} {
+		id, err := s.SaveFeedback(-100, 7, text, int64(1000+i))
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, id)
+	}
+
+	open, err := s.FetchFeedback(10, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []int64{ids[2], ids[1], ids[0]}; !slices.Equal(feedbackIDs(open), want) {
+		t.Fatalf("open feedback = %v, want newest first %v", feedbackIDs(open), want)
+	}
+	if want := (Feedback{ID: ids[2], ChatID: -100, UserID: 7, Text: "typo in /help", TS: 1002}); open[0] != want {
+		t.Errorf("newest = %+v, want %+v", open[0], want)
+	}
+	if limited, err := s.FetchFeedback(2, false); err != nil || !slices.Equal(feedbackIDs(limited), []int64{ids[2], ids[1]}) {
+		t.Errorf("FetchFeedback(2) = %v, %v; want the two newest", feedbackIDs(limited), err)
+	}
+
+	ok, err := s.ResolveFeedback(ids[1])
+	if err != nil || !ok {
+		t.Fatalf("ResolveFeedback(%d) = %v, %v; want true", ids[1], ok, err)
+	}
+	if ok, err := s.ResolveFeedback(ids[2] + 100); err != nil || ok {
+		t.Errorf("ResolveFeedback of a missing id = %v, %v; want false", ok, err)
+	}
+
+	open, err = s.FetchFeedback(10, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []int64{ids[2], ids[0]}; !slices.Equal(feedbackIDs(open), want) {
+		t.Errorf("open feedback after resolving = %v, want %v", feedbackIDs(open), want)
+	}
+	all, err := s.FetchFeedback(10, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []int64{ids[2], ids[1], ids[0]}; !slices.Equal(feedbackIDs(all), want) {
+		t.Fatalf("all feedback = %v, want %v", feedbackIDs(all), want)
+	}
+	if !all[1].Done || all[0].Done || all[2].Done {
+		t.Errorf("done flags = %v %v %v, want only the resolved entry done", all[0].Done, all[1].Done, all[2].Done)
+	}
+}
@@ -0,0 +1,111 @@
+package storage
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// SetCommandDuration records how long the command saved as row id took.
+func (s *Store) SetCommandDuration(id int64, d time.Duration) error {
+	_, err := s.db.Exec(`UPDATE command_usage SET duration_ms=? WHERE id=?`, d.Milliseconds(), id)
+	return err
+}
+
+// LatencyStats summarizes how long one category's commands took.
+type LatencyStats struct {
+	Category    string
+	Count       int
+	Median, P95 time.Duration
+}
+
+// FetchLatencyStats returns the median and p95 duration of the chat's
+// commands since the unix time since, per category in name order. Commands
+// without a recorded duration are left out.
+func (s *Store) FetchLatencyStats(chatID int64, since int64) ([]LatencyStats, error) {
+	rows, err := s.db.Query(`SELECT category, duration_ms FROM command_usage
+		WHERE chat_id=? AND ts>=? AND bot_id=? AND duration_ms IS NOT NULL
+		ORDER BY category, duration_ms`, chatID, since, s.bot)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []LatencyStats
+	var durations []int64
+	flush := func() {
+		if n := len(out); n > 0 {
+			out[n-1].Count = len(durations)
+			out[n-1].Median = time.Duration(percentile(durations, 50)) * time.Millisecond
+			out[n-1].P95 = time.Duration(percentile(durations, 95)) * time.Millisecond
+		}
+		durations = durations[:0]
+	}
+	for rows.Next() {
+		var category string
+		var ms int64
+		if err := rows.Scan(&category, &ms); err != nil {
+			return nil, err
+		}
+		if len(out) == 0 || out[len(out)-1].Category != category {
+			flush()
+			out = append(out, LatencyStats{Category: category})
+		}
+		durations = append(durations, ms)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	flush()
+	return out, nil
+}
+
+// LatencyPoint is the median command duration of one UTC day.
+type LatencyPoint struct {
+	Day    time.Time
+	Count  int
+	Median time.Duration
+}
+
+// FetchDailyLatency returns the median duration of the chat's commands for
+// each UTC day since the unix time since that has one, oldest first.
+func (s *Store) FetchDailyLatency(chatID int64, since int64) ([]LatencyPoint, error) {
+	rows, err := s.db.Query(`SELECT ts/86400 AS day, duration_ms FROM command_usage
+		WHERE chat_id=? AND ts>=? AND bot_id=? AND duration_ms IS NOT NULL
+		ORDER BY day, duration_ms`, chatID, since, s.bot)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	byDay := map[int64][]int64{}
+	for rows.Next() {
+		var day, ms int64
+		if err := rows.Scan(&day, &ms); err != nil {
+			return nil, err
+		}
+		byDay[day] = append(byDay[day], ms)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	out := make([]LatencyPoint, 0, len(byDay))
+	for day, durations := range byDay {
+		out = append(out, LatencyPoint{
+			Day:    time.Unix(day*86400, 0).UTC(),
+			Count:  len(durations),
+			Median: time.Duration(percentile(durations, 50)) * time.Millisecond,
+		})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Day.Before(out[j].Day) })
+	return out, nil
+}
+
+// percentile returns the nearest-rank p-th percentile of sorted, the
+// smallest value at least p percent of the values are at or below; 0 when
+// sorted is empty.
+func percentile(sorted []int64, p float64) int64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	return sorted[min(max(rank, 1), len(sorted))-1]
+}
@@ -0,0 +1,110 @@
+package storage
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPercentile(t *testing.T) {
+	oneToHundred := make([]int64, 100)
+	for i := range oneToHundred {
+		oneToHundred[i] = int64(i + 1)
+	}
+	tests := []struct {
+		name   string
+		sorted []int64
+		p      float64
+		want   int64
+	}{
+		{"empty", nil, 50, 0},
+		{"single", []int64{7}, 95, 7},
+		{"median of 1..100", oneToHundred, 50, 50},
+		{"p95 of 1..100", oneToHundred, 95, 95},
+		{"p100 of 1..100", oneToHundred, 100, 100},
+		{"p0 is the minimum", oneToHundred, 0, 1},
+		{"median of an odd count", []int64{10, 20, 30, 40, 50}, 50, 30},
+		{"median of an even count takes the lower", []int64{10, 20, 30, 40}, 50, 20},
+		{"p95 of 20 values", []int64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16, 17, 18, 19, 20}, 95, 19},
+		{"p95 of a long tail", []int64{100, 100, 100, 100, 100, 100, 100, 100, 100, 100, 100, 100, 100, 100, 100, 100, 100, 100, 100, 9000}, 95, 100},
+		{"p95 of a longer tail", []int64{100, 100, 100, 100, 100, 100, 100, 100, 100, 100, 100, 100, 100, 100, 100, 100, 100, 100, 9000, 9000}, 95, 9000},
+	}
+	for _, tc := range tests {
+		if got := percentile(tc.sorted, tc.p); got != tc.want {
+			t.Errorf("%s: percentile(p%v) = %d, want %d", tc.name, tc.p, got, tc.want)
+		}
+	}
+}
+
+func TestFetchLatencyStats(t *testing.T) {
+	s := newTestStore(t)
+	record := func(chatID int64, category string, ts int64, ms int64) {
+		t.Helper()
+		id, err := s.SaveCommandUsage(CommandUsage{ChatID: chatID, Command: category, Category: category, Timestamp: ts})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ms >= 0 {
+			if err := s.SetCommandDuration(id, time.Duration(ms)*time.Millisecond); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	for i := int64(1); i <= 20; i++ {
+		record(1, "chart", 1000, i*100)
+	}
+	record(1, "summary", 1000, 3000)
+	record(1, "summary", 1000, 1000)
+	record(1, "summary", 1000, -1)  // no duration recorded
+	record(1, "summary", 10, 50000) // before since
+	record(2, "summary", 1000, 50000)
+
+	got, err := s.FetchLatencyStats(1, 500)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []LatencyStats{
+		{Category: "chart", Count: 20, Median: time.Second, P95: 1900 * time.Millisecond},
+		{Category: "summary", Count: 2, Median: time.Second, P95: 3 * time.Second},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("stats[%d] = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestFetchDailyLatency(t *testing.T) {
+	s := newTestStore(t)
+	const day = 86400
+	for _, u := range []struct{ ts, ms int64 }{
+		{2*day + 10, 300}, {2*day + 20, 100}, {2*day + 30, 200},
+		{day + 5, 900},
+	} {
+		id, err := s.SaveCommandUsage(CommandUsage{ChatID: 1, Command: "chart", Category: "chart", Timestamp: u.ts})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := s.SetCommandDuration(id, time.Duration(u.ms)*time.Millisecond); err != nil {
+			t.Fatal(err)
+		}
+	}
+	got, err := s.FetchDailyLatency(1, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []LatencyPoint{
+		{Day: time.Unix(day, 0).UTC(), Count: 1, Median: 900 * time.Millisecond},
+		{Day: time.Unix(2*day, 0).UTC(), Count: 3, Median: 200 * time.Millisecond},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if !got[i].Day.Equal(want[i].Day) || got[i].Count != want[i].Count || got[i].Median != want[i].Median {
+			t.Errorf("point[%d] = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
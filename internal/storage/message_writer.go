@@ -0,0 +1,152 @@
+package storage
+
+import (
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// MessageWriter batches SaveMessage calls so a busy group costs one write
+// transaction per interval instead of one per message, which would contend
+// for SQLite's write lock with everything else. Messages are written in the
+// order they were saved.
+//
+// Flush boundary: a saved message is durable once the next flush commits,
+// at most interval later, or sooner when maxBatch messages are waiting.
+// Close flushes whatever is left, so a clean shutdown loses nothing; a crash
+// loses at most the messages saved since the last flush.
+type MessageWriter struct {
+	store    *Store
+	maxBatch int
+
+	mu      sync.Mutex
+	pending []Message
+	closed  bool
+
+	flushMu   sync.Mutex // serializes flushes, so batches commit in order
+	kick      chan struct{}
+	stop      chan struct{}
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// NewMessageWriter starts a writer that flushes every interval or as soon as
+// maxBatch messages are waiting, whichever comes first.
+func NewMessageWriter(store *Store, interval time.Duration, maxBatch int) *MessageWriter {
+	w := &MessageWriter{
+		store:    store,
+		maxBatch: max(1, maxBatch),
+		kick:     make(chan struct{}, 1),
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	go w.run(interval)
+	return w
+}
+
+func (w *MessageWriter) run(interval time.Duration) {
+	defer close(w.done)
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-t.C:
+		case <-w.kick:
+		case <-w.stop:
+			return
+		}
+		if err := w.Flush(); err != nil {
+			slog.Error("db: message flush failed", "err", err)
+		}
+	}
+}
+
+// Save queues m for the next flush. A closed writer stores m directly. When
+// the background flush falls ten batches behind, Save flushes in the caller
+// to keep the backlog, and with it what a crash could lose, bounded.
+func (w *MessageWriter) Save(m Message) error {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return w.store.SaveMessage(m)
+	}
+	w.pending = append(w.pending, m)
+	n := len(w.pending)
+	w.mu.Unlock()
+	switch {
+	case n >= 10*w.maxBatch:
+		return w.Flush()
+	case n >= w.maxBatch:
+		select {
+		case w.kick <- struct{}{}:
+		default:
+		}
+	}
+	return nil
+}
+
+// Flush writes the waiting messages now. Readers that must see every message
+// saved so far, such as /summary, call it first.
+func (w *MessageWriter) Flush() error {
+	if w == nil {
+		return nil
+	}
+	w.flushMu.Lock()
+	defer w.flushMu.Unlock()
+	w.mu.Lock()
+	batch := w.pending
+	w.pending = nil
+	w.mu.Unlock()
+	if len(batch) == 0 {
+		return nil
+	}
+	if err := w.store.saveMessages(batch); err != nil {
+		// one bad row must not cost the whole batch
+		slog.Warn("db: batched message insert failed, writing one by one", "messages", len(batch), "err", err)
+		var errs []error
+		for _, m := range batch {
+			if err := w.store.SaveMessage(m); err != nil {
+				errs = append(errs, err)
+			}
+		}
+		return errors.Join(errs...)
+	}
+	return nil
+}
+
+// Close stops the background flush and writes what is left. Saves after
+// Close go straight to the store.
+func (w *MessageWriter) Close() error {
+	if w == nil {
+		return nil
+	}
+	w.closeOnce.Do(func() {
+		w.mu.Lock()
+		w.closed = true
+		w.mu.Unlock()
+		close(w.stop)
+		<-w.done
+	})
+	return w.Flush()
+}
+
+// saveMessages inserts msgs in one transaction.
+func (s *Store) saveMessages(msgs []Message) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	stmt, err := tx.Prepare(`INSERT INTO messages(chat_id,user_id,user_name,thread_id,message_id,reply_to,text,ts) VALUES(?,?,?,?,?,?,?,?)`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+	for _, m := range msgs {
+		if _, err := stmt.Exec(m.ChatID, m.UserID, m.UserName, m.ThreadID, m.MessageID, m.ReplyTo, m.Text, m.Ts); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
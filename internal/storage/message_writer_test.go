@@ -0,0 +1,177 @@
+package storage
+
+import (
+	"path/filepath"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+// storedIDs returns the message_id of every stored message in insert order.
+func storedIDs(t *testing.T, s *Store) []int {
+	t.Helper()
+	rows, err := s.db.Query(`SELECT message_id FROM messages ORDER BY rowid`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+func msg(id int) Message { return Message{ChatID: 1, MessageID: id, Text: "m", Ts: 100} }
+
+func TestMessageWriterOrder(t *testing.T) {
+	s := newTestStore(t)
+	w := NewMessageWriter(s, time.Millisecond, 7)
+	var want []int
+	for id := 1; id <= 250; id++ {
+		if err := w.Save(msg(id)); err != nil {
+			t.Fatal(err)
+		}
+		want = append(want, id)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if got := storedIDs(t, s); !slices.Equal(got, want) {
+		t.Errorf("stored %d messages out of order: %v", len(got), got)
+	}
+}
+
+// TestMessageWriterConcurrentOrder checks each sender's messages stay in
+// order while several save at once and flushes run underneath.
+func TestMessageWriterConcurrentOrder(t *testing.T) {
+	s := newTestStore(t)
+	w := NewMessageWriter(s, time.Millisecond, 5)
+	const senders, each = 4, 50
+	var wg sync.WaitGroup
+	for sender := range senders {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range each {
+				if err := w.Save(msg(sender*1000 + i)); err != nil {
+					t.Error(err)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	ids := storedIDs(t, s)
+	if len(ids) != senders*each {
+		t.Fatalf("stored %d messages, want %d", len(ids), senders*each)
+	}
+	next := make([]int, senders)
+	for _, id := range ids {
+		sender, i := id/1000, id%1000
+		if i != next[sender] {
+			t.Fatalf("sender %d: message %d stored before %d", sender, i, next[sender])
+		}
+		next[sender]++
+	}
+}
+
+func TestMessageWriterFlushOnClose(t *testing.T) {
+	s := newTestStore(t)
+	w := NewMessageWriter(s, time.Hour, 100)
+	for id := 1; id <= 5; id++ {
+		w.Save(msg(id))
+	}
+	if got := storedIDs(t, s); len(got) != 0 {
+		t.Fatalf("%d messages written before any flush", len(got))
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if got := storedIDs(t, s); !slices.Equal(got, []int{1, 2, 3, 4, 5}) {
+		t.Errorf("after Close stored %v, want 1-5", got)
+	}
+	// Close is idempotent and later saves go straight to the store
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Save(msg(6)); err != nil {
+		t.Fatal(err)
+	}
+	if got := storedIDs(t, s); len(got) != 6 {
+		t.Errorf("save after Close not stored directly: %v", got)
+	}
+}
+
+func TestMessageWriterFullBatch(t *testing.T) {
+	s := newTestStore(t)
+	w := NewMessageWriter(s, time.Hour, 3)
+	defer w.Close()
+	for id := 1; id <= 3; id++ {
+		w.Save(msg(id))
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for len(storedIDs(t, s)) < 3 {
+		if time.Now().After(deadline) {
+			t.Fatal("a full batch was not flushed before the interval")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestMessageWriterBacklogFlushesInline(t *testing.T) {
+	s := newTestStore(t)
+	w := NewMessageWriter(s, time.Hour, 2)
+	defer w.Close()
+	// hold the background flush so the backlog builds up
+	w.flushMu.Lock()
+	for id := 1; id < 20; id++ {
+		w.Save(msg(id))
+	}
+	w.flushMu.Unlock()
+	if err := w.Save(msg(20)); err != nil { // the tenth batch
+		t.Fatal(err)
+	}
+	if got := storedIDs(t, s); len(got) != 20 {
+		t.Errorf("stored %d after a backlog of ten batches, want all 20", len(got))
+	}
+}
+
+// TestMessageWriterCrash abandons a writer without Close, as a crash would,
+// and reopens the database: what was flushed survives, and only what was
+// saved after the last flush is lost.
+func TestMessageWriterCrash(t *testing.T) {
+	dsn := "file:" + filepath.Join(t.TempDir(), "bot.db") + "?_fk=1"
+	db, err := OpenSQLite(dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := InitSchema(db); err != nil {
+		t.Fatal(err)
+	}
+	w := NewMessageWriter(NewStore(db), time.Hour, 100)
+	for id := 1; id <= 4; id++ {
+		w.Save(msg(id))
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	w.Save(msg(5)) // never flushed
+	db.Close()
+
+	db, err = OpenSQLite(dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if got := storedIDs(t, NewStore(db)); !slices.Equal(got, []int{1, 2, 3, 4}) {
+		t.Errorf("after the crash stored %v, want the flushed 1-4", got)
+	}
+}
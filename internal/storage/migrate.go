@@ -0,0 +1,138 @@
+package storage
+
+import "fmt"
+
+// addColumn adds column to table unless it already exists, letting InitSchema
+// upgrade databases created by older versions.
+func addColumn(db DB, table, column, decl string) error {
+	exists, err := hasColumn(db, table, column)
+	if err != nil || exists {
+		return err
+	}
+	_, err = db.Exec(`ALTER TABLE ` + table + ` ADD COLUMN ` + column + ` ` + decl)
+	return err
+}
+
+// hasColumn reports whether table has column.
+func hasColumn(db DB, table, column string) (bool, error) {
+	rows, err := db.Query(`PRAGMA table_info(` + table + `)`)
+	if err != nil {
+		return false, err
+	}
+	exists := false
+	for rows.Next() {
+		var (
+			cid     int
+			name    string
+			ctype   string
+			notnull int
+			dflt    any
+			pk      int
+		)
+		if err := rows.Scan(&cid, &name, &ctype, &notnull, &dflt, &pk); err != nil {
+			rows.Close()
+			return false, err
+		}
+		if name == column {
+			exists = true
+		}
+	}
+	rows.Close()
+	return exists, rows.Err()
+}
+
+// scopeUpdateOffsets rebuilds update_offsets keyed by (bot_id, chat_id); SQLite
+// can't change a primary key in place. Existing offsets belong to the primary bot.
+func scopeUpdateOffsets(db DB) error {
+	scoped, err := hasColumn(db, "update_offsets", "bot_id")
+	if err != nil || scoped {
+		return err
+	}
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	for _, stmt := range []string{
+		`CREATE TABLE update_offsets_new(
+			bot_id TEXT NOT NULL DEFAULT '',
+			chat_id INTEGER,
+			last_update_id INTEGER,
+			PRIMARY KEY(bot_id, chat_id)
+		)`,
+		`INSERT INTO update_offsets_new(chat_id,last_update_id) SELECT chat_id, last_update_id FROM update_offsets`,
+		`DROP TABLE update_offsets`,
+		`ALTER TABLE update_offsets_new RENAME TO update_offsets`,
+	} {
+		if _, err := tx.Exec(stmt); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// chatTable describes a table keyed by chat_id for MigrateChat. unique marks
+// tables where chat_id is a primary key, so an existing row for the new ID wins
+// and the old row is dropped instead of violating the constraint.
+type chatTable struct {
+	name   string
+	unique bool
+}
+
+// chatTables lists every table that stores chat_id; new per-chat tables must be added here.
+var chatTables = []chatTable{
+	{name: "messages"},
+	{name: "command_usage"},
+	{name: "chat_settings", unique: true},
+	{name: "update_offsets", unique: true},
+	{name: "feedback"},
+	{name: "schedules"},
+	{name: "watchlist", unique: true},
+	{name: "alert_log", unique: true},
+	{name: "targets"},
+	{name: "paper_trades"},
+	{name: "aliases", unique: true},
+	{name: "summaries"},
+	{name: "name_backfill", unique: true},
+	{name: "chats", unique: true},
+	{name: "portfolio_watches", unique: true},
+	{name: "recommendations"},
+	{name: "action_items", unique: true},
+}
+
+// MigrateChat rewrites chat_id from oldID to newID across all per-chat tables
+// in one transaction. Telegram changes the ID when a group becomes a supergroup.
+func (s *Store) MigrateChat(oldID, newID int64) (int64, error) {
+	if oldID == newID {
+		return 0, nil
+	}
+	tx, err := s.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	var moved int64
+	for _, t := range chatTables {
+		update := `UPDATE ` + t.name + ` SET chat_id=? WHERE chat_id=?`
+		if t.unique {
+			update = `UPDATE OR IGNORE ` + t.name + ` SET chat_id=? WHERE chat_id=?`
+		}
+		res, err := tx.Exec(update, newID, oldID)
+		if err != nil {
+			return 0, fmt.Errorf("migrate %s: %w", t.name, err)
+		}
+		if n, err := res.RowsAffected(); err == nil {
+			moved += n
+		}
+		if t.unique {
+			if _, err := tx.Exec(`DELETE FROM `+t.name+` WHERE chat_id=?`, oldID); err != nil {
+				return 0, fmt.Errorf("migrate %s: %w", t.name, err)
+			}
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return moved, nil
+}
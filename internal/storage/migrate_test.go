@@ -0,0 +1,161 @@
+package storage
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// newTestStore opens an in-memory database with the full schema, private to
+// the test.
+func newTestStore(t *testing.T) *Store {
+	t.Helper()
+	db, err := OpenSQLite("file:" + strings.ReplaceAll(t.Name(), "/", "_") + "?mode=memory&cache=shared&_fk=1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	if err := InitSchema(db); err != nil {
+		t.Fatal(err)
+	}
+	return NewStore(db)
+}
+
+func TestMigrateChat(t *testing.T) {
+	const oldID, newID = int64(-100), int64(-1001)
+	s := newTestStore(t)
+	for _, m := range []Message{
+		{ChatID: oldID, Text: "old 1", Ts: 1},
+		{ChatID: oldID, Text: "old 2", Ts: 2},
+		{ChatID: newID, Text: "new 1", Ts: 3},
+		{ChatID: 7, Text: "other chat", Ts: 4},
+	} {
+		if err := s.SaveMessage(m); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// watchlist is keyed by (chat_id, symbol): SPY collides, QQQ moves
+	if err := s.AddWatchlist(oldID, "SPY", "QQQ"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.AddWatchlist(newID, "SPY"); err != nil {
+		t.Fatal(err)
+	}
+	// chat_settings is keyed by chat_id: the new chat's row wins
+	if err := s.SetChatSetting(oldID, "theme", "dark"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.SetChatSetting(newID, "theme", "light"); err != nil {
+		t.Fatal(err)
+	}
+
+	// action_items is keyed by (chat_id, num): the old chat's item 1 collides
+	if _, err := s.SaveActionItems(oldID, []ActionItem{{Task: "old 1"}, {Task: "old 2"}}, 1); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.SaveActionItems(newID, []ActionItem{{Task: "new 1"}}, 2); err != nil {
+		t.Fatal(err)
+	}
+
+	moved, err := s.MigrateChat(oldID, newID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 2 messages, QQQ and action item 2; the colliding SPY, settings and
+	// action item 1 rows are dropped
+	if moved != 4 {
+		t.Errorf("moved = %d, want 4", moved)
+	}
+
+	msgs, err := s.FetchMessages(newID, AllThreads, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var texts []string
+	for _, m := range msgs {
+		texts = append(texts, m.Text)
+	}
+	if want := []string{"old 1", "old 2", "new 1"}; !slices.Equal(texts, want) {
+		t.Errorf("messages of new chat = %q, want %q", texts, want)
+	}
+	if msgs, _ := s.FetchMessages(oldID, AllThreads, 0); len(msgs) != 0 {
+		t.Errorf("old chat still has %d messages", len(msgs))
+	}
+	if msgs, _ := s.FetchMessages(7, AllThreads, 0); len(msgs) != 1 {
+		t.Errorf("unrelated chat has %d messages, want 1", len(msgs))
+	}
+
+	list, err := s.FetchWatchlist(newID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	slices.Sort(list)
+	if want := []string{"QQQ", "SPY"}; !slices.Equal(list, want) {
+		t.Errorf("watchlist of new chat = %v, want %v", list, want)
+	}
+	if list, _ := s.FetchWatchlist(oldID); len(list) != 0 {
+		t.Errorf("old chat still has watchlist %v", list)
+	}
+
+	cs, err := s.FetchChatSettings(newID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cs.Theme != "light" {
+		t.Errorf("theme of new chat = %q, want light", cs.Theme)
+	}
+	if cs, _ := s.FetchChatSettings(oldID); cs.Theme != "" {
+		t.Errorf("old chat still has settings, theme %q", cs.Theme)
+	}
+
+	items, err := s.FetchOpenActionItems(newID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var tasks []string
+	for _, a := range items {
+		tasks = append(tasks, fmt.Sprint(a.Num, " ", a.Task))
+	}
+	if want := []string{"1 new 1", "2 old 2"}; !slices.Equal(tasks, want) {
+		t.Errorf("action items of new chat = %q, want %q", tasks, want)
+	}
+	if items, _ := s.FetchOpenActionItems(oldID); len(items) != 0 {
+		t.Errorf("old chat still has %d action items", len(items))
+	}
+}
+
+// TestChatTablesComplete fails when a table with a chat_id column is missing
+// from chatTables, which would leave its rows behind on a migration.
+func TestChatTablesComplete(t *testing.T) {
+	s := newTestStore(t)
+	rows, err := s.db.Query(`SELECT m.name FROM sqlite_master m JOIN pragma_table_info(m.name) c
+		WHERE m.type='table' AND c.name='chat_id'`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			t.Fatal(err)
+		}
+		if !slices.ContainsFunc(chatTables, func(c chatTable) bool { return c.name == name }) {
+			t.Errorf("table %s has a chat_id but is not in chatTables", name)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMigrateChatSameID(t *testing.T) {
+	s := newTestStore(t)
+	if err := s.SaveMessage(Message{ChatID: 1, Text: "x", Ts: 1}); err != nil {
+		t.Fatal(err)
+	}
+	moved, err := s.MigrateChat(1, 1)
+	if err != nil || moved != 0 {
+		t.Fatalf("MigrateChat(1, 1) = %d, %v; want 0, nil", moved, err)
+	}
+}
@@ -0,0 +1,75 @@
+package storage
+
+// initNameBackfillSchema creates the per-chat cursor of the user name
+// backfill: the highest user_id resolved so far, so a restart resumes there.
+func initNameBackfillSchema(db DB) error {
+	_, err := db.Exec(`CREATE TABLE IF NOT EXISTS name_backfill(
+		chat_id INTEGER PRIMARY KEY,
+		last_user_id INTEGER NOT NULL DEFAULT 0
+	)`)
+	return err
+}
+
+// FetchUnnamedChats returns the chats with stored messages from users
+// (positive IDs) that have no name yet, with each chat's backfill cursor.
+func (s *Store) FetchUnnamedChats() (map[int64]int64, error) {
+	rows, err := s.db.Query(`SELECT m.chat_id, COALESCE(MAX(b.last_user_id), 0)
+		FROM messages m LEFT JOIN name_backfill b ON b.chat_id = m.chat_id
+		WHERE m.user_name = '' AND m.user_id > COALESCE(b.last_user_id, 0)
+		GROUP BY m.chat_id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := map[int64]int64{}
+	for rows.Next() {
+		var chatID, cursor int64
+		if err := rows.Scan(&chatID, &cursor); err != nil {
+			return nil, err
+		}
+		out[chatID] = cursor
+	}
+	return out, rows.Err()
+}
+
+// FetchUnnamedUsers returns up to limit distinct user IDs above after whose
+// messages in chatID have no name, in ascending order.
+func (s *Store) FetchUnnamedUsers(chatID, after int64, limit int) ([]int64, error) {
+	rows, err := s.db.Query(`SELECT DISTINCT user_id FROM messages
+		WHERE chat_id=? AND user_name='' AND user_id>?
+		ORDER BY user_id LIMIT ?`, chatID, after, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		out = append(out, id)
+	}
+	return out, rows.Err()
+}
+
+// BackfillUserName names the user's unnamed messages in chatID and moves the
+// chat's backfill cursor to userID, in one transaction. Messages that already
+// carry a name are left alone.
+func (s *Store) BackfillUserName(chatID, userID int64, name string) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if _, err := tx.Exec(`UPDATE messages SET user_name=? WHERE chat_id=? AND user_id=? AND user_name=''`,
+		name, chatID, userID); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`INSERT INTO name_backfill(chat_id,last_user_id) VALUES(?,?)
+		ON CONFLICT(chat_id) DO UPDATE SET last_user_id=excluded.last_user_id`,
+		chatID, userID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,51 @@
+package storage
+
+// PaperTrade is a simulated fill in a chat's /paper book; Qty is negative for sells.
+type PaperTrade struct {
+	ID     int64
+	ChatID int64
+	UserID int64
+	Symbol string
+	Qty    float64
+	Price  float64
+	TS     int64
+}
+
+func initPaperSchema(db DB) error {
+	_, err := db.Exec(`CREATE TABLE IF NOT EXISTS paper_trades(
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		chat_id INTEGER NOT NULL,
+		user_id INTEGER NOT NULL,
+		symbol TEXT NOT NULL,
+		qty REAL NOT NULL,
+		price REAL NOT NULL,
+		ts INTEGER NOT NULL
+	)`)
+	return err
+}
+
+// SavePaperTrade records a fill at the quoted price.
+func (s *Store) SavePaperTrade(t PaperTrade) error {
+	_, err := s.db.Exec(`INSERT INTO paper_trades(chat_id,user_id,symbol,qty,price,ts) VALUES(?,?,?,?,?,?)`,
+		t.ChatID, t.UserID, t.Symbol, t.Qty, t.Price, t.TS)
+	return err
+}
+
+// FetchPaperTrades returns the chat's fills in execution order.
+func (s *Store) FetchPaperTrades(chatID int64) ([]PaperTrade, error) {
+	rows, err := s.db.Query(`SELECT id, chat_id, user_id, symbol, qty, price, ts
+		FROM paper_trades WHERE chat_id=? ORDER BY ts, id`, chatID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []PaperTrade
+	for rows.Next() {
+		var t PaperTrade
+		if err := rows.Scan(&t.ID, &t.ChatID, &t.UserID, &t.Symbol, &t.Qty, &t.Price, &t.TS); err != nil {
+			return nil, err
+		}
+		out = append(out, t)
+	}
+	return out, rows.Err()
+}
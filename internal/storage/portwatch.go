@@ -0,0 +1,159 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// PortfolioWatch is a named weighted portfolio a chat follows for drawdowns.
+// Values start at 100 from the Base prices when the watch was set.
+type PortfolioWatch struct {
+	ID        int64
+	ChatID    int64
+	Name      string
+	Symbols   []string
+	Weights   []float64
+	Base      []float64 // price of each symbol when the watch was set
+	Threshold float64   // drawdown from the peak that alerts, in percent
+	Value     float64   // at the last valuation
+	Peak      float64   // running high of Value
+	ValuedDay string    // trading day of the last valuation, YYYY-MM-DD; "" before the first
+	AlertedAt int64     // set while the current breach has been alerted, 0 otherwise
+	CreatedAt int64
+}
+
+func initPortfolioWatchSchema(db DB) error {
+	_, err := db.Exec(`CREATE TABLE IF NOT EXISTS portfolio_watches(
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		chat_id INTEGER NOT NULL,
+		name TEXT NOT NULL,
+		holdings TEXT NOT NULL,
+		threshold REAL NOT NULL,
+		value REAL NOT NULL,
+		peak REAL NOT NULL,
+		valued_day TEXT NOT NULL DEFAULT '',
+		alerted_at INTEGER NOT NULL DEFAULT 0,
+		created_at INTEGER NOT NULL,
+		UNIQUE(chat_id, name)
+	)`)
+	return err
+}
+
+// encodeHoldings stores each holding as "SYMBOL WEIGHT BASE", space separated.
+func encodeHoldings(w PortfolioWatch) string {
+	parts := make([]string, 0, 3*len(w.Symbols))
+	for i, sym := range w.Symbols {
+		parts = append(parts, sym, strconv.FormatFloat(w.Weights[i], 'g', -1, 64), strconv.FormatFloat(w.Base[i], 'g', -1, 64))
+	}
+	return strings.Join(parts, " ")
+}
+
+func decodeHoldings(w *PortfolioWatch, s string) error {
+	f := strings.Fields(s)
+	if len(f)%3 != 0 {
+		return fmt.Errorf("portfolio watch %d: malformed holdings %q", w.ID, s)
+	}
+	for i := 0; i < len(f); i += 3 {
+		weight, err := strconv.ParseFloat(f[i+1], 64)
+		if err != nil {
+			return err
+		}
+		base, err := strconv.ParseFloat(f[i+2], 64)
+		if err != nil {
+			return err
+		}
+		w.Symbols = append(w.Symbols, f[i])
+		w.Weights = append(w.Weights, weight)
+		w.Base = append(w.Base, base)
+	}
+	return nil
+}
+
+const portfolioWatchColumns = `id, chat_id, name, holdings, threshold, value, peak, valued_day, alerted_at, created_at`
+
+func scanPortfolioWatches(rows *sql.Rows) ([]PortfolioWatch, error) {
+	var out []PortfolioWatch
+	for rows.Next() {
+		var w PortfolioWatch
+		var holdings string
+		if err := rows.Scan(&w.ID, &w.ChatID, &w.Name, &holdings, &w.Threshold, &w.Value, &w.Peak,
+			&w.ValuedDay, &w.AlertedAt, &w.CreatedAt); err != nil {
+			return nil, err
+		}
+		if err := decodeHoldings(&w, holdings); err != nil {
+			return nil, err
+		}
+		out = append(out, w)
+	}
+	return out, rows.Err()
+}
+
+// SavePortfolioWatch stores w under its name in the chat, replacing a watch
+// of the same name and starting its value, peak and alert state afresh. It
+// reports whether an existing watch was replaced.
+func (s *Store) SavePortfolioWatch(w PortfolioWatch) (bool, error) {
+	res, err := s.db.Exec(`UPDATE portfolio_watches SET holdings=?, threshold=?, value=?, peak=?, valued_day='', alerted_at=0, created_at=?
+		WHERE chat_id=? AND name=?`,
+		encodeHoldings(w), w.Threshold, w.Value, w.Peak, w.CreatedAt, w.ChatID, w.Name)
+	if err != nil {
+		return false, err
+	}
+	if n, _ := res.RowsAffected(); n > 0 {
+		return true, nil
+	}
+	_, err = s.db.Exec(`INSERT INTO portfolio_watches(chat_id,name,holdings,threshold,value,peak,created_at) VALUES(?,?,?,?,?,?,?)`,
+		w.ChatID, w.Name, encodeHoldings(w), w.Threshold, w.Value, w.Peak, w.CreatedAt)
+	return false, err
+}
+
+// SetPortfolioWatchThreshold changes the alert threshold of the chat's watch
+// name and re-arms its alert. It reports false when there is no such watch.
+func (s *Store) SetPortfolioWatchThreshold(chatID int64, name string, threshold float64) (bool, error) {
+	res, err := s.db.Exec(`UPDATE portfolio_watches SET threshold=?, alerted_at=0 WHERE chat_id=? AND name=?`, threshold, chatID, name)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+// FetchPortfolioWatches returns the chat's portfolio watches by name.
+func (s *Store) FetchPortfolioWatches(chatID int64) ([]PortfolioWatch, error) {
+	rows, err := s.db.Query(`SELECT `+portfolioWatchColumns+` FROM portfolio_watches WHERE chat_id=? ORDER BY name`, chatID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanPortfolioWatches(rows)
+}
+
+// FetchUnvaluedPortfolioWatches returns the watches across chats not yet
+// valued on day.
+func (s *Store) FetchUnvaluedPortfolioWatches(day string) ([]PortfolioWatch, error) {
+	rows, err := s.db.Query(`SELECT `+portfolioWatchColumns+` FROM portfolio_watches WHERE valued_day<>? ORDER BY id`, day)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanPortfolioWatches(rows)
+}
+
+// SavePortfolioValuation records a watch's valuation on day.
+func (s *Store) SavePortfolioValuation(id int64, value, peak float64, day string, alertedAt int64) error {
+	_, err := s.db.Exec(`UPDATE portfolio_watches SET value=?, peak=?, valued_day=?, alerted_at=? WHERE id=?`,
+		value, peak, day, alertedAt, id)
+	return err
+}
+
+// DeletePortfolioWatch removes the chat's watch name. It reports false when
+// there is no such watch.
+func (s *Store) DeletePortfolioWatch(chatID int64, name string) (bool, error) {
+	res, err := s.db.Exec(`DELETE FROM portfolio_watches WHERE chat_id=? AND name=?`, chatID, name)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
@@ -0,0 +1,70 @@
+package storage
+
+// Recommendation is one position a /recommend answer suggested.
+type Recommendation struct {
+	ID     int64
+	ChatID int64
+	UserID int64
+	Symbol string
+	Short  bool
+	Thesis string // the /recommend text it answered
+	TS     int64
+}
+
+func initRecommendationsSchema(db DB) error {
+	_, err := db.Exec(`CREATE TABLE IF NOT EXISTS recommendations(
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		chat_id INTEGER NOT NULL,
+		user_id INTEGER NOT NULL,
+		symbol TEXT NOT NULL,
+		direction TEXT NOT NULL,
+		thesis TEXT NOT NULL DEFAULT '',
+		ts INTEGER NOT NULL
+	)`)
+	return err
+}
+
+func direction(short bool) string {
+	if short {
+		return "short"
+	}
+	return "long"
+}
+
+// SaveRecommendations records the positions of one answer in a transaction.
+func (s *Store) SaveRecommendations(recs []Recommendation) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	for _, r := range recs {
+		if _, err := tx.Exec(`INSERT INTO recommendations(chat_id,user_id,symbol,direction,thesis,ts) VALUES(?,?,?,?,?,?)`,
+			r.ChatID, r.UserID, r.Symbol, direction(r.Short), r.Thesis, r.TS); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// FetchRecommendations returns the chat's recommended positions made since
+// the given Unix time, newest first.
+func (s *Store) FetchRecommendations(chatID, since int64) ([]Recommendation, error) {
+	rows, err := s.db.Query(`SELECT id, chat_id, user_id, symbol, direction, thesis, ts
+		FROM recommendations WHERE chat_id=? AND ts>=? ORDER BY ts DESC, id`, chatID, since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []Recommendation
+	for rows.Next() {
+		var r Recommendation
+		var dir string
+		if err := rows.Scan(&r.ID, &r.ChatID, &r.UserID, &r.Symbol, &dir, &r.Thesis, &r.TS); err != nil {
+			return nil, err
+		}
+		r.Short = dir == "short"
+		out = append(out, r)
+	}
+	return out, rows.Err()
+}
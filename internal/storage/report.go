@@ -0,0 +1,79 @@
+package storage
+
+// ChatCount is the number of commands one chat ran.
+type ChatCount struct {
+	ChatID int64
+	Count  int
+}
+
+// UsageReport aggregates command usage across every chat for since <= ts < until.
+type UsageReport struct {
+	Since, Until int64
+	Total        int
+	ActiveChats  int
+	Categories   map[string]int
+	Bots         map[string]int // commands per bot id ("" = primary)
+	TopChats     []ChatCount    // busiest first, at most the requested number
+}
+
+// FetchUsageReport aggregates command_usage over all chats, unlike the
+// per-chat /usage queries.
+func (s *Store) FetchUsageReport(since, until int64, topChats int) (UsageReport, error) {
+	r := UsageReport{Since: since, Until: until, Categories: map[string]int{}, Bots: map[string]int{}}
+	rows, err := s.db.Query(`SELECT category, COUNT(*) FROM command_usage
+		WHERE ts>=? AND ts<? GROUP BY category`, since, until)
+	if err != nil {
+		return r, err
+	}
+	for rows.Next() {
+		var category string
+		var n int
+		if err := rows.Scan(&category, &n); err != nil {
+			rows.Close()
+			return r, err
+		}
+		r.Categories[category] = n
+		r.Total += n
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return r, err
+	}
+
+	rows, err = s.db.Query(`SELECT bot_id, COUNT(*) FROM command_usage
+		WHERE ts>=? AND ts<? GROUP BY bot_id`, since, until)
+	if err != nil {
+		return r, err
+	}
+	for rows.Next() {
+		var bot string
+		var n int
+		if err := rows.Scan(&bot, &n); err != nil {
+			rows.Close()
+			return r, err
+		}
+		r.Bots[bot] = n
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return r, err
+	}
+
+	rows, err = s.db.Query(`SELECT chat_id, COUNT(*) AS n FROM command_usage
+		WHERE ts>=? AND ts<? GROUP BY chat_id ORDER BY n DESC, chat_id`, since, until)
+	if err != nil {
+		return r, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var c ChatCount
+		if err := rows.Scan(&c.ChatID, &c.Count); err != nil {
+			return r, err
+		}
+		r.ActiveChats++
+		if len(r.TopChats) < topChats {
+			r.TopChats = append(r.TopChats, c)
+		}
+	}
+	return r, rows.Err()
+}
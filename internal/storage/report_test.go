@@ -0,0 +1,52 @@
+package storage
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFetchUsageReport(t *testing.T) {
+	s := newTestStore(t)
+	beta := s.ForBot("beta")
+	for _, u := range []struct {
+		s        *Store
+		chatID   int64
+		category string
+		ts       int64
+	}{
+		{s, 1, "charts", 100},
+		{s, 1, "charts", 150},
+		{s, 1, "summarizer", 199},
+		{s, 2, "charts", 120},
+		{beta, 3, "charts", 130},
+		{beta, 3, "charts", 140},
+		{s, 4, "charts", 99},  // before since
+		{s, 4, "charts", 200}, // until is exclusive
+	} {
+		if _, err := u.s.SaveCommandUsage(CommandUsage{ChatID: u.chatID, Command: u.category, Category: u.category, Timestamp: u.ts}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	got, err := s.FetchUsageReport(100, 200, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := UsageReport{
+		Since: 100, Until: 200, Total: 6, ActiveChats: 3,
+		Categories: map[string]int{"charts": 5, "summarizer": 1},
+		Bots:       map[string]int{"": 4, "beta": 2},
+		TopChats:   []ChatCount{{ChatID: 1, Count: 3}, {ChatID: 3, Count: 2}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("FetchUsageReport = %+v, want %+v", got, want)
+	}
+
+	// the previous week is fetched without a chat list
+	prev, err := s.FetchUsageReport(0, 100, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if prev.Total != 1 || prev.ActiveChats != 1 || len(prev.TopChats) != 0 {
+		t.Errorf("previous week = %+v, want one command in one chat and no chat list", prev)
+	}
+}
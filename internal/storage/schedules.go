@@ -0,0 +1,72 @@
+package storage
+
+// Schedule is a command the bot posts into a chat at a fixed local time.
+type Schedule struct {
+	ID      int64
+	ChatID  int64
+	Spec    string // "HH:MM mon-fri"
+	Command string // command text run through the normal handlers, e.g. "/stock SPY 1d"
+	LastRun int64  // unix time of the last run (0 = never)
+}
+
+func initSchedulesSchema(db DB) error {
+	_, err := db.Exec(`CREATE TABLE IF NOT EXISTS schedules(
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		chat_id INTEGER NOT NULL,
+		spec TEXT NOT NULL,
+		command TEXT NOT NULL,
+		created_at INTEGER NOT NULL,
+		last_run INTEGER NOT NULL DEFAULT 0
+	)`)
+	return err
+}
+
+// AddSchedule stores a schedule for chatID and returns its ID.
+func (s *Store) AddSchedule(chatID int64, spec, command string, ts int64) (int64, error) {
+	res, err := s.db.Exec(`INSERT INTO schedules(chat_id,spec,command,created_at) VALUES(?,?,?,?)`,
+		chatID, spec, command, ts)
+	if err != nil {
+		return 0, err
+	}
+	return res.LastInsertId()
+}
+
+// FetchSchedules returns the schedules of chatID, or of every chat when chatID is 0.
+func (s *Store) FetchSchedules(chatID int64) ([]Schedule, error) {
+	q := `SELECT id, chat_id, spec, command, last_run FROM schedules`
+	var args []any
+	if chatID != 0 {
+		q += ` WHERE chat_id=?`
+		args = append(args, chatID)
+	}
+	rows, err := s.db.Query(q+` ORDER BY id`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []Schedule
+	for rows.Next() {
+		var sc Schedule
+		if err := rows.Scan(&sc.ID, &sc.ChatID, &sc.Spec, &sc.Command, &sc.LastRun); err != nil {
+			return nil, err
+		}
+		out = append(out, sc)
+	}
+	return out, rows.Err()
+}
+
+// DeleteSchedule removes schedule id from chatID. It reports false when the chat has no such schedule.
+func (s *Store) DeleteSchedule(chatID, id int64) (bool, error) {
+	res, err := s.db.Exec(`DELETE FROM schedules WHERE id=? AND chat_id=?`, id, chatID)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+// MarkScheduleRun records that schedule id ran at ts.
+func (s *Store) MarkScheduleRun(id, ts int64) error {
+	_, err := s.db.Exec(`UPDATE schedules SET last_run=? WHERE id=?`, ts, id)
+	return err
+}
@@ -0,0 +1,57 @@
+package storage
+
+import "strings"
+
+// initSearchSchema creates messages_fts, a full-text index over message text
+// kept in sync by triggers. It is built once from the existing messages the
+// first time it is created.
+func initSearchSchema(db DB) error {
+	rows, err := db.Query(`SELECT 1 FROM sqlite_master WHERE type='table' AND name='messages_fts'`)
+	if err != nil {
+		return err
+	}
+	exists := rows.Next()
+	rows.Close()
+	if exists {
+		return nil
+	}
+	for _, stmt := range []string{
+		`CREATE VIRTUAL TABLE messages_fts USING fts4(content="messages", text)`,
+		`CREATE TRIGGER messages_fts_ai AFTER INSERT ON messages BEGIN
+			INSERT INTO messages_fts(docid, text) VALUES(new.rowid, new.text);
+		END`,
+		`CREATE TRIGGER messages_fts_bd BEFORE DELETE ON messages BEGIN
+			DELETE FROM messages_fts WHERE docid=old.rowid;
+		END`,
+		`CREATE TRIGGER messages_fts_bu BEFORE UPDATE OF text ON messages BEGIN
+			DELETE FROM messages_fts WHERE docid=old.rowid;
+		END`,
+		`CREATE TRIGGER messages_fts_au AFTER UPDATE OF text ON messages BEGIN
+			INSERT INTO messages_fts(docid, text) VALUES(new.rowid, new.text);
+		END`,
+		`INSERT INTO messages_fts(messages_fts) VALUES('rebuild')`,
+	} {
+		if _, err := db.Exec(stmt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SearchMessages returns up to limit of chatID's messages before ts that
+// contain any of the terms, newest first, limited to one forum topic unless
+// threadID is AllThreads.
+func (s *Store) SearchMessages(chatID int64, threadID int, terms []string, before int64, limit int) ([]Message, error) {
+	if len(terms) == 0 {
+		return nil, nil
+	}
+	quoted := make([]string, len(terms))
+	for i, t := range terms {
+		quoted[i] = `"` + strings.ReplaceAll(t, `"`, ``) + `"`
+	}
+	return s.queryMessages(`SELECT m.chat_id, m.thread_id, m.message_id, m.reply_to, m.user_id, m.user_name, m.text, m.ts
+		FROM messages_fts f JOIN messages m ON m.rowid=f.docid
+		WHERE messages_fts MATCH ? AND m.chat_id=? AND (?<0 OR m.thread_id=?) AND m.ts<?
+		ORDER BY m.ts DESC LIMIT ?`,
+		strings.Join(quoted, " OR "), chatID, threadID, threadID, before, limit)
+}
@@ -0,0 +1,120 @@
+package storage
+
+import (
+	"fmt"
+)
+
+// ChatSettings holds per-chat preferences changed through /set.
+type ChatSettings struct {
+	ChatID int64
+	// SourceChannel is a channel whose stored posts /summary channel reads (0 = none)
+	SourceChannel int64
+	// Chart defaults applied when a command omits the argument ("" = built-in default)
+	DefaultWindow   string
+	DefaultInterval string
+	Theme           string
+	// Timezone is an IANA zone name for chart labels ("" = America/New_York)
+	Timezone string
+	// MoversAuto is the intraday move in percent that triggers a watchlist alert (0 = off)
+	MoversAuto float64
+	// StoreMessages keeps chat text for /summary (default true; false is privacy mode)
+	StoreMessages bool
+	// AutoPin pins the scheduled morning brief (default true; turned off when the bot lacks the right)
+	AutoPin bool
+	// PinnedMessageID is the brief the bot last pinned, unpinned before the next one (0 = none)
+	PinnedMessageID int
+	// TranslateLang is the default /translate target language ("" = English)
+	TranslateLang string
+	// Cashtags is the automatic reply to $TICKER mentions: "" (off), "quote" or "chart"
+	Cashtags string
+}
+
+// chatSettingColumns whitelists the columns SetChatSetting may write, so the
+// column name can be interpolated into SQL safely.
+var chatSettingColumns = map[string]bool{
+	"source_channel":    true,
+	"default_window":    true,
+	"default_interval":  true,
+	"theme":             true,
+	"timezone":          true,
+	"movers_auto":       true,
+	"store_messages":    true,
+	"auto_pin":          true,
+	"pinned_message_id": true,
+	"translate_lang":    true,
+	"cashtags":          true,
+}
+
+func initSettingsSchema(db DB) error {
+	if _, err := db.Exec(`CREATE TABLE IF NOT EXISTS chat_settings(
+		chat_id INTEGER PRIMARY KEY,
+		source_channel INTEGER NOT NULL DEFAULT 0
+	)`); err != nil {
+		return err
+	}
+	for _, col := range []string{"default_window", "default_interval", "theme", "timezone", "translate_lang", "cashtags"} {
+		if err := addColumn(db, "chat_settings", col, "TEXT NOT NULL DEFAULT ''"); err != nil {
+			return err
+		}
+	}
+	if err := addColumn(db, "chat_settings", "movers_auto", "REAL NOT NULL DEFAULT 0"); err != nil {
+		return err
+	}
+	if err := addColumn(db, "chat_settings", "store_messages", "INTEGER NOT NULL DEFAULT 1"); err != nil {
+		return err
+	}
+	if err := addColumn(db, "chat_settings", "auto_pin", "INTEGER NOT NULL DEFAULT 1"); err != nil {
+		return err
+	}
+	return addColumn(db, "chat_settings", "pinned_message_id", "INTEGER NOT NULL DEFAULT 0")
+}
+
+// FetchChatSettings returns the settings for chatID, or defaults when none are stored.
+// On error the returned settings have StoreMessages false, so a failing lookup
+// never stores messages for a chat that opted out.
+func (s *Store) FetchChatSettings(chatID int64) (ChatSettings, error) {
+	cs := ChatSettings{ChatID: chatID, StoreMessages: true, AutoPin: true}
+	rows, err := s.db.Query(`SELECT source_channel, default_window, default_interval, theme, timezone, movers_auto, store_messages, auto_pin, pinned_message_id, translate_lang, cashtags
+		FROM chat_settings WHERE chat_id=?`, chatID)
+	if err != nil {
+		return ChatSettings{ChatID: chatID}, err
+	}
+	defer rows.Close()
+	if rows.Next() {
+		if err := rows.Scan(&cs.SourceChannel, &cs.DefaultWindow, &cs.DefaultInterval, &cs.Theme, &cs.Timezone, &cs.MoversAuto, &cs.StoreMessages, &cs.AutoPin, &cs.PinnedMessageID, &cs.TranslateLang, &cs.Cashtags); err != nil {
+			return ChatSettings{ChatID: chatID}, err
+		}
+	}
+	return cs, rows.Err()
+}
+
+// FetchMoversAutoChats returns the chats with automatic movers alerts enabled
+// and their thresholds.
+func (s *Store) FetchMoversAutoChats() (map[int64]float64, error) {
+	rows, err := s.db.Query(`SELECT chat_id, movers_auto FROM chat_settings WHERE movers_auto > 0`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := map[int64]float64{}
+	for rows.Next() {
+		var chatID int64
+		var threshold float64
+		if err := rows.Scan(&chatID, &threshold); err != nil {
+			return nil, err
+		}
+		out[chatID] = threshold
+	}
+	return out, rows.Err()
+}
+
+// SetChatSetting upserts a single settings column for chatID.
+func (s *Store) SetChatSetting(chatID int64, column string, value any) error {
+	if !chatSettingColumns[column] {
+		return fmt.Errorf("unknown chat setting %q", column)
+	}
+	_, err := s.db.Exec(`INSERT INTO chat_settings(chat_id,`+column+`) VALUES(?,?)
+		ON CONFLICT(chat_id) DO UPDATE SET `+column+`=excluded.`+column,
+		chatID, value)
+	return err
+}
@@ -1,7 +1,13 @@
 package storage
 
 import (
+	"context"
 	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
 	"time"
 
 	// Register sqlite3 driver
@@ -11,10 +17,14 @@ import (
 type DB interface {
 	Exec(query string, args ...any) (sql.Result, error)
 	Query(query string, args ...any) (*sql.Rows, error)
+	Begin() (*sql.Tx, error)
 	Close() error
 }
 
-type Store struct{ db DB }
+type Store struct {
+	db  DB
+	bot string // see ForBot
+}
 
 func OpenSQLite(dsn string) (DB, error) {
 	return sql.Open("sqlite3", dsn)
@@ -28,58 +38,358 @@ func InitSchema(db DB) error {
 		return err
 	}
 
+	// Forum topic of each message (0 = General topic or a chat without topics)
+	if err := addColumn(db, "messages", "thread_id", "INTEGER NOT NULL DEFAULT 0"); err != nil {
+		return err
+	}
+	// Telegram message IDs and sender names let summaries show who replied to what
+	for _, col := range []string{"message_id", "reply_to"} {
+		if err := addColumn(db, "messages", col, "INTEGER NOT NULL DEFAULT 0"); err != nil {
+			return err
+		}
+	}
+	if err := addColumn(db, "messages", "user_name", "TEXT NOT NULL DEFAULT ''"); err != nil {
+		return err
+	}
+
 	// Create command_usage table for analytics
-	_, err := db.Exec(`CREATE TABLE IF NOT EXISTS command_usage(
+	if _, err := db.Exec(`CREATE TABLE IF NOT EXISTS command_usage(
 		id INTEGER PRIMARY KEY AUTOINCREMENT,
 		chat_id INTEGER,
 		user_id INTEGER,
 		command TEXT,
 		category TEXT,
 		ts INTEGER
-	)`)
-	return err
+	)`); err != nil {
+		return err
+	}
+
+	// Normalized arguments of each command, used by /history to re-run it
+	if err := addColumn(db, "command_usage", "args", "TEXT NOT NULL DEFAULT ''"); err != nil {
+		return err
+	}
+	// Tickers a chart or portfolio command resolved, space-separated, for /usage
+	if err := addColumn(db, "command_usage", "symbols", "TEXT NOT NULL DEFAULT ''"); err != nil {
+		return err
+	}
+	// Bot that handled the command when one process serves several ('' = primary)
+	if err := addColumn(db, "command_usage", "bot_id", "TEXT NOT NULL DEFAULT ''"); err != nil {
+		return err
+	}
+	// How long the handler took, NULL until it returns
+	if err := addColumn(db, "command_usage", "duration_ms", "INTEGER"); err != nil {
+		return err
+	}
+
+	// Highest processed update_id per chat, used to drop redelivered updates
+	if _, err := db.Exec(`CREATE TABLE IF NOT EXISTS update_offsets(
+		chat_id INTEGER PRIMARY KEY,
+		last_update_id INTEGER
+	)`); err != nil {
+		return err
+	}
+	if err := scopeUpdateOffsets(db); err != nil {
+		return err
+	}
+
+	// feature tables live next to their store methods
+	for _, init := range []func(DB) error{initSettingsSchema, initFeedbackSchema, initSchedulesSchema, initWatchlistSchema, initAlertLogSchema, initTargetsSchema, initPaperSchema, initCalendarSchema, initAliasesSchema, initSearchSchema, initSummariesSchema, initNameBackfillSchema, initChatsSchema, initPortfolioWatchSchema, initRecommendationsSchema, initActionItemsSchema} {
+		if err := init(db); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 func NewStore(db DB) *Store { return &Store{db: db} }
 
-func (s *Store) SaveMessage(chatID, userID int64, text string, ts int64) error {
-	_, err := s.db.Exec(`INSERT INTO messages(chat_id,user_id,text,ts) VALUES(?,?,?,?)`,
-		chatID, userID, text, ts)
+// ForBot returns a store over the same database that records command usage
+// and update offsets under the bot id, for processes serving several bots.
+// Everything else is shared, as it is keyed by chat. The primary bot uses "".
+func (s *Store) ForBot(id string) *Store { return &Store{db: s.db, bot: id} }
+
+// Ping runs a trivial query to confirm the database file is readable.
+func (s *Store) Ping(ctx context.Context) error {
+	rows, err := s.db.Query(`SELECT 1`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return err
+		}
+		return errors.New("SELECT 1 returned no rows")
+	}
+	return ctx.Err()
+}
+
+// DBSize returns the size of the database file in bytes, from its page count.
+func (s *Store) DBSize() (int64, error) {
+	rows, err := s.db.Query(`SELECT page_count * page_size FROM pragma_page_count(), pragma_page_size()`)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+	var n int64
+	if rows.Next() {
+		if err := rows.Scan(&n); err != nil {
+			return 0, err
+		}
+	}
+	return n, rows.Err()
+}
+
+// CheckWrite inserts and reads back a sentinel row inside a transaction that
+// is always rolled back, confirming the database file is writable.
+func (s *Store) CheckWrite(ctx context.Context) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	const sentinel = -1
+	if _, err := tx.ExecContext(ctx, `INSERT OR REPLACE INTO update_offsets(chat_id,last_update_id) VALUES(0,?)`, sentinel); err != nil {
+		return fmt.Errorf("write: %w", err)
+	}
+	var got int64
+	if err := tx.QueryRowContext(ctx, `SELECT last_update_id FROM update_offsets WHERE bot_id='' AND chat_id=0`).Scan(&got); err != nil {
+		return fmt.Errorf("read back: %w", err)
+	}
+	if got != sentinel {
+		return fmt.Errorf("read back %d, want %d", got, sentinel)
+	}
+	return nil
+}
+
+// AllThreads makes FetchMessages read every forum topic of a chat.
+const AllThreads = -1
+
+// Message is one stored chat message.
+type Message struct {
+	ChatID    int64
+	ThreadID  int // forum topic (0 = General or no topics)
+	MessageID int // Telegram message_id (0 for rows stored before it was kept)
+	ReplyTo   int // message_id this one replies to (0 = not a reply)
+	UserID    int64
+	UserName  string
+	Text      string
+	Ts        int64
+}
+
+func (s *Store) SaveMessage(m Message) error {
+	_, err := s.db.Exec(`INSERT INTO messages(chat_id,user_id,user_name,thread_id,message_id,reply_to,text,ts) VALUES(?,?,?,?,?,?,?,?)`,
+		m.ChatID, m.UserID, m.UserName, m.ThreadID, m.MessageID, m.ReplyTo, m.Text, m.Ts)
 	return err
 }
 
-func (s *Store) FetchMessages(chatID int64, since int64) ([]string, error) {
-	rows, err := s.db.Query(`SELECT text FROM messages WHERE chat_id=? AND ts>=? ORDER BY ts ASC`,
-		chatID, since)
+// DeleteMessages removes every stored message of chatID and returns how many were deleted.
+func (s *Store) DeleteMessages(chatID int64) (int64, error) {
+	res, err := s.db.Exec(`DELETE FROM messages WHERE chat_id=?`, chatID)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// FetchMessages returns chatID's messages since ts, limited to one forum topic
+// unless threadID is AllThreads.
+func (s *Store) FetchMessages(chatID int64, threadID int, since int64) ([]Message, error) {
+	return s.queryMessages(`SELECT chat_id, thread_id, message_id, reply_to, user_id, user_name, text, ts
+		FROM messages WHERE chat_id=? AND (?<0 OR thread_id=?) AND ts>=? ORDER BY ts ASC`,
+		chatID, threadID, threadID, since)
+}
+
+// FetchMessagesByID returns the stored messages of chatID with the given
+// Telegram message IDs; IDs that were never stored are skipped.
+func (s *Store) FetchMessagesByID(chatID int64, ids []int) ([]Message, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	args := []any{chatID}
+	for _, id := range ids {
+		args = append(args, id)
+	}
+	return s.queryMessages(`SELECT chat_id, thread_id, message_id, reply_to, user_id, user_name, text, ts
+		FROM messages WHERE chat_id=? AND message_id IN (?`+strings.Repeat(",?", len(ids)-1)+`)`, args...)
+}
+
+func (s *Store) queryMessages(query string, args ...any) ([]Message, error) {
+	rows, err := s.db.Query(query, args...)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var out []string
+	var out []Message
 	for rows.Next() {
-		var t string
-		if err := rows.Scan(&t); err == nil && t != "" {
-			out = append(out, t)
+		var m Message
+		if err := rows.Scan(&m.ChatID, &m.ThreadID, &m.MessageID, &m.ReplyTo, &m.UserID, &m.UserName, &m.Text, &m.Ts); err == nil && m.Text != "" {
+			out = append(out, m)
 		}
 	}
 	return out, nil
 }
 
+// KnownChat reports whether the bot has seen chatID, using the same sources
+// as ListChatIDs.
+func (s *Store) KnownChat(chatID int64) (bool, error) {
+	rows, err := s.db.Query(`SELECT 1 FROM messages WHERE chat_id=?
+		UNION SELECT 1 FROM command_usage WHERE chat_id=?
+		UNION SELECT 1 FROM chat_settings WHERE chat_id=?
+		LIMIT 1`, chatID, chatID, chatID)
+	if err != nil {
+		return false, err
+	}
+	defer rows.Close()
+	return rows.Next(), rows.Err()
+}
+
+// ListChatIDs returns every chat the bot has seen, from stored messages,
+// command usage and chat settings.
+func (s *Store) ListChatIDs() ([]int64, error) {
+	rows, err := s.db.Query(`SELECT chat_id FROM messages
+		UNION SELECT chat_id FROM command_usage
+		UNION SELECT chat_id FROM chat_settings
+		ORDER BY chat_id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		if id != 0 {
+			out = append(out, id)
+		}
+	}
+	return out, rows.Err()
+}
+
 // CommandUsage represents a command usage record
 type CommandUsage struct {
 	Command   string
+	Args      string   // arguments after the command, whitespace-normalized
+	Symbols   []string // upper-cased tickers a chart or portfolio command named
 	Category  string
 	ChatID    int64
 	UserID    int64
 	Timestamp int64
 }
 
-// SaveCommandUsage tracks command usage for analytics
-func (s *Store) SaveCommandUsage(chatID, userID int64, command, category string) error {
-	ts := time.Now().Unix()
-	_, err := s.db.Exec(`INSERT INTO command_usage(chat_id,user_id,command,category,ts) VALUES(?,?,?,?,?)`,
-		chatID, userID, command, category, ts)
-	return err
+// SaveCommandUsage tracks command usage for analytics and returns the row's
+// id, for SetCommandDuration. A zero Timestamp is now.
+func (s *Store) SaveCommandUsage(u CommandUsage) (int64, error) {
+	if u.Timestamp == 0 {
+		u.Timestamp = time.Now().Unix()
+	}
+	res, err := s.db.Exec(`INSERT INTO command_usage(chat_id,user_id,command,category,args,symbols,ts,bot_id) VALUES(?,?,?,?,?,?,?,?)`,
+		u.ChatID, u.UserID, u.Command, u.Category, u.Args, strings.Join(u.Symbols, " "), u.Timestamp, s.bot)
+	if err != nil {
+		return 0, err
+	}
+	return res.LastInsertId()
+}
+
+// SymbolCount is how often one ticker was charted.
+type SymbolCount struct {
+	Symbol string
+	Count  int
+}
+
+// FetchTopSymbols returns the chat's most-charted tickers since the unix time
+// since, most frequent first, at most limit of them. A command naming several
+// tickers counts once for each.
+func (s *Store) FetchTopSymbols(chatID int64, since int64, limit int) ([]SymbolCount, error) {
+	rows, err := s.db.Query(`SELECT symbols FROM command_usage
+		WHERE chat_id=? AND ts>=? AND bot_id=? AND symbols!=''`, chatID, since, s.bot)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	counts := map[string]int{}
+	for rows.Next() {
+		var symbols string
+		if err := rows.Scan(&symbols); err != nil {
+			return nil, err
+		}
+		for _, sym := range strings.Fields(symbols) {
+			counts[sym]++
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	out := make([]SymbolCount, 0, len(counts))
+	for sym, n := range counts {
+		out = append(out, SymbolCount{Symbol: sym, Count: n})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Count != out[j].Count {
+			return out[i].Count > out[j].Count
+		}
+		return out[i].Symbol < out[j].Symbol
+	})
+	if len(out) > limit {
+		out = out[:limit]
+	}
+	return out, nil
+}
+
+// CountCommandsSince returns how many times the chat used any of the given
+// commands of this bot since the unix time since.
+func (s *Store) CountCommandsSince(chatID int64, commands []string, since int64) (int, error) {
+	if len(commands) == 0 {
+		return 0, nil
+	}
+	args := []any{chatID, since, s.bot}
+	for _, c := range commands {
+		args = append(args, c)
+	}
+	rows, err := s.db.Query(`SELECT COUNT(*) FROM command_usage
+		WHERE chat_id=? AND ts>=? AND bot_id=? AND command IN (?`+strings.Repeat(",?", len(commands)-1)+`)`, args...)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+	var n int
+	if rows.Next() {
+		if err := rows.Scan(&n); err != nil {
+			return 0, err
+		}
+	}
+	return n, rows.Err()
+}
+
+// FetchRecentCommands returns the chat's latest uses of the given commands of
+// this bot, newest first.
+func (s *Store) FetchRecentCommands(chatID int64, commands []string, limit int) ([]CommandUsage, error) {
+	if len(commands) == 0 {
+		return nil, nil
+	}
+	args := []any{chatID, s.bot}
+	for _, c := range commands {
+		args = append(args, c)
+	}
+	args = append(args, limit)
+	rows, err := s.db.Query(`SELECT command, args, category, user_id, ts FROM command_usage
+		WHERE chat_id=? AND bot_id=? AND command IN (?`+strings.Repeat(",?", len(commands)-1)+`)
+		ORDER BY ts DESC, id DESC LIMIT ?`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []CommandUsage
+	for rows.Next() {
+		u := CommandUsage{ChatID: chatID}
+		if err := rows.Scan(&u.Command, &u.Args, &u.Category, &u.UserID, &u.Timestamp); err != nil {
+			return nil, err
+		}
+		out = append(out, u)
+	}
+	return out, rows.Err()
 }
 
 // UsageStats represents aggregated usage statistics
@@ -89,15 +399,22 @@ type UsageStats struct {
 	Commands map[string]int // command -> count
 }
 
-// FetchUsageStats retrieves usage statistics for the given time period
+// FetchUsageStats retrieves usage statistics for the given time period; like
+// the other /usage queries it only counts this store's bot.
 func (s *Store) FetchUsageStats(chatID int64, since int64) (map[string]*UsageStats, error) {
+	return s.FetchUsageStatsRange(chatID, since, math.MaxInt64)
+}
+
+// FetchUsageStatsRange retrieves usage statistics for since <= ts < until, e.g.
+// the period before the one /usage reports, to compare against.
+func (s *Store) FetchUsageStatsRange(chatID int64, since, until int64) (map[string]*UsageStats, error) {
 	rows, err := s.db.Query(`
 		SELECT category, command, COUNT(*) as count 
 		FROM command_usage 
-		WHERE chat_id=? AND ts>=? 
+		WHERE chat_id=? AND ts>=? AND ts<? AND bot_id=?
 		GROUP BY category, command 
 		ORDER BY category, count DESC`,
-		chatID, since)
+		chatID, since, until, s.bot)
 	if err != nil {
 		return nil, err
 	}
@@ -131,6 +448,11 @@ type TimeSeriesPoint struct {
 
 // FetchUsageTimeSeries retrieves time series data for usage analytics
 func (s *Store) FetchUsageTimeSeries(chatID int64, since int64, intervalHours int) (map[string][]TimeSeriesPoint, error) {
+	return s.FetchUsageTimeSeriesRange(chatID, since, math.MaxInt64, intervalHours)
+}
+
+// FetchUsageTimeSeriesRange is FetchUsageTimeSeries limited to ts < until.
+func (s *Store) FetchUsageTimeSeriesRange(chatID int64, since, until int64, intervalHours int) (map[string][]TimeSeriesPoint, error) {
 	// Group by time intervals (default 1 hour)
 	if intervalHours <= 0 {
 		intervalHours = 1
@@ -142,10 +464,10 @@ func (s *Store) FetchUsageTimeSeries(chatID int64, since int64, intervalHours in
 			(ts / (? * 3600)) * (? * 3600) as time_bucket,
 			COUNT(*) as count
 		FROM command_usage 
-		WHERE chat_id=? AND ts>=? 
+		WHERE chat_id=? AND ts>=? AND ts<? AND bot_id=?
 		GROUP BY category, time_bucket 
 		ORDER BY category, time_bucket`,
-		intervalHours, intervalHours, chatID, since)
+		intervalHours, intervalHours, chatID, since, until, s.bot)
 	if err != nil {
 		return nil, err
 	}
@@ -167,3 +489,32 @@ func (s *Store) FetchUsageTimeSeries(chatID int64, since int64, intervalHours in
 	}
 	return series, nil
 }
+
+// SaveUpdateOffset records the last accepted update_id for a chat. Update IDs
+// are per bot, so offsets are kept per bot too.
+func (s *Store) SaveUpdateOffset(chatID int64, updateID int) error {
+	_, err := s.db.Exec(`INSERT INTO update_offsets(bot_id,chat_id,last_update_id) VALUES(?,?,?)
+		ON CONFLICT(bot_id,chat_id) DO UPDATE SET last_update_id=excluded.last_update_id
+		WHERE excluded.last_update_id > update_offsets.last_update_id`,
+		s.bot, chatID, updateID)
+	return err
+}
+
+// FetchUpdateOffsets loads the bot's last accepted update_id for every chat.
+func (s *Store) FetchUpdateOffsets() (map[int64]int, error) {
+	rows, err := s.db.Query(`SELECT chat_id, last_update_id FROM update_offsets WHERE bot_id=?`, s.bot)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := make(map[int64]int)
+	for rows.Next() {
+		var chatID int64
+		var id int
+		if err := rows.Scan(&chatID, &id); err != nil {
+			continue
+		}
+		out[chatID] = id
+	}
+	return out, nil
+}
@@ -0,0 +1,103 @@
+package storage
+
+import (
+	"slices"
+	"testing"
+)
+
+// TestCommandUsagePerBot checks that bots sharing a database only see their
+// own command history.
+func TestCommandUsagePerBot(t *testing.T) {
+	s := newTestStore(t)
+	prod, staging := s.ForBot(""), s.ForBot("staging")
+	for _, u := range []struct {
+		store *Store
+		cmd   string
+		ts    int64
+	}{
+		{prod, "ask", 100},
+		{prod, "ask", 200},
+		{staging, "ask", 300},
+		{staging, "summary", 400},
+	} {
+		if _, err := u.store.SaveCommandUsage(CommandUsage{ChatID: 1, Command: u.cmd, Category: "ai", Timestamp: u.ts}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	tests := []struct {
+		name   string
+		store  *Store
+		count  int
+		recent []int64 // timestamps, newest first
+	}{
+		{"default bot", prod, 2, []int64{200, 100}},
+		{"extra bot", staging, 2, []int64{400, 300}},
+		{"unused bot", s.ForBot("other"), 0, nil},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			n, err := tc.store.CountCommandsSince(1, []string{"ask", "summary"}, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if n != tc.count {
+				t.Errorf("CountCommandsSince = %d, want %d", n, tc.count)
+			}
+			recent, err := tc.store.FetchRecentCommands(1, []string{"ask", "summary"}, 10)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var ts []int64
+			for _, u := range recent {
+				ts = append(ts, u.Timestamp)
+			}
+			if !slices.Equal(ts, tc.recent) {
+				t.Errorf("FetchRecentCommands = %v, want %v", ts, tc.recent)
+			}
+		})
+	}
+}
+
+func TestFetchTopSymbols(t *testing.T) {
+	s := newTestStore(t)
+	for _, u := range []struct {
+		store   *Store
+		chatID  int64
+		symbols []string
+		ts      int64
+	}{
+		{s, 1, []string{"NVDA"}, 100},
+		{s, 1, []string{"NVDA", "AMD"}, 110}, // each named ticker counts
+		{s, 1, []string{"AMD"}, 120},
+		{s, 1, []string{"TSLA"}, 130},
+		{s, 1, []string{"SPY"}, 140},
+		{s, 1, nil, 150},                             // a command without tickers
+		{s, 1, []string{"MSFT"}, 50},                 // before since
+		{s, 2, []string{"MSFT"}, 100},                // another chat
+		{s.ForBot("beta"), 1, []string{"MSFT"}, 100}, // another bot
+	} {
+		if _, err := u.store.SaveCommandUsage(CommandUsage{ChatID: u.chatID, Command: "chart", Category: "charts", Symbols: u.symbols, Timestamp: u.ts}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	got, err := s.FetchTopSymbols(1, 100, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// ties are broken by name
+	want := []SymbolCount{{"AMD", 2}, {"NVDA", 2}, {"SPY", 1}, {"TSLA", 1}}
+	if !slices.Equal(got, want) {
+		t.Errorf("FetchTopSymbols = %v, want %v", got, want)
+	}
+	got, err = s.FetchTopSymbols(1, 100, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(got, want[:3]) {
+		t.Errorf("FetchTopSymbols limited to 3 = %v, want %v", got, want[:3])
+	}
+	got, err = s.FetchTopSymbols(3, 0, 10)
+	if err != nil || len(got) != 0 {
+		t.Errorf("FetchTopSymbols of an unknown chat = %v, %v; want none", got, err)
+	}
+}
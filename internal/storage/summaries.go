@@ -0,0 +1,76 @@
+package storage
+
+// Summary is a stored /summary result.
+type Summary struct {
+	ID       int64
+	ChatID   int64 // chat the summary was posted in
+	ThreadID int   // forum topic summarized (AllThreads = whole chat)
+	From, To int64 // unix range of the messages covered
+	Text     string
+	TS       int64
+}
+
+func initSummariesSchema(db DB) error {
+	if _, err := db.Exec(`CREATE TABLE IF NOT EXISTS summaries(
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		chat_id INTEGER NOT NULL,
+		thread_id INTEGER NOT NULL DEFAULT 0,
+		period_from INTEGER NOT NULL,
+		period_to INTEGER NOT NULL,
+		text TEXT NOT NULL,
+		ts INTEGER NOT NULL
+	)`); err != nil {
+		return err
+	}
+	_, err := db.Exec(`CREATE INDEX IF NOT EXISTS summaries_chat_ts ON summaries(chat_id, ts)`)
+	return err
+}
+
+// SaveSummary stores a summary and returns its ID.
+func (s *Store) SaveSummary(sm Summary) (int64, error) {
+	res, err := s.db.Exec(`INSERT INTO summaries(chat_id,thread_id,period_from,period_to,text,ts) VALUES(?,?,?,?,?,?)`,
+		sm.ChatID, sm.ThreadID, sm.From, sm.To, sm.Text, sm.TS)
+	if err != nil {
+		return 0, err
+	}
+	return res.LastInsertId()
+}
+
+// FetchSummaries returns up to limit of the chat's newest summaries.
+func (s *Store) FetchSummaries(chatID int64, limit int) ([]Summary, error) {
+	return s.querySummaries(`SELECT id, chat_id, thread_id, period_from, period_to, text, ts
+		FROM summaries WHERE chat_id=? ORDER BY ts DESC, id DESC LIMIT ?`, chatID, limit)
+}
+
+// FetchSummary returns the chat's summary id; ok is false when there is none.
+func (s *Store) FetchSummary(chatID, id int64) (sm Summary, ok bool, err error) {
+	list, err := s.querySummaries(`SELECT id, chat_id, thread_id, period_from, period_to, text, ts
+		FROM summaries WHERE chat_id=? AND id=?`, chatID, id)
+	if err != nil || len(list) == 0 {
+		return Summary{}, false, err
+	}
+	return list[0], true, nil
+}
+
+// PruneSummaries deletes summaries stored before before.
+func (s *Store) PruneSummaries(before int64) error {
+	_, err := s.db.Exec(`DELETE FROM summaries WHERE ts < ?`, before)
+	return err
+}
+
+func (s *Store) querySummaries(query string, args ...any) ([]Summary, error) {
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []Summary
+	for rows.Next() {
+		var sm Summary
+		if err := rows.Scan(&sm.ID, &sm.ChatID, &sm.ThreadID, &sm.From, &sm.To, &sm.Text, &sm.TS); err != nil {
+			return nil, err
+		}
+		out = append(out, sm)
+	}
+	return out, rows.Err()
+}
@@ -0,0 +1,132 @@
+package storage
+
+import "database/sql"
+
+// Target is a price level a user wants to be notified about.
+type Target struct {
+	ID        int64
+	ChatID    int64
+	UserID    int64
+	UserName  string // "@username" or first name, used for the mention
+	Symbol    string
+	Price     float64
+	RefPrice  float64 // price when set; the side it starts on decides the crossing direction
+	Note      string
+	CreatedAt int64
+	ExpiresAt int64
+	HitAt     int64 // 0 until the target is crossed
+}
+
+func initTargetsSchema(db DB) error {
+	_, err := db.Exec(`CREATE TABLE IF NOT EXISTS targets(
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		chat_id INTEGER NOT NULL,
+		user_id INTEGER NOT NULL,
+		user_name TEXT NOT NULL DEFAULT '',
+		symbol TEXT NOT NULL,
+		price REAL NOT NULL,
+		ref_price REAL NOT NULL,
+		note TEXT NOT NULL DEFAULT '',
+		created_at INTEGER NOT NULL,
+		expires_at INTEGER NOT NULL,
+		hit_at INTEGER NOT NULL DEFAULT 0
+	)`)
+	return err
+}
+
+const targetColumns = `id, chat_id, user_id, user_name, symbol, price, ref_price, note, created_at, expires_at, hit_at`
+
+func scanTargets(rows *sql.Rows) ([]Target, error) {
+	var out []Target
+	for rows.Next() {
+		var t Target
+		if err := rows.Scan(&t.ID, &t.ChatID, &t.UserID, &t.UserName, &t.Symbol, &t.Price, &t.RefPrice,
+			&t.Note, &t.CreatedAt, &t.ExpiresAt, &t.HitAt); err != nil {
+			return nil, err
+		}
+		out = append(out, t)
+	}
+	return out, rows.Err()
+}
+
+// SaveTarget inserts t, or updates the user's existing open target for the same
+// symbol in the chat. It returns the target ID and whether an existing one was updated.
+func (s *Store) SaveTarget(t Target) (int64, bool, error) {
+	res, err := s.db.Exec(`UPDATE targets SET price=?, ref_price=?, note=?, user_name=?, created_at=?, expires_at=?, hit_at=0
+		WHERE chat_id=? AND user_id=? AND symbol=?`,
+		t.Price, t.RefPrice, t.Note, t.UserName, t.CreatedAt, t.ExpiresAt, t.ChatID, t.UserID, t.Symbol)
+	if err != nil {
+		return 0, false, err
+	}
+	if n, _ := res.RowsAffected(); n > 0 {
+		rows, err := s.db.Query(`SELECT id FROM targets WHERE chat_id=? AND user_id=? AND symbol=?`, t.ChatID, t.UserID, t.Symbol)
+		if err != nil {
+			return 0, true, err
+		}
+		defer rows.Close()
+		var id int64
+		if rows.Next() {
+			if err := rows.Scan(&id); err != nil {
+				return 0, true, err
+			}
+		}
+		return id, true, rows.Err()
+	}
+	res, err = s.db.Exec(`INSERT INTO targets(chat_id,user_id,user_name,symbol,price,ref_price,note,created_at,expires_at)
+		VALUES(?,?,?,?,?,?,?,?,?)`,
+		t.ChatID, t.UserID, t.UserName, t.Symbol, t.Price, t.RefPrice, t.Note, t.CreatedAt, t.ExpiresAt)
+	if err != nil {
+		return 0, false, err
+	}
+	id, err := res.LastInsertId()
+	return id, false, err
+}
+
+// FetchTargets returns the chat's targets that have not been hit, oldest first.
+func (s *Store) FetchTargets(chatID int64) ([]Target, error) {
+	rows, err := s.db.Query(`SELECT `+targetColumns+` FROM targets WHERE chat_id=? AND hit_at=0 ORDER BY id`, chatID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanTargets(rows)
+}
+
+// FetchOpenTargets returns every target across chats that is neither hit nor expired at now.
+func (s *Store) FetchOpenTargets(now int64) ([]Target, error) {
+	rows, err := s.db.Query(`SELECT `+targetColumns+` FROM targets WHERE hit_at=0 AND expires_at>? ORDER BY id`, now)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanTargets(rows)
+}
+
+// MarkTargetHit records the crossing once; it reports false if another poll already did.
+func (s *Store) MarkTargetHit(id, ts int64) (bool, error) {
+	res, err := s.db.Exec(`UPDATE targets SET hit_at=? WHERE id=? AND hit_at=0`, ts, id)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+// DeleteTarget removes target id from chatID. It reports false when the chat has no such target.
+func (s *Store) DeleteTarget(chatID, id int64) (bool, error) {
+	res, err := s.db.Exec(`DELETE FROM targets WHERE id=? AND chat_id=?`, id, chatID)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+// DeleteExpiredTargets removes targets that expired by now and ones hit more than a week ago.
+func (s *Store) DeleteExpiredTargets(now int64) (int64, error) {
+	res, err := s.db.Exec(`DELETE FROM targets WHERE expires_at<=? OR (hit_at>0 AND hit_at<=?)`, now, now-7*24*3600)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
@@ -0,0 +1,57 @@
+package storage
+
+import "time"
+
+func initWatchlistSchema(db DB) error {
+	_, err := db.Exec(`CREATE TABLE IF NOT EXISTS watchlist(
+		chat_id INTEGER NOT NULL,
+		symbol TEXT NOT NULL,
+		created_at INTEGER NOT NULL,
+		PRIMARY KEY(chat_id, symbol)
+	)`)
+	return err
+}
+
+// AddWatchlist adds symbols to the chat's watchlist, ignoring ones already present.
+func (s *Store) AddWatchlist(chatID int64, symbols ...string) error {
+	now := time.Now().Unix()
+	for _, sym := range symbols {
+		if _, err := s.db.Exec(`INSERT OR IGNORE INTO watchlist(chat_id,symbol,created_at) VALUES(?,?,?)`,
+			chatID, sym, now); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RemoveWatchlist removes symbols from the chat's watchlist and returns how many were removed.
+func (s *Store) RemoveWatchlist(chatID int64, symbols ...string) (int64, error) {
+	var total int64
+	for _, sym := range symbols {
+		res, err := s.db.Exec(`DELETE FROM watchlist WHERE chat_id=? AND symbol=?`, chatID, sym)
+		if err != nil {
+			return total, err
+		}
+		n, _ := res.RowsAffected()
+		total += n
+	}
+	return total, nil
+}
+
+// FetchWatchlist returns the chat's watchlist in the order symbols were added.
+func (s *Store) FetchWatchlist(chatID int64) ([]string, error) {
+	rows, err := s.db.Query(`SELECT symbol FROM watchlist WHERE chat_id=? ORDER BY created_at, symbol`, chatID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []string
+	for rows.Next() {
+		var sym string
+		if err := rows.Scan(&sym); err != nil {
+			return nil, err
+		}
+		out = append(out, sym)
+	}
+	return out, rows.Err()
+}
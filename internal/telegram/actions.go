@@ -0,0 +1,157 @@
+package telegram
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"telegramBotTrade/internal/logging"
+	"telegramBotTrade/internal/openai"
+	"telegramBotTrade/internal/storage"
+)
+
+const (
+	actionsDefaultHours = 24
+	actionsMaxHours     = 168
+)
+
+// handleActions runs the /actions subcommands; args is everything after the
+// command.
+func (h *Handlers) handleActions(ctx context.Context, chatID int64, threadID int, args string) {
+	fields := strings.Fields(strings.ToLower(args))
+	switch {
+	case len(fields) == 0:
+		h.extractActions(ctx, chatID, threadID, actionsDefaultHours)
+	case len(fields) == 1 && fields[0] == "open":
+		h.listActions(ctx, chatID)
+	case len(fields) == 2 && fields[0] == "done":
+		num, err := strconv.Atoi(strings.TrimPrefix(fields[1], "#"))
+		if err != nil {
+			h.reply(chatID, h.t(chatID, "actions.usage"))
+			return
+		}
+		ok, err := h.store.MarkActionItemDone(chatID, num, time.Now().Unix())
+		switch {
+		case err != nil:
+			h.reply(chatID, h.t(chatID, "actions.update_failed")+err.Error())
+		case !ok:
+			h.reply(chatID, h.t(chatID, "actions.not_open", num))
+		default:
+			h.reply(chatID, h.t(chatID, "actions.done", num))
+		}
+	default:
+		hours, err := strconv.Atoi(strings.TrimSuffix(fields[0], "h"))
+		if len(fields) != 1 || err != nil {
+			h.reply(chatID, h.t(chatID, "actions.usage"))
+			return
+		}
+		h.extractActions(ctx, chatID, threadID, max(1, min(hours, actionsMaxHours)))
+	}
+}
+
+// extractActions asks the model for the action items of the last hours of
+// the forum topic, stores those not already open and posts them.
+func (h *Handlers) extractActions(ctx context.Context, chatID int64, threadID, hours int) {
+	cs := h.chartSettings(chatID)
+	if !cs.StoreMessages {
+		h.reply(chatID, T(cs, "actions.storage_off"))
+		return
+	}
+	if msg, over := h.aiBudgetExceeded(chatID); over {
+		h.reply(chatID, msg)
+		return
+	}
+	logger := logging.FromContext(ctx)
+	since := time.Now().Add(-time.Duration(hours) * time.Hour).Unix()
+	h.flushMessages(ctx)
+	msgs, err := h.store.FetchMessages(chatID, threadID, since)
+	if err != nil {
+		logger.Error("actions: fetch failed", "chat_id", chatID, "err", err)
+		h.reply(chatID, T(cs, "actions.history_failed")+err.Error())
+		return
+	}
+	msgs = slices.DeleteFunc(msgs, func(m storage.Message) bool { return strings.HasPrefix(m.Text, "/") })
+	if len(msgs) == 0 {
+		h.reply(chatID, T(cs, "summary.no_messages"))
+		return
+	}
+	transcript, _ := openai.Condense(h.summaryTranscript(ctx, chatID, msgs))
+	ctx, cancel := context.WithTimeout(ctx, 45*time.Second)
+	defer cancel()
+	found, err := h.summarize.ExtractActionItems(ctx, transcript)
+	if err != nil {
+		h.reply(chatID, T(cs, "actions.extract_failed")+err.Error())
+		return
+	}
+
+	// running /actions again over the same messages must not list items twice
+	open, err := h.store.FetchOpenActionItems(chatID)
+	if err != nil {
+		logger.Warn("actions: loading open items failed", "chat_id", chatID, "err", err)
+	}
+	known := map[string]bool{}
+	for _, a := range open {
+		known[strings.ToLower(a.Task)] = true
+	}
+	var fresh []storage.ActionItem
+	for _, it := range found {
+		if key := strings.ToLower(it.Task); !known[key] {
+			known[key] = true
+			fresh = append(fresh, storage.ActionItem{Owner: it.Owner, Task: it.Task, Due: it.Due})
+		}
+	}
+	if len(fresh) == 0 {
+		if len(found) == 0 {
+			h.reply(chatID, T(cs, "actions.none", hours))
+		} else {
+			h.reply(chatID, T(cs, "actions.none_new", hours, len(open)))
+		}
+		return
+	}
+	saved, err := h.store.SaveActionItems(chatID, fresh, time.Now().Unix())
+	if err != nil {
+		logger.Error("actions: save failed", "chat_id", chatID, "err", err)
+		h.reply(chatID, T(cs, "actions.save_failed")+err.Error())
+		return
+	}
+	logger.Info("actions: extracted", "chat_id", chatID, "messages", len(msgs), "found", len(found), "new", len(saved))
+	h.sendLong(chatID, T(cs, "actions.found", hours)+"\n"+actionChecklist(cs, saved)+"\n"+T(cs, "actions.check_off"), "HTML")
+}
+
+// listActions posts the chat's outstanding action items.
+func (h *Handlers) listActions(ctx context.Context, chatID int64) {
+	cs := h.chartSettings(chatID)
+	open, err := h.store.FetchOpenActionItems(chatID)
+	if err != nil {
+		logging.FromContext(ctx).Error("actions: list failed", "chat_id", chatID, "err", err)
+		h.reply(chatID, T(cs, "actions.load_failed")+err.Error())
+		return
+	}
+	if len(open) == 0 {
+		h.reply(chatID, T(cs, "actions.none_open"))
+		return
+	}
+	h.sendLong(chatID, T(cs, "actions.open", len(open))+"\n"+actionChecklist(cs, open), "HTML")
+}
+
+// actionChecklist renders items as numbered checklist lines:
+// "☐ 3. Bob → send the deck (due Friday)".
+func actionChecklist(cs storage.ChatSettings, items []storage.ActionItem) string {
+	var b strings.Builder
+	for _, a := range items {
+		fmt.Fprintf(&b, "☐ %d. ", a.Num)
+		if a.Owner != "" {
+			b.WriteString("<b>" + html.EscapeString(a.Owner) + "</b> → ")
+		}
+		b.WriteString(html.EscapeString(a.Task))
+		if a.Due != "" {
+			b.WriteString(" " + T(cs, "actions.due", html.EscapeString(a.Due)))
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
@@ -0,0 +1,82 @@
+package telegram
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"telegramBotTrade/internal/logging"
+	"telegramBotTrade/internal/storage"
+)
+
+const (
+	activityDefaultDays = 7
+	activityMaxDays     = 90
+	// activityHourlyDays is the longest window charted per hour instead of
+	// per day
+	activityHourlyDays = 2
+	// activityTopUsers is how many of the most active users get a line of
+	// their own with /activity users
+	activityTopUsers = 5
+)
+
+// handleActivity charts the chat's stored messages over the last days, per
+// day or per hour for short windows, optionally split by the most active
+// users.
+func (h *Handlers) handleActivity(ctx context.Context, chatID int64, days int, byUser bool) {
+	cs := h.chartSettings(chatID)
+	if !cs.StoreMessages {
+		h.reply(chatID, T(cs, "activity.storage_off"))
+		return
+	}
+	interval := 24
+	if days <= activityHourlyDays {
+		interval = 1
+	}
+	top := 0
+	if byUser {
+		top = activityTopUsers
+	}
+	now := time.Now()
+	h.flushMessages(ctx)
+	series, names, err := h.store.FetchMessageActivity(chatID, now.AddDate(0, 0, -days).Unix(), now.Unix(), interval, top)
+	if err != nil {
+		logging.FromContext(ctx).Error("activity failed", "chat_id", chatID, "err", err)
+		h.reply(chatID, T(cs, "activity.failed")+err.Error())
+		return
+	}
+	totals := make(map[string]int, len(names))
+	total := 0
+	for _, name := range names {
+		for _, p := range series[name] {
+			totals[name] += p.Count
+			total += p.Count
+		}
+	}
+	if total == 0 {
+		h.reply(chatID, T(cs, "activity.none", days))
+		return
+	}
+	img, err := h.analytics.MakeActivityChart(ctx, series, names, days, interval)
+	if err != nil {
+		logging.FromContext(ctx).Error("activity chart failed", "chat_id", chatID, "err", err)
+		h.replyFailure(chatID, T(cs, "activity.chart_failed"), err)
+		return
+	}
+	caption := T(cs, "activity.caption", total, days)
+	if byUser {
+		parts := make([]string, 0, len(names))
+		for _, name := range names {
+			if name != storage.ActivityOthers {
+				parts = append(parts, fmt.Sprintf("%s %d", name, totals[name]))
+			}
+		}
+		caption += "\n" + T(cs, "activity.most_active", strings.Join(parts, " • "))
+	}
+	photo := tgbotapi.NewPhoto(chatID, tgbotapi.FileBytes{Name: "activity.png", Bytes: img})
+	photo.Caption = caption
+	h.api.Send(photo)
+}
@@ -0,0 +1,92 @@
+package telegram
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+	"time"
+
+	"telegramBotTrade/internal/finance"
+	"telegramBotTrade/internal/logging"
+	"telegramBotTrade/internal/version"
+)
+
+// isAdmin reports whether chatID is the configured admin chat. For a private
+// chat with the maintainer this is their user ID.
+func (h *Handlers) isAdmin(chatID int64) bool {
+	return h.adminChatID != 0 && chatID == h.adminChatID
+}
+
+// handleVersion replies with build and runtime info in the admin chat.
+func (h *Handlers) handleVersion(chatID int64) {
+	if !h.isAdmin(chatID) {
+		h.reply(chatID, h.t(chatID, "admin.only"))
+		return
+	}
+	info := version.Get()
+	if h.about != nil {
+		info = h.about()
+	}
+	h.reply(chatID, info.String())
+}
+
+// handleStatus replies in the admin chat with the bot's live operational
+// state. A line is left out when its numbers can't be had, so one failing
+// collector doesn't cost the rest.
+func (h *Handlers) handleStatus(chatID int64) {
+	if !h.isAdmin(chatID) {
+		h.reply(chatID, h.t(chatID, "admin.only"))
+		return
+	}
+	info := version.Get()
+	if h.about != nil {
+		info = h.about()
+	}
+	var b strings.Builder
+	b.WriteString("Status\n")
+	fmt.Fprintf(&b, "Uptime: %s\n", info.Uptime)
+	fmt.Fprintf(&b, "Goroutines: %d\n", runtime.NumGoroutine())
+	if h.queueDepth != nil {
+		fmt.Fprintf(&b, "Handler queue: %d waiting\n", h.queueDepth())
+	}
+	if y := finance.CurrentYahooStats(); y.Requests > 0 {
+		fmt.Fprintf(&b, "Yahoo: %d requests, %d rate-limited (%.1f%%) in the last %s\n",
+			y.Requests, y.RateLimited, float64(y.RateLimited)/float64(y.Requests)*100, time.Since(y.Since).Round(time.Second))
+	} else {
+		b.WriteString("Yahoo: no requests in the current window\n")
+	}
+	caches := []string{cacheLine("charts", finance.ChartCacheStats()), cacheLine("quotes", finance.QuoteCacheStats())}
+	if h.summaries != nil {
+		caches = append(caches, cacheLine("summaries", h.summaries.stats()))
+	}
+	fmt.Fprintf(&b, "Cache hits: %s\n", strings.Join(caches, ", "))
+	if size, err := h.store.DBSize(); err == nil {
+		fmt.Fprintf(&b, "Database: %.1f MB\n", float64(size)/(1<<20))
+	}
+	errs := logging.LastErrors()
+	if len(errs) == 0 {
+		b.WriteString("Errors: none since start")
+	} else {
+		b.WriteString("Last error per subsystem:")
+		for _, e := range errs {
+			text := e.Message
+			if e.Err != "" {
+				text += ": " + e.Err
+			}
+			if r := []rune(text); len(r) > 200 {
+				text = string(r[:200]) + "…"
+			}
+			fmt.Fprintf(&b, "\n- %s, %s ago: %s", e.Subsystem, time.Since(e.Time).Round(time.Second), text)
+		}
+	}
+	h.sendLong(chatID, b.String(), "")
+}
+
+// cacheLine formats one cache's hit rate for /status, e.g. "charts 45% of 200".
+func cacheLine(name string, s finance.CacheStats) string {
+	n := s.Hits + s.Misses
+	if n == 0 {
+		return name + " no lookups yet"
+	}
+	return fmt.Sprintf("%s %.0f%% of %d", name, s.HitRate(), n)
+}
@@ -0,0 +1,324 @@
+package telegram
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"telegramBotTrade/internal/finance"
+	"telegramBotTrade/internal/logging"
+	"telegramBotTrade/internal/storage"
+)
+
+// againSpec tells /again where a command keeps its interval and window: the
+// capture groups of its pattern, or for portfolio commands the last field
+// of the holdings.
+type againSpec struct {
+	re               *regexp.Regexp
+	interval, window int // capture groups, 0 = the command has none
+	// anchor is the group of an intraday anchor, which a window override
+	// replaces: an anchored chart has no window
+	anchor int
+	// mini marks the 1d|1w|1m windows of /stock and /stocks
+	mini      bool
+	portfolio bool
+}
+
+// againSpecs are the chart and portfolio commands /again replays. Commands
+// that cost an AI call (/recommend, /summary, /chart) or change state are
+// left out.
+var againSpecs = map[string]againSpec{
+	"stock":        {re: reStock, window: 2, anchor: 3, mini: true},
+	"stocks":       {re: reStocks, window: 2, mini: true},
+	"stocks-index": {re: reStocksIndex, interval: 2, window: 3},
+	"stockx":       {re: reStockX, interval: 2, window: 3, anchor: 4},
+	"stocksx":      {re: reStocksX, interval: 2, window: 3},
+	"macd":         {re: reMACD, interval: 2, window: 3},
+	"atr":          {re: reATR, window: 3},
+	"vix":          {re: reVIX, window: 1},
+	"yield":        {re: reYield, window: 1},
+	"curve":        {re: reCurve},
+	"heat":         {re: reHeat, window: 2},
+	"yoy":          {re: reYoY},
+	"ew-port":      {re: reEWPort, window: 2},
+	"port":         {re: rePort, portfolio: true},
+	"portstats":    {re: rePortStats, portfolio: true},
+	"montecarlo":   {re: reMonteCarlo, portfolio: true},
+}
+
+// againCommand returns the name of the replayable command txt runs, or "".
+func againCommand(txt string) string {
+	fields := strings.Fields(txt)
+	if len(fields) == 0 || !strings.HasPrefix(fields[0], "/") {
+		return ""
+	}
+	name, _, _ := strings.Cut(strings.TrimPrefix(fields[0], "/"), "@")
+	if _, ok := againSpecs[name]; !ok || rePortWatch.MatchString(txt) {
+		return ""
+	}
+	return name
+}
+
+// againText applies the /again overrides in args to last and returns the
+// command to run and the theme to run it in. Overrides replace what last
+// says and everything else is kept, so an argument last left out still
+// follows the chat's /set default. Precedence, token by token:
+//   - window=X, interval=X and theme=X set that field outright;
+//   - a theme name sets the theme;
+//   - an interval sets the interval of a command that has one, unless an
+//     earlier token already did, read as the command reads "1h 1d";
+//   - anything else is the window.
+//
+// Setting a field twice, or one the command doesn't have, is an error. The
+// theme carries over to later replays until another override changes it.
+func againText(last storage.LastCommand, args string, cs storage.ChatSettings) (string, string, error) {
+	name := againCommand(last.Text)
+	spec, ok := againSpecs[name]
+	if !ok {
+		return "", "", fmt.Errorf("%s can't be run again", last.Text)
+	}
+	var interval, window string
+	theme := last.Theme
+	set := map[string]bool{}
+	for _, tok := range strings.Fields(strings.ToLower(args)) {
+		field, value, explicit := strings.Cut(tok, "=")
+		if !explicit {
+			value = tok
+			switch {
+			case slices.Contains(finance.Themes, tok):
+				field = "theme"
+			case spec.interval > 0 && !set["interval"] && isInterval(tok):
+				field = "interval"
+			default:
+				field = "window"
+			}
+		}
+		switch field {
+		case "theme":
+			if !slices.Contains(finance.Themes, value) {
+				return "", "", fmt.Errorf("unknown theme %s (%s)", value, strings.Join(finance.Themes, ", "))
+			}
+			theme = value
+		case "interval":
+			if spec.interval == 0 {
+				return "", "", fmt.Errorf("/%s has no interval", name)
+			}
+			interval = value
+		case "window":
+			if spec.window == 0 && !spec.portfolio {
+				return "", "", fmt.Errorf("/%s has no window", name)
+			}
+			window = value
+		default:
+			return "", "", fmt.Errorf("unknown override %s", tok)
+		}
+		if set[field] {
+			return "", "", fmt.Errorf("the %s is given twice", field)
+		}
+		set[field] = true
+	}
+
+	text := last.Text
+	switch {
+	case !set["interval"] && !set["window"]:
+	case spec.portfolio:
+		if !finance.IsPortfolioWindow(window) {
+			return "", "", fmt.Errorf("invalid window %s (use a window like 30d, 12w, 6m, 2y or ytd)", window)
+		}
+		text = withPortfolioWindow(name, text, window)
+	default:
+		g := spec.re.FindStringSubmatch(text)
+		if g == nil {
+			return "", "", fmt.Errorf("%s can't be run again", last.Text)
+		}
+		if set["interval"] {
+			g[spec.interval] = interval
+		}
+		if set["window"] {
+			g[spec.window] = againWindow(spec, window)
+			if spec.anchor > 0 {
+				g[spec.anchor] = ""
+			}
+		}
+		// a lone window such as 1d would read as the interval
+		if spec.interval > 0 && g[spec.window] != "" && g[spec.interval] == "" {
+			g[spec.interval] = defaultInterval(cs)
+		}
+		parts := []string{"/" + name}
+		for _, f := range g[1:] {
+			if f = strings.TrimSpace(f); f != "" {
+				parts = append(parts, f)
+			}
+		}
+		text = strings.Join(parts, " ")
+	}
+	if !spec.re.MatchString(text) {
+		return "", "", fmt.Errorf("%s doesn't fit /%s; see /help %s", strings.TrimSpace(args), name, name)
+	}
+	return text, theme, nil
+}
+
+// isInterval reports whether s is one of the chart intervals.
+func isInterval(s string) bool {
+	return slices.ContainsFunc(finance.IntervalLimits, func(l finance.IntervalLimit) bool { return l.Interval == s })
+}
+
+// againWindow maps a week onto the window spelling of the command, as
+// miniWindow and customWindow do for the /set default.
+func againWindow(spec againSpec, window string) string {
+	switch {
+	case spec.mini && window == "5d":
+		return "1w"
+	case spec.interval > 0 && window == "1w":
+		return "5d"
+	}
+	return window
+}
+
+// withPortfolioWindow replaces the window of a portfolio command, the last
+// field of its holdings, or adds it after them when the command had none.
+func withPortfolioWindow(name, text, window string) string {
+	fields := strings.Fields(commandArgs(text))
+	last := -1
+	for i, f := range fields {
+		// montecarlo's key=value options and /port's trailing flags
+		if !strings.Contains(f, "=") && !isPortFlag(f) {
+			last = i
+		}
+	}
+	if last >= 0 && finance.IsPortfolioWindow(fields[last]) {
+		fields[last] = window
+	} else {
+		fields = slices.Insert(fields, last+1, window)
+	}
+	return "/" + name + " " + strings.Join(fields, " ")
+}
+
+// handleAgain runs the chat's last chart or portfolio command again with the
+// overrides in args. The replay is handled like the command itself, so it
+// becomes the last command in turn.
+func (h *Handlers) handleAgain(ctx context.Context, m *tgbotapi.Message, args string) {
+	chatID := m.Chat.ID
+	last, err := h.again.get(chatID)
+	if err != nil {
+		h.reply(chatID, h.t(chatID, "again.load_failed")+err.Error())
+		return
+	}
+	if last.Text == "" {
+		h.reply(chatID, h.t(chatID, "again.none")+"\n\n"+h.t(chatID, "again.usage"))
+		return
+	}
+	text, theme, err := againText(last, args, h.chartSettings(chatID))
+	if err != nil {
+		h.reply(chatID, h.t(chatID, "again.failed", last.Text, err)+"\n\n"+h.t(chatID, "again.usage"))
+		return
+	}
+	logging.FromContext(ctx).Info("again: replay", "chat_id", chatID, "command", text, "theme", theme)
+	replay := *m
+	replay.ReplyToMessage = nil
+	replay.Text = text
+	ctx = withRerun(ctx)
+	if theme != "" {
+		ctx = context.WithValue(ctx, againThemeKey{}, theme)
+	}
+	h.HandleMessage(ctx, &replay)
+}
+
+type (
+	againRunKey   struct{}
+	againThemeKey struct{}
+)
+
+// againRun follows one replayable command through its handler.
+type againRun struct {
+	delivered bool
+}
+
+// withAgainRun starts following a replayable command, which becomes the
+// chat's /again command once its handler calls markDelivered.
+func withAgainRun(ctx context.Context) (context.Context, *againRun) {
+	run := &againRun{}
+	return context.WithValue(ctx, againRunKey{}, run), run
+}
+
+// markDelivered notes that the command being handled sent its chart or
+// result.
+func markDelivered(ctx context.Context) {
+	if run, ok := ctx.Value(againRunKey{}).(*againRun); ok {
+		run.delivered = true
+	}
+}
+
+// againTheme is the theme an /again override runs the command in, "" for
+// the chat's own.
+func againTheme(ctx context.Context) string {
+	theme, _ := ctx.Value(againThemeKey{}).(string)
+	return theme
+}
+
+// rememberLast records txt, the replayable command name ran, as the chat's
+// /again command.
+func (h *Handlers) rememberLast(ctx context.Context, chatID int64, name, txt string) {
+	c := storage.LastCommand{Text: strings.TrimSpace("/" + name + " " + commandArgs(txt)), Theme: againTheme(ctx)}
+	if err := h.again.remember(chatID, c); err != nil {
+		logging.FromContext(ctx).Error("again: save last command failed", "chat_id", chatID, "err", err)
+	}
+}
+
+// lastCommands keeps each chat's /again command in memory in front of the
+// chats table.
+type lastCommands struct {
+	store *storage.Store
+
+	mu     sync.Mutex
+	byChat map[int64]storage.LastCommand
+}
+
+func newLastCommands(store *storage.Store) *lastCommands {
+	return &lastCommands{store: store, byChat: map[int64]storage.LastCommand{}}
+}
+
+// get returns the chat's last command, empty when it ran none.
+func (l *lastCommands) get(chatID int64) (storage.LastCommand, error) {
+	l.mu.Lock()
+	c, ok := l.byChat[chatID]
+	l.mu.Unlock()
+	if ok {
+		return c, nil
+	}
+	c, err := l.store.FetchLastCommand(chatID)
+	if err != nil {
+		return c, err
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	// a command remembered while loading is newer
+	if cur, ok := l.byChat[chatID]; ok {
+		return cur, nil
+	}
+	l.byChat[chatID] = c
+	return c, nil
+}
+
+// remember makes c the chat's last command and writes it through.
+func (l *lastCommands) remember(chatID int64, c storage.LastCommand) error {
+	l.mu.Lock()
+	l.byChat[chatID] = c
+	l.mu.Unlock()
+	return l.store.SaveLastCommand(chatID, c, time.Now().Unix())
+}
+
+// forget drops the cached commands of the given chats.
+func (l *lastCommands) forget(chatIDs ...int64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, id := range chatIDs {
+		delete(l.byChat, id)
+	}
+}
@@ -0,0 +1,86 @@
+package telegram
+
+import (
+	"strings"
+	"testing"
+
+	"telegramBotTrade/internal/storage"
+)
+
+func TestAgainCommand(t *testing.T) {
+	tests := map[string]string{
+		"/stockx TSLA 5m 1m":      "stockx",
+		"/stockx@TradeBot TSLA":   "stockx",
+		"/port AAPL 0.5 MSFT 0.5": "port",
+		"/port watch tech AAPL 1": "",
+		"/summary 24h":            "",
+		"/recommend buy the dip":  "",
+		"stockx TSLA":             "",
+		"":                        "",
+	}
+	for txt, want := range tests {
+		if got := againCommand(txt); got != want {
+			t.Errorf("againCommand(%q) = %q, want %q", txt, got, want)
+		}
+	}
+}
+
+func TestAgainText(t *testing.T) {
+	tests := []struct {
+		last, lastTheme, args string
+		defaultInterval       string
+		want, wantTheme       string
+		wantErr               string
+	}{
+		{last: "/stockx TSLA 5m 1m", want: "/stockx TSLA 5m 1m"},
+		{last: "/stockx TSLA 5m 1m", args: "1y", want: "/stockx TSLA 5m 1y"},
+		{last: "/stockx TSLA 5m 1m", args: "1h", want: "/stockx TSLA 1h 1m"},
+		// the first interval-looking word is the interval, the second the window
+		{last: "/stockx TSLA 5m 1m", args: "1h 1d", want: "/stockx TSLA 1h 1d"},
+		{last: "/stockx TSLA", args: "1d", want: "/stockx TSLA 1d"},
+		// a lone window gets the chat's interval so it isn't read as one
+		{last: "/stockx TSLA", args: "window=1d", want: "/stockx TSLA 5m 1d"},
+		{last: "/stockx TSLA", args: "window=1d", defaultInterval: "15m", want: "/stockx TSLA 15m 1d"},
+		{last: "/stockx TSLA 5m 1m", args: "1w", want: "/stockx TSLA 5m 5d"},
+		// a window replaces an intraday anchor
+		{last: "/stockx TSLA 5m open vwap", args: "window=5d", want: "/stockx TSLA 5m 5d vwap"},
+		{last: "/stock AAPL 1d", args: "5d", want: "/stock AAPL 1w"},
+		{last: "/stocksx AAPL MSFT 1h 1y", args: "interval=1d", want: "/stocksx AAPL MSFT 1d 1y"},
+		{last: "/stocks-index SPY QQQ 1d 1y", args: "5Y", want: "/stocks-index SPY QQQ 1d 5y"},
+
+		// themes are overrides of their own and carry over
+		{last: "/stockx TSLA 5m 1m", args: "dark", want: "/stockx TSLA 5m 1m", wantTheme: "dark"},
+		{last: "/stockx TSLA 5m 1m", args: "grafana 1y", want: "/stockx TSLA 5m 1y", wantTheme: "grafana"},
+		{last: "/stockx TSLA 5m 1m", lastTheme: "dark", want: "/stockx TSLA 5m 1m", wantTheme: "dark"},
+		{last: "/stockx TSLA 5m 1m", lastTheme: "dark", args: "theme=light", want: "/stockx TSLA 5m 1m", wantTheme: "light"},
+		{last: "/curve", args: "ant", want: "/curve", wantTheme: "ant"},
+
+		// portfolio windows are the last field of the holdings
+		{last: "/port AAPL 0.5 MSFT 0.5 1y detail", args: "6m", want: "/port AAPL 0.5 MSFT 0.5 6m detail"},
+		{last: "/port AAPL 0.5 MSFT 0.5", args: "ytd", want: "/port AAPL 0.5 MSFT 0.5 ytd"},
+		{last: "/portstats SPY 1 2y", args: "window=5y", want: "/portstats SPY 1 5y"},
+
+		{last: "/summary 24h", wantErr: "can't be run again"},
+		{last: "/curve", args: "1y", wantErr: "/curve has no window"},
+		{last: "/stock AAPL", args: "interval=1h", wantErr: "/stock has no interval"},
+		{last: "/port AAPL 1", args: "1h", wantErr: "invalid window 1h"},
+		{last: "/stockx TSLA", args: "1y 2y", wantErr: "the window is given twice"},
+		{last: "/stockx TSLA", args: "dark theme=light", wantErr: "the theme is given twice"},
+		{last: "/stockx TSLA", args: "theme=neon", wantErr: "unknown theme neon"},
+		{last: "/stockx TSLA", args: "color=red", wantErr: "unknown override color=red"},
+		{last: "/stockx TSLA 5m", args: "7y", wantErr: "7y doesn't fit /stockx"},
+	}
+	for _, tc := range tests {
+		last := storage.LastCommand{Text: tc.last, Theme: tc.lastTheme}
+		got, theme, err := againText(last, tc.args, storage.ChatSettings{DefaultInterval: tc.defaultInterval})
+		if tc.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Errorf("%s + %q: error %v, want %q", tc.last, tc.args, err, tc.wantErr)
+			}
+			continue
+		}
+		if err != nil || got != tc.want || theme != tc.wantTheme {
+			t.Errorf("%s + %q = %q, %q, %v; want %q, %q", tc.last, tc.args, got, theme, err, tc.want, tc.wantTheme)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package telegram
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"telegramBotTrade/internal/finance"
+	"telegramBotTrade/internal/logging"
+)
+
+const (
+	alertPollInterval = 5 * time.Minute
+	// alertLogRetention bounds the alert_log table; records only matter for the current period.
+	alertLogRetention = 7 * 24 * time.Hour
+)
+
+// runAlertPoller runs the background alert checks until ctx is cancelled.
+func (b *Bot) runAlertPoller(ctx context.Context) {
+	t := time.NewTicker(alertPollInterval)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-t.C:
+			pctx := logging.WithRequestID(ctx, logging.NewRequestID())
+			b.checkMovers(pctx, now)
+			b.checkTargets(pctx, now)
+			b.checkPortfolioWatches(pctx, now)
+			if err := b.store.PruneAlertLog(now.Add(-alertLogRetention).Unix()); err != nil {
+				logging.FromContext(pctx).Warn("alerts: prune failed", "err", err)
+			}
+		}
+	}
+}
+
+// checkMovers pushes a movers alert for each watchlist symbol that crossed the
+// chat's movers_auto threshold, at most once per symbol per local day.
+func (b *Bot) checkMovers(ctx context.Context, now time.Time) {
+	logger := logging.FromContext(ctx)
+	chats, err := b.store.FetchMoversAutoChats()
+	if err != nil {
+		logger.Error("alerts: load movers chats failed", "err", err)
+		return
+	}
+	// one batch of quotes covers every chat's watchlist
+	watches := map[int64][]string{}
+	var all []string
+	for chatID := range chats {
+		if !b.h.chats.active(chatID) {
+			continue
+		}
+		watch, err := b.store.FetchWatchlist(chatID)
+		if err != nil || len(watch) == 0 {
+			continue
+		}
+		watches[chatID] = watch
+		all = append(all, watch...)
+	}
+	if len(all) == 0 {
+		return
+	}
+	slices.Sort(all)
+	qctx, cancel := context.WithTimeout(ctx, time.Minute)
+	quotes, _ := finance.FetchBatchQuotes(qctx, slices.Compact(all))
+	cancel()
+	for chatID, watch := range watches {
+		threshold := chats[chatID]
+		mine := make(map[string]finance.Quote, len(watch))
+		for _, sym := range watch {
+			if q, ok := quotes[sym]; ok {
+				mine[sym] = q
+			}
+		}
+		day := now.In(chatClock(b.h.chartSettings(chatID))).Format("2006-01-02")
+		var fresh []finance.Quote
+		for _, q := range moversAbove(mine, threshold) {
+			first, err := b.store.MarkAlerted(chatID, "movers", q.Symbol, day)
+			if err != nil {
+				logger.Error("alerts: mark failed", "chat_id", chatID, "symbol", q.Symbol, "err", err)
+				continue
+			}
+			if first {
+				fresh = append(fresh, q)
+			}
+		}
+		if len(fresh) > 0 {
+			logger.Info("alerts: movers", "chat_id", chatID, "count", len(fresh))
+			b.h.sendMovers(chatID, b.h.t(chatID, "movers.alert", threshold), fresh, nil)
+		}
+	}
+}
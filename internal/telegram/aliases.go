@@ -0,0 +1,167 @@
+package telegram
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// botCommands lists every command the bot dispatches. Custom aliases may only
+// expand into one of these, so an alias can never point at another alias.
+var botCommands = map[string]bool{
+	"/summary": true, "/recommend": true, "/usage": true, "/activity": true, "/set": true, "/help": true, "/start": true,
+	"/stock": true, "/stocks": true, "/stockx": true, "/stocksx": true, "/stocks-index": true,
+	"/ew-port": true, "/port": true, "/portstats": true, "/portbuilder": true, "/montecarlo": true,
+	"/watch": true, "/brief": true, "/movers": true, "/target": true, "/paper": true,
+	"/macd": true, "/atr": true, "/yoy": true, "/vix": true, "/yield": true, "/curve": true, "/heat": true, "/ohlc": true, "/export": true,
+	"/info": true, "/optmove": true, "/calendar": true, "/history": true, "/again": true,
+	"/schedule": true, "/feedback": true, "/alias": true, "/chart": true, "/ask": true, "/actions": true, "/summaries": true,
+	"/translate": true, "/recap": true, "/futures": true,
+	"/version": true, "/status": true, "/report": true, "/broadcast": true,
+}
+
+// builtinAliases are the shorthands every chat gets.
+var builtinAliases = map[string]string{
+	"/s":  "/stock",
+	"/ss": "/stocks",
+	"/sx": "/stockx",
+	"/p":  "/port",
+}
+
+// maxAliasesPerChat caps /alias entries per chat.
+const maxAliasesPerChat = 20
+
+var reAliasName = regexp.MustCompile(`^[a-z][a-z0-9_]{0,15}$`)
+
+// commandName returns the lowercased command of txt without any @botname,
+// e.g. "/stock" for "/stock@MyBot SPY".
+func commandName(txt string) string {
+	first, _, _ := strings.Cut(txt, " ")
+	first, _, _ = strings.Cut(first, "@")
+	return strings.ToLower(first)
+}
+
+// expandAlias rewrites a builtin or custom alias into the command it stands
+// for, keeping any arguments after it, and then futures shorthands among its
+// symbols (see expandFutures). Anything else is returned unchanged.
+func (h *Handlers) expandAlias(chatID int64, txt string) string {
+	if !strings.HasPrefix(txt, "/") {
+		return txt
+	}
+	return expandFutures(h.expandCommandAlias(chatID, txt))
+}
+
+// expandCommandAlias rewrites the command of txt when it is an alias.
+// Expansions always start with a real command, so one pass is enough.
+func (h *Handlers) expandCommandAlias(chatID int64, txt string) string {
+	name := commandName(txt)
+	if botCommands[name] {
+		return txt
+	}
+	_, args, _ := strings.Cut(txt, " ")
+	expansion, ok := builtinAliases[name]
+	if !ok {
+		var err error
+		if expansion, err = h.store.FetchAlias(chatID, strings.TrimPrefix(name, "/")); err != nil || expansion == "" {
+			return txt
+		}
+	}
+	return strings.TrimSpace(expansion + " " + strings.TrimSpace(args))
+}
+
+// handleAlias dispatches /alias add|remove|list.
+func (h *Handlers) handleAlias(chatID int64, args string) {
+	sub, rest, _ := strings.Cut(strings.TrimSpace(args), " ")
+	switch strings.ToLower(sub) {
+	case "", "list":
+		h.listAliases(chatID)
+	case "add", "set":
+		h.addAlias(chatID, strings.TrimSpace(rest))
+	case "remove", "delete", "del":
+		name := strings.TrimPrefix(strings.ToLower(strings.TrimSpace(rest)), "/")
+		if name == "" {
+			h.reply(chatID, h.t(chatID, "alias.usage"))
+			return
+		}
+		ok, err := h.store.DeleteAlias(chatID, name)
+		switch {
+		case err != nil:
+			h.reply(chatID, h.t(chatID, "alias.remove_failed")+err.Error())
+		case !ok:
+			h.reply(chatID, h.t(chatID, "alias.unknown", name))
+		default:
+			h.reply(chatID, h.t(chatID, "alias.removed", name))
+		}
+	default:
+		h.reply(chatID, h.t(chatID, "alias.usage"))
+	}
+}
+
+func (h *Handlers) addAlias(chatID int64, args string) {
+	name, expansion, _ := strings.Cut(args, " ")
+	name = strings.TrimPrefix(strings.ToLower(name), "/")
+	expansion = strings.TrimSpace(expansion)
+	if unq, err := strconv.Unquote(expansion); err == nil {
+		expansion = unq
+	} else {
+		expansion = strings.Trim(expansion, `"“”`)
+	}
+	expansion = strings.TrimSpace(expansion)
+	if name == "" || expansion == "" {
+		h.reply(chatID, h.t(chatID, "alias.usage"))
+		return
+	}
+	if !reAliasName.MatchString(name) {
+		h.reply(chatID, h.t(chatID, "alias.bad_name"))
+		return
+	}
+	if botCommands["/"+name] || builtinAliases["/"+name] != "" {
+		h.reply(chatID, h.t(chatID, "alias.taken", name))
+		return
+	}
+	target := commandName(expansion)
+	if !botCommands[target] {
+		h.reply(chatID, h.t(chatID, "alias.bad_target", target))
+		return
+	}
+	if target == "/alias" {
+		h.reply(chatID, h.t(chatID, "alias.recursive"))
+		return
+	}
+	existing, err := h.store.FetchAliases(chatID)
+	if err != nil {
+		h.reply(chatID, h.t(chatID, "alias.load_failed")+err.Error())
+		return
+	}
+	replacing := false
+	for _, a := range existing {
+		replacing = replacing || a.Name == name
+	}
+	if !replacing && len(existing) >= maxAliasesPerChat {
+		h.reply(chatID, h.t(chatID, "alias.too_many", maxAliasesPerChat))
+		return
+	}
+	if err := h.store.SetAlias(chatID, name, expansion); err != nil {
+		h.reply(chatID, h.t(chatID, "alias.save_failed")+err.Error())
+		return
+	}
+	h.reply(chatID, h.t(chatID, "alias.added", name, expansion))
+}
+
+func (h *Handlers) listAliases(chatID int64) {
+	list, err := h.store.FetchAliases(chatID)
+	if err != nil {
+		h.reply(chatID, h.t(chatID, "alias.load_failed")+err.Error())
+		return
+	}
+	var b strings.Builder
+	b.WriteString(h.t(chatID, "alias.title") + "\n\n/s → /stock, /ss → /stocks, /sx → /stockx, /p → /port")
+	for _, a := range list {
+		fmt.Fprintf(&b, "\n/%s → %s", a.Name, a.Expansion)
+	}
+	if len(list) == 0 {
+		b.WriteString("\n\n" + h.t(chatID, "alias.none") + " " + h.t(chatID, "alias.usage"))
+	}
+	h.reply(chatID, b.String())
+}
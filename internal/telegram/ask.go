@@ -0,0 +1,131 @@
+package telegram
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+
+	"telegramBotTrade/internal/logging"
+	"telegramBotTrade/internal/storage"
+)
+
+const (
+	askDefaultHours = 24
+	askMaxHours     = 168
+	// askMaxMatches caps the older keyword-matched messages pulled in.
+	askMaxMatches = 40
+	// askCharBudget keeps the excerpt around 6k tokens at ~4 characters per token.
+	askCharBudget = 24000
+)
+
+// askStopwords are left out of the full-text query built from a question.
+var askStopwords = map[string]bool{
+	"the": true, "and": true, "for": true, "are": true, "was": true, "were": true, "did": true, "does": true,
+	"what": true, "when": true, "where": true, "which": true, "who": true, "whom": true, "why": true, "how": true,
+	"that": true, "this": true, "with": true, "about": true, "from": true, "have": true, "has": true, "had": true,
+	"you": true, "your": true, "our": true, "they": true, "them": true, "their": true, "its": true,
+	"can": true, "could": true, "would": true, "should": true, "will": true, "any": true, "all": true,
+	"yesterday": true, "today": true, "last": true, "week": true, "chat": true, "someone": true, "anyone": true,
+}
+
+// askTerms returns the distinct keywords of question for the full-text search.
+func askTerms(question string) []string {
+	var out []string
+	for _, w := range strings.FieldsFunc(strings.ToLower(question), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		if len([]rune(w)) < 3 || askStopwords[w] || slices.Contains(out, w) {
+			continue
+		}
+		out = append(out, w)
+	}
+	return out
+}
+
+// askContext picks the messages an /ask question is answered from within a
+// character budget: keyword matches from before the window (newest first) may
+// use up to a third of it, the most recent messages the rest. The result is in
+// chronological order.
+func askContext(recent, matched []storage.Message, budget int) []storage.Message {
+	var picked []storage.Message
+	used := 0
+	for _, m := range matched {
+		if used+len(m.Text) > budget/3 {
+			break
+		}
+		picked = append(picked, m)
+		used += len(m.Text)
+	}
+	for i := len(recent) - 1; i >= 0; i-- {
+		if used+len(recent[i].Text) > budget {
+			break
+		}
+		picked = append(picked, recent[i])
+		used += len(recent[i].Text)
+	}
+	slices.SortStableFunc(picked, func(a, b storage.Message) int {
+		switch {
+		case a.Ts < b.Ts:
+			return -1
+		case a.Ts > b.Ts:
+			return 1
+		}
+		return 0
+	})
+	return picked
+}
+
+// handleAsk answers a question from the chat's stored messages of the forum
+// topic it was asked in.
+func (h *Handlers) handleAsk(ctx context.Context, chatID int64, threadID int, hours int, question string) {
+	cs := h.chartSettings(chatID)
+	if question == "" {
+		h.reply(chatID, T(cs, "ask.usage"))
+		return
+	}
+	if !cs.StoreMessages {
+		h.reply(chatID, T(cs, "ask.storage_off"))
+		return
+	}
+	if msg, over := h.aiBudgetExceeded(chatID); over {
+		h.reply(chatID, msg)
+		return
+	}
+	logger := logging.FromContext(ctx)
+	since := time.Now().Add(-time.Duration(hours) * time.Hour).Unix()
+	h.flushMessages(ctx)
+	recent, err := h.store.FetchMessages(chatID, threadID, since)
+	if err != nil {
+		logger.Error("ask: fetch failed", "chat_id", chatID, "err", err)
+		h.reply(chatID, T(cs, "actions.history_failed")+err.Error())
+		return
+	}
+	// drop the /ask itself and other commands, they only add noise
+	recent = slices.DeleteFunc(recent, func(m storage.Message) bool { return strings.HasPrefix(m.Text, "/") })
+	matched, err := h.store.SearchMessages(chatID, threadID, askTerms(question), since, askMaxMatches)
+	if err != nil {
+		logger.Warn("ask: search failed", "chat_id", chatID, "err", err)
+	}
+	msgs := askContext(recent, matched, askCharBudget)
+	if len(msgs) == 0 {
+		h.reply(chatID, T(cs, "ask.none", hours))
+		return
+	}
+
+	loc := chatClock(cs)
+	transcript := h.summaryTranscript(ctx, chatID, msgs)
+	for i, m := range msgs {
+		transcript[i].Time = time.Unix(m.Ts, 0).In(loc).Format("Mon Jan 2 15:04")
+	}
+	ctx, cancel := context.WithTimeout(ctx, 45*time.Second)
+	defer cancel()
+	answer, err := h.summarize.Answer(ctx, question, transcript)
+	if err != nil {
+		h.reply(chatID, T(cs, "ask.failed")+err.Error())
+		return
+	}
+	logger.Info("ask: answered", "chat_id", chatID, "recent", len(recent), "matched", len(matched), "used", len(msgs))
+	h.reply(chatID, answer)
+}
@@ -0,0 +1,50 @@
+package telegram
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"telegramBotTrade/internal/finance"
+	"telegramBotTrade/internal/logging"
+)
+
+const (
+	atrDefaultWindow = "6m"
+	atrMaxPeriod     = 100
+)
+
+// handleATR sends daily closes with close ± k·ATR stop lines for sym.
+func (h *Handlers) handleATR(ctx context.Context, chatID int64, sym, periodArg, window string) {
+	period := finance.DefaultATRPeriod
+	if periodArg != "" {
+		p, err := strconv.Atoi(periodArg)
+		if err != nil || p < 2 || p > atrMaxPeriod {
+			h.reply(chatID, h.t(chatID, "atr.bad_period", atrMaxPeriod))
+			return
+		}
+		period = p
+	}
+	if window == "" {
+		window = atrDefaultWindow
+	}
+	ctx, cancel := context.WithTimeout(ctx, 60*time.Second)
+	defer cancel()
+	ctx, fresh := finance.WithFreshness(ctx)
+	cs := h.chartSettings(chatID)
+	img, sum, err := finance.MakeATRChart(ctx, sym, period, window, renderOptions(ctx, cs))
+	if err != nil {
+		logging.FromContext(ctx).Error("atr failed", "chat_id", chatID, "symbol", sym, "err", err)
+		h.replyFailure(chatID, T(cs, "atr.failed"), err)
+		return
+	}
+	photo := tgbotapi.NewPhoto(chatID, tgbotapi.FileBytes{Name: sym + "_atr.png", Bytes: img})
+	photo.Caption = T(cs, "atr.caption",
+		strings.ToUpper(sym), sum.Period, sum.ATR, sum.Pct, sum.Price, sum.Multiple, sum.LongStop, sum.ShortStop)
+	photo.Caption = demoCaption(freshCaption(cs, photo.Caption, fresh))
+	h.api.Send(photo)
+	markDelivered(ctx)
+}
@@ -1,57 +1,309 @@
 package telegram
 
 import (
+	"context"
 	"encoding/json"
-	"log"
+	"errors"
+	"io"
+	"log/slog"
 	"net/http"
+	"sync"
+	"time"
 
+	"telegramBotTrade/internal/logging"
 	"telegramBotTrade/internal/storage"
+	"telegramBotTrade/internal/version"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 )
 
 type Bot struct {
-	api   *tgbotapi.BotAPI
-	store *storage.Store
-	h     *Handlers
+	name      string // see Options.Name
+	api       *tgbotapi.BotAPI
+	store     *storage.Store
+	h         *Handlers
+	transport transport
+	pool      *workerPool
+	updates   *updateTracker
+
+	// cached getMe result for readiness checks
+	meMu      sync.Mutex
+	meChecked time.Time
+	meErr     error
 }
 
-func NewBot(token, webhookURL string, db storage.DB, openAIKey string) (*Bot, error) {
-	api, err := tgbotapi.NewBotAPI(token)
+const getMeCacheTTL = time.Minute
+
+// transport delivers Telegram updates to the bot until ctx is cancelled.
+type transport interface {
+	Name() string
+	Run(ctx context.Context, b *Bot) error
+}
+
+// webhookTransport receives updates through WebhookHandler; Run only waits for shutdown.
+type webhookTransport struct{}
+
+func (t *webhookTransport) Name() string { return "webhook" }
+
+func (t *webhookTransport) Run(ctx context.Context, _ *Bot) error {
+	<-ctx.Done()
+	return nil
+}
+
+// pollingTransport pulls updates with getUpdates for deployments without a public URL.
+type pollingTransport struct{ timeout int }
+
+func (t *pollingTransport) Name() string { return "polling" }
+
+func (t *pollingTransport) Run(ctx context.Context, b *Bot) error {
+	updates := make(chan polledUpdate, 100)
+	go t.poll(ctx, b.api, updates)
+	for {
+		select {
+		case <-ctx.Done():
+			slog.Info("telegram: polling stopped")
+			return nil
+		case u := <-updates:
+			_ = b.dispatch(u.update, u.threadID)
+		}
+	}
+}
+
+type polledUpdate struct {
+	update   tgbotapi.Update
+	threadID int
+}
+
+// poll long-polls getUpdates until ctx is cancelled. It decodes the raw
+// updates itself so forum topic IDs survive (see decodeUpdate).
+func (t *pollingTransport) poll(ctx context.Context, api *tgbotapi.BotAPI, out chan<- polledUpdate) {
+	offset := 0
+	for ctx.Err() == nil {
+		params := tgbotapi.Params{}
+		params.AddNonZero("offset", offset)
+		params.AddNonZero("timeout", t.timeout)
+		resp, err := api.MakeRequest("getUpdates", params)
+		var raws []json.RawMessage
+		if err == nil {
+			err = json.Unmarshal(resp.Result, &raws)
+		}
+		if err != nil {
+			slog.Warn("telegram: getUpdates failed, retrying in 3 seconds", "err", err)
+			select {
+			case <-ctx.Done():
+			case <-time.After(3 * time.Second):
+			}
+			continue
+		}
+		for _, raw := range raws {
+			update, threadID, err := decodeUpdate(raw)
+			if err != nil {
+				slog.Warn("telegram: undecodable update skipped", "err", err)
+				continue
+			}
+			if update.UpdateID < offset {
+				continue
+			}
+			offset = update.UpdateID + 1
+			select {
+			case out <- polledUpdate{update: update, threadID: threadID}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}
+}
+
+// Options configures a Bot.
+type Options struct {
+	// Name identifies the bot when one process serves several; empty is the
+	// primary bot. Usage analytics and update offsets are kept per bot, and
+	// only the primary bot runs schedules, alerts, the name backfill and the
+	// weekly report, since those tables are shared by chat.
+	Name       string
+	Token      string
+	WebhookURL string // empty selects long polling
+	OpenAIKey  string
+	Workers    int // handler goroutines (default 16)
+	QueueSize  int // pending updates before new ones are rejected (default 256)
+	// PerChatOrder serializes handling per chat_id so one chat's commands never interleave
+	PerChatOrder bool
+	AdminChatID  int64               // chat allowed to run admin commands (0 = none)
+	About        func() version.Info // build/runtime info for /version
+	// TargetExpiryDays is how long /target entries live (default 30)
+	TargetExpiryDays int
+	// WeeklyReport sends AdminChatID a cross-chat usage report every Monday
+	WeeklyReport bool
+	// AIDailyLimit caps LLM requests per chat per day (default 50)
+	AIDailyLimit int
+}
+
+// NewBot creates the bot. A non-empty WebhookURL registers a webhook; an empty
+// one deletes any existing webhook and falls back to long polling.
+func NewBot(opts Options, db storage.DB) (*Bot, error) {
+	api, err := tgbotapi.NewBotAPI(opts.Token)
 	if err != nil {
 		return nil, err
 	}
 
-	// set webhook
-	webhook, err := tgbotapi.NewWebhook(webhookURL)
+	webhookURL := opts.WebhookURL
+	var t transport
+	if webhookURL != "" {
+		// set webhook
+		webhook, err := tgbotapi.NewWebhook(webhookURL)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := api.Request(webhook); err != nil {
+			return nil, err
+		}
+		slog.Info("telegram: webhook set", "url", webhookURL)
+		t = &webhookTransport{}
+	} else {
+		// getUpdates is rejected while a webhook is registered
+		if _, err := api.Request(tgbotapi.DeleteWebhookConfig{}); err != nil {
+			return nil, err
+		}
+		slog.Info("telegram: webhook deleted, using long polling")
+		t = &pollingTransport{timeout: 60}
+	}
+
+	for lang, keys := range missingMessages() {
+		if len(keys) > 0 {
+			slog.Warn("telegram: messages missing from catalog, falling back to English", "lang", lang, "keys", keys)
+		}
+	}
+
+	s := storage.NewStore(db).ForBot(opts.Name)
+	h := NewHandlers(api, s, opts.OpenAIKey)
+	h.adminChatID = opts.AdminChatID
+	h.about = opts.About
+	h.targetExpiryDays = opts.TargetExpiryDays
+	h.weeklyReport = opts.WeeklyReport
+	h.aiDailyLimit = opts.AIDailyLimit
+
+	b := &Bot{name: opts.Name, api: api, store: s, h: h, transport: t, updates: newUpdateTracker(s)}
+	b.pool = newWorkerPool(opts.Workers, opts.QueueSize, opts.PerChatOrder, h.HandleMessage, func(_ context.Context, m *tgbotapi.Message) {
+		h.reply(m.Chat.ID, h.t(m.Chat.ID, "error.panic"))
+	})
+	h.queueDepth = b.pool.depth
+	return b, nil
+}
+
+// CheckToken calls getMe with token and returns the bot's username.
+func CheckToken(token string) (string, error) {
+	api, err := tgbotapi.NewBotAPI(token)
 	if err != nil {
-		return nil, err
+		return "", err
 	}
-	if _, err := api.Request(webhook); err != nil {
-		return nil, err
+	return api.Self.UserName, nil
+}
+
+// Mode reports the active update transport ("webhook" or "polling").
+func (b *Bot) Mode() string { return b.transport.Name() }
+
+// Run receives updates until ctx is cancelled.
+func (b *Bot) Run(ctx context.Context) error {
+	slog.Info("telegram: receiving updates", "bot", b.name, "transport", b.transport.Name())
+	if b.name == "" {
+		go b.runScheduler(ctx)
+		go b.runAlertPoller(ctx)
+		go b.runNameBackfill(ctx)
 	}
-	log.Printf("telegram: webhook set to %s", webhookURL)
+	return b.transport.Run(ctx, b)
+}
 
-	s := storage.NewStore(db)
-	h := NewHandlers(api, s, openAIKey)
+// CheckTelegram calls getMe to confirm the token is still valid. Results are
+// cached for a minute so frequent readiness probes don't hit the Bot API.
+func (b *Bot) CheckTelegram(_ context.Context) error {
+	b.meMu.Lock()
+	defer b.meMu.Unlock()
+	if !b.meChecked.IsZero() && time.Since(b.meChecked) < getMeCacheTTL {
+		return b.meErr
+	}
+	_, err := b.api.GetMe()
+	b.meChecked = time.Now()
+	b.meErr = err
+	return err
+}
 
-	return &Bot{api: api, store: s, h: h}, nil
+// dispatch hands a single update to the worker pool regardless of transport.
+// It returns errQueueFull when the update was dropped.
+func (b *Bot) dispatch(update tgbotapi.Update, threadID int) error {
+	// every log line for this update carries the same request_id
+	ctx := logging.WithRequestID(context.Background(), logging.NewRequestID())
+	ctx = withThread(ctx, threadID)
+	logger := logging.FromContext(ctx).With("update_id", update.UpdateID)
+	if cq := update.CallbackQuery; cq != nil {
+		if cq.Message != nil {
+			b.h.chats.reactivate(cq.Message.Chat.ID)
+		}
+		b.handleCallback(ctx, cq)
+		return nil
+	}
+	msg := update.Message
+	if msg == nil {
+		// channel posts have no From; handlers fall back to SenderChat
+		msg = update.ChannelPost
+	}
+	if msg == nil {
+		logger.Debug("update: non-message update received")
+		return nil
+	}
+	if !b.updates.accept(msg.Chat.ID, update.UpdateID) {
+		logger.Info("update: duplicate skipped", "chat_id", msg.Chat.ID)
+		return nil
+	}
+	logger.Info("update: message received", "chat_id", msg.Chat.ID, "thread_id", threadID, "from", senderID(msg), "channel_post", update.ChannelPost != nil)
+	b.h.chats.reactivate(msg.Chat.ID)
+	logger.Debug("update: message text", "text", msg.Text)
+	if err := b.pool.submit(job{ctx: ctx, msg: msg}); err != nil {
+		logger.Warn("update: dropped", "chat_id", msg.Chat.ID, "queue_depth", b.pool.depth(), "err", err)
+		// Telegram redelivers the update after the error; let that copy in
+		b.updates.forget(msg.Chat.ID, update.UpdateID)
+		return err
+	}
+	return nil
 }
 
-// Webhook HTTP handler (registered at /telegram/webhook)
+// Shutdown optionally deletes the webhook and then waits up to timeout for
+// in-flight handlers to finish. It reports whether all handlers drained.
+func (b *Bot) Shutdown(deleteWebhook bool, timeout time.Duration) bool {
+	if deleteWebhook && b.transport.Name() == "webhook" {
+		if _, err := b.api.Request(tgbotapi.DeleteWebhookConfig{}); err != nil {
+			slog.Error("telegram: delete webhook on shutdown failed", "err", err)
+		} else {
+			slog.Info("telegram: webhook deleted on shutdown")
+		}
+	}
+	drained := b.pool.stop(timeout)
+	b.updates.close()
+	if err := b.h.messages.Close(); err != nil {
+		slog.Error("telegram: flushing stored messages on shutdown failed", "err", err)
+	}
+	return drained
+}
+
+// Webhook HTTP handler (registered at the bot's /telegram/webhook path)
 func (b *Bot) WebhookHandler(w http.ResponseWriter, r *http.Request) {
-	var update tgbotapi.Update
-	if err := json.NewDecoder(r.Body).Decode(&update); err != nil {
+	raw, err := io.ReadAll(r.Body)
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		http.Error(w, "update too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+	if err != nil {
 		http.Error(w, "bad update", 400)
 		return
 	}
-	if update.Message != nil {
-		log.Printf("webhook: chat_id=%d from=%d text=%q", update.Message.Chat.ID, update.Message.From.ID, update.Message.Text)
-	} else {
-		log.Printf("webhook: non-message update received")
+	update, threadID, err := decodeUpdate(raw)
+	if err != nil {
+		http.Error(w, "bad update", 400)
+		return
 	}
-	if update.Message != nil {
-		go b.h.HandleMessage(update.Message)
+	if err := b.dispatch(update, threadID); err != nil {
+		http.Error(w, "busy", http.StatusUnprocessableEntity)
+		return
 	}
 	w.WriteHeader(http.StatusOK)
 }
@@ -0,0 +1,231 @@
+package telegram
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"telegramBotTrade/internal/finance"
+	"telegramBotTrade/internal/logging"
+)
+
+// briefCommand is the command stored in schedules for a chat's morning brief.
+const briefCommand = "/brief now"
+
+// marketSymbols make up the snapshot section of the morning brief.
+var marketSymbols = []string{"SPY", "QQQ", "DIA", "IWM", "^VIX", "^TNX", "GLD", "CL=F", "BTC-USD"}
+
+// handleBrief subscribes the chat to a weekday morning brief, which is stored
+// as a schedule running /brief now.
+func (h *Handlers) handleBrief(ctx context.Context, chatID int64, args string) {
+	cs := h.chartSettings(chatID)
+	fields := strings.Fields(strings.ToLower(args))
+	if len(fields) == 0 {
+		h.reply(chatID, T(cs, "brief.usage"))
+		return
+	}
+	switch fields[0] {
+	case "now":
+		h.sendBrief(ctx, chatID)
+	case "off":
+		n, err := h.removeBriefSchedules(chatID)
+		if err != nil {
+			h.reply(chatID, T(cs, "brief.update_failed")+err.Error())
+			return
+		}
+		if n == 0 {
+			h.reply(chatID, T(cs, "brief.none"))
+			return
+		}
+		h.reply(chatID, T(cs, "brief.off"))
+	case "on":
+		if len(fields) != 2 {
+			h.reply(chatID, T(cs, "brief.usage"))
+			return
+		}
+		g := reScheduleTime.FindStringSubmatch(fields[1])
+		if g == nil {
+			h.reply(chatID, T(cs, "brief.usage"))
+			return
+		}
+		// one brief per chat: replace any existing subscription
+		if _, err := h.removeBriefSchedules(chatID); err != nil {
+			h.reply(chatID, T(cs, "brief.update_failed")+err.Error())
+			return
+		}
+		hh, _ := strconv.Atoi(g[1])
+		spec := fmt.Sprintf("%02d:%s %s", hh, g[2], weekdaySpec)
+		if _, err := h.store.AddSchedule(chatID, spec, briefCommand, time.Now().Unix()); err != nil {
+			h.reply(chatID, T(cs, "brief.save_failed")+err.Error())
+			return
+		}
+		h.reply(chatID, T(cs, "brief.on", hh, g[2], chatClock(cs)))
+	default:
+		h.reply(chatID, T(cs, "brief.usage"))
+	}
+}
+
+func (h *Handlers) removeBriefSchedules(chatID int64) (int, error) {
+	list, err := h.store.FetchSchedules(chatID)
+	if err != nil {
+		return 0, err
+	}
+	n := 0
+	for _, sc := range list {
+		if sc.Command != briefCommand {
+			continue
+		}
+		if _, err := h.store.DeleteSchedule(chatID, sc.ID); err != nil {
+			return n, err
+		}
+		n++
+	}
+	return n, nil
+}
+
+func (h *Handlers) sendBrief(ctx context.Context, chatID int64) {
+	// scheduled briefs skip US market holidays; /brief now always runs
+	if holiday := finance.USHoliday(h.briefDay(chatID)); holiday != "" && isScheduled(ctx) {
+		h.reply(chatID, h.t(chatID, "brief.holiday", holiday))
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, 60*time.Second)
+	defer cancel()
+	text := h.composeBrief(ctx, chatID)
+	if text == "" {
+		h.reply(chatID, h.t(chatID, "brief.failed"))
+		return
+	}
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ParseMode = "HTML"
+	sent, err := h.api.Send(msg)
+	if err != nil {
+		logging.FromContext(ctx).Error("brief: send failed", "chat_id", chatID, "err", err)
+		return
+	}
+	if isScheduled(ctx) {
+		h.pinBrief(ctx, chatID, sent.MessageID)
+	}
+}
+
+// composeBrief builds the morning brief. Each section is dropped on its own
+// when its data can't be fetched; it returns "" only when nothing is left.
+func (h *Handlers) composeBrief(ctx context.Context, chatID int64) string {
+	logger := logging.FromContext(ctx).With("chat_id", chatID)
+	cs := h.chartSettings(chatID)
+	var sections []string
+	var movers []finance.Quote
+
+	market, errs := finance.FetchBatchQuotes(ctx, marketSymbols)
+	if len(errs) > 0 {
+		logger.Warn("brief: market quotes failed", "failed", len(errs))
+	}
+	if len(market) > 0 {
+		sections = append(sections, T(cs, "brief.market")+"\n"+quoteTable(marketSymbols, market, false))
+	}
+
+	watch, err := h.store.FetchWatchlist(chatID)
+	if err != nil {
+		logger.Error("brief: watchlist load failed", "err", err)
+	}
+	if len(watch) > 0 {
+		quotes, errs := finance.FetchBatchQuotes(ctx, watch)
+		if len(errs) > 0 {
+			logger.Warn("brief: watchlist quotes failed", "failed", len(errs))
+		}
+		if len(quotes) > 0 {
+			sections = append(sections, T(cs, "brief.watchlist")+"\n"+quoteTable(watch, quotes, true))
+			for _, q := range quotes {
+				movers = append(movers, q)
+			}
+		}
+	}
+	if len(sections) == 0 {
+		return ""
+	}
+	if len(movers) == 0 {
+		for _, q := range market {
+			movers = append(movers, q)
+		}
+	}
+
+	if comment := h.moverComment(ctx, movers); comment != "" {
+		sections = append(sections, "<i>"+html.EscapeString(comment)+"</i>")
+	}
+	day := h.briefDay(chatID)
+	header := T(cs, "brief.title") + " • " + day.Format("Mon Jan 2")
+	if finance.USEarlyClose(day) {
+		header += "\n" + T(cs, "brief.early_close")
+	}
+	return header + "\n\n" + strings.Join(sections, "\n\n")
+}
+
+// briefDay is noon Eastern on the chat's current date, so the market
+// calendar is read for the day the chat is starting, not for whatever date
+// it is in New York.
+func (h *Handlers) briefDay(chatID int64) time.Time {
+	y, m, d := time.Now().In(chatClock(h.chartSettings(chatID))).Date()
+	return time.Date(y, m, d, 12, 0, 0, 0, finance.DefaultLocation())
+}
+
+// moverComment asks OpenAI for a short comment on the largest absolute mover.
+// Failures only drop the comment.
+func (h *Handlers) moverComment(ctx context.Context, quotes []finance.Quote) string {
+	if len(quotes) == 0 {
+		return ""
+	}
+	sort.Slice(quotes, func(i, j int) bool { return math.Abs(quotes[i].ChangePct) > math.Abs(quotes[j].ChangePct) })
+	var facts strings.Builder
+	for _, q := range quotes {
+		fmt.Fprintf(&facts, "%s %.2f %+.2f%%\n", q.Symbol, q.Price, q.ChangePct)
+	}
+	top := quotes[0]
+	comment, err := h.summarize.MoverComment(ctx, fmt.Sprintf("%s %+.2f%%", top.Symbol, top.ChangePct), facts.String())
+	if err != nil {
+		return ""
+	}
+	return comment
+}
+
+// quoteTable renders quotes as a monospace table in symbols order, or sorted by
+// absolute move when byMove is set. Missing symbols are skipped.
+func quoteTable(symbols []string, quotes map[string]finance.Quote, byMove bool) string {
+	rows := make([]finance.Quote, 0, len(quotes))
+	for _, s := range symbols {
+		if q, ok := quotes[s]; ok {
+			rows = append(rows, q)
+		}
+	}
+	if byMove {
+		sort.SliceStable(rows, func(i, j int) bool { return math.Abs(rows[i].ChangePct) > math.Abs(rows[j].ChangePct) })
+	}
+	var b strings.Builder
+	b.WriteString("<pre>")
+	for _, q := range rows {
+		fmt.Fprintf(&b, "%-8s %10.2f %s %+6.2f%%\n", html.EscapeString(q.Symbol), q.Price, moveArrow(q.ChangePct), q.ChangePct)
+	}
+	b.WriteString("</pre>")
+	if finance.DemoMode() {
+		b.WriteString("\n" + demoNote)
+	}
+	return b.String()
+}
+
+// moveArrow marks a percent move as up or down.
+func moveArrow(pct float64) string {
+	switch {
+	case pct > 0:
+		return "🟢▲"
+	case pct < 0:
+		return "🔴▼"
+	default:
+		return "⚪•"
+	}
+}
@@ -0,0 +1,101 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"telegramBotTrade/internal/logging"
+)
+
+// broadcastDelay spaces sends to stay well under Telegram's 30 messages/s limit.
+const broadcastDelay = 50 * time.Millisecond
+
+// sendOutcome classifies a failed send.
+type sendOutcome int
+
+const (
+	sendFailed      sendOutcome = iota
+	sendUnreachable             // blocked, kicked or chat gone: skipping is expected
+	sendRateLimited             // 429; RetryAfter says how long to wait
+)
+
+// classifySendError sorts a Telegram API error into a sendOutcome.
+func classifySendError(err error) (sendOutcome, time.Duration) {
+	var tgErr *tgbotapi.Error
+	if !errors.As(err, &tgErr) {
+		return sendFailed, 0
+	}
+	msg := strings.ToLower(tgErr.Message)
+	switch {
+	case tgErr.Code == 429:
+		return sendRateLimited, time.Duration(tgErr.RetryAfter) * time.Second
+	case tgErr.Code == 403,
+		strings.Contains(msg, "chat not found"),
+		strings.Contains(msg, "bot was blocked"),
+		strings.Contains(msg, "bot was kicked"),
+		strings.Contains(msg, "user is deactivated"),
+		strings.Contains(msg, "group chat was upgraded"):
+		return sendUnreachable, 0
+	}
+	return sendFailed, 0
+}
+
+// handleBroadcast sends text to every known chat from the admin chat and
+// reports how many deliveries succeeded, were skipped or failed.
+func (h *Handlers) handleBroadcast(ctx context.Context, chatID int64, text string) {
+	if !h.isAdmin(chatID) {
+		h.reply(chatID, h.t(chatID, "admin.only"))
+		return
+	}
+	if text == "" {
+		h.reply(chatID, h.t(chatID, "broadcast.usage"))
+		return
+	}
+	ids, err := h.store.ListChatIDs()
+	if err != nil {
+		h.reply(chatID, h.t(chatID, "broadcast.list_failed")+err.Error())
+		return
+	}
+	logger := logging.FromContext(ctx)
+	h.reply(chatID, h.t(chatID, "broadcast.started", len(ids)))
+	var sent, skipped, failed int
+	for _, id := range ids {
+		if ctx.Err() != nil {
+			break
+		}
+		err := h.sendBroadcast(ctx, id, text)
+		outcome, _ := classifySendError(err)
+		switch {
+		case err == nil:
+			sent++
+		case outcome == sendUnreachable:
+			skipped++
+			logger.Info("broadcast: chat unreachable", "chat_id", id, "err", err)
+		default:
+			failed++
+			logger.Warn("broadcast: send failed", "chat_id", id, "err", err)
+		}
+		time.Sleep(broadcastDelay)
+	}
+	h.reply(chatID, h.t(chatID, "broadcast.done", sent, skipped, failed, len(ids)-sent-skipped-failed))
+}
+
+// sendBroadcast sends one broadcast message, retrying once after a 429.
+func (h *Handlers) sendBroadcast(ctx context.Context, chatID int64, text string) error {
+	_, err := h.api.Send(tgbotapi.NewMessage(chatID, text))
+	outcome, wait := classifySendError(err)
+	if err == nil || outcome != sendRateLimited {
+		return err
+	}
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(wait + time.Second):
+	}
+	_, err = h.api.Send(tgbotapi.NewMessage(chatID, text))
+	return err
+}
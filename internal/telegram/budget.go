@@ -0,0 +1,29 @@
+package telegram
+
+import "time"
+
+// aiCommands are the tracked commands that call the LLM; together they count
+// against a chat's daily AI budget.
+var aiCommands = []string{"summary", "recommend", "chart", "ask", "translate", "actions"}
+
+// defaultAIDailyLimit is the per-chat AI budget when AI_DAILY_LIMIT is unset.
+const defaultAIDailyLimit = 50
+
+// aiBudgetExceeded reports whether chatID has used more LLM requests today, in
+// the chat's time zone, than the daily budget allows. The command being
+// handled has already been tracked, so it is included in the count. A failing
+// lookup doesn't block the request.
+func (h *Handlers) aiBudgetExceeded(chatID int64) (string, bool) {
+	limit := h.aiDailyLimit
+	if limit <= 0 {
+		limit = defaultAIDailyLimit
+	}
+	cs := h.chartSettings(chatID)
+	now := time.Now().In(chatClock(cs))
+	midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	used, err := h.store.CountCommandsSince(chatID, aiCommands, midnight.Unix())
+	if err != nil || used <= limit {
+		return "", false
+	}
+	return T(cs, "budget.exceeded", limit, now.Location()), true
+}
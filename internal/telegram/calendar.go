@@ -0,0 +1,79 @@
+package telegram
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"telegramBotTrade/internal/finance"
+	"telegramBotTrade/internal/logging"
+)
+
+// handleCalendar lists high-impact US releases for the rest of this week, or
+// the whole Monday-Friday week with "/calendar week", in the chat's time zone.
+func (h *Handlers) handleCalendar(ctx context.Context, chatID int64, arg string) {
+	arg = strings.ToLower(strings.TrimSpace(arg))
+	cs := h.chartSettings(chatID)
+	if arg != "" && arg != "week" {
+		h.reply(chatID, T(cs, "calendar.usage"))
+		return
+	}
+	loc := chatClock(cs)
+	now := time.Now().In(loc)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+	monday := today.AddDate(0, 0, -((int(today.Weekday()) + 6) % 7))
+	from := today
+	if arg == "week" {
+		from = monday
+	}
+	to := monday.AddDate(0, 0, 5)
+	if !from.Before(to) {
+		h.reply(chatID, T(cs, "calendar.week_over"))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 20*time.Second)
+	defer cancel()
+	events, err := h.calendar.Events(ctx)
+	if err != nil {
+		logging.FromContext(ctx).Error("calendar failed", "chat_id", chatID, "source", h.calendar.Name(), "err", err)
+		h.reply(chatID, T(cs, "calendar.failed")+err.Error())
+		return
+	}
+	events = finance.HighImpactUS(events, from, to)
+
+	var b strings.Builder
+	b.WriteString(T(cs, "calendar.title", loc.String()) + "\n")
+	i := 0
+	for day := from; day.Before(to); day = day.AddDate(0, 0, 1) {
+		next := day.AddDate(0, 0, 1)
+		fmt.Fprintf(&b, "\n<b>%s</b>\n", day.Format("Mon Jan 2"))
+		n := 0
+		for ; i < len(events) && events[i].Time.Before(next); i++ {
+			e := events[i]
+			fmt.Fprintf(&b, "%s %s", e.Time.In(loc).Format("15:04"), html.EscapeString(e.Title))
+			var extra []string
+			if e.Forecast != "" {
+				extra = append(extra, T(cs, "calendar.forecast")+html.EscapeString(e.Forecast))
+			}
+			if e.Previous != "" {
+				extra = append(extra, T(cs, "calendar.previous")+html.EscapeString(e.Previous))
+			}
+			if len(extra) > 0 {
+				b.WriteString(" (" + strings.Join(extra, ", ") + ")")
+			}
+			b.WriteString("\n")
+			n++
+		}
+		if n == 0 {
+			b.WriteString(T(cs, "calendar.nothing") + "\n")
+		}
+	}
+	msg := tgbotapi.NewMessage(chatID, b.String())
+	msg.ParseMode = "HTML"
+	h.api.Send(msg)
+}
@@ -0,0 +1,89 @@
+package telegram
+
+import (
+	"fmt"
+	"strings"
+
+	"telegramBotTrade/internal/finance"
+	"telegramBotTrade/internal/storage"
+)
+
+// demoNote marks replies built from DEMO_MODE prices.
+const demoNote = "🧪 Demo data: synthetic prices, do not trade on them"
+
+// demoCaption puts demoNote in front of a chart caption under DEMO_MODE.
+func demoCaption(caption string) string {
+	if !finance.DemoMode() {
+		return caption
+	}
+	return demoNote + "\n" + caption
+}
+
+// priceLine is the caption line of a single-symbol chart: the last price and
+// the change over the window drawn, e.g. "Last 512.30 (+1.84%)".
+func priceLine(cs storage.ChatSettings, meta finance.ChartMeta) string {
+	if meta.Points == 0 {
+		return ""
+	}
+	return "\n" + T(cs, "caption.last", meta.LastPrice, meta.Change)
+}
+
+// changesLine lists each symbol's change over a multi-symbol chart's window,
+// in legend order, e.g. "SPY +1.2% • QQQ -0.4%".
+func changesLine(meta finance.ChartMeta) string {
+	if len(meta.Changes) == 0 {
+		return ""
+	}
+	parts := make([]string, 0, len(meta.Symbols))
+	for _, s := range meta.Symbols {
+		if c, ok := meta.Changes[s]; ok {
+			parts = append(parts, fmt.Sprintf("%s %+.1f%%", s, c))
+		}
+	}
+	return "\n" + strings.Join(parts, " • ")
+}
+
+// statsLine summarizes a portfolio chart's statistics.
+func statsLine(cs storage.ChatSettings, meta finance.ChartMeta) string {
+	st := meta.Portfolio
+	if st == nil {
+		return ""
+	}
+	return "\n" + T(cs, "caption.stats", st.TotalReturn, st.SharpeRatio, st.Volatility, st.MaxDrawdown, st.NumDays) +
+		truncatedLine(cs, st) + wipedOutLine(cs, st)
+}
+
+// wipedOutLine says where shorts or margin took a backtest to zero.
+func wipedOutLine(cs storage.ChatSettings, st *finance.PortfolioStats) string {
+	if st.WipedOutAt.IsZero() {
+		return ""
+	}
+	return "\n" + T(cs, "caption.wiped_out", st.WipedOutAt.Format("2006-01-02"))
+}
+
+// truncatedLine warns that a portfolio backtest covers less than the window
+// asked for because one asset's history is shorter.
+func truncatedLine(cs storage.ChatSettings, st *finance.PortfolioStats) string {
+	if st.TruncatedTo.IsZero() {
+		return ""
+	}
+	return "\n" + T(cs, "caption.truncated", st.TruncatedTo.Format("2006-01-02"), st.LimitedBy)
+}
+
+// shownInterval is the interval a chart actually drew, which differs from the
+// requested one when a long window was resampled or clamped.
+func shownInterval(meta finance.ChartMeta, requested string) string {
+	if meta.Interval != "" {
+		return strings.ToUpper(meta.Interval)
+	}
+	return strings.ToUpper(requested)
+}
+
+// clampCaption puts the notice of a window shortened to interval's limit in
+// front of caption, so a "1m 1y" request doesn't pass for a year of minutes.
+func clampCaption(caption, interval, window string) string {
+	if note := finance.ClampNotice(interval, window); note != "" {
+		return "⚠️ " + note + "\n" + caption
+	}
+	return caption
+}
@@ -0,0 +1,123 @@
+package telegram
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"telegramBotTrade/internal/finance"
+	"telegramBotTrade/internal/logging"
+)
+
+const (
+	// cashtagCooldownTTL is how long a chat waits before the same cashtag gets
+	// another automatic reply.
+	cashtagCooldownTTL = 10 * time.Minute
+	// cashtagMaxPerMessage caps the symbols answered for one message.
+	cashtagMaxPerMessage = 3
+)
+
+// cashtagModes are the values accepted by /set cashtags.
+var cashtagModes = []string{"quote", "chart"}
+
+// cashtagCooldown remembers when each chat last got an automatic reply for a
+// symbol, so a busy thread about $NVDA gets one quote, not one per message.
+type cashtagCooldown struct {
+	mu   sync.Mutex
+	ttl  time.Duration
+	last map[string]time.Time
+}
+
+func newCashtagCooldown(ttl time.Duration) *cashtagCooldown {
+	return &cashtagCooldown{ttl: ttl, last: map[string]time.Time{}}
+}
+
+// allow reports whether chatID may get a reply for sym at now and, if so,
+// starts its cooldown. Expired entries are dropped as the map grows.
+func (c *cashtagCooldown) allow(chatID int64, sym string, now time.Time) bool {
+	key := fmt.Sprintf("%d:%s", chatID, sym)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if t, ok := c.last[key]; ok && now.Sub(t) < c.ttl {
+		return false
+	}
+	if len(c.last) >= 1000 {
+		for k, t := range c.last {
+			if now.Sub(t) >= c.ttl {
+				delete(c.last, k)
+			}
+		}
+	}
+	c.last[key] = now
+	return true
+}
+
+// cashtagSymbols returns the distinct cashtags of a plain (non-command)
+// message, at most cashtagMaxPerMessage of them.
+func cashtagSymbols(text string) []string {
+	if strings.HasPrefix(text, "/") {
+		return nil
+	}
+	syms, _ := tickerMentions(text)
+	if len(syms) > cashtagMaxPerMessage {
+		syms = syms[:cashtagMaxPerMessage]
+	}
+	return syms
+}
+
+// autoCashtags answers $TICKER mentions in m with a quote or a 1d chart,
+// depending on the chat's /set cashtags mode.
+func (h *Handlers) autoCashtags(ctx context.Context, m *tgbotapi.Message, text string) {
+	syms := cashtagSymbols(text)
+	if len(syms) == 0 {
+		return
+	}
+	cs := h.chartSettings(m.Chat.ID)
+	if cs.Cashtags == "" {
+		return
+	}
+	now := time.Now()
+	var due []string
+	for _, s := range syms {
+		if validSymbol(s) && h.cashtags.allow(m.Chat.ID, s, now) {
+			due = append(due, s)
+		}
+	}
+	if len(due) == 0 {
+		return
+	}
+	logger := logging.FromContext(ctx)
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	if cs.Cashtags == "chart" {
+		opts := renderOptions(ctx, cs)
+		for _, s := range due {
+			res, err := finance.MakeChart(ctx, s, "5m", "1d", opts)
+			if err != nil {
+				// unknown symbols and busy renders stay silent; nobody asked
+				logger.Info("cashtags: chart skipped", "chat_id", m.Chat.ID, "symbol", s, "err", err)
+				continue
+			}
+			h.sendChart(m.Chat.ID, s+"_1d", T(cs, "cashtags.caption", s)+priceLine(cs, res.Meta), res.Image, opts)
+		}
+		return
+	}
+
+	quotes, errs := finance.FetchQuotes(ctx, due)
+	for s, err := range errs {
+		logger.Info("cashtags: quote skipped", "chat_id", m.Chat.ID, "symbol", s, "err", err)
+	}
+	if len(quotes) == 0 {
+		return
+	}
+	msg := tgbotapi.NewMessage(m.Chat.ID, quoteTable(due, quotes, false))
+	msg.ParseMode = "HTML"
+	msg.ReplyToMessageID = m.MessageID
+	msg.DisableNotification = true
+	h.api.Send(msg)
+}
@@ -0,0 +1,72 @@
+package telegram
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestCashtagSymbols(t *testing.T) {
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"$NVDA looks strong", []string{"NVDA"}},
+		{"$nvda and $NVDA", []string{"NVDA"}},
+		{"$TSLA's delivery numbers", []string{"TSLA"}},
+		{"$brk.b $BTC-USD $GC=F", []string{"BRK.B", "BTC-USD", "GC=F"}},
+		{"$AAPL $MSFT $GOOG $AMZN", []string{"AAPL", "MSFT", "GOOG"}}, // at most three
+		{"spent $100 on NVDA", nil},                                   // prices and bare words aren't cashtags
+		{"$TOOLONGX is not a ticker", nil},
+		{"/stockx $NVDA", nil}, // commands answer themselves
+		{"", nil},
+	}
+	for _, tc := range tests {
+		if got := cashtagSymbols(tc.text); !slices.Equal(got, tc.want) {
+			t.Errorf("cashtagSymbols(%q) = %v, want %v", tc.text, got, tc.want)
+		}
+	}
+}
+
+func TestCashtagCooldown(t *testing.T) {
+	c := newCashtagCooldown(10 * time.Minute)
+	t0 := time.Date(2024, 6, 3, 15, 0, 0, 0, time.UTC)
+	steps := []struct {
+		chatID int64
+		sym    string
+		at     time.Duration
+		want   bool
+	}{
+		{1, "NVDA", 0, true},
+		{1, "NVDA", time.Minute, false},
+		{1, "AMD", time.Minute, true},  // another symbol
+		{2, "NVDA", time.Minute, true}, // another chat
+		{1, "NVDA", 9*time.Minute + 59*time.Second, false},
+		{1, "NVDA", 10 * time.Minute, true}, // cooldown over
+		{1, "NVDA", 15 * time.Minute, false},
+	}
+	for i, s := range steps {
+		if got := c.allow(s.chatID, s.sym, t0.Add(s.at)); got != s.want {
+			t.Errorf("step %d: allow(%d, %s, +%v) = %v, want %v", i, s.chatID, s.sym, s.at, got, s.want)
+		}
+	}
+}
+
+func TestCashtagCooldownPrunes(t *testing.T) {
+	c := newCashtagCooldown(time.Minute)
+	t0 := time.Date(2024, 6, 3, 15, 0, 0, 0, time.UTC)
+	for i := range 1000 {
+		c.allow(int64(i), "NVDA", t0)
+	}
+	c.allow(1, "AMD", t0.Add(30*time.Second)) // nothing has expired yet
+	if len(c.last) != 1001 {
+		t.Fatalf("%d entries before expiry, want 1001", len(c.last))
+	}
+	c.allow(1, "MSFT", t0.Add(time.Minute))
+	if len(c.last) != 2 {
+		t.Errorf("%d entries after expiry, want the live AMD and MSFT ones", len(c.last))
+	}
+	if _, ok := c.last["1:AMD"]; !ok {
+		t.Error("an entry still cooling down was dropped")
+	}
+}
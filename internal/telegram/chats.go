@@ -0,0 +1,138 @@
+package telegram
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"telegramBotTrade/internal/storage"
+)
+
+// inactiveChatResponse answers requests to an inactive chat without calling
+// Telegram. It decodes into a 403 *tgbotapi.Error, so callers see the same
+// error as from a real block (see classifySendError).
+const inactiveChatResponse = `{"ok":false,"error_code":403,"description":"Forbidden: chat is inactive (bot was blocked or removed)"}`
+
+// chatGuard wraps the Bot API HTTP client and tracks which chats the bot can
+// no longer reach. A 403 saying the bot was blocked or kicked marks the chat
+// inactive in storage; later requests to it fail at once instead of going
+// out, and the scheduler and alert pollers skip it. The next message from
+// the chat makes it active again (see reactivate).
+type chatGuard struct {
+	next  tgbotapi.HTTPClient
+	store *storage.Store
+
+	mu       sync.Mutex
+	inactive map[int64]bool
+}
+
+// newChatGuard loads the inactive chats and installs a chatGuard as api's
+// HTTP client.
+func newChatGuard(api *tgbotapi.BotAPI, store *storage.Store) *chatGuard {
+	g := &chatGuard{next: api.Client, store: store, inactive: map[int64]bool{}}
+	ids, err := store.FetchInactiveChats()
+	if err != nil {
+		slog.Warn("chats: load inactive chats failed", "err", err)
+	}
+	for _, id := range ids {
+		g.inactive[id] = true
+	}
+	api.Client = g
+	return g
+}
+
+// active reports whether sends to chatID are still attempted.
+func (g *chatGuard) active(chatID int64) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return !g.inactive[chatID]
+}
+
+// reactivate marks chatID active again after it sent the bot a message.
+func (g *chatGuard) reactivate(chatID int64) {
+	g.mu.Lock()
+	was := g.inactive[chatID]
+	delete(g.inactive, chatID)
+	g.mu.Unlock()
+	if !was {
+		return
+	}
+	if err := g.store.SetChatActive(chatID, true, "", time.Now().Unix()); err != nil {
+		slog.Error("chats: reactivate failed", "chat_id", chatID, "err", err)
+	}
+	slog.Info("chats: chat reactivated", "chat_id", chatID)
+}
+
+func (g *chatGuard) deactivate(chatID int64, reason string) {
+	g.mu.Lock()
+	was := g.inactive[chatID]
+	g.inactive[chatID] = true
+	g.mu.Unlock()
+	if was {
+		return
+	}
+	if err := g.store.SetChatActive(chatID, false, reason, time.Now().Unix()); err != nil {
+		slog.Error("chats: deactivate failed", "chat_id", chatID, "err", err)
+	}
+	slog.Info("chats: chat marked inactive", "chat_id", chatID, "reason", reason)
+}
+
+func (g *chatGuard) Do(req *http.Request) (*http.Response, error) {
+	if req.Body == nil {
+		return g.next.Do(req)
+	}
+	body, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	req.ContentLength = int64(len(body))
+	chatID := requestChatID(req.Header.Get("Content-Type"), body)
+	if chatID == 0 {
+		return g.next.Do(req)
+	}
+	if !g.active(chatID) {
+		return &http.Response{
+			StatusCode: http.StatusForbidden,
+			Status:     "403 Forbidden",
+			Header:     http.Header{"Content-Type": {"application/json"}},
+			Body:       io.NopCloser(strings.NewReader(inactiveChatResponse)),
+			Request:    req,
+		}, nil
+	}
+	resp, err := g.next.Do(req)
+	if err != nil || resp.StatusCode != http.StatusForbidden {
+		return resp, err
+	}
+	raw, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(raw))
+	var apiResp tgbotapi.APIResponse
+	if json.Unmarshal(raw, &apiResp) == nil && chatGone(apiResp.Description) {
+		g.deactivate(chatID, apiResp.Description)
+	}
+	return resp, nil
+}
+
+// chatGone reports whether a 403 description means the bot can no longer
+// post in the chat at all, as opposed to lacking a permission.
+func chatGone(description string) bool {
+	d := strings.ToLower(description)
+	for _, s := range []string{"bot was blocked", "bot was kicked", "user is deactivated", "bot is not a member"} {
+		if strings.Contains(d, s) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,128 @@
+package telegram
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// telegramStub answers every request with a 403 description for the chats in
+// forbidden and ok otherwise, counting the requests that reached it.
+type telegramStub struct {
+	forbidden map[int64]string
+	calls     int
+}
+
+func (s *telegramStub) Do(req *http.Request) (*http.Response, error) {
+	s.calls++
+	body, _ := io.ReadAll(req.Body)
+	status, resp := http.StatusOK, `{"ok":true,"result":{}}`
+	if d, ok := s.forbidden[requestChatID(req.Header.Get("Content-Type"), body)]; ok {
+		status, resp = http.StatusForbidden, `{"ok":false,"error_code":403,"description":"`+d+`"}`
+	}
+	return &http.Response{StatusCode: status, Body: io.NopCloser(strings.NewReader(resp))}, nil
+}
+
+// sendTo posts a sendMessage form for chatID through g and returns the status.
+func sendTo(t *testing.T, g *chatGuard, chatID int64) int {
+	t.Helper()
+	form := url.Values{"chat_id": {strconv.FormatInt(chatID, 10)}, "text": {"hi"}}
+	req, err := http.NewRequest(http.MethodPost, "https://api.telegram.org/bot/sendMessage", strings.NewReader(form.Encode()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp, err := g.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	return resp.StatusCode
+}
+
+func TestChatGone(t *testing.T) {
+	tests := []struct {
+		description string
+		want        bool
+	}{
+		{"Forbidden: bot was blocked by the user", true},
+		{"Forbidden: bot was kicked from the supergroup chat", true},
+		{"Forbidden: user is deactivated", true},
+		{"Forbidden: bot is not a member of the channel chat", true},
+		{"Forbidden: not enough rights to send photos to the chat", false},
+		{"Bad Request: chat not found", false},
+	}
+	for _, tc := range tests {
+		if got := chatGone(tc.description); got != tc.want {
+			t.Errorf("chatGone(%q) = %v, want %v", tc.description, got, tc.want)
+		}
+	}
+}
+
+func TestChatGuardKickedAndReAdded(t *testing.T) {
+	const kicked, muted, other = int64(-100), int64(-200), int64(7)
+	store := newTestStore(t)
+	stub := &telegramStub{forbidden: map[int64]string{
+		kicked: "Forbidden: bot was kicked from the group chat",
+		muted:  "Forbidden: not enough rights to send text messages to the chat",
+	}}
+	g := newChatGuard(&tgbotapi.BotAPI{Client: stub}, store)
+
+	if got := sendTo(t, g, kicked); got != http.StatusForbidden {
+		t.Fatalf("send to the kicked chat = %d, want 403", got)
+	}
+	if got := sendTo(t, g, muted); got != http.StatusForbidden {
+		t.Fatalf("send to the muted chat = %d, want 403", got)
+	}
+	if g.active(kicked) || !g.active(muted) || !g.active(other) {
+		t.Fatalf("active = %v, %v, %v; want only the kicked chat inactive", g.active(kicked), g.active(muted), g.active(other))
+	}
+	if ids, err := store.FetchInactiveChats(); err != nil || !slices.Equal(ids, []int64{kicked}) {
+		t.Fatalf("FetchInactiveChats = %v, %v; want [%d]", ids, err, kicked)
+	}
+
+	// later sends are answered without calling Telegram
+	calls := stub.calls
+	if got := sendTo(t, g, kicked); got != http.StatusForbidden || stub.calls != calls {
+		t.Errorf("send to an inactive chat = %d with %d calls, want a local 403", got, stub.calls-calls)
+	}
+	// and a restart remembers the chat
+	if newChatGuard(&tgbotapi.BotAPI{Client: stub}, store).active(kicked) {
+		t.Error("the kicked chat is active after a restart")
+	}
+
+	// the bot is added back: the service message reactivates the chat
+	// before it is handled
+	delete(stub.forbidden, kicked)
+	handled := make(chan bool, 1)
+	b := &Bot{h: &Handlers{chats: g}, updates: newUpdateTracker(store)}
+	defer b.updates.close()
+	b.pool = newWorkerPool(1, 1, false, func(_ context.Context, m *tgbotapi.Message) {
+		handled <- g.active(m.Chat.ID)
+	}, nil)
+	readded := tgbotapi.Update{UpdateID: 1, Message: &tgbotapi.Message{
+		MessageID:      1,
+		Chat:           &tgbotapi.Chat{ID: kicked, Type: "group"},
+		NewChatMembers: []tgbotapi.User{{ID: 99, IsBot: true}},
+	}}
+	if err := b.dispatch(readded, 0); err != nil {
+		t.Fatal(err)
+	}
+	if !<-handled {
+		t.Error("the chat was still inactive when its update was handled")
+	}
+	if ids, err := store.FetchInactiveChats(); err != nil || len(ids) != 0 {
+		t.Errorf("FetchInactiveChats after re-adding = %v, %v; want none", ids, err)
+	}
+	calls = stub.calls
+	if got := sendTo(t, g, kicked); got != http.StatusOK || stub.calls != calls+1 {
+		t.Errorf("send after re-adding = %d with %d calls, want it sent", got, stub.calls-calls)
+	}
+}
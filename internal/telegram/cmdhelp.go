@@ -0,0 +1,262 @@
+package telegram
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"telegramBotTrade/internal/finance"
+	"telegramBotTrade/internal/storage"
+)
+
+// commandParam is one argument of a command card. values describes what it
+// accepts, built from the tables the command validates against so the card
+// can't drift from the code.
+type commandParam struct {
+	name     string
+	optional bool
+	values   func() string
+}
+
+// commandCard is the /help COMMAND card of a command.
+type commandCard struct {
+	summary  string
+	params   []commandParam
+	examples [2]string
+}
+
+// Parameters shared by several cards.
+var (
+	paramSymbol = commandParam{name: "SYMBOL", values: func() string {
+		return "a ticker as Yahoo Finance lists it, e.g. TSLA, BRK-B, ^GSPC, EURUSD=X, or a /futures name such as oil"
+	}}
+	paramSymbols = commandParam{name: "S1 S2 ...", values: func() string {
+		return fmt.Sprintf("%d to %d tickers", multiChartSymbols.min, multiChartSymbols.max)
+	}}
+	paramInterval = commandParam{name: "interval", optional: true, values: func() string {
+		parts := make([]string, len(finance.IntervalLimits))
+		for i, l := range finance.IntervalLimits {
+			parts[i] = l.Interval
+		}
+		return strings.Join(parts, ", ") + " (default 5m, or /set interval)"
+	}}
+	paramWindow = commandParam{name: "window", optional: true, values: func() string {
+		parts := make([]string, len(finance.IntervalLimits))
+		for i, l := range finance.IntervalLimits {
+			parts[i] = l.Interval + "→" + l.Max
+		}
+		return strings.Join(finance.ChartWindows(), ", ") + "; longest per interval: " + strings.Join(parts, ", ")
+	}}
+	paramMiniWindow = commandParam{name: "window", optional: true, values: func() string {
+		return strings.Join(finance.MiniChartWindows, ", ") + " (default 1d)"
+	}}
+	paramWeights = commandParam{name: "S1 W1 S2 W2 ...", values: func() string {
+		return fmt.Sprintf("up to %d tickers, each followed by its weight as 0.6 or 60%%; negative weights are shorts, what's left is cash", portfolioSymbols.max)
+	}}
+	paramPortWindow = commandParam{name: "window", optional: true, values: func() string {
+		return "Nd, Nw, Nm, Ny or ytd (default 1y)"
+	}}
+	paramAnchor = commandParam{name: "anchor", optional: true, values: func() string {
+		return "open (today's regular session from 09:30), pre (from 04:00, premarket included) or prevclose (today's session, change against yesterday's close drawn dashed); replaces the window"
+	}}
+	paramHist = commandParam{name: "hist[=W|fixed] [normal]", optional: true, values: func() string {
+		return fmt.Sprintf("also send a histogram of the daily returns in W%% bins (default: chosen from the returns; fixed is %g%%); normal overlays the normal curve", finance.HistogramFixedWidth)
+	}}
+	paramSVG = commandParam{name: "svg", optional: true, values: func() string {
+		return "send the chart as an SVG file"
+	}}
+)
+
+// commandCards holds a card for each command whose arguments need more than
+// the one line of /help.
+var commandCards = map[string]commandCard{
+	"/stock": {
+		summary:  "5-minute chart of one symbol.",
+		params:   []commandParam{paramSymbol, paramMiniWindow, paramAnchor, {name: "vwap", optional: true, values: func() string { return "add the session VWAP" }}, paramSVG},
+		examples: [2]string{"/stock TSLA 1w vwap", "/stock SPY prevclose"},
+	},
+	"/stocks": {
+		summary:  "5-minute chart of several symbols; more than two are shown as % change.",
+		params:   []commandParam{paramSymbols, paramMiniWindow, paramSVG},
+		examples: [2]string{"/stocks SPY QQQ", "/stocks AAPL MSFT NVDA 1w"},
+	},
+	"/stockx": {
+		summary:  "Chart of one symbol at any interval and window.",
+		params:   []commandParam{paramSymbol, paramInterval, paramWindow, paramAnchor, {name: "vwap", optional: true, values: func() string { return "add VWAP, intraday intervals only" }}, {name: "noevents", optional: true, values: func() string { return "leave out the ex-dividend (D) and split (S) marks of daily charts" }}, paramSVG},
+		examples: [2]string{"/stockx TSLA 1h 6m", "/stockx SPY 1d 5y"},
+	},
+	"/stocksx": {
+		summary:  "Chart of several symbols at any interval and window; more than two are shown as % change.",
+		params:   []commandParam{paramSymbols, paramInterval, paramWindow, paramSVG},
+		examples: [2]string{"/stocksx SPY TLT 1d 1y", "/stocksx AAPL MSFT GOOG 1h 3m"},
+	},
+	"/heat": {
+		summary: "Each symbol's change over the window as one strip of cells, deep red to deep green.",
+		params: []commandParam{{name: "S1 S2 ...", values: func() string {
+			return fmt.Sprintf("%d to %d tickers", heatSymbols.min, heatSymbols.max)
+		}}, {name: "window", optional: true, values: func() string {
+			return strings.Join(finance.ChartWindows(), ", ") + " (default 1d, the change from the previous close)"
+		}}, paramSVG},
+		examples: [2]string{"/heat SPY QQQ IWM TLT GLD BTC-USD", "/heat XLK XLF XLE XLV 3m"},
+	},
+	"/stocks-index": {
+		summary:  "Several symbols indexed to 100 at the start of the window.",
+		params:   []commandParam{paramSymbols, paramInterval, paramWindow, paramSVG},
+		examples: [2]string{"/stocks-index SPY QQQ IWM 1d 1y", "/stocks-index XLE XLK 1d 5y"},
+	},
+	"/ew-port": {
+		summary: "Backtest of an equal-weighted portfolio starting at $100.",
+		params: []commandParam{{name: "S1 S2 ...", values: func() string {
+			return fmt.Sprintf("%d to %d tickers", portfolioSymbols.min, portfolioSymbols.max)
+		}}, {name: "window", optional: true, values: func() string { return "Nd, Nw, Nm or Ny (default 1y)" }}, paramSVG},
+		examples: [2]string{"/ew-port SPY TLT GLD", "/ew-port AAPL MSFT NVDA 2y"},
+	},
+	"/port": {
+		summary:  "Backtest of a weighted portfolio; /portbuilder sets one up with buttons and /port watch alerts on its drawdowns.",
+		params:   []commandParam{paramWeights, paramPortWindow, {name: "detail", optional: true, values: func() string { return "also draw each asset" }}, paramHist, paramSVG},
+		examples: [2]string{"/port SPY 60% TLT 40% 5y", "/port QQQ 1 SPY -0.5 1y detail"},
+	},
+	"/portstats": {
+		summary:  "The /port backtest as statistics only: CAGR, Sharpe, Sortino, drawdown, best and worst day.",
+		params:   []commandParam{paramWeights, paramPortWindow},
+		examples: [2]string{"/portstats SPY 0.6 TLT 0.4", "/portstats QQQ 50% GLD 50% 10y"},
+	},
+	"/montecarlo": {
+		summary: "Fan chart of projected portfolio values from the returns of a backtest window.",
+		params: []commandParam{paramWeights, {name: "WINDOW", values: func() string { return "the backtest the returns are estimated from, e.g. 10y" }},
+			{name: "horizon=N", optional: true, values: func() string { return "how far to project, e.g. 20y (default 10y)" }},
+			{name: "sims=N", optional: true, values: func() string { return fmt.Sprintf("1 to %d simulations (default 2000)", finance.MaxMonteCarloSims) }},
+			{name: "seed=N", optional: true, values: func() string { return "repeats a run exactly" }}},
+		examples: [2]string{"/montecarlo SPY 0.7 TLT 0.3 10y", "/montecarlo QQQ 1 5y horizon=20y sims=5000"},
+	},
+	"/macd": {
+		summary:  "Price with MACD(12,26,9), signal and histogram below.",
+		params:   []commandParam{paramSymbol, paramInterval, paramWindow},
+		examples: [2]string{"/macd SPY", "/macd NVDA 1d 1y"},
+	},
+	"/atr": {
+		summary: "Daily closes with close ± 3×ATR stop lines.",
+		params: []commandParam{paramSymbol,
+			{name: "period", optional: true, values: func() string { return fmt.Sprintf("2 to %d days (default 14)", atrMaxPeriod) }},
+			{name: "window", optional: true, values: func() string { return "1m, 3m, 6m, 1y, 2y, 5y or 10y (default " + atrDefaultWindow + ")" }}},
+		examples: [2]string{"/atr TSLA", "/atr SPY 20 1y"},
+	},
+	"/yoy": {
+		summary: "This year's path against previous years, each indexed to 100 in January.",
+		params: []commandParam{paramSymbol,
+			{name: "years", optional: true, values: func() string {
+				return fmt.Sprintf("1 to %d previous years (default %d)", finance.MaxYoYYears, yoyDefaultYears)
+			}}},
+		examples: [2]string{"/yoy SPY", "/yoy GLD 3"},
+	},
+	"/ohlc": {
+		summary: "Table of the last daily bars: open, high, low, close and % change.",
+		params: []commandParam{paramSymbol,
+			{name: "n", optional: true, values: func() string { return fmt.Sprintf("1 to %d rows (default %d)", ohlcMaxRows, ohlcDefaultRows) }}},
+		examples: [2]string{"/ohlc AAPL", "/ohlc ^GSPC 20"},
+	},
+	"/export": {
+		summary:  "The series as a CSV file.",
+		params:   []commandParam{paramSymbol, paramInterval, paramWindow},
+		examples: [2]string{"/export SPY 1d 10y", "/export TSLA 5m 1m"},
+	},
+}
+
+// commandCardText renders the card of command, a key of commandCards. Only
+// its headings follow the chat's language; the cards themselves are English.
+func commandCardText(cs storage.ChatSettings, command string, c commandCard) string {
+	var b strings.Builder
+	b.WriteString(command)
+	for _, p := range c.params {
+		if p.optional {
+			b.WriteString(" [" + p.name + "]")
+		} else {
+			b.WriteString(" " + p.name)
+		}
+	}
+	b.WriteString("\n" + c.summary + "\n")
+	for _, p := range c.params {
+		fmt.Fprintf(&b, "\n• %s: %s", p.name, p.values())
+	}
+	b.WriteString("\n\n" + T(cs, "help.examples") + "\n" + c.examples[0] + "\n" + c.examples[1])
+	return b.String()
+}
+
+// handleHelpCommand replies with the card of name, with or without its
+// slash; a builtin alias shows its command's card. Commands without a card
+// get their /help lines, and unknown names the closest command.
+func (h *Handlers) handleHelpCommand(chatID int64, name string) {
+	cs := h.chartSettings(chatID)
+	command := "/" + strings.ToLower(strings.TrimPrefix(name, "/"))
+	if target, ok := builtinAliases[command]; ok {
+		command = target
+	}
+	if c, ok := commandCards[command]; ok {
+		h.reply(chatID, commandCardText(cs, command, c))
+		return
+	}
+	if botCommands[command] {
+		if lines := helpLines(T(cs, "help"), command); lines != "" {
+			h.reply(chatID, lines)
+			return
+		}
+		h.reply(chatID, T(cs, "help.no_extra", command))
+		return
+	}
+	if guess := closestCommand(command); guess != "" {
+		h.reply(chatID, T(cs, "help.did_you_mean", command, guess, strings.TrimPrefix(guess, "/")))
+		return
+	}
+	h.reply(chatID, T(cs, "help.unknown", command))
+}
+
+// helpLines returns the lines of the /help list text about command.
+func helpLines(text, command string) string {
+	var out []string
+	for _, line := range strings.Split(text, "\n") {
+		if strings.HasPrefix(line, "- "+command+" ") {
+			out = append(out, strings.TrimPrefix(line, "- "))
+		}
+	}
+	return strings.Join(out, "\n")
+}
+
+// closestCommand returns the command within two edits of command, or
+// within a third of its length for long names, or "" when none is that
+// close. Ties go to the alphabetically first.
+func closestCommand(command string) string {
+	limit := max(2, len(command)/3)
+	best, bestDist := "", limit+1
+	names := make([]string, 0, len(botCommands))
+	for name := range botCommands {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		if d := editDistance(command, name); d < bestDist {
+			best, bestDist = name, d
+		}
+	}
+	return best
+}
+
+// editDistance is the Levenshtein distance between a and b, in bytes.
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
@@ -0,0 +1,159 @@
+package telegram
+
+import (
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+
+	"telegramBotTrade/internal/storage"
+)
+
+const (
+	// recentUpdates is how many update_ids the tracker remembers per chat.
+	// Telegram retries an update within minutes, long before a chat sees this
+	// many newer ones.
+	recentUpdates = 256
+	// offsetFlushEvery is how often accepted update_ids are written to the
+	// update_offsets table, off the webhook path.
+	offsetFlushEvery = 2 * time.Second
+)
+
+// updateTracker remembers the update_ids recently accepted per chat so
+// Telegram retries (which reuse the update_id) are handled only once.
+// Updates that arrive out of order, as concurrent webhook deliveries can,
+// are still new and accepted. The highest id per chat is saved to the
+// update_offsets table in the background; after a restart everything up to
+// the saved id counts as seen, since the ids behind it are forgotten.
+type updateTracker struct {
+	store *storage.Store
+	mu    sync.Mutex
+	chats map[int64]*chatUpdates
+	dirty map[int64]int // highest id accepted per chat since the last flush
+
+	stop      chan struct{}
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// chatUpdates is one chat's recently accepted update_ids: seen for lookup
+// and ring in arrival order, so the oldest is forgotten first.
+type chatUpdates struct {
+	floor int // highest id saved before the last restart
+	seen  map[int]struct{}
+	ring  []int
+	next  int // slot of ring overwritten next once it is full
+}
+
+func newUpdateTracker(store *storage.Store) *updateTracker {
+	t := &updateTracker{
+		store: store,
+		chats: map[int64]*chatUpdates{},
+		dirty: map[int64]int{},
+		stop:  make(chan struct{}),
+		done:  make(chan struct{}),
+	}
+	offsets, err := store.FetchUpdateOffsets()
+	if err != nil {
+		slog.Warn("dedup: loading update offsets failed", "err", err)
+	}
+	for chatID, id := range offsets {
+		t.chats[chatID] = &chatUpdates{floor: id, seen: map[int]struct{}{}}
+	}
+	go t.run()
+	return t
+}
+
+// accept reports whether updateID is new for chatID and records it if so.
+func (t *updateTracker) accept(chatID int64, updateID int) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	c := t.chats[chatID]
+	if c == nil {
+		c = &chatUpdates{seen: map[int]struct{}{}}
+		t.chats[chatID] = c
+	}
+	if updateID <= c.floor {
+		return false
+	}
+	if _, ok := c.seen[updateID]; ok {
+		return false
+	}
+	if len(c.ring) < recentUpdates {
+		c.ring = append(c.ring, updateID)
+	} else {
+		delete(c.seen, c.ring[c.next])
+		c.ring[c.next] = updateID
+		c.next = (c.next + 1) % recentUpdates
+	}
+	c.seen[updateID] = struct{}{}
+	if updateID > t.dirty[chatID] {
+		t.dirty[chatID] = updateID
+	}
+	return true
+}
+
+// forget undoes accept for an update that was not handled after all, such as
+// one rejected by a full queue, so Telegram's redelivery of it is accepted.
+// Its ring slot stays taken until it comes round again.
+func (t *updateTracker) forget(chatID int64, updateID int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	c := t.chats[chatID]
+	if c == nil {
+		return
+	}
+	if _, ok := c.seen[updateID]; !ok {
+		return
+	}
+	delete(c.seen, updateID)
+	if i := slices.Index(c.ring, updateID); i >= 0 {
+		c.ring[i] = 0 // update_ids are positive
+	}
+	if t.dirty[chatID] != updateID {
+		return
+	}
+	// the next highest id still accepted; saving one already saved is a no-op
+	delete(t.dirty, chatID)
+	for _, id := range c.ring {
+		if id > t.dirty[chatID] {
+			t.dirty[chatID] = id
+		}
+	}
+}
+
+func (t *updateTracker) run() {
+	defer close(t.done)
+	tick := time.NewTicker(offsetFlushEvery)
+	defer tick.Stop()
+	for {
+		select {
+		case <-tick.C:
+			t.flush()
+		case <-t.stop:
+			return
+		}
+	}
+}
+
+// flush saves the highest update_id accepted per chat since the last flush.
+func (t *updateTracker) flush() {
+	t.mu.Lock()
+	dirty := t.dirty
+	t.dirty = map[int64]int{}
+	t.mu.Unlock()
+	for chatID, id := range dirty {
+		if err := t.store.SaveUpdateOffset(chatID, id); err != nil {
+			slog.Warn("dedup: saving update offset failed", "chat_id", chatID, "err", err)
+		}
+	}
+}
+
+// close stops the background writes and saves what is left.
+func (t *updateTracker) close() {
+	t.closeOnce.Do(func() {
+		close(t.stop)
+		<-t.done
+	})
+	t.flush()
+}
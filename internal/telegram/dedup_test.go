@@ -0,0 +1,176 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"telegramBotTrade/internal/storage"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// newTestStore opens a fresh database with the full schema.
+func newTestStore(t *testing.T) *storage.Store {
+	t.Helper()
+	db, err := storage.OpenSQLite("file:" + filepath.Join(t.TempDir(), "bot.db") + "?_fk=1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	if err := storage.InitSchema(db); err != nil {
+		t.Fatal(err)
+	}
+	return storage.NewStore(db)
+}
+
+func TestUpdateTrackerAccept(t *testing.T) {
+	tr := newUpdateTracker(newTestStore(t))
+	defer tr.close()
+	steps := []struct {
+		chatID   int64
+		updateID int
+		want     bool
+	}{
+		{1, 10, true},
+		{1, 10, false}, // retry
+		{1, 12, true},
+		{1, 11, true}, // out of order, still new
+		{1, 11, false},
+		{2, 10, true}, // ids are tracked per chat
+		{1, 9, true},
+		{1, 12, false},
+	}
+	for i, s := range steps {
+		if got := tr.accept(s.chatID, s.updateID); got != s.want {
+			t.Errorf("step %d: accept(%d, %d) = %v, want %v", i, s.chatID, s.updateID, got, s.want)
+		}
+	}
+}
+
+func TestUpdateTrackerForgetsOldest(t *testing.T) {
+	tr := newUpdateTracker(newTestStore(t))
+	defer tr.close()
+	for id := 1; id <= recentUpdates+1; id++ {
+		tr.accept(1, id)
+	}
+	if !tr.accept(1, 1) {
+		t.Error("id 1 should have been forgotten after recentUpdates newer ones")
+	}
+	if tr.accept(1, recentUpdates+1) {
+		t.Error("latest id accepted twice")
+	}
+}
+
+func TestUpdateTrackerRestart(t *testing.T) {
+	store := newTestStore(t)
+	tr := newUpdateTracker(store)
+	tr.accept(1, 5)
+	tr.accept(1, 7)
+	tr.accept(2, 3)
+	tr.close()
+
+	offsets, err := store.FetchUpdateOffsets()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if offsets[1] != 7 || offsets[2] != 3 {
+		t.Fatalf("offsets after close = %v, want 1:7 2:3", offsets)
+	}
+
+	tr = newUpdateTracker(store)
+	defer tr.close()
+	for _, id := range []int{5, 6, 7} {
+		if tr.accept(1, id) {
+			t.Errorf("update %d accepted again after restart", id)
+		}
+	}
+	if !tr.accept(1, 8) {
+		t.Error("new update rejected after restart")
+	}
+}
+
+func TestUpdateTrackerForget(t *testing.T) {
+	store := newTestStore(t)
+	tr := newUpdateTracker(store)
+	tr.accept(1, 5)
+	tr.accept(1, 7)
+	tr.forget(1, 7)
+	tr.forget(1, 8) // never accepted
+	tr.forget(2, 1) // unknown chat
+	if !tr.accept(1, 7) {
+		t.Fatal("forgotten update rejected")
+	}
+	if tr.accept(1, 5) {
+		t.Error("forget dropped another update")
+	}
+	tr.forget(1, 7)
+	tr.close()
+
+	offsets, err := store.FetchUpdateOffsets()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if offsets[1] != 5 {
+		t.Errorf("offset = %d, want 5 without the forgotten update", offsets[1])
+	}
+}
+
+func TestDispatchRetriesRejectedUpdate(t *testing.T) {
+	store := newTestStore(t)
+	release := make(chan struct{})
+	started := make(chan int, 10)
+	var mu sync.Mutex
+	var handled []int
+	b := &Bot{
+		h:       &Handlers{chats: &chatGuard{store: store, inactive: map[int64]bool{}}},
+		updates: newUpdateTracker(store),
+	}
+	defer b.updates.close()
+	b.pool = newWorkerPool(1, 1, false, func(_ context.Context, m *tgbotapi.Message) {
+		started <- m.MessageID
+		<-release
+		mu.Lock()
+		handled = append(handled, m.MessageID)
+		mu.Unlock()
+	}, nil)
+	update := func(id int) tgbotapi.Update {
+		return tgbotapi.Update{UpdateID: id, Message: &tgbotapi.Message{MessageID: id, Chat: &tgbotapi.Chat{ID: 1}}}
+	}
+
+	// one update in the worker, one waiting in the queue, the third rejected
+	if err := b.dispatch(update(1), 0); err != nil {
+		t.Fatal(err)
+	}
+	<-started
+	if err := b.dispatch(update(2), 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.dispatch(update(3), 0); !errors.Is(err, errQueueFull) {
+		t.Fatalf("dispatch on a full queue = %v, want errQueueFull", err)
+	}
+	close(release)
+	<-started
+
+	// Telegram's redelivery of the rejected update
+	for {
+		err := b.dispatch(update(3), 0)
+		if err == nil {
+			break
+		}
+		if !errors.Is(err, errQueueFull) {
+			t.Fatal(err)
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if !b.pool.stop(time.Second) {
+		t.Fatal("handlers did not drain")
+	}
+	if want := []int{1, 2, 3}; !slices.Equal(handled, want) {
+		t.Errorf("handled %v, want %v", handled, want)
+	}
+}
@@ -0,0 +1,83 @@
+package telegram
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"telegramBotTrade/internal/finance"
+	"telegramBotTrade/internal/logging"
+)
+
+// exportMaxRows rejects exports that would make an unwieldy CSV file.
+const exportMaxRows = 50000
+
+// handleExport sends sym's series for interval/window as a CSV document.
+func (h *Handlers) handleExport(ctx context.Context, chatID int64, sym, interval, window string) {
+	ctx, cancel := context.WithTimeout(ctx, 60*time.Second)
+	defer cancel()
+	cs := h.chartSettings(chatID)
+	s, err := finance.FetchBars(ctx, sym, interval, window)
+	if err != nil {
+		logging.FromContext(ctx).Error("export failed", "chat_id", chatID, "symbol", sym, "err", err)
+		h.replyFailure(chatID, T(cs, "fetch.failed", sym), err)
+		return
+	}
+	if len(s.Bars) > exportMaxRows {
+		h.reply(chatID, T(cs, "export.too_long",
+			strings.ToUpper(sym), s.Interval, s.Range, len(s.Bars), exportMaxRows, strings.ToUpper(sym), s.Range))
+		return
+	}
+	data, err := seriesCSV(s)
+	if err != nil {
+		h.reply(chatID, T(cs, "export.failed")+err.Error())
+		return
+	}
+	name := fmt.Sprintf("%s_%s_%s.csv", strings.ToUpper(sym), s.Interval, s.Range)
+	doc := tgbotapi.NewDocument(chatID, tgbotapi.FileBytes{Name: name, Bytes: data})
+	doc.Caption = clampCaption(T(cs, "export.caption", strings.ToUpper(sym), s.Interval, s.Range, len(s.Bars)), interval, window)
+	if !s.OHLC {
+		doc.Caption += "\n" + T(cs, "export.closes_only")
+	}
+	h.api.Send(doc)
+}
+
+// seriesCSV writes one row per bar with an ISO 8601 timestamp; open/high/low
+// and volume columns are included only when the source had them.
+func seriesCSV(s *finance.BarSeries) ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	header := []string{"timestamp"}
+	if s.OHLC {
+		header = append(header, "open", "high", "low")
+	}
+	header = append(header, "close")
+	if s.Volume {
+		header = append(header, "volume")
+	}
+	if err := w.Write(header); err != nil {
+		return nil, err
+	}
+	num := func(v float64) string { return strconv.FormatFloat(v, 'f', -1, 64) }
+	for _, b := range s.Bars {
+		row := []string{b.Time.Format(time.RFC3339)}
+		if s.OHLC {
+			row = append(row, num(b.Open), num(b.High), num(b.Low))
+		}
+		row = append(row, num(b.Close))
+		if s.Volume {
+			row = append(row, num(b.Volume))
+		}
+		if err := w.Write(row); err != nil {
+			return nil, err
+		}
+	}
+	w.Flush()
+	return buf.Bytes(), w.Error()
+}
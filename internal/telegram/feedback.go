@@ -0,0 +1,103 @@
+package telegram
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"telegramBotTrade/internal/logging"
+)
+
+// maxFeedbackLen caps /feedback so the forwarded copy fits one Telegram message.
+const maxFeedbackLen = 3000
+
+// handleFeedback stores user feedback and forwards it to the admin chat. In the
+// admin chat "list" and "done" manage the stored entries instead.
+func (h *Handlers) handleFeedback(ctx context.Context, m *tgbotapi.Message, text string) {
+	chatID := m.Chat.ID
+	if text == "" {
+		h.reply(chatID, h.t(chatID, "feedback.usage"))
+		return
+	}
+	if h.isAdmin(chatID) {
+		fields := strings.Fields(text)
+		switch fields[0] {
+		case "list":
+			n := 10
+			if len(fields) > 1 {
+				if v, err := strconv.Atoi(fields[1]); err == nil && v > 0 {
+					n = min(v, 50)
+				}
+			}
+			h.listFeedback(chatID, n)
+			return
+		case "done":
+			if len(fields) != 2 {
+				h.reply(chatID, h.t(chatID, "feedback.usage"))
+				return
+			}
+			id, err := strconv.ParseInt(strings.TrimPrefix(fields[1], "#"), 10, 64)
+			if err != nil {
+				h.reply(chatID, h.t(chatID, "feedback.usage"))
+				return
+			}
+			ok, err := h.store.ResolveFeedback(id)
+			switch {
+			case err != nil:
+				h.reply(chatID, h.t(chatID, "feedback.update_failed")+err.Error())
+			case !ok:
+				h.reply(chatID, h.t(chatID, "feedback.not_found", id))
+			default:
+				h.reply(chatID, h.t(chatID, "feedback.done", id))
+			}
+			return
+		}
+	}
+	if utf8.RuneCountInString(text) > maxFeedbackLen {
+		h.reply(chatID, h.t(chatID, "feedback.too_long", maxFeedbackLen))
+		return
+	}
+	userID := senderID(m)
+	id, err := h.store.SaveFeedback(chatID, userID, text, time.Now().Unix())
+	if err != nil {
+		logging.FromContext(ctx).Error("feedback save failed", "chat_id", chatID, "err", err)
+		h.reply(chatID, h.t(chatID, "feedback.failed"))
+		return
+	}
+	h.reply(chatID, h.t(chatID, "feedback.saved", id))
+
+	if h.adminChatID != 0 && chatID != h.adminChatID {
+		from := strconv.FormatInt(userID, 10)
+		if m.From != nil && m.From.UserName != "" {
+			from = "@" + m.From.UserName
+		}
+		h.reply(h.adminChatID, h.t(h.adminChatID, "feedback.forward", id, from, chatID, m.Chat.Title, text))
+	}
+}
+
+func (h *Handlers) listFeedback(chatID int64, n int) {
+	items, err := h.store.FetchFeedback(n, false)
+	if err != nil {
+		h.reply(chatID, h.t(chatID, "feedback.load_failed")+err.Error())
+		return
+	}
+	if len(items) == 0 {
+		h.reply(chatID, h.t(chatID, "feedback.none"))
+		return
+	}
+	var b strings.Builder
+	b.WriteString(h.t(chatID, "feedback.list_header"))
+	for _, f := range items {
+		text := f.Text
+		if utf8.RuneCountInString(text) > 200 {
+			text = string([]rune(text)[:200]) + "…"
+		}
+		fmt.Fprintf(&b, "\n#%d • %s • chat %d • user %d\n%s\n", f.ID, time.Unix(f.TS, 0).UTC().Format("2006-01-02 15:04"), f.ChatID, f.UserID, text)
+	}
+	h.reply(chatID, b.String())
+}
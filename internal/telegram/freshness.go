@@ -0,0 +1,48 @@
+package telegram
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"telegramBotTrade/internal/finance"
+	"telegramBotTrade/internal/storage"
+)
+
+// freshCaption appends when the chart's data is from and a warning when the
+// source left out many of a session's bars. When the last bar is stale during
+// market hours, it puts a warning in front of the caption.
+func freshCaption(cs storage.ChatSettings, caption string, f *finance.Freshness) string {
+	asOf := f.AsOf()
+	if asOf.IsZero() {
+		return caption
+	}
+	now := time.Now()
+	et := asOf.In(finance.DefaultLocation())
+	stamp := et.Format("15:04")
+	if y, m, d := now.In(finance.DefaultLocation()).Date(); et.Year() != y || et.Month() != m || et.Day() != d {
+		stamp = et.Format("Jan 2 15:04")
+	}
+	age := now.Sub(asOf)
+	caption += "\n" + T(cs, "caption.as_of", stamp, formatAge(age))
+	if sym, share := f.MissingBars(); share > 0 {
+		caption += "\n" + T(cs, "caption.missing_bars", int(share*100+0.5), strings.ToUpper(sym))
+	}
+	if f.Stale(now) {
+		caption = T(cs, "caption.stale", formatAge(age)) + "\n" + caption
+	}
+	return caption
+}
+
+// formatAge renders a duration as 7m, 3h or 2d.
+func formatAge(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return "<1m"
+	case d < 2*time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < 48*time.Hour:
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	}
+	return fmt.Sprintf("%dd", int(d.Hours()/24))
+}
@@ -0,0 +1,146 @@
+package telegram
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"sort"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"telegramBotTrade/internal/finance"
+	"telegramBotTrade/internal/logging"
+)
+
+// futuresShorthand names a Yahoo continuous futures contract.
+type futuresShorthand struct {
+	names  []string // lowercase; the first is the one /futures shows
+	symbol string
+	desc   string
+}
+
+// futuresShorthands are the futures the symbol commands take by name, in
+// /futures order. Root codes that are also common stock tickers (CL is
+// Colgate, NG NovaGold, HG Hamilton) are left out in favor of a word.
+var futuresShorthands = []futuresShorthand{
+	{names: []string{"es"}, symbol: "ES=F", desc: "S&P 500 E-mini"},
+	{names: []string{"nq"}, symbol: "NQ=F", desc: "Nasdaq 100 E-mini"},
+	{names: []string{"ym"}, symbol: "YM=F", desc: "Dow E-mini"},
+	{names: []string{"rty"}, symbol: "RTY=F", desc: "Russell 2000 E-mini"},
+	{names: []string{"zn"}, symbol: "ZN=F", desc: "10-year T-note"},
+	{names: []string{"zb"}, symbol: "ZB=F", desc: "30-year T-bond"},
+	{names: []string{"gold", "gc"}, symbol: "GC=F", desc: "Gold"},
+	{names: []string{"silver", "si"}, symbol: "SI=F", desc: "Silver"},
+	{names: []string{"copper"}, symbol: "HG=F", desc: "Copper"},
+	{names: []string{"oil", "wti"}, symbol: "CL=F", desc: "WTI crude oil"},
+	{names: []string{"brent"}, symbol: "BZ=F", desc: "Brent crude oil"},
+	{names: []string{"natgas"}, symbol: "NG=F", desc: "Natural gas"},
+	{names: []string{"corn"}, symbol: "ZC=F", desc: "Corn"},
+	{names: []string{"wheat"}, symbol: "ZW=F", desc: "Wheat"},
+	{names: []string{"soybeans", "soy"}, symbol: "ZS=F", desc: "Soybeans"},
+	{names: []string{"btcf"}, symbol: "BTC=F", desc: "CME Bitcoin"},
+	{names: []string{"ethf"}, symbol: "ETH=F", desc: "CME Ether"},
+}
+
+// futuresByName maps every shorthand to its contract.
+var futuresByName = func() map[string]string {
+	m := map[string]string{}
+	for _, f := range futuresShorthands {
+		for _, n := range f.names {
+			m[n] = f.symbol
+		}
+	}
+	return m
+}()
+
+// expandFutures rewrites futures shorthands among the symbols of a command to
+// their continuous contract, e.g. /stock oil to /stock CL=F, and upper-cases
+// futures typed as cl=f. Only the symbol arguments are touched, so a
+// /port watch name or an /ask question stays as written.
+func expandFutures(txt string) string {
+	name := strings.TrimPrefix(commandName(txt), "/")
+	if re := symbolPatterns[name]; re != nil {
+		loc := re.FindStringSubmatchIndex(txt)
+		if loc == nil || loc[2] < 0 {
+			return txt
+		}
+		return txt[:loc[2]] + futuresField(txt[loc[2]:loc[3]]) + txt[loc[3]:]
+	}
+	cmd, args, _ := strings.Cut(txt, " ")
+	switch name {
+	case "port", "portstats", "montecarlo":
+		if rePortWatch.MatchString(txt) {
+			return txt
+		}
+		return cmd + " " + futuresField(args)
+	case "watch":
+		if sub, syms, ok := strings.Cut(strings.TrimSpace(args), " "); ok && (sub == "add" || sub == "remove") {
+			return cmd + " " + sub + " " + futuresField(syms)
+		}
+	case "target":
+		if sym, rest, _ := strings.Cut(strings.TrimSpace(args), " "); sym != "" {
+			return strings.TrimSpace(cmd + " " + futuresField(sym) + " " + rest)
+		}
+	}
+	return txt
+}
+
+// futuresField rewrites the futures among the space-separated tokens of field.
+func futuresField(field string) string {
+	tokens := strings.Fields(field)
+	for i, tok := range tokens {
+		lower := strings.ToLower(tok)
+		if sym, ok := futuresByName[lower]; ok {
+			tokens[i] = sym
+		} else if strings.HasSuffix(lower, "=f") {
+			tokens[i] = strings.ToUpper(tok)
+		}
+	}
+	return strings.Join(tokens, " ")
+}
+
+// handleFutures lists the futures shorthands with their current quotes.
+func (h *Handlers) handleFutures(ctx context.Context, chatID int64) {
+	symbols := make([]string, len(futuresShorthands))
+	for i, f := range futuresShorthands {
+		symbols[i] = f.symbol
+	}
+	ctx, cancel := context.WithTimeout(ctx, 45*time.Second)
+	defer cancel()
+	cs := h.chartSettings(chatID)
+	quotes, errs := finance.FetchBatchQuotes(ctx, symbols)
+	if len(quotes) == 0 {
+		logging.FromContext(ctx).Error("futures failed", "chat_id", chatID, "failed", len(errs))
+		h.reply(chatID, T(cs, "futures.failed"))
+		return
+	}
+	var b strings.Builder
+	b.WriteString(T(cs, "futures.title") + "\n<pre>")
+	for _, f := range futuresShorthands {
+		fmt.Fprintf(&b, "%-8s %-6s", html.EscapeString(f.names[0]), f.symbol)
+		if q, ok := quotes[f.symbol]; ok {
+			fmt.Fprintf(&b, " %10.2f %s %+6.2f%%", q.Price, moveArrow(q.ChangePct), q.ChangePct)
+		} else {
+			fmt.Fprintf(&b, " %10s", "n/a")
+		}
+		fmt.Fprintf(&b, "  %s\n", html.EscapeString(f.desc))
+	}
+	b.WriteString("</pre>")
+	if len(errs) > 0 {
+		failed := make([]string, 0, len(errs))
+		for s := range errs {
+			failed = append(failed, s)
+		}
+		sort.Strings(failed)
+		b.WriteString("\n" + T(cs, "quote.missing") + strings.Join(failed, ", "))
+	}
+	b.WriteString("\n" + T(cs, "futures.hint"))
+	if finance.DemoMode() {
+		b.WriteString("\n" + demoNote)
+	}
+	msg := tgbotapi.NewMessage(chatID, b.String())
+	msg.ParseMode = "HTML"
+	h.api.Send(msg)
+}
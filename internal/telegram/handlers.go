@@ -2,8 +2,10 @@ package telegram
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -11,33 +13,118 @@ import (
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 
 	"telegramBotTrade/internal/finance"
+	"telegramBotTrade/internal/logging"
 	"telegramBotTrade/internal/openai"
 	"telegramBotTrade/internal/storage"
+	"telegramBotTrade/internal/version"
 )
 
 var (
-	reSummary = regexp.MustCompile(`^/summary(?:@[\w_]+)?(?:\s+|/)?(\d+)?$`)
-	// /stock SYMBOL [1d|1w|1m]
-	reStock = regexp.MustCompile(`^/stock(?:@[\w_]+)?\s+([A-Za-z0-9\.^_=+-]+)(?:\s+(1d|1w|1m))?$`)
-	// /stocks S1 S2 ... [1d|1w|1m]
-	reStocks = regexp.MustCompile(`^/stocks(?:@[\w_]+)?\s+([A-Za-z0-9\.^_=+\-\s]+?)(?:\s+(1d|1w|1m))?$`)
+	// /summary [channel] [hours]
+	reSummary = regexp.MustCompile(`^/summary(?:@[\w_]+)?(?:\s+(channel|all))?(?:\s+|/)?(\d+)?$`)
+	// /stock SYMBOL [1d|1w|1m] [open|pre|prevclose] [vwap] [svg]
+	reStock = regexp.MustCompile(`^/stock(?:@[\w_]+)?\s+([A-Za-z0-9\.^_=+-]+)(?:\s+(1d|1w|1m))?(?:\s+(open|pre|prevclose))?(?:\s+(vwap))?(?:\s+(svg))?$`)
+	// /stocks S1 S2 ... [1d|1w|1m] [svg]
+	reStocks = regexp.MustCompile(`^/stocks(?:@[\w_]+)?\s+([A-Za-z0-9\.^_=+\-\s]+?)(?:\s+(1d|1w|1m))?(?:\s+(svg))?$`)
 	// /help
-	reHelp = regexp.MustCompile(`^/(help|start)(?:@[\w_]+)?$`)
-	// /stocks-index S1 S2 ... [interval] [window]
+	reHelp = regexp.MustCompile(`^/(help|start)(?:@[\w_]+)?(?:\s+(\S+))?$`)
+	// /stocks-index S1 S2 ... [interval] [window] [svg]
 	// interval one of 1m|5m|15m|1h|1d, window e.g. 1d|5d|1m|3m|6m|1y|2y|5y|10y|30y
-	reStocksIndex = regexp.MustCompile(`^/stocks-index(?:@[\w_]+)?\s+([A-Za-z0-9\.^_=+\-\s]+?)(?:\s+(1m|5m|15m|1h|1d))?(?:\s+(1d|5d|1m|3m|6m|1y|2y|5y|10y|30y))?$`)
-	// /stockx SYMBOL [interval] [window]
-	reStockX = regexp.MustCompile(`^/stockx(?:@[\w_]+)?\s+([A-Za-z0-9\.^_=+-]+)(?:\s+(1m|5m|15m|1h|1d))?(?:\s+(1d|5d|1m|3m|6m|1y|2y|5y|10y|30y))?$`)
-	// /stocksx S1 S2 ... [interval] [window]
-	reStocksX = regexp.MustCompile(`^/stocksx(?:@[\w_]+)?\s+([A-Za-z0-9\.^_=+\-\s]+?)(?:\s+(1m|5m|15m|1h|1d))?(?:\s+(1d|5d|1m|3m|6m|1y|2y|5y|10y|30y))?$`)
-	// /ew-port S1 S2 ... [Xd|Xw|Xm|Xy] - Equal weighted portfolio backtest
-	reEWPort = regexp.MustCompile(`^/ew-port(?:@[\w_]+)?\s+([A-Za-z0-9\.^_=+\-\s]+?)(?:\s+(\d+[dwmy]))?$`)
-	// /port S1 X1 S2 X2 ... Y - Weighted portfolio backtest
-	rePort = regexp.MustCompile(`^/port(?:@[\w_]+)?\s+(.+)$`)
+	reStocksIndex = regexp.MustCompile(`^/stocks-index(?:@[\w_]+)?\s+([A-Za-z0-9\.^_=+\-\s]+?)(?:\s+(1m|5m|15m|1h|1d))?(?:\s+(1d|5d|1m|3m|6m|1y|2y|5y|10y|30y))?(?:\s+(svg))?$`)
+	// /stockx SYMBOL [interval] [window] [open|pre|prevclose] [vwap] [noevents] [svg]
+	reStockX = regexp.MustCompile(`^/stockx(?:@[\w_]+)?\s+([A-Za-z0-9\.^_=+-]+)(?:\s+(1m|5m|15m|1h|1d))?(?:\s+(1d|5d|1m|3m|6m|1y|2y|5y|10y|30y))?(?:\s+(open|pre|prevclose))?(?:\s+(vwap))?(?:\s+(noevents))?(?:\s+(svg))?$`)
+	// /stocksx S1 S2 ... [interval] [window] [svg]
+	reStocksX = regexp.MustCompile(`^/stocksx(?:@[\w_]+)?\s+([A-Za-z0-9\.^_=+\-\s]+?)(?:\s+(1m|5m|15m|1h|1d))?(?:\s+(1d|5d|1m|3m|6m|1y|2y|5y|10y|30y))?(?:\s+(svg))?$`)
+	// /ew-port S1 S2 ... [Xd|Xw|Xm|Xy] [svg] - Equal weighted portfolio backtest
+	reEWPort = regexp.MustCompile(`^/ew-port(?:@[\w_]+)?\s+([A-Za-z0-9\.^_=+\-\s]+?)(?:\s+(\d+[dwmy]))?(?:\s+(svg))?$`)
+	// /port S1 X1 S2 X2 ... [Y] [detail] [svg] - Weighted portfolio backtest
+	rePort = regexp.MustCompile(`^/port(?:@[\w_]+)?\s+(.+?)(?:\s+(detail))?(?:\s+(hist(?:=\S+)?))?(?:\s+(normal))?(?:\s+(svg))?$`)
+	// /portstats S1 X1 S2 X2 ... [Y] - /port's statistics as text, without the chart
+	rePortStats = regexp.MustCompile(`^/portstats(?:@[\w_]+)?\s+(.+)$`)
+	// /port watch NAME S1 W1 ... [dd=N] | watch list | unwatch NAME
+	rePortWatch = regexp.MustCompile(`^/port(?:@[\w_]+)?\s+(watch|unwatch)(?:\s+(.+))?$`)
+	// /portbuilder [S1 S2 ...] - build a /port backtest with inline buttons
+	rePortBuilder = regexp.MustCompile(`^/portbuilder(?:@[\w_]+)?(?:\s+(.+))?$`)
 	// /recommend TEXT - Trading recommendation based on user input
 	reRecommend = regexp.MustCompile(`^/recommend(?:@[\w_]+)?\s+(.+)$`)
+	// /recommend scoreboard [Nd] - how past recommendations performed
+	reRecommendScore = regexp.MustCompile(`^/recommend(?:@[\w_]+)?\s+scoreboard(?:\s+(\d+)d)?$`)
 	// /usage [Xd] - Usage analytics
 	reUsage = regexp.MustCompile(`^/usage(?:@[\w_]+)?(?:\s+(\d+)d)?$`)
+	// /activity [Nd] [users] - Messages over time, optionally per user
+	reActivity = regexp.MustCompile(`^/activity(?:@[\w_]+)?(?:\s+(\d+)d)?(?:\s+(users))?$`)
+	// /set KEY VALUE - Per-chat settings
+	reSet = regexp.MustCompile(`^/set(?:@[\w_]+)?(?:\s+(\S+))?(?:\s+(.+))?$`)
+	// /version - Build info (admin chat only)
+	reVersion = regexp.MustCompile(`^/version(?:@[\w_]+)?$`)
+	// /status - Live operational state (admin chat only)
+	reStatus = regexp.MustCompile(`^/status(?:@[\w_]+)?$`)
+	// /report (admin chat only)
+	reReport = regexp.MustCompile(`^/report(?:@[\w_]+)?$`)
+	// /broadcast TEXT (admin chat only)
+	reBroadcast = regexp.MustCompile(`(?s)^/broadcast(?:@[\w_]+)?(?:\s+(.*))?$`)
+	// /feedback TEXT - Message for the maintainer (multi-line allowed)
+	reFeedback = regexp.MustCompile(`(?s)^/feedback(?:@[\w_]+)?(?:\s+(.*))?$`)
+	// /schedule [list|delete N|HH:MM /command]
+	reSchedule = regexp.MustCompile(`^/schedule(?:@[\w_]+)?(?:\s+(.+))?$`)
+	// /watch [add|remove S1 S2 ...]
+	reWatch = regexp.MustCompile(`^/watch(?:@[\w_]+)?(?:\s+(.+))?$`)
+	// /brief on HH:MM|off|now
+	reBrief = regexp.MustCompile(`^/brief(?:@[\w_]+)?(?:\s+(.+))?$`)
+	// /movers [threshold%]
+	reMovers = regexp.MustCompile(`^/movers(?:@[\w_]+)?(?:\s+(\S+))?$`)
+	// /target SYMBOL PRICE [note:"..."] | list | delete N
+	reTarget = regexp.MustCompile(`^/target(?:@[\w_]+)?(?:\s+(.+))?$`)
+	// /paper buy|sell SYMBOL QTY | positions | pnl
+	rePaper = regexp.MustCompile(`^/paper(?:@[\w_]+)?(?:\s+(.+))?$`)
+	// /montecarlo S1 W1 S2 W2 ... WINDOW [horizon=20y] [sims=N] [seed=N]
+	reMonteCarlo = regexp.MustCompile(`^/montecarlo(?:@[\w_]+)?(?:\s+(.+))?$`)
+	// /vix [window]
+	reVIX = regexp.MustCompile(`^/vix(?:@[\w_]+)?(?:\s+(\w+))?$`)
+	// /yield [window] - 13W, 5Y, 10Y and 30Y Treasury yields
+	reYield = regexp.MustCompile(`^/yield(?:@[\w_]+)?(?:\s+(\w+))?$`)
+	// /curve - Treasury yield curve snapshot
+	reCurve = regexp.MustCompile(`^/curve(?:@[\w_]+)?$`)
+	// /heat S1 S2 ... [window] [svg] - one strip of cells colored by change
+	reHeat = regexp.MustCompile(`^/heat(?:@[\w_]+)?\s+([A-Za-z0-9\.^_=+\-\s]+?)(?:\s+(1d|5d|1m|3m|6m|1y|2y|5y|10y|30y))?(?:\s+(svg))?$`)
+	// /futures - futures shorthands with quotes
+	reFutures = regexp.MustCompile(`^/futures(?:@[\w_]+)?$`)
+	// /macd SYMBOL [interval] [window]
+	reMACD = regexp.MustCompile(`^/macd(?:@[\w_]+)?\s+([A-Za-z0-9\.^_=+-]+)(?:\s+(1m|5m|15m|1h|1d))?(?:\s+(1d|5d|1m|3m|6m|1y|2y|5y|10y|30y))?$`)
+	// /atr SYMBOL [period] [window]
+	reATR = regexp.MustCompile(`^/atr(?:@[\w_]+)?\s+([A-Za-z0-9\.^_=+-]+)(?:\s+(\d+))?(?:\s+(1m|3m|6m|1y|2y|5y|10y))?$`)
+	// /ohlc SYMBOL [n]
+	reOHLC = regexp.MustCompile(`^/ohlc(?:@[\w_]+)?\s+([A-Za-z0-9\.^_=+-]+)(?:\s+(\d+))?$`)
+	// /export SYMBOL [interval] [window]
+	reExport = regexp.MustCompile(`^/export(?:@[\w_]+)?\s+([A-Za-z0-9\.^_=+-]+)(?:\s+(1m|5m|15m|1h|1d))?(?:\s+(1d|5d|1m|3m|6m|1y|2y|5y|10y|30y))?$`)
+	// /info SYMBOL
+	reInfo = regexp.MustCompile(`^/info(?:@[\w_]+)?\s+([A-Za-z0-9\.^_=+-]+)$`)
+	// /optmove SYMBOL
+	reOptMove = regexp.MustCompile(`^/optmove(?:@[\w_]+)?\s+([A-Za-z0-9\.^_=+-]+)$`)
+	// /yoy SYMBOL [years]
+	reYoY = regexp.MustCompile(`^/yoy(?:@[\w_]+)?\s+([A-Za-z0-9\.^_=+-]+)(?:\s+(\d+))?$`)
+	// /history [n]
+	reHistory = regexp.MustCompile(`^/history(?:@[\w_]+)?(?:\s+(\d+))?$`)
+	// /calendar [week]
+	reCalendar = regexp.MustCompile(`^/calendar(?:@[\w_]+)?(?:\s+(\w+))?$`)
+	// /chart FREE TEXT - chart request in plain language, read by the LLM
+	reChart = regexp.MustCompile(`(?s)^/chart(?:@[\w_]+)?(?:\s+(.*))?$`)
+	// /summaries [n] | show K
+	reSummaries = regexp.MustCompile(`^/summaries(?:@[\w_]+)?(?:\s+(.+))?$`)
+	// /ask [Nh] QUESTION - answer from the stored chat history
+	reAsk = regexp.MustCompile(`(?s)^/ask(?:@[\w_]+)?(?:\s+(\d+)h)?(?:\s+(.*))?$`)
+	// /actions [hours] | open | done N - action items checklist
+	reActions = regexp.MustCompile(`^/actions(?:@[\w_]+)?(?:\s+(.+))?$`)
+	// /translate [language] - as a reply to the message to translate
+	reTranslate = regexp.MustCompile(`^/translate(?:@[\w_]+)?(?:\s+(.+))?$`)
+	// /recap [Nd] [chart] - most discussed tickers with their returns
+	reRecap = regexp.MustCompile(`^/recap(?:@[\w_]+)?(?:\s+(\d+)d)?(?:\s+(chart))?$`)
+	// /again [overrides] - last chart or portfolio command again
+	reAgain = regexp.MustCompile(`^/again(?:@[\w_]+)?(?:\s+(.+))?$`)
+	// /alias add NAME "/command args" | remove NAME | list
+	reAlias = regexp.MustCompile(`^/alias(?:@[\w_]+)?(?:\s+(.+))?$`)
+	// reSymbol matches a single ticker as accepted by the chart commands
+	reSymbol = regexp.MustCompile(`^[A-Za-z0-9\.^_=+-]+$`)
 )
 
 type Handlers struct {
@@ -46,8 +133,33 @@ type Handlers struct {
 	summarize *openai.Summarizer
 	recommend *openai.Recommender
 	analytics *finance.UsageAnalytics
+	calendar  finance.CalendarProvider
+	settings  *settingsService
+	topics    *topicRouter // routes replies back to the forum topic of the command
+	chats     *chatGuard   // stops sends to chats that blocked or removed the bot
+
+	adminChatID      int64
+	about            func() version.Info
+	targetExpiryDays int
+	weeklyReport     bool
+	aiDailyLimit     int
+
+	cashtags  *cashtagCooldown // per chat and symbol cooldown of /set cashtags replies
+	builds    *portBuilder     // /portbuilder flows in progress
+	again     *lastCommands    // each chat's last chart command, for /again
+	summaries *summaryCache    // recent /summary results by their input
+	messages  *storage.MessageWriter
+
+	queueDepth func() int // updates waiting for a handler worker, for /status
 }
 
+// Stored chat messages are written in batches of up to messageBatchSize, at
+// least every messageFlushInterval.
+const (
+	messageFlushInterval = 500 * time.Millisecond
+	messageBatchSize     = 100
+)
+
 func NewHandlers(api *tgbotapi.BotAPI, store *storage.Store, openAIKey string) *Handlers {
 	return &Handlers{
 		api:       api,
@@ -55,22 +167,126 @@ func NewHandlers(api *tgbotapi.BotAPI, store *storage.Store, openAIKey string) *
 		summarize: openai.NewSummarizer(openAIKey),
 		recommend: openai.NewRecommender(openAIKey),
 		analytics: finance.NewUsageAnalytics(),
+		calendar:  finance.CachedCalendar(finance.FaireconomyCalendar{}, store),
+		settings:  newSettingsService(store, settingsCacheTTL),
+		topics:    newTopicRouter(api),
+		chats:     newChatGuard(api, store),
+		cashtags:  newCashtagCooldown(cashtagCooldownTTL),
+		builds:    newPortBuilder(portBuilderTTL),
+		again:     newLastCommands(store),
+		summaries: newSummaryCache(summaryCacheTTL),
+		messages:  storage.NewMessageWriter(store, messageFlushInterval, messageBatchSize),
 	}
 }
 
-func (h *Handlers) HandleMessage(m *tgbotapi.Message) {
-	// Save any text for later summaries
-	if txt := strings.TrimSpace(m.Text); txt != "" {
-		_ = h.store.SaveMessage(m.Chat.ID, m.From.ID, txt, int64(m.Date))
+// senderID returns the sending user's ID. Channel posts and anonymous admins
+// have no From, so the sending chat's ID is used instead (0 if neither is set).
+func senderID(m *tgbotapi.Message) int64 {
+	if m == nil {
+		return 0
+	}
+	if m.From != nil {
+		return m.From.ID
 	}
+	if m.SenderChat != nil {
+		return m.SenderChat.ID
+	}
+	return 0
+}
 
-	txt := strings.TrimSpace(m.Text)
+// senderName returns a display name for the message's sender: the user's name,
+// else their @username, else the sending chat's title.
+func senderName(m *tgbotapi.Message) string {
+	if name := userName(m.From); name != "" {
+		return name
+	}
+	if m.SenderChat != nil {
+		return m.SenderChat.Title
+	}
+	return ""
+}
+
+// userName returns the user's full name, else their @username ("" for nil).
+func userName(u *tgbotapi.User) string {
+	if u == nil {
+		return ""
+	}
+	if name := strings.TrimSpace(u.FirstName + " " + u.LastName); name != "" {
+		return name
+	}
+	if u.UserName != "" {
+		return "@" + u.UserName
+	}
+	return ""
+}
+
+func (h *Handlers) HandleMessage(ctx context.Context, m *tgbotapi.Message) {
+	// A group upgraded to a supergroup gets a new chat ID; both the old group
+	// (migrate_to) and the new supergroup (migrate_from) receive a service message.
+	if m.MigrateToChatID != 0 {
+		h.migrateChat(ctx, m.Chat.ID, m.MigrateToChatID)
+		return
+	}
+	if m.MigrateFromChatID != 0 {
+		h.migrateChat(ctx, m.MigrateFromChatID, m.Chat.ID)
+		return
+	}
+
+	// replies go back to the forum topic the message came from
+	threadID := threadFrom(ctx)
+	defer h.topics.enter(m.Chat.ID, threadID)()
+
+	userID := senderID(m)
+	// Save any text for later summaries; scheduled runs and /history re-runs are
+	// not chat history and chats with /set store_messages off are never stored
+	if txt := strings.TrimSpace(m.Text); txt != "" && !isScheduled(ctx) && !isRerun(ctx) && h.chartSettings(m.Chat.ID).StoreMessages {
+		sm := storage.Message{
+			ChatID:    m.Chat.ID,
+			ThreadID:  threadID,
+			MessageID: m.MessageID,
+			UserID:    userID,
+			UserName:  senderName(m),
+			Text:      txt,
+			Ts:        int64(m.Date),
+		}
+		// messages in a forum topic implicitly reply to the topic's first message
+		if r := m.ReplyToMessage; r != nil && r.MessageID != threadID {
+			sm.ReplyTo = r.MessageID
+		}
+		if err := h.messages.Save(sm); err != nil {
+			logging.FromContext(ctx).Warn("message save failed", "chat_id", m.Chat.ID, "err", err)
+		}
+	}
+	if h.historyRerun(ctx, m) {
+		return
+	}
+
+	// aliases such as /s SPY are rewritten to the command they stand for
+	txt := h.expandAlias(m.Chat.ID, strings.TrimSpace(m.Text))
+	if strings.HasPrefix(txt, "/") {
+		logging.FromContext(ctx).Info("command received", "chat_id", m.Chat.ID, "user_id", userID, "command", strings.Fields(txt)[0])
+	}
+	// a chart or portfolio command that delivers becomes the chat's /again
+	// command; scheduled runs don't
+	if name := againCommand(txt); name != "" && !isScheduled(ctx) {
+		var run *againRun
+		ctx, run = withAgainRun(ctx)
+		defer func() {
+			if run.delivered {
+				h.rememberLast(ctx, m.Chat.ID, name, txt)
+			}
+		}()
+	}
+	// the tracked command's duration is recorded when its handler returns
+	ctx, timing := withCommandTiming(ctx)
+	defer h.finishCommand(ctx, timing)
 	switch {
 	case reSummary.MatchString(txt):
-		h.trackCommand(m.Chat.ID, m.From.ID, "summary", "summarizer")
+		h.trackCommand(ctx, m.Chat.ID, userID, "summary", "summarizer", txt)
 		hours := 1
-		if g := reSummary.FindStringSubmatch(txt); len(g) == 2 && g[1] != "" {
-			fmt.Sscanf(g[1], "%d", &hours)
+		g := reSummary.FindStringSubmatch(txt)
+		if len(g) == 3 && g[2] != "" {
+			fmt.Sscanf(g[2], "%d", &hours)
 			if hours < 1 {
 				hours = 1
 			}
@@ -78,211 +294,306 @@ func (h *Handlers) HandleMessage(m *tgbotapi.Message) {
 				hours = 48
 			}
 		}
-		h.reply(m.Chat.ID, fmt.Sprintf("Summarizing last %dh…", hours))
-		h.handleSummary(m.Chat.ID, hours)
+		sourceChatID := m.Chat.ID
+		// in forum supergroups /summary covers the topic it was sent from
+		scope := threadID
+		if g[1] == "all" {
+			scope = storage.AllThreads
+		}
+		if len(g) == 3 && g[1] == "channel" {
+			settings, err := h.settings.Get(m.Chat.ID)
+			if err != nil || settings.SourceChannel == 0 {
+				h.reply(m.Chat.ID, h.t(m.Chat.ID, "summary.no_channel"))
+				return
+			}
+			sourceChatID = settings.SourceChannel
+			scope = storage.AllThreads
+		}
+		if !h.chartSettings(sourceChatID).StoreMessages {
+			h.reply(m.Chat.ID, h.t(m.Chat.ID, "summary.storage_off"))
+			return
+		}
+		switch {
+		case sourceChatID != m.Chat.ID:
+			h.reply(m.Chat.ID, h.t(m.Chat.ID, "summary.channel_progress", hours))
+		case scope > 0:
+			h.reply(m.Chat.ID, h.t(m.Chat.ID, "summary.topic_progress", hours, hours))
+		default:
+			h.reply(m.Chat.ID, h.t(m.Chat.ID, "summary.progress", hours))
+		}
+		h.handleSummary(ctx, m.Chat.ID, sourceChatID, scope, hours)
 
 	case reStock.MatchString(txt):
-		h.trackCommand(m.Chat.ID, m.From.ID, "stock", "charts")
+		h.trackCommand(ctx, m.Chat.ID, userID, "stock", "charts", txt)
 		g := reStock.FindStringSubmatch(txt)
 		sym := g[1]
 		window := ""
 		if len(g) >= 3 {
 			window = g[2]
 		}
-		h.handleStock(m.Chat.ID, sym, window)
+		cs := h.chartSettings(m.Chat.ID)
+		if window != "" && g[3] != "" {
+			h.reply(m.Chat.ID, T(cs, "chart.anchor_window", "/stock "+sym+" "+g[3]))
+			return
+		}
+		if window == "" {
+			window = miniWindow(cs)
+		}
+		opts := renderOptions(ctx, cs)
+		opts.Anchor = g[3]
+		opts.VWAP = g[4] != ""
+		opts.Format = g[5]
+		h.handleStock(ctx, m.Chat.ID, sym, window, opts)
 
 	case reHelp.MatchString(txt):
-		h.trackCommand(m.Chat.ID, m.From.ID, "help", "other")
+		h.trackCommand(ctx, m.Chat.ID, userID, "help", "other", txt)
 		// Show commands help
-		h.handleHelp(m.Chat.ID)
+		g := reHelp.FindStringSubmatch(txt)
+		switch arg := g[2]; {
+		case strings.EqualFold(arg, "limits"):
+			h.handleHelpLimits(m.Chat.ID)
+		case arg != "" && g[1] == "help":
+			h.handleHelpCommand(m.Chat.ID, arg)
+		default:
+			h.reply(m.Chat.ID, h.t(m.Chat.ID, "help"))
+		}
 
 	case reStocks.MatchString(txt):
-		h.trackCommand(m.Chat.ID, m.From.ID, "stocks", "charts")
+		h.trackCommand(ctx, m.Chat.ID, userID, "stocks", "charts", txt)
 		g := reStocks.FindStringSubmatch(txt)
 		symsField := strings.TrimSpace(g[1])
 		window := ""
 		if len(g) >= 3 {
 			window = g[2]
 		}
-		// Split on whitespace, normalize and dedupe
-		raw := strings.Fields(symsField)
-		seen := map[string]struct{}{}
-		syms := make([]string, 0, len(raw))
-		for _, s := range raw {
-			su := strings.ToUpper(strings.TrimSpace(s))
-			if su == "" {
-				continue
-			}
-			if _, ok := seen[su]; ok {
-				continue
-			}
-			seen[su] = struct{}{}
-			syms = append(syms, su)
-		}
-		if len(syms) < 2 {
-			h.reply(m.Chat.ID, "Please provide at least two symbols, e.g. /stocks SPY AAPL 1w")
+		cs := h.chartSettings(m.Chat.ID)
+		syms, err := parseSymbolList(cs, symsField, multiChartSymbols, "/stocks SPY AAPL 1w")
+		if err != nil {
+			h.reply(m.Chat.ID, err.Error())
 			return
 		}
-		h.handleMultiStock(m.Chat.ID, syms, window)
+		if window == "" {
+			window = miniWindow(cs)
+		}
+		opts := renderOptions(ctx, cs)
+		opts.Format = g[3]
+		h.handleMultiStock(ctx, m.Chat.ID, syms, window, opts)
 
 	case reStocksIndex.MatchString(txt):
-		h.trackCommand(m.Chat.ID, m.From.ID, "stocks-index", "charts")
+		h.trackCommand(ctx, m.Chat.ID, userID, "stocks-index", "charts", txt)
 		g := reStocksIndex.FindStringSubmatch(txt)
 		symsField := strings.TrimSpace(g[1])
-		interval := "5m"
+		cs := h.chartSettings(m.Chat.ID)
+		interval := defaultInterval(cs)
 		if len(g) >= 3 && g[2] != "" {
 			interval = g[2]
 		}
-		window := ""
-		if len(g) >= 4 {
+		window := customWindow(cs)
+		if len(g) >= 4 && g[3] != "" {
 			window = g[3]
 		}
-		raw := strings.Fields(symsField)
-		seen := map[string]struct{}{}
-		syms := make([]string, 0, len(raw))
-		for _, s := range raw {
-			su := strings.ToUpper(strings.TrimSpace(s))
-			if su == "" {
-				continue
-			}
-			if _, ok := seen[su]; ok {
-				continue
-			}
-			seen[su] = struct{}{}
-			syms = append(syms, su)
-		}
-		if len(syms) < 2 {
-			h.reply(m.Chat.ID, "Please provide at least two symbols, e.g. /stocks-index SPY AAPL 1h 1y")
+		syms, err := parseSymbolList(cs, symsField, multiChartSymbols, "/stocks-index SPY AAPL 1h 1y")
+		if err != nil {
+			h.reply(m.Chat.ID, err.Error())
 			return
 		}
-		img, err := finance.MakeIndexedChart(syms, interval, window, true)
+		opts := renderOptions(ctx, cs)
+		opts.Format = g[4]
+		ctx, fresh := finance.WithFreshness(ctx)
+		res, err := finance.MakeIndexedChart(ctx, syms, interval, window, true, opts)
 		if err != nil {
-			h.reply(m.Chat.ID, "Indexed plot failed: "+err.Error())
+			logging.FromContext(ctx).Error("stocks-index failed", "chat_id", m.Chat.ID, "symbols", syms, "err", err)
+			h.replyFailure(m.Chat.ID, T(cs, "indexed.failed"), err)
 			return
 		}
-		name := strings.Join(syms, "_")
-		photo := tgbotapi.NewPhoto(m.Chat.ID, tgbotapi.FileBytes{Name: name + "_indexed.png", Bytes: img})
-		photo.Caption = "Indexed: " + strings.Join(syms, ", ") + " • " + strings.ToUpper(interval) + " • " + strings.ToUpper(window)
-		h.api.Send(photo)
+		skipped := res.Meta.Skipped
+		logSkipped(ctx, skipped)
+		caption := T(cs, "indexed.caption") + strings.Join(chartedSymbols(syms, skipped), ", ") + " • " + shownInterval(res.Meta, interval) + " • " + strings.ToUpper(window) +
+			changesLine(res.Meta) + skippedNote(cs, skipped)
+		caption = clampCaption(caption, interval, window)
+		h.sendChart(m.Chat.ID, strings.Join(syms, "_")+"_indexed", freshCaption(cs, caption, fresh), res.Image, opts)
+		markDelivered(ctx)
 
 	case reStockX.MatchString(txt):
-		h.trackCommand(m.Chat.ID, m.From.ID, "stockx", "charts")
+		h.trackCommand(ctx, m.Chat.ID, userID, "stockx", "charts", txt)
 		g := reStockX.FindStringSubmatch(txt)
 		sym := g[1]
-		interval := "5m"
+		cs := h.chartSettings(m.Chat.ID)
+		interval := defaultInterval(cs)
 		if len(g) >= 3 && g[2] != "" {
 			interval = g[2]
 		}
-		window := ""
-		if len(g) >= 4 {
+		window := customWindow(cs)
+		if len(g) >= 4 && g[3] != "" {
 			window = g[3]
 		}
-		img, err := finance.MakeChart(sym, interval, window)
+		opts := renderOptions(ctx, cs)
+		opts.Anchor = g[4]
+		opts.VWAP = g[5] != ""
+		opts.NoEvents = g[6] != ""
+		opts.Format = g[7]
+		span, file := strings.ToUpper(window), sym+"_"+interval+"_"+window
+		if opts.Anchor != "" {
+			switch {
+			case g[3] != "":
+				h.reply(m.Chat.ID, T(cs, "chart.anchor_window", "/stockx "+sym+" 5m "+opts.Anchor))
+				return
+			case g[2] == "1d":
+				h.reply(m.Chat.ID, T(cs, "chart.anchor_interval", "/stockx "+sym+" 5m "+opts.Anchor))
+				return
+			case interval == "1d":
+				interval = "5m"
+			}
+			span, file = finance.AnchorLabel(opts.Anchor), sym+"_"+interval+"_"+opts.Anchor
+		}
+		ctx, fresh := finance.WithFreshness(ctx)
+		res, err := finance.MakeChart(ctx, sym, interval, window, opts)
 		if err != nil {
-			h.reply(m.Chat.ID, "Chart failed: "+err.Error())
+			logging.FromContext(ctx).Error("stockx failed", "chat_id", m.Chat.ID, "symbol", sym, "err", err)
+			h.replyFailure(m.Chat.ID, T(cs, "chart.failed"), err)
 			return
 		}
-		photo := tgbotapi.NewPhoto(m.Chat.ID, tgbotapi.FileBytes{Name: sym + "_" + interval + "_" + window + ".png", Bytes: img})
-		photo.Caption = strings.ToUpper(sym) + " • " + strings.ToUpper(interval) + " • " + strings.ToUpper(window)
-		h.api.Send(photo)
+		caption := strings.ToUpper(sym) + " • " + shownInterval(res.Meta, interval) + " • " + span + priceLine(cs, res.Meta)
+		if res.Meta.Note != "" {
+			caption += "\n" + res.Meta.Note
+		}
+		if opts.Anchor == "" {
+			caption = clampCaption(caption, interval, window)
+		}
+		h.sendChart(m.Chat.ID, file, freshCaption(cs, caption, fresh), res.Image, opts)
+		markDelivered(ctx)
 
 	case reStocksX.MatchString(txt):
-		h.trackCommand(m.Chat.ID, m.From.ID, "stocksx", "charts")
+		h.trackCommand(ctx, m.Chat.ID, userID, "stocksx", "charts", txt)
 		g := reStocksX.FindStringSubmatch(txt)
 		symsField := strings.TrimSpace(g[1])
-		interval := "5m"
+		cs := h.chartSettings(m.Chat.ID)
+		interval := defaultInterval(cs)
 		if len(g) >= 3 && g[2] != "" {
 			interval = g[2]
 		}
-		window := ""
-		if len(g) >= 4 {
+		window := customWindow(cs)
+		if len(g) >= 4 && g[3] != "" {
 			window = g[3]
 		}
-		raw := strings.Fields(symsField)
-		seen := map[string]struct{}{}
-		syms := make([]string, 0, len(raw))
-		for _, s := range raw {
-			su := strings.ToUpper(strings.TrimSpace(s))
-			if su == "" {
-				continue
-			}
-			if _, ok := seen[su]; ok {
-				continue
-			}
-			seen[su] = struct{}{}
-			syms = append(syms, su)
-		}
-		if len(syms) < 2 {
-			h.reply(m.Chat.ID, "Please provide at least two symbols, e.g. /stocksx SPY AAPL 1h 1y")
+		syms, err := parseSymbolList(cs, symsField, multiChartSymbols, "/stocksx SPY AAPL 1h 1y")
+		if err != nil {
+			h.reply(m.Chat.ID, err.Error())
 			return
 		}
-		img, err := finance.MakeMultiChart(syms, interval, window)
+		opts := renderOptions(ctx, cs)
+		opts.Format = g[4]
+		ctx, fresh := finance.WithFreshness(ctx)
+		res, err := finance.MakeMultiChart(ctx, syms, interval, window, opts)
 		if err != nil {
-			h.reply(m.Chat.ID, "Multi chart failed: "+err.Error())
+			logging.FromContext(ctx).Error("stocksx failed", "chat_id", m.Chat.ID, "symbols", syms, "err", err)
+			h.replyFailure(m.Chat.ID, T(cs, "multi.failed"), err)
 			return
 		}
-		name := strings.Join(syms, "_")
-		photo := tgbotapi.NewPhoto(m.Chat.ID, tgbotapi.FileBytes{Name: name + "_" + interval + "_" + window + ".png", Bytes: img})
-		photo.Caption = "Multi: " + strings.Join(syms, ", ") + " • " + strings.ToUpper(interval) + " • " + strings.ToUpper(window)
-		h.api.Send(photo)
+		skipped := res.Meta.Skipped
+		logSkipped(ctx, skipped)
+		caption := T(cs, "multi.caption") + strings.Join(chartedSymbols(syms, skipped), ", ") + " • " + shownInterval(res.Meta, interval) + " • " + strings.ToUpper(window) +
+			changesLine(res.Meta) + skippedNote(cs, skipped)
+		caption = clampCaption(caption, interval, window)
+		h.sendChart(m.Chat.ID, strings.Join(syms, "_")+"_"+interval+"_"+window, freshCaption(cs, caption, fresh), res.Image, opts)
+		markDelivered(ctx)
 
 	case reEWPort.MatchString(txt):
-		h.trackCommand(m.Chat.ID, m.From.ID, "ew-port", "portfolio")
+		h.trackCommand(ctx, m.Chat.ID, userID, "ew-port", "portfolio", txt)
 		g := reEWPort.FindStringSubmatch(txt)
 		symsField := strings.TrimSpace(g[1])
 		window := "1y" // Default to 1 year
 		if len(g) >= 3 && g[2] != "" {
 			window = g[2]
 		}
-		raw := strings.Fields(symsField)
-		seen := map[string]struct{}{}
-		syms := make([]string, 0, len(raw))
-		for _, s := range raw {
-			su := strings.ToUpper(strings.TrimSpace(s))
-			if su == "" {
-				continue
-			}
-			if _, ok := seen[su]; ok {
-				continue
-			}
-			seen[su] = struct{}{}
-			syms = append(syms, su)
-		}
-		if len(syms) < 2 {
-			h.reply(m.Chat.ID, "Please provide at least two symbols, e.g. /ew-port SPY AAPL QQQ 2y")
+		cs := h.chartSettings(m.Chat.ID)
+		syms, err := parseSymbolList(cs, symsField, portfolioSymbols, "/ew-port SPY AAPL QQQ 2y")
+		if err != nil {
+			h.reply(m.Chat.ID, err.Error())
 			return
 		}
-		h.handlePortfolio(m.Chat.ID, syms, window)
+		opts := renderOptions(ctx, cs)
+		opts.Format = g[3]
+		h.handlePortfolio(ctx, m.Chat.ID, syms, window, opts)
+
+	case rePortWatch.MatchString(txt):
+		h.trackCommand(ctx, m.Chat.ID, userID, "portwatch", "portfolio", txt)
+		g := rePortWatch.FindStringSubmatch(txt)
+		h.handlePortWatch(ctx, m.Chat.ID, g[1], g[2])
 
 	case rePort.MatchString(txt):
-		h.trackCommand(m.Chat.ID, m.From.ID, "port", "portfolio")
+		h.trackCommand(ctx, m.Chat.ID, userID, "port", "portfolio", txt)
 		g := rePort.FindStringSubmatch(txt)
 		input := strings.TrimSpace(g[1])
 
+		cs := h.chartSettings(m.Chat.ID)
 		symbols, weights, window, err := finance.ParseWeightedPortfolio(input)
 		if err != nil {
-			h.reply(m.Chat.ID, fmt.Sprintf("Invalid portfolio format: %v\n\nUsage: /port SPY 0.5 AAPL 0.25 1y", err))
+			h.reply(m.Chat.ID, T(cs, "port.invalid", err, "/port SPY 0.5 AAPL 0.25 1y, /port SPY 60% TLT rest, /port SPY QQQ GLD eq 2y"))
 			return
 		}
 		if len(symbols) == 0 {
-			h.reply(m.Chat.ID, "Please provide at least one symbol with weight, e.g. /port SPY 0.6 AAPL 0.3 1y")
+			h.reply(m.Chat.ID, T(cs, "port.no_symbols", "/port SPY 0.6 AAPL 0.3 1y"))
 			return
 		}
-		h.handleWeightedPortfolio(m.Chat.ID, symbols, weights, window)
+		hist, ok := parseHistOption(g[3], g[4] != "")
+		if !ok {
+			h.reply(m.Chat.ID, T(cs, "port.bad_hist", strings.TrimPrefix(g[3], "hist="), finance.HistogramFixedWidth))
+			return
+		}
+		opts := renderOptions(ctx, cs)
+		opts.Format = g[5]
+		h.handleWeightedPortfolio(ctx, m.Chat.ID, symbols, weights, window, g[2] != "", hist, opts)
+
+	case rePortStats.MatchString(txt):
+		h.trackCommand(ctx, m.Chat.ID, userID, "portstats", "portfolio", txt)
+		input := strings.TrimSpace(rePortStats.FindStringSubmatch(txt)[1])
+		symbols, weights, window, err := finance.ParseWeightedPortfolio(input)
+		if err != nil {
+			h.reply(m.Chat.ID, h.t(m.Chat.ID, "port.invalid", err, "/portstats SPY 0.6 TLT 0.4 1y"))
+			return
+		}
+		if len(symbols) == 0 {
+			h.reply(m.Chat.ID, h.t(m.Chat.ID, "port.no_symbols", "/portstats SPY 0.6 TLT 0.4 1y"))
+			return
+		}
+		h.handlePortStats(ctx, m.Chat.ID, symbols, weights, window)
+
+	case rePortBuilder.MatchString(txt):
+		h.trackCommand(ctx, m.Chat.ID, userID, "portbuilder", "portfolio", txt)
+		h.handlePortBuilder(m.Chat.ID, userID, rePortBuilder.FindStringSubmatch(txt)[1])
+
+	case reRecommendScore.MatchString(txt):
+		h.trackCommand(ctx, m.Chat.ID, userID, "scoreboard", "recommender", txt)
+		days := scoreboardDefaultDays
+		if g := reRecommendScore.FindStringSubmatch(txt); g[1] != "" {
+			days, _ = strconv.Atoi(g[1])
+		}
+		h.handleScoreboard(ctx, m.Chat.ID, days)
 
 	case reRecommend.MatchString(txt):
-		h.trackCommand(m.Chat.ID, m.From.ID, "recommend", "recommender")
+		h.trackCommand(ctx, m.Chat.ID, userID, "recommend", "recommender", txt)
 		g := reRecommend.FindStringSubmatch(txt)
 		userInput := strings.TrimSpace(g[1])
 		if userInput == "" {
-			h.reply(m.Chat.ID, "Please provide your investment thesis or market view after /recommend")
+			h.reply(m.Chat.ID, h.t(m.Chat.ID, "recommend.empty"))
 			return
 		}
-		h.reply(m.Chat.ID, "🤖 Analyzing your request and generating trading recommendations...")
-		h.handleRecommendation(m.Chat.ID, userInput)
+		h.reply(m.Chat.ID, h.t(m.Chat.ID, "recommend.progress"))
+		h.handleRecommendation(ctx, m.Chat.ID, userID, userInput)
+
+	case reActivity.MatchString(txt):
+		h.trackCommand(ctx, m.Chat.ID, userID, "activity", "other", txt)
+		g := reActivity.FindStringSubmatch(txt)
+		days := activityDefaultDays
+		if g[1] != "" {
+			days, _ = strconv.Atoi(g[1])
+			days = min(max(days, 1), activityMaxDays)
+		}
+		h.handleActivity(ctx, m.Chat.ID, days, g[2] != "")
 
 	case reUsage.MatchString(txt):
-		h.trackCommand(m.Chat.ID, m.From.ID, "usage", "other")
+		h.trackCommand(ctx, m.Chat.ID, userID, "usage", "other", txt)
 		g := reUsage.FindStringSubmatch(txt)
 		days := 0 // Default: all time
 		if len(g) >= 2 && g[1] != "" {
@@ -296,81 +607,375 @@ func (h *Handlers) HandleMessage(m *tgbotapi.Message) {
 				}
 			}
 		}
-		h.reply(m.Chat.ID, "📊 Generating usage analytics...")
-		h.handleUsage(m.Chat.ID, days)
+		h.reply(m.Chat.ID, h.t(m.Chat.ID, "usage.progress"))
+		h.handleUsage(ctx, m.Chat.ID, days)
+
+	case reSet.MatchString(txt):
+		h.trackCommand(ctx, m.Chat.ID, userID, "set", "other", txt)
+		g := reSet.FindStringSubmatch(txt)
+		h.handleSet(ctx, m.Chat.ID, strings.ToLower(g[1]), strings.TrimSpace(g[2]))
+
+	case reVersion.MatchString(txt):
+		h.trackCommand(ctx, m.Chat.ID, userID, "version", "other", txt)
+		h.handleVersion(m.Chat.ID)
+
+	case reStatus.MatchString(txt):
+		h.trackCommand(ctx, m.Chat.ID, userID, "status", "other", txt)
+		h.handleStatus(m.Chat.ID)
+
+	case reReport.MatchString(txt):
+		h.trackCommand(ctx, m.Chat.ID, userID, "report", "other", txt)
+		h.handleReport(ctx, m.Chat.ID)
+
+	case reBroadcast.MatchString(txt):
+		h.trackCommand(ctx, m.Chat.ID, userID, "broadcast", "other", txt)
+		g := reBroadcast.FindStringSubmatch(txt)
+		h.handleBroadcast(ctx, m.Chat.ID, strings.TrimSpace(g[1]))
+
+	case reFeedback.MatchString(txt):
+		h.trackCommand(ctx, m.Chat.ID, userID, "feedback", "other", txt)
+		g := reFeedback.FindStringSubmatch(txt)
+		h.handleFeedback(ctx, m, strings.TrimSpace(g[1]))
+
+	case reSchedule.MatchString(txt):
+		h.trackCommand(ctx, m.Chat.ID, userID, "schedule", "other", txt)
+		g := reSchedule.FindStringSubmatch(txt)
+		h.handleSchedule(m.Chat.ID, strings.TrimSpace(g[1]))
+
+	case reWatch.MatchString(txt):
+		h.trackCommand(ctx, m.Chat.ID, userID, "watch", "other", txt)
+		g := reWatch.FindStringSubmatch(txt)
+		h.handleWatch(m.Chat.ID, strings.TrimSpace(g[1]))
+
+	case reBrief.MatchString(txt):
+		h.trackCommand(ctx, m.Chat.ID, userID, "brief", "charts", txt)
+		g := reBrief.FindStringSubmatch(txt)
+		h.handleBrief(ctx, m.Chat.ID, strings.TrimSpace(g[1]))
+
+	case reMovers.MatchString(txt):
+		h.trackCommand(ctx, m.Chat.ID, userID, "movers", "charts", txt)
+		g := reMovers.FindStringSubmatch(txt)
+		h.handleMovers(ctx, m.Chat.ID, g[1])
+
+	case reTarget.MatchString(txt):
+		h.trackCommand(ctx, m.Chat.ID, userID, "target", "other", txt)
+		g := reTarget.FindStringSubmatch(txt)
+		h.handleTarget(ctx, m, strings.TrimSpace(g[1]))
+
+	case rePaper.MatchString(txt):
+		h.trackCommand(ctx, m.Chat.ID, userID, "paper", "portfolio", txt)
+		g := rePaper.FindStringSubmatch(txt)
+		h.handlePaper(ctx, m, strings.TrimSpace(g[1]))
+
+	case reMonteCarlo.MatchString(txt):
+		h.trackCommand(ctx, m.Chat.ID, userID, "montecarlo", "portfolio", txt)
+		g := reMonteCarlo.FindStringSubmatch(txt)
+		h.reply(m.Chat.ID, h.t(m.Chat.ID, "montecarlo.progress"))
+		h.handleMonteCarlo(ctx, m.Chat.ID, strings.TrimSpace(g[1]))
+
+	case reVIX.MatchString(txt):
+		h.trackCommand(ctx, m.Chat.ID, userID, "vix", "charts", txt)
+		g := reVIX.FindStringSubmatch(txt)
+		h.handleVIX(ctx, m.Chat.ID, g[1])
+
+	case reYield.MatchString(txt):
+		h.trackCommand(ctx, m.Chat.ID, userID, "yield", "charts", txt)
+		h.handleYield(ctx, m.Chat.ID, reYield.FindStringSubmatch(txt)[1])
+
+	case reCurve.MatchString(txt):
+		h.trackCommand(ctx, m.Chat.ID, userID, "curve", "charts", txt)
+		h.handleCurve(ctx, m.Chat.ID)
+
+	case reHeat.MatchString(txt):
+		h.trackCommand(ctx, m.Chat.ID, userID, "heat", "charts", txt)
+		g := reHeat.FindStringSubmatch(txt)
+		cs := h.chartSettings(m.Chat.ID)
+		syms, err := parseSymbolList(cs, strings.TrimSpace(g[1]), heatSymbols, "/heat SPY QQQ IWM TLT 1m")
+		if err != nil {
+			h.reply(m.Chat.ID, err.Error())
+			return
+		}
+		opts := renderOptions(ctx, cs)
+		opts.Format = g[3]
+		h.handleHeat(ctx, m.Chat.ID, syms, g[2], opts)
+
+	case reFutures.MatchString(txt):
+		h.trackCommand(ctx, m.Chat.ID, userID, "futures", "charts", txt)
+		h.handleFutures(ctx, m.Chat.ID)
+
+	case reMACD.MatchString(txt):
+		h.trackCommand(ctx, m.Chat.ID, userID, "macd", "charts", txt)
+		g := reMACD.FindStringSubmatch(txt)
+		h.handleMACD(ctx, m.Chat.ID, g[1], g[2], g[3])
+
+	case reATR.MatchString(txt):
+		h.trackCommand(ctx, m.Chat.ID, userID, "atr", "charts", txt)
+		g := reATR.FindStringSubmatch(txt)
+		h.handleATR(ctx, m.Chat.ID, g[1], g[2], g[3])
+
+	case reOHLC.MatchString(txt):
+		h.trackCommand(ctx, m.Chat.ID, userID, "ohlc", "charts", txt)
+		g := reOHLC.FindStringSubmatch(txt)
+		h.handleOHLC(ctx, m.Chat.ID, g[1], g[2])
+
+	case reExport.MatchString(txt):
+		h.trackCommand(ctx, m.Chat.ID, userID, "export", "charts", txt)
+		g := reExport.FindStringSubmatch(txt)
+		cs := h.chartSettings(m.Chat.ID)
+		interval, window := defaultInterval(cs), customWindow(cs)
+		if g[2] != "" {
+			interval = g[2]
+		}
+		if g[3] != "" {
+			window = g[3]
+		}
+		h.handleExport(ctx, m.Chat.ID, g[1], interval, window)
+
+	case reInfo.MatchString(txt):
+		h.trackCommand(ctx, m.Chat.ID, userID, "info", "charts", txt)
+		h.handleInfo(ctx, m.Chat.ID, reInfo.FindStringSubmatch(txt)[1])
+
+	case reOptMove.MatchString(txt):
+		h.trackCommand(ctx, m.Chat.ID, userID, "optmove", "charts", txt)
+		h.handleOptMove(ctx, m.Chat.ID, reOptMove.FindStringSubmatch(txt)[1])
+
+	case reYoY.MatchString(txt):
+		h.trackCommand(ctx, m.Chat.ID, userID, "yoy", "charts", txt)
+		g := reYoY.FindStringSubmatch(txt)
+		h.handleYoY(ctx, m.Chat.ID, g[1], g[2])
+
+	case reHistory.MatchString(txt):
+		h.trackCommand(ctx, m.Chat.ID, userID, "history", "other", txt)
+		g := reHistory.FindStringSubmatch(txt)
+		h.handleHistory(m.Chat.ID, g[1])
+
+	case reAgain.MatchString(txt):
+		h.trackCommand(ctx, m.Chat.ID, userID, "again", "charts", txt)
+		h.handleAgain(ctx, m, reAgain.FindStringSubmatch(txt)[1])
+
+	case reCalendar.MatchString(txt):
+		h.trackCommand(ctx, m.Chat.ID, userID, "calendar", "other", txt)
+		g := reCalendar.FindStringSubmatch(txt)
+		h.handleCalendar(ctx, m.Chat.ID, g[1])
+
+	case reAlias.MatchString(txt):
+		h.trackCommand(ctx, m.Chat.ID, userID, "alias", "other", txt)
+		h.handleAlias(m.Chat.ID, reAlias.FindStringSubmatch(txt)[1])
+
+	case reSummaries.MatchString(txt):
+		h.trackCommand(ctx, m.Chat.ID, userID, "summaries", "summarizer", txt)
+		h.handleSummaries(m.Chat.ID, reSummaries.FindStringSubmatch(txt)[1])
+
+	case reAsk.MatchString(txt):
+		h.trackCommand(ctx, m.Chat.ID, userID, "ask", "summarizer", txt)
+		g := reAsk.FindStringSubmatch(txt)
+		hours := askDefaultHours
+		if g[1] != "" {
+			hours, _ = strconv.Atoi(g[1])
+			hours = max(1, min(hours, askMaxHours))
+		}
+		h.handleAsk(ctx, m.Chat.ID, threadID, hours, strings.TrimSpace(g[2]))
+
+	case reActions.MatchString(txt):
+		args := strings.TrimSpace(reActions.FindStringSubmatch(txt)[1])
+		// only extraction calls the model and counts against the AI budget
+		name := "actions"
+		if sub, _, _ := strings.Cut(strings.ToLower(args), " "); sub == "open" || sub == "done" {
+			name = "actionlist"
+		}
+		h.trackCommand(ctx, m.Chat.ID, userID, name, "summarizer", txt)
+		h.handleActions(ctx, m.Chat.ID, threadID, args)
+
+	case reTranslate.MatchString(txt):
+		h.trackCommand(ctx, m.Chat.ID, userID, "translate", "summarizer", txt)
+		h.handleTranslate(ctx, m, strings.TrimSpace(reTranslate.FindStringSubmatch(txt)[1]))
+
+	case reRecap.MatchString(txt):
+		h.trackCommand(ctx, m.Chat.ID, userID, "recap", "other", txt)
+		g := reRecap.FindStringSubmatch(txt)
+		days := recapDefaultDays
+		if g[1] != "" {
+			days, _ = strconv.Atoi(g[1])
+			days = max(1, min(days, recapMaxDays))
+		}
+		h.handleRecap(ctx, m.Chat.ID, days, g[2] != "")
+
+	case reChart.MatchString(txt):
+		h.trackCommand(ctx, m.Chat.ID, userID, "chart", "charts", txt)
+		h.handleNLChart(ctx, m, strings.TrimSpace(reChart.FindStringSubmatch(txt)[1]))
+
+	default:
+		if text, ok := h.mentionText(txt); ok && !isRerun(ctx) {
+			h.trackCommand(ctx, m.Chat.ID, userID, "chart", "charts", txt)
+			h.handleNLChart(ctx, m, text)
+		} else if !isRerun(ctx) && !isScheduled(ctx) {
+			h.autoCashtags(ctx, m, txt)
+		}
 	}
 }
 
-func (h *Handlers) handleSummary(chatID int64, hours int) {
-	since := time.Now().Add(-time.Duration(hours) * time.Hour).Unix()
-	msgs, err := h.store.FetchMessages(chatID, since)
+// handleSummary summarizes messages stored for sourceChatID and replies in chatID.
+// The two differ when a discussion group summarizes its linked channel. threadID
+// limits the summary to one forum topic, or storage.AllThreads for the whole chat.
+func (h *Handlers) handleSummary(ctx context.Context, chatID, sourceChatID int64, threadID, hours int) {
+	now := time.Now()
+	since := now.Add(-time.Duration(hours) * time.Hour).Unix()
+	h.flushMessages(ctx)
+	msgs, err := h.store.FetchMessages(sourceChatID, threadID, since)
 	if err != nil {
-		h.reply(chatID, "Summary failed: "+err.Error())
+		logging.FromContext(ctx).Error("summary failed", "chat_id", chatID, "err", err)
+		h.reply(chatID, h.t(chatID, "summary.failed")+err.Error())
 		return
 	}
 	if len(msgs) == 0 {
-		h.reply(chatID, "No messages found in the selected time window.")
+		h.reply(chatID, h.t(chatID, "summary.no_messages"))
+		return
+	}
+	key := newSummaryKey(sourceChatID, threadID, hours, msgs)
+	if out, ok := h.summaries.get(key, now); ok {
+		logging.FromContext(ctx).Info("summary: served from cache", "chat_id", chatID, "messages", len(msgs))
+		h.sendLong(chatID, out+"\n\n"+h.t(chatID, "summary.cached"), "Markdown")
 		return
 	}
-	ctx, cancel := context.WithTimeout(context.Background(), 45*time.Second)
+	ctx, cancel := context.WithTimeout(ctx, 45*time.Second)
 	defer cancel()
-	out, err := h.summarize.Summarize(ctx, msgs)
+	transcript, condensed := openai.Condense(h.summaryTranscript(ctx, sourceChatID, msgs))
+	out, err := h.summarize.Summarize(ctx, transcript)
 	if err != nil {
-		h.reply(chatID, "Summary failed: "+err.Error())
+		logging.FromContext(ctx).Error("summary failed", "chat_id", chatID, "err", err)
+		h.reply(chatID, h.t(chatID, "summary.failed")+err.Error())
 		return
 	}
-	msg := tgbotapi.NewMessage(chatID, out)
-	msg.ParseMode = "Markdown"
-	h.api.Send(msg)
+	if condensed > 0 {
+		out += "\n\n" + h.t(chatID, "summary.condensed", condensed, len(msgs))
+	}
+	h.summaries.put(key, out, now)
+	h.saveSummary(ctx, storage.Summary{ChatID: chatID, ThreadID: threadID, From: since, To: now.Unix(), Text: out, TS: now.Unix()})
+	h.sendLong(chatID, out, "Markdown")
+}
+
+// flushMessages writes the chat messages still queued, before a command reads
+// them back.
+func (h *Handlers) flushMessages(ctx context.Context) {
+	if err := h.messages.Flush(); err != nil {
+		logging.FromContext(ctx).Warn("message flush failed", "err", err)
+	}
 }
 
-func (h *Handlers) handleStock(chatID int64, sym string, window string) {
-	img, err := finance.Make5mChart(sym, window)
+// summaryTranscript converts stored messages for the summarizer, attaching the
+// parent of each reply. Parents older than the window are looked up in storage;
+// replies whose parent was never stored are passed on as plain messages.
+func (h *Handlers) summaryTranscript(ctx context.Context, chatID int64, msgs []storage.Message) []openai.ChatMessage {
+	byID := make(map[int]storage.Message, len(msgs))
+	for _, m := range msgs {
+		if m.MessageID != 0 {
+			byID[m.MessageID] = m
+		}
+	}
+	var missing []int
+	for _, m := range msgs {
+		if _, ok := byID[m.ReplyTo]; m.ReplyTo != 0 && !ok {
+			missing = append(missing, m.ReplyTo)
+		}
+	}
+	if len(missing) > 0 {
+		parents, err := h.store.FetchMessagesByID(chatID, missing)
+		if err != nil {
+			logging.FromContext(ctx).Warn("summary: reply parents lookup failed", "chat_id", chatID, "err", err)
+		}
+		for _, p := range parents {
+			byID[p.MessageID] = p
+		}
+	}
+	author := func(m storage.Message) string {
+		if m.UserName != "" {
+			return m.UserName
+		}
+		return fmt.Sprintf("user %d", m.UserID)
+	}
+	out := make([]openai.ChatMessage, 0, len(msgs))
+	for _, m := range msgs {
+		cm := openai.ChatMessage{Author: author(m), Text: m.Text}
+		if p, ok := byID[m.ReplyTo]; ok && m.ReplyTo != 0 {
+			cm.ReplyAuthor, cm.ReplyText = author(p), p.Text
+		}
+		out = append(out, cm)
+	}
+	return out
+}
+
+func (h *Handlers) handleStock(ctx context.Context, chatID int64, sym string, window string, opts finance.RenderOptions) {
+	cs := h.chartSettings(chatID)
+	ctx, fresh := finance.WithFreshness(ctx)
+	res, err := finance.Make5mChart(ctx, sym, window, opts)
 	if err != nil {
-		h.reply(chatID, fmt.Sprintf("Couldn’t fetch %s: %v", sym, err))
+		logging.FromContext(ctx).Error("stock failed", "chat_id", chatID, "symbol", sym, "err", err)
+		h.replyFailure(chatID, T(cs, "stock.failed", sym), err)
 		return
 	}
-	photo := tgbotapi.NewPhoto(chatID, tgbotapi.FileBytes{Name: sym + ".png", Bytes: img})
 	w := strings.ToLower(strings.TrimSpace(window))
 	if w == "" {
 		w = "1d"
 	}
-	photo.Caption = strings.ToUpper(sym) + " • 5m • " + strings.ToUpper(w)
-	h.api.Send(photo)
+	span := strings.ToUpper(w)
+	if opts.Anchor != "" {
+		span = finance.AnchorLabel(opts.Anchor)
+	}
+	caption := strings.ToUpper(sym) + " • 5m • " + span + priceLine(cs, res.Meta)
+	if res.Meta.Note != "" {
+		caption += "\n" + res.Meta.Note
+	}
+	h.sendChart(chatID, sym, freshCaption(cs, caption, fresh), res.Image, opts)
+	markDelivered(ctx)
 }
 
-func (h *Handlers) handleMultiStock(chatID int64, syms []string, window string) {
-	img, err := finance.MakeMulti5mChart(syms, window)
+func (h *Handlers) handleMultiStock(ctx context.Context, chatID int64, syms []string, window string, opts finance.RenderOptions) {
+	cs := h.chartSettings(chatID)
+	ctx, fresh := finance.WithFreshness(ctx)
+	res, err := finance.MakeMulti5mChart(ctx, syms, window, opts)
 	if err != nil {
-		h.reply(chatID, fmt.Sprintf("Couldn’t fetch multi: %v", err))
+		logging.FromContext(ctx).Error("stocks failed", "chat_id", chatID, "symbols", syms, "err", err)
+		h.replyFailure(chatID, T(cs, "stock.failed", "multi"), err)
 		return
 	}
-	name := strings.Join(syms, "_")
-	photo := tgbotapi.NewPhoto(chatID, tgbotapi.FileBytes{Name: name + ".png", Bytes: img})
 	w := strings.ToLower(strings.TrimSpace(window))
 	if w == "" {
 		w = "1d"
 	}
-	photo.Caption = "Multi: " + strings.Join(syms, ", ") + " • 5m • " + strings.ToUpper(w)
-	h.api.Send(photo)
+	skipped := res.Meta.Skipped
+	logSkipped(ctx, skipped)
+	caption := T(cs, "multi.caption") + strings.Join(chartedSymbols(syms, skipped), ", ") + " • 5m • " + strings.ToUpper(w) + changesLine(res.Meta) + skippedNote(cs, skipped)
+	h.sendChart(chatID, strings.Join(syms, "_"), freshCaption(cs, caption, fresh), res.Image, opts)
+	markDelivered(ctx)
 }
 
-func (h *Handlers) handlePortfolio(chatID int64, syms []string, window string) {
-	img, err := finance.MakePortfolioChart(syms, window)
+func (h *Handlers) handlePortfolio(ctx context.Context, chatID int64, syms []string, window string, opts finance.RenderOptions) {
+	cs := h.chartSettings(chatID)
+	ctx, fresh := finance.WithFreshness(ctx)
+	res, err := finance.MakePortfolioChart(ctx, syms, window, opts)
 	if err != nil {
-		h.reply(chatID, fmt.Sprintf("Portfolio failed: %v", err))
+		logging.FromContext(ctx).Error("ew-port failed", "chat_id", chatID, "symbols", syms, "err", err)
+		h.replyFailure(chatID, T(cs, "ewport.failed"), err)
 		return
 	}
-	name := strings.Join(syms, "_")
-	photo := tgbotapi.NewPhoto(chatID, tgbotapi.FileBytes{Name: name + "_portfolio_" + window + ".png", Bytes: img})
-	photo.Caption = "Equal Weighted Portfolio: " + strings.Join(syms, ", ") + " • " + strings.ToUpper(window)
-	h.api.Send(photo)
+	caption := T(cs, "ewport.caption") + strings.Join(syms, ", ") + " • " + strings.ToUpper(window) + statsLine(cs, res.Meta)
+	h.sendChart(chatID, strings.Join(syms, "_")+"_portfolio_"+window, freshCaption(cs, caption, fresh), res.Image, opts)
+	markDelivered(ctx)
 }
 
-func (h *Handlers) handleWeightedPortfolio(chatID int64, syms []string, weights []float64, window string) {
-	img, err := finance.MakeWeightedPortfolioChart(syms, weights, window)
+func (h *Handlers) handleWeightedPortfolio(ctx context.Context, chatID int64, syms []string, weights []float64, window string, detail bool, hist *finance.HistogramOptions, opts finance.RenderOptions) {
+	cs := h.chartSettings(chatID)
+	ctx, fresh := finance.WithFreshness(ctx)
+	var res finance.ChartResult
+	var err error
+	if detail {
+		res, err = finance.MakeWeightedPortfolioDetailChart(ctx, syms, weights, window, opts)
+	} else {
+		res, err = finance.MakeWeightedPortfolioChart(ctx, syms, weights, window, opts)
+	}
 	if err != nil {
-		h.reply(chatID, fmt.Sprintf("Weighted portfolio failed: %v", err))
+		logging.FromContext(ctx).Error("port failed", "chat_id", chatID, "symbols", syms, "err", err)
+		h.replyFailure(chatID, T(cs, "port.failed"), err)
 		return
 	}
 
@@ -380,77 +985,207 @@ func (h *Handlers) handleWeightedPortfolio(chatID int64, syms []string, weights
 		weightStrs = append(weightStrs, fmt.Sprintf("%s%.1f", symbol, weights[i]*100))
 	}
 
-	name := strings.Join(weightStrs, "_")
-	photo := tgbotapi.NewPhoto(chatID, tgbotapi.FileBytes{Name: name + "_wport_" + window + ".png", Bytes: img})
-
-	// Calculate total weight and cash
-	totalWeight := 0.0
-	for _, w := range weights {
-		totalWeight += w
-	}
-	cashPct := (1.0 - totalWeight) * 100
+	name := strings.Join(weightStrs, "_") + "_wport_" + window
 
 	var caption strings.Builder
-	caption.WriteString("Weighted Portfolio: ")
-	for i, symbol := range syms {
-		if i > 0 {
-			caption.WriteString(", ")
-		}
-		weight := weights[i]
-		if weight >= 0 {
-			caption.WriteString(fmt.Sprintf("%s %.1f%%", symbol, weight*100))
-		} else {
-			caption.WriteString(fmt.Sprintf("%s %.1f%% SHORT", symbol, -weight*100))
+	caption.WriteString(T(cs, "port.caption") + portfolioComposition(cs, syms, weights))
+	caption.WriteString(" • " + strings.ToUpper(window))
+	caption.WriteString(statsLine(cs, res.Meta))
+
+	h.sendChart(chatID, name, freshCaption(cs, caption.String(), fresh), res.Image, opts)
+	markDelivered(ctx)
+	if len(res.Meta.Contributions) > 0 {
+		h.reply(chatID, contributionTable(cs, res.Meta.Contributions))
+	}
+	if hist != nil {
+		img, err := finance.MakeWeightedPortfolioHistogram(ctx, syms, weights, window, *hist, opts)
+		if err != nil {
+			logging.FromContext(ctx).Error("port: histogram failed", "chat_id", chatID, "symbols", syms, "err", err)
+			h.replyFailure(chatID, T(cs, "port.hist_failed"), err)
+			return
 		}
+		h.sendChart(chatID, name+"_hist", T(cs, "port.hist_caption")+portfolioComposition(cs, syms, weights)+" • "+strings.ToUpper(window), img, opts)
 	}
-	if cashPct > 0 {
-		caption.WriteString(fmt.Sprintf(", Cash %.1f%%", cashPct))
-	} else if cashPct < 0 {
-		caption.WriteString(fmt.Sprintf(", Margin %.1f%%", -cashPct))
+}
+
+// parseHistOption reads /port's histogram token: "hist" picks the bin width
+// from the returns, "hist=fixed" uses finance.HistogramFixedWidth and
+// "hist=0.5" (or "0.5%") sets it. normal, allowed on its own, implies hist.
+// It returns nil when neither was given, and false for a bad width.
+func parseHistOption(tok string, normal bool) (*finance.HistogramOptions, bool) {
+	if tok == "" && !normal {
+		return nil, true
 	}
-	caption.WriteString(" • " + strings.ToUpper(window))
+	opt := &finance.HistogramOptions{Normal: normal}
+	switch v, _ := strings.CutPrefix(tok, "hist="); {
+	case tok == "" || tok == "hist":
+	case v == "fixed":
+		opt.BinWidth = finance.HistogramFixedWidth
+	default:
+		w, err := strconv.ParseFloat(strings.TrimSuffix(v, "%"), 64)
+		if err != nil || w < 0.01 || w > 10 {
+			return nil, false
+		}
+		opt.BinWidth = w
+	}
+	return opt, true
+}
 
-	photo.Caption = caption.String()
-	h.api.Send(photo)
+// contributionTable lists each constituent's return and its contribution to
+// the portfolio return, largest drag first. /port detail sends it instead of
+// the per-asset lines when there are too many constituents to draw.
+func contributionTable(settings storage.ChatSettings, cs []finance.AssetContribution) string {
+	sort.SliceStable(cs, func(i, j int) bool { return cs[i].Contribution < cs[j].Contribution })
+	var b strings.Builder
+	b.WriteString(T(settings, "port.contributions", finance.PortfolioDetailMax) + "\n")
+	total := 0.0
+	for _, c := range cs {
+		b.WriteString("\n" + T(settings, "port.contribution", c.Symbol, c.Weight*100, c.Return, c.Contribution))
+		total += c.Contribution
+	}
+	b.WriteString("\n\n" + T(settings, "port.contributions_total", total))
+	return b.String()
 }
 
-func (h *Handlers) handleHelp(chatID int64) {
-	help := "Commands\n\n" +
-		"- /summary [hours] - Summarize chat messages from the last N hours (default: 1, max: 48)\n" +
-		"- /recommend TEXT - Get AI-powered trading recommendations based on your market view or thesis\n" +
-		"- /usage [Xd] - View usage analytics (default: all time, specify days like /usage 7d)\n" +
-		"- /stock SYMBOL [1d|1w|1m] - Single-symbol 5m mini chart\n" +
-		"- /stocks S1 S2 ... [1d|1w|1m] - Multi-symbol 5m; auto-normalizes to % when >2\n" +
-		"- /stockx SYMBOL [1m|5m|15m|1h|1d] [1d|5d|1m|3m|6m|1y|2y|5y|10y|30y] - Single-symbol custom\n" +
-		"- /stocksx S1 S2 ... [interval] [window] - Multi-symbol custom; auto-normalizes to % when >2\n" +
-		"- /stocks-index S1 S2 ... [interval] [window] - Index to base 100 at start for relative performance\n" +
-		"- /ew-port S1 S2 ... [Xd|Xw|Xm|Xy] - Equal weighted portfolio backtest (starting $100)\n" +
-		"- /port S1 W1 S2 W2 ... [Xd|Xw|Xm|Xy] - Weighted portfolio (W>0=long, W<0=short, rest=cash/margin)\n" +
-		"\nLimits (Yahoo): 1m→30d, 5m→90d, 15m→180d, 1h→2y, 1d→30y. X-axis in Eastern Time."
-	h.reply(chatID, help)
+// helpText is the English /help list, the "help" message; /help COMMAND
+// shows a command's lines of it when the command has no card.
+const helpText = "Commands\n\n" +
+	"- /summary [hours] - Summarize chat messages from the last N hours (default: 1, max: 48)\n" +
+	"- /recommend TEXT - Get AI-powered trading recommendations based on your market view or thesis\n" +
+	"- /recommend scoreboard [Nd] - Hit rate and average return of the chat's recommended positions since they were made (default 30d)\n" +
+	"- /usage [Xd] - View usage analytics (default: all time, specify days like /usage 7d)\n" +
+	"- /activity [Nd] [users] - Chart this chat's messages per day (per hour up to 2d; default 7d, max 90d); users splits out the 5 most active\n" +
+	"- /summaries [n] - Recent stored summaries with their time ranges; /summaries show K re-sends one (kept 90 days)\n" +
+	"- /ask [Nh] QUESTION - Answer a question from the chat history (last 24h by default, plus older matching messages)\n" +
+	"- /actions [hours] | open | done N - Action items of the chat as a numbered checklist\n" +
+	"- /translate [language] - Reply to a message to translate it (default from /set lang, else English)\n" +
+	"- /recap [Nd] [chart] - Most discussed tickers of the last 7 days (up to 30) with their returns; chart adds an indexed chart of the top 5\n" +
+	"- /summary all [hours] - In a forum group, summarize every topic instead of just this one\n" +
+	"- /summary channel [hours] - Summarize the linked channel set via /set source_channel\n" +
+	"- /set source_channel @channel|ID|off - Link a channel whose posts /summary channel reads\n" +
+	"- /set window|interval|theme VALUE - Chart defaults used when arguments are omitted; /set show lists them\n" +
+	"- /set auto_pin on|off - Pin the scheduled morning brief silently, unpinning the previous one (default on)\n" +
+	"- /set store_messages off - Stop storing (and delete) this chat's messages; /summary becomes unavailable\n" +
+	"- /set lang LANGUAGE|off - Language of the bot's replies (English or Chinese) and the default /translate target\n" +
+	"- /watch add|remove S1 S2 ... - Manage the chat watchlist; /watch lists it\n" +
+	"- /brief on HH:MM|off|now - Weekday morning brief: market snapshot, watchlist moves and an AI comment\n" +
+	"- /movers [N%] - Watchlist symbols moving more than N% today (default 2%); /set movers_auto N pushes alerts\n" +
+	"- /target SYMBOL PRICE [note:\"...\"] - Get mentioned once when the price is crossed; /target list|delete N\n" +
+	"- /macd SYMBOL [interval] [window] - Price with MACD(12,26,9), signal and histogram below\n" +
+	"- /atr SYMBOL [period] [window] - Daily closes with close ± 3×ATR stop lines (default ATR(14), 6m)\n" +
+	"- /yoy SYMBOL [years] - This year's path vs previous years, each indexed to 100 in January (default 5)\n" +
+	"- /history [n] - Last n chart/portfolio commands; reply to the list with a number to run one again\n" +
+	"- /again [overrides] - Run the last chart/portfolio command again, changing the window, interval or theme, e.g. /again 1w, /again 1h 6m, /again dark\n" +
+	"- /ohlc SYMBOL [n] - Table of the last n daily bars: open, high, low, close and % change (default 10, max 30)\n" +
+	"- /export SYMBOL [interval] [window] - Download the series as CSV (timestamp, OHLC when available, close, volume)\n" +
+	"- /info SYMBOL - Fundamentals: name, sector, market cap, P/E, dividend yield, beta, 52-week range\n" +
+	"- /optmove SYMBOL - Move priced in by the nearest-expiry at-the-money straddle\n" +
+	"- /calendar [week] - High-impact US economic releases for the rest of the week (or the whole week)\n" +
+	"- /vix [window] - VIX with its 1-year percentile and VIX9D/VIX/VIX3M term structure (default 6m)\n" +
+	"- /yield [window] - 13-week, 5, 10 and 30-year Treasury yields in % (default 1y)\n" +
+	"- /curve - Treasury yield curve snapshot; flags an inversion when short rates exceed long ones\n" +
+	"- /heat S1 S2 ... [window] [svg] - One strip of cells, red to green by each symbol's change (default 1d)\n" +
+	"- /futures - Futures shorthands with quotes; symbol commands take them by name, e.g. /stock oil, /stocks es nq\n" +
+	"- /montecarlo S1 W1 S2 W2 ... WINDOW [horizon=20y] [sims=2000] [seed=N] - Projected value fan chart (5/25/50/75/95%)\n" +
+	"- /paper buy|sell SYMBOL QTY, /paper positions, /paper pnl - Shared paper-trading book at live quotes\n" +
+	"- /schedule HH:MM /command - Post a command every weekday at that local time; /schedule list|delete N\n" +
+	"- /feedback TEXT - Send feedback to the bot maintainer\n" +
+	"- /chart TEXT - Chart from plain language, e.g. /chart apple vs microsoft this year; also works by mentioning the bot\n" +
+	"- /alias add NAME \"/command args\" - Chat shorthand, e.g. /alias add g \"/stockx GLD 1h 6m\" then /g; /alias list|remove NAME. Built in: /s, /ss, /sx, /p\n" +
+	"- /set tz Area/City - Time zone for chart labels (default America/New_York)\n" +
+	"- /stock SYMBOL [1d|1w|1m] [open|pre|prevclose] [vwap] [svg] - Single-symbol 5m mini chart, optionally with session VWAP; open/pre/prevclose show today's session only\n" +
+	"- /stocks S1 S2 ... [1d|1w|1m] [svg] - Multi-symbol 5m; auto-normalizes to % when >2\n" +
+	"- /stockx SYMBOL [1m|5m|15m|1h|1d] [1d|5d|1m|3m|6m|1y|2y|5y|10y|30y] [open|pre|prevclose] [vwap] [noevents] [svg] - Single-symbol custom (vwap and anchors on intraday intervals; daily charts mark ex-dividend dates D and splits S unless noevents)\n" +
+	"- /stocksx S1 S2 ... [interval] [window] [svg] - Multi-symbol custom; auto-normalizes to % when >2\n" +
+	"- /stocks-index S1 S2 ... [interval] [window] [svg] - Index to base 100 at start for relative performance\n" +
+	"- /ew-port S1 S2 ... [Xd|Xw|Xm|Xy] [svg] - Equal weighted portfolio backtest (starting $100)\n" +
+	"- /port S1 W1 S2 W2 ... [Xd|Xw|Xm|Xy|ytd] [detail] [hist[=W]] [normal] [svg] - Weighted portfolio over the window (default 1y; W>0=long, W<0=short, rest=cash/margin); detail also draws each asset, hist[=W] [normal] adds a daily returns histogram, svg sends the chart as an SVG file\n" +
+	"- /portstats S1 W1 S2 W2 ... [Xd|Xw|Xm|Xy|ytd] - Same backtest as /port, statistics only (CAGR, Sharpe, Sortino, drawdown dates, best/worst day)\n" +
+	"- /port watch NAME S1 W1 S2 W2 ... [dd=N] - Alert when the portfolio falls N% below its peak (default 10), valued after each US close; /port watch list, /port unwatch NAME\n" +
+	"- /portbuilder [S1 S2 ...] - Build a /port backtest with buttons: pick symbols from the watchlist (or name them), set weights in 5% steps, choose a window\n" +
+	"\nLimits (Yahoo): 1m→30d, 5m→90d, 15m→180d, 1h→2y, 1d→30y; longer windows are shortened with a notice (/help limits). /help COMMAND shows a command's arguments with examples. X-axis in Eastern Time unless /set tz is used."
+
+// handleHelpLimits lists the longest window served for each interval, from
+// the same table that clamps chart requests.
+func (h *Handlers) handleHelpLimits(chatID int64) {
+	cs := h.chartSettings(chatID)
+	var b strings.Builder
+	b.WriteString(T(cs, "help.limits") + "\n")
+	for _, l := range finance.IntervalLimits {
+		b.WriteString("\n" + T(cs, "help.limit", l.Interval, l.Max))
+	}
+	b.WriteString("\n\n" + T(cs, "help.limits_note"))
+	h.reply(chatID, b.String())
 }
 
-func (h *Handlers) handleRecommendation(chatID int64, userInput string) {
-	ctx, cancel := context.WithTimeout(context.Background(), 45*time.Second)
+// handleRecommendation answers a thesis and records the positions it
+// suggests for /recommend scoreboard.
+func (h *Handlers) handleRecommendation(ctx context.Context, chatID, userID int64, userInput string) {
+	ctx, cancel := context.WithTimeout(ctx, 45*time.Second)
 	defer cancel()
 
 	recommendation, err := h.recommend.GetTradingRecommendation(ctx, userInput)
 	if err != nil {
-		h.reply(chatID, "Failed to generate recommendation: "+err.Error())
+		logging.FromContext(ctx).Error("recommend failed", "chat_id", chatID, "err", err)
+		h.reply(chatID, h.t(chatID, "recommend.failed")+err.Error())
 		return
 	}
 
-	msg := tgbotapi.NewMessage(chatID, recommendation)
+	msg := tgbotapi.NewMessage(chatID, recommendation.Text)
 	msg.ParseMode = "Markdown"
 	h.api.Send(msg)
+
+	now := time.Now().Unix()
+	recs := make([]storage.Recommendation, len(recommendation.Picks))
+	for i, p := range recommendation.Picks {
+		recs[i] = storage.Recommendation{ChatID: chatID, UserID: userID, Symbol: p.Symbol, Short: p.Short, Thesis: userInput, TS: now}
+	}
+	if len(recs) > 0 {
+		if err := h.store.SaveRecommendations(recs); err != nil {
+			logging.FromContext(ctx).Error("recommend: save picks failed", "chat_id", chatID, "err", err)
+		}
+	}
+}
+
+// migrateChat moves stored data from a group's old chat ID to its supergroup ID.
+// It is safe to run twice since the second run finds no rows under oldID.
+func (h *Handlers) migrateChat(ctx context.Context, oldID, newID int64) {
+	moved, err := h.store.MigrateChat(oldID, newID)
+	h.settings.Invalidate(oldID, newID)
+	h.again.forget(oldID, newID)
+	if err != nil {
+		logging.FromContext(ctx).Error("chat migration failed", "old_chat_id", oldID, "new_chat_id", newID, "err", err)
+		return
+	}
+	logging.FromContext(ctx).Info("chat migrated", "old_chat_id", oldID, "new_chat_id", newID, "rows", moved)
 }
 
-func (h *Handlers) trackCommand(chatID, userID int64, command, category string) {
+// trackCommand records a command for analytics. Arguments and tickers are
+// kept only for commands /history can re-run, so free text like /feedback or
+// an /ask question is not stored twice.
+func (h *Handlers) trackCommand(ctx context.Context, chatID, userID int64, command, category, text string) {
+	u := storage.CommandUsage{ChatID: chatID, UserID: userID, Command: command, Category: category}
+	if historyCommands[command] {
+		u.Args = commandArgs(text)
+		u.Symbols = usageSymbols(command, text)
+	}
 	// Track command usage for analytics (ignore errors to not disrupt user experience)
-	_ = h.store.SaveCommandUsage(chatID, userID, command, category)
+	if id, err := h.store.SaveCommandUsage(u); err == nil {
+		timeCommand(ctx, id)
+	}
+}
+
+// commandArgs returns the arguments after the command token with runs of
+// whitespace collapsed to single spaces.
+func commandArgs(text string) string {
+	fields := strings.Fields(text)
+	if len(fields) < 2 {
+		return ""
+	}
+	return strings.Join(fields[1:], " ")
 }
 
-func (h *Handlers) handleUsage(chatID int64, days int) {
+func (h *Handlers) handleUsage(ctx context.Context, chatID int64, days int) {
 	// Calculate time range
 	var since int64 = 0 // All time by default
 	if days > 0 {
@@ -458,23 +1193,43 @@ func (h *Handlers) handleUsage(chatID int64, days int) {
 	}
 
 	// Fetch usage statistics
+	cs := h.chartSettings(chatID)
 	stats, err := h.store.FetchUsageStats(chatID, since)
 	if err != nil {
-		h.reply(chatID, "Failed to fetch usage statistics: "+err.Error())
+		h.reply(chatID, T(cs, "usage.failed")+err.Error())
 		return
 	}
 
 	if len(stats) == 0 {
 		if days > 0 {
-			h.reply(chatID, fmt.Sprintf("No command usage found in the last %d days.", days))
+			h.reply(chatID, T(cs, "usage.none_days", days))
 		} else {
-			h.reply(chatID, "No command usage found.")
+			h.reply(chatID, T(cs, "usage.none"))
 		}
 		return
 	}
 
+	// Compare with the preceding period of the same length
+	var prev map[string]*storage.UsageStats
+	if days > 0 {
+		prevSince := time.Unix(since, 0).AddDate(0, 0, -days).Unix()
+		if p, err := h.store.FetchUsageStatsRange(chatID, prevSince, since); err == nil {
+			prev = p
+		}
+	}
+
 	// Generate text summary
-	textSummary := h.analytics.FormatUsageStatsText(stats, days)
+	textSummary := h.analytics.FormatUsageStatsText(stats, prev, days)
+	top, err := h.store.FetchTopSymbols(chatID, since, usageTopSymbols)
+	if err != nil {
+		logging.FromContext(ctx).Warn("usage: top symbols failed", "chat_id", chatID, "err", err)
+	}
+	textSummary += h.analytics.FormatTopSymbols(top)
+	latency, err := h.store.FetchLatencyStats(chatID, since)
+	if err != nil {
+		logging.FromContext(ctx).Warn("usage: latency failed", "chat_id", chatID, "err", err)
+	}
+	textSummary += h.analytics.FormatLatency(latency)
 
 	// Send text summary first
 	msg := tgbotapi.NewMessage(chatID, textSummary)
@@ -482,33 +1237,53 @@ func (h *Handlers) handleUsage(chatID int64, days int) {
 	h.api.Send(msg)
 
 	// Generate and send pie chart
-	pieChart, err := h.analytics.MakeUsageChart(stats, days)
+	pieChart, err := h.analytics.MakeUsageChart(ctx, stats, days)
 	if err == nil {
 		photo := tgbotapi.NewPhoto(chatID, tgbotapi.FileBytes{
 			Name:  "usage_distribution.png",
 			Bytes: pieChart,
 		})
-		photo.Caption = fmt.Sprintf("Command Usage Distribution (%d days)", days)
+		photo.Caption = T(cs, "usage.pie_caption", days)
 		h.api.Send(photo)
 	}
+	if len(top) > 0 {
+		if img, err := h.analytics.MakeTopSymbolsChart(ctx, top, days); err == nil {
+			photo := tgbotapi.NewPhoto(chatID, tgbotapi.FileBytes{Name: "usage_symbols.png", Bytes: img})
+			photo.Caption = T(cs, "usage.symbols_caption", days)
+			h.api.Send(photo)
+		}
+	}
 
 	// Generate and send time series chart if we have time range
 	if days > 0 {
-		series, err := h.store.FetchUsageTimeSeries(chatID, since, calculateInterval(days))
+		interval := calculateInterval(days)
+		series, err := h.store.FetchUsageTimeSeries(chatID, since, interval)
 		if err == nil && len(series) > 0 {
-			timeChart, err := h.analytics.MakeUsageTimeSeriesChart(series, days)
+			prevSince := time.Unix(since, 0).AddDate(0, 0, -days).Unix()
+			prevSeries, _ := h.store.FetchUsageTimeSeriesRange(chatID, prevSince, since, interval)
+			timeChart, err := h.analytics.MakeUsageTimeSeriesChart(ctx, series, prevSeries, days, interval)
 			if err == nil {
 				photo := tgbotapi.NewPhoto(chatID, tgbotapi.FileBytes{
 					Name:  "usage_timeseries.png",
 					Bytes: timeChart,
 				})
-				photo.Caption = fmt.Sprintf("Command Usage Over Time (%d days)", days)
+				photo.Caption = T(cs, "usage.series_caption", days)
+				h.api.Send(photo)
+			}
+		}
+		if points, err := h.store.FetchDailyLatency(chatID, since); err == nil && len(points) > 1 {
+			if img, err := h.analytics.MakeLatencyChart(ctx, points, days); err == nil {
+				photo := tgbotapi.NewPhoto(chatID, tgbotapi.FileBytes{Name: "usage_latency.png", Bytes: img})
+				photo.Caption = T(cs, "usage.latency_caption", days)
 				h.api.Send(photo)
 			}
 		}
 	}
 }
 
+// usageTopSymbols is how many tickers the /usage bar chart shows.
+const usageTopSymbols = 10
+
 // calculateInterval determines the time interval for bucketing based on the number of days
 func calculateInterval(days int) int {
 	if days <= 1 {
@@ -525,3 +1300,51 @@ func calculateInterval(days int) int {
 func (h *Handlers) reply(chatID int64, text string) {
 	h.api.Send(tgbotapi.NewMessage(chatID, text))
 }
+
+// sendChart sends a rendered chart named name (without extension): PNGs as
+// photos, SVGs as documents since Telegram can't show SVG inline.
+func (h *Handlers) sendChart(chatID int64, name, caption string, img []byte, opts finance.RenderOptions) {
+	caption = demoCaption(caption)
+	if opts.SVG() {
+		doc := tgbotapi.NewDocument(chatID, tgbotapi.FileBytes{Name: name + ".svg", Bytes: img})
+		doc.Caption = caption
+		h.api.Send(doc)
+		return
+	}
+	photo := tgbotapi.NewPhoto(chatID, tgbotapi.FileBytes{Name: name + ".png", Bytes: img})
+	photo.Caption = caption
+	h.api.Send(photo)
+}
+
+// replyFailure reports a failed chart or data command as prefix followed by
+// the error, except that a full render queue gets a short "busy" note instead.
+// Failures the finance layer classifies are explained in a line with what to
+// try next; callers log the detailed error.
+func (h *Handlers) replyFailure(chatID int64, prefix string, err error) {
+	cs := h.chartSettings(chatID)
+	if errors.Is(err, finance.ErrRenderBusy) {
+		h.reply(chatID, T(cs, "fail.busy"))
+		return
+	}
+	h.reply(chatID, prefix+failureText(cs, err))
+}
+
+// failureText explains a finance failure to the user, or is the raw error
+// when it isn't one of the classified kinds. A multi-symbol failure lists
+// each symbol with its reason.
+func failureText(cs storage.ChatSettings, err error) string {
+	var multi *finance.SymbolsError
+	switch {
+	case errors.As(err, &multi):
+		return T(cs, "fail.symbols") + skippedNote(cs, multi.Skipped)
+	case errors.Is(err, finance.ErrRateLimited):
+		return T(cs, "fail.rate_limited")
+	case errors.Is(err, finance.ErrSymbolNotFound):
+		return T(cs, "fail.not_found")
+	case errors.Is(err, finance.ErrNoData):
+		return T(cs, "fail.no_data")
+	case errors.Is(err, finance.ErrTimeout), errors.Is(err, context.DeadlineExceeded):
+		return T(cs, "fail.timeout")
+	}
+	return err.Error()
+}
@@ -0,0 +1,32 @@
+package telegram
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"telegramBotTrade/internal/finance"
+	"telegramBotTrade/internal/logging"
+)
+
+// handleHeat sends the heat strip of syms over window.
+func (h *Handlers) handleHeat(ctx context.Context, chatID int64, syms []string, window string, opts finance.RenderOptions) {
+	ctx, cancel := context.WithTimeout(ctx, 60*time.Second)
+	defer cancel()
+	if window == "" {
+		window = "1d"
+	}
+	ctx, fresh := finance.WithFreshness(ctx)
+	cs := h.chartSettings(chatID)
+	res, err := finance.MakeHeatChart(ctx, syms, window, opts)
+	if err != nil {
+		logging.FromContext(ctx).Error("heat failed", "chat_id", chatID, "symbols", syms, "err", err)
+		h.replyFailure(chatID, T(cs, "heat.failed"), err)
+		return
+	}
+	skipped := res.Meta.Skipped
+	logSkipped(ctx, skipped)
+	caption := T(cs, "heat.caption", strings.Join(chartedSymbols(syms, skipped), ", "), strings.ToUpper(window)) + changesLine(res.Meta) + skippedNote(cs, skipped)
+	h.sendChart(chatID, strings.Join(syms, "_")+"_heat", freshCaption(cs, caption, fresh), res.Image, opts)
+	markDelivered(ctx)
+}
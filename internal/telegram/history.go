@@ -0,0 +1,178 @@
+package telegram
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"telegramBotTrade/internal/finance"
+	"telegramBotTrade/internal/logging"
+)
+
+const (
+	historyDefault = 10
+	historyMax     = 30
+)
+
+// historyCommands are the read-only chart and portfolio commands /history
+// lists and may re-run. Commands that change state (paper, brief) are left out.
+var historyCommands = map[string]bool{
+	"stock": true, "stocks": true, "stocks-index": true, "stockx": true, "stocksx": true,
+	"ew-port": true, "port": true, "portstats": true, "montecarlo": true, "movers": true,
+	"vix": true, "yield": true, "curve": true, "heat": true, "macd": true, "atr": true, "yoy": true, "ohlc": true, "export": true, "info": true, "optmove": true,
+}
+
+// symbolPatterns are the history commands whose first capture group holds
+// their ticker or ticker list; portfolio commands are parsed separately.
+var symbolPatterns = map[string]*regexp.Regexp{
+	"stock": reStock, "stocks": reStocks, "stocks-index": reStocksIndex, "stockx": reStockX,
+	"stocksx": reStocksX, "ew-port": reEWPort, "macd": reMACD, "atr": reATR, "yoy": reYoY,
+	"ohlc": reOHLC, "export": reExport, "info": reInfo, "optmove": reOptMove, "heat": reHeat,
+}
+
+// isPortFlag reports whether f is one of /port's trailing flags rather than
+// a holding or the window.
+func isPortFlag(f string) bool {
+	switch f {
+	case "detail", "hist", "normal", "svg":
+		return true
+	}
+	return false
+}
+
+// usageSymbols returns the distinct upper-cased tickers a history command
+// names, for the /usage top symbols; nil when it names none or doesn't parse.
+func usageSymbols(command, text string) []string {
+	var fields []string
+	switch command {
+	case "port", "portstats", "montecarlo":
+		var input []string
+		for _, f := range strings.Fields(commandArgs(text)) {
+			// montecarlo's key=value options and /port's trailing flags
+			if !strings.Contains(f, "=") && !isPortFlag(f) {
+				input = append(input, f)
+			}
+		}
+		syms, _, _, err := finance.ParseWeightedPortfolio(strings.Join(input, " "))
+		if err != nil {
+			return nil
+		}
+		fields = syms
+	default:
+		re := symbolPatterns[command]
+		if re == nil {
+			return nil
+		}
+		g := re.FindStringSubmatch(text)
+		if g == nil {
+			return nil
+		}
+		fields = strings.Fields(g[1])
+	}
+	var out []string
+	for _, f := range fields {
+		sym := strings.ToUpper(strings.TrimPrefix(f, "$"))
+		if reSymbol.MatchString(sym) && !slices.Contains(out, sym) {
+			out = append(out, sym)
+		}
+	}
+	return out
+}
+
+// reHistoryEntry matches one numbered line of a /history reply.
+var reHistoryEntry = regexp.MustCompile(`^(\d+)\. (/\S+.*)$`)
+
+type rerunKey struct{}
+
+// withRerun marks ctx as a /history re-run so the command text is not stored
+// again as chat history.
+func withRerun(ctx context.Context) context.Context {
+	return context.WithValue(ctx, rerunKey{}, true)
+}
+
+func isRerun(ctx context.Context) bool {
+	v, _ := ctx.Value(rerunKey{}).(bool)
+	return v
+}
+
+// handleHistory lists the chat's last n chart and portfolio commands.
+func (h *Handlers) handleHistory(chatID int64, arg string) {
+	cs := h.chartSettings(chatID)
+	n := historyDefault
+	if arg != "" {
+		v, err := strconv.Atoi(arg)
+		if err != nil || v < 1 || v > historyMax {
+			h.reply(chatID, T(cs, "history.usage", historyMax))
+			return
+		}
+		n = v
+	}
+	cmds := make([]string, 0, len(historyCommands))
+	for c := range historyCommands {
+		cmds = append(cmds, c)
+	}
+	slices.Sort(cmds)
+	list, err := h.store.FetchRecentCommands(chatID, cmds, n)
+	if err != nil {
+		h.reply(chatID, T(cs, "history.load_failed")+err.Error())
+		return
+	}
+	if len(list) == 0 {
+		h.reply(chatID, T(cs, "history.none"))
+		return
+	}
+	var b strings.Builder
+	b.WriteString(T(cs, "history.header") + "\n\n")
+	for i, u := range list {
+		fmt.Fprintf(&b, "%d. %s\n", i+1, strings.TrimSpace("/"+u.Command+" "+u.Args))
+	}
+	h.reply(chatID, b.String())
+}
+
+// historyRerun handles a numeric reply to one of the bot's /history messages by
+// running the chosen command again. It reports whether m was such a reply.
+func (h *Handlers) historyRerun(ctx context.Context, m *tgbotapi.Message) bool {
+	r := m.ReplyToMessage
+	if r == nil || r.From == nil || r.From.ID != h.api.Self.ID || !isHistoryList(r.Text) {
+		return false
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(m.Text))
+	if err != nil {
+		return false
+	}
+	for _, line := range strings.Split(r.Text, "\n") {
+		g := reHistoryEntry.FindStringSubmatch(strings.TrimSpace(line))
+		if g == nil || g[1] != strconv.Itoa(n) {
+			continue
+		}
+		// Only re-run commands /history would list, whatever the message says.
+		cmd := strings.TrimPrefix(strings.Fields(g[2])[0], "/")
+		if !historyCommands[cmd] {
+			return false
+		}
+		logging.FromContext(ctx).Info("history: re-run", "chat_id", m.Chat.ID, "command", g[2])
+		rerun := *m
+		rerun.ReplyToMessage = nil
+		rerun.Text = g[2]
+		h.HandleMessage(withRerun(ctx), &rerun)
+		return true
+	}
+	h.reply(m.Chat.ID, h.t(m.Chat.ID, "history.no_entry", n))
+	return true
+}
+
+// isHistoryList reports whether text is a /history list in any language, so a
+// reply to one still works after the chat switches language.
+func isHistoryList(text string) bool {
+	for _, catalog := range catalogs {
+		if header := catalog["history.header"]; header != "" && strings.HasPrefix(text, header) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,73 @@
+package telegram
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"telegramBotTrade/internal/storage"
+)
+
+// Languages the bot's own messages come in. A chat picks one with /set lang,
+// which also sets the default /translate target.
+const (
+	langEnglish = "en"
+	langChinese = "zh"
+)
+
+// catalogs maps each language to its messages by key. English is complete;
+// a key missing from another language falls back to it.
+var catalogs = map[string]map[string]string{
+	langEnglish: catalogEN,
+	langChinese: catalogZH,
+}
+
+// chatLang is the language of the bot's messages in a chat whose /set lang is
+// lang: Chinese for any name or code of it ("Chinese", "zh-CN", "中文"),
+// English otherwise, since there is no other catalog yet.
+func chatLang(lang string) string {
+	l := strings.ToLower(strings.TrimSpace(lang))
+	switch {
+	case l == "zh", strings.HasPrefix(l, "zh-"), strings.HasPrefix(l, "zh_"),
+		strings.Contains(l, "chinese"), strings.Contains(l, "mandarin"),
+		strings.Contains(l, "中文"), strings.Contains(l, "汉语"), strings.Contains(l, "漢語"), strings.Contains(l, "华语"), strings.Contains(l, "華語"):
+		return langChinese
+	}
+	return langEnglish
+}
+
+// T returns the message key in the chat's language, formatted with args as
+// by fmt.Sprintf when there are any. A key missing from the language falls
+// back to English, and one missing from English too is returned as is.
+func T(cs storage.ChatSettings, key string, args ...any) string {
+	text, ok := catalogs[chatLang(cs.TranslateLang)][key]
+	if !ok {
+		if text, ok = catalogEN[key]; !ok {
+			text = key
+		}
+	}
+	if len(args) == 0 {
+		return text
+	}
+	return fmt.Sprintf(text, args...)
+}
+
+// t is T for the settings of chatID.
+func (h *Handlers) t(chatID int64, key string, args ...any) string {
+	return T(h.chartSettings(chatID), key, args...)
+}
+
+// missingMessages lists, per language, the English keys its catalog lacks.
+// NewBot logs them, since those messages fall back to English.
+func missingMessages() map[string][]string {
+	out := map[string][]string{}
+	for lang, catalog := range catalogs {
+		for key := range catalogEN {
+			if _, ok := catalog[key]; !ok {
+				out[lang] = append(out[lang], key)
+			}
+		}
+		sort.Strings(out[lang])
+	}
+	return out
+}
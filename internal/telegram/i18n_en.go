@@ -0,0 +1,422 @@
+package telegram
+
+// catalogEN is the English text of every message, by key. Keys are grouped
+// by the file that sends them.
+var catalogEN = map[string]string{
+	"help": helpText,
+	// captions.go, freshness.go, symbols.go
+	"caption.last":         "Last %.2f (%+.2f%%)",
+	"caption.stats":        "Return %+.2f%% • Sharpe %.2f • Vol %.2f%% • MaxDD %.2f%% • %d days",
+	"caption.wiped_out":    "💥 Portfolio wiped out on %s; the backtest stops there",
+	"caption.truncated":    "⚠️ Window truncated to %s, limited by %s",
+	"caption.as_of":        "data as of %s ET (%s ago)",
+	"caption.missing_bars": "⚠️ %d%% of %s bars missing from source",
+	"caption.stale":        "⚠️ Stale data: the last bar is %s old",
+	"caption.skipped":      "skipped: %s",
+	"symbols.invalid":      "Not valid symbols: %s. Check the interval/window after the symbols (lowercase, e.g. %s)",
+	"symbols.too_few":      "Please provide at least %d different symbols (got %d), e.g. %s",
+	"symbols.too_many":     "Too many symbols: at most %d per chart (got %d). Split them across several commands.",
+	// handlers.go
+	"summary.no_channel":       "No source channel configured. Use /set source_channel @channel first.",
+	"summary.storage_off":      "Message storage is disabled for this chat (/set store_messages off), so there is nothing to summarize. Use /set store_messages on to start storing messages again.",
+	"summary.channel_progress": "Summarizing the linked channel's last %dh…",
+	"summary.topic_progress":   "Summarizing this topic's last %dh… (/summary all %d covers every topic)",
+	"summary.progress":         "Summarizing last %dh…",
+	"chart.anchor_window":      "An anchored chart shows the latest session; leave out the window, e.g. %s",
+	"chart.anchor_interval":    "An anchored chart needs an intraday interval (1m, 5m, 15m or 1h), e.g. %s",
+	"chart.failed":             "Chart failed: ",
+	"indexed.failed":           "Indexed plot failed: ",
+	"indexed.caption":          "Indexed: ",
+	"multi.failed":             "Multi chart failed: ",
+	"multi.caption":            "Multi: ",
+	"port.invalid":             "Invalid portfolio format: %v\n\nUsage: %s",
+	"port.no_symbols":          "Please provide at least one symbol with weight, e.g. %s",
+	"port.bad_hist":            "Invalid histogram bin width %q: use hist, hist=fixed (%g%%) or hist=W with W from 0.01 to 10 percent",
+	"recommend.empty":          "Please provide your investment thesis or market view after /recommend",
+	"recommend.progress":       "🤖 Analyzing your request and generating trading recommendations...",
+	"usage.progress":           "📊 Generating usage analytics...",
+	"montecarlo.progress":      "🎲 Running Monte Carlo simulation...",
+	"summary.failed":           "Summary failed: ",
+	"summary.no_messages":      "No messages found in the selected time window.",
+	"summary.cached":           "_(cached)_",
+	"summary.condensed":        "_%d of %d messages were repeats or emoji only and were condensed._",
+	"stock.failed":             "Couldn’t fetch %s: ",
+	"ewport.failed":            "Portfolio failed: ",
+	"ewport.caption":           "Equal Weighted Portfolio: ",
+	"port.failed":              "Weighted portfolio failed: ",
+	"port.caption":             "Weighted Portfolio: ",
+	"port.hist_failed":         "Daily returns histogram failed: ",
+	"port.hist_caption":        "Daily returns: ",
+	"port.contributions":       "Contributions (more than %d assets to draw)",
+	"port.contribution":        "%s %.1f%%: %+.1f%% → %+.2f pts",
+	"port.contributions_total": "Total from assets: %+.2f pts",
+	"help.limits":              "Interval limits (data source)",
+	"help.limit":               "%s bars: up to %s",
+	"help.limits_note":         "A longer window is shortened to the limit and the chart caption says so, e.g. /stockx TSLA 1m 1y shows 30 days.",
+	"recommend.failed":         "Failed to generate recommendation: ",
+	"usage.failed":             "Failed to fetch usage statistics: ",
+	"usage.none_days":          "No command usage found in the last %d days.",
+	"usage.none":               "No command usage found.",
+	"usage.pie_caption":        "Command Usage Distribution (%d days)",
+	"usage.symbols_caption":    "Most Charted Symbols (%d days)",
+	"usage.series_caption":     "Command Usage Over Time (%d days)",
+	"usage.latency_caption":    "Daily Median Latency (%d days)",
+	"fail.busy":                "The bot is busy rendering other charts, try again shortly.",
+	"fail.symbols":             "couldn’t fetch enough of the symbols",
+	"fail.rate_limited":        "the data source is rate limiting the bot. Try again in a minute.",
+	"fail.not_found":           "symbol not found. Check the ticker as Yahoo Finance lists it, e.g. BRK-B or ^GSPC.",
+	"fail.no_data":             "not enough data for that. Try a longer window or a coarser interval.",
+	"fail.timeout":             "the data source took too long to answer. Try again shortly.",
+	// cmdhelp.go
+	"help.examples":     "Examples:",
+	"help.no_extra":     "%s has no extra help; see /help.",
+	"help.did_you_mean": "Unknown command %s. Did you mean %s? Try /help %s.",
+	"help.unknown":      "Unknown command %s. /help lists them all.",
+	// activity.go
+	"activity.storage_off":  "Message storage is disabled for this chat (/set store_messages off), so there is no activity to chart.",
+	"activity.failed":       "Failed to fetch chat activity: ",
+	"activity.none":         "No stored messages in the last %d days.",
+	"activity.chart_failed": "Activity chart failed: ",
+	"activity.caption":      "Chat activity: %d messages in %d days",
+	"activity.most_active":  "Most active: %s",
+	// actions.go
+	"actions.usage":          "Usage:\n/actions [hours] - Find the action items of the last hours (default 24, max 168) and add them to the checklist\n/actions open - List the outstanding items\n/actions done N - Check off item N",
+	"actions.update_failed":  "Failed to update action item: ",
+	"actions.not_open":       "No open action item %d in this chat. /actions open lists them.",
+	"actions.done":           "✅ Item %d done.",
+	"actions.storage_off":    "Message storage is disabled for this chat (/set store_messages off), so there are no messages to find action items in.",
+	"actions.history_failed": "Failed to load chat history: ",
+	"actions.extract_failed": "Failed to find action items: ",
+	"actions.none":           "No action items in the last %dh.",
+	"actions.none_new":       "No new action items in the last %dh; /actions open lists the %d outstanding.",
+	"actions.save_failed":    "Failed to save action items: ",
+	"actions.found":          "<b>Action items, last %dh</b>",
+	"actions.check_off":      "Check one off with /actions done N.",
+	"actions.load_failed":    "Failed to load action items: ",
+	"actions.none_open":      "No open action items. /actions [hours] finds new ones in the chat.",
+	"actions.open":           "<b>Open action items (%d)</b>",
+	"actions.due":            "(due %s)",
+	// /again
+	"again.usage":       "Usage: /again [overrides] - Run this chat's last chart or portfolio command again, e.g. /again 1w, /again 1h 6m, /again dark\nA theme name sets the theme. An interval (1m, 5m, 15m, 1h, 1d) sets the interval of commands that have one, the first time one is given; any other word is the window. window=, interval= and theme= say which outright, e.g. /again window=1d.",
+	"again.load_failed": "Failed to load the last command: ",
+	"again.none":        "No chart yet to run again.",
+	"again.failed":      "Can't run %s again: %v",
+	// /alias
+	"alias.usage":         "Usage:\n/alias add NAME \"/command args\" - e.g. /alias add g \"/stockx GLD 1h 6m\", then /g\n/alias remove NAME\n/alias list",
+	"alias.remove_failed": "Failed to remove alias: ",
+	"alias.unknown":       "No alias /%s in this chat.",
+	"alias.removed":       "Alias /%s removed.",
+	"alias.bad_name":      "Alias names are 1-16 lowercase letters, digits or _, starting with a letter.",
+	"alias.taken":         "/%s is already a bot command.",
+	"alias.bad_target":    "An alias must expand into a bot command such as /stockx, not %s.",
+	"alias.recursive":     "An alias can't expand into /alias.",
+	"alias.load_failed":   "Failed to load aliases: ",
+	"alias.too_many":      "This chat already has %d aliases (the maximum). Remove one with /alias remove NAME.",
+	"alias.save_failed":   "Failed to save alias: ",
+	"alias.title":         "Aliases",
+	"alias.none":          "No custom aliases.",
+	"alias.added":         "Alias /%s → %s",
+	// /atr
+	"atr.bad_period": "ATR period must be between 2 and %d.",
+	"atr.failed":     "ATR failed: ",
+	"atr.caption":    "%s ATR(%d): $%.2f (%.2f%% of $%.2f)\nStops at %g×ATR: long %.2f • short %.2f",
+	// /heat
+	"heat.failed":  "Heat strip failed: ",
+	"heat.caption": "Heat: %s • %s",
+	// /macd
+	"macd.failed":          "MACD failed: ",
+	"macd.above":           "above",
+	"macd.below":           "below",
+	"macd.caption":         "%s MACD(12,26,9) • %s • %s\nMACD %.3f is %s signal %.3f (hist %+.3f)\n%d bullish, %d bearish crosses in range",
+	"montecarlo.usage":     "Usage: /montecarlo SPY 0.7 TLT 0.3 10y [horizon=20y] [sims=2000] [seed=42]\nThe window (10y) is the backtest used to estimate returns; horizon is how far to project (default 10y).",
+	"montecarlo.invalid":   "Invalid %s: %v",
+	"portfolio.bad_format": "Invalid portfolio format: %v",
+	"montecarlo.failed":    "Monte Carlo failed: ",
+	"montecarlo.caption":   "Monte Carlo • %s horizon • %d sims (backtest %s)\nMedian terminal value: %.1f (start 100)\nProbability of loss: %.1f%%\nDaily μ %.3f%% • σ %.2f%%",
+	// Cashtags
+	"cashtags.caption": "%s today (5m)",
+	// /vix
+	"vix.failed":       "VIX chart failed: ",
+	"vix.contango":     "contango (VIX below VIX3M)",
+	"vix.inverted":     "⚠️ inverted (VIX above VIX3M)",
+	"vix.caption":      "VIX %.2f • %.0fth percentile of the past year\nVIX9D %.2f • VIX3M %.2f\nTerm structure: %s",
+	"port.short":       "%s %.1f%% SHORT",
+	"port.cash":        "Cash %.1f%%",
+	"port.margin":      "Margin %.1f%%",
+	"portstats.failed": "Portfolio stats failed: ",
+	// /yield and /curve
+	"yield.failed":   "Yield chart failed: ",
+	"yield.inverted": "⚠️ Inverted: ",
+	"curve.failed":   "Curve chart failed: ",
+	"curve.spread":   "10Y−13W spread: %+.2f pp",
+	// /yoy
+	"yoy.usage":   "Usage: /yoy SYMBOL [years], with 1 to %d previous years (default %d)",
+	"yoy.failed":  "YoY chart failed: ",
+	"yoy.caption": "%s year-to-date vs the previous %d years, indexed to 100 at each January start (x-axis: trading day of the year; current year listed first)",
+	// /optmove
+	"optmove.no_options": "%s has no listed options.",
+	"optmove.failed":     "Couldn’t price the %s straddle: ",
+	"optmove.today":      "expires today",
+	"optmove.one_day":    "1 day left",
+	"optmove.days":       "%d days left",
+	"optmove.reply":      "%s implied move by %s (%s): ±$%.2f (±%.1f%%)\nATM %.2f straddle = call %.2f + put %.2f, underlying %.2f",
+	// /info
+	"info.failed": "Couldn’t fetch fundamentals for %s: ",
+	"info.none":   "No fundamentals reported for this symbol.",
+	// /futures
+	"futures.failed": "Futures failed: no quotes could be fetched.",
+	"futures.title":  "<b>Futures</b> (continuous front month)",
+	"quote.missing":  "No quote for: ",
+	"futures.hint":   "Use a name in any symbol command, e.g. /stock oil or /stocksx es nq 1h 1m.",
+	"fetch.failed":   "Couldn’t fetch %s: ",
+	// /paper
+	"paper.usage":        "Usage:\n/paper buy SYMBOL QTY\n/paper sell SYMBOL QTY\n/paper positions\n/paper pnl - P&L and equity curve",
+	"paper.bad_qty":      "Quantity must be a positive number.",
+	"paper.load_failed":  "Failed to load paper book: ",
+	"paper.oversold":     "Can't sell %g %s: the book holds %g (short selling isn't supported).",
+	"paper.bought":       "Bought",
+	"paper.sold":         "Sold",
+	"paper.save_failed":  "Failed to record trade: ",
+	"paper.filled":       "📝 %s %g %s @ %.2f (%.2f total).",
+	"paper.positions":    "<b>Paper positions</b>",
+	"paper.no_positions": "no open positions",
+	"paper.none":         "No paper trades yet.",
+	"paper.pnl":          "Paper P&L\nRealized: %+.2f\nUnrealized: %+.2f\nEquity: %.2f (%+.2f%% vs %.0f start)",
+	"paper.at_cost":      "%d position(s) without a live quote are marked at cost.",
+	"paper.no_curve":     "Equity curve unavailable: ",
+	// /portbuilder
+	"builder.empty_watchlist": "The watchlist is empty. Add symbols with /watch add SPY TLT, or name them: /portbuilder SPY TLT GLD",
+	"builder.expired":         "This builder has expired or belongs to someone else. Start your own with /portbuilder.",
+	"builder.cancelled":       "Portfolio builder cancelled.",
+	"builder.running":         "Running %s…",
+	"builder.too_many":        "At most %d symbols per portfolio.",
+	"builder.pick_one":        "Pick at least one symbol first.",
+	"builder.no_weight":       "Give at least one symbol a weight.",
+	"builder.over":            "The weights add up to %d%%; bring them to 100%% or less.",
+	"builder.cancel":          "Cancel",
+	"builder.step_pick":       "Portfolio builder 1/3: pick up to %d symbols from the watchlist, or cancel and name them: /portbuilder SPY TLT GLD",
+	"builder.weights":         "Weights ➡️",
+	"builder.step_weights":    "Portfolio builder 2/3: set the weights in %d%% steps.",
+	"builder.window":          "Window ➡️",
+	"builder.back":            "⬅️ Back",
+	"builder.step_window":     "Portfolio builder 3/3: choose the backtest window.",
+	"builder.confirm":         "Run %s?",
+	"builder.run":             "▶️ Run",
+	"builder.total_over":      "Total: %d%% ⚠️ over 100%%",
+	"builder.total_full":      "Total: 100%",
+	"builder.total":           "Total: %d%% (%d%% cash)",
+	// /recap
+	"recap.storage_off": "Message storage is disabled for this chat (/set store_messages off), so there is nothing to recap.",
+	"recap.none":        "No tickers mentioned in the last %dd (%d messages).",
+	"recap.title":       "Most discussed, last %dd (%d messages)",
+	"recap.mention":     "%s - %d mention, %s",
+	"recap.mentions":    "%s - %d mentions, %s",
+	"recap.failed":      "Recap chart failed: ",
+	"recap.caption":     "Most discussed: %s, indexed to 100 (%s, %s)",
+	// /ask
+	"ask.usage":       "Usage: /ask [Nh] QUESTION, e.g. /ask what ticker did Bob pitch yesterday? Looks at the last 24 hours by default (up to 168h) plus older messages that match the question's words.",
+	"ask.storage_off": "Message storage is disabled for this chat (/set store_messages off), so there is no history to search.",
+	"ask.none":        "No stored messages to search in the last %dh.",
+	"ask.failed":      "Failed to answer: ",
+	// /brief
+	"brief.usage":         "Usage: /brief on HH:MM | /brief off | /brief now",
+	"brief.update_failed": "Failed to update brief: ",
+	"brief.none":          "No morning brief is scheduled.",
+	"brief.off":           "Morning brief turned off.",
+	"brief.save_failed":   "Failed to save brief: ",
+	"brief.on":            "Morning brief scheduled every weekday at %02d:%s (%s), except US market holidays. Add symbols with /watch add.",
+	"brief.holiday":       "No morning brief today: US markets are closed for %s.",
+	"brief.failed":        "Morning brief unavailable: no quotes could be fetched.",
+	"brief.market":        "<b>Market</b>",
+	"brief.watchlist":     "<b>Watchlist</b>",
+	"brief.title":         "<b>Morning brief</b>",
+	"brief.early_close":   "US markets close early today, at 13:00 ET.",
+	// /calendar
+	"calendar.usage":     "Usage: /calendar [week]",
+	"calendar.week_over": "No trading days left this week. The calendar source only covers the current week; try /calendar week.",
+	"calendar.failed":    "Economic calendar unavailable: ",
+	"calendar.title":     "<b>US economic calendar</b> • high impact • %s",
+	"calendar.forecast":  "fcst ",
+	"calendar.previous":  "prev ",
+	"calendar.nothing":   "Nothing scheduled",
+	// /export
+	"export.too_long":    "%s %s over %s has %d rows, more than the %d allowed. Use a coarser interval, e.g. /export %s 1h %s",
+	"export.failed":      "Export failed: ",
+	"export.caption":     "%s • %s • %s • %d rows (timestamps in exchange time)",
+	"export.closes_only": "Only closes were available for this series.",
+	// /ohlc
+	"ohlc.usage":    "Usage: /ohlc SYMBOL [n], with n from 1 to %d (default %d)",
+	"ohlc.too_many": "/ohlc shows at most %d rows. For a longer history, download it as CSV: /export %s 1d 6m",
+	"ohlc.no_data":  "No open/high/low data is available for %s right now.",
+	"ohlc.title":    "<b>%s</b> • last %d daily bars",
+	// /feedback
+	"feedback.usage":         "Usage: /feedback your message\nAdmin chat: /feedback list [n] | /feedback done N",
+	"feedback.too_long":      "Feedback is too long (max %d characters). Please shorten it.",
+	"feedback.failed":        "Sorry, your feedback could not be saved.",
+	"feedback.saved":         "Thanks! Your feedback was recorded as #%d.",
+	"feedback.forward":       "📬 Feedback #%d from %s in chat %d (%s):\n\n%s",
+	"feedback.update_failed": "Failed to update feedback: ",
+	"feedback.not_found":     "Feedback #%d not found.",
+	"feedback.done":          "Feedback #%d marked done.",
+	"feedback.load_failed":   "Failed to load feedback: ",
+	"feedback.none":          "No open feedback.",
+	"feedback.list_header":   "Open feedback (newest first)\n",
+	// /history
+	"history.header":      "Recent chart commands (reply with a number to run it again):",
+	"history.usage":       "Usage: /history [n], with n from 1 to %d",
+	"history.load_failed": "Failed to load history: ",
+	"history.none":        "No chart commands yet.",
+	"history.no_entry":    "No entry %d in that history list.",
+	// /movers
+	"movers.usage":           "Usage: /movers [threshold%], e.g. /movers 3",
+	"movers.empty_watchlist": "Watchlist is empty. Add symbols with /watch add SPY AAPL ...",
+	"movers.failed":          "Movers failed: no quotes could be fetched.",
+	"movers.none":            "No watchlist symbol is moving more than %g%% today.",
+	"movers.title":           "<b>Movers ≥ %g%%</b>",
+	"movers.alert":           "<b>Movers alert</b> (±%g%%)",
+	"movers.auto_usage":      "Usage: /set movers_auto 3|off",
+	"movers.auto_off":        "Automatic movers alerts turned off.",
+	"movers.auto_on":         "Watchlist symbols crossing ±%g%% will be announced once per day.",
+	"settings.save_failed":   "Failed to save setting: ",
+	// AI budget
+	"budget.exceeded": "This chat has used its %d AI requests for today. The budget resets at midnight (%s).",
+	// /chart
+	"nlchart.no_symbol":   "I couldn't tell which symbol you want. Try e.g. \"@bot apple over the last six months\" or /stockx AAPL 1d 6m.",
+	"nlchart.unsupported": "I understood %s but that isn't a chart I can draw. Check /help for the supported intervals and windows.",
+	"nlchart.usage":       "Tell me what to chart, e.g. /chart apple over the last six months",
+	"nlchart.failed":      "Sorry, I couldn't work out that chart request: ",
+	"nlchart.send_it":     "Did you mean %s? Send it as a command to draw it.",
+	"nlchart.confirm":     "Did you mean %s?",
+	"nlchart.yes":         "Yes, draw it",
+	"nlchart.no":          "No",
+	"nlchart.cancelled":   "OK, never mind.",
+	"nlchart.drawing":     "Drawing %s…",
+	// Auto-pin
+	"pin.no_right": "I couldn't pin the morning brief: I need to be an admin with the \"Pin messages\" right in this chat. Auto-pin is now off; after granting the right, turn it back on with /set auto_pin on.",
+	"pin.usage":    "Usage: /set auto_pin on|off",
+	"pin.on":       "Auto-pin enabled. The next scheduled morning brief will be pinned silently (the bot needs the \"Pin messages\" admin right).",
+	"pin.off":      "Auto-pin disabled.",
+	// /port watch
+	"portwatch.usage":         "Usage:\n/port watch NAME S1 W1 S2 W2 ... [dd=N] - Alert when the portfolio falls N% (default 10) below its peak, valued at each US close\n/port watch NAME dd=N - Change the threshold of a watch\n/port watch list\n/port unwatch NAME",
+	"portwatch.delete_failed": "Failed to delete portfolio watch: ",
+	"portwatch.unknown":       "No portfolio watch named %q in this chat.",
+	"portwatch.removed":       "Stopped watching %s.",
+	"portwatch.bad_name":      "Portfolio names are up to 32 letters, digits, - or _.",
+	"portwatch.bad_dd":        "The drawdown threshold must be between 1 and 90 (dd=10 alerts 10% below the peak).",
+	"portwatch.update_failed": "Failed to update portfolio watch: ",
+	"portwatch.not_yet":       "No portfolio watch named %s yet. Give its holdings: /port watch %s SPY 0.6 TLT 0.4 dd=%g",
+	"portwatch.threshold":     "%s now alerts at a %g%% drawdown.",
+	"portwatch.load_failed":   "Failed to load portfolio watches: ",
+	"portwatch.too_many":      "This chat already watches %d portfolios; remove one with /port unwatch NAME.",
+	"portwatch.no_price":      "Couldn’t fetch %s; a watch needs a price for every holding.",
+	"portwatch.save_failed":   "Failed to save portfolio watch: ",
+	"portwatch.saved":         "Watching %s: %s. Valued at 100 from current prices; alerts once per breach when it falls %g%% below its peak, checked after each US close.",
+	"portwatch.replaced":      "Replaced and watching %s: %s. Valued at 100 from current prices; alerts once per breach when it falls %g%% below its peak, checked after each US close.",
+	"portwatch.none":          "No portfolio watches.",
+	"portwatch.title":         "Portfolio watches",
+	"portwatch.unvalued":      "not valued yet",
+	"portwatch.valued":        "%.1f, peak %.1f (%.1f%% drawdown) at the %s close",
+	"portwatch.alert":         "📉 Portfolio %s is %.1f%% below its peak (%.1f vs %.1f at the %s close), past its %g%% threshold.\n%s",
+	// /schedule
+	"schedule.usage":         "Usage:\n/schedule HH:MM /command args - Post a command every weekday at a local time, e.g. /schedule 09:25 /stock SPY 1d\n/schedule list\n/schedule delete N",
+	"schedule.delete_failed": "Failed to delete schedule: ",
+	"schedule.unknown":       "Schedule #%d not found in this chat.",
+	"schedule.deleted":       "Schedule #%d deleted.",
+	"schedule.not_allowed":   "%s can't be scheduled; only chart and quote commands such as /stock, /port or /macd can.",
+	"schedule.load_failed":   "Failed to load schedules: ",
+	"schedule.too_many":      "This chat already has %d schedules (the maximum). Delete one with /schedule delete N.",
+	"schedule.save_failed":   "Failed to save schedule: ",
+	"schedule.added":         "Schedule #%d: %s every weekday at %02d:%s (%s).",
+	"schedule.none":          "No schedules.",
+	"schedule.title":         "Schedules (%s)",
+	// /recommend scoreboard
+	"scoreboard.usage":       "Usage: /recommend scoreboard [Nd], with N from 1 to %d",
+	"scoreboard.load_failed": "Failed to load recommendations: ",
+	"scoreboard.none":        "No recommendations in the last %dd. /recommend TEXT records the positions it suggests.",
+	"scoreboard.title":       "Recommendation scoreboard, last %dd (%d positions)",
+	"scoreboard.unscored":    "No prices available to score them yet.",
+	"scoreboard.stats":       "Hit rate: %.0f%% (%d/%d) • Average return: %+.1f%%",
+	"scoreboard.more":        "… and %d more",
+	"scoreboard.long":        "LONG",
+	"scoreboard.short":       "SHORT",
+	// /summaries
+	"summaries.usage":       "Usage: /summaries [n] lists the last n summaries (default 5, max 20); /summaries show K re-sends summary #K.",
+	"summaries.load_failed": "Failed to load summaries: ",
+	"summaries.none":        "No stored summaries yet. Run /summary to create one.",
+	"summaries.title":       "Recent summaries (%s)",
+	"summaries.show_hint":   "/summaries show K re-sends the full text.",
+	"summaries.show_failed": "Failed to load summary: ",
+	"summaries.unknown":     "Summary #%d not found in this chat.",
+	"summaries.header":      "Summary #%d • %s–%s",
+	// /target
+	"target.usage":         "Usage:\n/target SYMBOL PRICE [note:\"text\"] - Notify you once when SYMBOL crosses PRICE (setting it again edits it)\n/target list\n/target delete N",
+	"target.delete_failed": "Failed to delete target: ",
+	"target.unknown":       "Target #%d not found in this chat.",
+	"target.deleted":       "Target #%d deleted.",
+	"target.already":       "%s is already at %.2f.",
+	"target.save_failed":   "Failed to save target: ",
+	"target.set":           "Target #%d set: %s %s %.2f (now %.2f, %+.2f%% away). Expires in %d days.",
+	"target.updated":       "Target #%d updated: %s %s %.2f (now %.2f, %+.2f%% away). Expires in %d days.",
+	"target.load_failed":   "Failed to load targets: ",
+	"target.none":          "No open targets.",
+	"target.title":         "Open targets",
+	"target.now":           "now %.2f (%+.2f%% away)",
+	"target.no_quote":      "no quote",
+	"target.days_left":     "%dd left",
+	"target.hit":           "🎯 %s %s hit %.2f (now %.2f).",
+	"target.note":          "Note: ",
+	// /set
+	"set.usage":           "Usage:\n/set window 1d|1w|5d|1m|3m|6m|1y|2y|5y|10y|30y|off\n/set interval 1m|5m|15m|1h|1d|off\n/set theme light|dark|grafana|ant\n/set tz Area/City|off (e.g. Asia/Singapore)\n/set source_channel @channel|ID|off\n/set movers_auto 3|off\n/set store_messages on|off\n/set auto_pin on|off\n/set lang LANGUAGE|off (language of the bot's replies, English or Chinese, and the default /translate target)\n/set cashtags quote|chart|off (auto-reply to $TICKER mentions)\n/set show",
+	"set.window":          "window",
+	"set.interval":        "interval",
+	"set.theme":           "theme",
+	"set.cashtags":        "cashtag reply",
+	"set.invalid":         "Invalid %s %q. Choose one of: %s",
+	"set.reset":           "Default %s reset.",
+	"set.done":            "Default %s set to %s.",
+	"set.default":         "(default)",
+	"set.title":           "Chat settings",
+	"set.per_command":     "per command",
+	"set.none":            "none",
+	"store.usage":         "Usage: /set store_messages on|off",
+	"store.on":            "Message storage enabled. /summary covers messages from now on.",
+	"store.purge_failed":  "Message storage disabled, but deleting stored messages failed: ",
+	"store.off":           "Message storage disabled and %d stored messages deleted. Commands keep working; /summary is unavailable.",
+	"tz.unknown":          "Unknown time zone %q. Use an IANA name such as: %s",
+	"tz.reset":            "Time zone reset to America/New_York.",
+	"tz.set":              "Time zone set to %s.",
+	"channel.cleared":     "Source channel cleared.",
+	"channel.not_found":   "Couldn’t find channel %s. Make sure the bot is a member of it.",
+	"channel.not_channel": "%s is not a channel.",
+	"channel.set":         "Source channel set to %s. Use /summary channel [hours] to summarize its posts.",
+	// /translate and /set lang
+	"translate.usage":     "Reply to a message with /translate [language], e.g. /translate or /translate Chinese. The default language is set with /set lang.",
+	"translate.no_text":   "That message has no text to translate.",
+	"translate.unknown":   "Unknown language %s. ",
+	"translate.failed":    "Failed to translate: ",
+	"translate.truncated": "(only the first %d characters were translated)",
+	"lang.usage":          "Usage: /set lang LANGUAGE|off, e.g. /set lang Chinese. It sets both the /translate default and the language of the bot’s replies (English or Chinese).",
+	"lang.reset":          "Translation language reset to %s.",
+	"lang.set":            "/translate now defaults to %s.",
+	// /watch
+	"watch.usage":         "Usage: /watch add S1 S2 ... | /watch remove S1 ... | /watch",
+	"watch.load_failed":   "Failed to load watchlist: ",
+	"watch.empty":         "Watchlist is empty. ",
+	"watch.list":          "Watchlist: ",
+	"watch.bad_symbol":    "Invalid symbol %q.",
+	"watch.too_many":      "Watchlists are limited to %d symbols.",
+	"watch.update_failed": "Failed to update watchlist: ",
+	"watch.added":         "Added to watchlist: ",
+	"watch.removed":       "Removed %d symbol(s) from the watchlist.",
+
+	// Admin commands: /version, /status, /broadcast, /report
+	"admin.only":            "This command is only available in the admin chat.",
+	"broadcast.usage":       "Usage: /broadcast TEXT",
+	"broadcast.list_failed": "Failed to list chats: ",
+	"broadcast.started":     "Broadcasting to %d chats…",
+	"broadcast.done":        "Broadcast done: %d sent, %d skipped (blocked/kicked), %d failed, %d not attempted.",
+	"report.failed":         "Usage report failed: ",
+
+	// Handler panics
+	"error.panic": "Sorry, something went wrong while handling that command. The error has been logged.",
+}
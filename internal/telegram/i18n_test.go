@@ -0,0 +1,65 @@
+package telegram
+
+import (
+	"maps"
+	"regexp"
+	"strconv"
+	"testing"
+)
+
+func TestCatalogsHaveEveryKey(t *testing.T) {
+	for lang, catalog := range catalogs {
+		for _, other := range catalogs {
+			for key := range other {
+				if _, ok := catalog[key]; !ok {
+					t.Errorf("%s catalog is missing %q", lang, key)
+				}
+			}
+		}
+	}
+	if missing := missingMessages(); len(missing) != 0 {
+		t.Errorf("missingMessages() = %v, want none", missing)
+	}
+}
+
+// formatVerb matches a fmt verb with its flags, width, precision and an
+// explicit argument index, which the Chinese catalog uses to reorder.
+var formatVerb = regexp.MustCompile(`%[-+# 0]*(?:\[(\d+)\])?\d*(?:\.\d+)?(?:\[(\d+)\])?([a-zA-Z%])`)
+
+// formatArgs maps each argument a format consumes, by number, to its verb.
+func formatArgs(format string) map[int]string {
+	args := map[int]string{}
+	next := 1
+	for _, m := range formatVerb.FindAllStringSubmatch(format, -1) {
+		if m[3] == "%" {
+			continue
+		}
+		if idx := m[1] + m[2]; idx != "" {
+			next, _ = strconv.Atoi(idx)
+		}
+		args[next] = m[3]
+		next++
+	}
+	return args
+}
+
+// literalPercent lists messages shown without arguments whose text has a bare
+// percent sign, which would otherwise read as a verb.
+var literalPercent = map[string]bool{
+	"help":             true,
+	"portwatch.bad_dd": true,
+}
+
+func TestCatalogFormatVerbs(t *testing.T) {
+	for key, en := range catalogEN {
+		if literalPercent[key] {
+			continue
+		}
+		want := formatArgs(en)
+		for lang, catalog := range catalogs {
+			if got := formatArgs(catalog[key]); !maps.Equal(got, want) {
+				t.Errorf("%s %q formats %v, English %v", lang, key, got, want)
+			}
+		}
+	}
+}
@@ -0,0 +1,481 @@
+package telegram
+
+// catalogZH is the Simplified Chinese text of the messages, by key, in the
+// order of catalogEN. Command names, arguments and tickers stay as they are
+// typed.
+var catalogZH = map[string]string{
+	"help": helpTextZH,
+	// captions.go, freshness.go, symbols.go
+	"caption.last":         "最新 %.2f (%+.2f%%)",
+	"caption.stats":        "收益 %+.2f%% • 夏普 %.2f • 波动率 %.2f%% • 最大回撤 %.2f%% • %d 天",
+	"caption.wiped_out":    "💥 组合于 %s 亏光，回测到此为止",
+	"caption.truncated":    "⚠️ 区间截短至 %s，受 %s 的历史数据限制",
+	"caption.as_of":        "数据截至美东时间 %s（%s前）",
+	"caption.missing_bars": "⚠️ 数据源缺少 %d%% 的 %s K线",
+	"caption.stale":        "⚠️ 数据过时：最后一根K线已是 %s 前",
+	"caption.skipped":      "已跳过：%s",
+	"symbols.invalid":      "无效代码：%s。请检查代码后面的周期/区间（小写，例如 %s）",
+	"symbols.too_few":      "请至少提供 %d 个不同的代码（收到 %d 个），例如 %s",
+	"symbols.too_many":     "代码太多：每张图最多 %d 个（收到 %d 个）。请分成几条命令。",
+	// handlers.go
+	"summary.no_channel":       "尚未设置来源频道。请先使用 /set source_channel @channel。",
+	"summary.storage_off":      "本群已关闭消息存储（/set store_messages off），没有可总结的内容。使用 /set store_messages on 重新开始存储消息。",
+	"summary.channel_progress": "正在总结关联频道最近 %d 小时的消息…",
+	"summary.topic_progress":   "正在总结本话题最近 %d 小时的消息…（/summary all %d 涵盖所有话题）",
+	"summary.progress":         "正在总结最近 %d 小时的消息…",
+	"chart.anchor_window":      "锚定图表只显示最近一个交易时段，请省略区间，例如 %s",
+	"chart.anchor_interval":    "锚定图表需要日内周期（1m、5m、15m 或 1h），例如 %s",
+	"chart.failed":             "图表生成失败：",
+	"indexed.failed":           "指数化图表生成失败：",
+	"indexed.caption":          "指数化：",
+	"multi.failed":             "多代码图表生成失败：",
+	"multi.caption":            "多代码：",
+	"port.invalid":             "组合格式无效：%v\n\n用法：%s",
+	"port.no_symbols":          "请至少提供一个代码及其权重，例如 %s",
+	"port.bad_hist":            "直方图组宽 %q 无效：请使用 hist、hist=fixed（%g%%）或 hist=W，W 为 0.01 到 10（百分比）",
+	"recommend.empty":          "请在 /recommend 后写上你的投资逻辑或市场观点",
+	"recommend.progress":       "🤖 正在分析你的请求并生成交易建议...",
+	"usage.progress":           "📊 正在生成使用统计...",
+	"montecarlo.progress":      "🎲 正在运行蒙特卡洛模拟...",
+	"summary.failed":           "总结失败：",
+	"summary.no_messages":      "所选时间范围内没有消息。",
+	"summary.cached":           "_（缓存）_",
+	"summary.condensed":        "_%d/%d 条消息是重复内容或纯表情，已合并。_",
+	"stock.failed":             "无法获取 %s：",
+	"ewport.failed":            "组合回测失败：",
+	"ewport.caption":           "等权组合：",
+	"port.failed":              "加权组合回测失败：",
+	"port.caption":             "加权组合：",
+	"port.hist_failed":         "日收益直方图生成失败：",
+	"port.hist_caption":        "日收益：",
+	"port.contributions":       "贡献（资产超过 %d 个，无法逐一绘制）",
+	"port.contribution":        "%s %.1f%%：%+.1f%% → %+.2f 个百分点",
+	"port.contributions_total": "资产合计：%+.2f 个百分点",
+	"help.limits":              "周期限制（数据源）",
+	"help.limit":               "%s K线：最多 %s",
+	"help.limits_note":         "更长的区间会被截短到上限，图表说明会注明，例如 /stockx TSLA 1m 1y 只显示 30 天。",
+	"recommend.failed":         "生成建议失败：",
+	"usage.failed":             "获取使用统计失败：",
+	"usage.none_days":          "最近 %d 天没有命令使用记录。",
+	"usage.none":               "没有命令使用记录。",
+	"usage.pie_caption":        "命令使用分布（%d 天）",
+	"usage.symbols_caption":    "最常绘制的代码（%d 天）",
+	"usage.series_caption":     "命令使用趋势（%d 天）",
+	"usage.latency_caption":    "每日延迟中位数（%d 天）",
+	"fail.busy":                "机器人正忙于生成其他图表，请稍后再试。",
+	"fail.symbols":             "可获取的代码不足",
+	"fail.rate_limited":        "数据源正在限流，请一分钟后再试。",
+	"fail.not_found":           "找不到该代码。请按 Yahoo Finance 的写法检查，例如 BRK-B 或 ^GSPC。",
+	"fail.no_data":             "数据不足。请尝试更长的区间或更粗的周期。",
+	"fail.timeout":             "数据源响应超时，请稍后再试。",
+	// cmdhelp.go
+	"help.examples":     "示例：",
+	"help.no_extra":     "%s 没有更多说明，请查看 /help。",
+	"help.did_you_mean": "未知命令 %s。你是想用 %s 吗？试试 /help %s。",
+	"help.unknown":      "未知命令 %s。/help 列出所有命令。",
+	// activity.go
+	"activity.storage_off":  "本群已关闭消息存储（/set store_messages off），没有可绘制的活跃度。",
+	"activity.failed":       "获取群活跃度失败：",
+	"activity.none":         "最近 %d 天没有存储的消息。",
+	"activity.chart_failed": "活跃度图表生成失败：",
+	"activity.caption":      "群活跃度：%d 条消息，%d 天",
+	"activity.most_active":  "最活跃：%s",
+	// actions.go
+	"actions.usage":          "用法：\n/actions [hours] - 找出最近几小时的待办事项（默认 24，最多 168）并加入清单\n/actions open - 列出未完成的事项\n/actions done N - 勾掉第 N 项",
+	"actions.update_failed":  "更新待办事项失败：",
+	"actions.not_open":       "本群没有未完成的第 %d 项。/actions open 列出所有未完成事项。",
+	"actions.done":           "✅ 第 %d 项已完成。",
+	"actions.storage_off":    "本群已关闭消息存储（/set store_messages off），没有可查找待办事项的消息。",
+	"actions.history_failed": "加载聊天记录失败：",
+	"actions.extract_failed": "查找待办事项失败：",
+	"actions.none":           "最近 %d 小时没有待办事项。",
+	"actions.none_new":       "最近 %d 小时没有新的待办事项；/actions open 列出 %d 项未完成事项。",
+	"actions.save_failed":    "保存待办事项失败：",
+	"actions.found":          "<b>待办事项，最近 %d 小时</b>",
+	"actions.check_off":      "用 /actions done N 勾掉一项。",
+	"actions.load_failed":    "加载待办事项失败：",
+	"actions.none_open":      "没有未完成的待办事项。/actions [hours] 可从聊天中查找新的事项。",
+	"actions.open":           "<b>未完成的待办事项（%d）</b>",
+	"actions.due":            "（截止 %s）",
+	// /again
+	"again.usage":       "用法：/again [覆盖项] - 重新运行本聊天上一条图表或组合命令，例如 /again 1w、/again 1h 6m、/again dark\n主题名设置主题。周期（1m、5m、15m、1h、1d）在首次给出时设置支持周期的命令的周期；其他词为时间窗口。也可用 window=、interval= 和 theme= 明确指定，例如 /again window=1d。",
+	"again.load_failed": "加载上一条命令失败：",
+	"again.none":        "还没有可重新运行的图表。",
+	"again.failed":      "无法重新运行 %s：%v",
+	// /alias
+	"alias.usage":         "用法：\n/alias add 名称 \"/命令 参数\" - 例如 /alias add g \"/stockx GLD 1h 6m\"，然后使用 /g\n/alias remove 名称\n/alias list",
+	"alias.remove_failed": "删除别名失败：",
+	"alias.unknown":       "本聊天没有别名 /%s。",
+	"alias.removed":       "别名 /%s 已删除。",
+	"alias.bad_name":      "别名须为 1-16 个小写字母、数字或 _，并以字母开头。",
+	"alias.taken":         "/%s 已经是机器人命令。",
+	"alias.bad_target":    "别名必须展开为机器人命令（如 /stockx），而不是 %s。",
+	"alias.recursive":     "别名不能展开为 /alias。",
+	"alias.load_failed":   "加载别名失败：",
+	"alias.too_many":      "本聊天已有 %d 个别名（上限）。请先用 /alias remove 名称 删除一个。",
+	"alias.save_failed":   "保存别名失败：",
+	"alias.title":         "别名",
+	"alias.none":          "没有自定义别名。",
+	"alias.added":         "别名 /%s → %s",
+	// /atr
+	"atr.bad_period": "ATR 周期必须在 2 到 %d 之间。",
+	"atr.failed":     "ATR 失败：",
+	"atr.caption":    "%s ATR(%d)：$%.2f（%.2f%%，价格 $%.2f）\n%g×ATR 止损：多头 %.2f • 空头 %.2f",
+	// /heat
+	"heat.failed":  "热力条生成失败：",
+	"heat.caption": "热力：%s • %s",
+	// /macd
+	"macd.failed":          "MACD 失败：",
+	"macd.above":           "高于",
+	"macd.below":           "低于",
+	"macd.caption":         "%s MACD(12,26,9) • %s • %s\nMACD %.3f %s信号线 %.3f（柱 %+.3f）\n区间内 %d 次金叉、%d 次死叉",
+	"montecarlo.usage":     "用法：/montecarlo SPY 0.7 TLT 0.3 10y [horizon=20y] [sims=2000] [seed=42]\n时间窗口（10y）是用于估计收益的回测区间；horizon 是预测的时长（默认 10y）。",
+	"montecarlo.invalid":   "%s 无效：%v",
+	"portfolio.bad_format": "组合格式无效：%v",
+	"montecarlo.failed":    "蒙特卡洛模拟失败：",
+	"montecarlo.caption":   "蒙特卡洛 • 预测 %s • %d 次模拟（回测 %s）\n终值中位数：%.1f（起点 100）\n亏损概率：%.1f%%\n日均 μ %.3f%% • σ %.2f%%",
+	// Cashtags
+	"cashtags.caption": "%s 今日（5m）",
+	// /vix
+	"vix.failed":       "VIX 图表生成失败：",
+	"vix.contango":     "升水（VIX 低于 VIX3M）",
+	"vix.inverted":     "⚠️ 倒挂（VIX 高于 VIX3M）",
+	"vix.caption":      "VIX %.2f • 过去一年第 %.0f 百分位\nVIX9D %.2f • VIX3M %.2f\n期限结构：%s",
+	"port.short":       "%s %.1f%% 做空",
+	"port.cash":        "现金 %.1f%%",
+	"port.margin":      "融资 %.1f%%",
+	"portstats.failed": "组合统计失败：",
+	// /yield and /curve
+	"yield.failed":   "收益率图表生成失败：",
+	"yield.inverted": "⚠️ 倒挂：",
+	"curve.failed":   "收益率曲线生成失败：",
+	"curve.spread":   "10Y−13W 利差：%+.2f 个百分点",
+	// /yoy
+	"yoy.usage":   "用法：/yoy 代码 [年数]，对比前 1 到 %d 年（默认 %d）",
+	"yoy.failed":  "同比图表生成失败：",
+	"yoy.caption": "%s 年初至今与前 %d 年对比，每年一月初指数化为 100（横轴：年内交易日；当年列在最前）",
+	// /optmove
+	"optmove.no_options": "%s 没有上市期权。",
+	"optmove.failed":     "无法为 %s 跨式组合定价：",
+	"optmove.today":      "今日到期",
+	"optmove.one_day":    "剩余 1 天",
+	"optmove.days":       "剩余 %d 天",
+	"optmove.reply":      "%s 至 %s 的隐含波动（%s）：±$%.2f（±%.1f%%）\n平值 %.2f 跨式 = 看涨 %.2f + 看跌 %.2f，标的 %.2f",
+	// /info
+	"info.failed": "无法获取 %s 的基本面数据：",
+	"info.none":   "该代码没有基本面数据。",
+	// /futures
+	"futures.failed": "期货查询失败：无法获取任何报价。",
+	"futures.title":  "<b>期货</b>（连续近月合约）",
+	"quote.missing":  "无报价：",
+	"futures.hint":   "可在任何代码命令中使用这些名称，例如 /stock oil 或 /stocksx es nq 1h 1m。",
+	"fetch.failed":   "无法获取 %s：",
+	// /paper
+	"paper.usage":        "用法：\n/paper buy 代码 数量\n/paper sell 代码 数量\n/paper positions\n/paper pnl - 盈亏和权益曲线",
+	"paper.bad_qty":      "数量必须为正数。",
+	"paper.load_failed":  "加载模拟账本失败：",
+	"paper.oversold":     "无法卖出 %g %s：账本持有 %g（不支持卖空）。",
+	"paper.bought":       "买入",
+	"paper.sold":         "卖出",
+	"paper.save_failed":  "记录交易失败：",
+	"paper.filled":       "📝 %s %g %s @ %.2f（合计 %.2f）。",
+	"paper.positions":    "<b>模拟持仓</b>",
+	"paper.no_positions": "没有持仓",
+	"paper.none":         "还没有模拟交易。",
+	"paper.pnl":          "模拟盈亏\n已实现：%+.2f\n未实现：%+.2f\n权益：%.2f（相对初始 %[5].0f 为 %+[4].2f%%）",
+	"paper.at_cost":      "%d 个持仓没有实时报价，按成本计价。",
+	"paper.no_curve":     "权益曲线不可用：",
+	// /portbuilder
+	"builder.empty_watchlist": "自选列表为空。用 /watch add SPY TLT 添加代码，或直接指定：/portbuilder SPY TLT GLD",
+	"builder.expired":         "此组合构建器已过期或属于其他人。请用 /portbuilder 开始你自己的构建。",
+	"builder.cancelled":       "组合构建已取消。",
+	"builder.running":         "正在运行 %s…",
+	"builder.too_many":        "每个组合最多 %d 个代码。",
+	"builder.pick_one":        "请先至少选择一个代码。",
+	"builder.no_weight":       "请至少为一个代码设置权重。",
+	"builder.over":            "权重合计 %d%%；请调整到 100%% 或以下。",
+	"builder.cancel":          "取消",
+	"builder.step_pick":       "组合构建 1/3：从自选列表中最多选择 %d 个代码，或取消后直接指定：/portbuilder SPY TLT GLD",
+	"builder.weights":         "权重 ➡️",
+	"builder.step_weights":    "组合构建 2/3：以 %d%% 为步长设置权重。",
+	"builder.window":          "时间窗口 ➡️",
+	"builder.back":            "⬅️ 返回",
+	"builder.step_window":     "组合构建 3/3：选择回测时间窗口。",
+	"builder.confirm":         "运行 %s？",
+	"builder.run":             "▶️ 运行",
+	"builder.total_over":      "合计：%d%% ⚠️ 超过 100%%",
+	"builder.total_full":      "合计：100%",
+	"builder.total":           "合计：%d%%（%d%% 现金）",
+	// /recap
+	"recap.storage_off": "本聊天已关闭消息存储（/set store_messages off），因此没有可回顾的内容。",
+	"recap.none":        "最近 %d 天（%d 条消息）没有提到任何代码。",
+	"recap.title":       "最近 %d 天讨论最多（%d 条消息）",
+	"recap.mention":     "%s - 提及 %d 次，%s",
+	"recap.mentions":    "%s - 提及 %d 次，%s",
+	"recap.failed":      "回顾图表生成失败：",
+	"recap.caption":     "讨论最多：%s，指数化为 100（%s，%s）",
+	// /ask
+	"ask.usage":       "用法：/ask [Nh] 问题，例如 /ask 昨天 Bob 推荐了哪只股票？默认查看最近 24 小时（最多 168h），以及与问题关键词匹配的更早消息。",
+	"ask.storage_off": "本聊天已关闭消息存储（/set store_messages off），因此没有可搜索的历史记录。",
+	"ask.none":        "最近 %d 小时没有可搜索的已存消息。",
+	"ask.failed":      "回答失败：",
+	// /brief
+	"brief.usage":         "用法：/brief on HH:MM | /brief off | /brief now",
+	"brief.update_failed": "更新晨报失败：",
+	"brief.none":          "没有已安排的晨报。",
+	"brief.off":           "晨报已关闭。",
+	"brief.save_failed":   "保存晨报失败：",
+	"brief.on":            "晨报已安排在每个工作日 %02d:%s（%s）发送，美国市场假日除外。用 /watch add 添加代码。",
+	"brief.holiday":       "今日无晨报：美国市场因 %s 休市。",
+	"brief.failed":        "晨报不可用：无法获取任何报价。",
+	"brief.market":        "<b>市场</b>",
+	"brief.watchlist":     "<b>自选列表</b>",
+	"brief.title":         "<b>晨报</b>",
+	"brief.early_close":   "美国市场今日提前收盘，收盘时间为美东 13:00。",
+	// /calendar
+	"calendar.usage":     "用法：/calendar [week]",
+	"calendar.week_over": "本周已没有剩余交易日。日历数据源只覆盖本周；请尝试 /calendar week。",
+	"calendar.failed":    "经济日历不可用：",
+	"calendar.title":     "<b>美国经济日历</b> • 高影响 • %s",
+	"calendar.forecast":  "预期 ",
+	"calendar.previous":  "前值 ",
+	"calendar.nothing":   "无安排",
+	// /export
+	"export.too_long":    "%s %s 周期、%s 区间共有 %d 行，超过允许的 %d 行。请使用更粗的周期，例如 /export %s 1h %s",
+	"export.failed":      "导出失败：",
+	"export.caption":     "%s • %s • %s • %d 行（时间戳为交易所时间）",
+	"export.closes_only": "该序列只有收盘价数据。",
+	// /ohlc
+	"ohlc.usage":    "用法：/ohlc 代码 [n]，n 为 1 到 %d（默认 %d）",
+	"ohlc.too_many": "/ohlc 最多显示 %d 行。如需更长的历史，请下载 CSV：/export %s 1d 6m",
+	"ohlc.no_data":  "%s 目前没有开盘/最高/最低价数据。",
+	"ohlc.title":    "<b>%s</b> • 最近 %d 根日线",
+	// /feedback
+	"feedback.usage":         "用法：/feedback 你的留言\n管理员聊天：/feedback list [n] | /feedback done N",
+	"feedback.too_long":      "反馈过长（最多 %d 个字符），请精简。",
+	"feedback.failed":        "抱歉，你的反馈未能保存。",
+	"feedback.saved":         "谢谢！你的反馈已记录为 #%d。",
+	"feedback.forward":       "📬 反馈 #%d，来自 %s，聊天 %d（%s）：\n\n%s",
+	"feedback.update_failed": "更新反馈失败：",
+	"feedback.not_found":     "未找到反馈 #%d。",
+	"feedback.done":          "反馈 #%d 已标记为完成。",
+	"feedback.load_failed":   "加载反馈失败：",
+	"feedback.none":          "没有未处理的反馈。",
+	"feedback.list_header":   "未处理的反馈（最新在前）\n",
+	// /history
+	"history.header":      "最近的图表命令（回复编号即可重新运行）：",
+	"history.usage":       "用法：/history [n]，n 为 1 到 %d",
+	"history.load_failed": "加载历史记录失败：",
+	"history.none":        "还没有图表命令。",
+	"history.no_entry":    "该历史列表中没有第 %d 项。",
+	// /movers
+	"movers.usage":           "用法：/movers [阈值%]，例如 /movers 3",
+	"movers.empty_watchlist": "自选列表为空。用 /watch add SPY AAPL ... 添加代码",
+	"movers.failed":          "异动查询失败：无法获取任何报价。",
+	"movers.none":            "今日没有自选代码的涨跌幅超过 %g%%。",
+	"movers.title":           "<b>异动 ≥ %g%%</b>",
+	"movers.alert":           "<b>异动提醒</b>（±%g%%）",
+	"movers.auto_usage":      "用法：/set movers_auto 3|off",
+	"movers.auto_off":        "自动异动提醒已关闭。",
+	"movers.auto_on":         "自选代码涨跌幅超过 ±%g%% 时每天提醒一次。",
+	"settings.save_failed":   "保存设置失败：",
+	// AI budget
+	"budget.exceeded": "本聊天今天已用完 %d 次 AI 请求额度。额度将在午夜（%s）重置。",
+	// /chart
+	"nlchart.no_symbol":   "我无法判断你想要哪个代码。试试例如 \"@bot 苹果过去六个月\" 或 /stockx AAPL 1d 6m。",
+	"nlchart.unsupported": "我理解为 %s，但这不是我能绘制的图表。请查看 /help 了解支持的周期和时间窗口。",
+	"nlchart.usage":       "告诉我要画什么图，例如 /chart 苹果过去六个月",
+	"nlchart.failed":      "抱歉，我无法理解这个图表请求：",
+	"nlchart.send_it":     "你是想要 %s 吗？请把它作为命令发送来绘制。",
+	"nlchart.confirm":     "你是想要 %s 吗？",
+	"nlchart.yes":         "是，画出来",
+	"nlchart.no":          "否",
+	"nlchart.cancelled":   "好的，已取消。",
+	"nlchart.drawing":     "正在绘制 %s…",
+	// Auto-pin
+	"pin.no_right": "无法置顶晨报：我需要在本聊天中成为拥有“置顶消息”权限的管理员。自动置顶现已关闭；授予权限后，请用 /set auto_pin on 重新开启。",
+	"pin.usage":    "用法：/set auto_pin on|off",
+	"pin.on":       "自动置顶已开启。下一份定时晨报将被静默置顶（机器人需要“置顶消息”管理员权限）。",
+	"pin.off":      "自动置顶已关闭。",
+	// /port watch
+	"portwatch.usage":         "用法：\n/port watch 名称 S1 W1 S2 W2 ... [dd=N] - 组合从峰值回撤 N%（默认 10）时提醒，每个美股收盘后估值\n/port watch 名称 dd=N - 修改监控的阈值\n/port watch list\n/port unwatch 名称",
+	"portwatch.delete_failed": "删除组合监控失败：",
+	"portwatch.unknown":       "本聊天没有名为 %q 的组合监控。",
+	"portwatch.removed":       "已停止监控 %s。",
+	"portwatch.bad_name":      "组合名称最多 32 个字母、数字、- 或 _。",
+	"portwatch.bad_dd":        "回撤阈值必须在 1 到 90 之间（dd=10 表示低于峰值 10% 时提醒）。",
+	"portwatch.update_failed": "更新组合监控失败：",
+	"portwatch.not_yet":       "还没有名为 %s 的组合监控。请给出持仓：/port watch %s SPY 0.6 TLT 0.4 dd=%g",
+	"portwatch.threshold":     "%s 现在在回撤 %g%% 时提醒。",
+	"portwatch.load_failed":   "加载组合监控失败：",
+	"portwatch.too_many":      "本聊天已监控 %d 个组合；请用 /port unwatch 名称 删除一个。",
+	"portwatch.no_price":      "无法获取 %s；监控需要每个持仓都有价格。",
+	"portwatch.save_failed":   "保存组合监控失败：",
+	"portwatch.saved":         "正在监控 %s：%s。按当前价格估值为 100；每个美股收盘后检查，低于峰值 %g%% 时每次突破提醒一次。",
+	"portwatch.replaced":      "已替换并监控 %s：%s。按当前价格估值为 100；每个美股收盘后检查，低于峰值 %g%% 时每次突破提醒一次。",
+	"portwatch.none":          "没有组合监控。",
+	"portwatch.title":         "组合监控",
+	"portwatch.unvalued":      "尚未估值",
+	"portwatch.valued":        "%[4]s 收盘：%[1].1f，峰值 %.1f（回撤 %.1f%%）",
+	"portwatch.alert":         "📉 组合 %s 低于峰值 %.1f%%（%[5]s 收盘 %[3].1f，峰值 %.1f），超过 %[6]g%% 阈值。\n%s",
+	// /schedule
+	"schedule.usage":         "用法：\n/schedule HH:MM /命令 参数 - 每个工作日在本地时间发送一条命令，例如 /schedule 09:25 /stock SPY 1d\n/schedule list\n/schedule delete N",
+	"schedule.delete_failed": "删除定时任务失败：",
+	"schedule.unknown":       "本聊天中找不到定时任务 #%d。",
+	"schedule.deleted":       "定时任务 #%d 已删除。",
+	"schedule.not_allowed":   "%s 不能被定时执行；只有 /stock、/port、/macd 等图表和行情命令可以。",
+	"schedule.load_failed":   "加载定时任务失败：",
+	"schedule.too_many":      "本聊天已有 %d 个定时任务（上限）。请用 /schedule delete N 删除一个。",
+	"schedule.save_failed":   "保存定时任务失败：",
+	"schedule.added":         "定时任务 #%d：每个工作日 %02[3]d:%[4]s（%[5]s）执行 %[2]s。",
+	"schedule.none":          "没有定时任务。",
+	"schedule.title":         "定时任务（%s）",
+	// /recommend scoreboard
+	"scoreboard.usage":       "用法：/recommend scoreboard [Nd]，N 为 1 到 %d",
+	"scoreboard.load_failed": "加载建议失败：",
+	"scoreboard.none":        "最近 %d 天没有建议。/recommend 文本 会记录它建议的仓位。",
+	"scoreboard.title":       "建议记分板，最近 %d 天（%d 个仓位）",
+	"scoreboard.unscored":    "暂时没有价格可用于评分。",
+	"scoreboard.stats":       "命中率：%.0f%%（%d/%d）• 平均收益：%+.1f%%",
+	"scoreboard.more":        "… 以及另外 %d 个",
+	"scoreboard.long":        "做多",
+	"scoreboard.short":       "做空",
+	// /summaries
+	"summaries.usage":       "用法：/summaries [n] 列出最近 n 条摘要（默认 5，最多 20）；/summaries show K 重新发送摘要 #K。",
+	"summaries.load_failed": "加载摘要失败：",
+	"summaries.none":        "还没有已保存的摘要。运行 /summary 生成一条。",
+	"summaries.title":       "最近的摘要（%s）",
+	"summaries.show_hint":   "/summaries show K 重新发送全文。",
+	"summaries.show_failed": "加载摘要失败：",
+	"summaries.unknown":     "本聊天中找不到摘要 #%d。",
+	"summaries.header":      "摘要 #%d • %s–%s",
+	// /target
+	"target.usage":         "用法：\n/target 代码 价格 [note:\"文字\"] - 代码价格穿越目标价时通知你一次（再次设置即修改）\n/target list\n/target delete N",
+	"target.delete_failed": "删除目标价失败：",
+	"target.unknown":       "本聊天中找不到目标价 #%d。",
+	"target.deleted":       "目标价 #%d 已删除。",
+	"target.already":       "%s 已经在 %.2f。",
+	"target.save_failed":   "保存目标价失败：",
+	"target.set":           "目标价 #%d 已设置：%s %s %.2f（当前 %.2f，相差 %+.2f%%）。%d 天后过期。",
+	"target.updated":       "目标价 #%d 已更新：%s %s %.2f（当前 %.2f，相差 %+.2f%%）。%d 天后过期。",
+	"target.load_failed":   "加载目标价失败：",
+	"target.none":          "没有未触发的目标价。",
+	"target.title":         "未触发的目标价",
+	"target.now":           "当前 %.2f（相差 %+.2f%%）",
+	"target.no_quote":      "无报价",
+	"target.days_left":     "剩余 %d 天",
+	"target.hit":           "🎯 %s %s 触及 %.2f（当前 %.2f）。",
+	"target.note":          "备注：",
+	// /set
+	"set.usage":           "用法：\n/set window 1d|1w|5d|1m|3m|6m|1y|2y|5y|10y|30y|off\n/set interval 1m|5m|15m|1h|1d|off\n/set theme light|dark|grafana|ant\n/set tz 地区/城市|off（例如 Asia/Singapore）\n/set source_channel @频道|ID|off\n/set movers_auto 3|off\n/set store_messages on|off\n/set auto_pin on|off\n/set lang 语言|off（机器人回复的语言，英文或中文，也是 /translate 的默认目标语言）\n/set cashtags quote|chart|off（自动回复 $代码 提及）\n/set show",
+	"set.window":          "时间窗口",
+	"set.interval":        "周期",
+	"set.theme":           "主题",
+	"set.cashtags":        "代码提及回复",
+	"set.invalid":         "%s %q 无效。请从以下选项中选择：%s",
+	"set.reset":           "默认%s已重置。",
+	"set.done":            "默认%s已设为 %s。",
+	"set.default":         "（默认）",
+	"set.title":           "聊天设置",
+	"set.per_command":     "按命令",
+	"set.none":            "无",
+	"store.usage":         "用法：/set store_messages on|off",
+	"store.on":            "消息存储已开启。/summary 将覆盖从现在起的消息。",
+	"store.purge_failed":  "消息存储已关闭，但删除已存消息失败：",
+	"store.off":           "消息存储已关闭，已删除 %d 条已存消息。命令仍可使用；/summary 不可用。",
+	"tz.unknown":          "未知时区 %q。请使用 IANA 名称，例如：%s",
+	"tz.reset":            "时区已重置为 America/New_York。",
+	"tz.set":              "时区已设为 %s。",
+	"channel.cleared":     "来源频道已清除。",
+	"channel.not_found":   "找不到频道 %s。请确认机器人是该频道的成员。",
+	"channel.not_channel": "%s 不是频道。",
+	"channel.set":         "来源频道已设为 %s。用 /summary channel [小时] 总结它的帖子。",
+	// /translate and /set lang
+	"translate.usage":     "回复一条消息并发送 /translate [语言]，例如 /translate 或 /translate English。默认语言用 /set lang 设置。",
+	"translate.no_text":   "那条消息没有可翻译的文字。",
+	"translate.unknown":   "未知语言 %s。",
+	"translate.failed":    "翻译失败：",
+	"translate.truncated": "（只翻译了前 %d 个字符）",
+	"lang.usage":          "用法：/set lang 语言|off，例如 /set lang English。它同时设置 /translate 的默认语言和机器人回复的语言（英文或中文）。",
+	"lang.reset":          "翻译语言已重置为 %s。",
+	"lang.set":            "/translate 现在默认翻译为 %s。",
+	// /watch
+	"watch.usage":         "用法：/watch add S1 S2 ... | /watch remove S1 ... | /watch",
+	"watch.load_failed":   "加载自选列表失败：",
+	"watch.empty":         "自选列表为空。",
+	"watch.list":          "自选列表：",
+	"watch.bad_symbol":    "无效代码 %q。",
+	"watch.too_many":      "自选列表最多 %d 个代码。",
+	"watch.update_failed": "更新自选列表失败：",
+	"watch.added":         "已加入自选列表：",
+	"watch.removed":       "已从自选列表移除 %d 个代码。",
+
+	// Admin commands: /version, /status, /broadcast, /report
+	"admin.only":            "此命令只能在管理员聊天中使用。",
+	"broadcast.usage":       "用法：/broadcast 文本",
+	"broadcast.list_failed": "列出聊天失败：",
+	"broadcast.started":     "正在向 %d 个聊天广播…",
+	"broadcast.done":        "广播完成：已发送 %d，跳过 %d（已屏蔽/移出），失败 %d，未尝试 %d。",
+	"report.failed":         "使用报告生成失败：",
+
+	// Handler panics
+	"error.panic": "抱歉，处理该命令时出错了，错误已记录。",
+}
+
+// helpTextZH is the Chinese /help list, line for line like helpText, so
+// /help COMMAND finds a command's lines in it the same way.
+const helpTextZH = "命令\n\n" +
+	"- /summary [hours] - 总结最近 N 小时的群聊消息（默认 1，最多 48）\n" +
+	"- /recommend TEXT - 根据你的市场观点或投资逻辑获取 AI 交易建议\n" +
+	"- /recommend scoreboard [Nd] - 本群推荐过的仓位自推荐以来的命中率和平均收益（默认 30d）\n" +
+	"- /usage [Xd] - 查看使用统计（默认全部时间，可指定天数，如 /usage 7d）\n" +
+	"- /activity [Nd] [users] - 本群每天的消息数图表（2d 以内按小时；默认 7d，最多 90d）；users 单独列出最活跃的 5 人\n" +
+	"- /summaries [n] - 最近保存的总结及其时间范围；/summaries show K 重新发送一条（保留 90 天）\n" +
+	"- /ask [Nh] QUESTION - 根据聊天记录回答问题（默认最近 24h，另加更早的相关消息）\n" +
+	"- /actions [hours] | open | done N - 群里的待办事项，编号清单\n" +
+	"- /translate [language] - 回复一条消息来翻译它（默认使用 /set lang，否则英语）\n" +
+	"- /recap [Nd] [chart] - 最近 7 天（最多 30 天）讨论最多的代码及其收益；chart 附上前 5 名的指数化图表\n" +
+	"- /summary all [hours] - 在论坛群组中总结所有话题，而不只是当前话题\n" +
+	"- /summary channel [hours] - 总结通过 /set source_channel 关联的频道\n" +
+	"- /set source_channel @channel|ID|off - 关联一个频道，供 /summary channel 读取\n" +
+	"- /set window|interval|theme VALUE - 省略参数时使用的图表默认值；/set show 列出全部设置\n" +
+	"- /set auto_pin on|off - 静默置顶每天的晨报，并取消置顶上一条（默认开启）\n" +
+	"- /set store_messages off - 停止存储（并删除）本群消息；/summary 将不可用\n" +
+	"- /set lang LANGUAGE|off - 机器人回复的语言（English 或 中文）及 /translate 的默认目标语言\n" +
+	"- /watch add|remove S1 S2 ... - 管理本群自选列表；/watch 查看列表\n" +
+	"- /brief on HH:MM|off|now - 工作日晨报：市场概况、自选股涨跌和 AI 点评\n" +
+	"- /movers [N%] - 今日涨跌超过 N% 的自选股（默认 2%）；/set movers_auto N 开启自动提醒\n" +
+	"- /target SYMBOL PRICE [note:\"...\"] - 价格穿越时提醒一次；/target list|delete N\n" +
+	"- /macd SYMBOL [interval] [window] - 价格图，下方附 MACD(12,26,9)、信号线和柱状图\n" +
+	"- /atr SYMBOL [period] [window] - 日收盘价及收盘价 ± 3×ATR 止损线（默认 ATR(14)，6m）\n" +
+	"- /yoy SYMBOL [years] - 今年走势与往年对比，每年 1 月指数化为 100（默认 5 年）\n" +
+	"- /history [n] - 最近 n 条图表/组合命令；回复列表中的编号即可重新运行\n" +
+	"- /again [overrides] - 重新运行上一条图表/组合命令，可修改区间、周期或主题，例如 /again 1w、/again 1h 6m、/again dark\n" +
+	"- /ohlc SYMBOL [n] - 最近 n 根日K线表格：开盘、最高、最低、收盘和涨跌幅（默认 10，最多 30）\n" +
+	"- /export SYMBOL [interval] [window] - 下载 CSV 数据（时间戳、可用时的 OHLC、收盘价、成交量）\n" +
+	"- /info SYMBOL - 基本面：名称、行业、市值、市盈率、股息率、贝塔、52 周区间\n" +
+	"- /optmove SYMBOL - 最近到期平值跨式期权隐含的波动幅度\n" +
+	"- /calendar [week] - 本周剩余（或整周）的美国重要经济数据发布\n" +
+	"- /vix [window] - VIX 及其 1 年百分位和 VIX9D/VIX/VIX3M 期限结构（默认 6m）\n" +
+	"- /yield [window] - 13 周、5 年、10 年和 30 年美债收益率（%）（默认 1y）\n" +
+	"- /curve - 美债收益率曲线快照；短端高于长端时提示倒挂\n" +
+	"- /heat S1 S2 ... [window] [svg] - 一排色块，按各代码涨跌由红到绿（默认 1d）\n" +
+	"- /futures - 期货简称及报价；代码类命令可直接用简称，例如 /stock oil、/stocks es nq\n" +
+	"- /montecarlo S1 W1 S2 W2 ... WINDOW [horizon=20y] [sims=2000] [seed=N] - 组合价值预测扇形图（5/25/50/75/95%）\n" +
+	"- /paper buy|sell SYMBOL QTY, /paper positions, /paper pnl - 按实时报价成交的群共享模拟账户\n" +
+	"- /schedule HH:MM /command - 每个工作日在该本地时间发布一条命令；/schedule list|delete N\n" +
+	"- /feedback TEXT - 向机器人维护者发送反馈\n" +
+	"- /chart TEXT - 用自然语言画图，例如 /chart apple vs microsoft this year；@机器人 也可以\n" +
+	"- /alias add NAME \"/command args\" - 本群命令简写，例如 /alias add g \"/stockx GLD 1h 6m\" 后使用 /g；/alias list|remove NAME。内置：/s、/ss、/sx、/p\n" +
+	"- /set tz Area/City - 图表标签的时区（默认 America/New_York）\n" +
+	"- /stock SYMBOL [1d|1w|1m] [open|pre|prevclose] [vwap] [svg] - 单代码 5m 迷你图，可加时段 VWAP；open/pre/prevclose 只显示今天的时段\n" +
+	"- /stocks S1 S2 ... [1d|1w|1m] [svg] - 多代码 5m 图；超过 2 个时自动换算为 %\n" +
+	"- /stockx SYMBOL [1m|5m|15m|1h|1d] [1d|5d|1m|3m|6m|1y|2y|5y|10y|30y] [open|pre|prevclose] [vwap] [noevents] [svg] - 单代码自定义图（日内周期可用 vwap 和锚点；日线图用 D 标出除息日、S 标出拆股，noevents 关闭）\n" +
+	"- /stocksx S1 S2 ... [interval] [window] [svg] - 多代码自定义图；超过 2 个时自动换算为 %\n" +
+	"- /stocks-index S1 S2 ... [interval] [window] [svg] - 以起点为 100 指数化，比较相对表现\n" +
+	"- /ew-port S1 S2 ... [Xd|Xw|Xm|Xy] [svg] - 等权组合回测（初始 $100）\n" +
+	"- /port S1 W1 S2 W2 ... [Xd|Xw|Xm|Xy|ytd] [detail] [hist[=W]] [normal] [svg] - 区间内的加权组合（默认 1y；W>0 做多，W<0 做空，其余为现金/融资）；detail 同时绘制各资产，hist[=W] [normal] 附日收益直方图，svg 以 SVG 文件发送图表\n" +
+	"- /portstats S1 W1 S2 W2 ... [Xd|Xw|Xm|Xy|ytd] - 与 /port 相同的回测，只输出统计（CAGR、夏普、索提诺、回撤日期、最好/最差单日）\n" +
+	"- /port watch NAME S1 W1 S2 W2 ... [dd=N] - 组合从峰值回撤 N%（默认 10）时提醒，每个美股收盘后估值；/port watch list、/port unwatch NAME\n" +
+	"- /portbuilder [S1 S2 ...] - 用按钮搭建 /port 回测：从自选列表选代码（或直接写出），按 5% 步长设置权重，选择区间\n" +
+	"\n限制（Yahoo）：1m→30d，5m→90d，15m→180d，1h→2y，1d→30y；更长的区间会被截短并提示（/help limits）。/help COMMAND 显示命令的参数和示例。X 轴为美东时间，可用 /set tz 修改。"
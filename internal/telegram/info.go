@@ -0,0 +1,93 @@
+package telegram
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"math"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"telegramBotTrade/internal/finance"
+	"telegramBotTrade/internal/logging"
+	"telegramBotTrade/internal/storage"
+)
+
+// handleInfo replies with sym's fundamentals snapshot.
+func (h *Handlers) handleInfo(ctx context.Context, chatID int64, sym string) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	cs := h.chartSettings(chatID)
+	f, err := finance.FetchFundamentals(ctx, sym)
+	if err != nil {
+		logging.FromContext(ctx).Error("info failed", "chat_id", chatID, "symbol", sym, "err", err)
+		h.replyFailure(chatID, T(cs, "info.failed", strings.ToUpper(sym)), err)
+		return
+	}
+	msg := tgbotapi.NewMessage(chatID, formatInfo(cs, f))
+	msg.ParseMode = "HTML"
+	h.api.Send(msg)
+}
+
+// formatInfo renders the snapshot, leaving out every field Yahoo didn't report
+// rather than printing zeros. The table's labels stay English so it lines up.
+func formatInfo(cs storage.ChatSettings, f *finance.Fundamentals) string {
+	var b strings.Builder
+	title := "<b>" + html.EscapeString(f.Symbol) + "</b>"
+	if f.Name != "" {
+		title += " • " + html.EscapeString(f.Name)
+	}
+	b.WriteString(title + "\n")
+	switch {
+	case f.Sector != "" && f.Industry != "":
+		b.WriteString(html.EscapeString(f.Sector+" / "+f.Industry) + "\n")
+	case f.Sector != "":
+		b.WriteString(html.EscapeString(f.Sector) + "\n")
+	case f.QuoteType != "" && f.QuoteType != "EQUITY":
+		b.WriteString(html.EscapeString(f.QuoteType) + "\n")
+	}
+	var rows []string
+	add := func(label string, v *float64, format func(float64) string) {
+		if v == nil || *v == 0 || math.IsNaN(*v) {
+			return
+		}
+		rows = append(rows, fmt.Sprintf("%-14s %s", label, format(*v)))
+	}
+	currency := func(v float64) string {
+		if f.Currency != "" && f.Currency != "USD" {
+			return humanize(v) + " " + f.Currency
+		}
+		return "$" + humanize(v)
+	}
+	ratio := func(v float64) string { return fmt.Sprintf("%.2f", v) }
+	add("Market cap", f.MarketCap, currency)
+	add("P/E (ttm)", f.TrailingPE, ratio)
+	add("P/E (fwd)", f.ForwardPE, ratio)
+	add("Dividend yield", f.DividendYield, func(v float64) string { return fmt.Sprintf("%.2f%%", v*100) })
+	add("Beta", f.Beta, ratio)
+	if f.Low52 != nil && f.High52 != nil && *f.High52 > 0 {
+		rows = append(rows, fmt.Sprintf("%-14s %.2f – %.2f", "52w range", *f.Low52, *f.High52))
+	}
+	if len(rows) == 0 {
+		b.WriteString(T(cs, "info.none"))
+		return b.String()
+	}
+	b.WriteString("<pre>" + html.EscapeString(strings.Join(rows, "\n")) + "</pre>")
+	return b.String()
+}
+
+// humanize formats large numbers with a K/M/B/T suffix, e.g. 1.95T.
+func humanize(v float64) string {
+	abs := math.Abs(v)
+	for _, u := range []struct {
+		div    float64
+		suffix string
+	}{{1e12, "T"}, {1e9, "B"}, {1e6, "M"}, {1e3, "K"}} {
+		if abs >= u.div {
+			return fmt.Sprintf("%.2f%s", v/u.div, u.suffix)
+		}
+	}
+	return fmt.Sprintf("%.2f", v)
+}
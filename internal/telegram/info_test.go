@@ -0,0 +1,85 @@
+package telegram
+
+import (
+	"strings"
+	"testing"
+
+	"telegramBotTrade/internal/finance"
+	"telegramBotTrade/internal/storage"
+)
+
+func TestHumanize(t *testing.T) {
+	tests := []struct {
+		v    float64
+		want string
+	}{
+		{1.95e12, "1.95T"},
+		{3.4e11, "340.00B"},
+		{2.5e9, "2.50B"},
+		{7.25e6, "7.25M"},
+		{1500, "1.50K"},
+		{999, "999.00"},
+		{-2.1e9, "-2.10B"},
+		{0, "0.00"},
+	}
+	for _, tc := range tests {
+		if got := humanize(tc.v); got != tc.want {
+			t.Errorf("humanize(%v) = %q, want %q", tc.v, got, tc.want)
+		}
+	}
+}
+
+func ptr(v float64) *float64 { return &v }
+
+func TestFormatInfo(t *testing.T) {
+	en := storage.ChatSettings{}
+	tests := []struct {
+		name      string
+		f         finance.Fundamentals
+		want      []string
+		wantNotIn []string
+	}{
+		{
+			name: "equity",
+			f: finance.Fundamentals{Symbol: "AAPL", Name: "Apple Inc.", QuoteType: "EQUITY", Currency: "USD",
+				Sector: "Technology", Industry: "Consumer Electronics",
+				MarketCap: ptr(2.95e12), TrailingPE: ptr(31.456), ForwardPE: ptr(28.1), DividendYield: ptr(0.0051),
+				Beta: ptr(1.24), Low52: ptr(164.08), High52: ptr(237.23)},
+			want: []string{"<b>AAPL</b> • Apple Inc.", "Technology / Consumer Electronics", "$2.95T", "31.46", "28.10",
+				"0.51%", "1.24", "164.08 – 237.23"},
+		},
+		{
+			name: "etf without earnings",
+			f: finance.Fundamentals{Symbol: "SPY", Name: "SPDR S&P 500", QuoteType: "ETF", Currency: "USD",
+				TrailingPE: ptr(0), DividendYield: ptr(0.012), Low52: ptr(400), High52: ptr(560)},
+			want:      []string{"SPDR S&amp;P 500", "ETF", "1.20%", "400.00 – 560.00"},
+			wantNotIn: []string{"Market cap", "P/E", "Beta"},
+		},
+		{
+			name: "foreign currency",
+			f:    finance.Fundamentals{Symbol: "7203.T", QuoteType: "EQUITY", Currency: "JPY", MarketCap: ptr(4.2e13)},
+			want: []string{"42.00T JPY"},
+		},
+		{
+			name:      "nothing reported",
+			f:         finance.Fundamentals{Symbol: "BTC-USD", QuoteType: "CRYPTOCURRENCY"},
+			want:      []string{"CRYPTOCURRENCY", T(en, "info.none")},
+			wantNotIn: []string{"<pre>"},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := formatInfo(en, &tc.f)
+			for _, s := range tc.want {
+				if !strings.Contains(got, s) {
+					t.Errorf("missing %q in\n%s", s, got)
+				}
+			}
+			for _, s := range tc.wantNotIn {
+				if strings.Contains(got, s) {
+					t.Errorf("unexpected %q in\n%s", s, got)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,41 @@
+package telegram
+
+import (
+	"context"
+	"time"
+
+	"telegramBotTrade/internal/logging"
+)
+
+type commandTimingKey struct{}
+
+// commandTiming follows one message through HandleMessage: when it started
+// and the command_usage row trackCommand saved for it, if any.
+type commandTiming struct {
+	start time.Time
+	id    int64
+}
+
+// withCommandTiming starts timing the message being handled.
+func withCommandTiming(ctx context.Context) (context.Context, *commandTiming) {
+	t := &commandTiming{start: time.Now()}
+	return context.WithValue(ctx, commandTimingKey{}, t), t
+}
+
+// timeCommand attaches the usage row id to the message being timed.
+func timeCommand(ctx context.Context, id int64) {
+	if t, ok := ctx.Value(commandTimingKey{}).(*commandTiming); ok {
+		t.id = id
+	}
+}
+
+// finishCommand records how long the tracked command took; messages that
+// were not a command record nothing.
+func (h *Handlers) finishCommand(ctx context.Context, t *commandTiming) {
+	if t.id == 0 {
+		return
+	}
+	if err := h.store.SetCommandDuration(t.id, time.Since(t.start)); err != nil {
+		logging.FromContext(ctx).Warn("usage: save duration failed", "id", t.id, "err", err)
+	}
+}
@@ -0,0 +1,42 @@
+package telegram
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"telegramBotTrade/internal/finance"
+	"telegramBotTrade/internal/logging"
+)
+
+// handleMACD sends the price and MACD(12,26,9) panels for sym.
+func (h *Handlers) handleMACD(ctx context.Context, chatID int64, sym, interval, window string) {
+	cs := h.chartSettings(chatID)
+	if interval == "" {
+		interval = defaultInterval(cs)
+	}
+	if window == "" {
+		window = customWindow(cs)
+	}
+	ctx, cancel := context.WithTimeout(ctx, 60*time.Second)
+	defer cancel()
+	ctx, fresh := finance.WithFreshness(ctx)
+	img, sum, err := finance.MakeMACDChart(ctx, sym, interval, window, renderOptions(ctx, cs))
+	if err != nil {
+		logging.FromContext(ctx).Error("macd failed", "chat_id", chatID, "symbol", sym, "err", err)
+		h.replyFailure(chatID, T(cs, "macd.failed"), err)
+		return
+	}
+	state := T(cs, "macd.above")
+	if sum.Hist < 0 {
+		state = T(cs, "macd.below")
+	}
+	photo := tgbotapi.NewPhoto(chatID, tgbotapi.FileBytes{Name: sym + "_macd.png", Bytes: img})
+	photo.Caption = T(cs, "macd.caption",
+		strings.ToUpper(sym), strings.ToUpper(interval), strings.ToUpper(window), sum.Line, state, sum.Signal, sum.Hist, sum.Bullish, sum.Bearish)
+	photo.Caption = demoCaption(freshCaption(cs, clampCaption(photo.Caption, interval, window), fresh))
+	h.api.Send(photo)
+	markDelivered(ctx)
+}
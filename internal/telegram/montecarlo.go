@@ -0,0 +1,74 @@
+package telegram
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"telegramBotTrade/internal/finance"
+	"telegramBotTrade/internal/logging"
+)
+
+// handleMonteCarlo parses "/montecarlo S1 W1 ... WINDOW key=value..." and sends a fan chart.
+func (h *Handlers) handleMonteCarlo(ctx context.Context, chatID int64, args string) {
+	params := finance.MonteCarloParams{Seed: time.Now().UnixNano()}
+	horizon := "10y"
+	var rest []string
+	for _, f := range strings.Fields(args) {
+		k, v, ok := strings.Cut(f, "=")
+		if !ok {
+			rest = append(rest, f)
+			continue
+		}
+		var err error
+		switch strings.ToLower(k) {
+		case "horizon":
+			horizon = v
+		case "sims":
+			params.Sims, err = strconv.Atoi(v)
+			if err == nil && (params.Sims < 1 || params.Sims > finance.MaxMonteCarloSims) {
+				err = fmt.Errorf("sims must be between 1 and %d", finance.MaxMonteCarloSims)
+			}
+		case "seed":
+			params.Seed, err = strconv.ParseInt(v, 10, 64)
+		default:
+			err = fmt.Errorf("unknown option %q", k)
+		}
+		if err != nil {
+			h.reply(chatID, h.t(chatID, "montecarlo.invalid", k, err)+"\n\n"+h.t(chatID, "montecarlo.usage"))
+			return
+		}
+	}
+	days, err := finance.ParseHorizon(horizon)
+	if err != nil {
+		h.reply(chatID, err.Error()+"\n\n"+h.t(chatID, "montecarlo.usage"))
+		return
+	}
+	params.HorizonDays = days
+	symbols, weights, window, err := finance.ParseWeightedPortfolio(strings.Join(rest, " "))
+	if err != nil {
+		h.reply(chatID, h.t(chatID, "portfolio.bad_format", err)+"\n\n"+h.t(chatID, "montecarlo.usage"))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 90*time.Second)
+	defer cancel()
+	ctx, fresh := finance.WithFreshness(ctx)
+	cs := h.chartSettings(chatID)
+	img, res, err := finance.MakeMonteCarloChart(ctx, symbols, weights, window, params, renderOptions(ctx, cs))
+	if err != nil {
+		logging.FromContext(ctx).Error("montecarlo failed", "chat_id", chatID, "symbols", symbols, "err", err)
+		h.replyFailure(chatID, T(cs, "montecarlo.failed"), err)
+		return
+	}
+	photo := tgbotapi.NewPhoto(chatID, tgbotapi.FileBytes{Name: "montecarlo.png", Bytes: img})
+	photo.Caption = T(cs, "montecarlo.caption",
+		strings.ToUpper(horizon), res.Sims, strings.ToUpper(window), res.MedianFinal, res.ProbLoss*100, res.MeanDaily*100, res.VolDaily*100)
+	photo.Caption = demoCaption(freshCaption(cs, photo.Caption, fresh))
+	h.api.Send(photo)
+	markDelivered(ctx)
+}
@@ -0,0 +1,118 @@
+package telegram
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"telegramBotTrade/internal/finance"
+	"telegramBotTrade/internal/logging"
+)
+
+const defaultMoversThreshold = 2.0
+
+// parseThreshold reads "3", "3%" or "2.5%" as a positive percentage.
+func parseThreshold(s string) (float64, bool) {
+	v, err := strconv.ParseFloat(strings.TrimSuffix(strings.TrimSpace(s), "%"), 64)
+	if err != nil || v <= 0 || v > 100 {
+		return 0, false
+	}
+	return v, true
+}
+
+// moversAbove returns the quotes moving at least threshold percent, largest first.
+func moversAbove(quotes map[string]finance.Quote, threshold float64) []finance.Quote {
+	var out []finance.Quote
+	for _, q := range quotes {
+		if math.Abs(q.ChangePct) >= threshold {
+			out = append(out, q)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return math.Abs(out[i].ChangePct) > math.Abs(out[j].ChangePct) })
+	return out
+}
+
+// handleMovers lists watchlist symbols moving more than threshold percent today.
+func (h *Handlers) handleMovers(ctx context.Context, chatID int64, arg string) {
+	cs := h.chartSettings(chatID)
+	threshold := defaultMoversThreshold
+	if arg != "" {
+		v, ok := parseThreshold(arg)
+		if !ok {
+			h.reply(chatID, T(cs, "movers.usage"))
+			return
+		}
+		threshold = v
+	}
+	watch, err := h.store.FetchWatchlist(chatID)
+	if err != nil {
+		h.reply(chatID, T(cs, "watch.load_failed")+err.Error())
+		return
+	}
+	if len(watch) == 0 {
+		h.reply(chatID, T(cs, "movers.empty_watchlist"))
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, 45*time.Second)
+	defer cancel()
+	quotes, errs := finance.FetchBatchQuotes(ctx, watch)
+	if len(quotes) == 0 {
+		logging.FromContext(ctx).Error("movers failed", "chat_id", chatID, "failed", len(errs))
+		h.reply(chatID, T(cs, "movers.failed"))
+		return
+	}
+	movers := moversAbove(quotes, threshold)
+	if len(movers) == 0 {
+		h.reply(chatID, T(cs, "movers.none", threshold))
+		return
+	}
+	h.sendMovers(chatID, T(cs, "movers.title", threshold), movers, errs)
+}
+
+func (h *Handlers) sendMovers(chatID int64, title string, movers []finance.Quote, errs map[string]error) {
+	var b strings.Builder
+	b.WriteString(title + "\n<pre>")
+	for _, q := range movers {
+		fmt.Fprintf(&b, "%s %-8s %+6.2f%% %10.2f\n", moveArrow(q.ChangePct), q.Symbol, q.ChangePct, q.Price)
+	}
+	b.WriteString("</pre>")
+	if len(errs) > 0 {
+		failed := make([]string, 0, len(errs))
+		for s := range errs {
+			failed = append(failed, s)
+		}
+		sort.Strings(failed)
+		b.WriteString("\n" + h.t(chatID, "quote.missing") + strings.Join(failed, ", "))
+	}
+	msg := tgbotapi.NewMessage(chatID, b.String())
+	msg.ParseMode = "HTML"
+	h.api.Send(msg)
+}
+
+// setMoversAuto stores the threshold for background movers alerts.
+func (h *Handlers) setMoversAuto(chatID int64, value string) {
+	threshold := 0.0
+	if value != "off" && value != "0" {
+		v, ok := parseThreshold(value)
+		if !ok {
+			h.reply(chatID, h.t(chatID, "movers.auto_usage"))
+			return
+		}
+		threshold = v
+	}
+	if err := h.settings.Set(chatID, "movers_auto", threshold); err != nil {
+		h.reply(chatID, h.t(chatID, "settings.save_failed")+err.Error())
+		return
+	}
+	if threshold == 0 {
+		h.reply(chatID, h.t(chatID, "movers.auto_off"))
+		return
+	}
+	h.reply(chatID, h.t(chatID, "movers.auto_on", threshold))
+}
@@ -0,0 +1,138 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"telegramBotTrade/internal/logging"
+)
+
+const (
+	// nameBackfillGap spaces getChatMember calls well below Telegram's limits.
+	nameBackfillGap = time.Second
+	// nameBackfillIdle is the pause between passes over the unnamed chats.
+	nameBackfillIdle  = time.Hour
+	nameBackfillBatch = 50
+	// formerMember labels users no longer in the chat.
+	formerMember = "former member"
+)
+
+// runNameBackfill names the users of messages stored before names were kept,
+// so summaries and /ask show who said what instead of raw IDs. Each chat's
+// cursor is persisted per user, so a restart resumes where it stopped.
+func (b *Bot) runNameBackfill(ctx context.Context) {
+	for {
+		b.backfillNames(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(nameBackfillIdle):
+		}
+	}
+}
+
+// backfillNames makes one pass over every chat with unnamed messages.
+func (b *Bot) backfillNames(ctx context.Context) {
+	ctx = logging.WithRequestID(ctx, logging.NewRequestID())
+	logger := logging.FromContext(ctx)
+	chats, err := b.store.FetchUnnamedChats()
+	if err != nil {
+		logger.Error("names: load chats failed", "err", err)
+		return
+	}
+	tick := time.NewTicker(nameBackfillGap)
+	defer tick.Stop()
+	for chatID, cursor := range chats {
+		if !b.h.chats.active(chatID) {
+			continue
+		}
+		named, err := b.backfillChat(ctx, chatID, cursor, tick.C)
+		if ctx.Err() != nil {
+			return
+		}
+		if named > 0 {
+			logger.Info("names: backfilled", "chat_id", chatID, "users", named)
+		}
+		if err != nil {
+			// retried on the next pass; a chat the bot left fails every time
+			logger.Debug("names: chat skipped", "chat_id", chatID, "err", err)
+		}
+	}
+}
+
+// backfillChat resolves the chat's unnamed users above cursor in ascending
+// order, taking one tick per Telegram call, and returns how many it named.
+func (b *Bot) backfillChat(ctx context.Context, chatID, cursor int64, tick <-chan time.Time) (int, error) {
+	named := 0
+	for {
+		users, err := b.store.FetchUnnamedUsers(chatID, cursor, nameBackfillBatch)
+		if err != nil || len(users) == 0 {
+			return named, err
+		}
+		for _, userID := range users {
+			select {
+			case <-ctx.Done():
+				return named, ctx.Err()
+			case <-tick:
+			}
+			name, err := b.memberName(ctx, chatID, userID)
+			if err != nil {
+				return named, err
+			}
+			if err := b.store.BackfillUserName(chatID, userID, name); err != nil {
+				return named, err
+			}
+			cursor = userID
+			named++
+		}
+	}
+}
+
+// memberName returns the user's current name in chatID. Users who left, were
+// removed or are unknown to the chat are labeled as former members.
+func (b *Bot) memberName(ctx context.Context, chatID, userID int64) (string, error) {
+	for {
+		member, err := b.api.GetChatMember(tgbotapi.GetChatMemberConfig{
+			ChatConfigWithUser: tgbotapi.ChatConfigWithUser{ChatID: chatID, UserID: userID},
+		})
+		if err == nil {
+			name := userName(member.User)
+			switch {
+			case !member.HasLeft() && !member.WasKicked() && name != "":
+				return name, nil
+			case name != "":
+				return name + " (" + formerMember + ")", nil
+			}
+			return formerMember, nil
+		}
+		if memberUnknown(err) {
+			return formerMember, nil
+		}
+		outcome, retry := classifySendError(err)
+		if outcome != sendRateLimited {
+			return "", err
+		}
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case <-time.After(retry):
+		}
+	}
+}
+
+// memberUnknown reports whether getChatMember failed because the user is not
+// (or no longer) a participant.
+func memberUnknown(err error) bool {
+	var tgErr *tgbotapi.Error
+	if !errors.As(err, &tgErr) {
+		return false
+	}
+	msg := strings.ToLower(tgErr.Message)
+	return strings.Contains(msg, "user not found") ||
+		strings.Contains(msg, "participant_id_invalid") ||
+		strings.Contains(msg, "member not found")
+}
@@ -0,0 +1,171 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"telegramBotTrade/internal/logging"
+	"telegramBotTrade/internal/openai"
+	"telegramBotTrade/internal/storage"
+)
+
+// callbackRun prefixes the data of a "Did you mean …?" button; the rest is the
+// chart command to run.
+const callbackRun = "run:"
+
+// callbackCancel is the data of the button that dismisses the question.
+const callbackCancel = "cancel"
+
+// mentionText returns txt without the bot's @username when a plain message
+// mentions the bot, so "@Bot show me apple for six months" can be charted.
+func (h *Handlers) mentionText(txt string) (string, bool) {
+	name := h.api.Self.UserName
+	if strings.HasPrefix(txt, "/") || name == "" || !strings.Contains(strings.ToLower(txt), "@"+strings.ToLower(name)) {
+		return "", false
+	}
+	re := regexp.MustCompile(`(?i)@` + regexp.QuoteMeta(name) + `\b`)
+	if !re.MatchString(txt) {
+		return "", false
+	}
+	return strings.TrimSpace(re.ReplaceAllString(txt, "")), true
+}
+
+// intentCommand turns an extracted chart request into the chart command that
+// draws it, e.g. "/stockx AAPL 1d 6m". The command is checked against the
+// command's own pattern so it always dispatches.
+func intentCommand(cs storage.ChatSettings, intent openai.ChartIntent) (string, error) {
+	var syms []string
+	for _, s := range intent.Symbols {
+		if reSymbol.MatchString(s) && validSymbol(s) {
+			syms = append(syms, s)
+		}
+	}
+	if len(syms) == 0 {
+		return "", errors.New(T(cs, "nlchart.no_symbol"))
+	}
+	if len(syms) > multiChartSymbols.max {
+		syms = syms[:multiChartSymbols.max]
+	}
+	interval, window := intent.Interval, intent.Window
+	if interval == "" && window != "" {
+		// a long lookback with the 5m default would be clamped anyway
+		switch window {
+		case "1d":
+			interval = "5m"
+		case "5d", "1m":
+			interval = "1h"
+		default:
+			interval = "1d"
+		}
+	}
+
+	var cmd string
+	var re *regexp.Regexp
+	switch {
+	case intent.ChartType == "macd":
+		cmd, re = "/macd "+syms[0], reMACD
+	case len(syms) == 1:
+		cmd, re = "/stockx "+syms[0], reStockX
+	case intent.ChartType == "indexed":
+		cmd, re = "/stocks-index "+strings.Join(syms, " "), reStocksIndex
+	default:
+		cmd, re = "/stocksx "+strings.Join(syms, " "), reStocksX
+	}
+	cmd = strings.TrimSpace(cmd + " " + interval + " " + window)
+	if !re.MatchString(cmd) {
+		return "", errors.New(T(cs, "nlchart.unsupported", cmd))
+	}
+	return cmd, nil
+}
+
+// handleNLChart charts a free-text request. A confident extraction runs the
+// chart straight away; a guessed one is confirmed with Yes/No buttons first.
+func (h *Handlers) handleNLChart(ctx context.Context, m *tgbotapi.Message, text string) {
+	cs := h.chartSettings(m.Chat.ID)
+	if text == "" {
+		h.reply(m.Chat.ID, T(cs, "nlchart.usage"))
+		return
+	}
+	if msg, over := h.aiBudgetExceeded(m.Chat.ID); over {
+		h.reply(m.Chat.ID, msg)
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, 20*time.Second)
+	intent, err := h.summarize.ExtractChartIntent(ctx, text)
+	cancel()
+	if err != nil {
+		logging.FromContext(ctx).Error("chart intent failed", "chat_id", m.Chat.ID, "err", err)
+		h.reply(m.Chat.ID, T(cs, "nlchart.failed")+err.Error())
+		return
+	}
+	cmd, err := intentCommand(cs, intent)
+	if err != nil {
+		h.reply(m.Chat.ID, err.Error())
+		return
+	}
+	logging.FromContext(ctx).Info("chart intent", "chat_id", m.Chat.ID, "command", cmd, "confident", intent.Confident)
+	// callback data is capped at 64 bytes; longer commands are offered as text
+	if !intent.Confident {
+		if len(callbackRun+cmd) > 64 {
+			h.reply(m.Chat.ID, T(cs, "nlchart.send_it", cmd))
+			return
+		}
+		msg := tgbotapi.NewMessage(m.Chat.ID, T(cs, "nlchart.confirm", cmd))
+		msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(T(cs, "nlchart.yes"), callbackRun+cmd),
+			tgbotapi.NewInlineKeyboardButtonData(T(cs, "nlchart.no"), callbackCancel),
+		))
+		h.api.Send(msg)
+		return
+	}
+	rerun := *m
+	rerun.ReplyToMessage = nil
+	rerun.Text = cmd
+	h.HandleMessage(withRerun(ctx), &rerun)
+}
+
+// handleCallback answers a "Did you mean …?" button. Yes runs the chart as a
+// message from the user who pressed it, through the worker pool like any
+// other update; only the read-only commands /history may re-run are accepted,
+// whatever the callback data says. /portbuilder buttons go to the builder.
+func (b *Bot) handleCallback(ctx context.Context, cq *tgbotapi.CallbackQuery) {
+	if strings.HasPrefix(cq.Data, callbackBuilder) {
+		// the builder answers its own callbacks; a finished build runs /port
+		if cmd := b.h.portBuilderCallback(cq); cmd != "" {
+			b.submitCallbackCommand(ctx, cq, cmd)
+		}
+		return
+	}
+	_, _ = b.api.Request(tgbotapi.NewCallback(cq.ID, ""))
+	if cq.Message == nil {
+		return
+	}
+	chatID, msgID := cq.Message.Chat.ID, cq.Message.MessageID
+	cmd, ok := strings.CutPrefix(cq.Data, callbackRun)
+	if !ok || !historyCommands[strings.TrimPrefix(commandName(cmd), "/")] {
+		b.api.Send(tgbotapi.NewEditMessageText(chatID, msgID, b.h.t(chatID, "nlchart.cancelled")))
+		return
+	}
+	b.api.Send(tgbotapi.NewEditMessageText(chatID, msgID, b.h.t(chatID, "nlchart.drawing", cmd)))
+	b.submitCallbackCommand(ctx, cq, cmd)
+}
+
+// submitCallbackCommand runs cmd as a message from the user who pressed the
+// button, through the worker pool like any other update.
+func (b *Bot) submitCallbackCommand(ctx context.Context, cq *tgbotapi.CallbackQuery, cmd string) {
+	msg := &tgbotapi.Message{
+		MessageID: cq.Message.MessageID,
+		From:      cq.From,
+		Chat:      cq.Message.Chat,
+		Text:      cmd,
+		Date:      int(time.Now().Unix()),
+	}
+	if err := b.pool.submit(job{ctx: withRerun(ctx), msg: msg}); err != nil {
+		logging.FromContext(ctx).Warn("callback: dropped", "chat_id", cq.Message.Chat.ID, "err", err)
+	}
+}
@@ -0,0 +1,50 @@
+package telegram
+
+import (
+	"context"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"telegramBotTrade/internal/finance"
+	"telegramBotTrade/internal/logging"
+	"telegramBotTrade/internal/server"
+)
+
+// Notify delivers an /api/notify message from CI or a monitor into a chat the
+// bot already knows. When a symbol is given its chart follows the text using
+// the chat's chart defaults; a failed chart is reported in the result rather
+// than failing the delivery.
+func (b *Bot) Notify(ctx context.Context, n server.Notification) (server.NotifyResult, error) {
+	known, err := b.store.KnownChat(n.ChatID)
+	if err != nil {
+		return server.NotifyResult{}, err
+	}
+	if !known {
+		return server.NotifyResult{}, server.ErrUnknownChat
+	}
+	msg := tgbotapi.NewMessage(n.ChatID, n.Text)
+	msg.ParseMode = n.ParseMode
+	sent, err := b.api.Send(msg)
+	if err != nil {
+		return server.NotifyResult{}, err
+	}
+	res := server.NotifyResult{MessageID: sent.MessageID}
+	if n.Symbol == "" {
+		return res, nil
+	}
+
+	cs := b.h.chartSettings(n.ChatID)
+	interval, window := defaultInterval(cs), customWindow(cs)
+	chart, err := finance.MakeChart(ctx, n.Symbol, interval, window, renderOptions(ctx, cs))
+	if err == nil {
+		photo := tgbotapi.NewPhoto(n.ChatID, tgbotapi.FileBytes{Name: n.Symbol + "_" + interval + ".png", Bytes: chart.Image})
+		photo.Caption = demoCaption(n.Symbol + " • " + shownInterval(chart.Meta, interval) + priceLine(cs, chart.Meta))
+		photo.ReplyToMessageID = sent.MessageID
+		_, err = b.api.Send(photo)
+	}
+	if err != nil {
+		logging.FromContext(ctx).Warn("notify: chart failed", "chat_id", n.ChatID, "symbol", n.Symbol, "err", err)
+		res.ChartError = err.Error()
+	}
+	return res, nil
+}
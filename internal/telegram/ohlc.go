@@ -0,0 +1,94 @@
+package telegram
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"telegramBotTrade/internal/finance"
+	"telegramBotTrade/internal/logging"
+	"telegramBotTrade/internal/storage"
+)
+
+const (
+	ohlcDefaultRows = 10
+	// ohlcMaxRows keeps the table well under Telegram's 4096-character limit.
+	ohlcMaxRows = 30
+)
+
+// handleOHLC replies with a monospace table of sym's last daily bars.
+func (h *Handlers) handleOHLC(ctx context.Context, chatID int64, sym, nArg string) {
+	cs := h.chartSettings(chatID)
+	n := ohlcDefaultRows
+	if nArg != "" {
+		v, err := strconv.Atoi(nArg)
+		if err != nil || v < 1 {
+			h.reply(chatID, T(cs, "ohlc.usage", ohlcMaxRows, ohlcDefaultRows))
+			return
+		}
+		if v > ohlcMaxRows {
+			h.reply(chatID, T(cs, "ohlc.too_many", ohlcMaxRows, strings.ToUpper(sym)))
+			return
+		}
+		n = v
+	}
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	// 3 months covers 30 sessions plus the bar before the first row
+	s, err := finance.FetchBars(ctx, sym, "1d", "3m")
+	if err != nil {
+		logging.FromContext(ctx).Error("ohlc failed", "chat_id", chatID, "symbol", sym, "err", err)
+		h.replyFailure(chatID, T(cs, "fetch.failed", sym), err)
+		return
+	}
+	if !s.OHLC {
+		h.reply(chatID, T(cs, "ohlc.no_data", sym))
+		return
+	}
+	msg := tgbotapi.NewMessage(chatID, formatOHLCTable(cs, strings.ToUpper(sym), s.Bars, n))
+	msg.ParseMode = "HTML"
+	h.api.Send(msg)
+}
+
+// formatOHLCTable renders the last n bars, newest first. The change column is
+// close-to-close, so the oldest fetched bar only serves as a reference. The
+// column headings stay English so they line up with the numbers.
+func formatOHLCTable(cs storage.ChatSettings, sym string, bars []finance.Bar, n int) string {
+	start := len(bars) - n
+	if start < 0 {
+		start = 0
+	}
+	var b strings.Builder
+	b.WriteString(T(cs, "ohlc.title", html.EscapeString(sym), len(bars)-start) + "\n<pre>")
+	fmt.Fprintf(&b, "%-10s %9s %9s %9s %9s %7s\n", "Date", "Open", "High", "Low", "Close", "Chg%")
+	for i := len(bars) - 1; i >= start; i-- {
+		bar := bars[i]
+		chg, arrow := "", ""
+		if i > 0 && bars[i-1].Close != 0 {
+			pct := (bar.Close/bars[i-1].Close - 1) * 100
+			chg, arrow = fmt.Sprintf("%+.2f", pct), moveArrow(pct)
+		}
+		fmt.Fprintf(&b, "%-10s %9s %9s %9s %9s %7s %s\n", bar.Time.Format("2006-01-02"),
+			ohlcPrice(bar.Open), ohlcPrice(bar.High), ohlcPrice(bar.Low), ohlcPrice(bar.Close), chg, arrow)
+	}
+	b.WriteString("</pre>")
+	return b.String()
+}
+
+// ohlcPrice formats a price to fit the 9-character columns.
+func ohlcPrice(v float64) string {
+	switch {
+	case v == 0:
+		return "-"
+	case v >= 100000:
+		return strconv.FormatFloat(v, 'f', 0, 64)
+	case v < 1:
+		return strconv.FormatFloat(v, 'f', 4, 64)
+	}
+	return strconv.FormatFloat(v, 'f', 2, 64)
+}
@@ -0,0 +1,38 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"telegramBotTrade/internal/finance"
+	"telegramBotTrade/internal/logging"
+)
+
+// handleOptMove reports the move priced into sym's nearest-expiry ATM straddle.
+func (h *Handlers) handleOptMove(ctx context.Context, chatID int64, sym string) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	cs := h.chartSettings(chatID)
+	m, err := finance.FetchImpliedMove(ctx, sym)
+	if errors.Is(err, finance.ErrNoOptions) {
+		h.reply(chatID, T(cs, "optmove.no_options", strings.ToUpper(sym)))
+		return
+	}
+	if err != nil {
+		logging.FromContext(ctx).Error("optmove failed", "chat_id", chatID, "symbol", sym, "err", err)
+		h.replyFailure(chatID, T(cs, "optmove.failed", strings.ToUpper(sym)), err)
+		return
+	}
+	days := T(cs, "optmove.today")
+	switch {
+	case m.DaysLeft == 1:
+		days = T(cs, "optmove.one_day")
+	case m.DaysLeft > 1:
+		days = T(cs, "optmove.days", m.DaysLeft)
+	}
+	h.reply(chatID, T(cs, "optmove.reply",
+		m.Symbol, m.Expiry.Format("Mon Jan 2"), days, m.Move, m.MovePct,
+		m.Strike, m.Call, m.Put, m.Price))
+}
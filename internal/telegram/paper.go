@@ -0,0 +1,194 @@
+package telegram
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"telegramBotTrade/internal/finance"
+	"telegramBotTrade/internal/logging"
+	"telegramBotTrade/internal/storage"
+)
+
+// handlePaper runs the chat's shared paper-trading book.
+func (h *Handlers) handlePaper(ctx context.Context, m *tgbotapi.Message, args string) {
+	chatID := m.Chat.ID
+	fields := strings.Fields(strings.ToLower(args))
+	if len(fields) == 0 {
+		h.reply(chatID, h.t(chatID, "paper.usage"))
+		return
+	}
+	switch fields[0] {
+	case "buy", "sell":
+		if len(fields) != 3 || !reSymbol.MatchString(fields[1]) {
+			h.reply(chatID, h.t(chatID, "paper.usage"))
+			return
+		}
+		qty, err := strconv.ParseFloat(fields[2], 64)
+		if err != nil || qty <= 0 {
+			h.reply(chatID, h.t(chatID, "paper.bad_qty"))
+			return
+		}
+		h.paperTrade(ctx, m, fields[0] == "buy", strings.ToUpper(fields[1]), qty)
+	case "positions", "pos":
+		h.paperPositions(ctx, chatID)
+	case "pnl":
+		h.paperPnL(ctx, chatID)
+	default:
+		h.reply(chatID, h.t(chatID, "paper.usage"))
+	}
+}
+
+func (h *Handlers) paperFills(chatID int64) ([]finance.PaperFill, error) {
+	trades, err := h.store.FetchPaperTrades(chatID)
+	if err != nil {
+		return nil, err
+	}
+	fills := make([]finance.PaperFill, 0, len(trades))
+	for _, t := range trades {
+		fills = append(fills, finance.PaperFill{Symbol: t.Symbol, Qty: t.Qty, Price: t.Price, Time: time.Unix(t.TS, 0)})
+	}
+	return fills, nil
+}
+
+func (h *Handlers) paperTrade(ctx context.Context, m *tgbotapi.Message, buy bool, sym string, qty float64) {
+	chatID := m.Chat.ID
+	cs := h.chartSettings(chatID)
+	if !buy {
+		fills, err := h.paperFills(chatID)
+		if err != nil {
+			h.reply(chatID, T(cs, "paper.load_failed")+err.Error())
+			return
+		}
+		held := 0.0
+		positions, _ := finance.PaperBook(fills)
+		for _, p := range positions {
+			if p.Symbol == sym {
+				held = p.Qty
+			}
+		}
+		if qty > held+1e-9 {
+			h.reply(chatID, T(cs, "paper.oversold", qty, sym, held))
+			return
+		}
+	}
+	qctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	q, err := finance.FetchQuote(qctx, sym)
+	if err != nil {
+		logging.FromContext(ctx).Error("paper quote failed", "chat_id", chatID, "symbol", sym, "err", err)
+		h.replyFailure(chatID, T(cs, "fetch.failed", sym), err)
+		return
+	}
+	signed, verb := qty, T(cs, "paper.bought")
+	if !buy {
+		signed, verb = -qty, T(cs, "paper.sold")
+	}
+	err = h.store.SavePaperTrade(storage.PaperTrade{
+		ChatID: chatID,
+		UserID: senderID(m),
+		Symbol: sym,
+		Qty:    signed,
+		Price:  q.Price,
+		TS:     time.Now().Unix(),
+	})
+	if err != nil {
+		h.reply(chatID, T(cs, "paper.save_failed")+err.Error())
+		return
+	}
+	h.reply(chatID, T(cs, "paper.filled", verb, qty, sym, q.Price, qty*q.Price))
+}
+
+// paperMarks prices the open positions at live quotes.
+func paperMarks(ctx context.Context, positions []finance.PaperPosition) (map[string]finance.Quote, map[string]error) {
+	var syms []string
+	for _, p := range positions {
+		if p.Qty > 0 {
+			syms = append(syms, p.Symbol)
+		}
+	}
+	ctx, cancel := context.WithTimeout(ctx, 45*time.Second)
+	defer cancel()
+	return finance.FetchQuotes(ctx, syms)
+}
+
+func (h *Handlers) paperPositions(ctx context.Context, chatID int64) {
+	cs := h.chartSettings(chatID)
+	fills, err := h.paperFills(chatID)
+	if err != nil {
+		h.reply(chatID, T(cs, "paper.load_failed")+err.Error())
+		return
+	}
+	positions, cash := finance.PaperBook(fills)
+	quotes, _ := paperMarks(ctx, positions)
+	var b strings.Builder
+	b.WriteString(T(cs, "paper.positions") + "\n<pre>")
+	open := 0
+	for _, p := range positions {
+		if p.Qty == 0 {
+			continue
+		}
+		open++
+		if q, ok := quotes[p.Symbol]; ok {
+			fmt.Fprintf(&b, "%-7s %8g @ %9.2f  last %9.2f  %+10.2f\n", p.Symbol, p.Qty, p.AvgCost, q.Price, (q.Price-p.AvgCost)*p.Qty)
+		} else {
+			fmt.Fprintf(&b, "%-7s %8g @ %9.2f  last       n/a\n", p.Symbol, p.Qty, p.AvgCost)
+		}
+	}
+	if open == 0 {
+		b.WriteString(T(cs, "paper.no_positions") + "\n")
+	}
+	fmt.Fprintf(&b, "\ncash %.2f</pre>", cash)
+	msg := tgbotapi.NewMessage(chatID, b.String())
+	msg.ParseMode = "HTML"
+	h.api.Send(msg)
+}
+
+func (h *Handlers) paperPnL(ctx context.Context, chatID int64) {
+	cs := h.chartSettings(chatID)
+	fills, err := h.paperFills(chatID)
+	if err != nil {
+		h.reply(chatID, T(cs, "paper.load_failed")+err.Error())
+		return
+	}
+	if len(fills) == 0 {
+		h.reply(chatID, T(cs, "paper.none")+" "+T(cs, "paper.usage"))
+		return
+	}
+	positions, cash := finance.PaperBook(fills)
+	quotes, errs := paperMarks(ctx, positions)
+	var realized, unrealized, holdings float64
+	for _, p := range positions {
+		realized += p.Realized
+		if q, ok := quotes[p.Symbol]; ok {
+			unrealized += (q.Price - p.AvgCost) * p.Qty
+			holdings += q.Price * p.Qty
+		} else {
+			// no live quote: carry the position at cost
+			holdings += p.AvgCost * p.Qty
+		}
+	}
+	equity := cash + holdings
+	summary := T(cs, "paper.pnl",
+		realized, unrealized, equity, (equity/finance.PaperStartingCash-1)*100, finance.PaperStartingCash)
+	if len(errs) > 0 {
+		summary += "\n" + T(cs, "paper.at_cost", len(errs))
+	}
+
+	cctx, cancel := context.WithTimeout(ctx, 60*time.Second)
+	defer cancel()
+	cctx, fresh := finance.WithFreshness(cctx)
+	img, _, err := finance.MakePaperEquityChart(cctx, fills, renderOptions(ctx, cs))
+	if err != nil {
+		logging.FromContext(ctx).Warn("paper equity chart failed", "chat_id", chatID, "err", err)
+		h.reply(chatID, summary+"\n\n"+T(cs, "paper.no_curve")+failureText(cs, err))
+		return
+	}
+	photo := tgbotapi.NewPhoto(chatID, tgbotapi.FileBytes{Name: "paper_equity.png", Bytes: img})
+	photo.Caption = demoCaption(freshCaption(cs, summary, fresh))
+	h.api.Send(photo)
+}
@@ -0,0 +1,79 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"telegramBotTrade/internal/logging"
+)
+
+// pinBrief silently pins a freshly sent scheduled brief, first unpinning the
+// one it replaces. When the bot lacks the pin right it explains once and turns
+// auto-pin off for the chat.
+func (h *Handlers) pinBrief(ctx context.Context, chatID int64, messageID int) {
+	logger := logging.FromContext(ctx)
+	cs := h.chartSettings(chatID)
+	if !cs.AutoPin {
+		return
+	}
+	if cs.PinnedMessageID != 0 {
+		// the old brief may already have been unpinned or deleted by hand
+		if _, err := h.api.Request(tgbotapi.UnpinChatMessageConfig{ChatID: chatID, MessageID: cs.PinnedMessageID}); err != nil {
+			logger.Info("pin: unpin previous brief failed", "chat_id", chatID, "message_id", cs.PinnedMessageID, "err", err)
+		}
+	}
+	_, err := h.api.Request(tgbotapi.PinChatMessageConfig{ChatID: chatID, MessageID: messageID, DisableNotification: true})
+	if err != nil {
+		if !pinNotAllowed(err) {
+			logger.Warn("pin: pin brief failed", "chat_id", chatID, "err", err)
+			return
+		}
+		if err := h.settings.Set(chatID, "auto_pin", false); err != nil {
+			logger.Error("pin: disable auto-pin failed", "chat_id", chatID, "err", err)
+			return
+		}
+		h.reply(chatID, T(cs, "pin.no_right"))
+		return
+	}
+	if err := h.settings.Set(chatID, "pinned_message_id", messageID); err != nil {
+		logger.Error("pin: save pinned message failed", "chat_id", chatID, "err", err)
+	}
+}
+
+// pinNotAllowed reports whether a pin failed because the bot lacks the right.
+func pinNotAllowed(err error) bool {
+	var tgErr *tgbotapi.Error
+	if !errors.As(err, &tgErr) {
+		return false
+	}
+	msg := strings.ToLower(tgErr.Message)
+	return tgErr.Code == 403 ||
+		strings.Contains(msg, "not enough rights") ||
+		strings.Contains(msg, "chat_admin_required") ||
+		strings.Contains(msg, "need administrator rights")
+}
+
+// setAutoPin turns pinning of the scheduled morning brief on or off.
+func (h *Handlers) setAutoPin(chatID int64, value string) {
+	var on bool
+	switch value {
+	case "on":
+		on = true
+	case "off":
+	default:
+		h.reply(chatID, h.t(chatID, "pin.usage"))
+		return
+	}
+	if err := h.settings.Set(chatID, "auto_pin", on); err != nil {
+		h.reply(chatID, h.t(chatID, "settings.save_failed")+err.Error())
+		return
+	}
+	if on {
+		h.reply(chatID, h.t(chatID, "pin.on"))
+		return
+	}
+	h.reply(chatID, h.t(chatID, "pin.off"))
+}
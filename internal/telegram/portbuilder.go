@@ -0,0 +1,334 @@
+package telegram
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"telegramBotTrade/internal/storage"
+)
+
+// callbackBuilder prefixes the data of every /portbuilder button.
+const callbackBuilder = "pb:"
+
+const (
+	// portBuilderTTL is how long an untouched build stays usable; each button
+	// press extends it.
+	portBuilderTTL = 10 * time.Minute
+	// portBuilderChoices caps the watchlist symbols offered as buttons.
+	portBuilderChoices = 24
+	// portBuilderStep is the weight change of one +/- press, in percent.
+	portBuilderStep = 5
+)
+
+// portBuilderSymbols bounds a build; each symbol is one row of weight buttons.
+var portBuilderSymbols = symbolLimits{min: 1, max: 10}
+
+// portBuilderWindows are the backtest windows offered in the last step.
+var portBuilderWindows = []string{"6m", "1y", "2y", "5y", "10y", "ytd"}
+
+// The steps of a build, in order.
+const (
+	buildPick = iota
+	buildWeights
+	buildWindow
+	buildConfirm
+)
+
+// portDraft is one user's /portbuilder in progress, shown in message msgID
+// in the language of cs.
+type portDraft struct {
+	cs      storage.ChatSettings
+	msgID   int
+	step    int
+	choices []string // watchlist symbols offered; empty when typed
+	symbols []string
+	weights []int // percent, in portBuilderStep steps
+	window  string
+	expires time.Time
+}
+
+type draftKey struct {
+	chatID, userID int64
+}
+
+// portBuilder holds the builds in progress, keyed by chat and user so members
+// of a group each get their own and can't press each other's buttons. Starting
+// a new build replaces the user's previous one in that chat.
+type portBuilder struct {
+	mu     sync.Mutex
+	ttl    time.Duration
+	drafts map[draftKey]*portDraft
+}
+
+func newPortBuilder(ttl time.Duration) *portBuilder {
+	return &portBuilder{ttl: ttl, drafts: map[draftKey]*portDraft{}}
+}
+
+// start stores d for the user, dropping expired builds as the map grows.
+func (p *portBuilder) start(chatID, userID int64, d *portDraft, now time.Time) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if len(p.drafts) >= 1000 {
+		for k, old := range p.drafts {
+			if !now.Before(old.expires) {
+				delete(p.drafts, k)
+			}
+		}
+	}
+	d.expires = now.Add(p.ttl)
+	p.drafts[draftKey{chatID, userID}] = d
+}
+
+// handlePortBuilder starts a build: with symbols it goes straight to the
+// weights, otherwise the chat's watchlist is offered to pick from.
+func (h *Handlers) handlePortBuilder(chatID, userID int64, args string) {
+	d := &portDraft{cs: h.chartSettings(chatID), step: buildWeights}
+	if strings.TrimSpace(args) != "" {
+		syms, err := parseSymbolList(d.cs, args, portBuilderSymbols, "/portbuilder SPY TLT GLD")
+		if err != nil {
+			h.reply(chatID, err.Error())
+			return
+		}
+		d.pickAll(syms)
+	} else {
+		list, err := h.store.FetchWatchlist(chatID)
+		if err != nil {
+			h.reply(chatID, T(d.cs, "watch.load_failed")+err.Error())
+			return
+		}
+		if len(list) == 0 {
+			h.reply(chatID, T(d.cs, "builder.empty_watchlist"))
+			return
+		}
+		d.step = buildPick
+		d.choices = list[:min(len(list), portBuilderChoices)]
+	}
+	text, markup := d.view()
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ReplyMarkup = markup
+	sent, err := h.api.Send(msg)
+	if err != nil {
+		return
+	}
+	d.msgID = sent.MessageID
+	h.builds.start(chatID, userID, d, time.Now())
+}
+
+// portBuilderCallback applies a /portbuilder button press and redraws the
+// build. It returns the /port command to run once the user confirms, or "".
+// Presses on another user's build, a replaced one or an expired one only get
+// a notice.
+func (h *Handlers) portBuilderCallback(cq *tgbotapi.CallbackQuery) string {
+	if cq.Message == nil || cq.From == nil {
+		_, _ = h.api.Request(tgbotapi.NewCallback(cq.ID, ""))
+		return ""
+	}
+	chatID, msgID := cq.Message.Chat.ID, cq.Message.MessageID
+	key := draftKey{chatID, cq.From.ID}
+	action := strings.TrimPrefix(cq.Data, callbackBuilder)
+	now := time.Now()
+
+	p := h.builds
+	p.mu.Lock()
+	d := p.drafts[key]
+	if d == nil || d.msgID != msgID || !now.Before(d.expires) {
+		p.mu.Unlock()
+		_, _ = h.api.Request(tgbotapi.NewCallback(cq.ID, h.t(chatID, "builder.expired")))
+		return ""
+	}
+	var cmd string
+	switch action {
+	case "x":
+		delete(p.drafts, key)
+	case "run":
+		if d.step == buildConfirm {
+			cmd = d.command()
+			delete(p.drafts, key)
+		}
+	}
+	notice := ""
+	if action != "x" && cmd == "" {
+		notice = d.apply(action)
+		d.expires = now.Add(p.ttl)
+	}
+	text, markup := d.view()
+	p.mu.Unlock()
+
+	_, _ = h.api.Request(tgbotapi.NewCallback(cq.ID, notice))
+	switch {
+	case action == "x":
+		h.api.Send(tgbotapi.NewEditMessageText(chatID, msgID, T(d.cs, "builder.cancelled")))
+	case cmd != "":
+		h.api.Send(tgbotapi.NewEditMessageText(chatID, msgID, T(d.cs, "builder.running", cmd)))
+	case notice == "" && action != "nop":
+		h.api.Send(tgbotapi.NewEditMessageTextAndMarkup(chatID, msgID, text, markup))
+	}
+	return cmd
+}
+
+// apply performs one button action on d and returns a notice for the user
+// when the action was refused.
+func (d *portDraft) apply(action string) string {
+	verb, arg, _ := strings.Cut(action, ":")
+	i, err := strconv.Atoi(arg)
+	switch verb {
+	case "t": // toggle a watchlist symbol
+		if d.step != buildPick || err != nil || i < 0 || i >= len(d.choices) {
+			return ""
+		}
+		sym := d.choices[i]
+		if j := slices.Index(d.symbols, sym); j >= 0 {
+			d.symbols = slices.Delete(d.symbols, j, j+1)
+			return ""
+		}
+		if len(d.symbols) >= portBuilderSymbols.max {
+			return T(d.cs, "builder.too_many", portBuilderSymbols.max)
+		}
+		d.symbols = append(d.symbols, sym)
+	case "+", "-": // change a weight
+		if d.step != buildWeights || err != nil || i < 0 || i >= len(d.weights) {
+			return ""
+		}
+		if verb == "+" {
+			d.weights[i] = min(d.weights[i]+portBuilderStep, 100)
+		} else {
+			d.weights[i] = max(d.weights[i]-portBuilderStep, 0)
+		}
+	case "next":
+		switch d.step {
+		case buildPick:
+			if len(d.symbols) == 0 {
+				return T(d.cs, "builder.pick_one")
+			}
+			d.pickAll(d.symbols)
+		case buildWeights:
+			switch total := d.total(); {
+			case total == 0:
+				return T(d.cs, "builder.no_weight")
+			case total > 100:
+				return T(d.cs, "builder.over", total)
+			}
+			d.step = buildWindow
+		}
+	case "win":
+		if d.step != buildWindow || !slices.Contains(portBuilderWindows, arg) {
+			return ""
+		}
+		d.window = arg
+		d.step = buildConfirm
+	case "back":
+		if d.step > buildWeights || (d.step == buildWeights && len(d.choices) > 0) {
+			d.step--
+		}
+	}
+	return ""
+}
+
+// pickAll moves to the weights step with syms weighted equally, rounded down
+// to a whole step so the total never exceeds 100%.
+func (d *portDraft) pickAll(syms []string) {
+	d.symbols = syms
+	d.weights = make([]int, len(syms))
+	w := 100 / len(syms) / portBuilderStep * portBuilderStep
+	for i := range d.weights {
+		d.weights[i] = w
+	}
+	d.step = buildWeights
+}
+
+func (d *portDraft) total() int {
+	total := 0
+	for _, w := range d.weights {
+		total += w
+	}
+	return total
+}
+
+// command is the /port command for the build; symbols left at 0% are
+// dropped and whatever the weights leave under 100% is held as cash.
+func (d *portDraft) command() string {
+	parts := []string{"/port"}
+	for i, sym := range d.symbols {
+		if d.weights[i] > 0 {
+			parts = append(parts, sym, fmt.Sprintf("%d%%", d.weights[i]))
+		}
+	}
+	return strings.Join(append(parts, d.window), " ")
+}
+
+// view renders the message text and buttons of d's current step.
+func (d *portDraft) view() (string, tgbotapi.InlineKeyboardMarkup) {
+	button := func(label, action string) tgbotapi.InlineKeyboardButton {
+		return tgbotapi.NewInlineKeyboardButtonData(label, callbackBuilder+action)
+	}
+	cancel := button(T(d.cs, "builder.cancel"), "x")
+	var text string
+	var rows [][]tgbotapi.InlineKeyboardButton
+	switch d.step {
+	case buildPick:
+		text = T(d.cs, "builder.step_pick", portBuilderSymbols.max)
+		var row []tgbotapi.InlineKeyboardButton
+		for i, sym := range d.choices {
+			label := sym
+			if slices.Contains(d.symbols, sym) {
+				label = "✅ " + sym
+			}
+			row = append(row, button(label, "t:"+strconv.Itoa(i)))
+			if len(row) == 3 {
+				rows, row = append(rows, row), nil
+			}
+		}
+		if len(row) > 0 {
+			rows = append(rows, row)
+		}
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(button(T(d.cs, "builder.weights"), "next"), cancel))
+	case buildWeights:
+		text = T(d.cs, "builder.step_weights", portBuilderStep) + "\n" + d.totalLine()
+		for i, sym := range d.symbols {
+			n := strconv.Itoa(i)
+			rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+				button("−", "-:"+n),
+				button(fmt.Sprintf("%s %d%%", sym, d.weights[i]), "nop"),
+				button("+", "+:"+n),
+			))
+		}
+		nav := []tgbotapi.InlineKeyboardButton{button(T(d.cs, "builder.window"), "next"), cancel}
+		if len(d.choices) > 0 {
+			nav = append([]tgbotapi.InlineKeyboardButton{button(T(d.cs, "builder.back"), "back")}, nav...)
+		}
+		rows = append(rows, nav)
+	case buildWindow:
+		text = T(d.cs, "builder.step_window") + "\n" + d.totalLine()
+		half := len(portBuilderWindows) / 2
+		for _, ws := range [][]string{portBuilderWindows[:half], portBuilderWindows[half:]} {
+			var row []tgbotapi.InlineKeyboardButton
+			for _, w := range ws {
+				row = append(row, button(w, "win:"+w))
+			}
+			rows = append(rows, row)
+		}
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(button(T(d.cs, "builder.back"), "back"), cancel))
+	case buildConfirm:
+		text = T(d.cs, "builder.confirm", d.command()) + "\n" + d.totalLine()
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(button(T(d.cs, "builder.run"), "run"), button(T(d.cs, "builder.back"), "back"), cancel))
+	}
+	return text, tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
+
+// totalLine is the running total, e.g. "Total: 90% (10% cash)".
+func (d *portDraft) totalLine() string {
+	switch total := d.total(); {
+	case total > 100:
+		return T(d.cs, "builder.total_over", total)
+	case total == 100:
+		return T(d.cs, "builder.total_full")
+	default:
+		return T(d.cs, "builder.total", total, 100-total)
+	}
+}
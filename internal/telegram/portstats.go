@@ -0,0 +1,94 @@
+package telegram
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"telegramBotTrade/internal/finance"
+	"telegramBotTrade/internal/logging"
+	"telegramBotTrade/internal/storage"
+)
+
+// portfolioComposition describes weights the way /port captions do, e.g.
+// "SPY 60.0%, TLT 30.0%, Cash 10.0%".
+func portfolioComposition(cs storage.ChatSettings, syms []string, weights []float64) string {
+	parts := make([]string, 0, len(syms)+1)
+	total := 0.0
+	for i, symbol := range syms {
+		weight := weights[i]
+		total += weight
+		if weight >= 0 {
+			parts = append(parts, fmt.Sprintf("%s %.1f%%", symbol, weight*100))
+		} else {
+			parts = append(parts, T(cs, "port.short", symbol, -weight*100))
+		}
+	}
+	if cashPct := (1.0 - total) * 100; cashPct > 0.05 {
+		parts = append(parts, T(cs, "port.cash", cashPct))
+	} else if cashPct < -0.05 {
+		parts = append(parts, T(cs, "port.margin", -cashPct))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// handlePortStats replies with a weighted portfolio's statistics as text,
+// skipping the chart /port would render.
+func (h *Handlers) handlePortStats(ctx context.Context, chatID int64, syms []string, weights []float64, window string) {
+	ctx, fresh := finance.WithFreshness(ctx)
+	cs := h.chartSettings(chatID)
+	stats, err := finance.WeightedPortfolioStats(ctx, syms, weights, window)
+	if err != nil {
+		logging.FromContext(ctx).Error("portstats failed", "chat_id", chatID, "symbols", syms, "err", err)
+		h.replyFailure(chatID, T(cs, "portstats.failed"), err)
+		return
+	}
+	text := html.EscapeString(portfolioComposition(cs, syms, weights)) + " • " + strings.ToUpper(window) + "\n" +
+		formatPortStats(cs, stats)
+	msg := tgbotapi.NewMessage(chatID, demoCaption(freshCaption(cs, text, fresh)))
+	msg.ParseMode = "HTML"
+	h.api.Send(msg)
+	markDelivered(ctx)
+}
+
+// formatPortStats lays out the statistics as an aligned <pre> block; dates
+// are shown in the chat's clock. The labels stay English so the columns
+// line up in any language.
+func formatPortStats(cs storage.ChatSettings, st *finance.PortfolioStats) string {
+	loc := chatClock(cs)
+	date := func(t time.Time) string {
+		if t.IsZero() {
+			return ""
+		}
+		return " " + t.In(loc).Format("Jan 2 2006")
+	}
+	var b strings.Builder
+	b.WriteString("<pre>")
+	fmt.Fprintf(&b, "Return       %+8.2f%%\n", st.TotalReturn)
+	fmt.Fprintf(&b, "CAGR         %+8.2f%%\n", st.AnnualReturn)
+	fmt.Fprintf(&b, "Volatility   %8.2f%%\n", st.Volatility)
+	fmt.Fprintf(&b, "Sharpe       %8.2f\n", st.SharpeRatio)
+	fmt.Fprintf(&b, "Sortino      %8.2f\n", st.SortinoRatio)
+	fmt.Fprintf(&b, "Calmar       %8.2f\n", st.CalmarRatio)
+	fmt.Fprintf(&b, "Max drawdown %8.2f%%", -st.MaxDrawdown)
+	if !st.MaxDDPeak.IsZero() {
+		fmt.Fprintf(&b, "%s →%s", date(st.MaxDDPeak), date(st.MaxDDTrough))
+	}
+	fmt.Fprintf(&b, "\nBest day     %+8.2f%%%s\n", st.BestDay, date(st.BestDayAt))
+	fmt.Fprintf(&b, "Worst day    %+8.2f%%%s\n", st.WorstDay, date(st.WorstDayAt))
+	fmt.Fprintf(&b, "Observations %8d days", st.NumDays)
+	for i, s := range st.Starts {
+		label := ""
+		if i == 0 {
+			label = "Data from"
+		}
+		fmt.Fprintf(&b, "\n%-12s %-8s %s", label, html.EscapeString(s.Symbol), s.Start.In(loc).Format("2006-01-02"))
+	}
+	b.WriteString("</pre>")
+	b.WriteString(html.EscapeString(truncatedLine(cs, st)))
+	return b.String()
+}
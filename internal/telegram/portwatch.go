@@ -0,0 +1,259 @@
+package telegram
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"telegramBotTrade/internal/finance"
+	"telegramBotTrade/internal/logging"
+	"telegramBotTrade/internal/storage"
+)
+
+const (
+	// portWatchDefaultDD is the drawdown alert threshold when dd= is left out.
+	portWatchDefaultDD = 10
+	// portWatchMax caps the portfolio watches of one chat.
+	portWatchMax = 10
+	// portWatchSettle is how long after the close the day's valuation waits,
+	// so the quotes are the official closes.
+	portWatchSettle = 20 * time.Minute
+	// portWatchStaleQuote is the age past which a quote no longer counts as
+	// the constituent's latest close (halted or delisted).
+	portWatchStaleQuote = 7 * 24 * time.Hour
+)
+
+var (
+	rePortWatchName = regexp.MustCompile(`^[a-z0-9_-]{1,32}$`)
+	rePortWatchDD   = regexp.MustCompile(`^dd=(\d+(?:\.\d+)?)%?$`)
+)
+
+// handlePortWatch manages the chat's portfolio drawdown watches; verb is
+// "watch" or "unwatch".
+func (h *Handlers) handlePortWatch(ctx context.Context, chatID int64, verb, args string) {
+	cs := h.chartSettings(chatID)
+	fields := strings.Fields(args)
+	if verb == "unwatch" {
+		if len(fields) != 1 {
+			h.reply(chatID, T(cs, "portwatch.usage"))
+			return
+		}
+		ok, err := h.store.DeletePortfolioWatch(chatID, strings.ToLower(fields[0]))
+		switch {
+		case err != nil:
+			h.reply(chatID, T(cs, "portwatch.delete_failed")+err.Error())
+		case !ok:
+			h.reply(chatID, T(cs, "portwatch.unknown", fields[0]))
+		default:
+			h.reply(chatID, T(cs, "portwatch.removed", strings.ToLower(fields[0])))
+		}
+		return
+	}
+	if len(fields) == 0 || (len(fields) == 1 && fields[0] == "list") {
+		h.listPortWatches(chatID)
+		return
+	}
+	name := strings.ToLower(fields[0])
+	if !rePortWatchName.MatchString(name) {
+		h.reply(chatID, T(cs, "portwatch.bad_name")+"\n\n"+T(cs, "portwatch.usage"))
+		return
+	}
+	threshold := float64(portWatchDefaultDD)
+	var input []string
+	for _, f := range fields[1:] {
+		if g := rePortWatchDD.FindStringSubmatch(strings.ToLower(f)); g != nil {
+			threshold, _ = strconv.ParseFloat(g[1], 64)
+			continue
+		}
+		input = append(input, f)
+	}
+	if threshold < 1 || threshold > 90 {
+		h.reply(chatID, T(cs, "portwatch.bad_dd"))
+		return
+	}
+	if len(input) == 0 {
+		if len(fields) == 1 {
+			h.reply(chatID, T(cs, "portwatch.usage"))
+			return
+		}
+		ok, err := h.store.SetPortfolioWatchThreshold(chatID, name, threshold)
+		switch {
+		case err != nil:
+			h.reply(chatID, T(cs, "portwatch.update_failed")+err.Error())
+		case !ok:
+			h.reply(chatID, T(cs, "portwatch.not_yet", name, name, threshold))
+		default:
+			h.reply(chatID, T(cs, "portwatch.threshold", name, threshold))
+		}
+		return
+	}
+	symbols, weights, _, err := finance.ParseWeightedPortfolio(strings.Join(input, " "))
+	if err != nil || len(symbols) == 0 {
+		if err == nil {
+			err = fmt.Errorf("no holdings")
+		}
+		h.reply(chatID, T(cs, "portfolio.bad_format", err)+"\n\n"+T(cs, "portwatch.usage"))
+		return
+	}
+	existing, err := h.store.FetchPortfolioWatches(chatID)
+	if err != nil {
+		h.reply(chatID, T(cs, "portwatch.load_failed")+err.Error())
+		return
+	}
+	if len(existing) >= portWatchMax && !slices.ContainsFunc(existing, func(w storage.PortfolioWatch) bool { return w.Name == name }) {
+		h.reply(chatID, T(cs, "portwatch.too_many", portWatchMax))
+		return
+	}
+
+	qctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	quotes, errs := finance.FetchBatchQuotes(qctx, symbols)
+	cancel()
+	base := make([]float64, len(symbols))
+	for i, sym := range symbols {
+		q, ok := quotes[sym]
+		if !ok || q.Price <= 0 {
+			logging.FromContext(ctx).Error("port watch quote failed", "chat_id", chatID, "symbol", sym, "err", errs[sym])
+			h.reply(chatID, T(cs, "portwatch.no_price", sym))
+			return
+		}
+		base[i] = q.Price
+	}
+	w := storage.PortfolioWatch{
+		ChatID:    chatID,
+		Name:      name,
+		Symbols:   symbols,
+		Weights:   weights,
+		Base:      base,
+		Threshold: threshold,
+		Value:     100,
+		Peak:      100,
+		CreatedAt: time.Now().Unix(),
+	}
+	replaced, err := h.store.SavePortfolioWatch(w)
+	if err != nil {
+		h.reply(chatID, T(cs, "portwatch.save_failed")+err.Error())
+		return
+	}
+	saved := "portwatch.saved"
+	if replaced {
+		saved = "portwatch.replaced"
+	}
+	h.reply(chatID, T(cs, saved, name, holdingsText(w), threshold))
+}
+
+func (h *Handlers) listPortWatches(chatID int64) {
+	cs := h.chartSettings(chatID)
+	list, err := h.store.FetchPortfolioWatches(chatID)
+	if err != nil {
+		h.reply(chatID, T(cs, "portwatch.load_failed")+err.Error())
+		return
+	}
+	if len(list) == 0 {
+		h.reply(chatID, T(cs, "portwatch.none")+" "+T(cs, "portwatch.usage"))
+		return
+	}
+	var b strings.Builder
+	b.WriteString(T(cs, "portwatch.title") + "\n")
+	for _, w := range list {
+		fmt.Fprintf(&b, "\n%s: %s • dd=%g%%", w.Name, holdingsText(w), w.Threshold)
+		if w.ValuedDay == "" {
+			b.WriteString("\n   " + T(cs, "portwatch.unvalued"))
+			continue
+		}
+		b.WriteString("\n   " + T(cs, "portwatch.valued", w.Value, w.Peak, drawdownPct(w.Value, w.Peak), w.ValuedDay))
+	}
+	h.reply(chatID, b.String())
+}
+
+// holdingsText lists a watch's weights, e.g. "SPY 60%, TLT 40%".
+func holdingsText(w storage.PortfolioWatch) string {
+	parts := make([]string, len(w.Symbols))
+	for i, sym := range w.Symbols {
+		parts[i] = fmt.Sprintf("%s %.4g%%", sym, w.Weights[i]*100)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// drawdownPct is how far value is below peak, in percent.
+func drawdownPct(value, peak float64) float64 {
+	if peak <= 0 || value >= peak {
+		return 0
+	}
+	return (1 - value/peak) * 100
+}
+
+// checkPortfolioWatches values every portfolio watch once per US trading day
+// after the close and alerts a chat when a portfolio's drawdown from its
+// running peak reaches the threshold, once per breach: the alert re-arms when
+// the drawdown recovers below the threshold. A watch with a holding whose
+// quote is missing or stale is left unvalued and retried on the next poll
+// rather than valued without it.
+func (b *Bot) checkPortfolioWatches(ctx context.Context, now time.Time) {
+	logger := logging.FromContext(ctx)
+	_, closeAt, ok := finance.USSession(now)
+	if !ok || now.Before(closeAt.Add(portWatchSettle)) {
+		return
+	}
+	day := closeAt.Format(time.DateOnly)
+	watches, err := b.store.FetchUnvaluedPortfolioWatches(day)
+	if err != nil {
+		logger.Error("alerts: load portfolio watches failed", "err", err)
+		return
+	}
+	// watches of inactive chats are valued once the chat is back
+	watches = slices.DeleteFunc(watches, func(w storage.PortfolioWatch) bool { return !b.h.chats.active(w.ChatID) })
+	if len(watches) == 0 {
+		return
+	}
+	var all []string
+	for _, w := range watches {
+		all = append(all, w.Symbols...)
+	}
+	slices.Sort(all)
+	qctx, cancel := context.WithTimeout(ctx, time.Minute)
+	quotes, _ := finance.FetchBatchQuotes(qctx, slices.Compact(all))
+	cancel()
+	for _, w := range watches {
+		current := make([]float64, len(w.Symbols))
+		var missing []string
+		for i, sym := range w.Symbols {
+			q, ok := quotes[sym]
+			if !ok || q.Price <= 0 || (!q.Time.IsZero() && now.Sub(q.Time) > portWatchStaleQuote) {
+				missing = append(missing, sym)
+				continue
+			}
+			current[i] = q.Price
+		}
+		if len(missing) > 0 {
+			logger.Warn("alerts: portfolio watch not valued, quotes missing", "chat_id", w.ChatID, "watch", w.Name, "symbols", missing)
+			continue
+		}
+		value, err := finance.PortfolioValue(w.Symbols, w.Weights, w.Base, current)
+		if err != nil {
+			logger.Error("alerts: portfolio valuation failed", "chat_id", w.ChatID, "watch", w.Name, "err", err)
+			continue
+		}
+		peak := max(w.Peak, value)
+		dd := drawdownPct(value, peak)
+		alertedAt, alert := w.AlertedAt, false
+		switch {
+		case dd < w.Threshold:
+			alertedAt = 0
+		case alertedAt == 0:
+			alertedAt, alert = now.Unix(), true
+		}
+		if err := b.store.SavePortfolioValuation(w.ID, value, peak, day, alertedAt); err != nil {
+			logger.Error("alerts: save portfolio valuation failed", "chat_id", w.ChatID, "watch", w.Name, "err", err)
+			continue
+		}
+		if alert {
+			b.h.reply(w.ChatID, b.h.t(w.ChatID, "portwatch.alert",
+				w.Name, dd, value, peak, day, w.Threshold, holdingsText(w)))
+			logger.Info("alerts: portfolio drawdown", "chat_id", w.ChatID, "watch", w.Name, "drawdown", dd)
+		}
+	}
+}
@@ -0,0 +1,253 @@
+package telegram
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+
+	"telegramBotTrade/internal/finance"
+	"telegramBotTrade/internal/logging"
+	"telegramBotTrade/internal/storage"
+)
+
+const (
+	recapDefaultDays = 7
+	recapMaxDays     = 30
+	// recapTop is how many tickers the recap table lists.
+	recapTop = 10
+	// recapChartTop is how many of them the optional indexed chart draws.
+	recapChartTop = 5
+	// recapMaxLookups bounds the symbol searches spent on bare uppercase words.
+	recapMaxLookups = 25
+)
+
+var (
+	// reCashtag matches $TSLA, $brk.b, $BTC-USD or $GC=F, but not $100.
+	reCashtag = regexp.MustCompile(`\$([A-Za-z][A-Za-z0-9]{0,5}(?:[.\-][A-Za-z]{1,3}|=[Ff])?)\b`)
+	// reBareTicker matches a standalone 2-5 letter uppercase word such as TSLA.
+	reBareTicker = regexp.MustCompile(`\b[A-Z]{2,5}\b`)
+	reURL        = regexp.MustCompile(`(?i)\b(?:https?://|www\.)\S+`)
+)
+
+// notTickers are uppercase words common in market chat that are rarely meant
+// as a ticker even when one exists (CEO, USA, ...). A cashtag still counts.
+var notTickers = map[string]bool{
+	"CEO": true, "CFO": true, "CTO": true, "COO": true, "USA": true, "US": true, "UK": true, "EU": true,
+	"IPO": true, "ETF": true, "ETFS": true, "GDP": true, "CPI": true, "PPI": true, "PCE": true, "NFP": true,
+	"FED": true, "FOMC": true, "ECB": true, "BOJ": true, "SEC": true, "IRS": true, "AI": true, "API": true,
+	"ATH": true, "ATL": true, "EPS": true, "PE": true, "YOY": true, "QOQ": true, "MOM": true, "YTD": true,
+	"EOD": true, "EOW": true, "AH": true, "PM": true, "AM": true, "IMO": true, "IMHO": true, "LOL": true,
+	"OMG": true, "WTF": true, "FYI": true, "TBH": true, "BTW": true, "DD": true, "YOLO": true, "FOMO": true,
+	"HODL": true, "OK": true, "NY": true, "NYC": true, "LA": true, "SF": true, "USD": true, "EUR": true,
+	"JPY": true, "GBP": true, "CNY": true, "HKD": true, "SGD": true, "THE": true, "AND": true, "FOR": true,
+	"NOT": true, "BUY": true, "SELL": true, "HOLD": true, "LONG": true, "SHORT": true, "CALL": true,
+	"CALLS": true, "PUT": true, "PUTS": true, "TA": true, "FA": true, "OTM": true, "ITM": true, "ATM": true,
+	"IV": true, "DTE": true, "RSI": true, "MACD": true, "EMA": true, "SMA": true, "VWAP": true, "ATR": true,
+	"TLDR": true, "ASAP": true, "NEWS": true, "WSB": true, "IT": true, "IS": true, "SO": true, "GO": true,
+	"BE": true, "DO": true, "NO": true, "ON": true, "ARE": true, "ALL": true, "NEW": true, "NOW": true,
+	"ONE": true, "OUT": true, "CAN": true, "HAS": true, "LOW": true, "HIGH": true, "BIG": true,
+}
+
+// tickerMentions returns the distinct tickers text mentions: every cashtag,
+// and uppercase words that look like a ticker. Bare words are left out when
+// the whole message is shouted, since every word is uppercase then. The
+// caller validates bare words before counting them.
+func tickerMentions(text string) (cashtags, bare []string) {
+	text = reURL.ReplaceAllString(text, " ")
+	for _, g := range reCashtag.FindAllStringSubmatch(text, -1) {
+		if sym := strings.ToUpper(g[1]); !slices.Contains(cashtags, sym) {
+			cashtags = append(cashtags, sym)
+		}
+	}
+	text = reCashtag.ReplaceAllString(text, " ")
+	if shouted(text) {
+		return cashtags, nil
+	}
+	for _, w := range reBareTicker.FindAllString(text, -1) {
+		if !notTickers[w] && !slices.Contains(cashtags, w) && !slices.Contains(bare, w) {
+			bare = append(bare, w)
+		}
+	}
+	return cashtags, bare
+}
+
+// shouted reports whether a message of several words is mostly upper case.
+func shouted(text string) bool {
+	if len(strings.Fields(text)) < 4 {
+		return false
+	}
+	var upper, letters int
+	for _, r := range text {
+		if unicode.IsLetter(r) {
+			letters++
+			if unicode.IsUpper(r) {
+				upper++
+			}
+		}
+	}
+	return letters > 0 && upper*10 > letters*6
+}
+
+// tickerCount is how many messages mentioned a ticker; Tagged is set once any
+// of them used a cashtag.
+type tickerCount struct {
+	Symbol string
+	Count  int
+	Tagged bool
+	Return float64
+	HasRet bool
+}
+
+// countMentions tallies the messages mentioning each ticker, most discussed first.
+func countMentions(msgs []storage.Message) []tickerCount {
+	byTicker := map[string]*tickerCount{}
+	var order []*tickerCount
+	add := func(sym string, tagged bool) {
+		tc, ok := byTicker[sym]
+		if !ok {
+			tc = &tickerCount{Symbol: sym}
+			byTicker[sym] = tc
+			order = append(order, tc)
+		}
+		tc.Count++
+		tc.Tagged = tc.Tagged || tagged
+	}
+	for _, m := range msgs {
+		cashtags, bare := tickerMentions(m.Text)
+		for _, s := range cashtags {
+			add(s, true)
+		}
+		for _, s := range bare {
+			add(s, false)
+		}
+	}
+	out := make([]tickerCount, len(order))
+	for i, tc := range order {
+		out[i] = *tc
+	}
+	slices.SortStableFunc(out, func(a, b tickerCount) int { return b.Count - a.Count })
+	return out
+}
+
+// handleRecap posts the tickers the chat discussed most over the last days,
+// with each one's return over the same period and optionally an indexed
+// chart of the top ones.
+func (h *Handlers) handleRecap(ctx context.Context, chatID int64, days int, withChart bool) {
+	cs := h.chartSettings(chatID)
+	if !cs.StoreMessages {
+		h.reply(chatID, T(cs, "recap.storage_off"))
+		return
+	}
+	logger := logging.FromContext(ctx)
+	since := time.Now().Add(-time.Duration(days) * 24 * time.Hour)
+	h.flushMessages(ctx)
+	msgs, err := h.store.FetchMessages(chatID, storage.AllThreads, since.Unix())
+	if err != nil {
+		logger.Error("recap: fetch failed", "chat_id", chatID, "err", err)
+		h.reply(chatID, T(cs, "actions.history_failed")+err.Error())
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, 90*time.Second)
+	defer cancel()
+
+	var top []tickerCount
+	lookups := 0
+	for _, tc := range countMentions(msgs) {
+		if len(top) == recapTop {
+			break
+		}
+		if !tc.Tagged {
+			if lookups == recapMaxLookups {
+				continue
+			}
+			lookups++
+			if ok, err := finance.IsListedSymbol(ctx, tc.Symbol); err != nil || !ok {
+				if err != nil {
+					logger.Warn("recap: symbol lookup failed", "symbol", tc.Symbol, "err", err)
+				}
+				continue
+			}
+		}
+		tc.Return, tc.HasRet = periodReturn(ctx, tc.Symbol, since)
+		if !tc.HasRet && tc.Tagged {
+			// a cashtag Yahoo has no prices for is most likely a typo
+			if ok, _ := finance.IsListedSymbol(ctx, tc.Symbol); !ok {
+				continue
+			}
+		}
+		top = append(top, tc)
+	}
+	if len(top) == 0 {
+		h.reply(chatID, T(cs, "recap.none", days, len(msgs)))
+		return
+	}
+
+	var b strings.Builder
+	b.WriteString(T(cs, "recap.title", days, len(msgs)) + "\n")
+	for i, tc := range top {
+		ret := "n/a"
+		if tc.HasRet {
+			ret = fmt.Sprintf("%+.1f%%", tc.Return)
+		}
+		line := "recap.mentions"
+		if tc.Count == 1 {
+			line = "recap.mention"
+		}
+		fmt.Fprintf(&b, "\n%d. %s", i+1, T(cs, line, tc.Symbol, tc.Count, ret))
+	}
+	h.reply(chatID, b.String())
+	logger.Info("recap: posted", "chat_id", chatID, "days", days, "tickers", len(top), "lookups", lookups)
+
+	if !withChart {
+		return
+	}
+	var syms []string
+	for _, tc := range top {
+		if tc.HasRet && len(syms) < recapChartTop {
+			syms = append(syms, tc.Symbol)
+		}
+	}
+	if len(syms) == 0 {
+		return
+	}
+	interval, window := "1d", "1m"
+	if days <= 5 {
+		interval, window = "1h", "5d"
+	}
+	opts := renderOptions(ctx, cs)
+	res, err := finance.MakeIndexedChart(ctx, syms, interval, window, true, opts)
+	if err != nil {
+		logger.Error("recap: chart failed", "chat_id", chatID, "err", err)
+		h.replyFailure(chatID, T(cs, "recap.failed"), err)
+		return
+	}
+	skipped := res.Meta.Skipped
+	logSkipped(ctx, skipped)
+	caption := T(cs, "recap.caption", strings.Join(chartedSymbols(syms, skipped), ", "), interval, window) + skippedNote(cs, skipped)
+	h.sendChart(chatID, "recap", caption, res.Image, opts)
+}
+
+// periodReturn is symbol's percentage change from the last daily close before
+// since to the latest close.
+func periodReturn(ctx context.Context, symbol string, since time.Time) (float64, bool) {
+	s, err := finance.FetchBars(ctx, symbol, "1d", "3m")
+	if err != nil || len(s.Bars) < 2 {
+		return 0, false
+	}
+	base := s.Bars[0].Close
+	for _, bar := range s.Bars {
+		if !bar.Time.Before(since) {
+			break
+		}
+		base = bar.Close
+	}
+	last := s.Bars[len(s.Bars)-1].Close
+	if base == 0 {
+		return 0, false
+	}
+	return (last/base - 1) * 100, true
+}
@@ -0,0 +1,73 @@
+package telegram
+
+import (
+	"slices"
+	"testing"
+
+	"telegramBotTrade/internal/storage"
+)
+
+func TestTickerMentions(t *testing.T) {
+	tests := []struct {
+		text           string
+		cashtags, bare []string
+	}{
+		{"The CEO of TSLA said USA sales are up", nil, []string{"TSLA"}},
+		{"IPO news from the SEC, FYI", nil, nil},
+		{"$ceo reports tomorrow", []string{"CEO"}, nil}, // a cashtag is never filtered
+		{"$TSLA vs TSLA vs tsla", []string{"TSLA"}, nil},
+		{"$brk.b $BTC-USD $GC=F and $100 of calls", []string{"BRK.B", "BTC-USD", "GC=F"}, nil},
+		{"NVDA then AMD then NVDA", nil, []string{"NVDA", "AMD"}},
+		{"see https://example.com/NVDA/chart and AMD", nil, []string{"AMD"}},
+		{"www.MSFT.com is down", nil, nil},
+		{"WOW NVDA IS RIPPING TODAY", nil, nil},               // shouted: every word is uppercase
+		{"WOW $NVDA IS RIPPING TODAY", []string{"NVDA"}, nil}, // but a cashtag still counts
+		{"NVDA AMD", nil, []string{"NVDA", "AMD"}},            // too short to be shouting
+		{"I like F and GOOGLE", nil, nil},                     // outside 2-5 letters
+		{"nothing to see here", nil, nil},
+	}
+	for _, tc := range tests {
+		cashtags, bare := tickerMentions(tc.text)
+		if !slices.Equal(cashtags, tc.cashtags) || !slices.Equal(bare, tc.bare) {
+			t.Errorf("tickerMentions(%q) = %v, %v; want %v, %v", tc.text, cashtags, bare, tc.cashtags, tc.bare)
+		}
+	}
+}
+
+func TestShouted(t *testing.T) {
+	tests := []struct {
+		text string
+		want bool
+	}{
+		{"BUY THE DIP NOW", true},
+		{"BUY THE DIP", false}, // fewer than four words
+		{"Buy the dip now", false},
+		{"AAPL MSFT NVDA and some more words here", false},
+		{"1 2 3 4", false},
+	}
+	for _, tc := range tests {
+		if got := shouted(tc.text); got != tc.want {
+			t.Errorf("shouted(%q) = %v, want %v", tc.text, got, tc.want)
+		}
+	}
+}
+
+func TestCountMentions(t *testing.T) {
+	msgs := []storage.Message{
+		{Text: "AMD looks weak"},
+		{Text: "$NVDA to the moon, NVDA NVDA"}, // one message counts once
+		{Text: "NVDA again, and AMD"},
+		{Text: "the CEO said so"},
+		{Text: "$AMD calls"},
+		{Text: "TSLA"},
+	}
+	got := countMentions(msgs)
+	want := []tickerCount{
+		{Symbol: "AMD", Count: 3, Tagged: true},
+		{Symbol: "NVDA", Count: 2, Tagged: true},
+		{Symbol: "TSLA", Count: 1},
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("countMentions = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,89 @@
+package telegram
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"telegramBotTrade/internal/logging"
+)
+
+const (
+	// weeklyReportHour is when, in the admin chat's time zone, the Monday report goes out.
+	weeklyReportHour = 9
+	reportTopChats   = 5
+)
+
+// runWeeklyReport sends the cross-chat usage report to the admin chat once per
+// ISO week, on Monday from weeklyReportHour local time. It is opt-in via WEEKLY_REPORT.
+func (b *Bot) runWeeklyReport(now time.Time) {
+	h := b.h
+	if !h.weeklyReport || h.adminChatID == 0 {
+		return
+	}
+	local := now.In(chatClock(h.chartSettings(h.adminChatID)))
+	if local.Weekday() != time.Monday || local.Hour() < weeklyReportHour {
+		return
+	}
+	ctx := logging.WithRequestID(context.Background(), logging.NewRequestID())
+	year, week := local.ISOWeek()
+	first, err := b.store.MarkAlerted(h.adminChatID, "report", "weekly", fmt.Sprintf("%d-W%02d", year, week))
+	if err != nil {
+		logging.FromContext(ctx).Error("report: mark failed", "err", err)
+		return
+	}
+	if !first {
+		return
+	}
+	// The report covers the seven days before this Monday's local midnight.
+	until := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, local.Location())
+	h.sendUsageReport(ctx, h.adminChatID, until)
+}
+
+// handleReport sends the cross-chat usage report for the last seven days on demand.
+func (h *Handlers) handleReport(ctx context.Context, chatID int64) {
+	if !h.isAdmin(chatID) {
+		h.reply(chatID, h.t(chatID, "admin.only"))
+		return
+	}
+	h.sendUsageReport(ctx, chatID, time.Now())
+}
+
+// sendUsageReport aggregates the week ending at until, compares it with the
+// week before and sends the result to chatID.
+func (h *Handlers) sendUsageReport(ctx context.Context, chatID int64, until time.Time) {
+	since := until.AddDate(0, 0, -7)
+	cur, err := h.store.FetchUsageReport(since.Unix(), until.Unix(), reportTopChats)
+	if err != nil {
+		logging.FromContext(ctx).Error("report: query failed", "err", err)
+		h.reply(chatID, h.t(chatID, "report.failed")+err.Error())
+		return
+	}
+	prev, err := h.store.FetchUsageReport(since.AddDate(0, 0, -7).Unix(), since.Unix(), 0)
+	if err != nil {
+		logging.FromContext(ctx).Warn("report: previous week query failed", "err", err)
+	}
+	names := make(map[int64]string, len(cur.TopChats))
+	for _, c := range cur.TopChats {
+		names[c.ChatID] = h.chatName(c.ChatID)
+	}
+	h.reply(chatID, h.analytics.FormatWeeklyReport(cur, prev, names))
+}
+
+// chatName returns a chat's title or username, or "" when Telegram can't say.
+func (h *Handlers) chatName(chatID int64) string {
+	chat, err := h.api.GetChat(tgbotapi.ChatInfoConfig{ChatConfig: tgbotapi.ChatConfig{ChatID: chatID}})
+	if err != nil {
+		return ""
+	}
+	switch {
+	case chat.Title != "":
+		return chat.Title
+	case chat.UserName != "":
+		return "@" + chat.UserName
+	}
+	return strings.TrimSpace(chat.FirstName + " " + chat.LastName)
+}
@@ -0,0 +1,226 @@
+package telegram
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"telegramBotTrade/internal/finance"
+	"telegramBotTrade/internal/logging"
+	"telegramBotTrade/internal/storage"
+)
+
+// maxSchedulesPerChat caps /schedule entries so one chat can't flood the worker pool.
+const maxSchedulesPerChat = 5
+
+// weekdaySpec is the only recurrence supported today; it is stored after the time
+// so other recurrences can be added without migrating existing rows.
+const weekdaySpec = "mon-fri"
+
+var reScheduleTime = regexp.MustCompile(`^([01]?\d|2[0-3]):([0-5]\d)$`)
+
+// schedulable lists the commands /schedule accepts: read-only charts and
+// quotes. A scheduled run has no sender, so commands that keep state per user
+// (/target, /paper, /portbuilder) or change the chat's would act for nobody.
+var schedulable = map[string]bool{
+	"/stock": true, "/stocks": true, "/stockx": true, "/stocksx": true, "/stocks-index": true,
+	"/ew-port": true, "/port": true, "/portstats": true, "/montecarlo": true,
+	"/macd": true, "/atr": true, "/yoy": true, "/vix": true, "/yield": true, "/curve": true, "/heat": true,
+	"/ohlc": true, "/export": true, "/info": true, "/optmove": true, "/calendar": true, "/futures": true,
+	"/recap": true,
+}
+
+type scheduledKey struct{}
+
+// withScheduled marks ctx as a scheduler run so the command text is not stored as chat history.
+func withScheduled(ctx context.Context) context.Context {
+	return context.WithValue(ctx, scheduledKey{}, true)
+}
+
+func isScheduled(ctx context.Context) bool {
+	v, _ := ctx.Value(scheduledKey{}).(bool)
+	return v
+}
+
+// handleSchedule dispatches /schedule list|delete N|HH:MM /command.
+func (h *Handlers) handleSchedule(chatID int64, args string) {
+	fields := strings.Fields(args)
+	if len(fields) == 0 || fields[0] == "list" {
+		h.listSchedules(chatID)
+		return
+	}
+	cs := h.chartSettings(chatID)
+	if fields[0] == "delete" || fields[0] == "del" {
+		if len(fields) != 2 {
+			h.reply(chatID, T(cs, "schedule.usage"))
+			return
+		}
+		id, err := strconv.ParseInt(strings.TrimPrefix(fields[1], "#"), 10, 64)
+		if err != nil {
+			h.reply(chatID, T(cs, "schedule.usage"))
+			return
+		}
+		ok, err := h.store.DeleteSchedule(chatID, id)
+		switch {
+		case err != nil:
+			h.reply(chatID, T(cs, "schedule.delete_failed")+err.Error())
+		case !ok:
+			h.reply(chatID, T(cs, "schedule.unknown", id))
+		default:
+			h.reply(chatID, T(cs, "schedule.deleted", id))
+		}
+		return
+	}
+
+	g := reScheduleTime.FindStringSubmatch(fields[0])
+	if g == nil || len(fields) < 2 || !strings.HasPrefix(fields[1], "/") {
+		h.reply(chatID, T(cs, "schedule.usage"))
+		return
+	}
+	cmd := strings.Join(fields[1:], " ")
+	// check what an alias expands to, so /myset can't schedule /set
+	name := commandName(h.expandAlias(chatID, cmd))
+	if !schedulable[name] {
+		h.reply(chatID, T(cs, "schedule.not_allowed", name))
+		return
+	}
+	existing, err := h.store.FetchSchedules(chatID)
+	if err != nil {
+		h.reply(chatID, T(cs, "schedule.load_failed")+err.Error())
+		return
+	}
+	if len(existing) >= maxSchedulesPerChat {
+		h.reply(chatID, T(cs, "schedule.too_many", maxSchedulesPerChat))
+		return
+	}
+	hh, _ := strconv.Atoi(g[1])
+	spec := fmt.Sprintf("%02d:%s %s", hh, g[2], weekdaySpec)
+	id, err := h.store.AddSchedule(chatID, spec, cmd, time.Now().Unix())
+	if err != nil {
+		h.reply(chatID, T(cs, "schedule.save_failed")+err.Error())
+		return
+	}
+	h.reply(chatID, T(cs, "schedule.added", id, cmd, hh, g[2], chatClock(cs)))
+}
+
+func (h *Handlers) listSchedules(chatID int64) {
+	cs := h.chartSettings(chatID)
+	list, err := h.store.FetchSchedules(chatID)
+	if err != nil {
+		h.reply(chatID, T(cs, "schedule.load_failed")+err.Error())
+		return
+	}
+	if len(list) == 0 {
+		h.reply(chatID, T(cs, "schedule.none")+" "+T(cs, "schedule.usage"))
+		return
+	}
+	var b strings.Builder
+	b.WriteString(T(cs, "schedule.title", chatClock(cs)) + "\n")
+	for _, sc := range list {
+		fmt.Fprintf(&b, "\n#%d • %s • %s", sc.ID, sc.Spec, sc.Command)
+	}
+	h.reply(chatID, b.String())
+}
+
+// chatClock returns the time zone schedules run in: the chat's /set tz, or the
+// chart default.
+func chatClock(cs storage.ChatSettings) *time.Location {
+	if loc := chatLocation(cs); loc != nil {
+		return loc
+	}
+	return finance.DefaultLocation()
+}
+
+// parseScheduleSpec splits "HH:MM mon-fri" into hour and minute.
+func parseScheduleSpec(spec string) (hour, minute int, ok bool) {
+	clock, _, _ := strings.Cut(spec, " ")
+	g := reScheduleTime.FindStringSubmatch(clock)
+	if g == nil {
+		return 0, 0, false
+	}
+	hour, _ = strconv.Atoi(g[1])
+	minute, _ = strconv.Atoi(g[2])
+	return hour, minute, true
+}
+
+// scheduleDue reports whether sc should run at now in loc: a weekday, the
+// matching minute, and not already run that local day.
+func scheduleDue(sc storage.Schedule, now time.Time, loc *time.Location) bool {
+	hour, minute, ok := parseScheduleSpec(sc.Spec)
+	if !ok {
+		return false
+	}
+	local := now.In(loc)
+	if wd := local.Weekday(); wd == time.Saturday || wd == time.Sunday {
+		return false
+	}
+	if local.Hour() != hour || local.Minute() != minute {
+		return false
+	}
+	if sc.LastRun != 0 {
+		last := time.Unix(sc.LastRun, 0).In(loc)
+		if last.Year() == local.Year() && last.YearDay() == local.YearDay() {
+			return false
+		}
+	}
+	return true
+}
+
+// runScheduler checks schedules twice a minute (so no minute is skipped) until
+// ctx is cancelled.
+func (b *Bot) runScheduler(ctx context.Context) {
+	t := time.NewTicker(30 * time.Second)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-t.C:
+			b.runDueSchedules(now)
+			b.runWeeklyReport(now)
+		}
+	}
+}
+
+// runDueSchedules submits each due command to the worker pool as a synthetic
+// message, so scheduled runs take the same path as typed commands.
+func (b *Bot) runDueSchedules(now time.Time) {
+	list, err := b.store.FetchSchedules(0)
+	if err != nil {
+		logging.FromContext(context.Background()).Error("schedule: load failed", "err", err)
+		return
+	}
+	for _, sc := range list {
+		// suspended while the chat has blocked or removed the bot
+		if !b.h.chats.active(sc.ChatID) || !scheduleDue(sc, now, chatClock(b.h.chartSettings(sc.ChatID))) {
+			continue
+		}
+		ctx := withScheduled(logging.WithRequestID(context.Background(), logging.NewRequestID()))
+		logger := logging.FromContext(ctx).With("chat_id", sc.ChatID, "schedule_id", sc.ID)
+		// mark first so a failing command isn't retried every tick
+		if err := b.store.MarkScheduleRun(sc.ID, now.Unix()); err != nil {
+			logger.Error("schedule: mark run failed", "err", err)
+			continue
+		}
+		// rows saved before the allowlist, or through an alias changed since
+		if name := commandName(b.h.expandAlias(sc.ChatID, sc.Command)); !schedulable[name] {
+			logger.Warn("schedule: command not schedulable, skipped", "command", name)
+			continue
+		}
+		msg := &tgbotapi.Message{
+			Chat: &tgbotapi.Chat{ID: sc.ChatID},
+			Text: sc.Command,
+			Date: int(now.Unix()),
+		}
+		if err := b.pool.submit(job{ctx: ctx, msg: msg}); err != nil {
+			logger.Warn("schedule: dropped", "err", err)
+			continue
+		}
+		logger.Info("schedule: submitted", "command", sc.Command)
+	}
+}
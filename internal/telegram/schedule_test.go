@@ -0,0 +1,17 @@
+package telegram
+
+import "testing"
+
+func TestSchedulableCommands(t *testing.T) {
+	for name := range schedulable {
+		if !botCommands[name] {
+			t.Errorf("schedulable %s is not a bot command", name)
+		}
+	}
+	// these keep state per user or per chat, which a run without a sender can't own
+	for _, name := range []string{"/schedule", "/set", "/feedback", "/target", "/paper", "/portbuilder", "/watch", "/alias", "/brief", "/movers", "/broadcast"} {
+		if schedulable[name] {
+			t.Errorf("%s must not be schedulable", name)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package telegram
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"telegramBotTrade/internal/finance"
+	"telegramBotTrade/internal/logging"
+	"telegramBotTrade/internal/storage"
+)
+
+const (
+	scoreboardDefaultDays = 30
+	scoreboardMaxDays     = 365
+	// scoreboardLines caps the calls listed; the totals cover all of them.
+	scoreboardLines = 20
+)
+
+// handleScoreboard reports how the positions /recommend suggested in the chat
+// over the last days did since they were made.
+func (h *Handlers) handleScoreboard(ctx context.Context, chatID int64, days int) {
+	cs := h.chartSettings(chatID)
+	if days < 1 || days > scoreboardMaxDays {
+		h.reply(chatID, T(cs, "scoreboard.usage", scoreboardMaxDays))
+		return
+	}
+	since := time.Now().AddDate(0, 0, -days)
+	recs, err := h.store.FetchRecommendations(chatID, since.Unix())
+	if err != nil {
+		h.reply(chatID, T(cs, "scoreboard.load_failed")+err.Error())
+		return
+	}
+	if len(recs) == 0 {
+		h.reply(chatID, T(cs, "scoreboard.none", days))
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, 90*time.Second)
+	defer cancel()
+	scores := finance.ScoreCalls(ctx, recommendationCalls(recs))
+	logging.FromContext(ctx).Info("scoreboard: scored", "chat_id", chatID, "days", days, "calls", len(scores))
+	h.reply(chatID, formatScoreboard(cs, scores, days))
+}
+
+func recommendationCalls(recs []storage.Recommendation) []finance.Call {
+	calls := make([]finance.Call, len(recs))
+	for i, r := range recs {
+		calls[i] = finance.Call{Symbol: r.Symbol, Short: r.Short, At: time.Unix(r.TS, 0)}
+	}
+	return calls
+}
+
+// formatScoreboard renders the totals and the newest calls, e.g.
+// "SHORT TLT (Oct 02): +1.4% ✅", with dates in the chat's clock.
+func formatScoreboard(cs storage.ChatSettings, scores []finance.CallScore, days int) string {
+	loc := chatClock(cs)
+	scored, hits, avg := finance.CallStats(scores)
+	var b strings.Builder
+	b.WriteString(T(cs, "scoreboard.title", days, len(scores)) + "\n")
+	if scored == 0 {
+		b.WriteString(T(cs, "scoreboard.unscored") + "\n")
+	} else {
+		b.WriteString(T(cs, "scoreboard.stats", float64(hits)/float64(scored)*100, hits, scored, avg) + "\n")
+	}
+	for i, s := range scores {
+		if i == scoreboardLines {
+			b.WriteString("\n" + T(cs, "scoreboard.more", len(scores)-scoreboardLines))
+			break
+		}
+		side := T(cs, "scoreboard.long")
+		if s.Short {
+			side = T(cs, "scoreboard.short")
+		}
+		fmt.Fprintf(&b, "\n%s %s (%s): ", side, s.Symbol, s.At.In(loc).Format("Jan 02"))
+		switch {
+		case !s.OK:
+			b.WriteString("n/a")
+		case s.Return > 0:
+			fmt.Fprintf(&b, "%+.1f%% ✅", s.Return)
+		default:
+			fmt.Fprintf(&b, "%+.1f%% ❌", s.Return)
+		}
+	}
+	return b.String()
+}
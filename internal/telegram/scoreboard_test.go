@@ -0,0 +1,59 @@
+package telegram
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"telegramBotTrade/internal/finance"
+	"telegramBotTrade/internal/storage"
+)
+
+func TestRecommendationCalls(t *testing.T) {
+	ts := time.Date(2024, 10, 2, 15, 0, 0, 0, time.UTC).Unix()
+	recs := []storage.Recommendation{
+		{ID: 1, ChatID: 5, Symbol: "SPY", TS: ts},
+		{ID: 2, ChatID: 5, Symbol: "TLT", Short: true, TS: ts},
+	}
+	calls := recommendationCalls(recs)
+	if len(calls) != 2 || calls[0].Symbol != "SPY" || calls[0].Short || !calls[1].Short || calls[1].At.Unix() != ts {
+		t.Errorf("recommendationCalls = %+v", calls)
+	}
+}
+
+func TestFormatScoreboard(t *testing.T) {
+	en := storage.ChatSettings{Timezone: "UTC"}
+	at := time.Date(2024, 10, 2, 15, 0, 0, 0, time.UTC)
+	scores := []finance.CallScore{
+		{Call: finance.Call{Symbol: "SPY", At: at}, Return: 3.21, OK: true},
+		{Call: finance.Call{Symbol: "TLT", Short: true, At: at}, Return: 1.44, OK: true},
+		{Call: finance.Call{Symbol: "QQQ", At: at}, Return: -2, OK: true},
+		{Call: finance.Call{Symbol: "XXX", At: at}},
+	}
+	got := formatScoreboard(en, scores, 30)
+	for _, want := range []string{
+		T(en, "scoreboard.title", 30, 4),
+		T(en, "scoreboard.stats", 200.0/3, 2, 3, (3.21+1.44-2)/3),
+		T(en, "scoreboard.long") + " SPY (Oct 02): +3.2% ✅",
+		T(en, "scoreboard.short") + " TLT (Oct 02): +1.4% ✅",
+		"QQQ (Oct 02): -2.0% ❌",
+		"XXX (Oct 02): n/a",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("missing %q in\n%s", want, got)
+		}
+	}
+
+	got = formatScoreboard(en, scores[3:], 7)
+	if !strings.Contains(got, T(en, "scoreboard.unscored")) {
+		t.Errorf("no unscored note in\n%s", got)
+	}
+
+	many := make([]finance.CallScore, scoreboardLines+5)
+	for i := range many {
+		many[i] = finance.CallScore{Call: finance.Call{Symbol: "SPY", At: at}, Return: 1, OK: true}
+	}
+	if got := formatScoreboard(en, many, 30); !strings.Contains(got, T(en, "scoreboard.more", 5)) {
+		t.Errorf("no overflow line in\n%s", got)
+	}
+}
@@ -0,0 +1,270 @@
+package telegram
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"telegramBotTrade/internal/finance"
+	"telegramBotTrade/internal/logging"
+	"telegramBotTrade/internal/storage"
+)
+
+// settingWindows are the values accepted by /set window. /stock and /stocks only
+// support 1d|1w|1m, so other values apply to the custom-window commands only.
+var settingWindows = []string{"1d", "1w", "5d", "1m", "3m", "6m", "1y", "2y", "5y", "10y", "30y"}
+
+// settingIntervals are the values accepted by /set interval.
+var settingIntervals = []string{"1m", "5m", "15m", "1h", "1d"}
+
+// handleSet dispatches /set KEY VALUE to the matching setting.
+func (h *Handlers) handleSet(ctx context.Context, chatID int64, key, value string) {
+	switch key {
+	case "source_channel":
+		h.setSourceChannel(ctx, chatID, value)
+	case "window":
+		h.setChoice(chatID, "default_window", "set.window", strings.ToLower(value), settingWindows)
+	case "interval":
+		h.setChoice(chatID, "default_interval", "set.interval", strings.ToLower(value), settingIntervals)
+	case "theme":
+		h.setChoice(chatID, "theme", "set.theme", strings.ToLower(value), finance.Themes)
+	case "movers_auto":
+		h.setMoversAuto(chatID, strings.ToLower(value))
+	case "tz", "timezone":
+		h.setTimezone(chatID, value)
+	case "store_messages":
+		h.setStoreMessages(ctx, chatID, strings.ToLower(value))
+	case "auto_pin":
+		h.setAutoPin(chatID, strings.ToLower(value))
+	case "cashtags":
+		h.setChoice(chatID, "cashtags", "set.cashtags", strings.ToLower(value), cashtagModes)
+	case "lang", "language":
+		h.setTranslateLang(chatID, value)
+	case "show", "":
+		h.showSettings(chatID)
+	default:
+		h.reply(chatID, h.t(chatID, "set.usage"))
+	}
+}
+
+// setChoice stores value in column when it is one of allowed; "off" resets it.
+// label is the catalog key of the setting's name in replies.
+func (h *Handlers) setChoice(chatID int64, column, label, value string, allowed []string) {
+	cs := h.chartSettings(chatID)
+	if value == "off" || value == "default" {
+		value = ""
+	} else if !slices.Contains(allowed, value) {
+		h.reply(chatID, T(cs, "set.invalid", T(cs, label), value, strings.Join(allowed, ", ")))
+		return
+	}
+	if err := h.settings.Set(chatID, column, value); err != nil {
+		h.reply(chatID, T(cs, "settings.save_failed")+err.Error())
+		return
+	}
+	if value == "" {
+		h.reply(chatID, T(cs, "set.reset", T(cs, label)))
+		return
+	}
+	h.reply(chatID, T(cs, "set.done", T(cs, label), value))
+}
+
+// showSettings prints the effective defaults for the chat.
+func (h *Handlers) showSettings(chatID int64) {
+	cs := h.chartSettings(chatID)
+	orDefault := func(v, def string) string {
+		if v == "" {
+			return def + " " + T(cs, "set.default")
+		}
+		return v
+	}
+	var b strings.Builder
+	b.WriteString(T(cs, "set.title") + "\n\n")
+	b.WriteString("- window: " + orDefault(cs.DefaultWindow, T(cs, "set.per_command")) + "\n")
+	b.WriteString("- interval: " + orDefault(cs.DefaultInterval, "5m") + "\n")
+	b.WriteString("- theme: " + orDefault(cs.Theme, "light") + "\n")
+	b.WriteString("- tz: " + orDefault(cs.Timezone, "America/New_York") + "\n")
+	b.WriteString("- lang: " + orDefault(cs.TranslateLang, defaultTranslateLang) + "\n")
+	b.WriteString("- cashtags: " + orDefault(cs.Cashtags, "off") + "\n")
+	if cs.MoversAuto > 0 {
+		b.WriteString(fmt.Sprintf("- movers_auto: %g%%\n", cs.MoversAuto))
+	} else {
+		b.WriteString("- movers_auto: off\n")
+	}
+	if cs.StoreMessages {
+		b.WriteString("- store_messages: on\n")
+	} else {
+		b.WriteString("- store_messages: off\n")
+	}
+	if cs.AutoPin {
+		b.WriteString("- auto_pin: on\n")
+	} else {
+		b.WriteString("- auto_pin: off\n")
+	}
+	if cs.SourceChannel != 0 {
+		b.WriteString(fmt.Sprintf("- source_channel: %d\n", cs.SourceChannel))
+	} else {
+		b.WriteString("- source_channel: " + T(cs, "set.none") + "\n")
+	}
+	h.reply(chatID, b.String())
+}
+
+// setStoreMessages turns message storage on or off. Turning it off also purges
+// the chat's stored messages.
+func (h *Handlers) setStoreMessages(ctx context.Context, chatID int64, value string) {
+	var on bool
+	switch value {
+	case "on":
+		on = true
+	case "off":
+	default:
+		h.reply(chatID, h.t(chatID, "store.usage"))
+		return
+	}
+	if err := h.settings.Set(chatID, "store_messages", on); err != nil {
+		h.reply(chatID, h.t(chatID, "settings.save_failed")+err.Error())
+		return
+	}
+	if on {
+		h.reply(chatID, h.t(chatID, "store.on"))
+		return
+	}
+	// queued messages would otherwise land after the purge
+	h.flushMessages(ctx)
+	n, err := h.store.DeleteMessages(chatID)
+	if err != nil {
+		logging.FromContext(ctx).Error("purge messages failed", "chat_id", chatID, "err", err)
+		h.reply(chatID, h.t(chatID, "store.purge_failed")+err.Error())
+		return
+	}
+	h.reply(chatID, h.t(chatID, "store.off", n))
+}
+
+// exampleZones are suggested when /set tz gets an unknown zone name.
+var exampleZones = []string{"America/New_York", "Europe/London", "Asia/Singapore", "Asia/Hong_Kong", "Asia/Tokyo", "UTC"}
+
+// setTimezone stores an IANA time zone used for chart labels.
+func (h *Handlers) setTimezone(chatID int64, value string) {
+	value = strings.TrimSpace(value)
+	if value == "off" || value == "default" {
+		value = ""
+	} else if _, err := time.LoadLocation(value); err != nil || value == "" || strings.EqualFold(value, "local") {
+		h.reply(chatID, h.t(chatID, "tz.unknown", value, strings.Join(exampleZones, ", ")))
+		return
+	}
+	if err := h.settings.Set(chatID, "timezone", value); err != nil {
+		h.reply(chatID, h.t(chatID, "settings.save_failed")+err.Error())
+		return
+	}
+	if value == "" {
+		h.reply(chatID, h.t(chatID, "tz.reset"))
+		return
+	}
+	h.reply(chatID, h.t(chatID, "tz.set", value))
+}
+
+// chatLocation returns the chat's time zone, or nil for the Eastern default.
+func chatLocation(cs storage.ChatSettings) *time.Location {
+	if cs.Timezone == "" {
+		return nil
+	}
+	loc, err := time.LoadLocation(cs.Timezone)
+	if err != nil {
+		return nil
+	}
+	return loc
+}
+
+// chartSettings loads the chat's settings, falling back to built-in defaults on error.
+func (h *Handlers) chartSettings(chatID int64) storage.ChatSettings {
+	cs, err := h.settings.Get(chatID)
+	if err != nil {
+		return storage.ChatSettings{ChatID: chatID}
+	}
+	return cs
+}
+
+// renderOptions builds chart render options from chat settings, in the
+// theme an /again override asks for if any.
+func renderOptions(ctx context.Context, cs storage.ChatSettings) finance.RenderOptions {
+	theme := cs.Theme
+	if t := againTheme(ctx); t != "" {
+		theme = t
+	}
+	return finance.RenderOptions{Theme: theme, Location: chatLocation(cs)}
+}
+
+// defaultInterval returns the chat's default interval for custom charts.
+func defaultInterval(cs storage.ChatSettings) string {
+	if cs.DefaultInterval != "" {
+		return cs.DefaultInterval
+	}
+	return "5m"
+}
+
+// miniWindow maps the chat's default window onto the 1d|1w|1m windows of /stock and /stocks.
+func miniWindow(cs storage.ChatSettings) string {
+	switch cs.DefaultWindow {
+	case "1d":
+		return "1d"
+	case "1w", "5d":
+		return "1w"
+	case "1m":
+		return "1m"
+	}
+	return ""
+}
+
+// customWindow maps the chat's default window onto the windows of /stockx and friends.
+func customWindow(cs storage.ChatSettings) string {
+	if cs.DefaultWindow == "1w" {
+		return "5d"
+	}
+	return cs.DefaultWindow
+}
+
+// setSourceChannel links a channel so /summary channel reads its stored posts.
+// The bot must be a member of that channel for its posts to be stored.
+func (h *Handlers) setSourceChannel(ctx context.Context, chatID int64, value string) {
+	switch strings.ToLower(value) {
+	case "":
+		h.reply(chatID, h.t(chatID, "set.usage"))
+		return
+	case "off", "none":
+		if err := h.settings.Set(chatID, "source_channel", 0); err != nil {
+			h.reply(chatID, h.t(chatID, "settings.save_failed")+err.Error())
+			return
+		}
+		h.reply(chatID, h.t(chatID, "channel.cleared"))
+		return
+	}
+
+	cfg := tgbotapi.ChatInfoConfig{}
+	if id, err := strconv.ParseInt(value, 10, 64); err == nil {
+		cfg.ChatID = id
+	} else {
+		if !strings.HasPrefix(value, "@") {
+			value = "@" + value
+		}
+		cfg.SuperGroupUsername = value
+	}
+	chat, err := h.api.GetChat(cfg)
+	if err != nil {
+		logging.FromContext(ctx).Warn("set: channel lookup failed", "chat_id", chatID, "value", value, "err", err)
+		h.reply(chatID, h.t(chatID, "channel.not_found", value))
+		return
+	}
+	if !chat.IsChannel() {
+		h.reply(chatID, h.t(chatID, "channel.not_channel", value))
+		return
+	}
+	if err := h.settings.Set(chatID, "source_channel", chat.ID); err != nil {
+		h.reply(chatID, h.t(chatID, "settings.save_failed")+err.Error())
+		return
+	}
+	h.reply(chatID, h.t(chatID, "channel.set", chat.Title))
+}
@@ -0,0 +1,104 @@
+package telegram
+
+import (
+	"sync"
+	"time"
+
+	"telegramBotTrade/internal/storage"
+)
+
+const (
+	// settingsCacheTTL bounds how long a chat's settings are served from
+	// memory. Writes through settingsService invalidate immediately; the TTL
+	// only matters for writes made elsewhere, such as another bot in the same
+	// process.
+	settingsCacheTTL = time.Minute
+	// settingsCacheMax is the cache size at which expired entries are swept.
+	settingsCacheMax = 5000
+)
+
+// settingsStore is the part of *storage.Store the settings cache reads and
+// writes through.
+type settingsStore interface {
+	FetchChatSettings(chatID int64) (storage.ChatSettings, error)
+	SetChatSetting(chatID int64, column string, value any) error
+}
+
+// settingsService reads chat settings through an in-memory cache, since
+// nearly every incoming message needs them, and writes them through to the
+// store. All handler code goes through it instead of the raw Store calls.
+type settingsService struct {
+	store settingsStore
+	ttl   time.Duration
+
+	mu      sync.Mutex
+	entries map[int64]settingsEntry
+	// gen counts writes per chat, so a load that raced with a write is not
+	// cached over the newer value. It only holds chats that changed a setting
+	// and is never swept, which would let an old generation match again.
+	gen map[int64]uint64
+}
+
+type settingsEntry struct {
+	cs      storage.ChatSettings
+	expires time.Time
+}
+
+func newSettingsService(store settingsStore, ttl time.Duration) *settingsService {
+	return &settingsService{store: store, ttl: ttl, entries: map[int64]settingsEntry{}, gen: map[int64]uint64{}}
+}
+
+// Get returns the chat's settings, or defaults when none are stored. Failed
+// loads are not cached and return the store's fail-safe settings.
+func (s *settingsService) Get(chatID int64) (storage.ChatSettings, error) {
+	now := time.Now()
+	s.mu.Lock()
+	if e, ok := s.entries[chatID]; ok && now.Before(e.expires) {
+		s.mu.Unlock()
+		return e.cs, nil
+	}
+	gen := s.gen[chatID]
+	s.mu.Unlock()
+
+	cs, err := s.store.FetchChatSettings(chatID)
+	if err != nil {
+		return cs, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.gen[chatID] == gen {
+		if len(s.entries) >= settingsCacheMax {
+			s.sweep(now)
+		}
+		s.entries[chatID] = settingsEntry{cs: cs, expires: now.Add(s.ttl)}
+	}
+	return cs, nil
+}
+
+// Set writes one settings column and drops the chat's cached settings.
+func (s *settingsService) Set(chatID int64, column string, value any) error {
+	err := s.store.SetChatSetting(chatID, column, value)
+	// invalidate even on error: the write may have landed
+	s.Invalidate(chatID)
+	return err
+}
+
+// Invalidate drops the cached settings of the given chats and fences off any
+// load already in flight for them.
+func (s *settingsService) Invalidate(chatIDs ...int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, id := range chatIDs {
+		delete(s.entries, id)
+		s.gen[id]++
+	}
+}
+
+// sweep drops expired entries. Callers hold s.mu.
+func (s *settingsService) sweep(now time.Time) {
+	for id, e := range s.entries {
+		if !now.Before(e.expires) {
+			delete(s.entries, id)
+		}
+	}
+}
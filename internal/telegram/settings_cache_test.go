@@ -0,0 +1,134 @@
+package telegram
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"telegramBotTrade/internal/storage"
+)
+
+// fakeSettingsStore keeps each chat's theme in memory and counts loads. When
+// hold is set, a load reads the theme and then waits on it before returning,
+// so a test can land a write in between.
+type fakeSettingsStore struct {
+	mu     sync.Mutex
+	themes map[int64]string
+	loads  int
+	hold   chan struct{}
+	loaded chan struct{} // signalled when a held load has read the theme
+}
+
+func newFakeSettingsStore() *fakeSettingsStore {
+	return &fakeSettingsStore{themes: map[int64]string{}}
+}
+
+func (f *fakeSettingsStore) FetchChatSettings(chatID int64) (storage.ChatSettings, error) {
+	f.mu.Lock()
+	f.loads++
+	cs := storage.ChatSettings{ChatID: chatID, Theme: f.themes[chatID]}
+	hold, loaded := f.hold, f.loaded
+	f.mu.Unlock()
+	if hold != nil {
+		loaded <- struct{}{}
+		<-hold
+	}
+	return cs, nil
+}
+
+func (f *fakeSettingsStore) SetChatSetting(chatID int64, column string, value any) error {
+	if column != "theme" {
+		return fmt.Errorf("unknown setting %s", column)
+	}
+	f.mu.Lock()
+	f.themes[chatID] = value.(string)
+	f.mu.Unlock()
+	return nil
+}
+
+func (f *fakeSettingsStore) loadCount() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.loads
+}
+
+func theme(t *testing.T, s *settingsService, chatID int64) string {
+	t.Helper()
+	cs, err := s.Get(chatID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cs.Theme
+}
+
+func TestSettingsServiceCaches(t *testing.T) {
+	store := newFakeSettingsStore()
+	s := newSettingsService(store, time.Hour)
+	store.themes[1] = "dark"
+	for range 3 {
+		if got := theme(t, s, 1); got != "dark" {
+			t.Fatalf("theme = %q, want dark", got)
+		}
+	}
+	if n := store.loadCount(); n != 1 {
+		t.Errorf("loads = %d, want 1", n)
+	}
+	theme(t, s, 2)
+	if n := store.loadCount(); n != 2 {
+		t.Errorf("loads = %d, want 2 with a second chat", n)
+	}
+}
+
+func TestSettingsServiceSetInvalidates(t *testing.T) {
+	store := newFakeSettingsStore()
+	s := newSettingsService(store, time.Hour)
+	theme(t, s, 1)
+	if err := s.Set(1, "theme", "dark"); err != nil {
+		t.Fatal(err)
+	}
+	if got := theme(t, s, 1); got != "dark" {
+		t.Errorf("theme after Set = %q, want dark", got)
+	}
+}
+
+func TestSettingsServiceTTL(t *testing.T) {
+	store := newFakeSettingsStore()
+	s := newSettingsService(store, time.Millisecond)
+	theme(t, s, 1)
+	store.SetChatSetting(1, "theme", "dark") // written behind the cache's back
+	time.Sleep(5 * time.Millisecond)
+	if got := theme(t, s, 1); got != "dark" {
+		t.Errorf("theme after the TTL = %q, want dark", got)
+	}
+}
+
+// TestSettingsServiceStaleLoad races a write with a load that read the old
+// settings: the load may return them, but must not cache them over the write.
+func TestSettingsServiceStaleLoad(t *testing.T) {
+	store := newFakeSettingsStore()
+	store.themes[1] = "light"
+	store.hold, store.loaded = make(chan struct{}), make(chan struct{})
+	s := newSettingsService(store, time.Hour)
+
+	done := make(chan string)
+	go func() {
+		cs, _ := s.Get(1)
+		done <- cs.Theme
+	}()
+	<-store.loaded // the load has read "light"
+	if err := s.Set(1, "theme", "dark"); err != nil {
+		t.Fatal(err)
+	}
+	store.mu.Lock()
+	hold := store.hold
+	store.hold = nil // later loads don't wait
+	store.mu.Unlock()
+	close(hold)
+	if got := <-done; got != "light" {
+		t.Fatalf("in-flight load returned %q, want the light it read", got)
+	}
+	if got := theme(t, s, 1); got != "dark" {
+		t.Errorf("theme after the write = %q, want dark; the stale load was cached", got)
+	}
+}
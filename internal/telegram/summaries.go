@@ -0,0 +1,148 @@
+package telegram
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"telegramBotTrade/internal/logging"
+	"telegramBotTrade/internal/storage"
+)
+
+const (
+	summariesDefault = 5
+	summariesMax     = 20
+	// summaryRetention is how long stored summaries are kept.
+	summaryRetention = 90 * 24 * time.Hour
+	// maxMessageLen is Telegram's limit for one text message.
+	maxMessageLen = 4096
+)
+
+// saveSummary stores a generated summary and drops those past the retention.
+func (h *Handlers) saveSummary(ctx context.Context, sm storage.Summary) {
+	logger := logging.FromContext(ctx)
+	if _, err := h.store.SaveSummary(sm); err != nil {
+		logger.Warn("summary: save failed", "chat_id", sm.ChatID, "err", err)
+	}
+	if err := h.store.PruneSummaries(time.Now().Add(-summaryRetention).Unix()); err != nil {
+		logger.Warn("summary: prune failed", "err", err)
+	}
+}
+
+// handleSummaries lists the chat's stored summaries or re-sends one.
+func (h *Handlers) handleSummaries(chatID int64, args string) {
+	fields := strings.Fields(args)
+	switch {
+	case len(fields) == 0:
+		h.listSummaries(chatID, summariesDefault)
+	case len(fields) == 2 && fields[0] == "show", len(fields) == 1 && strings.HasPrefix(fields[0], "#"):
+		id, err := strconv.ParseInt(strings.TrimPrefix(fields[len(fields)-1], "#"), 10, 64)
+		if err != nil {
+			h.reply(chatID, h.t(chatID, "summaries.usage"))
+			return
+		}
+		h.showSummary(chatID, id)
+	case len(fields) == 1:
+		n, err := strconv.Atoi(fields[0])
+		if err != nil || n < 1 || n > summariesMax {
+			h.reply(chatID, h.t(chatID, "summaries.usage"))
+			return
+		}
+		h.listSummaries(chatID, n)
+	default:
+		h.reply(chatID, h.t(chatID, "summaries.usage"))
+	}
+}
+
+func (h *Handlers) listSummaries(chatID int64, n int) {
+	cs := h.chartSettings(chatID)
+	list, err := h.store.FetchSummaries(chatID, n)
+	if err != nil {
+		h.reply(chatID, T(cs, "summaries.load_failed")+err.Error())
+		return
+	}
+	if len(list) == 0 {
+		h.reply(chatID, T(cs, "summaries.none"))
+		return
+	}
+	loc := chatClock(cs)
+	var b strings.Builder
+	b.WriteString(T(cs, "summaries.title", loc) + "\n")
+	for _, sm := range list {
+		from, to := time.Unix(sm.From, 0).In(loc), time.Unix(sm.To, 0).In(loc)
+		fmt.Fprintf(&b, "\n#%d • %s–%s • %s", sm.ID, from.Format("Mon Jan 2 15:04"), to.Format("15:04"), summaryPreview(sm.Text))
+	}
+	b.WriteString("\n\n" + T(cs, "summaries.show_hint"))
+	h.reply(chatID, b.String())
+}
+
+func (h *Handlers) showSummary(chatID, id int64) {
+	cs := h.chartSettings(chatID)
+	sm, ok, err := h.store.FetchSummary(chatID, id)
+	switch {
+	case err != nil:
+		h.reply(chatID, T(cs, "summaries.show_failed")+err.Error())
+		return
+	case !ok:
+		h.reply(chatID, T(cs, "summaries.unknown", id))
+		return
+	}
+	loc := chatClock(cs)
+	header := T(cs, "summaries.header", sm.ID,
+		time.Unix(sm.From, 0).In(loc).Format("Mon Jan 2 15:04"), time.Unix(sm.To, 0).In(loc).Format("Mon Jan 2 15:04")) + "\n\n"
+	h.sendLong(chatID, header+sm.Text, "Markdown")
+}
+
+// summaryPreview is the first non-empty line of a summary, shortened.
+func summaryPreview(text string) string {
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.Trim(strings.TrimSpace(line), "*#_-• ")
+		if line == "" {
+			continue
+		}
+		if r := []rune(line); len(r) > 60 {
+			line = string(r[:60]) + "…"
+		}
+		return line
+	}
+	return ""
+}
+
+// sendLong sends text in as many messages as Telegram's length limit needs,
+// splitting at line breaks. A part the API rejects in parseMode (unbalanced
+// markup after a split) is re-sent as plain text.
+func (h *Handlers) sendLong(chatID int64, text, parseMode string) {
+	for _, part := range splitMessage(text, maxMessageLen) {
+		msg := tgbotapi.NewMessage(chatID, part)
+		msg.ParseMode = parseMode
+		if _, err := h.api.Send(msg); err != nil && parseMode != "" {
+			h.api.Send(tgbotapi.NewMessage(chatID, part))
+		}
+	}
+}
+
+// splitMessage cuts text into parts of at most limit characters, preferring
+// line breaks and falling back to a hard cut for a single overlong line.
+func splitMessage(text string, limit int) []string {
+	var parts []string
+	r := []rune(text)
+	for len(r) > limit {
+		cut := limit
+		for i := limit; i > limit/2; i-- {
+			if r[i] == '\n' {
+				cut = i
+				break
+			}
+		}
+		parts = append(parts, strings.TrimRight(string(r[:cut]), "\n"))
+		r = []rune(strings.TrimLeft(string(r[cut:]), "\n"))
+	}
+	if len(r) > 0 {
+		parts = append(parts, string(r))
+	}
+	return parts
+}
@@ -0,0 +1,88 @@
+package telegram
+
+import (
+	"sync"
+	"time"
+
+	"telegramBotTrade/internal/finance"
+	"telegramBotTrade/internal/storage"
+)
+
+// summaryCacheTTL is how long a /summary result is reused for the same
+// window, so several people asking at once cost one OpenAI run.
+const summaryCacheTTL = 10 * time.Minute
+
+// summaryKey identifies the input of a summary by the messages in its window
+// rather than by when it was asked: the count and the first and last message
+// change as soon as anything is stored or slides out of the window, so a new
+// message never gets a stale summary, while requests seconds apart share one.
+type summaryKey struct {
+	chatID   int64
+	threadID int
+	hours    int
+	count    int
+	firstID  int
+	firstTS  int64
+	lastID   int
+	lastTS   int64
+}
+
+func newSummaryKey(chatID int64, threadID, hours int, msgs []storage.Message) summaryKey {
+	k := summaryKey{chatID: chatID, threadID: threadID, hours: hours, count: len(msgs)}
+	if n := len(msgs); n > 0 {
+		k.firstID, k.firstTS = msgs[0].MessageID, msgs[0].Ts
+		k.lastID, k.lastTS = msgs[n-1].MessageID, msgs[n-1].Ts
+	}
+	return k
+}
+
+type summaryEntry struct {
+	text    string
+	expires time.Time
+}
+
+// summaryCache holds recent summaries by their input.
+type summaryCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[summaryKey]summaryEntry
+	lookups finance.CacheStats // for /status
+}
+
+func newSummaryCache(ttl time.Duration) *summaryCache {
+	return &summaryCache{ttl: ttl, entries: map[summaryKey]summaryEntry{}}
+}
+
+// get returns the summary stored for k, if it has not expired.
+func (c *summaryCache) get(k summaryKey, now time.Time) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[k]
+	if !ok || !now.Before(e.expires) {
+		c.lookups.Misses++
+		return "", false
+	}
+	c.lookups.Hits++
+	return e.text, true
+}
+
+// stats reports the cache's lookups.
+func (c *summaryCache) stats() finance.CacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.lookups
+}
+
+// put stores text for k. Expired entries are dropped as the map grows.
+func (c *summaryCache) put(k summaryKey, text string, now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.entries) >= 1000 {
+		for key, e := range c.entries {
+			if !now.Before(e.expires) {
+				delete(c.entries, key)
+			}
+		}
+	}
+	c.entries[k] = summaryEntry{text: text, expires: now.Add(c.ttl)}
+}
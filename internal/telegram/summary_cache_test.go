@@ -0,0 +1,47 @@
+package telegram
+
+import (
+	"testing"
+	"time"
+
+	"telegramBotTrade/internal/storage"
+)
+
+func TestSummaryCache(t *testing.T) {
+	msgs := []storage.Message{
+		{MessageID: 10, Text: "a", Ts: 1000},
+		{MessageID: 11, Text: "b", Ts: 1100},
+		{MessageID: 12, Text: "c", Ts: 1200},
+	}
+	// 599s past a 10-minute boundary, so the second request falls in the next one
+	now := time.Unix(1_700_000_400+599, 0)
+	c := newSummaryCache(summaryCacheTTL)
+	c.put(newSummaryKey(1, 0, 2, msgs), "summary", now)
+
+	withNew := append(append([]storage.Message(nil), msgs...), storage.Message{MessageID: 13, Ts: 1300})
+	tests := []struct {
+		name string
+		key  summaryKey
+		at   time.Time
+		hit  bool
+	}{
+		{"same messages", newSummaryKey(1, 0, 2, msgs), now, true},
+		{"across a time bucket", newSummaryKey(1, 0, 2, msgs), now.Add(5 * time.Second), true},
+		{"new message", newSummaryKey(1, 0, 2, withNew), now, false},
+		{"oldest slid out", newSummaryKey(1, 0, 2, msgs[1:]), now, false},
+		{"oldest replaced", newSummaryKey(1, 0, 2, append([]storage.Message{{MessageID: 9, Ts: 1050}}, msgs[1:]...)), now, false},
+		{"other window", newSummaryKey(1, 0, 3, msgs), now, false},
+		{"other thread", newSummaryKey(1, 7, 2, msgs), now, false},
+		{"other chat", newSummaryKey(2, 0, 2, msgs), now, false},
+		{"expired", newSummaryKey(1, 0, 2, msgs), now.Add(summaryCacheTTL), false},
+	}
+	for _, tc := range tests {
+		text, ok := c.get(tc.key, tc.at)
+		if ok != tc.hit || (ok && text != "summary") {
+			t.Errorf("%s: get = %q, %v; want hit %v", tc.name, text, ok, tc.hit)
+		}
+	}
+	if s := c.stats(); s.Hits != 2 || s.Misses != int64(len(tests)-2) {
+		t.Errorf("stats = %+v, want 2 hits and %d misses", s, len(tests)-2)
+	}
+}
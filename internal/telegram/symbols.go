@@ -0,0 +1,110 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"slices"
+	"strings"
+
+	"telegramBotTrade/internal/finance"
+	"telegramBotTrade/internal/logging"
+	"telegramBotTrade/internal/storage"
+)
+
+// symbolLimits bounds how many symbols a multi-symbol command accepts.
+type symbolLimits struct {
+	min, max int
+}
+
+var (
+	// multiChartSymbols covers /stocks, /stocksx and /stocks-index; each
+	// symbol is one Yahoo fetch and one line on the chart.
+	multiChartSymbols = symbolLimits{min: 2, max: 8}
+	// portfolioSymbols covers /ew-port.
+	portfolioSymbols = symbolLimits{min: 2, max: 20}
+	// heatSymbols covers /heat; each symbol is one Yahoo fetch and one cell.
+	heatSymbols = symbolLimits{min: 2, max: 12}
+)
+
+const maxSymbolLen = 15
+
+var (
+	reSymbolToken = regexp.MustCompile(`^[A-Z0-9.^_=+-]+$`)
+	// a duration like 1D, 5M or 10Y left in the list by a misspelled or
+	// unsupported interval/window (the command regexes only take lowercase)
+	reDurationToken = regexp.MustCompile(`^\d+(M|H|D|W|WK|MO|Y)$`)
+)
+
+// parseSymbolList splits a command's symbol field into uppercase, deduplicated
+// symbols within lim. The error text is meant for the user: it states the
+// limit or echoes the tokens that aren't symbols, followed by example, in the
+// chat's language.
+func parseSymbolList(cs storage.ChatSettings, field string, lim symbolLimits, example string) ([]string, error) {
+	seen := map[string]bool{}
+	var syms, rejected []string
+	for _, tok := range strings.Fields(field) {
+		su := strings.ToUpper(tok)
+		if !validSymbol(su) {
+			rejected = append(rejected, tok)
+			continue
+		}
+		if seen[su] {
+			continue
+		}
+		seen[su] = true
+		syms = append(syms, su)
+	}
+	switch {
+	case len(rejected) > 0:
+		return nil, errors.New(T(cs, "symbols.invalid", strings.Join(rejected, ", "), example))
+	case len(syms) < lim.min:
+		return nil, errors.New(T(cs, "symbols.too_few", lim.min, len(syms), example))
+	case len(syms) > lim.max:
+		return nil, errors.New(T(cs, "symbols.too_many", lim.max, len(syms)))
+	}
+	return syms, nil
+}
+
+// validSymbol reports whether an uppercased token can be a Yahoo symbol: up to
+// maxSymbolLen symbol characters, at least one letter, and not a duration.
+func validSymbol(s string) bool {
+	if len(s) > maxSymbolLen || !reSymbolToken.MatchString(s) || reDurationToken.MatchString(s) {
+		return false
+	}
+	return strings.ContainsAny(s, "ABCDEFGHIJKLMNOPQRSTUVWXYZ")
+}
+
+// skippedNote is the caption line naming the symbols a multi-symbol chart left
+// out, e.g. "\nskipped: XYZ (unknown symbol)"; empty when none were.
+func skippedNote(cs storage.ChatSettings, skipped []finance.SkippedSymbol) string {
+	if len(skipped) == 0 {
+		return ""
+	}
+	parts := make([]string, len(skipped))
+	for i, s := range skipped {
+		parts[i] = s.Symbol + " (" + s.Reason() + ")"
+	}
+	return "\n" + T(cs, "caption.skipped", strings.Join(parts, ", "))
+}
+
+// chartedSymbols returns syms without the ones the chart skipped.
+func chartedSymbols(syms []string, skipped []finance.SkippedSymbol) []string {
+	if len(skipped) == 0 {
+		return syms
+	}
+	out := make([]string, 0, len(syms))
+	for _, sym := range syms {
+		if !slices.ContainsFunc(skipped, func(s finance.SkippedSymbol) bool { return s.Symbol == sym }) {
+			out = append(out, sym)
+		}
+	}
+	return out
+}
+
+// logSkipped records the full fetch error of each skipped symbol.
+func logSkipped(ctx context.Context, skipped []finance.SkippedSymbol) {
+	for _, s := range skipped {
+		logging.FromContext(ctx).Warn("chart: symbol skipped", "symbol", s.Symbol, "err", s.Err)
+	}
+}
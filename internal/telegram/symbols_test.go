@@ -0,0 +1,88 @@
+package telegram
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"telegramBotTrade/internal/storage"
+)
+
+func TestParseSymbolList(t *testing.T) {
+	en := storage.ChatSettings{}
+	tests := []struct {
+		name    string
+		field   string
+		lim     symbolLimits
+		want    []string
+		wantErr string // substring of the error text
+	}{
+		{name: "uppercased and deduplicated", field: "spy AAPL Spy aapl qqq", lim: multiChartSymbols, want: []string{"SPY", "AAPL", "QQQ"}},
+		{name: "yahoo punctuation", field: "^GSPC BRK-B EURUSD=X BTC-USD 7203.T", lim: multiChartSymbols, want: []string{"^GSPC", "BRK-B", "EURUSD=X", "BTC-USD", "7203.T"}},
+		{name: "extra whitespace", field: "  SPY \t  QQQ  ", lim: multiChartSymbols, want: []string{"SPY", "QQQ"}},
+		// a misspelled or uppercase window stays in the symbol field
+		{name: "uppercase duration", field: "SPY AAPL 1D", lim: multiChartSymbols, wantErr: "1D"},
+		{name: "unsupported window", field: "SPY AAPL 4h", lim: multiChartSymbols, wantErr: "4h"},
+		{name: "month duration", field: "SPY AAPL 3MO", lim: multiChartSymbols, wantErr: "3MO"},
+		{name: "digits only", field: "SPY 123", lim: multiChartSymbols, wantErr: "123"},
+		{name: "too long", field: "SPY ABCDEFGHIJKLMNOP", lim: multiChartSymbols, wantErr: "ABCDEFGHIJKLMNOP"},
+		{name: "bad characters", field: "SPY AA$PL", lim: multiChartSymbols, wantErr: "AA$PL"},
+		{name: "every rejected token echoed", field: "SPY 1D 2W", lim: multiChartSymbols, wantErr: "1D, 2W"},
+		{name: "one symbol", field: "SPY", lim: multiChartSymbols, wantErr: T(en, "symbols.too_few", 2, 1, "/x")},
+		{name: "duplicates count once", field: "SPY spy", lim: multiChartSymbols, wantErr: T(en, "symbols.too_few", 2, 1, "/x")},
+		{name: "too many", field: "A B C D E F G H I", lim: multiChartSymbols, wantErr: T(en, "symbols.too_many", 8, 9)},
+		{name: "portfolio allows more", field: "A B C D E F G H I", lim: portfolioSymbols, want: []string{"A", "B", "C", "D", "E", "F", "G", "H", "I"}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := parseSymbolList(en, tc.field, tc.lim, "/x")
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Fatalf("parseSymbolList(%q) = %v, %v; want error containing %q", tc.field, got, err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil || !slices.Equal(got, tc.want) {
+				t.Fatalf("parseSymbolList(%q) = %v, %v; want %v", tc.field, got, err, tc.want)
+			}
+		})
+	}
+}
+
+// TestMultiSymbolRegexes checks the lazy symbol capture leaves a valid
+// interval and window to their own groups.
+func TestMultiSymbolRegexes(t *testing.T) {
+	tests := []struct {
+		text                   string
+		syms, interval, window string
+	}{
+		{"/stocksx SPY AAPL 1h 1y", "SPY AAPL", "1h", "1y"},
+		{"/stocksx SPY AAPL 1y", "SPY AAPL", "", "1y"},
+		{"/stocksx SPY AAPL 1d 5d", "SPY AAPL", "1d", "5d"},
+		{"/stocksx SPY AAPL", "SPY AAPL", "", ""},
+		{"/stocksx SPY AAPL 1D", "SPY AAPL 1D", "", ""},
+		{"/stocks-index QQQ IWM 15m 3m", "QQQ IWM", "15m", "3m"},
+	}
+	for _, tc := range tests {
+		re := reStocksX
+		if strings.HasPrefix(tc.text, "/stocks-index") {
+			re = reStocksIndex
+		}
+		g := re.FindStringSubmatch(tc.text)
+		if g == nil {
+			t.Errorf("%q did not match", tc.text)
+			continue
+		}
+		if g[1] != tc.syms || g[2] != tc.interval || g[3] != tc.window {
+			t.Errorf("%q: symbols %q interval %q window %q; want %q %q %q", tc.text, g[1], g[2], g[3], tc.syms, tc.interval, tc.window)
+		}
+	}
+	g := reStocks.FindStringSubmatch("/stocks SPY AAPL 1w")
+	if g == nil || g[1] != "SPY AAPL" || g[2] != "1w" {
+		t.Errorf("/stocks SPY AAPL 1w: got %q", g)
+	}
+	g = reEWPort.FindStringSubmatch("/ew-port SPY AAPL QQQ 2y")
+	if g == nil || g[1] != "SPY AAPL QQQ" || g[2] != "2y" {
+		t.Errorf("/ew-port SPY AAPL QQQ 2y: got %q", g)
+	}
+}
@@ -0,0 +1,249 @@
+package telegram
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"telegramBotTrade/internal/finance"
+	"telegramBotTrade/internal/logging"
+	"telegramBotTrade/internal/storage"
+)
+
+const defaultTargetExpiryDays = 30
+
+// reTargetNote matches note:"quoted text" or note:word.
+var reTargetNote = regexp.MustCompile(`note:(?:"([^"]*)"|(\S+))`)
+
+// parseTargetArgs splits "NVDA 150 note:\"trim here\"" into its parts.
+func parseTargetArgs(args string) (symbol string, price float64, note string, ok bool) {
+	if g := reTargetNote.FindStringSubmatch(args); g != nil {
+		note = strings.TrimSpace(g[1] + g[2])
+		args = strings.Replace(args, g[0], "", 1)
+	}
+	fields := strings.Fields(args)
+	if len(fields) != 2 || !reSymbol.MatchString(fields[0]) {
+		return "", 0, "", false
+	}
+	p, err := strconv.ParseFloat(strings.TrimPrefix(fields[1], "$"), 64)
+	if err != nil || p <= 0 {
+		return "", 0, "", false
+	}
+	return strings.ToUpper(fields[0]), p, note, true
+}
+
+// displayName is how a target's owner is mentioned when it is hit.
+func displayName(m *tgbotapi.Message) string {
+	if m.From == nil {
+		return ""
+	}
+	if m.From.UserName != "" {
+		return "@" + m.From.UserName
+	}
+	return m.From.FirstName
+}
+
+// mention renders an HTML mention that notifies the user even without a username.
+func mention(userID int64, name string) string {
+	if strings.HasPrefix(name, "@") {
+		return html.EscapeString(name)
+	}
+	if name == "" {
+		name = "there"
+	}
+	return fmt.Sprintf(`<a href="tg://user?id=%d">%s</a>`, userID, html.EscapeString(name))
+}
+
+func (h *Handlers) handleTarget(ctx context.Context, m *tgbotapi.Message, args string) {
+	chatID := m.Chat.ID
+	cs := h.chartSettings(chatID)
+	fields := strings.Fields(args)
+	if len(fields) == 0 {
+		h.reply(chatID, T(cs, "target.usage"))
+		return
+	}
+	switch strings.ToLower(fields[0]) {
+	case "list":
+		h.listTargets(ctx, chatID)
+		return
+	case "delete", "del", "rm":
+		if len(fields) != 2 {
+			h.reply(chatID, T(cs, "target.usage"))
+			return
+		}
+		id, err := strconv.ParseInt(strings.TrimPrefix(fields[1], "#"), 10, 64)
+		if err != nil {
+			h.reply(chatID, T(cs, "target.usage"))
+			return
+		}
+		ok, err := h.store.DeleteTarget(chatID, id)
+		switch {
+		case err != nil:
+			h.reply(chatID, T(cs, "target.delete_failed")+err.Error())
+		case !ok:
+			h.reply(chatID, T(cs, "target.unknown", id))
+		default:
+			h.reply(chatID, T(cs, "target.deleted", id))
+		}
+		return
+	}
+
+	sym, price, note, ok := parseTargetArgs(args)
+	if !ok {
+		h.reply(chatID, T(cs, "target.usage"))
+		return
+	}
+	qctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	q, err := finance.FetchQuote(qctx, sym)
+	if err != nil {
+		logging.FromContext(ctx).Error("target quote failed", "chat_id", chatID, "symbol", sym, "err", err)
+		h.replyFailure(chatID, T(cs, "fetch.failed", sym), err)
+		return
+	}
+	if q.Price == price {
+		h.reply(chatID, T(cs, "target.already", sym, price))
+		return
+	}
+	days := h.targetExpiryDays
+	if days <= 0 {
+		days = defaultTargetExpiryDays
+	}
+	now := time.Now()
+	t := storage.Target{
+		ChatID:    chatID,
+		UserID:    senderID(m),
+		UserName:  displayName(m),
+		Symbol:    sym,
+		Price:     price,
+		RefPrice:  q.Price,
+		Note:      note,
+		CreatedAt: now.Unix(),
+		ExpiresAt: now.AddDate(0, 0, days).Unix(),
+	}
+	id, updated, err := h.store.SaveTarget(t)
+	if err != nil {
+		h.reply(chatID, T(cs, "target.save_failed")+err.Error())
+		return
+	}
+	saved := "target.set"
+	if updated {
+		saved = "target.updated"
+	}
+	h.reply(chatID, T(cs, saved,
+		id, sym, targetSide(t), price, q.Price, distancePct(q.Price, price), days))
+}
+
+// targetSide is the direction the price has to move to hit the target.
+func targetSide(t storage.Target) string {
+	if t.Price > t.RefPrice {
+		return "≥"
+	}
+	return "≤"
+}
+
+// targetCrossed reports whether price reached the target from the side it started on.
+func targetCrossed(t storage.Target, price float64) bool {
+	if t.Price > t.RefPrice {
+		return price >= t.Price
+	}
+	return price <= t.Price
+}
+
+// distancePct is how far target is from price, in percent of price.
+func distancePct(price, target float64) float64 {
+	if price == 0 {
+		return 0
+	}
+	return (target/price - 1) * 100
+}
+
+func (h *Handlers) listTargets(ctx context.Context, chatID int64) {
+	cs := h.chartSettings(chatID)
+	list, err := h.store.FetchTargets(chatID)
+	if err != nil {
+		h.reply(chatID, T(cs, "target.load_failed")+err.Error())
+		return
+	}
+	if len(list) == 0 {
+		h.reply(chatID, T(cs, "target.none")+" "+T(cs, "target.usage"))
+		return
+	}
+	syms := make([]string, 0, len(list))
+	for _, t := range list {
+		syms = append(syms, t.Symbol)
+	}
+	qctx, cancel := context.WithTimeout(ctx, 45*time.Second)
+	defer cancel()
+	quotes, _ := finance.FetchBatchQuotes(qctx, normalizeSymbols(syms))
+	now := time.Now()
+	var b strings.Builder
+	b.WriteString(T(cs, "target.title") + "\n")
+	for _, t := range list {
+		fmt.Fprintf(&b, "\n#%d %s %s %.2f", t.ID, t.Symbol, targetSide(t), t.Price)
+		if q, ok := quotes[t.Symbol]; ok {
+			b.WriteString(" • " + T(cs, "target.now", q.Price, distancePct(q.Price, t.Price)))
+		} else {
+			b.WriteString(" • " + T(cs, "target.no_quote"))
+		}
+		left := time.Unix(t.ExpiresAt, 0).Sub(now)
+		b.WriteString(" • " + t.UserName + " • " + T(cs, "target.days_left", int(left.Hours()/24)))
+		if t.Note != "" {
+			fmt.Fprintf(&b, "\n   “%s”", t.Note)
+		}
+	}
+	h.reply(chatID, b.String())
+}
+
+// checkTargets notifies each target's owner once when its price is crossed.
+func (b *Bot) checkTargets(ctx context.Context, now time.Time) {
+	logger := logging.FromContext(ctx)
+	if n, err := b.store.DeleteExpiredTargets(now.Unix()); err != nil {
+		logger.Warn("alerts: expire targets failed", "err", err)
+	} else if n > 0 {
+		logger.Info("alerts: targets expired", "count", n)
+	}
+	targets, err := b.store.FetchOpenTargets(now.Unix())
+	if err != nil {
+		logger.Error("alerts: load targets failed", "err", err)
+		return
+	}
+	// targets of inactive chats wait, unhit, until the chat is back
+	targets = slices.DeleteFunc(targets, func(t storage.Target) bool { return !b.h.chats.active(t.ChatID) })
+	if len(targets) == 0 {
+		return
+	}
+	syms := make([]string, 0, len(targets))
+	for _, t := range targets {
+		syms = append(syms, t.Symbol)
+	}
+	qctx, cancel := context.WithTimeout(ctx, time.Minute)
+	quotes, _ := finance.FetchBatchQuotes(qctx, normalizeSymbols(syms))
+	cancel()
+	for _, t := range targets {
+		q, ok := quotes[t.Symbol]
+		if !ok || !targetCrossed(t, q.Price) {
+			continue
+		}
+		first, err := b.store.MarkTargetHit(t.ID, now.Unix())
+		if err != nil || !first {
+			continue
+		}
+		cs := b.h.chartSettings(t.ChatID)
+		text := T(cs, "target.hit", mention(t.UserID, t.UserName), html.EscapeString(t.Symbol), t.Price, q.Price)
+		if t.Note != "" {
+			text += "\n" + T(cs, "target.note") + html.EscapeString(t.Note)
+		}
+		msg := tgbotapi.NewMessage(t.ChatID, text)
+		msg.ParseMode = "HTML"
+		b.api.Send(msg)
+		logger.Info("alerts: target hit", "chat_id", t.ChatID, "target_id", t.ID, "symbol", t.Symbol)
+	}
+}
@@ -0,0 +1,170 @@
+package telegram
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Forum topics arrived in Bot API 6.3, after the tgbotapi release this bot is
+// built on, so the library neither decodes message_thread_id nor sends it.
+// Incoming thread IDs are read from the raw update JSON and outgoing ones are
+// added to send* requests by topicRouter.
+
+type threadKey struct{}
+
+// withThread records the forum topic an update came from.
+func withThread(ctx context.Context, threadID int) context.Context {
+	return context.WithValue(ctx, threadKey{}, threadID)
+}
+
+// threadFrom returns the forum topic of the update being handled (0 = General
+// topic or a chat without topics).
+func threadFrom(ctx context.Context) int {
+	v, _ := ctx.Value(threadKey{}).(int)
+	return v
+}
+
+// decodeUpdate parses a raw update and its forum topic ID. Only messages sent
+// to a topic count; in ordinary supergroups message_thread_id names a reply
+// thread, which the bot doesn't scope by. For a button press the topic is the
+// one of the message carrying the button.
+func decodeUpdate(raw []byte) (tgbotapi.Update, int, error) {
+	var update tgbotapi.Update
+	if err := json.Unmarshal(raw, &update); err != nil {
+		return update, 0, err
+	}
+	type topicProbe struct {
+		ThreadID int  `json:"message_thread_id"`
+		IsTopic  bool `json:"is_topic_message"`
+	}
+	var probe struct {
+		Message       *topicProbe `json:"message"`
+		CallbackQuery *struct {
+			Message *topicProbe `json:"message"`
+		} `json:"callback_query"`
+	}
+	if err := json.Unmarshal(raw, &probe); err != nil {
+		return update, 0, nil
+	}
+	msg := probe.Message
+	if msg == nil && probe.CallbackQuery != nil {
+		msg = probe.CallbackQuery.Message
+	}
+	if msg == nil || !msg.IsTopic {
+		return update, 0, nil
+	}
+	return update, msg.ThreadID, nil
+}
+
+// topicRouter wraps the Bot API HTTP client and adds message_thread_id to
+// send* requests for chats that are handling a command from a forum topic, so
+// every reply lands in the topic it answers. A chat handles one command at a
+// time with PER_CHAT_ORDER (the default); without it, concurrent commands
+// from different topics of one chat may reply into each other's topic.
+type topicRouter struct {
+	next tgbotapi.HTTPClient
+
+	mu     sync.Mutex
+	active map[int64]int
+}
+
+// newTopicRouter installs a topicRouter as api's HTTP client.
+func newTopicRouter(api *tgbotapi.BotAPI) *topicRouter {
+	r := &topicRouter{next: api.Client, active: map[int64]int{}}
+	api.Client = r
+	return r
+}
+
+// enter routes chatID's replies to threadID until the returned func is called.
+func (r *topicRouter) enter(chatID int64, threadID int) func() {
+	if threadID == 0 {
+		return func() {}
+	}
+	r.mu.Lock()
+	r.active[chatID] = threadID
+	r.mu.Unlock()
+	return func() {
+		r.mu.Lock()
+		if r.active[chatID] == threadID {
+			delete(r.active, chatID)
+		}
+		r.mu.Unlock()
+	}
+}
+
+func (r *topicRouter) threadFor(chatID int64) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.active[chatID]
+}
+
+func (r *topicRouter) idle() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.active) == 0
+}
+
+func (r *topicRouter) Do(req *http.Request) (*http.Response, error) {
+	if req.Body == nil || !strings.HasPrefix(path.Base(req.URL.Path), "send") || r.idle() {
+		return r.next.Do(req)
+	}
+	body, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	ct := req.Header.Get("Content-Type")
+	if threadID := r.threadFor(requestChatID(ct, body)); threadID != 0 {
+		body = addThreadParam(ct, body, threadID)
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	req.ContentLength = int64(len(body))
+	return r.next.Do(req)
+}
+
+// requestChatID reads chat_id from a form or multipart request body (0 if absent).
+func requestChatID(contentType string, body []byte) int64 {
+	mediaType, params, _ := mime.ParseMediaType(contentType)
+	if mediaType != "multipart/form-data" {
+		values, _ := url.ParseQuery(string(body))
+		id, _ := strconv.ParseInt(values.Get("chat_id"), 10, 64)
+		return id
+	}
+	mr := multipart.NewReader(bytes.NewReader(body), params["boundary"])
+	for {
+		part, err := mr.NextPart()
+		if err != nil {
+			return 0
+		}
+		if part.FormName() == "chat_id" {
+			v, _ := io.ReadAll(part)
+			id, _ := strconv.ParseInt(string(v), 10, 64)
+			return id
+		}
+	}
+}
+
+// addThreadParam appends message_thread_id to a form body, or prepends it as
+// the first part of a multipart body.
+func addThreadParam(contentType string, body []byte, threadID int) []byte {
+	mediaType, params, _ := mime.ParseMediaType(contentType)
+	if mediaType != "multipart/form-data" {
+		return append(body, "&message_thread_id="+strconv.Itoa(threadID)...)
+	}
+	part := "--" + params["boundary"] + "\r\n" +
+		"Content-Disposition: form-data; name=\"message_thread_id\"\r\n\r\n" +
+		strconv.Itoa(threadID) + "\r\n"
+	return append([]byte(part), body...)
+}
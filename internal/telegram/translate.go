@@ -0,0 +1,95 @@
+package telegram
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"telegramBotTrade/internal/logging"
+)
+
+const (
+	// translateMaxChars caps the text sent for translation.
+	translateMaxChars = 2000
+	// defaultTranslateLang is the target when neither the command nor the chat names one.
+	defaultTranslateLang = "English"
+)
+
+// reLanguage accepts a language name or code such as "English", "zh" or "pt-BR".
+var reLanguage = regexp.MustCompile(`^\p{L}[\p{L}\- ]{1,29}$`)
+
+// handleTranslate translates the message m replies to into lang, or the chat's
+// default language when lang is empty, and answers as a reply to the original.
+func (h *Handlers) handleTranslate(ctx context.Context, m *tgbotapi.Message, lang string) {
+	chatID := m.Chat.ID
+	src := m.ReplyToMessage
+	if src == nil {
+		h.reply(chatID, h.t(chatID, "translate.usage"))
+		return
+	}
+	text := src.Text
+	if text == "" {
+		text = src.Caption
+	}
+	if strings.TrimSpace(text) == "" {
+		h.reply(chatID, h.t(chatID, "translate.no_text"))
+		return
+	}
+	if lang == "" {
+		lang = h.chartSettings(chatID).TranslateLang
+	}
+	if lang == "" {
+		lang = defaultTranslateLang
+	}
+	if !reLanguage.MatchString(lang) {
+		h.reply(chatID, h.t(chatID, "translate.unknown", lang)+h.t(chatID, "translate.usage"))
+		return
+	}
+	if msg, over := h.aiBudgetExceeded(chatID); over {
+		h.reply(chatID, msg)
+		return
+	}
+	truncated := false
+	if r := []rune(text); len(r) > translateMaxChars {
+		text, truncated = string(r[:translateMaxChars]), true
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	out, err := h.summarize.Translate(ctx, text, lang)
+	if err != nil {
+		h.reply(chatID, h.t(chatID, "translate.failed")+err.Error())
+		return
+	}
+	if truncated {
+		out += "\n\n" + h.t(chatID, "translate.truncated", translateMaxChars)
+	}
+	logging.FromContext(ctx).Info("translate: done", "chat_id", chatID, "lang", lang, "chars", len([]rune(text)))
+	msg := tgbotapi.NewMessage(chatID, out)
+	msg.ReplyToMessageID = src.MessageID
+	h.api.Send(msg)
+}
+
+// setTranslateLang stores the chat's default /translate language, which also
+// picks the language of the bot's own replies.
+func (h *Handlers) setTranslateLang(chatID int64, value string) {
+	value = strings.TrimSpace(value)
+	if strings.EqualFold(value, "off") || strings.EqualFold(value, "default") {
+		value = ""
+	} else if !reLanguage.MatchString(value) {
+		h.reply(chatID, h.t(chatID, "lang.usage"))
+		return
+	}
+	if err := h.settings.Set(chatID, "translate_lang", value); err != nil {
+		h.reply(chatID, h.t(chatID, "settings.save_failed")+err.Error())
+		return
+	}
+	if value == "" {
+		h.reply(chatID, h.t(chatID, "lang.reset", defaultTranslateLang))
+		return
+	}
+	h.reply(chatID, h.t(chatID, "lang.set", value))
+}
@@ -0,0 +1,35 @@
+package telegram
+
+import (
+	"context"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"telegramBotTrade/internal/finance"
+	"telegramBotTrade/internal/logging"
+)
+
+// handleVIX sends the stacked ^VIX level and term-structure chart for window.
+func (h *Handlers) handleVIX(ctx context.Context, chatID int64, window string) {
+	ctx, cancel := context.WithTimeout(ctx, 60*time.Second)
+	defer cancel()
+	ctx, fresh := finance.WithFreshness(ctx)
+	cs := h.chartSettings(chatID)
+	img, sum, err := finance.MakeVIXChart(ctx, window, renderOptions(ctx, cs))
+	if err != nil {
+		logging.FromContext(ctx).Error("vix failed", "chat_id", chatID, "window", window, "err", err)
+		h.replyFailure(chatID, T(cs, "vix.failed"), err)
+		return
+	}
+	state := T(cs, "vix.contango")
+	if sum.Inverted {
+		state = T(cs, "vix.inverted")
+	}
+	photo := tgbotapi.NewPhoto(chatID, tgbotapi.FileBytes{Name: "vix.png", Bytes: img})
+	photo.Caption = T(cs, "vix.caption",
+		sum.Level, sum.Percentile, sum.VIX9D, sum.VIX3M, state)
+	photo.Caption = demoCaption(freshCaption(cs, photo.Caption, fresh))
+	h.api.Send(photo)
+	markDelivered(ctx)
+}
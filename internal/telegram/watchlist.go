@@ -0,0 +1,79 @@
+package telegram
+
+import "strings"
+
+// maxWatchlist caps a chat's watchlist so briefs and movers stay one message.
+const maxWatchlist = 30
+
+// normalizeSymbols upper-cases and dedupes symbols, keeping their order.
+func normalizeSymbols(raw []string) []string {
+	seen := map[string]struct{}{}
+	out := make([]string, 0, len(raw))
+	for _, s := range raw {
+		su := strings.ToUpper(strings.TrimSpace(s))
+		if su == "" {
+			continue
+		}
+		if _, ok := seen[su]; ok {
+			continue
+		}
+		seen[su] = struct{}{}
+		out = append(out, su)
+	}
+	return out
+}
+
+// handleWatch manages the chat's watchlist used by /brief and /movers.
+func (h *Handlers) handleWatch(chatID int64, args string) {
+	fields := strings.Fields(args)
+	if len(fields) == 0 || fields[0] == "list" {
+		list, err := h.store.FetchWatchlist(chatID)
+		if err != nil {
+			h.reply(chatID, h.t(chatID, "watch.load_failed")+err.Error())
+			return
+		}
+		if len(list) == 0 {
+			h.reply(chatID, h.t(chatID, "watch.empty")+h.t(chatID, "watch.usage"))
+			return
+		}
+		h.reply(chatID, h.t(chatID, "watch.list")+strings.Join(list, ", "))
+		return
+	}
+	syms := normalizeSymbols(fields[1:])
+	if len(syms) == 0 {
+		h.reply(chatID, h.t(chatID, "watch.usage"))
+		return
+	}
+	for _, s := range syms {
+		if !reSymbol.MatchString(s) {
+			h.reply(chatID, h.t(chatID, "watch.bad_symbol", s))
+			return
+		}
+	}
+	switch fields[0] {
+	case "add":
+		existing, err := h.store.FetchWatchlist(chatID)
+		if err != nil {
+			h.reply(chatID, h.t(chatID, "watch.load_failed")+err.Error())
+			return
+		}
+		if len(normalizeSymbols(append(existing, syms...))) > maxWatchlist {
+			h.reply(chatID, h.t(chatID, "watch.too_many", maxWatchlist))
+			return
+		}
+		if err := h.store.AddWatchlist(chatID, syms...); err != nil {
+			h.reply(chatID, h.t(chatID, "watch.update_failed")+err.Error())
+			return
+		}
+		h.reply(chatID, h.t(chatID, "watch.added")+strings.Join(syms, ", "))
+	case "remove", "rm", "del":
+		n, err := h.store.RemoveWatchlist(chatID, syms...)
+		if err != nil {
+			h.reply(chatID, h.t(chatID, "watch.update_failed")+err.Error())
+			return
+		}
+		h.reply(chatID, h.t(chatID, "watch.removed", n))
+	default:
+		h.reply(chatID, h.t(chatID, "watch.usage"))
+	}
+}
@@ -0,0 +1,149 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"telegramBotTrade/internal/logging"
+)
+
+const (
+	defaultWorkers   = 16
+	defaultQueueSize = 256
+)
+
+var errQueueFull = errors.New("handler queue full")
+
+type job struct {
+	ctx context.Context
+	msg *tgbotapi.Message
+}
+
+// workerPool runs message handlers on a fixed number of goroutines fed by a
+// bounded queue, so a burst of updates can't spawn unbounded goroutines.
+//
+// In ordered mode every worker owns its own queue and a chat is always routed
+// to the same worker, so commands from one chat never interleave while
+// different chats still run in parallel. Otherwise all workers share one queue.
+type workerPool struct {
+	queues  []chan job
+	handle  func(context.Context, *tgbotapi.Message)
+	onPanic func(context.Context, *tgbotapi.Message)
+
+	mu     sync.RWMutex
+	closed bool
+	wg     sync.WaitGroup
+}
+
+func newWorkerPool(workers, queueSize int, ordered bool, handle, onPanic func(context.Context, *tgbotapi.Message)) *workerPool {
+	if workers <= 0 {
+		workers = defaultWorkers
+	}
+	if queueSize <= 0 {
+		queueSize = defaultQueueSize
+	}
+	p := &workerPool{
+		handle:  handle,
+		onPanic: onPanic,
+	}
+	if ordered {
+		per := queueSize / workers
+		if per < 1 {
+			per = 1
+		}
+		for i := 0; i < workers; i++ {
+			p.queues = append(p.queues, make(chan job, per))
+		}
+	} else {
+		p.queues = []chan job{make(chan job, queueSize)}
+	}
+	p.wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go p.work(p.queues[i%len(p.queues)])
+	}
+	return p
+}
+
+func (p *workerPool) work(q chan job) {
+	defer p.wg.Done()
+	for j := range q {
+		p.run(j)
+	}
+}
+
+// queueFor picks the queue for a chat; chats stick to one queue in ordered mode.
+func (p *workerPool) queueFor(chatID int64) chan job {
+	if len(p.queues) == 1 {
+		return p.queues[0]
+	}
+	idx := chatID % int64(len(p.queues))
+	if idx < 0 {
+		idx = -idx // group and channel IDs are negative
+	}
+	return p.queues[idx]
+}
+
+// run executes one job, recovering panics so one bad update can't kill the process.
+func (p *workerPool) run(j job) {
+	defer func() {
+		if r := recover(); r != nil {
+			logging.FromContext(j.ctx).Error("handler panic", "panic", r, "stack", string(debug.Stack()))
+			if p.onPanic != nil && j.msg != nil {
+				p.onPanic(j.ctx, j.msg)
+			}
+		}
+	}()
+	p.handle(j.ctx, j.msg)
+}
+
+// submit enqueues a job without blocking; it fails when the queue is full or the pool is stopped.
+func (p *workerPool) submit(j job) error {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.closed {
+		return errors.New("handler pool stopped")
+	}
+	select {
+	case p.queueFor(j.msg.Chat.ID) <- j:
+		return nil
+	default:
+		return errQueueFull
+	}
+}
+
+// depth reports the number of queued jobs not yet picked up by a worker.
+func (p *workerPool) depth() int {
+	n := 0
+	for _, q := range p.queues {
+		n += len(q)
+	}
+	return n
+}
+
+// stop closes the queue and waits up to timeout for queued and running jobs.
+func (p *workerPool) stop(timeout time.Duration) bool {
+	p.mu.Lock()
+	if !p.closed {
+		p.closed = true
+		for _, q := range p.queues {
+			close(q)
+		}
+	}
+	p.mu.Unlock()
+	done := make(chan struct{})
+	go func() {
+		p.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return true
+	case <-time.After(timeout):
+		return false
+	}
+}
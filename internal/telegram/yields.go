@@ -0,0 +1,79 @@
+package telegram
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"telegramBotTrade/internal/finance"
+	"telegramBotTrade/internal/logging"
+	"telegramBotTrade/internal/storage"
+)
+
+// handleYield sends the Treasury yields chart for window.
+func (h *Handlers) handleYield(ctx context.Context, chatID int64, window string) {
+	ctx, cancel := context.WithTimeout(ctx, 60*time.Second)
+	defer cancel()
+	ctx, fresh := finance.WithFreshness(ctx)
+	cs := h.chartSettings(chatID)
+	img, sum, err := finance.MakeYieldChart(ctx, window, renderOptions(ctx, cs))
+	if err != nil {
+		logging.FromContext(ctx).Error("yield failed", "chat_id", chatID, "window", window, "err", err)
+		h.replyFailure(chatID, T(cs, "yield.failed"), err)
+		return
+	}
+	photo := tgbotapi.NewPhoto(chatID, tgbotapi.FileBytes{Name: "yields.png", Bytes: img})
+	photo.Caption = demoCaption(freshCaption(cs, yieldsLine(sum)+inversionLine(cs, sum), fresh))
+	h.api.Send(photo)
+	markDelivered(ctx)
+}
+
+// handleCurve sends a snapshot of the Treasury curve with its yields.
+func (h *Handlers) handleCurve(ctx context.Context, chatID int64) {
+	ctx, cancel := context.WithTimeout(ctx, 60*time.Second)
+	defer cancel()
+	ctx, fresh := finance.WithFreshness(ctx)
+	cs := h.chartSettings(chatID)
+	img, sum, err := finance.MakeCurveChart(ctx, renderOptions(ctx, cs))
+	if err != nil {
+		logging.FromContext(ctx).Error("curve failed", "chat_id", chatID, "err", err)
+		h.replyFailure(chatID, T(cs, "curve.failed"), err)
+		return
+	}
+	caption := yieldsLine(sum) + "\n" + T(cs, "curve.spread", yieldOf(sum, "10Y")-yieldOf(sum, "13W")) + inversionLine(cs, sum)
+	photo := tgbotapi.NewPhoto(chatID, tgbotapi.FileBytes{Name: "curve.png", Bytes: img})
+	photo.Caption = demoCaption(freshCaption(cs, caption, fresh))
+	h.api.Send(photo)
+	markDelivered(ctx)
+}
+
+// yieldsLine lists the latest yields, e.g. "13W 4.32% • 5Y 4.01% • ...".
+func yieldsLine(sum *finance.YieldSummary) string {
+	parts := make([]string, len(sum.Yields))
+	for i, y := range sum.Yields {
+		parts[i] = fmt.Sprintf("%s %.2f%%", finance.Treasuries[i].Label, y)
+	}
+	return strings.Join(parts, " • ")
+}
+
+// inversionLine flags the pairs where short rates exceed long ones, or is
+// empty for a normal curve.
+func inversionLine(cs storage.ChatSettings, sum *finance.YieldSummary) string {
+	if len(sum.Inversions) == 0 {
+		return ""
+	}
+	return "\n" + T(cs, "yield.inverted") + strings.Join(sum.Inversions, ", ")
+}
+
+// yieldOf returns the latest yield of the maturity labeled label.
+func yieldOf(sum *finance.YieldSummary, label string) float64 {
+	for i, t := range finance.Treasuries {
+		if t.Label == label {
+			return sum.Yields[i]
+		}
+	}
+	return 0
+}
@@ -0,0 +1,42 @@
+package telegram
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"telegramBotTrade/internal/finance"
+	"telegramBotTrade/internal/logging"
+)
+
+const yoyDefaultYears = 5
+
+// handleYoY overlays sym's year-to-date path on its previous years' paths.
+func (h *Handlers) handleYoY(ctx context.Context, chatID int64, sym, yearsArg string) {
+	years := yoyDefaultYears
+	if yearsArg != "" {
+		n, err := strconv.Atoi(yearsArg)
+		if err != nil || n < 1 || n > finance.MaxYoYYears {
+			h.reply(chatID, h.t(chatID, "yoy.usage", finance.MaxYoYYears, yoyDefaultYears))
+			return
+		}
+		years = n
+	}
+	ctx, cancel := context.WithTimeout(ctx, 60*time.Second)
+	defer cancel()
+	ctx, fresh := finance.WithFreshness(ctx)
+	cs := h.chartSettings(chatID)
+	img, err := finance.MakeYoYChart(ctx, sym, years, renderOptions(ctx, cs))
+	if err != nil {
+		logging.FromContext(ctx).Error("yoy failed", "chat_id", chatID, "symbol", sym, "err", err)
+		h.replyFailure(chatID, T(cs, "yoy.failed"), err)
+		return
+	}
+	photo := tgbotapi.NewPhoto(chatID, tgbotapi.FileBytes{Name: sym + "_yoy.png", Bytes: img})
+	photo.Caption = demoCaption(freshCaption(cs, T(cs, "yoy.caption", strings.ToUpper(sym), years), fresh))
+	h.api.Send(photo)
+	markDelivered(ctx)
+}
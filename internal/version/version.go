@@ -0,0 +1,106 @@
+// Package version reports build metadata injected at link time, e.g.
+//
+//	go build -ldflags "-X telegramBotTrade/internal/version.Commit=$(git rev-parse --short HEAD) \
+//	  -X telegramBotTrade/internal/version.BuildTime=$(date -u +%Y-%m-%dT%H:%M:%SZ)" ./cmd/bot
+package version
+
+import (
+	"fmt"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"time"
+)
+
+// Set via -ldflags -X. Commit falls back to the VCS revision embedded by the Go toolchain.
+var (
+	Commit    = ""
+	BuildTime = ""
+)
+
+var started = time.Now()
+
+// Info describes the running binary. OpenAIModel and DBPath are filled in by
+// the caller since they come from configuration.
+type Info struct {
+	Commit      string `json:"commit"`
+	BuildTime   string `json:"build_time"`
+	GoVersion   string `json:"go_version"`
+	Uptime      string `json:"uptime"`
+	OpenAIModel string `json:"openai_model,omitempty"`
+	DBPath      string `json:"db_path,omitempty"`
+}
+
+// Get returns the build info and current uptime.
+func Get() Info {
+	return Info{
+		Commit:    commit(),
+		BuildTime: orUnknown(BuildTime),
+		GoVersion: runtime.Version(),
+		Uptime:    time.Since(started).Round(time.Second).String(),
+	}
+}
+
+func commit() string {
+	if Commit != "" {
+		return Commit
+	}
+	if bi, ok := debug.ReadBuildInfo(); ok {
+		if rev := revision(bi.Settings); rev != "" {
+			return rev
+		}
+	}
+	return "unknown"
+}
+
+// revision is the short VCS revision in the build settings, marked -dirty
+// when the tree had local changes; "" when the build wasn't stamped.
+func revision(settings []debug.BuildSetting) string {
+	var rev, dirty string
+	for _, s := range settings {
+		switch s.Key {
+		case "vcs.revision":
+			rev = s.Value
+		case "vcs.modified":
+			if s.Value == "true" {
+				dirty = "-dirty"
+			}
+		}
+	}
+	if len(rev) > 12 {
+		rev = rev[:12]
+	}
+	if rev == "" {
+		return ""
+	}
+	return rev + dirty
+}
+
+func orUnknown(s string) string {
+	if s == "" {
+		return "unknown"
+	}
+	return s
+}
+
+// String formats the info as a multi-line chat message.
+func (i Info) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Commit: %s\n", i.Commit)
+	fmt.Fprintf(&b, "Built: %s\n", i.BuildTime)
+	fmt.Fprintf(&b, "Go: %s\n", i.GoVersion)
+	fmt.Fprintf(&b, "Uptime: %s\n", i.Uptime)
+	if i.OpenAIModel != "" {
+		fmt.Fprintf(&b, "OpenAI model: %s\n", i.OpenAIModel)
+	}
+	if i.DBPath != "" {
+		fmt.Fprintf(&b, "DB: %s\n", i.DBPath)
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// LogAttrs returns the info as slog key/value pairs.
+func (i Info) LogAttrs() []any {
+	return []any{"commit", i.Commit, "build_time", i.BuildTime, "go", i.GoVersion,
+		"openai_model", i.OpenAIModel, "db_path", i.DBPath}
+}
@@ -0,0 +1,82 @@
+package version
+
+import (
+	"encoding/json"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"testing"
+)
+
+func TestInfoString(t *testing.T) {
+	base := Info{Commit: "abc1234", BuildTime: "2024-06-03T12:00:00Z", GoVersion: "go1.24.0", Uptime: "1h2m3s"}
+	full := base
+	full.OpenAIModel, full.DBPath = "gpt-4", "/data/bot.db"
+	tests := []struct {
+		name string
+		info Info
+		want string
+	}{
+		{"required lines only", base, "Commit: abc1234\nBuilt: 2024-06-03T12:00:00Z\nGo: go1.24.0\nUptime: 1h2m3s"},
+		{"with model and db", full, "Commit: abc1234\nBuilt: 2024-06-03T12:00:00Z\nGo: go1.24.0\nUptime: 1h2m3s\nOpenAI model: gpt-4\nDB: /data/bot.db"},
+		{"model only", Info{Commit: "unknown", BuildTime: "unknown", GoVersion: "go1.24.0", Uptime: "0s", OpenAIModel: "gpt-4"},
+			"Commit: unknown\nBuilt: unknown\nGo: go1.24.0\nUptime: 0s\nOpenAI model: gpt-4"},
+	}
+	for _, tc := range tests {
+		if got := tc.info.String(); got != tc.want {
+			t.Errorf("%s: String() =\n%s\nwant\n%s", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestInfoJSONOmitsOptional(t *testing.T) {
+	b, err := json.Marshal(Info{Commit: "abc"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := string(b); strings.Contains(s, "openai_model") || strings.Contains(s, "db_path") {
+		t.Errorf("json = %s, want the unset optional fields left out", s)
+	}
+}
+
+func TestRevision(t *testing.T) {
+	const full = "0123456789abcdef0123456789abcdef01234567"
+	tests := []struct {
+		name     string
+		settings []debug.BuildSetting
+		want     string
+	}{
+		{"not stamped", nil, ""},
+		{"clean", []debug.BuildSetting{{Key: "vcs.revision", Value: full}, {Key: "vcs.modified", Value: "false"}}, "0123456789ab"},
+		{"dirty", []debug.BuildSetting{{Key: "vcs.modified", Value: "true"}, {Key: "vcs.revision", Value: full}}, "0123456789ab-dirty"},
+		{"short", []debug.BuildSetting{{Key: "vcs.revision", Value: "abc"}}, "abc"},
+		{"modified without a revision", []debug.BuildSetting{{Key: "vcs.modified", Value: "true"}}, ""},
+	}
+	for _, tc := range tests {
+		if got := revision(tc.settings); got != tc.want {
+			t.Errorf("%s: revision = %q, want %q", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestGet(t *testing.T) {
+	defer func(c, b string) { Commit, BuildTime = c, b }(Commit, BuildTime)
+
+	Commit, BuildTime = "deadbee", ""
+	info := Get()
+	if info.Commit != "deadbee" {
+		t.Errorf("Commit = %q, want the linked-in deadbee", info.Commit)
+	}
+	if info.BuildTime != "unknown" {
+		t.Errorf("BuildTime = %q, want unknown when not linked in", info.BuildTime)
+	}
+	if info.GoVersion != runtime.Version() {
+		t.Errorf("GoVersion = %q, want %q", info.GoVersion, runtime.Version())
+	}
+
+	// without -ldflags the commit comes from the build, or is unknown
+	Commit = ""
+	if got := Get().Commit; got == "" {
+		t.Error("Commit is empty without -ldflags, want a revision or unknown")
+	}
+}